@@ -45,19 +45,11 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 		account = &pubAccount
 	} else {
 		// accountID resolved to a valid account, merge with AccountDefaults for a complete config
-		account = &config.Account{}
-		completeJSON, err := jsonpatch.MergePatch(cfg.AccountDefaultsJSON(), accountJSON)
-		if err == nil {
-			err = json.Unmarshal(completeJSON, account)
-		}
-		if err != nil {
+		var err error
+		if account, err = MergeWithDefaults(cfg, accountID, accountJSON); err != nil {
 			errs = append(errs, err)
 			return nil, errs
 		}
-		// Fill in ID if needed, so it can be left out of account definition
-		if len(account.ID) == 0 {
-			account.ID = accountID
-		}
 	}
 	if account.Disabled {
 		errs = append(errs, &errortypes.BlacklistedAcct{
@@ -67,3 +59,20 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 	}
 	return account, nil
 }
+
+// MergeWithDefaults merges the stored account JSON with the host AccountDefaults, for a complete config
+func MergeWithDefaults(cfg *config.Configuration, accountID string, accountJSON json.RawMessage) (*config.Account, error) {
+	account := &config.Account{}
+	completeJSON, err := jsonpatch.MergePatch(cfg.AccountDefaultsJSON(), accountJSON)
+	if err == nil {
+		err = json.Unmarshal(completeJSON, account)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Fill in ID if needed, so it can be left out of account definition
+	if len(account.ID) == 0 {
+		account.ID = accountID
+	}
+	return account, nil
+}
@@ -2,19 +2,21 @@ package config
 
 import (
 	"github.com/golang/glog"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/analytics/clients"
 	"github.com/prebid/prebid-server/analytics/filesystem"
 	"github.com/prebid/prebid-server/analytics/pubstack"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/privacy"
 )
 
 //Modules that need to be logged to need to be initialized here
 func NewPBSAnalytics(analytics *config.Analytics) analytics.PBSAnalyticsModule {
-	modules := make(enabledAnalytics, 0)
+	modules := make(enabledAnalytics)
 	if len(analytics.File.Filename) > 0 {
 		if mod, err := filesystem.NewFileLogger(analytics.File.Filename); err == nil {
-			modules = append(modules, mod)
+			modules["file"] = mod
 		} else {
 			glog.Fatalf("Could not initialize FileLogger for file %v :%v", analytics.File.Filename, err)
 		}
@@ -29,7 +31,7 @@ func NewPBSAnalytics(analytics *config.Analytics) analytics.PBSAnalyticsModule {
 			analytics.Pubstack.Buffers.BufferSize,
 			analytics.Pubstack.Buffers.Timeout)
 		if err == nil {
-			modules = append(modules, pubstackModule)
+			modules["pubstack"] = pubstackModule
 		} else {
 			glog.Errorf("Could not initialize PubstackModule: %v", err)
 		}
@@ -37,18 +39,22 @@ func NewPBSAnalytics(analytics *config.Analytics) analytics.PBSAnalyticsModule {
 	return modules
 }
 
-//Collection of all the correctly configured analytics modules - implements the PBSAnalyticsModule interface
-type enabledAnalytics []analytics.PBSAnalyticsModule
+//Collection of all the correctly configured analytics modules, by name - implements the PBSAnalyticsModule interface
+type enabledAnalytics map[string]analytics.PBSAnalyticsModule
 
 func (ea enabledAnalytics) LogAuctionObject(ao *analytics.AuctionObject) {
-	for _, module := range ea {
-		module.LogAuctionObject(ao)
+	for name, module := range ea {
+		if reportAnalyticsAllowed(name, ao.Account, ao.Request) {
+			module.LogAuctionObject(ao)
+		}
 	}
 }
 
 func (ea enabledAnalytics) LogVideoObject(vo *analytics.VideoObject) {
-	for _, module := range ea {
-		module.LogVideoObject(vo)
+	for name, module := range ea {
+		if reportAnalyticsAllowed(name, vo.Account, vo.Request) {
+			module.LogVideoObject(vo)
+		}
 	}
 }
 
@@ -65,13 +71,29 @@ func (ea enabledAnalytics) LogSetUIDObject(so *analytics.SetUIDObject) {
 }
 
 func (ea enabledAnalytics) LogAmpObject(ao *analytics.AmpObject) {
-	for _, module := range ea {
-		module.LogAmpObject(ao)
+	for name, module := range ea {
+		if reportAnalyticsAllowed(name, ao.Account, ao.Request) {
+			module.LogAmpObject(ao)
+		}
 	}
 }
 
 func (ea enabledAnalytics) LogNotificationEventObject(ne *analytics.NotificationEvent) {
-	for _, module := range ea {
-		module.LogNotificationEventObject(ne)
+	for name, module := range ea {
+		if reportAnalyticsAllowed(name, ne.Account, nil) {
+			module.LogNotificationEventObject(ne)
+		}
+	}
+}
+
+// reportAnalyticsAllowed returns true if the activity controls of the account allow the module to report on the
+// request. The transactions not tied to an account are always reported.
+func reportAnalyticsAllowed(module string, account *config.Account, request *openrtb2.BidRequest) bool {
+	if account == nil {
+		return true
 	}
+
+	activityControl := privacy.NewActivityControl(&account.Privacy)
+	component := privacy.Component{Type: privacy.ComponentTypeAnalytics, Name: module}
+	return activityControl.Allow(privacy.ActivityReportAnalytics, component, privacy.NewActivityRequest(request))
 }
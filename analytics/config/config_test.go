@@ -59,6 +59,33 @@ func TestSampleModule(t *testing.T) {
 	}
 }
 
+func TestSampleModuleActivityControl(t *testing.T) {
+	var count int
+	am := initAnalytics(&count)
+
+	deniedAccount := &config.Account{
+		Privacy: config.AccountPrivacy{
+			AllowActivities: config.AllowActivities{
+				ReportAnalytics: config.Activity{
+					Rules: []config.ActivityRule{{
+						Condition: config.ActivityCondition{ComponentType: []string{"analytics"}, ComponentName: []string{"sampleModule"}},
+						Allow:     false,
+					}},
+				},
+			},
+		},
+	}
+
+	am.LogAuctionObject(&analytics.AuctionObject{Request: &openrtb2.BidRequest{}, Account: deniedAccount})
+	am.LogAmpObject(&analytics.AmpObject{Account: deniedAccount})
+	am.LogVideoObject(&analytics.VideoObject{Account: deniedAccount})
+	am.LogNotificationEventObject(&analytics.NotificationEvent{Account: deniedAccount})
+	assert.Equal(t, 0, count, "PBSAnalyticsModule denied by the account activity controls")
+
+	am.LogAuctionObject(&analytics.AuctionObject{Request: &openrtb2.BidRequest{}, Account: &config.Account{}})
+	assert.Equal(t, 1, count, "PBSAnalyticsModule allowed by default")
+}
+
 type sampleModule struct {
 	count *int
 }
@@ -76,8 +103,8 @@ func (m *sampleModule) LogAmpObject(ao *analytics.AmpObject) { *m.count++ }
 func (m *sampleModule) LogNotificationEventObject(ne *analytics.NotificationEvent) { *m.count++ }
 
 func initAnalytics(count *int) analytics.PBSAnalyticsModule {
-	modules := make(enabledAnalytics, 0)
-	modules = append(modules, &sampleModule{count})
+	modules := make(enabledAnalytics)
+	modules["sampleModule"] = &sampleModule{count}
 	return &modules
 }
 
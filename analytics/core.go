@@ -43,6 +43,7 @@ type AmpObject struct {
 	AuctionResponse    *openrtb2.BidResponse
 	AmpTargetingValues map[string]string
 	Origin             string
+	Account            *config.Account
 	StartTime          time.Time
 }
 
@@ -54,6 +55,7 @@ type VideoObject struct {
 	Response      *openrtb2.BidResponse
 	VideoRequest  *openrtb_ext.BidRequestVideo
 	VideoResponse *openrtb_ext.BidResponseVideo
+	Account       *config.Account
 	StartTime     time.Time
 }
 
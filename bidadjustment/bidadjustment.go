@@ -0,0 +1,140 @@
+package bidadjustment
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const (
+	defaultCurrency string  = "USD"
+	maxMultiplier   float64 = 100
+)
+
+// Merge returns the bid adjustments of the auction: the request adjustments override the account ones for the same
+// media type, bidder and deal ID. The account adjustments which are invalid are left out.
+func Merge(request, account *openrtb_ext.ExtRequestPrebidBidAdjustments) *openrtb_ext.ExtRequestPrebidBidAdjustments {
+	if account == nil {
+		return request
+	}
+
+	merged := &openrtb_ext.ExtRequestPrebidBidAdjustments{}
+	mergedMediaTypes := []*openrtb_ext.AdjustmentsByBidder{
+		&merged.MediaType.Banner,
+		&merged.MediaType.Video,
+		&merged.MediaType.Audio,
+		&merged.MediaType.Native,
+		&merged.MediaType.WildCard,
+	}
+	for i, mediaType := range mediaTypes {
+		var requestAdjustments openrtb_ext.AdjustmentsByBidder
+		if request != nil {
+			requestAdjustments = request.MediaType.ForMediaType(mediaType)
+		}
+		*mergedMediaTypes[i] = mergeByBidder(requestAdjustments, account.MediaType.ForMediaType(mediaType))
+	}
+	return merged
+}
+
+func mergeByBidder(request, account openrtb_ext.AdjustmentsByBidder) openrtb_ext.AdjustmentsByBidder {
+	if len(request) == 0 && len(account) == 0 {
+		return nil
+	}
+
+	merged := make(openrtb_ext.AdjustmentsByBidder, len(request)+len(account))
+	for bidder, byDealID := range account {
+		for dealID, adjustments := range byDealID {
+			if validateAdjustments(adjustments) != nil {
+				continue
+			}
+			if merged[bidder] == nil {
+				merged[bidder] = make(map[string][]openrtb_ext.Adjustment)
+			}
+			merged[bidder][dealID] = adjustments
+		}
+	}
+	for bidder, byDealID := range request {
+		for dealID, adjustments := range byDealID {
+			if merged[bidder] == nil {
+				merged[bidder] = make(map[string][]openrtb_ext.Adjustment)
+			}
+			merged[bidder][dealID] = adjustments
+		}
+	}
+	return merged
+}
+
+// Get returns the adjustments applying to a bid. The media type, bidder and deal ID are matched in that order,
+// each of them preferring an exact match over the wildcard.
+func Get(adjustments *openrtb_ext.ExtRequestPrebidBidAdjustments, mediaType openrtb_ext.BidType, bidder openrtb_ext.BidderName, dealID string) []openrtb_ext.Adjustment {
+	if adjustments == nil {
+		return nil
+	}
+
+	for _, mediaTypeKey := range []string{string(mediaType), openrtb_ext.AdjustmentWildCard} {
+		byBidder := adjustments.MediaType.ForMediaType(mediaTypeKey)
+		for _, bidderKey := range []string{string(bidder), openrtb_ext.AdjustmentWildCard} {
+			byDealID, found := byBidder[bidderKey]
+			if !found {
+				continue
+			}
+			dealIDKeys := []string{openrtb_ext.AdjustmentWildCard}
+			if dealID != "" {
+				dealIDKeys = []string{dealID, openrtb_ext.AdjustmentWildCard}
+			}
+			for _, dealIDKey := range dealIDKeys {
+				if found, ok := byDealID[dealIDKey]; ok {
+					return found
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Apply adjusts the bid price, in the bid currency, by each of the adjustments in order. The static CPM values are
+// converted from the adjustment currency to the bid currency.
+func Apply(adjustments []openrtb_ext.Adjustment, price float64, bidCurrency string, conversions currency.Conversions) (float64, error) {
+	for _, adjustment := range adjustments {
+		switch adjustment.Type {
+		case openrtb_ext.AdjustmentTypeMultiplier:
+			price = price * adjustment.Value
+		case openrtb_ext.AdjustmentTypeCPM, openrtb_ext.AdjustmentTypeStatic:
+			value, err := convert(adjustment, bidCurrency, conversions)
+			if err != nil {
+				return price, err
+			}
+			if adjustment.Type == openrtb_ext.AdjustmentTypeCPM {
+				price = price - value
+			} else {
+				price = value
+			}
+		}
+	}
+	return price, nil
+}
+
+func convert(adjustment openrtb_ext.Adjustment, bidCurrency string, conversions currency.Conversions) (float64, error) {
+	adjustmentCurrency := adjustment.Currency
+	if adjustmentCurrency == "" {
+		adjustmentCurrency = defaultCurrency
+	}
+	if bidCurrency == "" {
+		bidCurrency = defaultCurrency
+	}
+	rate, err := conversions.GetRate(adjustmentCurrency, bidCurrency)
+	if err != nil {
+		return 0, fmt.Errorf("unable to convert the %s adjustment from %s to %s: %v", adjustment.Type, adjustmentCurrency, bidCurrency, err)
+	}
+	return adjustment.Value * rate, nil
+}
+
+// mediaTypes lists the media type keys of the bid adjustments
+var mediaTypes = []string{
+	string(openrtb_ext.BidTypeBanner),
+	string(openrtb_ext.BidTypeVideo),
+	string(openrtb_ext.BidTypeAudio),
+	string(openrtb_ext.BidTypeNative),
+	openrtb_ext.AdjustmentWildCard,
+}
@@ -0,0 +1,257 @@
+package bidadjustment
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	multiplier := []openrtb_ext.Adjustment{{Type: openrtb_ext.AdjustmentTypeMultiplier, Value: 0.9}}
+	cpm := []openrtb_ext.Adjustment{{Type: openrtb_ext.AdjustmentTypeCPM, Value: 0.1, Currency: "USD"}}
+	invalid := []openrtb_ext.Adjustment{{Type: "unknown", Value: 1}}
+
+	testCases := []struct {
+		description string
+		request     *openrtb_ext.ExtRequestPrebidBidAdjustments
+		account     *openrtb_ext.ExtRequestPrebidBidAdjustments
+		expected    *openrtb_ext.ExtRequestPrebidBidAdjustments
+	}{
+		{
+			description: "No account adjustments",
+			request: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Banner: openrtb_ext.AdjustmentsByBidder{"appnexus": {"*": multiplier}},
+			}},
+			expected: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Banner: openrtb_ext.AdjustmentsByBidder{"appnexus": {"*": multiplier}},
+			}},
+		},
+		{
+			description: "No request adjustments",
+			account: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Video: openrtb_ext.AdjustmentsByBidder{"*": {"*": cpm}},
+			}},
+			expected: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Video: openrtb_ext.AdjustmentsByBidder{"*": {"*": cpm}},
+			}},
+		},
+		{
+			description: "Request overrides account for the same deal",
+			request: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Banner: openrtb_ext.AdjustmentsByBidder{"appnexus": {"deal-1": multiplier}},
+			}},
+			account: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Banner: openrtb_ext.AdjustmentsByBidder{"appnexus": {"deal-1": cpm, "*": cpm}},
+			}},
+			expected: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Banner: openrtb_ext.AdjustmentsByBidder{"appnexus": {"deal-1": multiplier, "*": cpm}},
+			}},
+		},
+		{
+			description: "Invalid account adjustments left out",
+			account: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Native: openrtb_ext.AdjustmentsByBidder{"appnexus": {"*": invalid}, "rubicon": {"*": multiplier}},
+			}},
+			expected: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Native: openrtb_ext.AdjustmentsByBidder{"rubicon": {"*": multiplier}},
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, Merge(test.request, test.account), test.description)
+	}
+}
+
+func TestGet(t *testing.T) {
+	adjustments := &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+		Banner: openrtb_ext.AdjustmentsByBidder{
+			"appnexus": {
+				"deal-1": {{Type: openrtb_ext.AdjustmentTypeMultiplier, Value: 1}},
+				"*":      {{Type: openrtb_ext.AdjustmentTypeMultiplier, Value: 2}},
+			},
+			"*": {
+				"*": {{Type: openrtb_ext.AdjustmentTypeMultiplier, Value: 3}},
+			},
+		},
+		WildCard: openrtb_ext.AdjustmentsByBidder{
+			"rubicon": {
+				"*": {{Type: openrtb_ext.AdjustmentTypeMultiplier, Value: 4}},
+			},
+		},
+	}}
+
+	testCases := []struct {
+		description   string
+		mediaType     openrtb_ext.BidType
+		bidder        openrtb_ext.BidderName
+		dealID        string
+		expectedValue float64
+	}{
+		{
+			description:   "Exact deal",
+			mediaType:     openrtb_ext.BidTypeBanner,
+			bidder:        "appnexus",
+			dealID:        "deal-1",
+			expectedValue: 1,
+		},
+		{
+			description:   "Wildcard deal",
+			mediaType:     openrtb_ext.BidTypeBanner,
+			bidder:        "appnexus",
+			dealID:        "deal-2",
+			expectedValue: 2,
+		},
+		{
+			description:   "No deal",
+			mediaType:     openrtb_ext.BidTypeBanner,
+			bidder:        "appnexus",
+			expectedValue: 2,
+		},
+		{
+			description:   "Wildcard bidder",
+			mediaType:     openrtb_ext.BidTypeBanner,
+			bidder:        "pubmatic",
+			expectedValue: 3,
+		},
+		{
+			description:   "Wildcard media type",
+			mediaType:     openrtb_ext.BidTypeVideo,
+			bidder:        "rubicon",
+			expectedValue: 4,
+		},
+		{
+			description: "No match",
+			mediaType:   openrtb_ext.BidTypeVideo,
+			bidder:      "appnexus",
+		},
+	}
+
+	for _, test := range testCases {
+		result := Get(adjustments, test.mediaType, test.bidder, test.dealID)
+		if test.expectedValue == 0 {
+			assert.Empty(t, result, test.description)
+			continue
+		}
+		if assert.Len(t, result, 1, test.description) {
+			assert.Equal(t, test.expectedValue, result[0].Value, test.description)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	conversions := currency.NewRates(map[string]map[string]float64{"EUR": {"USD": 1.2}})
+
+	testCases := []struct {
+		description   string
+		adjustments   []openrtb_ext.Adjustment
+		bidCurrency   string
+		expectedPrice float64
+		expectedError string
+	}{
+		{
+			description:   "Multiplier",
+			adjustments:   []openrtb_ext.Adjustment{{Type: openrtb_ext.AdjustmentTypeMultiplier, Value: 0.5}},
+			bidCurrency:   "USD",
+			expectedPrice: 1,
+		},
+		{
+			description:   "CPM converted to the bid currency",
+			adjustments:   []openrtb_ext.Adjustment{{Type: openrtb_ext.AdjustmentTypeCPM, Value: 1, Currency: "EUR"}},
+			bidCurrency:   "USD",
+			expectedPrice: 0.8,
+		},
+		{
+			description:   "Static with default currency",
+			adjustments:   []openrtb_ext.Adjustment{{Type: openrtb_ext.AdjustmentTypeStatic, Value: 3}},
+			bidCurrency:   "USD",
+			expectedPrice: 3,
+		},
+		{
+			description: "Applied in order",
+			adjustments: []openrtb_ext.Adjustment{
+				{Type: openrtb_ext.AdjustmentTypeCPM, Value: 1, Currency: "USD"},
+				{Type: openrtb_ext.AdjustmentTypeMultiplier, Value: 3},
+			},
+			bidCurrency:   "USD",
+			expectedPrice: 3,
+		},
+		{
+			description:   "Unknown conversion",
+			adjustments:   []openrtb_ext.Adjustment{{Type: openrtb_ext.AdjustmentTypeCPM, Value: 1, Currency: "JPY"}},
+			bidCurrency:   "USD",
+			expectedPrice: 2,
+			expectedError: "unable to convert the cpm adjustment from JPY to USD: Currency conversion rate not found: 'JPY' => 'USD'",
+		},
+	}
+
+	for _, test := range testCases {
+		price, err := Apply(test.adjustments, 2, test.bidCurrency, conversions)
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+		assert.InDelta(t, test.expectedPrice, price, 0.0001, test.description)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		description   string
+		adjustments   *openrtb_ext.ExtRequestPrebidBidAdjustments
+		expectedError string
+	}{
+		{
+			description: "Nil",
+		},
+		{
+			description: "Valid",
+			adjustments: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Banner: openrtb_ext.AdjustmentsByBidder{"appnexus": {"*": {
+					{Type: openrtb_ext.AdjustmentTypeMultiplier, Value: 1.1},
+					{Type: openrtb_ext.AdjustmentTypeCPM, Value: 0.1, Currency: "USD"},
+				}}},
+			}},
+		},
+		{
+			description: "Unknown type",
+			adjustments: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Video: openrtb_ext.AdjustmentsByBidder{"appnexus": {"*": {{Type: "divider", Value: 2}}}},
+			}},
+			expectedError: `invalid bid adjustment for media type video, bidder appnexus and deal ID *: adjtype "divider" must be one of multiplier, cpm or static`,
+		},
+		{
+			description: "Multiplier too large",
+			adjustments: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				WildCard: openrtb_ext.AdjustmentsByBidder{"*": {"*": {{Type: openrtb_ext.AdjustmentTypeMultiplier, Value: 100}}}},
+			}},
+			expectedError: "invalid bid adjustment for media type *, bidder * and deal ID *: multiplier value 100.000000 must be between 0 and 100",
+		},
+		{
+			description: "Negative static value",
+			adjustments: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Audio: openrtb_ext.AdjustmentsByBidder{"appnexus": {"deal-1": {{Type: openrtb_ext.AdjustmentTypeStatic, Value: -1}}}},
+			}},
+			expectedError: "invalid bid adjustment for media type audio, bidder appnexus and deal ID deal-1: static value -1.000000 must not be negative",
+		},
+		{
+			description: "No adjustment",
+			adjustments: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+				Native: openrtb_ext.AdjustmentsByBidder{"appnexus": {"*": {}}},
+			}},
+			expectedError: "invalid bid adjustment for media type native, bidder appnexus and deal ID *: no adjustment",
+		},
+	}
+
+	for _, test := range testCases {
+		err := Validate(test.adjustments)
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package bidadjustment
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Validate checks the bid adjustments found in the request or the account config.
+func Validate(adjustments *openrtb_ext.ExtRequestPrebidBidAdjustments) error {
+	if adjustments == nil {
+		return nil
+	}
+
+	for _, mediaType := range mediaTypes {
+		for bidder, byDealID := range adjustments.MediaType.ForMediaType(mediaType) {
+			for dealID, dealAdjustments := range byDealID {
+				if err := validateAdjustments(dealAdjustments); err != nil {
+					return fmt.Errorf("invalid bid adjustment for media type %s, bidder %s and deal ID %s: %v", mediaType, bidder, dealID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateAdjustments(adjustments []openrtb_ext.Adjustment) error {
+	if len(adjustments) == 0 {
+		return fmt.Errorf("no adjustment")
+	}
+
+	for _, adjustment := range adjustments {
+		switch adjustment.Type {
+		case openrtb_ext.AdjustmentTypeMultiplier:
+			if adjustment.Value < 0 || adjustment.Value >= maxMultiplier {
+				return fmt.Errorf("multiplier value %f must be between 0 and %.0f", adjustment.Value, maxMultiplier)
+			}
+		case openrtb_ext.AdjustmentTypeCPM, openrtb_ext.AdjustmentTypeStatic:
+			if adjustment.Value < 0 {
+				return fmt.Errorf("%s value %f must not be negative", adjustment.Type, adjustment.Value)
+			}
+		default:
+			return fmt.Errorf("adjtype %q must be one of %s, %s or %s", adjustment.Type, openrtb_ext.AdjustmentTypeMultiplier, openrtb_ext.AdjustmentTypeCPM, openrtb_ext.AdjustmentTypeStatic)
+		}
+	}
+	return nil
+}
@@ -1,5 +1,13 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/util/sliceutil"
+)
+
 // IntegrationType enumerates the values of integrations Prebid Server can configure for an account
 type IntegrationType string
 
@@ -13,13 +21,79 @@ const (
 
 // Account represents a publisher account configuration
 type Account struct {
-	ID            string      `mapstructure:"id" json:"id"`
-	Disabled      bool        `mapstructure:"disabled" json:"disabled"`
-	CacheTTL      DefaultTTLs `mapstructure:"cache_ttl" json:"cache_ttl"`
-	EventsEnabled bool        `mapstructure:"events_enabled" json:"events_enabled"`
-	CCPA          AccountCCPA `mapstructure:"ccpa" json:"ccpa"`
-	GDPR          AccountGDPR `mapstructure:"gdpr" json:"gdpr"`
-	DebugAllow    bool        `mapstructure:"debug_allow" json:"debug_allow"`
+	ID             string                                      `mapstructure:"id" json:"id"`
+	Disabled       bool                                        `mapstructure:"disabled" json:"disabled"`
+	CacheTTL       DefaultTTLs                                 `mapstructure:"cache_ttl" json:"cache_ttl"`
+	EventsEnabled  bool                                        `mapstructure:"events_enabled" json:"events_enabled"`
+	CCPA           AccountCCPA                                 `mapstructure:"ccpa" json:"ccpa"`
+	GDPR           AccountGDPR                                 `mapstructure:"gdpr" json:"gdpr"`
+	DebugAllow     bool                                        `mapstructure:"debug_allow" json:"debug_allow"`
+	PriceFloors    AccountPriceFloors                          `mapstructure:"price_floors" json:"price_floors"`
+	Hooks          AccountHooks                                `mapstructure:"hooks" json:"hooks"`
+	Privacy        AccountPrivacy                              `mapstructure:"privacy" json:"privacy"`
+	Validations    AccountValidations                          `mapstructure:"validations" json:"validations"`
+	BidAdjustments *openrtb_ext.ExtRequestPrebidBidAdjustments `mapstructure:"bidadjustments" json:"bidadjustments,omitempty"`
+	Bidders        AccountBidders                              `mapstructure:"bidders" json:"bidders"`
+	RateLimit      EndpointRateLimits                          `mapstructure:"rate_limit" json:"rate_limit"`
+	CookieSync     AccountCookieSync                           `mapstructure:"cookie_sync" json:"cookie_sync"`
+}
+
+// AccountCookieSync holds the defaults and limits the account applies to its /cookie_sync requests
+type AccountCookieSync struct {
+	// DefaultLimit is the maximum number of syncs returned when the request has no limit. 0 means no limit.
+	DefaultLimit int `mapstructure:"default_limit" json:"default_limit,omitempty"`
+	// MaxLimit caps the limit of the requests. 0 means no cap.
+	MaxLimit int `mapstructure:"max_limit" json:"max_limit,omitempty"`
+	// DefaultCoopSync enables cooperative syncing for the requests which don't say whether to use it. It takes
+	// precedence over user_sync.coop_sync.default.
+	DefaultCoopSync *bool `mapstructure:"default_coop_sync" json:"default_coop_sync,omitempty"`
+	// PriorityGroups are the bidders cooperatively synced after the requested ones, the first group first. They
+	// replace user_sync.coop_sync.priority_groups.
+	PriorityGroups [][]string `mapstructure:"priority_groups" json:"priority_groups,omitempty"`
+}
+
+func (a *AccountCookieSync) validate(errs []error) []error {
+	if a.DefaultLimit < 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.cookie_sync.default_limit must be >= 0. Got %d", a.DefaultLimit))
+	}
+	if a.MaxLimit < 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.cookie_sync.max_limit must be >= 0. Got %d", a.MaxLimit))
+	}
+	for i, group := range a.PriorityGroups {
+		for _, bidder := range group {
+			if bidder == "" {
+				errs = append(errs, fmt.Errorf("account_defaults.cookie_sync.priority_groups[%d] has an empty bidder", i))
+			}
+		}
+	}
+	return errs
+}
+
+// AccountBidders restricts the bidders an account can call, and holds the default params of its bidders
+type AccountBidders struct {
+	// Allowed lists the only bidders the account can call. Every bidder is allowed if it's empty.
+	Allowed []string `mapstructure:"allowed" json:"allowed,omitempty"`
+	// Denied lists the bidders the account can't call. It takes precedence over Allowed.
+	Denied []string `mapstructure:"denied" json:"denied,omitempty"`
+	// Params holds the default params of each bidder. The params of the request take precedence over them.
+	Params map[string]map[string]interface{} `mapstructure:"params" json:"params,omitempty"`
+}
+
+// IsBidderAllowed indicates whether the account can call the bidder. A bidder alias is denied if its core bidder
+// is denied, and allowed if either the alias or its core bidder is allowed.
+func (a *AccountBidders) IsBidderAllowed(bidder string, coreBidder string) bool {
+	if sliceutil.ContainsStringIgnoreCase(a.Denied, bidder) || sliceutil.ContainsStringIgnoreCase(a.Denied, coreBidder) {
+		return false
+	}
+	if len(a.Allowed) == 0 {
+		return true
+	}
+	return sliceutil.ContainsStringIgnoreCase(a.Allowed, bidder) || sliceutil.ContainsStringIgnoreCase(a.Allowed, coreBidder)
+}
+
+// HasControls indicates whether the account restricts its bidders or defines default bidder params.
+func (a *AccountBidders) HasControls() bool {
+	return len(a.Allowed) > 0 || len(a.Denied) > 0 || len(a.Params) > 0
 }
 
 // AccountCCPA represents account-specific CCPA configuration
@@ -79,3 +153,161 @@ func (a *AccountIntegration) GetByIntegrationType(integrationType IntegrationTyp
 
 	return integrationEnabled
 }
+
+// AccountPriceFloors represents account-specific price floors configuration
+type AccountPriceFloors struct {
+	Enabled           bool                        `mapstructure:"enabled" json:"enabled"`
+	EnforceFloorsRate int                         `mapstructure:"enforce_floors_rate" json:"enforce_floors_rate"`
+	EnforceDealFloors bool                        `mapstructure:"enforce_deal_floors" json:"enforce_deal_floors"`
+	UseDynamicData    bool                        `mapstructure:"use_dynamic_data" json:"use_dynamic_data"`
+	Data              *openrtb_ext.PriceFloorData `mapstructure:"data" json:"data,omitempty"`
+	Fetch             AccountFloorFetch           `mapstructure:"fetch" json:"fetch"`
+}
+
+// AccountFloorFetch represents the settings used to fetch an account's price floors data from a remote provider
+type AccountFloorFetch struct {
+	Enabled     bool   `mapstructure:"enabled" json:"enabled"`
+	URL         string `mapstructure:"url" json:"url"`
+	Timeout     int    `mapstructure:"timeout_ms" json:"timeout_ms"`
+	MaxFileSize int    `mapstructure:"max_file_size_kb" json:"max_file_size_kb"`
+	MaxAge      int    `mapstructure:"max_age_sec" json:"max_age_sec"`
+	Period      int    `mapstructure:"period_sec" json:"period_sec"`
+}
+
+func (a *AccountPriceFloors) validate(errs []error) []error {
+	if a.EnforceFloorsRate < 0 || a.EnforceFloorsRate > 100 {
+		errs = append(errs, fmt.Errorf("account_defaults.price_floors.enforce_floors_rate should be between 0 and 100"))
+	}
+	if a.Fetch.Enabled && a.Fetch.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.price_floors.fetch.timeout_ms should be greater than 0"))
+	}
+	return errs
+}
+
+// AccountHooks represents the account-specific settings of the modules plugged into the auction pipeline
+type AccountHooks struct {
+	// ExecutionPlan defines the hooks executed for the account's requests, after the host ones.
+	ExecutionPlan HookExecutionPlan `mapstructure:"execution_plan" json:"execution_plan"`
+}
+
+// AccountPrivacy represents account-specific privacy configuration
+type AccountPrivacy struct {
+	AllowActivities AllowActivities `mapstructure:"allow_activities" json:"allow_activities"`
+}
+
+// AllowActivities holds the controls of the activities which carry a privacy risk, such as sharing user data
+// with a bidder or an analytics module
+type AllowActivities struct {
+	SyncUser           Activity `mapstructure:"sync_user" json:"sync_user"`
+	FetchBids          Activity `mapstructure:"fetch_bids" json:"fetch_bids"`
+	TransmitUfpd       Activity `mapstructure:"transmit_ufpd" json:"transmit_ufpd"`
+	TransmitPreciseGeo Activity `mapstructure:"transmit_precise_geo" json:"transmit_precise_geo"`
+	TransmitEids       Activity `mapstructure:"transmit_eids" json:"transmit_eids"`
+	ReportAnalytics    Activity `mapstructure:"report_analytics" json:"report_analytics"`
+}
+
+// Activity decides whether an activity is allowed. The rules are evaluated in order and the first one matching
+// the request decides, the default applies when none does and allows the activity if not set.
+type Activity struct {
+	Default *bool          `mapstructure:"default" json:"default,omitempty"`
+	Rules   []ActivityRule `mapstructure:"rules" json:"rules,omitempty"`
+}
+
+// ActivityRule allows or denies an activity for the requests matching its condition
+type ActivityRule struct {
+	Condition ActivityCondition `mapstructure:"condition" json:"condition"`
+	Allow     bool              `mapstructure:"allow" json:"allow"`
+}
+
+// ActivityCondition matches a request when each of its non empty lists has a value matching the request. Geos
+// are ISO-3166-1-alpha-3 country codes, optionally followed by a dot and the region of the country.
+type ActivityCondition struct {
+	ComponentName []string `mapstructure:"component_name" json:"component_name,omitempty"`
+	ComponentType []string `mapstructure:"component_type" json:"component_type,omitempty"`
+	GPPSID        []int8   `mapstructure:"gpp_sid" json:"gpp_sid,omitempty"`
+	Geo           []string `mapstructure:"geo" json:"geo,omitempty"`
+}
+
+// ActivityComponentTypes lists the types of the components which perform activities
+var ActivityComponentTypes = []string{"bidder", "analytics"}
+
+func (a *AccountPrivacy) validate(errs []error) []error {
+	activities := []struct {
+		name     string
+		activity Activity
+	}{
+		{"sync_user", a.AllowActivities.SyncUser},
+		{"fetch_bids", a.AllowActivities.FetchBids},
+		{"transmit_ufpd", a.AllowActivities.TransmitUfpd},
+		{"transmit_precise_geo", a.AllowActivities.TransmitPreciseGeo},
+		{"transmit_eids", a.AllowActivities.TransmitEids},
+		{"report_analytics", a.AllowActivities.ReportAnalytics},
+	}
+	for _, a := range activities {
+		for i, rule := range a.activity.Rules {
+			for _, componentType := range rule.Condition.ComponentType {
+				if !isActivityComponentType(componentType) {
+					errs = append(errs, fmt.Errorf("account_defaults.privacy.allow_activities.%s.rules[%d].condition.component_type %q is not one of %v", a.name, i, componentType, ActivityComponentTypes))
+				}
+			}
+			for _, geo := range rule.Condition.Geo {
+				if geo == "" || strings.HasPrefix(geo, ".") || strings.Count(geo, ".") > 1 {
+					errs = append(errs, fmt.Errorf("account_defaults.privacy.allow_activities.%s.rules[%d].condition.geo %q must be a country, optionally followed by a dot and a region", a.name, i, geo))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func isActivityComponentType(componentType string) bool {
+	for _, t := range ActivityComponentTypes {
+		if strings.EqualFold(t, componentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationMode defines how a bid response validation is enforced
+type ValidationMode string
+
+const (
+	// ValidationSkip does not run the validation
+	ValidationSkip ValidationMode = "skip"
+	// ValidationWarn keeps the invalid bids and reports a warning for each of them
+	ValidationWarn ValidationMode = "warn"
+	// ValidationEnforce rejects the invalid bids
+	ValidationEnforce ValidationMode = "enforce"
+)
+
+// AccountValidations represents the account-specific validations of the bids returned by the bidders
+type AccountValidations struct {
+	// BannerCreativeSize checks that the size of banner bids matches one of the formats requested by their imp
+	BannerCreativeSize ValidationMode `mapstructure:"banner_creative_size" json:"banner_creative_size"`
+	// SecureMarkup checks that the bids made for secure imps have no insecure http:// creative
+	SecureMarkup ValidationMode `mapstructure:"secure_markup" json:"secure_markup"`
+}
+
+func (a *AccountValidations) validate(errs []error) []error {
+	if !a.BannerCreativeSize.isValid() {
+		errs = append(errs, fmt.Errorf("account_defaults.validations.banner_creative_size must be one of skip, warn or enforce. Got %q", a.BannerCreativeSize))
+	}
+	if !a.SecureMarkup.isValid() {
+		errs = append(errs, fmt.Errorf("account_defaults.validations.secure_markup must be one of skip, warn or enforce. Got %q", a.SecureMarkup))
+	}
+	return errs
+}
+
+// IsEnabled returns true if the validation runs, either to warn about or to reject the invalid bids
+func (m ValidationMode) IsEnabled() bool {
+	return m == ValidationWarn || m == ValidationEnforce
+}
+
+func (m ValidationMode) isValid() bool {
+	switch m {
+	case "", ValidationSkip, ValidationWarn, ValidationEnforce:
+		return true
+	}
+	return false
+}
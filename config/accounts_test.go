@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -241,3 +242,173 @@ func TestAccountIntegrationGetByIntegrationType(t *testing.T) {
 		}
 	}
 }
+
+func TestAccountPrivacyValidate(t *testing.T) {
+	testCases := []struct {
+		description    string
+		activity       Activity
+		expectedErrors []error
+	}{
+		{
+			description: "Valid",
+			activity: Activity{
+				Rules: []ActivityRule{{Condition: ActivityCondition{ComponentType: []string{"bidder"}, Geo: []string{"USA.CA", "FRA"}}}},
+			},
+		},
+		{
+			description: "Invalid Component Type",
+			activity: Activity{
+				Rules: []ActivityRule{{Condition: ActivityCondition{ComponentType: []string{"module"}}}},
+			},
+			expectedErrors: []error{
+				errors.New(`account_defaults.privacy.allow_activities.sync_user.rules[0].condition.component_type "module" is not one of [bidder analytics]`),
+			},
+		},
+		{
+			description: "Invalid Geo",
+			activity: Activity{
+				Rules: []ActivityRule{{Condition: ActivityCondition{Geo: []string{"USA.CA.LA"}}}},
+			},
+			expectedErrors: []error{
+				errors.New(`account_defaults.privacy.allow_activities.sync_user.rules[0].condition.geo "USA.CA.LA" must be a country, optionally followed by a dot and a region`),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		privacy := AccountPrivacy{AllowActivities: AllowActivities{SyncUser: test.activity}}
+		errs := privacy.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestAccountValidationsValidate(t *testing.T) {
+	testCases := []struct {
+		description    string
+		validations    AccountValidations
+		expectedErrors []error
+	}{
+		{
+			description: "Valid",
+			validations: AccountValidations{BannerCreativeSize: ValidationWarn, SecureMarkup: ValidationEnforce},
+		},
+		{
+			description: "Not Set",
+			validations: AccountValidations{},
+		},
+		{
+			description: "Invalid",
+			validations: AccountValidations{BannerCreativeSize: "reject", SecureMarkup: ValidationSkip},
+			expectedErrors: []error{
+				errors.New(`account_defaults.validations.banner_creative_size must be one of skip, warn or enforce. Got "reject"`),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.validations.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestAccountCookieSyncValidate(t *testing.T) {
+	testCases := []struct {
+		description    string
+		cookieSync     AccountCookieSync
+		expectedErrors []error
+	}{
+		{
+			description: "Valid",
+			cookieSync:  AccountCookieSync{DefaultLimit: 5, MaxLimit: 10, PriorityGroups: [][]string{{"appnexus"}, {"rubicon"}}},
+		},
+		{
+			description: "Not Set",
+			cookieSync:  AccountCookieSync{},
+		},
+		{
+			description: "Invalid",
+			cookieSync:  AccountCookieSync{DefaultLimit: -1, MaxLimit: -2, PriorityGroups: [][]string{{"appnexus", ""}}},
+			expectedErrors: []error{
+				errors.New("account_defaults.cookie_sync.default_limit must be >= 0. Got -1"),
+				errors.New("account_defaults.cookie_sync.max_limit must be >= 0. Got -2"),
+				errors.New("account_defaults.cookie_sync.priority_groups[0] has an empty bidder"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.cookieSync.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestAccountBiddersIsBidderAllowed(t *testing.T) {
+	testCases := []struct {
+		description string
+		bidders     AccountBidders
+		bidder      string
+		coreBidder  string
+		expected    bool
+	}{
+		{
+			description: "No Restrictions",
+			bidders:     AccountBidders{},
+			bidder:      "appnexus",
+			coreBidder:  "appnexus",
+			expected:    true,
+		},
+		{
+			description: "Allowed - Case Insensitive",
+			bidders:     AccountBidders{Allowed: []string{"AppNexus"}},
+			bidder:      "appnexus",
+			coreBidder:  "appnexus",
+			expected:    true,
+		},
+		{
+			description: "Not Allowed",
+			bidders:     AccountBidders{Allowed: []string{"rubicon"}},
+			bidder:      "appnexus",
+			coreBidder:  "appnexus",
+			expected:    false,
+		},
+		{
+			description: "Denied",
+			bidders:     AccountBidders{Denied: []string{"appnexus"}},
+			bidder:      "appnexus",
+			coreBidder:  "appnexus",
+			expected:    false,
+		},
+		{
+			description: "Denied Takes Precedence Over Allowed",
+			bidders:     AccountBidders{Allowed: []string{"appnexus"}, Denied: []string{"appnexus"}},
+			bidder:      "appnexus",
+			coreBidder:  "appnexus",
+			expected:    false,
+		},
+		{
+			description: "Alias - Core Bidder Allowed",
+			bidders:     AccountBidders{Allowed: []string{"appnexus"}},
+			bidder:      "districtm",
+			coreBidder:  "appnexus",
+			expected:    true,
+		},
+		{
+			description: "Alias - Alias Allowed",
+			bidders:     AccountBidders{Allowed: []string{"districtm"}},
+			bidder:      "districtm",
+			coreBidder:  "appnexus",
+			expected:    true,
+		},
+		{
+			description: "Alias - Core Bidder Denied",
+			bidders:     AccountBidders{Allowed: []string{"districtm"}, Denied: []string{"appnexus"}},
+			bidder:      "districtm",
+			coreBidder:  "appnexus",
+			expected:    false,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, test.bidders.IsBidderAllowed(test.bidder, test.coreBidder), test.description)
+	}
+}
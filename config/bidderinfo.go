@@ -21,6 +21,32 @@ type BidderInfo struct {
 	Debug                   *DebugInfo        `yaml:"debug"`
 	GVLVendorID             uint16            `yaml:"gvlVendorID"`
 	Syncer                  *Syncer           `yaml:"userSync"`
+
+	// AliasOf is set by the info files which don't belong to a core bidder. Such a bidder is an alias which
+	// reuses the adapter of the core bidder named here, with its own endpoint, user sync and GVL vendor ID.
+	AliasOf string `yaml:"aliasOf"`
+
+	// EndpointCompression is the encoding of the request bodies sent to the bidder. Only "gzip" is supported.
+	EndpointCompression string `yaml:"endpointCompression"`
+
+	// Transport overrides the settings of the HTTP client shared by the bidders, giving the bidder its own
+	// connection pool.
+	Transport *TransportInfo `yaml:"transport"`
+}
+
+// CompressionGZIP is the EndpointCompression of the bidders which accept gzip request bodies
+const CompressionGZIP = "gzip"
+
+// TransportInfo specifies the HTTP transport settings of a bidder. The settings left out are taken from the
+// http_client config.
+type TransportInfo struct {
+	MaxIdleConns        int `yaml:"maxIdleConnections"`
+	MaxIdleConnsPerHost int `yaml:"maxIdleConnectionsPerHost"`
+	IdleConnTimeout     int `yaml:"idleConnectionTimeoutSeconds"`
+	// HTTP2 enables or disables HTTP/2. It's attempted by default, but only used if the server supports it.
+	HTTP2 *bool `yaml:"http2"`
+	// DialTimeout bounds the time it takes to connect to the bidder, in milliseconds
+	DialTimeout int `yaml:"dialTimeoutMs"`
 }
 
 // MaintainerInfo specifies the support email address for a bidder.
@@ -146,6 +172,8 @@ type SyncerEndpoint struct {
 	//  {{.GDPR}}        - This will be replaced with the "gdpr" property sent to /cookie_sync.
 	//  {{.Consent}}     - This will be replaced with the "consent" property sent to /cookie_sync.
 	//  {{.USPrivacy}}   - This will be replaced with the "us_privacy" property sent to /cookie_sync.
+	//  {{.GPP}}         - This will be replaced with the "gpp" property sent to /cookie_sync.
+	//  {{.GPPSID}}      - This will be replaced with the "gpp_sid" property sent to /cookie_sync.
 	URL string `yaml:"url" mapstructure:"url"`
 
 	// RedirectURL is an endpoint on the host server the user will be redirected to when a user sync
@@ -216,23 +244,108 @@ func loadBidderInfo(r infoReader, adapterConfigs map[string]Adapter, bidders []s
 	infos := BidderInfos{}
 
 	for _, bidder := range bidders {
-		data, err := r.Read(bidder)
+		info, err := readBidderInfo(r, bidder)
 		if err != nil {
 			return nil, err
 		}
 
-		info := BidderInfo{}
-		if err := yaml.Unmarshal(data, &info); err != nil {
-			return nil, fmt.Errorf("error parsing yaml for bidder %s: %v", bidder, err)
+		if info.AliasOf != "" {
+			return nil, fmt.Errorf("bidder %s is a core bidder and cannot be an alias of %s", bidder, info.AliasOf)
 		}
 
 		info.Enabled = isEnabledByConfig(adapterConfigs, bidder)
 		infos[bidder] = info
 	}
 
+	aliasInfos, err := loadAliasBidderInfo(r, adapterConfigs, infos)
+	if err != nil {
+		return nil, err
+	}
+	for alias, info := range aliasInfos {
+		infos[alias] = info
+	}
+
 	return infos, nil
 }
 
+// loadAliasBidderInfo parses the info files which don't belong to a core bidder. Each of them must define the
+// core bidder it is an alias of. The alias takes the maintainer, capabilities and debug settings of its parent
+// when it doesn't define them, and is enabled unless the host disables it or its parent.
+func loadAliasBidderInfo(r infoReader, adapterConfigs map[string]Adapter, coreInfos BidderInfos) (BidderInfos, error) {
+	names, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	aliasInfos := BidderInfos{}
+	for _, alias := range names {
+		if _, isCoreBidder := coreInfos[alias]; isCoreBidder {
+			continue
+		}
+
+		info, err := readBidderInfo(r, alias)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.AliasOf == "" {
+			return nil, fmt.Errorf("bidder info for %s doesn't match a known bidder and doesn't define aliasOf", alias)
+		}
+
+		parentInfo, parentFound := coreInfos[info.AliasOf]
+		if !parentFound {
+			return nil, fmt.Errorf("alias %s references unknown bidder %s", alias, info.AliasOf)
+		}
+
+		if info.Maintainer == nil {
+			info.Maintainer = parentInfo.Maintainer
+		}
+		if info.Capabilities == nil {
+			info.Capabilities = parentInfo.Capabilities
+		}
+		if info.Debug == nil {
+			info.Debug = parentInfo.Debug
+		}
+
+		aliasConfig, aliasConfigFound := adapterConfigs[strings.ToLower(alias)]
+		info.Enabled = parentInfo.Enabled && (!aliasConfigFound || !aliasConfig.Disabled)
+		aliasInfos[alias] = info
+	}
+
+	return aliasInfos, nil
+}
+
+func readBidderInfo(r infoReader, bidder string) (BidderInfo, error) {
+	info := BidderInfo{}
+
+	data, err := r.Read(bidder)
+	if err != nil {
+		return info, err
+	}
+
+	if err := yaml.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("error parsing yaml for bidder %s: %v", bidder, err)
+	}
+
+	if err := validateBidderInfo(info); err != nil {
+		return info, fmt.Errorf("invalid bidder info for %s: %v", bidder, err)
+	}
+
+	return info, nil
+}
+
+func validateBidderInfo(info BidderInfo) error {
+	if info.EndpointCompression != "" && !strings.EqualFold(info.EndpointCompression, CompressionGZIP) {
+		return fmt.Errorf("endpointCompression must be empty or %s. Got %s", CompressionGZIP, info.EndpointCompression)
+	}
+	if t := info.Transport; t != nil {
+		if t.MaxIdleConns < 0 || t.MaxIdleConnsPerHost < 0 || t.IdleConnTimeout < 0 || t.DialTimeout < 0 {
+			return fmt.Errorf("the transport settings must be >= 0")
+		}
+	}
+	return nil
+}
+
 func isEnabledByConfig(adapterConfigs map[string]Adapter, bidderName string) bool {
 	a, ok := adapterConfigs[strings.ToLower(bidderName)]
 	return ok && !a.Disabled
@@ -240,6 +353,8 @@ func isEnabledByConfig(adapterConfigs map[string]Adapter, bidderName string) boo
 
 type infoReader interface {
 	Read(bidder string) ([]byte, error)
+	// List returns the name of every bidder having an info file
+	List() ([]string, error)
 }
 
 type infoReaderFromDisk struct {
@@ -251,6 +366,21 @@ func (r infoReaderFromDisk) Read(bidder string) ([]byte, error) {
 	return ioutil.ReadFile(path)
 }
 
+func (r infoReaderFromDisk) List() ([]string, error) {
+	files, err := ioutil.ReadDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	bidders := make([]string, 0, len(files))
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".yaml") {
+			bidders = append(bidders, strings.TrimSuffix(file.Name(), ".yaml"))
+		}
+	}
+	return bidders, nil
+}
+
 // ToGVLVendorIDMap transforms a BidderInfos object to a map of bidder names to GVL id. Disabled
 // bidders are omitted from the result.
 func (infos BidderInfos) ToGVLVendorIDMap() map[openrtb_ext.BidderName]uint16 {
@@ -67,6 +67,7 @@ func TestLoadBidderInfoFromDisk(t *testing.T) {
 
 func TestLoadBidderInfo(t *testing.T) {
 	bidder := "someBidder" // important to be mixed case for tests
+	falseValue := false
 
 	testCases := []struct {
 		description   string
@@ -144,6 +145,35 @@ func TestLoadBidderInfo(t *testing.T) {
 			givenContent:  "invalid yaml",
 			expectedError: "error parsing yaml for bidder someBidder: yaml: unmarshal errors:\n  line 1: cannot unmarshal !!str `invalid...` into config.BidderInfo",
 		},
+		{
+			description:  "Compression And Transport",
+			givenConfigs: map[string]Adapter{strings.ToLower(bidder): {}},
+			givenContent: "endpointCompression: GZIP\ntransport:\n  maxIdleConnections: 50\n  idleConnectionTimeoutSeconds: 30\n  http2: false\n  dialTimeoutMs: 200",
+			expectedInfo: map[string]BidderInfo{
+				bidder: {
+					Enabled:             true,
+					EndpointCompression: "GZIP",
+					Transport: &TransportInfo{
+						MaxIdleConns:    50,
+						IdleConnTimeout: 30,
+						HTTP2:           &falseValue,
+						DialTimeout:     200,
+					},
+				},
+			},
+		},
+		{
+			description:   "Unsupported Compression",
+			givenConfigs:  map[string]Adapter{strings.ToLower(bidder): {}},
+			givenContent:  "endpointCompression: br",
+			expectedError: "invalid bidder info for someBidder: endpointCompression must be empty or gzip. Got br",
+		},
+		{
+			description:   "Negative Transport Setting",
+			givenConfigs:  map[string]Adapter{strings.ToLower(bidder): {}},
+			givenContent:  "transport:\n  dialTimeoutMs: -1",
+			expectedError: "invalid bidder info for someBidder: the transport settings must be >= 0",
+		},
 	}
 
 	for _, test := range testCases {
@@ -330,6 +360,148 @@ func (r fakeInfoReader) Read(bidder string) ([]byte, error) {
 	return []byte(r.content), r.err
 }
 
+func (r fakeInfoReader) List() ([]string, error) {
+	return nil, nil
+}
+
+type fakeInfoFilesReader struct {
+	files map[string]string
+}
+
+func (r fakeInfoFilesReader) Read(bidder string) ([]byte, error) {
+	return []byte(r.files[bidder]), nil
+}
+
+func (r fakeInfoFilesReader) List() ([]string, error) {
+	bidders := make([]string, 0, len(r.files))
+	for bidder := range r.files {
+		bidders = append(bidders, bidder)
+	}
+	return bidders, nil
+}
+
+func TestLoadBidderInfoAliases(t *testing.T) {
+	parentYAML := `
+maintainer:
+  email: "parent@domain.com"
+capabilities:
+  site:
+    mediaTypes:
+      - banner
+gvlVendorID: 42
+userSync:
+  redirect:
+    url: "https://parent.com/sync"
+    userMacro: "$UID"
+`
+	aliasYAML := `
+aliasOf: "parent"
+gvlVendorID: 43
+userSync:
+  redirect:
+    url: "https://alias.com/sync"
+    userMacro: "$UID"
+`
+	parentCapabilities := &CapabilitiesInfo{Site: &PlatformInfo{MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}}}
+
+	testCases := []struct {
+		description   string
+		givenConfigs  map[string]Adapter
+		givenFiles    map[string]string
+		expectedInfo  BidderInfos
+		expectedError string
+	}{
+		{
+			description:  "Alias inherits maintainer and capabilities but has its own GVL vendor ID and user sync",
+			givenConfigs: map[string]Adapter{"parent": {}},
+			givenFiles:   map[string]string{"parent": parentYAML, "alias": aliasYAML},
+			expectedInfo: BidderInfos{
+				"parent": {
+					Enabled:      true,
+					Maintainer:   &MaintainerInfo{Email: "parent@domain.com"},
+					Capabilities: parentCapabilities,
+					GVLVendorID:  42,
+					Syncer:       &Syncer{Redirect: &SyncerEndpoint{URL: "https://parent.com/sync", UserMacro: "$UID"}},
+				},
+				"alias": {
+					Enabled:      true,
+					Maintainer:   &MaintainerInfo{Email: "parent@domain.com"},
+					Capabilities: parentCapabilities,
+					GVLVendorID:  43,
+					Syncer:       &Syncer{Redirect: &SyncerEndpoint{URL: "https://alias.com/sync", UserMacro: "$UID"}},
+					AliasOf:      "parent",
+				},
+			},
+		},
+		{
+			description:  "Alias disabled by the host",
+			givenConfigs: map[string]Adapter{"parent": {}, "alias": {Disabled: true}},
+			givenFiles:   map[string]string{"parent": parentYAML, "alias": `aliasOf: "parent"`},
+			expectedInfo: BidderInfos{
+				"parent": {
+					Enabled:      true,
+					Maintainer:   &MaintainerInfo{Email: "parent@domain.com"},
+					Capabilities: parentCapabilities,
+					GVLVendorID:  42,
+					Syncer:       &Syncer{Redirect: &SyncerEndpoint{URL: "https://parent.com/sync", UserMacro: "$UID"}},
+				},
+				"alias": {
+					Enabled:      false,
+					Maintainer:   &MaintainerInfo{Email: "parent@domain.com"},
+					Capabilities: parentCapabilities,
+					AliasOf:      "parent",
+				},
+			},
+		},
+		{
+			description:  "Alias of a disabled bidder",
+			givenConfigs: map[string]Adapter{"parent": {Disabled: true}},
+			givenFiles:   map[string]string{"parent": `gvlVendorID: 42`, "alias": `aliasOf: "parent"`},
+			expectedInfo: BidderInfos{
+				"parent": {Enabled: false, GVLVendorID: 42},
+				"alias":  {Enabled: false, AliasOf: "parent"},
+			},
+		},
+		{
+			description:   "Alias of an unknown bidder",
+			givenConfigs:  map[string]Adapter{"parent": {}},
+			givenFiles:    map[string]string{"parent": parentYAML, "alias": `aliasOf: "unknown"`},
+			expectedError: "alias alias references unknown bidder unknown",
+		},
+		{
+			description:   "Alias of an alias",
+			givenConfigs:  map[string]Adapter{"parent": {}},
+			givenFiles:    map[string]string{"parent": parentYAML, "alias": aliasYAML, "aliasOfAlias": `aliasOf: "alias"`},
+			expectedError: "alias aliasOfAlias references unknown bidder alias",
+		},
+		{
+			description:   "Unknown bidder without aliasOf",
+			givenConfigs:  map[string]Adapter{"parent": {}},
+			givenFiles:    map[string]string{"parent": parentYAML, "unknown": `gvlVendorID: 43`},
+			expectedError: "bidder info for unknown doesn't match a known bidder and doesn't define aliasOf",
+		},
+		{
+			description:   "Core bidder with aliasOf",
+			givenConfigs:  map[string]Adapter{"parent": {}},
+			givenFiles:    map[string]string{"parent": `aliasOf: "other"`},
+			expectedError: "bidder parent is a core bidder and cannot be an alias of other",
+		},
+	}
+
+	for _, test := range testCases {
+		r := fakeInfoFilesReader{test.givenFiles}
+		info, err := loadBidderInfo(r, test.givenConfigs, []string{"parent"})
+
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+
+		assert.Equal(t, test.expectedInfo, info, test.description)
+	}
+}
+
 func TestToGVLVendorIDMap(t *testing.T) {
 	givenBidderInfos := BidderInfos{
 		"bidderA": BidderInfo{Enabled: true, GVLVendorID: 0},
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/bidadjustment"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/spf13/viper"
@@ -43,6 +44,8 @@ type Configuration struct {
 	UserSync          UserSync        `mapstructure:"user_sync"`
 	// Note that StoredVideo refers to stored video requests, and has nothing to do with caching video creatives.
 	StoredVideo StoredRequests `mapstructure:"stored_video_req"`
+	// StoredResponses holds the bidder and auction responses which the requests can ask to replay instead of calling the bidders.
+	StoredResponses StoredRequests `mapstructure:"stored_responses"`
 
 	// Adapters should have a key for every openrtb_ext.BidderName, converted to lower-case.
 	// Se also: https://github.com/spf13/viper/issues/371#issuecomment-335388559
@@ -83,6 +86,21 @@ type Configuration struct {
 	AutoGenSourceTID bool `mapstructure:"auto_gen_source_tid"`
 	//When true, new bid id will be generated in seatbid[].bid[].ext.prebid.bidid and used in event urls instead
 	GenerateBidID bool `mapstructure:"generate_bid_id"`
+	// PriceFloors holds the host-level price floors settings
+	PriceFloors PriceFloors `mapstructure:"price_floors"`
+	// Hooks holds the settings of the modules plugged into the auction pipeline
+	Hooks Hooks `mapstructure:"hooks"`
+	// Experiment holds the settings of the features which are not generally available yet
+	Experiment Experiment `mapstructure:"experiment"`
+	// BidderConfigReload holds the settings to reload the bidder configuration without restarting the process
+	BidderConfigReload BidderConfigReload `mapstructure:"bidder_config_reload"`
+	// Tracing holds the settings of the spans exported for the auction requests
+	Tracing Tracing `mapstructure:"tracing"`
+	// CircuitBreaker holds the settings of the breakers which stop calling the failing bidders for a while
+	CircuitBreaker CircuitBreaker `mapstructure:"circuit_breaker"`
+	// RateLimiting holds the limits of the requests to each endpoint. The limits of each account are set
+	// by AccountDefaults.RateLimit.
+	RateLimiting RateLimiting `mapstructure:"rate_limiting"`
 }
 
 const MIN_COOKIE_SIZE_BYTES = 500
@@ -102,6 +120,7 @@ func (cfg *Configuration) validate(v *viper.Viper) []error {
 	errs = cfg.Accounts.validate(errs)
 	errs = cfg.CategoryMapping.validate(errs)
 	errs = cfg.StoredVideo.validate(errs)
+	errs = cfg.StoredResponses.validate(errs)
 	errs = cfg.Metrics.validate(errs)
 	if cfg.MaxRequestSize < 0 {
 		errs = append(errs, fmt.Errorf("cfg.max_request_size must be >= 0. Got %d", cfg.MaxRequestSize))
@@ -111,12 +130,209 @@ func (cfg *Configuration) validate(v *viper.Viper) []error {
 	errs = validateAdapters(cfg.Adapters, errs)
 	errs = cfg.Debug.validate(errs)
 	errs = cfg.ExtCacheURL.validate(errs)
+	errs = cfg.AccountDefaults.PriceFloors.validate(errs)
+	errs = cfg.Hooks.HostExecutionPlan.validate("hooks.host_execution_plan", errs)
+	errs = cfg.AccountDefaults.Hooks.ExecutionPlan.validate("account_defaults.hooks.execution_plan", errs)
+	errs = cfg.AccountDefaults.Privacy.validate(errs)
+	errs = cfg.AccountDefaults.Validations.validate(errs)
+	errs = cfg.AccountDefaults.CookieSync.validate(errs)
+	errs = cfg.Experiment.validate(errs)
+	errs = cfg.BidderConfigReload.validate(errs)
+	errs = cfg.Tracing.validate(errs)
+	errs = cfg.CircuitBreaker.validate(errs)
+	errs = cfg.RateLimiting.validate(errs)
+	errs = cfg.UserSync.validate(errs)
+	errs = cfg.AccountDefaults.RateLimit.validate("account_defaults.rate_limit", errs)
+	if err := bidadjustment.Validate(cfg.AccountDefaults.BidAdjustments); err != nil {
+		errs = append(errs, fmt.Errorf("account_defaults.bidadjustments: %v", err))
+	}
 	if cfg.AccountDefaults.Disabled {
 		glog.Warning(`With account_defaults.disabled=true, host-defined accounts must exist and have "disabled":false. All other requests will be rejected.`)
 	}
 	return errs
 }
 
+// PriceFloors specifies the host-level price floors settings
+type PriceFloors struct {
+	// Enabled turns the price floors feature on for the host. Accounts may still opt out.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// BidderConfigReload specifies how the bidder configuration is reloaded at runtime
+type BidderConfigReload struct {
+	// Enabled exposes the POST /bidders/reload admin endpoint
+	Enabled bool `mapstructure:"enabled"`
+	// WatchFiles reloads the configuration whenever the bidder-info files or the config file change
+	WatchFiles bool `mapstructure:"watch_files"`
+	// DebounceMs is the quiet time to wait after a file change before reloading, so that a batch of changes triggers a single reload
+	DebounceMs int `mapstructure:"debounce_ms"`
+}
+
+func (cfg *BidderConfigReload) validate(errs []error) []error {
+	if cfg.Enabled && cfg.WatchFiles && cfg.DebounceMs < 0 {
+		errs = append(errs, fmt.Errorf("bidder_config_reload.debounce_ms must be >= 0. Got %d", cfg.DebounceMs))
+	}
+	return errs
+}
+
+// Tracing specifies how the /openrtb2 requests are traced. The spans are exported to an OpenTelemetry collector
+// with the OTLP/HTTP protocol.
+type Tracing struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the traces URL of the collector, e.g. http://localhost:4318/v1/traces
+	Endpoint    string `mapstructure:"endpoint"`
+	ServiceName string `mapstructure:"service_name"`
+	// SamplingRatio is the ratio of the requests traced, between 0 and 1. It only applies to the requests
+	// which don't come with a W3C trace context, otherwise the sampling decision of the caller is honoured.
+	SamplingRatio float64 `mapstructure:"sampling_ratio"`
+	// QueueSize is the max number of spans waiting to be exported. The spans ended while the queue is full are dropped.
+	QueueSize int `mapstructure:"queue_size"`
+	// BatchSize is the max number of spans exported per request to the collector
+	BatchSize       int `mapstructure:"batch_size"`
+	FlushIntervalMs int `mapstructure:"flush_interval_ms"`
+	TimeoutMs       int `mapstructure:"timeout_ms"`
+}
+
+func (cfg *Tracing) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("tracing.endpoint is required when tracing is enabled"))
+	}
+	if cfg.SamplingRatio < 0 || cfg.SamplingRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sampling_ratio must be between 0 and 1. Got %f", cfg.SamplingRatio))
+	}
+	if cfg.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("tracing.batch_size must be > 0. Got %d", cfg.BatchSize))
+	}
+	if cfg.QueueSize < cfg.BatchSize {
+		errs = append(errs, fmt.Errorf("tracing.queue_size must be >= tracing.batch_size. Got %d", cfg.QueueSize))
+	}
+	if cfg.FlushIntervalMs <= 0 {
+		errs = append(errs, fmt.Errorf("tracing.flush_interval_ms must be > 0. Got %d", cfg.FlushIntervalMs))
+	}
+	if cfg.TimeoutMs <= 0 {
+		errs = append(errs, fmt.Errorf("tracing.timeout_ms must be > 0. Got %d", cfg.TimeoutMs))
+	}
+	return errs
+}
+
+// CircuitBreaker specifies when a bidder is skipped because too many of its requests recently failed. The
+// breaker of a bidder opens when its error or timeout rate over the sliding window reaches the threshold. The
+// bidder is then skipped for OpenDurationMs, after which a few probe requests are let through. The breaker
+// closes once they all succeed, and opens again as soon as one fails.
+type CircuitBreaker struct {
+	Enabled bool `mapstructure:"enabled"`
+	// WindowMs is the duration of the sliding window, which is split into WindowBuckets buckets
+	WindowMs      int `mapstructure:"window_ms"`
+	WindowBuckets int `mapstructure:"window_buckets"`
+	// MinRequests is the number of requests in the window below which the breaker doesn't open
+	MinRequests          int     `mapstructure:"min_requests"`
+	ErrorRateThreshold   float64 `mapstructure:"error_rate_threshold"`
+	TimeoutRateThreshold float64 `mapstructure:"timeout_rate_threshold"`
+	OpenDurationMs       int     `mapstructure:"open_duration_ms"`
+	// HalfOpenRequests is the number of probe requests let through once the breaker was open for OpenDurationMs
+	HalfOpenRequests int `mapstructure:"half_open_requests"`
+}
+
+func (cfg *CircuitBreaker) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.WindowBuckets <= 0 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.window_buckets must be > 0. Got %d", cfg.WindowBuckets))
+	}
+	if cfg.WindowMs < cfg.WindowBuckets {
+		errs = append(errs, fmt.Errorf("circuit_breaker.window_ms must be >= circuit_breaker.window_buckets. Got %d", cfg.WindowMs))
+	}
+	if cfg.MinRequests <= 0 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.min_requests must be > 0. Got %d", cfg.MinRequests))
+	}
+	if cfg.ErrorRateThreshold <= 0 || cfg.ErrorRateThreshold > 1 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.error_rate_threshold must be > 0 and <= 1. Got %f", cfg.ErrorRateThreshold))
+	}
+	if cfg.TimeoutRateThreshold <= 0 || cfg.TimeoutRateThreshold > 1 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.timeout_rate_threshold must be > 0 and <= 1. Got %f", cfg.TimeoutRateThreshold))
+	}
+	if cfg.OpenDurationMs <= 0 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.open_duration_ms must be > 0. Got %d", cfg.OpenDurationMs))
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.half_open_requests must be > 0. Got %d", cfg.HalfOpenRequests))
+	}
+	return errs
+}
+
+// RateLimiting specifies how the requests to the endpoints are rate limited. Each request must get through
+// the limit of its endpoint, the one of its account and, if set, the one of its client IP.
+type RateLimiting struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoints limits all the requests to each endpoint, whatever their account
+	Endpoints EndpointRateLimits `mapstructure:"endpoints"`
+	// IP limits the requests of each client IP to each endpoint
+	IP EndpointRateLimits `mapstructure:"ip"`
+	// MaxKeys bounds the number of accounts and IPs tracked at once. The idle ones are dropped to make room
+	// for the new ones, and the requests of the accounts and IPs which don't fit aren't limited.
+	MaxKeys int `mapstructure:"max_keys"`
+}
+
+func (cfg *RateLimiting) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	errs = cfg.Endpoints.validate("rate_limiting.endpoints", errs)
+	errs = cfg.IP.validate("rate_limiting.ip", errs)
+	if cfg.MaxKeys <= 0 {
+		errs = append(errs, fmt.Errorf("rate_limiting.max_keys must be > 0. Got %d", cfg.MaxKeys))
+	}
+	return errs
+}
+
+// EndpointRateLimits holds the rate limit of each rate limited endpoint
+type EndpointRateLimits struct {
+	Auction    RateLimit `mapstructure:"auction" json:"auction"`
+	AMP        RateLimit `mapstructure:"amp" json:"amp"`
+	Video      RateLimit `mapstructure:"video" json:"video"`
+	CookieSync RateLimit `mapstructure:"cookie_sync" json:"cookie_sync"`
+}
+
+func (cfg *EndpointRateLimits) validate(prefix string, errs []error) []error {
+	errs = cfg.Auction.validate(prefix+".auction", errs)
+	errs = cfg.AMP.validate(prefix+".amp", errs)
+	errs = cfg.Video.validate(prefix+".video", errs)
+	errs = cfg.CookieSync.validate(prefix+".cookie_sync", errs)
+	return errs
+}
+
+// RateLimit is a token bucket, which lets RequestsPerSecond requests through on average, and up to Burst
+// requests at once. The requests aren't limited if RequestsPerSecond is 0.
+type RateLimit struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second" json:"requests_per_second"`
+	Burst             int     `mapstructure:"burst" json:"burst"`
+}
+
+// IsEnabled indicates whether the requests are limited
+func (cfg RateLimit) IsEnabled() bool {
+	return cfg.RequestsPerSecond > 0
+}
+
+// IsValid indicates whether the limit can be used. The account limits aren't validated at startup,
+// so they're checked when they're looked up.
+func (cfg RateLimit) IsValid() bool {
+	return len(cfg.validate("rate_limit", nil)) == 0
+}
+
+func (cfg *RateLimit) validate(prefix string, errs []error) []error {
+	if cfg.RequestsPerSecond < 0 {
+		errs = append(errs, fmt.Errorf("%s.requests_per_second must be >= 0. Got %f", prefix, cfg.RequestsPerSecond))
+	}
+	if cfg.IsEnabled() && cfg.Burst < 1 {
+		errs = append(errs, fmt.Errorf("%s.burst must be >= 1. Got %d", prefix, cfg.Burst))
+	}
+	return errs
+}
+
 type AuctionTimeouts struct {
 	// The default timeout is used if the user's request didn't define one. Use 0 if there's no default.
 	Default uint64 `mapstructure:"default"`
@@ -678,12 +894,35 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_requests.postgres.poll_for_updates.timeout_ms", 0)
 	v.SetDefault("stored_requests.postgres.poll_for_updates.query", "")
 	v.SetDefault("stored_requests.postgres.poll_for_updates.amp_query", "")
+	v.SetDefault("stored_requests.mysql.connection.dbname", "")
+	v.SetDefault("stored_requests.mysql.connection.host", "")
+	v.SetDefault("stored_requests.mysql.connection.port", 0)
+	v.SetDefault("stored_requests.mysql.connection.user", "")
+	v.SetDefault("stored_requests.mysql.connection.password", "")
+	v.SetDefault("stored_requests.mysql.connection.query_string", "")
+	v.SetDefault("stored_requests.mysql.fetcher.query", "")
+	v.SetDefault("stored_requests.mysql.fetcher.amp_query", "")
+	v.SetDefault("stored_requests.mysql.initialize_caches.timeout_ms", 0)
+	v.SetDefault("stored_requests.mysql.initialize_caches.query", "")
+	v.SetDefault("stored_requests.mysql.initialize_caches.amp_query", "")
+	v.SetDefault("stored_requests.mysql.poll_for_updates.refresh_rate_seconds", 0)
+	v.SetDefault("stored_requests.mysql.poll_for_updates.timeout_ms", 0)
+	v.SetDefault("stored_requests.mysql.poll_for_updates.query", "")
+	v.SetDefault("stored_requests.mysql.poll_for_updates.amp_query", "")
 	v.SetDefault("stored_requests.http.endpoint", "")
 	v.SetDefault("stored_requests.http.amp_endpoint", "")
 	v.SetDefault("stored_requests.in_memory_cache.type", "none")
 	v.SetDefault("stored_requests.in_memory_cache.ttl_seconds", 0)
 	v.SetDefault("stored_requests.in_memory_cache.request_cache_size_bytes", 0)
 	v.SetDefault("stored_requests.in_memory_cache.imp_cache_size_bytes", 0)
+	v.SetDefault("stored_requests.shared_cache.type", "none")
+	v.SetDefault("stored_requests.shared_cache.address", "")
+	v.SetDefault("stored_requests.shared_cache.password", "")
+	v.SetDefault("stored_requests.shared_cache.database", 0)
+	v.SetDefault("stored_requests.shared_cache.key_prefix", "pbs")
+	v.SetDefault("stored_requests.shared_cache.ttl_seconds", 0)
+	v.SetDefault("stored_requests.shared_cache.timeout_ms", 50)
+	v.SetDefault("stored_requests.shared_cache.pool_size", 10)
 	v.SetDefault("stored_requests.cache_events_api", false)
 	v.SetDefault("stored_requests.http_events.endpoint", "")
 	v.SetDefault("stored_requests.http_events.amp_endpoint", "")
@@ -704,16 +943,67 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.refresh_rate_seconds", 0)
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.timeout_ms", 0)
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.query", "")
+	v.SetDefault("stored_video_req.mysql.connection.dbname", "")
+	v.SetDefault("stored_video_req.mysql.connection.host", "")
+	v.SetDefault("stored_video_req.mysql.connection.port", 0)
+	v.SetDefault("stored_video_req.mysql.connection.user", "")
+	v.SetDefault("stored_video_req.mysql.connection.password", "")
+	v.SetDefault("stored_video_req.mysql.connection.query_string", "")
+	v.SetDefault("stored_video_req.mysql.fetcher.query", "")
+	v.SetDefault("stored_video_req.mysql.initialize_caches.timeout_ms", 0)
+	v.SetDefault("stored_video_req.mysql.initialize_caches.query", "")
+	v.SetDefault("stored_video_req.mysql.poll_for_updates.refresh_rate_seconds", 0)
+	v.SetDefault("stored_video_req.mysql.poll_for_updates.timeout_ms", 0)
+	v.SetDefault("stored_video_req.mysql.poll_for_updates.query", "")
 	v.SetDefault("stored_video_req.http.endpoint", "")
 	v.SetDefault("stored_video_req.in_memory_cache.type", "none")
 	v.SetDefault("stored_video_req.in_memory_cache.ttl_seconds", 0)
 	v.SetDefault("stored_video_req.in_memory_cache.request_cache_size_bytes", 0)
 	v.SetDefault("stored_video_req.in_memory_cache.imp_cache_size_bytes", 0)
+	v.SetDefault("stored_video_req.shared_cache.type", "none")
+	v.SetDefault("stored_video_req.shared_cache.address", "")
+	v.SetDefault("stored_video_req.shared_cache.password", "")
+	v.SetDefault("stored_video_req.shared_cache.database", 0)
+	v.SetDefault("stored_video_req.shared_cache.key_prefix", "pbs")
+	v.SetDefault("stored_video_req.shared_cache.ttl_seconds", 0)
+	v.SetDefault("stored_video_req.shared_cache.timeout_ms", 50)
+	v.SetDefault("stored_video_req.shared_cache.pool_size", 10)
 	v.SetDefault("stored_video_req.cache_events.enabled", false)
 	v.SetDefault("stored_video_req.cache_events.endpoint", "")
 	v.SetDefault("stored_video_req.http_events.endpoint", "")
 	v.SetDefault("stored_video_req.http_events.refresh_rate_seconds", 0)
 	v.SetDefault("stored_video_req.http_events.timeout_ms", 0)
+	v.SetDefault("stored_responses.filesystem.enabled", false)
+	v.SetDefault("stored_responses.filesystem.directorypath", "")
+	v.SetDefault("stored_responses.postgres.connection.dbname", "")
+	v.SetDefault("stored_responses.postgres.connection.host", "")
+	v.SetDefault("stored_responses.postgres.connection.port", 0)
+	v.SetDefault("stored_responses.postgres.connection.user", "")
+	v.SetDefault("stored_responses.postgres.connection.password", "")
+	v.SetDefault("stored_responses.postgres.fetcher.query", "")
+	v.SetDefault("stored_responses.postgres.initialize_caches.timeout_ms", 0)
+	v.SetDefault("stored_responses.postgres.initialize_caches.query", "")
+	v.SetDefault("stored_responses.postgres.poll_for_updates.refresh_rate_seconds", 0)
+	v.SetDefault("stored_responses.postgres.poll_for_updates.timeout_ms", 0)
+	v.SetDefault("stored_responses.postgres.poll_for_updates.query", "")
+	v.SetDefault("stored_responses.http.endpoint", "")
+	v.SetDefault("stored_responses.in_memory_cache.type", "none")
+	v.SetDefault("stored_responses.in_memory_cache.ttl_seconds", 0)
+	v.SetDefault("stored_responses.in_memory_cache.request_cache_size_bytes", 0)
+	v.SetDefault("stored_responses.in_memory_cache.imp_cache_size_bytes", 0)
+	v.SetDefault("stored_responses.shared_cache.type", "none")
+	v.SetDefault("stored_responses.shared_cache.address", "")
+	v.SetDefault("stored_responses.shared_cache.password", "")
+	v.SetDefault("stored_responses.shared_cache.database", 0)
+	v.SetDefault("stored_responses.shared_cache.key_prefix", "pbs")
+	v.SetDefault("stored_responses.shared_cache.ttl_seconds", 0)
+	v.SetDefault("stored_responses.shared_cache.timeout_ms", 50)
+	v.SetDefault("stored_responses.shared_cache.pool_size", 10)
+	v.SetDefault("stored_responses.cache_events.enabled", false)
+	v.SetDefault("stored_responses.cache_events.endpoint", "")
+	v.SetDefault("stored_responses.http_events.endpoint", "")
+	v.SetDefault("stored_responses.http_events.refresh_rate_seconds", 0)
+	v.SetDefault("stored_responses.http_events.timeout_ms", 0)
 
 	v.SetDefault("vtrack.timeout_ms", 2000)
 	v.SetDefault("vtrack.allow_unknown_bidder", true)
@@ -723,11 +1013,39 @@ func SetupViper(v *viper.Viper, filename string) {
 
 	v.SetDefault("accounts.filesystem.enabled", false)
 	v.SetDefault("accounts.filesystem.directorypath", "./stored_requests/data/by_id")
+	v.SetDefault("accounts.mysql.connection.dbname", "")
+	v.SetDefault("accounts.mysql.connection.host", "")
+	v.SetDefault("accounts.mysql.connection.port", 0)
+	v.SetDefault("accounts.mysql.connection.user", "")
+	v.SetDefault("accounts.mysql.connection.password", "")
+	v.SetDefault("accounts.mysql.connection.query_string", "")
+	v.SetDefault("accounts.mysql.fetcher.query", "")
+	v.SetDefault("accounts.mysql.initialize_caches.timeout_ms", 0)
+	v.SetDefault("accounts.mysql.initialize_caches.query", "")
+	v.SetDefault("accounts.mysql.poll_for_updates.refresh_rate_seconds", 0)
+	v.SetDefault("accounts.mysql.poll_for_updates.timeout_ms", 0)
+	v.SetDefault("accounts.mysql.poll_for_updates.query", "")
 	v.SetDefault("accounts.in_memory_cache.type", "none")
+	v.SetDefault("accounts.shared_cache.type", "none")
+	v.SetDefault("accounts.shared_cache.address", "")
+	v.SetDefault("accounts.shared_cache.password", "")
+	v.SetDefault("accounts.shared_cache.database", 0)
+	v.SetDefault("accounts.shared_cache.key_prefix", "pbs")
+	v.SetDefault("accounts.shared_cache.ttl_seconds", 0)
+	v.SetDefault("accounts.shared_cache.timeout_ms", 50)
+	v.SetDefault("accounts.shared_cache.pool_size", 10)
 
 	// some adapters append the user id to the end of the redirect url instead of using
 	// macro substitution. it is important for the uid to be the last query parameter.
 	v.SetDefault("user_sync.redirect_url", "{{.ExternalURL}}/setuid?bidder={{.SyncerKey}}&gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}&f={{.SyncType}}&uid={{.UserMacro}}")
+	v.SetDefault("user_sync.uid_store.type", "none")
+	v.SetDefault("user_sync.uid_store.ttl_seconds", 1209600)
+	v.SetDefault("user_sync.uid_store.address", "")
+	v.SetDefault("user_sync.uid_store.password", "")
+	v.SetDefault("user_sync.uid_store.database", 0)
+	v.SetDefault("user_sync.uid_store.key_prefix", "pbs")
+	v.SetDefault("user_sync.uid_store.timeout_ms", 50)
+	v.SetDefault("user_sync.uid_store.pool_size", 10)
 
 	for _, bidder := range openrtb_ext.CoreBidderNames() {
 		setBidderDefaults(v, strings.ToLower(string(bidder)))
@@ -935,9 +1253,71 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("account_required", false)
 	v.SetDefault("account_defaults.disabled", false)
 	v.SetDefault("account_defaults.debug_allow", true)
+	v.SetDefault("account_defaults.price_floors.enabled", true)
+	v.SetDefault("account_defaults.price_floors.enforce_floors_rate", 100)
+	v.SetDefault("account_defaults.price_floors.enforce_deal_floors", false)
+	v.SetDefault("account_defaults.price_floors.use_dynamic_data", false)
+	v.SetDefault("account_defaults.price_floors.fetch.enabled", false)
+	v.SetDefault("account_defaults.price_floors.fetch.url", "")
+	v.SetDefault("account_defaults.price_floors.fetch.timeout_ms", 3000)
+	v.SetDefault("account_defaults.price_floors.fetch.max_file_size_kb", 100)
+	v.SetDefault("account_defaults.price_floors.fetch.max_age_sec", 86400)
+	v.SetDefault("account_defaults.price_floors.fetch.period_sec", 3600)
+	v.SetDefault("account_defaults.validations.banner_creative_size", "skip")
+	v.SetDefault("account_defaults.validations.secure_markup", "skip")
+	v.SetDefault("account_defaults.rate_limit.auction.requests_per_second", 0)
+	v.SetDefault("account_defaults.rate_limit.auction.burst", 0)
+	v.SetDefault("account_defaults.rate_limit.amp.requests_per_second", 0)
+	v.SetDefault("account_defaults.rate_limit.amp.burst", 0)
+	v.SetDefault("account_defaults.rate_limit.video.requests_per_second", 0)
+	v.SetDefault("account_defaults.rate_limit.video.burst", 0)
+	v.SetDefault("account_defaults.rate_limit.cookie_sync.requests_per_second", 0)
+	v.SetDefault("account_defaults.rate_limit.cookie_sync.burst", 0)
 	v.SetDefault("certificates_file", "")
 	v.SetDefault("auto_gen_source_tid", true)
 	v.SetDefault("generate_bid_id", false)
+	v.SetDefault("price_floors.enabled", false)
+	v.SetDefault("hooks.enabled", false)
+	v.SetDefault("bidder_config_reload.enabled", false)
+	v.SetDefault("bidder_config_reload.watch_files", false)
+	v.SetDefault("bidder_config_reload.debounce_ms", 500)
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.endpoint", "")
+	v.SetDefault("tracing.service_name", "prebid-server")
+	v.SetDefault("tracing.sampling_ratio", 0.01)
+	v.SetDefault("tracing.queue_size", 2048)
+	v.SetDefault("tracing.batch_size", 512)
+	v.SetDefault("tracing.flush_interval_ms", 5000)
+	v.SetDefault("tracing.timeout_ms", 10000)
+	v.SetDefault("circuit_breaker.enabled", false)
+	v.SetDefault("circuit_breaker.window_ms", 10000)
+	v.SetDefault("circuit_breaker.window_buckets", 10)
+	v.SetDefault("circuit_breaker.min_requests", 20)
+	v.SetDefault("circuit_breaker.error_rate_threshold", 0.5)
+	v.SetDefault("circuit_breaker.timeout_rate_threshold", 0.5)
+	v.SetDefault("circuit_breaker.open_duration_ms", 30000)
+	v.SetDefault("circuit_breaker.half_open_requests", 3)
+	v.SetDefault("rate_limiting.enabled", false)
+	v.SetDefault("rate_limiting.max_keys", 100000)
+	v.SetDefault("rate_limiting.endpoints.auction.requests_per_second", 0)
+	v.SetDefault("rate_limiting.endpoints.auction.burst", 0)
+	v.SetDefault("rate_limiting.endpoints.amp.requests_per_second", 0)
+	v.SetDefault("rate_limiting.endpoints.amp.burst", 0)
+	v.SetDefault("rate_limiting.endpoints.video.requests_per_second", 0)
+	v.SetDefault("rate_limiting.endpoints.video.burst", 0)
+	v.SetDefault("rate_limiting.endpoints.cookie_sync.requests_per_second", 0)
+	v.SetDefault("rate_limiting.endpoints.cookie_sync.burst", 0)
+	v.SetDefault("rate_limiting.ip.auction.requests_per_second", 0)
+	v.SetDefault("rate_limiting.ip.auction.burst", 0)
+	v.SetDefault("rate_limiting.ip.amp.requests_per_second", 0)
+	v.SetDefault("rate_limiting.ip.amp.burst", 0)
+	v.SetDefault("rate_limiting.ip.video.requests_per_second", 0)
+	v.SetDefault("rate_limiting.ip.video.burst", 0)
+	v.SetDefault("rate_limiting.ip.cookie_sync.requests_per_second", 0)
+	v.SetDefault("rate_limiting.ip.cookie_sync.burst", 0)
+	v.SetDefault("experiment.adscert.enabled", false)
+	v.SetDefault("experiment.adscert.remote.url", "")
+	v.SetDefault("experiment.adscert.remote.signing_timeout_ms", 5)
 
 	v.SetDefault("request_timeout_headers.request_time_in_queue", "")
 	v.SetDefault("request_timeout_headers.request_timeout_in_queue", "")
@@ -627,6 +627,12 @@ func TestValidateConfig(t *testing.T) {
 				Type: "none",
 			},
 		},
+		StoredResponses: StoredRequests{
+			Files: FileFetcherConfig{Enabled: true},
+			InMemoryCache: InMemoryCache{
+				Type: "none",
+			},
+		},
 		CategoryMapping: StoredRequests{
 			Files: FileFetcherConfig{Enabled: true},
 		},
@@ -931,6 +937,121 @@ func TestValidateAccountsConfigRestrictions(t *testing.T) {
 	assert.Contains(t, errs, errors.New("accounts.postgres: retrieving accounts via postgres not available, use accounts.files"))
 }
 
+func TestValidateTracing(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.Tracing.Enabled = true
+	cfg.Tracing.SamplingRatio = 1.5
+	cfg.Tracing.QueueSize = 10
+
+	errs := cfg.validate(v)
+	assert.ElementsMatch(t, []error{
+		errors.New("tracing.endpoint is required when tracing is enabled"),
+		errors.New("tracing.sampling_ratio must be between 0 and 1. Got 1.500000"),
+		errors.New("tracing.queue_size must be >= tracing.batch_size. Got 10"),
+	}, errs)
+}
+
+func TestValidateCircuitBreaker(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.ErrorRateThreshold = 0
+	cfg.CircuitBreaker.HalfOpenRequests = 0
+
+	errs := cfg.validate(v)
+	assert.ElementsMatch(t, []error{
+		errors.New("circuit_breaker.error_rate_threshold must be > 0 and <= 1. Got 0.000000"),
+		errors.New("circuit_breaker.half_open_requests must be > 0. Got 0"),
+	}, errs)
+}
+
+func TestValidateRateLimiting(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.RateLimiting.Enabled = true
+	cfg.RateLimiting.MaxKeys = 0
+	cfg.RateLimiting.Endpoints.Auction = RateLimit{RequestsPerSecond: 100, Burst: 0}
+	cfg.RateLimiting.IP.AMP = RateLimit{RequestsPerSecond: -1}
+	cfg.AccountDefaults.RateLimit.Video = RateLimit{RequestsPerSecond: 10, Burst: 0}
+
+	errs := cfg.validate(v)
+	assert.ElementsMatch(t, []error{
+		errors.New("rate_limiting.max_keys must be > 0. Got 0"),
+		errors.New("rate_limiting.endpoints.auction.burst must be >= 1. Got 0"),
+		errors.New("rate_limiting.ip.amp.requests_per_second must be >= 0. Got -1.000000"),
+		errors.New("account_defaults.rate_limit.video.burst must be >= 1. Got 0"),
+	}, errs)
+}
+
+func TestValidateUIDStore(t *testing.T) {
+	testCases := []struct {
+		description    string
+		uidStore       UIDStore
+		expectedErrors []error
+	}{
+		{
+			description: "Memory",
+			uidStore:    UIDStore{Type: "memory", TTL: 60},
+		},
+		{
+			description: "Redis",
+			uidStore:    UIDStore{Type: "redis", TTL: 60, Address: "localhost:6379", Timeout: 50},
+		},
+		{
+			description: "Redis without address",
+			uidStore:    UIDStore{Type: "redis", TTL: 0, Timeout: 0, Database: -1},
+			expectedErrors: []error{
+				errors.New("user_sync.uid_store.address is required when user_sync.uid_store.type=redis"),
+				errors.New("user_sync.uid_store.timeout_ms must be > 0 when user_sync.uid_store.type=redis. Got 0"),
+				errors.New("user_sync.uid_store.database must be >= 0. Got -1"),
+				errors.New("user_sync.uid_store.ttl_seconds must be > 0. Got 0"),
+			},
+		},
+		{
+			description:    "Invalid type",
+			uidStore:       UIDStore{Type: "mysql"},
+			expectedErrors: []error{errors.New("user_sync.uid_store.type mysql is invalid")},
+		},
+	}
+
+	for _, test := range testCases {
+		cfg, v := newDefaultConfig(t)
+		cfg.UserSync.UIDStore = test.uidStore
+
+		errs := cfg.validate(v)
+		assert.ElementsMatch(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateUIDPriorityGroups(t *testing.T) {
+	testCases := []struct {
+		description    string
+		priorityGroups [][]string
+		expectedErrors []error
+	}{
+		{
+			description:    "Valid",
+			priorityGroups: [][]string{{"appnexus", "rubicon"}, {"pubmatic"}},
+		},
+		{
+			description:    "Empty bidder",
+			priorityGroups: [][]string{{"appnexus"}, {""}},
+			expectedErrors: []error{errors.New("user_sync.uid_priority_groups[1] has an empty bidder")},
+		},
+		{
+			description:    "Bidder in two groups",
+			priorityGroups: [][]string{{"appnexus"}, {"rubicon", "appnexus"}},
+			expectedErrors: []error{errors.New("user_sync.uid_priority_groups has the bidder appnexus in both groups 0 and 1")},
+		},
+	}
+
+	for _, test := range testCases {
+		cfg, v := newDefaultConfig(t)
+		cfg.UserSync.UIDPriorityGroups = test.priorityGroups
+
+		errs := cfg.validate(v)
+		assert.ElementsMatch(t, test.expectedErrors, errs, test.description)
+	}
+}
+
 func TestUserSyncFromEnv(t *testing.T) {
 	truePtr := true
 
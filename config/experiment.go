@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Experiment holds the settings of the features which are not generally available yet
+type Experiment struct {
+	AdCerts ExperimentAdsCert `mapstructure:"adscert"`
+}
+
+// ExperimentAdsCert configures the signing of the outgoing bidder requests with the ads.cert Authenticated
+// Connections protocol. Requests are signed only when the host enables it and the request asks for it in
+// ext.prebid.experiment.adscert.enabled.
+type ExperimentAdsCert struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Remote is the signatory sidecar holding the origin domain and the private key used to sign the requests.
+	Remote AdsCertRemote `mapstructure:"remote"`
+}
+
+// AdsCertRemote configures the signatory sidecar which generates the X-Ads-Cert-Auth header values
+type AdsCertRemote struct {
+	// Url is the endpoint of the signatory sidecar, e.g. http://localhost:3000/sign
+	Url string `mapstructure:"url"`
+	// SigningTimeoutMs is the time given to the sidecar to sign a request
+	SigningTimeoutMs int `mapstructure:"signing_timeout_ms"`
+}
+
+func (cfg *Experiment) validate(errs []error) []error {
+	return cfg.AdCerts.validate(errs)
+}
+
+func (cfg *ExperimentAdsCert) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if u, err := url.ParseRequestURI(cfg.Remote.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		errs = append(errs, fmt.Errorf("experiment.adscert.remote.url must be a valid http or https url. Got %s", cfg.Remote.Url))
+	}
+	if cfg.Remote.SigningTimeoutMs <= 0 {
+		errs = append(errs, fmt.Errorf("experiment.adscert.remote.signing_timeout_ms must be positive. Got %d", cfg.Remote.SigningTimeoutMs))
+	}
+	return errs
+}
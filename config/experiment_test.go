@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExperimentValidate(t *testing.T) {
+	testCases := []struct {
+		description  string
+		data         Experiment
+		expectErrors []error
+	}{
+		{
+			description: "Ads.cert disabled",
+			data:        Experiment{AdCerts: ExperimentAdsCert{Enabled: false}},
+		},
+		{
+			description: "Ads.cert enabled with a valid remote signatory",
+			data: Experiment{AdCerts: ExperimentAdsCert{
+				Enabled: true,
+				Remote:  AdsCertRemote{Url: "http://localhost:3000/sign", SigningTimeoutMs: 5},
+			}},
+		},
+		{
+			description: "Ads.cert enabled with an invalid remote url",
+			data: Experiment{AdCerts: ExperimentAdsCert{
+				Enabled: true,
+				Remote:  AdsCertRemote{Url: "localhost:3000", SigningTimeoutMs: 5},
+			}},
+			expectErrors: []error{
+				errors.New("experiment.adscert.remote.url must be a valid http or https url. Got localhost:3000"),
+			},
+		},
+		{
+			description: "Ads.cert enabled without remote url and timeout",
+			data:        Experiment{AdCerts: ExperimentAdsCert{Enabled: true}},
+			expectErrors: []error{
+				errors.New("experiment.adscert.remote.url must be a valid http or https url. Got "),
+				errors.New("experiment.adscert.remote.signing_timeout_ms must be positive. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.data.validate(nil)
+		assert.Equal(t, test.expectErrors, errs, test.description)
+	}
+}
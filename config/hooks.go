@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+)
+
+// Hooks specifies the host-level settings of the modules plugged into the auction pipeline
+type Hooks struct {
+	// Enabled turns the execution of module hooks on. Without it, no module is built.
+	Enabled bool `mapstructure:"enabled"`
+	// Modules holds the configuration of each module, keyed by vendor and then by module name.
+	// A module is built only if its configuration has "enabled": true.
+	Modules map[string]map[string]interface{} `mapstructure:"modules"`
+	// HostExecutionPlan defines the hooks executed for every request, before the account ones.
+	HostExecutionPlan HookExecutionPlan `mapstructure:"host_execution_plan"`
+}
+
+// HookExecutionPlan defines the hooks executed for each endpoint and stage
+type HookExecutionPlan struct {
+	// Endpoints is keyed by the endpoint path, e.g. "/openrtb2/auction"
+	Endpoints map[string]HookExecutionEndpoint `mapstructure:"endpoints" json:"endpoints,omitempty"`
+}
+
+// HookExecutionEndpoint defines the hooks executed for an endpoint, keyed by the stage name
+type HookExecutionEndpoint struct {
+	Stages map[string]HookExecutionStage `mapstructure:"stages" json:"stages,omitempty"`
+}
+
+// HookExecutionStage defines the groups of hooks executed for a stage. Groups are executed one after the
+// other while the hooks of a group are executed in parallel.
+type HookExecutionStage struct {
+	Groups []HookExecutionGroup `mapstructure:"groups" json:"groups,omitempty"`
+}
+
+// HookExecutionGroup is a set of hooks executed in parallel
+type HookExecutionGroup struct {
+	// Timeout is the time in milliseconds given to the hooks of the group to return.
+	Timeout      int                 `mapstructure:"timeout" json:"timeout"`
+	HookSequence []HookExecutionStep `mapstructure:"hook_sequence" json:"hook_sequence"`
+}
+
+// HookExecutionStep references a hook of a module
+type HookExecutionStep struct {
+	ModuleCode   string `mapstructure:"module_code" json:"module_code"`
+	HookImplCode string `mapstructure:"hook_impl_code" json:"hook_impl_code"`
+}
+
+func (plan *HookExecutionPlan) validate(prefix string, errs []error) []error {
+	for endpoint, endpointPlan := range plan.Endpoints {
+		for stage, stagePlan := range endpointPlan.Stages {
+			for i, group := range stagePlan.Groups {
+				groupPrefix := fmt.Sprintf("%s.endpoints.%s.stages.%s.groups[%d]", prefix, endpoint, stage, i)
+				if group.Timeout <= 0 {
+					errs = append(errs, fmt.Errorf("%s.timeout must be positive. Got %d", groupPrefix, group.Timeout))
+				}
+				for j, step := range group.HookSequence {
+					if step.ModuleCode == "" || step.HookImplCode == "" {
+						errs = append(errs, fmt.Errorf("%s.hook_sequence[%d] must define both module_code and hook_impl_code", groupPrefix, j))
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
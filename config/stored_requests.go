@@ -19,6 +19,7 @@ const (
 	VideoDataType      DataType = "Video"
 	AMPRequestDataType DataType = "AMP Request"
 	AccountDataType    DataType = "Account"
+	ResponseDataType   DataType = "Response"
 )
 
 // Section returns the config section this type is defined in
@@ -29,6 +30,7 @@ func (dataType DataType) Section() string {
 		VideoDataType:      "stored_video_req",
 		AMPRequestDataType: "stored_amp_req",
 		AccountDataType:    "accounts",
+		ResponseDataType:   "stored_responses",
 	}[dataType]
 }
 
@@ -59,6 +61,10 @@ type StoredRequests struct {
 	// Fetchers are in stored_requests/backends/db_fetcher/postgres.go
 	// EventProducers are in stored_requests/events/postgres
 	Postgres PostgresConfig `mapstructure:"postgres"`
+	// MySql configures Fetchers and EventProducers which read from a MySQL DB.
+	// Fetchers are in stored_requests/backends/db_fetcher/fetcher.go
+	// EventProducers are in stored_requests/events/mysql
+	MySql MySqlConfig `mapstructure:"mysql"`
 	// HTTP configures an instance of stored_requests/backends/http/http_fetcher.go.
 	// If non-nil, Stored Requests will be fetched from the endpoint described there.
 	HTTP HTTPFetcherConfig `mapstructure:"http"`
@@ -71,6 +77,9 @@ type StoredRequests struct {
 	// HTTPEvents configures an instance of stored_requests/events/http/http.go.
 	// If non-nil, the server will use those endpoints to populate and update the cache.
 	HTTPEvents HTTPEventsConfig `mapstructure:"http_events"`
+	// SharedCache configures an instance of stored_requests/caches/redis/cache.go.
+	// If enabled, it is stacked behind the in-memory cache and shared by every Prebid Server instance.
+	SharedCache SharedCache `mapstructure:"shared_cache"`
 }
 
 // HTTPEventsConfig configures stored_requests/events/http/http.go
@@ -123,6 +132,9 @@ func resolvedStoredRequestsConfig(cfg *Configuration) {
 	amp.Postgres.FetcherQueries.QueryTemplate = sr.Postgres.FetcherQueries.AmpQueryTemplate
 	amp.Postgres.CacheInitialization.Query = sr.Postgres.CacheInitialization.AmpQuery
 	amp.Postgres.PollUpdates.Query = sr.Postgres.PollUpdates.AmpQuery
+	amp.MySql.FetcherQueries.QueryTemplate = sr.MySql.FetcherQueries.AmpQueryTemplate
+	amp.MySql.CacheInitialization.Query = sr.MySql.CacheInitialization.AmpQuery
+	amp.MySql.PollUpdates.Query = sr.MySql.PollUpdates.AmpQuery
 	amp.HTTP.Endpoint = sr.HTTP.AmpEndpoint
 	amp.CacheEvents.Endpoint = "/storedrequests/amp"
 	amp.HTTPEvents.Endpoint = sr.HTTPEvents.AmpEndpoint
@@ -133,6 +145,7 @@ func resolvedStoredRequestsConfig(cfg *Configuration) {
 	cfg.StoredVideo.dataType = VideoDataType
 	cfg.CategoryMapping.dataType = CategoryDataType
 	cfg.Accounts.dataType = AccountDataType
+	cfg.StoredResponses.dataType = ResponseDataType
 	return
 }
 
@@ -142,6 +155,11 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 	} else {
 		errs = cfg.Postgres.validate(cfg.DataType(), errs)
 	}
+	errs = cfg.MySql.validate(cfg.DataType(), errs)
+
+	if cfg.Postgres.ConnectionInfo.Database != "" && cfg.MySql.ConnectionInfo.Database != "" {
+		errs = append(errs, fmt.Errorf("%s: postgres and mysql cannot be used at the same time", cfg.Section()))
+	}
 
 	// Categories do not use cache so none of the following checks apply
 	if cfg.DataType() == CategoryDataType {
@@ -163,8 +181,15 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 		if cfg.Postgres.CacheInitialization.Query != "" {
 			errs = append(errs, fmt.Errorf("%s: postgres.initialize_caches.query must be empty if in_memory_cache=none", cfg.Section()))
 		}
+		if cfg.MySql.PollUpdates.Query != "" {
+			errs = append(errs, fmt.Errorf("%s: mysql.poll_for_updates.query must be empty if in_memory_cache=none", cfg.Section()))
+		}
+		if cfg.MySql.CacheInitialization.Query != "" {
+			errs = append(errs, fmt.Errorf("%s: mysql.initialize_caches.query must be empty if in_memory_cache=none", cfg.Section()))
+		}
 	}
 	errs = cfg.InMemoryCache.validate(cfg.DataType(), errs)
+	errs = cfg.SharedCache.validate(cfg.DataType(), errs)
 	return errs
 }
 
@@ -263,6 +288,14 @@ type PostgresFetcherQueries struct {
 	AmpQueryTemplate string `mapstructure:"amp_query"`
 }
 
+// MakeQueryResponses builds a query which can fetch numIds Stored Responses.
+// The QueryTemplate lists them with a %RESPONSE_ID_LIST% placeholder, such as "WHERE id in %RESPONSE_ID_LIST%",
+// and should return the rows with a 'response' type.
+func (cfg *PostgresFetcherQueries) MakeQueryResponses(numIds int) (query string) {
+	numIds = ensureNonNegative("Response", numIds)
+	return strings.Replace(cfg.QueryTemplate, "%RESPONSE_ID_LIST%", makeIdList(0, numIds), -1)
+}
+
 type PostgresCacheInitializer struct {
 	Timeout int `mapstructure:"timeout_ms"`
 	// Query should be something like:
@@ -390,6 +423,221 @@ func makeIdList(numSoFar int, numArgs int) string {
 	return final.String()
 }
 
+// MySqlConfig configures the Stored Request ecosystem to use MySQL. This must include a Fetcher,
+// and may optionally include some EventProducers to populate and refresh the caches.
+//
+// The queries work like the Postgres ones, except that MySQL uses "?" placeholders instead of "$1", "$2", etc.
+type MySqlConfig struct {
+	ConnectionInfo      MySqlConnection       `mapstructure:"connection"`
+	FetcherQueries      MySqlFetcherQueries   `mapstructure:"fetcher"`
+	CacheInitialization MySqlCacheInitializer `mapstructure:"initialize_caches"`
+	PollUpdates         MySqlUpdatePolling    `mapstructure:"poll_for_updates"`
+}
+
+func (cfg *MySqlConfig) validate(dataType DataType, errs []error) []error {
+	if cfg.ConnectionInfo.Database == "" {
+		return errs
+	}
+
+	errs = cfg.CacheInitialization.validate(dataType, errs)
+	errs = cfg.PollUpdates.validate(dataType, errs)
+	return errs
+}
+
+// MySqlConnection has options which put types to the MySQL data source name. See:
+// https://github.com/go-sql-driver/mysql#dsn-data-source-name
+type MySqlConnection struct {
+	Database string `mapstructure:"dbname"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+	// QueryString holds the extra DSN parameters, such as "tls=true&timeout=1s".
+	QueryString string `mapstructure:"query_string"`
+}
+
+// ConnString returns the data source name, formatted as "user:password@tcp(host:port)/dbname?query_string".
+func (cfg *MySqlConnection) ConnString() string {
+	buffer := bytes.NewBuffer(nil)
+
+	if cfg.Username != "" {
+		buffer.WriteString(cfg.Username)
+		if cfg.Password != "" {
+			buffer.WriteString(":")
+			buffer.WriteString(cfg.Password)
+		}
+		buffer.WriteString("@")
+	}
+
+	if cfg.Host != "" {
+		buffer.WriteString("tcp(")
+		buffer.WriteString(cfg.Host)
+		if cfg.Port > 0 {
+			buffer.WriteString(":")
+			buffer.WriteString(strconv.Itoa(cfg.Port))
+		}
+		buffer.WriteString(")")
+	}
+
+	buffer.WriteString("/")
+	buffer.WriteString(cfg.Database)
+
+	if cfg.QueryString != "" {
+		buffer.WriteString("?")
+		buffer.WriteString(cfg.QueryString)
+	}
+	return buffer.String()
+}
+
+type MySqlFetcherQueries struct {
+	// QueryTemplate is the MySQL Query which can be used to fetch configs from the database.
+	// It works like PostgresFetcherQueries.QueryTemplate. For example:
+	//   SELECT id, requestData, 'request' as type
+	//     FROM stored_requests
+	//     WHERE id in %REQUEST_ID_LIST%
+	//     UNION ALL
+	//   SELECT id, impData, 'imp' as type
+	//     FROM stored_imps
+	//     WHERE id in %IMP_ID_LIST%
+	//
+	// The MakeQuery function will transform this query into:
+	//   SELECT id, requestData, 'request' as type
+	//     FROM stored_requests
+	//     WHERE id in (?)
+	//     UNION ALL
+	//   SELECT id, impData, 'imp' as type
+	//     FROM stored_imps
+	//     WHERE id in (?, ?, ?, ...)
+	//
+	// The accounts are fetched with a %ACCOUNT_ID_LIST% placeholder, and should be returned with an 'account' type:
+	//   SELECT id, config, 'account' as type
+	//     FROM accounts
+	//     WHERE id in %ACCOUNT_ID_LIST%
+	QueryTemplate string `mapstructure:"query"`
+
+	// AmpQueryTemplate is the same as QueryTemplate, but used in the `/openrtb2/amp` endpoint.
+	AmpQueryTemplate string `mapstructure:"amp_query"`
+}
+
+// MakeQuery builds a query which can fetch numReqs Stored Requests and numImps Stored Imps.
+// See the docs on MySqlFetcherQueries.QueryTemplate for a description of how it works.
+func (cfg *MySqlFetcherQueries) MakeQuery(numReqs int, numImps int) (query string) {
+	numReqs = ensureNonNegative("Request", numReqs)
+	numImps = ensureNonNegative("Imp", numImps)
+
+	query = strings.Replace(cfg.QueryTemplate, "%REQUEST_ID_LIST%", makeMySqlIdList(numReqs), -1)
+	query = strings.Replace(query, "%IMP_ID_LIST%", makeMySqlIdList(numImps), -1)
+	return
+}
+
+// MakeQueryResponses builds a query which can fetch numIds Stored Responses.
+// The QueryTemplate lists them with a %RESPONSE_ID_LIST% placeholder, and should return the rows with a 'response' type.
+func (cfg *MySqlFetcherQueries) MakeQueryResponses(numIds int) (query string) {
+	numIds = ensureNonNegative("Response", numIds)
+	return strings.Replace(cfg.QueryTemplate, "%RESPONSE_ID_LIST%", makeMySqlIdList(numIds), -1)
+}
+
+// MakeQueryAccounts builds a query which can fetch numIds Accounts.
+// The QueryTemplate lists them with a %ACCOUNT_ID_LIST% placeholder, and should return the rows with an 'account' type.
+func (cfg *MySqlFetcherQueries) MakeQueryAccounts(numIds int) (query string) {
+	numIds = ensureNonNegative("Account", numIds)
+	return strings.Replace(cfg.QueryTemplate, "%ACCOUNT_ID_LIST%", makeMySqlIdList(numIds), -1)
+}
+
+type MySqlCacheInitializer struct {
+	Timeout int `mapstructure:"timeout_ms"`
+	// Query should be something like:
+	//
+	// SELECT id, requestData, 'request' AS type FROM stored_requests
+	// UNION ALL
+	// SELECT id, impData, 'imp' AS type FROM stored_imps
+	//
+	// This query will be run once on startup to fetch _all_ known Stored Request data from the database.
+	Query string `mapstructure:"query"`
+	// AmpQuery is just like Query, but for AMP Stored Requests
+	AmpQuery string `mapstructure:"amp_query"`
+}
+
+func (cfg *MySqlCacheInitializer) validate(dataType DataType, errs []error) []error {
+	section := dataType.Section()
+	if cfg.Query == "" {
+		return errs
+	}
+	if cfg.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s: mysql.initialize_caches.timeout_ms must be positive", section))
+	}
+	if strings.Contains(cfg.Query, "?") {
+		errs = append(errs, fmt.Errorf("%s: mysql.initialize_caches.query should not contain any wildcards (e.g. ?)", section))
+	}
+	return errs
+}
+
+type MySqlUpdatePolling struct {
+	// RefreshRate determines how frequently the Query and AmpQuery are run.
+	RefreshRate int `mapstructure:"refresh_rate_seconds"`
+
+	// Timeout is the amount of time before a call to the database is aborted.
+	Timeout int `mapstructure:"timeout_ms"`
+
+	// An example UpdateQuery is:
+	//
+	// SELECT id, requestData, 'request' AS type
+	//   FROM stored_requests
+	//   WHERE last_updated > ?
+	// UNION ALL
+	// SELECT id, requestData, 'imp' AS type
+	//   FROM stored_imps
+	//   WHERE last_updated > ?
+	//
+	// The code will be run periodically to fetch updates from the database. Every wildcard is bound to the
+	// time of the last update.
+	Query string `mapstructure:"query"`
+	// AmpQuery is the same as Query, but used for the `/openrtb2/amp` endpoint.
+	AmpQuery string `mapstructure:"amp_query"`
+}
+
+func (cfg *MySqlUpdatePolling) validate(dataType DataType, errs []error) []error {
+	section := dataType.Section()
+	if cfg.Query == "" {
+		return errs
+	}
+
+	if cfg.RefreshRate <= 0 {
+		errs = append(errs, fmt.Errorf("%s: mysql.poll_for_updates.refresh_rate_seconds must be > 0", section))
+	}
+
+	if cfg.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s: mysql.poll_for_updates.timeout_ms must be > 0", section))
+	}
+
+	if !strings.Contains(cfg.Query, "?") {
+		errs = append(errs, fmt.Errorf("%s: mysql.poll_for_updates.query must contain at least one wildcard", section))
+	}
+	return errs
+}
+
+// WildcardCount returns the number of "?" placeholders of the update query, which are all bound to the time
+// of the last update.
+func (cfg *MySqlUpdatePolling) WildcardCount() int {
+	return strings.Count(cfg.Query, "?")
+}
+
+func makeMySqlIdList(numArgs int) string {
+	// Any empty list like "()" is illegal in MySQL as well. See makeIdList.
+	if numArgs == 0 {
+		return "(NULL)"
+	}
+
+	final := bytes.NewBuffer(make([]byte, 0, 2+3*numArgs))
+	final.WriteString("(")
+	for i := 1; i < numArgs; i++ {
+		final.WriteString("?, ")
+	}
+	final.WriteString("?)")
+
+	return final.String()
+}
+
 type InMemoryCache struct {
 	// Identify the type of memory cache. "none", "unbounded", "lru"
 	Type string `mapstructure:"type"`
@@ -453,3 +701,52 @@ func (cfg *InMemoryCache) validate(dataType DataType, errs []error) []error {
 	}
 	return errs
 }
+
+// SharedCache configures a cache tier which lives outside of the Prebid Server process,
+// so that it survives restarts and can be shared by every instance.
+type SharedCache struct {
+	// Identify the type of shared cache. "none" or "redis"
+	Type string `mapstructure:"type"`
+	// Address is the host:port of the Redis-protocol server.
+	Address string `mapstructure:"address"`
+	// Password is sent with an AUTH command after connecting, if non-empty.
+	Password string `mapstructure:"password"`
+	// Database is the logical database selected after connecting.
+	Database int `mapstructure:"database"`
+	// KeyPrefix is prepended to every key and channel name, so that several deployments can share a server.
+	KeyPrefix string `mapstructure:"key_prefix"`
+	// TTL is the number of seconds a saved value stays in the shared cache. TTL <= 0 can be used for "no ttl".
+	TTL int `mapstructure:"ttl_seconds"`
+	// Timeout is the maximum number of milliseconds a single cache operation may take.
+	Timeout int `mapstructure:"timeout_ms"`
+	// PoolSize is the maximum number of idle connections kept open to the server.
+	PoolSize int `mapstructure:"pool_size"`
+}
+
+func (cfg *SharedCache) TimeoutDuration() time.Duration {
+	return time.Duration(cfg.Timeout) * time.Millisecond
+}
+
+func (cfg *SharedCache) validate(dataType DataType, errs []error) []error {
+	section := dataType.Section()
+	switch cfg.Type {
+	case "", "none":
+		// No errors for no config options
+	case "redis":
+		if dataType == CategoryDataType {
+			errs = append(errs, fmt.Errorf("%s: shared_cache is not supported for categories", section))
+		}
+		if cfg.Address == "" {
+			errs = append(errs, fmt.Errorf("%s: shared_cache.address is required when shared_cache.type=redis", section))
+		}
+		if cfg.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("%s: shared_cache.timeout_ms must be > 0 when shared_cache.type=redis. Got %d", section, cfg.Timeout))
+		}
+		if cfg.Database < 0 {
+			errs = append(errs, fmt.Errorf("%s: shared_cache.database must be >= 0. Got %d", section, cfg.Database))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s: shared_cache.type %s is invalid", section, cfg.Type))
+	}
+	return errs
+}
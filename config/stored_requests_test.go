@@ -41,6 +41,14 @@ func TestQueryMakerNegative(t *testing.T) {
 	assertStringsEqual(t, query, expected)
 }
 
+func TestQueryMakerResponses(t *testing.T) {
+	cfg := PostgresFetcherQueries{QueryTemplate: "SELECT id, responseData, 'response' as type FROM stored_responses WHERE id in %RESPONSE_ID_LIST%"}
+
+	assertStringsEqual(t, cfg.MakeQueryResponses(3), "SELECT id, responseData, 'response' as type FROM stored_responses WHERE id in ($1, $2, $3)")
+	assertStringsEqual(t, cfg.MakeQueryResponses(0), "SELECT id, responseData, 'response' as type FROM stored_responses WHERE id in (NULL)")
+	assertStringsEqual(t, cfg.MakeQueryResponses(-1), cfg.MakeQueryResponses(0))
+}
+
 func TestPostgressConnString(t *testing.T) {
 	db := "TestDB"
 	host := "somehost.com"
@@ -259,6 +267,221 @@ func TestPostgresConfigValidation(t *testing.T) {
 	}
 }
 
+func TestMySqlQueryMaker(t *testing.T) {
+	testCases := []struct {
+		description   string
+		numReqs       int
+		numImps       int
+		expectedQuery string
+	}{
+		{
+			description:   "Requests and imps",
+			numReqs:       1,
+			numImps:       3,
+			expectedQuery: "SELECT id, requestData, 'request' as type FROM stored_requests WHERE id in (?) UNION ALL SELECT id, impData, 'imp' as type FROM stored_requests WHERE id in (?, ?, ?)",
+		},
+		{
+			description:   "No requests",
+			numReqs:       0,
+			numImps:       2,
+			expectedQuery: "SELECT id, requestData, 'request' as type FROM stored_requests WHERE id in (NULL) UNION ALL SELECT id, impData, 'imp' as type FROM stored_requests WHERE id in (?, ?)",
+		},
+		{
+			description:   "No imps",
+			numReqs:       1,
+			numImps:       0,
+			expectedQuery: "SELECT id, requestData, 'request' as type FROM stored_requests WHERE id in (?) UNION ALL SELECT id, impData, 'imp' as type FROM stored_requests WHERE id in (NULL)",
+		},
+		{
+			description:   "Negative",
+			numReqs:       -1,
+			numImps:       -2,
+			expectedQuery: "SELECT id, requestData, 'request' as type FROM stored_requests WHERE id in (NULL) UNION ALL SELECT id, impData, 'imp' as type FROM stored_requests WHERE id in (NULL)",
+		},
+	}
+
+	cfg := MySqlFetcherQueries{QueryTemplate: sampleQueryTemplate}
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedQuery, cfg.MakeQuery(test.numReqs, test.numImps), test.description)
+	}
+}
+
+func TestMySqlQueryMakerResponsesAndAccounts(t *testing.T) {
+	responsesCfg := MySqlFetcherQueries{QueryTemplate: "SELECT id, responseData, 'response' as type FROM stored_responses WHERE id in %RESPONSE_ID_LIST%"}
+	assert.Equal(t, "SELECT id, responseData, 'response' as type FROM stored_responses WHERE id in (?, ?, ?)", responsesCfg.MakeQueryResponses(3))
+	assert.Equal(t, "SELECT id, responseData, 'response' as type FROM stored_responses WHERE id in (NULL)", responsesCfg.MakeQueryResponses(0))
+
+	accountsCfg := MySqlFetcherQueries{QueryTemplate: "SELECT id, config, 'account' as type FROM accounts WHERE id in %ACCOUNT_ID_LIST%"}
+	assert.Equal(t, "SELECT id, config, 'account' as type FROM accounts WHERE id in (?)", accountsCfg.MakeQueryAccounts(1))
+	assert.Equal(t, "SELECT id, config, 'account' as type FROM accounts WHERE id in (NULL)", accountsCfg.MakeQueryAccounts(-1))
+}
+
+func TestMySqlConnString(t *testing.T) {
+	testCases := []struct {
+		description string
+		connection  MySqlConnection
+		expected    string
+	}{
+		{
+			description: "All fields",
+			connection: MySqlConnection{
+				Database:    "TestDB",
+				Host:        "somehost.com",
+				Port:        20,
+				Username:    "someuser",
+				Password:    "somepassword",
+				QueryString: "tls=true",
+			},
+			expected: "someuser:somepassword@tcp(somehost.com:20)/TestDB?tls=true",
+		},
+		{
+			description: "No password and port",
+			connection: MySqlConnection{
+				Database: "TestDB",
+				Host:     "somehost.com",
+				Username: "someuser",
+			},
+			expected: "someuser@tcp(somehost.com)/TestDB",
+		},
+		{
+			description: "Database only",
+			connection:  MySqlConnection{Database: "TestDB"},
+			expected:    "/TestDB",
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, test.connection.ConnString(), test.description)
+	}
+}
+
+func TestMySqlConfigValidation(t *testing.T) {
+	tests := []struct {
+		description            string
+		connectionStr          string
+		cacheInitQuery         string
+		cacheInitTimeout       int
+		cacheUpdateQuery       string
+		cacheUpdateRefreshRate int
+		cacheUpdateTimeout     int
+		wantErrors             []error
+	}{
+		{
+			description:   "No connection string",
+			connectionStr: "",
+		},
+		{
+			description:   "Connection string but no queries",
+			connectionStr: "some-connection-string",
+		},
+		{
+			description:      "Valid cache init query",
+			connectionStr:    "some-connection-string",
+			cacheInitQuery:   "SELECT * FROM table;",
+			cacheInitTimeout: 1,
+		},
+		{
+			description:      "Invalid cache init query with zero timeout and wildcard",
+			connectionStr:    "some-connection-string",
+			cacheInitQuery:   "SELECT * FROM table WHERE id = ?",
+			cacheInitTimeout: 0,
+			wantErrors: []error{
+				errors.New("stored_requests: mysql.initialize_caches.timeout_ms must be positive"),
+				errors.New("stored_requests: mysql.initialize_caches.query should not contain any wildcards (e.g. ?)"),
+			},
+		},
+		{
+			description:            "Valid cache update query with many wildcards",
+			connectionStr:          "some-connection-string",
+			cacheUpdateQuery:       "SELECT * FROM table WHERE last_updated > ? UNION ALL SELECT * FROM other_table WHERE last_updated > ?",
+			cacheUpdateRefreshRate: 1,
+			cacheUpdateTimeout:     1,
+		},
+		{
+			description:            "Invalid cache update query with zero timeout and refresh rate and no wildcard",
+			connectionStr:          "some-connection-string",
+			cacheUpdateQuery:       "SELECT * FROM table",
+			cacheUpdateRefreshRate: 0,
+			cacheUpdateTimeout:     0,
+			wantErrors: []error{
+				errors.New("stored_requests: mysql.poll_for_updates.refresh_rate_seconds must be > 0"),
+				errors.New("stored_requests: mysql.poll_for_updates.timeout_ms must be > 0"),
+				errors.New("stored_requests: mysql.poll_for_updates.query must contain at least one wildcard"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		mysqlConfig := &MySqlConfig{
+			ConnectionInfo: MySqlConnection{
+				Database: tt.connectionStr,
+			},
+			CacheInitialization: MySqlCacheInitializer{
+				Query:   tt.cacheInitQuery,
+				Timeout: tt.cacheInitTimeout,
+			},
+			PollUpdates: MySqlUpdatePolling{
+				Query:       tt.cacheUpdateQuery,
+				RefreshRate: tt.cacheUpdateRefreshRate,
+				Timeout:     tt.cacheUpdateTimeout,
+			},
+		}
+
+		errs := mysqlConfig.validate(RequestDataType, nil)
+		assert.Equal(t, tt.wantErrors, errs, tt.description)
+	}
+}
+
+func TestStoredRequestsValidationDatabases(t *testing.T) {
+	testCases := []struct {
+		description string
+		config      StoredRequests
+		dataType    DataType
+		wantErrors  []error
+	}{
+		{
+			description: "MySQL accounts",
+			config: StoredRequests{
+				MySql:         MySqlConfig{ConnectionInfo: MySqlConnection{Database: "accounts"}},
+				InMemoryCache: InMemoryCache{Type: "none"},
+			},
+			dataType: AccountDataType,
+		},
+		{
+			description: "Postgres and MySQL",
+			config: StoredRequests{
+				Postgres:      PostgresConfig{ConnectionInfo: PostgresConnection{Database: "db"}},
+				MySql:         MySqlConfig{ConnectionInfo: MySqlConnection{Database: "db"}},
+				InMemoryCache: InMemoryCache{Type: "none"},
+			},
+			dataType:   RequestDataType,
+			wantErrors: []error{errors.New("stored_requests: postgres and mysql cannot be used at the same time")},
+		},
+		{
+			description: "MySQL events without cache",
+			config: StoredRequests{
+				MySql: MySqlConfig{
+					ConnectionInfo:      MySqlConnection{Database: "db"},
+					CacheInitialization: MySqlCacheInitializer{Query: "SELECT * FROM table", Timeout: 1},
+					PollUpdates:         MySqlUpdatePolling{Query: "SELECT * FROM table WHERE last_updated > ?", RefreshRate: 1, Timeout: 1},
+				},
+				InMemoryCache: InMemoryCache{Type: "none"},
+			},
+			dataType: RequestDataType,
+			wantErrors: []error{
+				errors.New("stored_requests: mysql.poll_for_updates.query must be empty if in_memory_cache=none"),
+				errors.New("stored_requests: mysql.initialize_caches.query must be empty if in_memory_cache=none"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test.config.SetDataType(test.dataType)
+		errs := test.config.validate(nil)
+		assert.Equal(t, test.wantErrors, errs, test.description)
+	}
+}
+
 func assertErrsExist(t *testing.T, err []error) {
 	t.Helper()
 	if len(err) == 0 {
@@ -298,6 +521,61 @@ func assertStringsEqual(t *testing.T, actual string, expected string) {
 	}
 }
 
+func TestSharedCacheValidation(t *testing.T) {
+	tests := []struct {
+		description string
+		dataType    DataType
+		sharedCache SharedCache
+		wantErrors  []error
+	}{
+		{
+			description: "Not configured",
+			dataType:    RequestDataType,
+		},
+		{
+			description: "None",
+			dataType:    RequestDataType,
+			sharedCache: SharedCache{Type: "none"},
+		},
+		{
+			description: "Valid redis",
+			dataType:    AccountDataType,
+			sharedCache: SharedCache{Type: "redis", Address: "localhost:6379", Timeout: 50},
+		},
+		{
+			description: "Invalid redis without address and timeout",
+			dataType:    RequestDataType,
+			sharedCache: SharedCache{Type: "redis", Database: -1},
+			wantErrors: []error{
+				errors.New("stored_requests: shared_cache.address is required when shared_cache.type=redis"),
+				errors.New("stored_requests: shared_cache.timeout_ms must be > 0 when shared_cache.type=redis. Got 0"),
+				errors.New("stored_requests: shared_cache.database must be >= 0. Got -1"),
+			},
+		},
+		{
+			description: "Redis for categories",
+			dataType:    CategoryDataType,
+			sharedCache: SharedCache{Type: "redis", Address: "localhost:6379", Timeout: 50},
+			wantErrors: []error{
+				errors.New("categories: shared_cache is not supported for categories"),
+			},
+		},
+		{
+			description: "Unknown type",
+			dataType:    VideoDataType,
+			sharedCache: SharedCache{Type: "memcached"},
+			wantErrors: []error{
+				errors.New("stored_video_req: shared_cache.type memcached is invalid"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		errs := tt.sharedCache.validate(tt.dataType, nil)
+		assert.Equal(t, tt.wantErrors, errs, tt.description)
+	}
+}
+
 func TestResolveConfig(t *testing.T) {
 	cfg := &Configuration{
 		StoredRequests: StoredRequests{
@@ -322,6 +600,17 @@ func TestResolveConfig(t *testing.T) {
 					AmpQuery: "amp-poll-query",
 				},
 			},
+			MySql: MySqlConfig{
+				FetcherQueries: MySqlFetcherQueries{
+					AmpQueryTemplate: "amp-mysql-fetcher-query",
+				},
+				CacheInitialization: MySqlCacheInitializer{
+					AmpQuery: "amp-mysql-cache-init-query",
+				},
+				PollUpdates: MySqlUpdatePolling{
+					AmpQuery: "amp-mysql-poll-query",
+				},
+			},
 			HTTP: HTTPFetcherConfig{
 				AmpEndpoint: "amp-http-fetcher-endpoint",
 			},
@@ -357,6 +646,9 @@ func TestResolveConfig(t *testing.T) {
 	assertStringsEqual(t, amp.Postgres.FetcherQueries.QueryTemplate, cfg.StoredRequests.Postgres.FetcherQueries.AmpQueryTemplate)
 	assertStringsEqual(t, amp.Postgres.CacheInitialization.Query, cfg.StoredRequests.Postgres.CacheInitialization.AmpQuery)
 	assertStringsEqual(t, amp.Postgres.PollUpdates.Query, cfg.StoredRequests.Postgres.PollUpdates.AmpQuery)
+	assertStringsEqual(t, amp.MySql.FetcherQueries.QueryTemplate, cfg.StoredRequests.MySql.FetcherQueries.AmpQueryTemplate)
+	assertStringsEqual(t, amp.MySql.CacheInitialization.Query, cfg.StoredRequests.MySql.CacheInitialization.AmpQuery)
+	assertStringsEqual(t, amp.MySql.PollUpdates.Query, cfg.StoredRequests.MySql.PollUpdates.AmpQuery)
 	assertStringsEqual(t, amp.HTTP.Endpoint, cfg.StoredRequests.HTTP.AmpEndpoint)
 	assertStringsEqual(t, amp.HTTPEvents.Endpoint, cfg.StoredRequests.HTTPEvents.AmpEndpoint)
 	assertStringsEqual(t, amp.CacheEvents.Endpoint, "/storedrequests/amp")
@@ -1,10 +1,36 @@
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
 // UserSync specifies the static global user sync configuration.
 type UserSync struct {
 	Cooperative UserSyncCooperative `mapstructure:"coop_sync"`
 	ExternalURL string              `mapstructure:"external_url"`
 	RedirectURL string              `mapstructure:"redirect_url"`
+	UIDStore    UIDStore            `mapstructure:"uid_store"`
+	// UIDPriorityGroups ranks the bidders whose UIDs are kept when the uids cookie grows past
+	// host_cookie.max_cookie_size_bytes, the highest priority group first. The UIDs of the bidders left
+	// out are evicted first.
+	UIDPriorityGroups [][]string `mapstructure:"uid_priority_groups"`
+}
+
+func (cfg *UserSync) validate(errs []error) []error {
+	groupOfBidder := make(map[string]int)
+	for i, group := range cfg.UIDPriorityGroups {
+		for _, bidder := range group {
+			if bidder == "" {
+				errs = append(errs, fmt.Errorf("user_sync.uid_priority_groups[%d] has an empty bidder", i))
+			} else if previous, ok := groupOfBidder[bidder]; ok {
+				errs = append(errs, fmt.Errorf("user_sync.uid_priority_groups has the bidder %s in both groups %d and %d", bidder, previous, i))
+			} else {
+				groupOfBidder[bidder] = i
+			}
+		}
+	}
+	return cfg.UIDStore.validate(errs)
 }
 
 // UserSyncCooperative specifies the static global default cooperative cookie sync
@@ -12,3 +38,61 @@ type UserSyncCooperative struct {
 	EnabledByDefault bool       `mapstructure:"default"`
 	PriorityGroups   [][]string `mapstructure:"priority_groups"`
 }
+
+// UIDStore configures the server side store of the bidder UIDs. When enabled, /setuid saves the UIDs in the store
+// instead of the uids cookie, and /getuids and /openrtb2/auction read them from there.
+type UIDStore struct {
+	// Type of the store. "none", "memory" or "redis"
+	Type string `mapstructure:"type"`
+	// TTL is the number of seconds a UID is kept after it's saved
+	TTL int `mapstructure:"ttl_seconds"`
+	// Address is the host:port of the Redis-protocol server.
+	Address string `mapstructure:"address"`
+	// Password is sent with an AUTH command after connecting, if non-empty.
+	Password string `mapstructure:"password"`
+	// Database is the logical database selected after connecting.
+	Database int `mapstructure:"database"`
+	// KeyPrefix is prepended to every key, so that several deployments can share a server.
+	KeyPrefix string `mapstructure:"key_prefix"`
+	// Timeout is the maximum number of milliseconds a single store operation may take.
+	Timeout int `mapstructure:"timeout_ms"`
+	// PoolSize is the maximum number of idle connections kept open to the server.
+	PoolSize int `mapstructure:"pool_size"`
+}
+
+// Enabled is true if the UIDs are kept in a server side store
+func (cfg *UIDStore) Enabled() bool {
+	return cfg.Type != "" && cfg.Type != "none"
+}
+
+func (cfg *UIDStore) TTLDuration() time.Duration {
+	return time.Duration(cfg.TTL) * time.Second
+}
+
+func (cfg *UIDStore) TimeoutDuration() time.Duration {
+	return time.Duration(cfg.Timeout) * time.Millisecond
+}
+
+func (cfg *UIDStore) validate(errs []error) []error {
+	switch cfg.Type {
+	case "", "none":
+		return errs
+	case "memory":
+	case "redis":
+		if cfg.Address == "" {
+			errs = append(errs, fmt.Errorf("user_sync.uid_store.address is required when user_sync.uid_store.type=redis"))
+		}
+		if cfg.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("user_sync.uid_store.timeout_ms must be > 0 when user_sync.uid_store.type=redis. Got %d", cfg.Timeout))
+		}
+		if cfg.Database < 0 {
+			errs = append(errs, fmt.Errorf("user_sync.uid_store.database must be >= 0. Got %d", cfg.Database))
+		}
+	default:
+		return append(errs, fmt.Errorf("user_sync.uid_store.type %s is invalid", cfg.Type))
+	}
+	if cfg.TTL <= 0 {
+		errs = append(errs, fmt.Errorf("user_sync.uid_store.ttl_seconds must be > 0. Got %d", cfg.TTL))
+	}
+	return errs
+}
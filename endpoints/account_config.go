@@ -0,0 +1,63 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/account"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests"
+)
+
+type accountConfigModel struct {
+	// Stored is false when no stored account was found, so the account only uses the host defaults
+	Stored  bool            `json:"stored"`
+	Account *config.Account `json:"account"`
+}
+
+// NewAccountConfigEndpoint returns the effective config of the account given by the `:id` URL param,
+// which is the stored account merged with the host AccountDefaults. Unlike the auction endpoints,
+// disabled accounts are returned as well.
+func NewAccountConfigEndpoint(cfg *config.Configuration, fetcher stored_requests.AccountFetcher) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		accountID := ps.ByName("id")
+
+		var model accountConfigModel
+		accountJSON, errs := fetcher.FetchAccount(r.Context(), accountID)
+		for _, err := range errs {
+			if _, ok := err.(stored_requests.NotFoundError); !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(fmt.Sprintf("Failed to fetch the account: %v\n", err)))
+				return
+			}
+		}
+
+		if len(errs) == 0 && accountJSON != nil {
+			mergedAccount, err := account.MergeWithDefaults(cfg, accountID, accountJSON)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(fmt.Sprintf("Failed to merge the account with the defaults: %v\n", err)))
+				return
+			}
+			model.Stored = true
+			model.Account = mergedAccount
+		} else {
+			defaultAccount := cfg.AccountDefaults
+			defaultAccount.ID = accountID
+			model.Account = &defaultAccount
+		}
+
+		jsonOutput, err := json.Marshal(model)
+		if err != nil {
+			glog.Errorf("Critical error when trying to marshal the config of account %s: %v", accountID, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(jsonOutput)
+	}
+}
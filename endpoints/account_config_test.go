@@ -0,0 +1,88 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountConfigEndpoint(t *testing.T) {
+	cfg := &config.Configuration{
+		AccountDefaults: config.Account{
+			EventsEnabled: true,
+			DebugAllow:    true,
+		},
+	}
+	if err := cfg.MarshalAccountDefaults(); err != nil {
+		t.Fatalf("Failed to marshal the account defaults: %v", err)
+	}
+	fetcher := FakeAccountsFetcher{AccountData: map[string]json.RawMessage{
+		"stored":   json.RawMessage(`{"debug_allow": false}`),
+		"disabled": json.RawMessage(`{"id": "disabled", "disabled": true}`),
+	}}
+
+	testCases := []struct {
+		description     string
+		accountID       string
+		expectedStored  bool
+		expectedAccount config.Account
+	}{
+		{
+			description:     "stored account merged with the defaults",
+			accountID:       "stored",
+			expectedStored:  true,
+			expectedAccount: config.Account{ID: "stored", EventsEnabled: true, DebugAllow: false},
+		},
+		{
+			description:     "disabled account",
+			accountID:       "disabled",
+			expectedStored:  true,
+			expectedAccount: config.Account{ID: "disabled", Disabled: true, EventsEnabled: true, DebugAllow: true},
+		},
+		{
+			description:     "unknown account uses the defaults",
+			accountID:       "unknown",
+			expectedStored:  false,
+			expectedAccount: config.Account{ID: "unknown", EventsEnabled: true, DebugAllow: true},
+		},
+	}
+
+	handler := NewAccountConfigEndpoint(cfg, fetcher)
+	for _, test := range testCases {
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest("GET", "/", nil), httprouter.Params{{Key: "id", Value: test.accountID}})
+
+		assert.Equal(t, http.StatusOK, recorder.Code, test.description)
+
+		var result accountConfigModel
+		if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result), test.description) {
+			assert.Equal(t, test.expectedStored, result.Stored, test.description)
+			assert.Equal(t, &test.expectedAccount, result.Account, test.description)
+		}
+	}
+}
+
+func TestAccountConfigEndpointFetchError(t *testing.T) {
+	cfg := &config.Configuration{}
+	handler := NewAccountConfigEndpoint(cfg, failingAccountsFetcher{})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest("GET", "/", nil), httprouter.Params{{Key: "id", Value: "account"}})
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "Failed to fetch the account: db unavailable\n", recorder.Body.String())
+}
+
+type failingAccountsFetcher struct{}
+
+func (f failingAccountsFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	return nil, []error{errors.New("db unavailable"), stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
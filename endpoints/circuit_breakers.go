@@ -0,0 +1,31 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+type circuitBreakers interface {
+	Status() map[openrtb_ext.BidderName]exchange.CircuitBreakerStatus
+}
+
+// NewCircuitBreakersEndpoint returns the state of the circuit breaker of each bidder which was called since
+// startup, along with the counts of the requests in its sliding window.
+func NewCircuitBreakersEndpoint(breakers circuitBreakers) httprouter.Handle {
+	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		jsonOutput, err := json.Marshal(breakers.Status())
+		if err != nil {
+			glog.Errorf("/bidders/circuit_breakers Critical error when trying to marshal the circuit breakers: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(jsonOutput)
+	}
+}
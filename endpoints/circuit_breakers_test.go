@@ -0,0 +1,58 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakersEndpoint(t *testing.T) {
+	openedAt := time.Date(2023, 3, 2, 12, 54, 56, 0, time.UTC)
+
+	testCases := []struct {
+		description  string
+		statuses     map[openrtb_ext.BidderName]exchange.CircuitBreakerStatus
+		expectedBody string
+	}{
+		{
+			description:  "no breaker",
+			statuses:     map[openrtb_ext.BidderName]exchange.CircuitBreakerStatus{},
+			expectedBody: `{}`,
+		},
+		{
+			description: "closed and open breakers",
+			statuses: map[openrtb_ext.BidderName]exchange.CircuitBreakerStatus{
+				openrtb_ext.BidderAppnexus: {State: metrics.CircuitBreakerClosed, Requests: 10, Errors: 1},
+				openrtb_ext.BidderRubicon:  {State: metrics.CircuitBreakerOpen, Requests: 20, Timeouts: 15, OpenedAt: &openedAt},
+			},
+			expectedBody: `{
+				"appnexus": {"state": "closed", "requests": 10, "errors": 1, "timeouts": 0},
+				"rubicon": {"state": "open", "requests": 20, "errors": 0, "timeouts": 15, "opened_at": "2023-03-02T12:54:56Z"}
+			}`,
+		},
+	}
+
+	for _, test := range testCases {
+		handler := NewCircuitBreakersEndpoint(&fakeCircuitBreakers{statuses: test.statuses})
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest("GET", "/bidders/circuit_breakers", nil), nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, test.description)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), test.description)
+		assert.JSONEq(t, test.expectedBody, recorder.Body.String(), test.description)
+	}
+}
+
+type fakeCircuitBreakers struct {
+	statuses map[openrtb_ext.BidderName]exchange.CircuitBreakerStatus
+}
+
+func (f *fakeCircuitBreakers) Status() map[openrtb_ext.BidderName]exchange.CircuitBreakerStatus {
+	return f.statuses
+}
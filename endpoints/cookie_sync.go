@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
+	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/gdpr"
@@ -18,6 +20,8 @@ import (
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	gdprPrivacy "github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/privacy/gpp"
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
 )
 
@@ -37,7 +41,9 @@ func NewCookieSyncEndpoint(
 	gdprPermissions gdpr.Permissions,
 	metrics metrics.MetricsEngine,
 	pbsAnalytics analytics.PBSAnalyticsModule,
-	bidders map[string]openrtb_ext.BidderName) HTTPRouterHandler {
+	accountsFetcher stored_requests.AccountFetcher,
+	bidders map[string]openrtb_ext.BidderName,
+	uidStore usersync.UIDStore) HTTPRouterHandler {
 
 	bidderHashSet := make(map[string]struct{}, len(bidders))
 	for _, bidder := range bidders {
@@ -54,8 +60,12 @@ func NewCookieSyncEndpoint(
 			ccpaEnforce:     config.CCPA.Enforce,
 			bidderHashSet:   bidderHashSet,
 		},
-		metrics:      metrics,
-		pbsAnalytics: pbsAnalytics,
+		metrics:         metrics,
+		pbsAnalytics:    pbsAnalytics,
+		accountsConfig:  config,
+		accountsFetcher: accountsFetcher,
+		uidPriority:     usersync.NewUIDPriority(config.UserSync.UIDPriorityGroups, syncersByBidder),
+		uidStore:        uidStore,
 	}
 }
 
@@ -66,6 +76,11 @@ type cookieSyncEndpoint struct {
 	privacyConfig    usersyncPrivacyConfig
 	metrics          metrics.MetricsEngine
 	pbsAnalytics     analytics.PBSAnalyticsModule
+	accountsConfig   *config.Configuration
+	accountsFetcher  stored_requests.AccountFetcher
+	uidPriority      usersync.UIDPriority
+	// uidStore is nil when the UIDs are kept in the uids cookie
+	uidStore usersync.UIDStore
 }
 
 func (c *cookieSyncEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -76,7 +91,12 @@ func (c *cookieSyncEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ ht
 		return
 	}
 
-	cookie := usersync.ParseCookieFromRequest(r, c.hostCookieConfig)
+	var cookie *usersync.Cookie
+	if c.uidStore != nil {
+		cookie = usersync.ParseCookieFromStore(r.Context(), r, c.hostCookieConfig, c.uidStore, r.URL.Query().Get(usersync.FirstPartyIDParam))
+	} else {
+		cookie = usersync.ParseCookieFromRequest(r, c.hostCookieConfig)
+	}
 
 	result := c.chooser.Choose(request, cookie)
 	switch result.Status {
@@ -110,6 +130,42 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 		return usersync.Request{}, privacy.Policies{}, err
 	}
 
+	gppSID, err := gpp.ParseSID(request.GPPSID)
+	if err != nil {
+		return usersync.Request{}, privacy.Policies{}, err
+	}
+
+	accountID := request.Account
+	if accountID == "" {
+		accountID = metrics.PublisherUnknown
+	}
+	account, accountErrs := accountService.GetAccount(context.Background(), c.accountsConfig, c.accountsFetcher, accountID)
+	if len(accountErrs) > 0 {
+		return usersync.Request{}, privacy.Policies{}, accountErrs[0]
+	}
+
+	parsedGPP := gpp.GPP{}
+	if request.GPP != "" {
+		if parsedGPP, err = gpp.Parse(request.GPP); err != nil {
+			request.GPP = ""
+		}
+	}
+
+	// the applicable GPP sections decide whether GDPR applies when the request does not say so
+	if gdprSignal == gdpr.SignalAmbiguous && len(gppSID) > 0 {
+		gdprSignal = gdpr.SignalNo
+		if gpp.Applies(gppSID, gpp.SectionTCFEU2) {
+			gdprSignal = gdpr.SignalYes
+		}
+		request.GDPR = strconv.Itoa(int(gdprSignal))
+	}
+	if request.GDPRConsent == "" && gpp.Applies(gppSID, gpp.SectionTCFEU2) {
+		request.GDPRConsent = parsedGPP.TCF2Consent()
+	}
+	if request.USPrivacy == "" && gpp.Applies(gppSID, gpp.SectionUSPV1) {
+		request.USPrivacy = parsedGPP.USPrivacy()
+	}
+
 	if request.GDPRConsent == "" {
 		if gdprSignal == gdpr.SignalYes {
 			return usersync.Request{}, privacy.Policies{}, errCookieSyncGDPRConsentMissing
@@ -128,6 +184,10 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 		CCPA: ccpa.Policy{
 			Consent: request.USPrivacy,
 		},
+		GPP: gpp.Policy{
+			Consent: request.GPP,
+			RawSID:  request.GPPSID,
+		},
 	}
 
 	ccpaParsedPolicy := ccpa.ParsedPolicy{}
@@ -140,6 +200,11 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 			ccpaParsedPolicy = parsedPolicy
 		}
 	}
+	if c.privacyConfig.ccpaEnforce {
+		// an invalid US section is treated as no opt-out, the same way as an invalid us_privacy string
+		gppOptOut, _ := parsedGPP.USOptOut(gppSID)
+		ccpaParsedPolicy = ccpaParsedPolicy.WithOptOut(gppOptOut)
+	}
 
 	syncTypeFilter, err := parseTypeFilter(request.FilterSettings)
 	if err != nil {
@@ -147,23 +212,51 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 	}
 
 	rx := usersync.Request{
-		Bidders: request.Bidders,
-		Cooperative: usersync.Cooperative{
-			Enabled:        (request.CooperativeSync != nil && *request.CooperativeSync) || (request.CooperativeSync == nil && c.config.Cooperative.EnabledByDefault),
-			PriorityGroups: c.config.Cooperative.PriorityGroups,
+		Bidders:     request.Bidders,
+		Cooperative: c.parseCooperative(request.CooperativeSync, account.CookieSync),
+		Limit:       request.Limit,
+		Limits: usersync.Limits{
+			Default: account.CookieSync.DefaultLimit,
+			Max:     account.CookieSync.MaxLimit,
 		},
-		Limit: request.Limit,
 		Privacy: usersyncPrivacy{
 			gdprPermissions:  c.privacyConfig.gdprPermissions,
 			gdprSignal:       gdprSignal,
 			gdprConsent:      request.GDPRConsent,
 			ccpaParsedPolicy: ccpaParsedPolicy,
+			activityControl:  privacy.NewActivityControl(&account.Privacy),
+			activityRequest:  privacy.ActivityRequest{GPPSID: gppSID},
 		},
 		SyncTypeFilter: syncTypeFilter,
 	}
+	// The UIDs kept in a UID store aren't evicted
+	if c.uidStore == nil {
+		rx.UIDEviction = usersync.UIDEviction{
+			HostCookie: c.hostCookieConfig,
+			Priority:   c.uidPriority,
+		}
+	}
 	return rx, privacyPolicies, nil
 }
 
+// parseCooperative applies the cooperative sync of the request, or else the default of the account, or else the
+// default of the host. The priority groups of the account replace those of the host.
+func (c *cookieSyncEndpoint) parseCooperative(requested *bool, accountCookieSync config.AccountCookieSync) usersync.Cooperative {
+	cooperative := usersync.Cooperative{
+		Enabled:        c.config.Cooperative.EnabledByDefault,
+		PriorityGroups: c.config.Cooperative.PriorityGroups,
+	}
+	if requested != nil {
+		cooperative.Enabled = *requested
+	} else if accountCookieSync.DefaultCoopSync != nil {
+		cooperative.Enabled = *accountCookieSync.DefaultCoopSync
+	}
+	if len(accountCookieSync.PriorityGroups) > 0 {
+		cooperative.PriorityGroups = accountCookieSync.PriorityGroups
+	}
+	return cooperative
+}
+
 func parseTypeFilter(request *cookieSyncRequestFilterSettings) (usersync.SyncTypeFilter, error) {
 	syncTypeFilter := usersync.SyncTypeFilter{
 		IFrame:   cookieSyncBidderFilterAllowAll,
@@ -241,10 +334,14 @@ func (c *cookieSyncEndpoint) writeBidderMetrics(biddersEvaluated []usersync.Bidd
 			c.metrics.RecordSyncerRequest(bidder.SyncerKey, metrics.SyncerCookieSyncPrivacyBlocked)
 		case usersync.StatusBlockedByCCPA:
 			c.metrics.RecordSyncerRequest(bidder.SyncerKey, metrics.SyncerCookieSyncPrivacyBlocked)
+		case usersync.StatusBlockedByPrivacy:
+			c.metrics.RecordSyncerRequest(bidder.SyncerKey, metrics.SyncerCookieSyncPrivacyBlocked)
 		case usersync.StatusAlreadySynced:
 			c.metrics.RecordSyncerRequest(bidder.SyncerKey, metrics.SyncerCookieSyncAlreadySynced)
 		case usersync.StatusTypeNotSupported:
 			c.metrics.RecordSyncerRequest(bidder.SyncerKey, metrics.SyncerCookieSyncTypeNotSupported)
+		case usersync.StatusBlockedByCookieSize:
+			c.metrics.RecordSyncerRequest(bidder.SyncerKey, metrics.SyncerCookieSyncCookieFull)
 		}
 	}
 }
@@ -311,6 +408,9 @@ type cookieSyncRequest struct {
 	GDPR            string                           `json:"gdpr"`
 	GDPRConsent     string                           `json:"gdpr_consent"`
 	USPrivacy       string                           `json:"us_privacy"`
+	GPP             string                           `json:"gpp"`
+	GPPSID          string                           `json:"gpp_sid"`
+	Account         string                           `json:"account"`
 	Limit           int                              `json:"limit"`
 	CooperativeSync *bool                            `json:"coopSync"`
 	FilterSettings  *cookieSyncRequestFilterSettings `json:"filterSettings"`
@@ -355,6 +455,8 @@ type usersyncPrivacy struct {
 	gdprSignal       gdpr.Signal
 	gdprConsent      string
 	ccpaParsedPolicy ccpa.ParsedPolicy
+	activityControl  privacy.ActivityControl
+	activityRequest  privacy.ActivityRequest
 }
 
 func (p usersyncPrivacy) GDPRAllowsHostCookie() bool {
@@ -371,3 +473,7 @@ func (p usersyncPrivacy) CCPAAllowsBidderSync(bidder string) bool {
 	enforce := p.ccpaParsedPolicy.CanEnforce() && p.ccpaParsedPolicy.ShouldEnforce(bidder)
 	return !enforce
 }
+
+func (p usersyncPrivacy) ActivityAllowsUserSync(bidder string) bool {
+	return p.activityControl.Allow(privacy.ActivitySyncUser, privacy.Component{Type: privacy.ComponentTypeBidder, Name: bidder}, p.activityRequest)
+}
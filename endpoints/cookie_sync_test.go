@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
@@ -18,6 +19,8 @@ import (
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	gdprPrivacy "github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/privacy/gpp"
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
 
 	"github.com/stretchr/testify/assert"
@@ -34,21 +37,25 @@ func TestNewCookieSyncEndpoint(t *testing.T) {
 		configCCPAEnforce = true
 		metrics           = metrics.MetricsEngineMock{}
 		analytics         = MockAnalytics{}
-		bidders           = map[string]openrtb_ext.BidderName{"bidderA": openrtb_ext.BidderName("bidderA"), "bidderB": openrtb_ext.BidderName("bidderB")}
-	)
-
-	endpoint := NewCookieSyncEndpoint(
-		syncersByBidder,
-		&config.Configuration{
+		fetcher           = FakeAccountsFetcher{}
+		cfg               = &config.Configuration{
 			UserSync:   configUserSync,
 			HostCookie: configHostCookie,
 			GDPR:       configGDPR,
 			CCPA:       config.CCPA{Enforce: configCCPAEnforce},
-		},
+		}
+		bidders = map[string]openrtb_ext.BidderName{"bidderA": openrtb_ext.BidderName("bidderA"), "bidderB": openrtb_ext.BidderName("bidderB")}
+	)
+
+	endpoint := NewCookieSyncEndpoint(
+		syncersByBidder,
+		cfg,
 		&gdprPerms,
 		&metrics,
 		&analytics,
+		&fetcher,
 		bidders,
+		nil,
 	)
 
 	expected := &cookieSyncEndpoint{
@@ -61,8 +68,11 @@ func TestNewCookieSyncEndpoint(t *testing.T) {
 			ccpaEnforce:     configCCPAEnforce,
 			bidderHashSet:   map[string]struct{}{"bidderA": {}, "bidderB": {}},
 		},
-		metrics:      &metrics,
-		pbsAnalytics: &analytics,
+		metrics:         &metrics,
+		pbsAnalytics:    &analytics,
+		accountsConfig:  cfg,
+		accountsFetcher: &fetcher,
+		uidPriority:     usersync.UIDPriority{},
 	}
 
 	assert.Equal(t, expected, endpoint)
@@ -247,8 +257,10 @@ func TestCookieSyncHandle(t *testing.T) {
 				},
 				ccpaEnforce: true,
 			},
-			metrics:      &mockMetrics,
-			pbsAnalytics: &mockAnalytics,
+			metrics:         &mockMetrics,
+			pbsAnalytics:    &mockAnalytics,
+			accountsConfig:  &config.Configuration{},
+			accountsFetcher: FakeAccountsFetcher{},
 		}
 		endpoint.Handle(writer, request, nil)
 
@@ -259,18 +271,132 @@ func TestCookieSyncHandle(t *testing.T) {
 	}
 }
 
+func TestCookieSyncHandleUIDStore(t *testing.T) {
+	syncTypeExpected := []usersync.SyncType{usersync.SyncTypeIFrame, usersync.SyncTypeRedirect}
+	sync := usersync.Sync{URL: "aURL", Type: usersync.SyncTypeRedirect, SupportCORS: true}
+	syncer := MockSyncer{}
+	syncer.On("GetSync", syncTypeExpected, privacy.Policies{}).Return(sync, nil).Maybe()
+
+	cookieWithSyncs := usersync.NewCookie()
+	cookieWithSyncs.TrySync("foo", "anyID")
+
+	store := usersync.NewMemoryUIDStore(time.Hour)
+	store.Set(context.Background(), "fp:first-party-id", "foo", "storedID")
+
+	testCases := []struct {
+		description    string
+		givenURL       string
+		givenCookie    *usersync.Cookie
+		expectedStatus string
+	}{
+		{
+			description:    "UIDs In Store",
+			givenURL:       "/cookiesync?fpid=first-party-id",
+			givenCookie:    nil,
+			expectedStatus: "ok",
+		},
+		{
+			description:    "UIDs In Cookie Only",
+			givenURL:       "/cookiesync?fpid=other-id",
+			givenCookie:    cookieWithSyncs,
+			expectedStatus: "no_cookie",
+		},
+		{
+			description:    "No First Party ID",
+			givenURL:       "/cookiesync",
+			givenCookie:    cookieWithSyncs,
+			expectedStatus: "no_cookie",
+		},
+	}
+
+	for _, test := range testCases {
+		mockMetrics := metrics.MetricsEngineMock{}
+		mockMetrics.On("RecordCookieSync", metrics.CookieSyncOK).Once()
+		mockMetrics.On("RecordSyncerRequest", "aSyncer", metrics.SyncerCookieSyncOK).Once()
+
+		mockAnalytics := MockAnalytics{}
+		mockAnalytics.On("LogCookieSyncObject", mock.Anything).Once()
+
+		request := httptest.NewRequest("POST", test.givenURL, strings.NewReader(`{}`))
+		if test.givenCookie != nil {
+			request.AddCookie(test.givenCookie.ToHTTPCookie(24 * time.Hour))
+		}
+
+		writer := httptest.NewRecorder()
+
+		endpoint := cookieSyncEndpoint{
+			chooser: FakeChooser{Result: usersync.Result{
+				Status:           usersync.StatusOK,
+				BiddersEvaluated: []usersync.BidderEvaluation{{Bidder: "a", SyncerKey: "aSyncer", Status: usersync.StatusOK}},
+				SyncersChosen:    []usersync.SyncerChoice{{Bidder: "a", Syncer: &syncer}},
+			}},
+			hostCookieConfig: &config.HostCookie{},
+			privacyConfig: usersyncPrivacyConfig{
+				gdprConfig: config.GDPR{
+					Enabled:      true,
+					DefaultValue: "0",
+				},
+				ccpaEnforce: true,
+			},
+			metrics:         &mockMetrics,
+			pbsAnalytics:    &mockAnalytics,
+			accountsConfig:  &config.Configuration{},
+			accountsFetcher: FakeAccountsFetcher{},
+			uidStore:        store,
+		}
+		endpoint.Handle(writer, request, nil)
+
+		expectedBody := `{"status":"` + test.expectedStatus + `","bidder_status":[` +
+			`{"bidder":"a","no_cookie":true,"usersync":{"url":"aURL","type":"redirect","supportCORS":true}}` +
+			`]}` + "\n"
+		assert.Equal(t, 200, writer.Code, test.description+":status_code")
+		assert.Equal(t, expectedBody, writer.Body.String(), test.description+":body")
+	}
+}
+
+func TestCookieSyncParseRequestUIDStore(t *testing.T) {
+	endpoint := cookieSyncEndpoint{
+		hostCookieConfig: &config.HostCookie{},
+		privacyConfig: usersyncPrivacyConfig{
+			gdprConfig: config.GDPR{
+				Enabled:      true,
+				DefaultValue: "0",
+			},
+		},
+		accountsConfig:  &config.Configuration{},
+		accountsFetcher: FakeAccountsFetcher{},
+		uidStore:        usersync.NewMemoryUIDStore(time.Hour),
+	}
+
+	request, _, err := endpoint.parseRequest(httptest.NewRequest("POST", "/cookiesync", strings.NewReader(`{}`)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, usersync.UIDEviction{}, request.UIDEviction, "The UIDs kept in a UID store aren't evicted")
+}
+
 func TestCookieSyncParseRequest(t *testing.T) {
 	expectedCCPAParsedPolicy, _ := ccpa.Policy{Consent: "1NYN"}.Parse(map[string]struct{}{})
+	expectedGPPCCPAParsedPolicy, _ := ccpa.Policy{Consent: "1YYN"}.Parse(map[string]struct{}{})
+	gppTCF2Consent := "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+	syncDeniedAccount := json.RawMessage(`{"privacy":{"allow_activities":{"sync_user":{"rules":[{"condition":{"gpp_sid":[7]},"allow":false}]}}}}`)
+	syncDeniedActivityControl := privacy.NewActivityControl(&config.AccountPrivacy{
+		AllowActivities: config.AllowActivities{
+			SyncUser: config.Activity{
+				Rules: []config.ActivityRule{{Condition: config.ActivityCondition{GPPSID: []int8{7}}, Allow: false}},
+			},
+		},
+	})
 
 	testCases := []struct {
-		description      string
-		givenConfig      config.UserSync
-		givenBody        io.Reader
-		givenGDPRConfig  config.GDPR
-		givenCCPAEnabled bool
-		expectedError    string
-		expectedPrivacy  privacy.Policies
-		expectedRequest  usersync.Request
+		description          string
+		givenConfig          config.UserSync
+		givenBody            io.Reader
+		givenGDPRConfig      config.GDPR
+		givenCCPAEnabled     bool
+		givenAccountRequired bool
+		expectedError        string
+		expectedPrivacy      privacy.Policies
+		expectedRequest      usersync.Request
 	}{
 		{
 			description: "Complete Request",
@@ -640,6 +766,102 @@ func TestCookieSyncParseRequest(t *testing.T) {
 			givenCCPAEnabled: true,
 			expectedError:    "gdpr_consent is required. gdpr is not specified and is assumed to be 1 by the server. set gdpr=0 to exempt this request",
 		},
+		{
+			description:      "GPP - Consent From Applicable Sections",
+			givenBody:        strings.NewReader(`{"gpp":"DBACNY~` + gppTCF2Consent + `~1YYN","gpp_sid":"2,6"}`),
+			givenGDPRConfig:  config.GDPR{Enabled: true, DefaultValue: "0"},
+			givenCCPAEnabled: true,
+			expectedPrivacy: privacy.Policies{
+				GDPR: gdprPrivacy.Policy{
+					Signal:  "1",
+					Consent: gppTCF2Consent,
+				},
+				CCPA: ccpa.Policy{
+					Consent: "1YYN",
+				},
+				GPP: gpp.Policy{
+					Consent: "DBACNY~" + gppTCF2Consent + "~1YYN",
+					RawSID:  "2,6",
+				},
+			},
+			expectedRequest: usersync.Request{
+				Privacy: usersyncPrivacy{
+					gdprSignal:       gdpr.SignalYes,
+					gdprConsent:      gppTCF2Consent,
+					ccpaParsedPolicy: expectedGPPCCPAParsedPolicy,
+					activityRequest:  privacy.ActivityRequest{GPPSID: []int8{2, 6}},
+				},
+				SyncTypeFilter: usersync.SyncTypeFilter{
+					IFrame:   usersync.NewUniformBidderFilter(usersync.BidderFilterModeInclude),
+					Redirect: usersync.NewUniformBidderFilter(usersync.BidderFilterModeInclude),
+				},
+			},
+		},
+		{
+			description:      "GPP - Sections Not Applicable",
+			givenBody:        strings.NewReader(`{"gpp":"DBACNY~` + gppTCF2Consent + `~1YYN","gpp_sid":"7"}`),
+			givenGDPRConfig:  config.GDPR{Enabled: true, DefaultValue: "1"},
+			givenCCPAEnabled: true,
+			expectedPrivacy: privacy.Policies{
+				GDPR: gdprPrivacy.Policy{
+					Signal: "0",
+				},
+				GPP: gpp.Policy{
+					Consent: "DBACNY~" + gppTCF2Consent + "~1YYN",
+					RawSID:  "7",
+				},
+			},
+			expectedRequest: usersync.Request{
+				Privacy: usersyncPrivacy{
+					gdprSignal:      gdpr.SignalNo,
+					activityRequest: privacy.ActivityRequest{GPPSID: []int8{7}},
+				},
+				SyncTypeFilter: usersync.SyncTypeFilter{
+					IFrame:   usersync.NewUniformBidderFilter(usersync.BidderFilterModeInclude),
+					Redirect: usersync.NewUniformBidderFilter(usersync.BidderFilterModeInclude),
+				},
+			},
+		},
+		{
+			description:      "GPP - Invalid SID",
+			givenBody:        strings.NewReader(`{"gpp":"DBABMA~` + gppTCF2Consent + `","gpp_sid":"2,x"}`),
+			givenGDPRConfig:  config.GDPR{Enabled: true, DefaultValue: "0"},
+			givenCCPAEnabled: true,
+			expectedError:    `invalid gpp_sid value "x"`,
+		},
+		{
+			description:      "Account Activity Controls",
+			givenBody:        strings.NewReader(`{"account":"syncDenied","gdpr":"0","gpp_sid":"7"}`),
+			givenGDPRConfig:  config.GDPR{Enabled: true, DefaultValue: "0"},
+			givenCCPAEnabled: true,
+			expectedPrivacy: privacy.Policies{
+				GDPR: gdprPrivacy.Policy{
+					Signal: "0",
+				},
+				GPP: gpp.Policy{
+					RawSID: "7",
+				},
+			},
+			expectedRequest: usersync.Request{
+				Privacy: usersyncPrivacy{
+					gdprSignal:      gdpr.SignalNo,
+					activityControl: syncDeniedActivityControl,
+					activityRequest: privacy.ActivityRequest{GPPSID: []int8{7}},
+				},
+				SyncTypeFilter: usersync.SyncTypeFilter{
+					IFrame:   usersync.NewUniformBidderFilter(usersync.BidderFilterModeInclude),
+					Redirect: usersync.NewUniformBidderFilter(usersync.BidderFilterModeInclude),
+				},
+			},
+		},
+		{
+			description:          "Account Required",
+			givenBody:            strings.NewReader(`{"gdpr":"0"}`),
+			givenGDPRConfig:      config.GDPR{Enabled: true, DefaultValue: "0"},
+			givenCCPAEnabled:     true,
+			givenAccountRequired: true,
+			expectedError:        "Prebid-server has been configured to discard requests without a valid Account ID. Please reach out to the prebid server host.",
+		},
 		{
 			description:      "HTTP Read Error",
 			givenBody:        ErrReader(errors.New("anyError")),
@@ -652,12 +874,17 @@ func TestCookieSyncParseRequest(t *testing.T) {
 	for _, test := range testCases {
 		httpRequest := httptest.NewRequest("POST", "/cookiesync", test.givenBody)
 
+		accountsConfig := &config.Configuration{AccountRequired: test.givenAccountRequired}
+		assert.NoError(t, accountsConfig.MarshalAccountDefaults(), test.description+":accountDefaults")
+
 		endpoint := cookieSyncEndpoint{
 			config: test.givenConfig,
 			privacyConfig: usersyncPrivacyConfig{
 				gdprConfig:  test.givenGDPRConfig,
 				ccpaEnforce: test.givenCCPAEnabled,
 			},
+			accountsConfig:  accountsConfig,
+			accountsFetcher: FakeAccountsFetcher{AccountData: map[string]json.RawMessage{"syncDenied": syncDeniedAccount}},
 		}
 		request, privacyPolicies, err := endpoint.parseRequest(httpRequest)
 
@@ -673,6 +900,71 @@ func TestCookieSyncParseRequest(t *testing.T) {
 	}
 }
 
+func TestCookieSyncParseRequestAccountCookieSync(t *testing.T) {
+	hostCooperative := config.UserSyncCooperative{
+		EnabledByDefault: false,
+		PriorityGroups:   [][]string{{"a"}},
+	}
+	accountData := map[string]json.RawMessage{
+		"limited": json.RawMessage(`{"cookie_sync":{"default_limit":2,"max_limit":4}}`),
+		"coop":    json.RawMessage(`{"cookie_sync":{"default_coop_sync":true,"priority_groups":[["b","c"],["d"]]}}`),
+	}
+
+	testCases := []struct {
+		description         string
+		givenBody           string
+		expectedLimit       int
+		expectedLimits      usersync.Limits
+		expectedCooperative usersync.Cooperative
+	}{
+		{
+			description:         "Host Defaults",
+			givenBody:           `{"limit":3}`,
+			expectedLimit:       3,
+			expectedCooperative: usersync.Cooperative{Enabled: false, PriorityGroups: [][]string{{"a"}}},
+		},
+		{
+			description:         "Account Limits",
+			givenBody:           `{"account":"limited","limit":3}`,
+			expectedLimit:       3,
+			expectedLimits:      usersync.Limits{Default: 2, Max: 4},
+			expectedCooperative: usersync.Cooperative{Enabled: false, PriorityGroups: [][]string{{"a"}}},
+		},
+		{
+			description:         "Account Cooperative Sync",
+			givenBody:           `{"account":"coop"}`,
+			expectedCooperative: usersync.Cooperative{Enabled: true, PriorityGroups: [][]string{{"b", "c"}, {"d"}}},
+		},
+		{
+			description:         "Request Cooperative Sync Takes Precedence Over Account",
+			givenBody:           `{"account":"coop","coopSync":false}`,
+			expectedCooperative: usersync.Cooperative{Enabled: false, PriorityGroups: [][]string{{"b", "c"}, {"d"}}},
+		},
+	}
+
+	for _, test := range testCases {
+		httpRequest := httptest.NewRequest("POST", "/cookiesync", strings.NewReader(test.givenBody))
+
+		accountsConfig := &config.Configuration{}
+		assert.NoError(t, accountsConfig.MarshalAccountDefaults(), test.description+":accountDefaults")
+
+		endpoint := cookieSyncEndpoint{
+			config: config.UserSync{Cooperative: hostCooperative},
+			privacyConfig: usersyncPrivacyConfig{
+				gdprConfig: config.GDPR{Enabled: true, DefaultValue: "0"},
+			},
+			accountsConfig:  accountsConfig,
+			accountsFetcher: FakeAccountsFetcher{AccountData: accountData},
+		}
+		request, _, err := endpoint.parseRequest(httpRequest)
+
+		assert.NoError(t, err, test.description+":err")
+		assert.Equal(t, test.expectedLimit, request.Limit, test.description+":limit")
+		assert.Equal(t, test.expectedLimits, request.Limits, test.description+":limits")
+		assert.Equal(t, test.expectedCooperative, request.Cooperative, test.description+":cooperative")
+	}
+}
+
 func TestParseTypeFilter(t *testing.T) {
 	testCases := []struct {
 		description    string
@@ -882,6 +1174,20 @@ func TestCookieSyncWriteBidderMetrics(t *testing.T) {
 				m.On("RecordSyncerRequest", "aSyncer", metrics.SyncerCookieSyncPrivacyBlocked).Once()
 			},
 		},
+		{
+			description: "One - Blocked By Activity Control",
+			given:       []usersync.BidderEvaluation{{Bidder: "a", SyncerKey: "aSyncer", Status: usersync.StatusBlockedByPrivacy}},
+			setExpectations: func(m *metrics.MetricsEngineMock) {
+				m.On("RecordSyncerRequest", "aSyncer", metrics.SyncerCookieSyncPrivacyBlocked).Once()
+			},
+		},
+		{
+			description: "One - Blocked By Cookie Size",
+			given:       []usersync.BidderEvaluation{{Bidder: "a", SyncerKey: "aSyncer", Status: usersync.StatusBlockedByCookieSize}},
+			setExpectations: func(m *metrics.MetricsEngineMock) {
+				m.On("RecordSyncerRequest", "aSyncer", metrics.SyncerCookieSyncCookieFull).Once()
+			},
+		},
 		{
 			description: "One - Already Synced",
 			given:       []usersync.BidderEvaluation{{Bidder: "a", SyncerKey: "aSyncer", Status: usersync.StatusAlreadySynced}},
@@ -1302,6 +1608,17 @@ func (m *MockAnalytics) LogNotificationEventObject(obj *analytics.NotificationEv
 	m.Called(obj)
 }
 
+type FakeAccountsFetcher struct {
+	AccountData map[string]json.RawMessage
+}
+
+func (f FakeAccountsFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	if account, ok := f.AccountData[accountID]; ok {
+		return account, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
+
 type MockGDPRPerms struct {
 	mock.Mock
 }
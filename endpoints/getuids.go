@@ -15,10 +15,15 @@ type userSyncs struct {
 }
 
 // NewGetUIDsEndpoint implements the /getuid endpoint which
-// returns all the existing syncs for the user
-func NewGetUIDsEndpoint(cfg config.HostCookie) httprouter.Handle {
+// returns all the existing syncs for the user, from the uidStore if there's one
+func NewGetUIDsEndpoint(cfg config.HostCookie, uidStore usersync.UIDStore) httprouter.Handle {
 	return httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-		pc := usersync.ParseCookieFromRequest(r, &cfg)
+		var pc *usersync.Cookie
+		if uidStore != nil {
+			pc = usersync.ParseCookieFromStore(r.Context(), r, &cfg, uidStore, r.URL.Query().Get(usersync.FirstPartyIDParam))
+		} else {
+			pc = usersync.ParseCookieFromRequest(r, &cfg)
+		}
 		userSyncs := new(userSyncs)
 		userSyncs.BuyerUIDs = pc.GetUIDs()
 		json.NewEncoder(w).Encode(userSyncs)
@@ -1,17 +1,20 @@
 package endpoints
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/usersync"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestGetUIDs(t *testing.T) {
 	req := makeRequest("/getuids", map[string]string{"adnxs": "123", "audienceNetwork": "456"})
-	endpoint := NewGetUIDsEndpoint(config.HostCookie{})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, nil)
 	res := httptest.NewRecorder()
 	endpoint(res, req, nil)
 
@@ -22,7 +25,7 @@ func TestGetUIDs(t *testing.T) {
 
 func TestGetUIDsWithNoSyncs(t *testing.T) {
 	req := makeRequest("/getuids", map[string]string{})
-	endpoint := NewGetUIDsEndpoint(config.HostCookie{})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, nil)
 	res := httptest.NewRecorder()
 	endpoint(res, req, nil)
 
@@ -32,10 +35,23 @@ func TestGetUIDsWithNoSyncs(t *testing.T) {
 
 func TestGetUIDWIthNoCookie(t *testing.T) {
 	req := httptest.NewRequest("GET", "/getuids", nil)
-	endpoint := NewGetUIDsEndpoint(config.HostCookie{})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, nil)
 	res := httptest.NewRecorder()
 	endpoint(res, req, nil)
 
 	assert.Equal(t, http.StatusOK, res.Code)
 	assert.JSONEq(t, `{}`, res.Body.String(), "GetUIDs endpoint shouldn't return anything if there doesn't exist a PBS cookie")
 }
+
+func TestGetUIDsFromStore(t *testing.T) {
+	store := usersync.NewMemoryUIDStore(time.Hour)
+	store.Set(context.Background(), "fp:user1", "adnxs", "789")
+
+	req := makeRequest("/getuids?fpid=user1", map[string]string{"adnxs": "123", "audienceNetwork": "456"})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, store)
+	res := httptest.NewRecorder()
+	endpoint(res, req, nil)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"buyeruids": {"adnxs": "789"}}`, res.Body.String(), "GetUIDs endpoint should read the user IDs from the store")
+}
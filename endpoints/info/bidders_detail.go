@@ -171,6 +171,8 @@ func mapDetailFromConfig(c config.BidderInfo, endpoint string) bidderDetail {
 		bidderDetail.Status = statusDisabled
 	}
 
+	bidderDetail.AliasOf = c.AliasOf
+
 	return bidderDetail
 }
 
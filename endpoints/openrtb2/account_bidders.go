@@ -0,0 +1,163 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	accountService "github.com/prebid/prebid-server/account"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// requestAccount is the account of a request, along with the errors of its lookup
+type requestAccount struct {
+	account *config.Account
+	errs    []error
+}
+
+// applyAccountBidders looks up the account of the request and applies its bidder controls to the imps, so that
+// the params are validated once merged. The account is looked up once per request: it's returned along with the
+// lookup errors, which are left to the endpoint once the request is valid. It also returns a warning for each
+// bidder removed from the request.
+func (deps *endpointDeps) applyAccountBidders(ctx context.Context, req *openrtb_ext.RequestWrapper) (requestAccount, []error) {
+	account, errs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, getRequestAccountID(req.BidRequest))
+	if len(errs) > 0 || !account.Bidders.HasControls() {
+		return requestAccount{account: account, errs: errs}, nil
+	}
+
+	var aliases map[string]string
+	if reqExt, err := req.GetRequestExt(); err == nil && reqExt.GetPrebid() != nil {
+		aliases = reqExt.GetPrebid().Aliases
+	}
+	return requestAccount{account: account}, applyAccountBidderControls(req.BidRequest, account.Bidders, aliases)
+}
+
+// applyAccountBidderControls removes the bidders the account can't call from the imps, and merges the default
+// params of the account under the request ones. The imps with an invalid ext are left to the request validation.
+func applyAccountBidderControls(req *openrtb2.BidRequest, bidders config.AccountBidders, aliases map[string]string) []error {
+	removed := make(map[string]struct{})
+	for i := range req.Imp {
+		applyAccountBidderControlsToImp(&req.Imp[i], bidders, aliases, removed)
+	}
+
+	removedBidders := make([]string, 0, len(removed))
+	for bidder := range removed {
+		removedBidders = append(removedBidders, bidder)
+	}
+	sort.Strings(removedBidders)
+
+	warnings := make([]error, 0, len(removedBidders))
+	for _, bidder := range removedBidders {
+		warnings = append(warnings, &errortypes.Warning{
+			Message:     fmt.Sprintf("bidder %s is not allowed for this account and was removed from the request", bidder),
+			WarningCode: errortypes.AccountBiddersWarningCode,
+		})
+	}
+	return warnings
+}
+
+func applyAccountBidderControlsToImp(imp *openrtb2.Imp, bidders config.AccountBidders, aliases map[string]string, removed map[string]struct{}) {
+	var impExt map[string]json.RawMessage
+	if err := json.Unmarshal(imp.Ext, &impExt); err != nil {
+		return
+	}
+
+	// Both request.imp.ext.prebid.bidder.BIDDER and the legacy request.imp.ext.BIDDER hold bidder params
+	var prebidExt map[string]json.RawMessage
+	var bidderParams map[string]json.RawMessage
+	if prebidJSON, ok := impExt[openrtb_ext.PrebidExtKey]; ok {
+		if err := json.Unmarshal(prebidJSON, &prebidExt); err != nil {
+			return
+		}
+		if bidderJSON, ok := prebidExt["bidder"]; ok {
+			if err := json.Unmarshal(bidderJSON, &bidderParams); err != nil {
+				return
+			}
+		}
+	}
+
+	changed := applyAccountBidderControlsToParams(impExt, bidders, aliases, removed)
+	if applyAccountBidderControlsToParams(bidderParams, bidders, aliases, removed) {
+		bidderJSON, err := json.Marshal(bidderParams)
+		if err != nil {
+			return
+		}
+		prebidExt["bidder"] = bidderJSON
+		if impExt[openrtb_ext.PrebidExtKey], err = json.Marshal(prebidExt); err != nil {
+			return
+		}
+		changed = true
+	}
+
+	if changed {
+		if impExtJSON, err := json.Marshal(impExt); err == nil {
+			imp.Ext = impExtJSON
+		}
+	}
+}
+
+// applyAccountBidderControlsToParams applies the bidder controls to the params of each bidder, and tells
+// whether they changed.
+func applyAccountBidderControlsToParams(params map[string]json.RawMessage, bidders config.AccountBidders, aliases map[string]string, removed map[string]struct{}) bool {
+	changed := false
+	for bidder, bidderParams := range params {
+		if !isBidderToValidate(bidder) {
+			continue
+		}
+
+		coreBidder := bidder
+		if parent, isAlias := aliases[bidder]; isAlias {
+			coreBidder = parent
+		} else if parent, isAlias := openrtb_ext.GetAliasBidderToParent()[openrtb_ext.BidderName(bidder)]; isAlias {
+			coreBidder = string(parent)
+		}
+		// Leave the unknown bidders and the other ext fields to the request validation
+		if _, isKnown := openrtb_ext.NormalizeBidderName(coreBidder); !isKnown {
+			continue
+		}
+
+		if !bidders.IsBidderAllowed(bidder, coreBidder) {
+			delete(params, bidder)
+			removed[bidder] = struct{}{}
+			changed = true
+			continue
+		}
+
+		if defaultParams := accountBidderParams(bidders, bidder); defaultParams != nil {
+			if merged, err := jsonpatch.MergePatch(defaultParams, bidderParams); err == nil {
+				params[bidder] = merged
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// accountBidderParams returns the default params of the bidder as JSON, or nil if the account doesn't define any
+func accountBidderParams(bidders config.AccountBidders, bidder string) json.RawMessage {
+	for name, params := range bidders.Params {
+		if strings.EqualFold(name, bidder) {
+			if paramsJSON, err := json.Marshal(params); err == nil {
+				return paramsJSON
+			}
+		}
+	}
+	return nil
+}
+
+// getRequestAccountID returns the account ID of the app or site publisher
+func getRequestAccountID(req *openrtb2.BidRequest) string {
+	if req.App != nil {
+		return getAccountID(req.App.Publisher)
+	}
+	if req.Site != nil {
+		return getAccountID(req.Site.Publisher)
+	}
+	return getAccountID(nil)
+}
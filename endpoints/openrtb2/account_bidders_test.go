@@ -0,0 +1,207 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	analyticsConf "github.com/prebid/prebid-server/analytics/config"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyAccountBidderControls(t *testing.T) {
+	testCases := []struct {
+		description      string
+		impExt           string
+		bidders          config.AccountBidders
+		aliases          map[string]string
+		expectedImpExt   string
+		expectedWarnings []error
+	}{
+		{
+			description:    "No Controls",
+			impExt:         `{"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`,
+			bidders:        config.AccountBidders{},
+			expectedImpExt: `{"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`,
+		},
+		{
+			description:    "Allowed Bidder",
+			impExt:         `{"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`,
+			bidders:        config.AccountBidders{Allowed: []string{"appnexus"}},
+			expectedImpExt: `{"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`,
+		},
+		{
+			description:    "Bidder Not Allowed",
+			impExt:         `{"prebid":{"bidder":{"appnexus":{"placementId":1},"rubicon":{"accountId":1}}}}`,
+			bidders:        config.AccountBidders{Allowed: []string{"appnexus"}},
+			expectedImpExt: `{"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`,
+			expectedWarnings: []error{&errortypes.Warning{
+				Message:     "bidder rubicon is not allowed for this account and was removed from the request",
+				WarningCode: errortypes.AccountBiddersWarningCode,
+			}},
+		},
+		{
+			description:    "Denied Legacy Bidder",
+			impExt:         `{"appnexus":{"placementId":1},"rubicon":{"accountId":1},"context":{"data":{}}}`,
+			bidders:        config.AccountBidders{Denied: []string{"Rubicon"}},
+			expectedImpExt: `{"appnexus":{"placementId":1},"context":{"data":{}}}`,
+			expectedWarnings: []error{&errortypes.Warning{
+				Message:     "bidder rubicon is not allowed for this account and was removed from the request",
+				WarningCode: errortypes.AccountBiddersWarningCode,
+			}},
+		},
+		{
+			description:    "Alias Of A Denied Bidder",
+			impExt:         `{"prebid":{"bidder":{"appnexus":{"placementId":1},"anAlias":{"placementId":2}}}}`,
+			bidders:        config.AccountBidders{Denied: []string{"appnexus"}},
+			aliases:        map[string]string{"anAlias": "appnexus"},
+			expectedImpExt: `{"prebid":{"bidder":{}}}`,
+			expectedWarnings: []error{
+				&errortypes.Warning{
+					Message:     "bidder anAlias is not allowed for this account and was removed from the request",
+					WarningCode: errortypes.AccountBiddersWarningCode,
+				},
+				&errortypes.Warning{
+					Message:     "bidder appnexus is not allowed for this account and was removed from the request",
+					WarningCode: errortypes.AccountBiddersWarningCode,
+				},
+			},
+		},
+		{
+			description:    "Default Params Merged Under The Request Ones",
+			impExt:         `{"prebid":{"bidder":{"rubicon":{"siteId":2,"zoneId":3}},"storedrequest":{"id":"imp"}}}`,
+			bidders:        config.AccountBidders{Params: map[string]map[string]interface{}{"rubicon": {"accountId": 1, "siteId": 1}}},
+			expectedImpExt: `{"prebid":{"bidder":{"rubicon":{"accountId":1,"siteId":2,"zoneId":3}},"storedrequest":{"id":"imp"}}}`,
+		},
+		{
+			description:    "Default Params Of Another Bidder",
+			impExt:         `{"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`,
+			bidders:        config.AccountBidders{Params: map[string]map[string]interface{}{"rubicon": {"accountId": 1}}},
+			expectedImpExt: `{"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`,
+		},
+		{
+			description:    "Unknown Bidders Left To The Validation",
+			impExt:         `{"unknown":{},"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`,
+			bidders:        config.AccountBidders{Allowed: []string{"appnexus"}},
+			expectedImpExt: `{"unknown":{},"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`,
+		},
+		{
+			description:    "Invalid Imp Ext Left To The Validation",
+			impExt:         `{"prebid":{"bidder":"appnexus"}}`,
+			bidders:        config.AccountBidders{Allowed: []string{"rubicon"}},
+			expectedImpExt: `{"prebid":{"bidder":"appnexus"}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp", Ext: json.RawMessage(test.impExt)}}}
+
+		warnings := applyAccountBidderControls(req, test.bidders, test.aliases)
+
+		assert.JSONEq(t, test.expectedImpExt, string(req.Imp[0].Ext), test.description)
+		if len(test.expectedWarnings) == 0 {
+			assert.Empty(t, warnings, test.description)
+		} else {
+			assert.Equal(t, test.expectedWarnings, warnings, test.description)
+		}
+	}
+}
+
+func TestParseRequestAccountBidders(t *testing.T) {
+	reqBody := `{
+		"id": "some-request-id",
+		"site": {"page": "test.somepage.com", "publisher": {"id": "bidders_acct"}},
+		"imp": [{
+			"id": "my-imp-id",
+			"banner": {"format": [{"w": 300, "h": 600}]},
+			"ext": {"prebid": {"bidder": {"appnexus": {}, "rubicon": {"accountId": 1, "siteId": 2, "zoneId": 3}}}}
+		}]
+	}`
+	cfg := &config.Configuration{MaxRequestSize: int64(len(reqBody))}
+	if err := cfg.MarshalAccountDefaults(); err != nil {
+		t.Fatalf("Failed to marshal the account defaults: %v", err)
+	}
+	accounts := &accountBiddersFetcher{data: json.RawMessage(`{"bidders": {"denied": ["rubicon"], "params": {"appnexus": {"placementId": 12883451}}}}`)}
+	deps := &endpointDeps{
+		paramsValidator:           newParamsValidator(t),
+		storedReqFetcher:          empty_fetcher.EmptyFetcher{},
+		accounts:                  accounts,
+		cfg:                       cfg,
+		metricsEngine:             &metricsConfig.DummyMetricsEngine{},
+		disabledBidders:           map[string]string{},
+		bidderMap:                 openrtb_ext.BuildBidderMap(),
+		privateNetworkIPValidator: hardcodedResponseIPValidator{response: true},
+	}
+
+	httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
+	req, _, account, errs := deps.parseRequest(httpReq, hookexecution.NewHookExecutor(hooks.EmptyPlanBuilder{}, hooks.EndpointAuction))
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, errortypes.AccountBiddersWarningCode, errortypes.ReadCode(errs[0]))
+	}
+	assert.JSONEq(t, `{"prebid":{"bidder":{"appnexus":{"placementId":12883451}}}}`, string(req.Imp[0].Ext))
+	if assert.NotNil(t, account.account) {
+		assert.Equal(t, "bidders_acct", account.account.ID)
+	}
+	assert.Empty(t, account.errs)
+}
+
+func TestAuctionAccountLookedUpOnce(t *testing.T) {
+	cfg := &config.Configuration{MaxRequestSize: maxSize}
+	if err := cfg.MarshalAccountDefaults(); err != nil {
+		t.Fatalf("Failed to marshal the account defaults: %v", err)
+	}
+	accounts := &accountBiddersFetcher{data: json.RawMessage(`{"bidders": {"params": {"appnexus": {"placementId": 12883451}}}}`)}
+	ex := &mockExchange{}
+
+	endpoint, _ := NewEndpoint(
+		ex,
+		newParamsValidator(t),
+		empty_fetcher.EmptyFetcher{},
+		accounts,
+		cfg,
+		&metricsConfig.DummyMetricsEngine{},
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil)
+
+	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(`{
+		"id": "some-request-id",
+		"site": {"page": "test.somepage.com", "publisher": {"id": "bidders_acct"}},
+		"imp": [{"id": "my-imp-id", "banner": {"format": [{"w": 300, "h": 600}]}, "ext": {"prebid": {"bidder": {"appnexus": {}}}}}]
+	}`))
+	recorder := httptest.NewRecorder()
+	endpoint(recorder, request, nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, 1, accounts.calls, "the account should be looked up once per request")
+}
+
+type accountBiddersFetcher struct {
+	data  json.RawMessage
+	calls int
+}
+
+func (f *accountBiddersFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	f.calls++
+	if accountID == "bidders_acct" {
+		return f.data, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
@@ -11,12 +11,13 @@ import (
 	"strings"
 	"time"
 
-	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/amp"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/privacy"
@@ -24,6 +25,7 @@ import (
 	"github.com/prebid/prebid-server/privacy/gdpr"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/iputil"
 
@@ -31,6 +33,8 @@ import (
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const defaultAmpRequestTimeoutMillis = 900
@@ -40,6 +44,8 @@ type AmpResponse struct {
 	Debug     *openrtb_ext.ExtResponseDebug                             `json:"debug,omitempty"`
 	Errors    map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage `json:"errors,omitempty"`
 	Warnings  map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage `json:"warnings,omitempty"`
+	// Ext holds the module hook outcomes in ext.prebid.modules when debug is enabled
+	Ext json.RawMessage `json:"ext,omitempty"`
 }
 
 // NewAmpEndpoint modifies the OpenRTB endpoint to handle AMP requests. This will basically modify the parsing
@@ -55,9 +61,12 @@ func NewAmpEndpoint(
 	disabledBidders map[string]string,
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
+	storedRespFetcher stored_requests.Fetcher,
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
+	uidStore usersync.UIDStore,
 ) (httprouter.Handle, error) {
 
-	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
+	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil || storedRespFetcher == nil || hookExecutionPlanBuilder == nil {
 		return nil, errors.New("NewAmpEndpoint requires non-nil arguments.")
 	}
 
@@ -83,7 +92,10 @@ func NewAmpEndpoint(
 		bidderMap,
 		nil,
 		nil,
-		ipValidator}).AmpAuction), nil
+		ipValidator,
+		storedRespFetcher,
+		hookExecutionPlanBuilder,
+		uidStore}).AmpAuction), nil
 
 }
 
@@ -130,7 +142,13 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	w.Header().Set("AMP-Access-Control-Allow-Source-Origin", origin)
 	w.Header().Set("Access-Control-Expose-Headers", "AMP-Access-Control-Allow-Source-Origin")
 
-	req, errL := deps.parseAmpRequest(r)
+	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hooks.EndpointAmp)
+
+	req, reqAccount, errL := deps.parseAmpRequest(r, hookExecutor)
+	if rejectErr := hookexecution.FindFirstRejectOrNil(errL); rejectErr != nil {
+		rejectAmpRequest(*rejectErr, w, hookExecutor, req, nil, &ao.Errors)
+		return
+	}
 	ao.Errors = append(ao.Errors, errL...)
 
 	if errortypes.ContainsFatalError(errL) {
@@ -144,7 +162,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 
 	ao.Request = req
 
-	ctx := context.Background()
+	ctx := tracing.DetachedContext(r.Context())
 	var cancel context.CancelFunc
 	if req.TMax > 0 {
 		ctx, cancel = context.WithDeadline(ctx, start.Add(time.Duration(req.TMax)*time.Millisecond))
@@ -153,15 +171,15 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	}
 	defer cancel()
 
-	usersyncs := usersync.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
+	usersyncs := deps.parseUsersyncs(ctx, r, r.URL.Query().Get(usersync.FirstPartyIDParam))
 	if usersyncs.HasAnyLiveSyncs() {
 		labels.CookieFlag = metrics.CookieFlagYes
 	} else {
 		labels.CookieFlag = metrics.CookieFlagNo
 	}
 	labels.PubID = getAccountID(req.Site.Publisher)
-	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
+	// The account was looked up along with the request, under the same pubID value
+	account, acctIDErrs := reqAccount.account, reqAccount.errs
 	if len(acctIDErrs) > 0 {
 		errL = append(errL, acctIDErrs...)
 		httpStatus := http.StatusBadRequest
@@ -182,6 +200,26 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		ao.Errors = append(ao.Errors, acctIDErrs...)
 		return
 	}
+	ao.Account = account
+
+	hookExecutor.SetAccount(account)
+	var rejectErr *hookexecution.RejectError
+	if req, rejectErr = hookExecutor.ExecuteProcessedAuctionStage(req); rejectErr != nil {
+		ao.Request = req
+		rejectAmpRequest(*rejectErr, w, hookExecutor, req, account, &ao.Errors)
+		return
+	}
+
+	storedAuctionResponses, storedBidResponses, storedRespErrs := deps.processStoredResponses(ctx, req.Imp)
+	if len(storedRespErrs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		for _, err := range storedRespErrs {
+			w.Write([]byte(fmt.Sprintf("Invalid request format: %s\n", err.Error())))
+		}
+		labels.RequestStatus = metrics.RequestStatusBadInput
+		ao.Errors = append(ao.Errors, storedRespErrs...)
+		return
+	}
 
 	secGPC := r.Header.Get("Sec-GPC")
 
@@ -193,6 +231,9 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		StartTime:                  start,
 		LegacyLabels:               labels,
 		GlobalPrivacyControlHeader: secGPC,
+		StoredAuctionResponses:     storedAuctionResponses,
+		StoredBidResponses:         storedBidResponses,
+		HookExecutor:               hookExecutor,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
@@ -262,6 +303,12 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		Warnings:  warnings,
 	}
 
+	if extResponse.Prebid != nil && len(extResponse.Prebid.Modules) > 0 {
+		ampResponse.Ext, _ = json.Marshal(openrtb_ext.ExtBidResponse{
+			Prebid: &openrtb_ext.ExtResponsePrebid{Modules: extResponse.Prebid.Modules},
+		})
+	}
+
 	ao.AmpTargetingValues = targets
 
 	// add debug information if requested
@@ -293,9 +340,9 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 // possible, it will return errors with messages that suggest improvements.
 //
 // If the errors list has at least one element, then no guarantees are made about the returned request.
-func (deps *endpointDeps) parseAmpRequest(httpRequest *http.Request) (req *openrtb2.BidRequest, errs []error) {
+func (deps *endpointDeps) parseAmpRequest(httpRequest *http.Request, hookExecutor hookexecution.StageExecutor) (req *openrtb2.BidRequest, account requestAccount, errs []error) {
 	// Load the stored request for the AMP ID.
-	req, e := deps.loadRequestJSONForAmp(httpRequest)
+	req, e := deps.loadRequestJSONForAmp(httpRequest, hookExecutor)
 	if errs = append(errs, e...); errortypes.ContainsFatalError(errs) {
 		return
 	}
@@ -311,13 +358,19 @@ func (deps *endpointDeps) parseAmpRequest(httpRequest *http.Request) (req *openr
 
 	// At this point, we should have a valid request that definitely has Targeting and Cache turned on
 
-	e = deps.validateRequest(&openrtb_ext.RequestWrapper{BidRequest: req})
+	reqWrapper := &openrtb_ext.RequestWrapper{BidRequest: req}
+	ctx, cancel := context.WithTimeout(tracing.DetachedContext(httpRequest.Context()), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
+	defer cancel()
+	account, e = deps.applyAccountBidders(ctx, reqWrapper)
+	errs = append(errs, e...)
+
+	e = deps.validateRequest(reqWrapper)
 	errs = append(errs, e...)
 	return
 }
 
 // Load the stored OpenRTB request for an incoming AMP request, or return the errors found.
-func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req *openrtb2.BidRequest, errs []error) {
+func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request, hookExecutor hookexecution.StageExecutor) (req *openrtb2.BidRequest, errs []error) {
 	req = &openrtb2.BidRequest{}
 	errs = nil
 
@@ -326,10 +379,14 @@ func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req
 		return nil, []error{err}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
+	ctx, cancel := context.WithTimeout(tracing.DetachedContext(httpRequest.Context()), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
 	defer cancel()
 
+	ctx, span := tracing.StartSpan(ctx, "stored_requests.fetch", trace.SpanKindInternal)
+	span.SetAttributes(attribute.Int("stored_requests", 1))
 	storedRequests, _, errs := deps.storedReqFetcher.FetchRequests(ctx, []string{ampParams.StoredRequestID}, nil)
+	recordSpanErrors(span, errs)
+	span.End()
 	if len(errs) > 0 {
 		return nil, errs
 	}
@@ -339,7 +396,14 @@ func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req
 	}
 
 	// The fetched config becomes the entire OpenRTB request
-	requestJSON := storedRequests[ampParams.StoredRequestID]
+	requestJSON, errs := executeRequestStages(httpRequest, storedRequests[ampParams.StoredRequestID], hookExecutor)
+	if len(errs) > 0 {
+		return
+	}
+	if requestJSON, err = moveGPPToRegsExt(requestJSON); err != nil {
+		errs = []error{err}
+		return
+	}
 	if err := json.Unmarshal(requestJSON, req); err != nil {
 		errs = []error{err}
 		return
@@ -563,3 +627,21 @@ func setEffectiveAmpPubID(req *openrtb2.BidRequest, account string) {
 		}
 	}
 }
+
+// rejectAmpRequest answers a request rejected by a module hook with empty targeting. The request and the
+// account are nil if they were not resolved yet.
+func rejectAmpRequest(rejectErr hookexecution.RejectError, w http.ResponseWriter, hookExecutor hookexecution.StageExecutor, request *openrtb2.BidRequest, account *config.Account, errs *[]error) {
+	ampResponse := AmpResponse{Targeting: map[string]string{}}
+	*errs = append(*errs, &rejectErr)
+
+	var err error
+	if ampResponse.Ext, err = hookexecution.EnrichExtBidResponse(nil, hookExecutor.GetOutcomes(), request, account); err != nil {
+		*errs = append(*errs, err)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(ampResponse); err != nil {
+		*errs = append(*errs, fmt.Errorf("/openrtb2/amp Failed to send response: %v", err))
+	}
+}
@@ -21,21 +21,27 @@ import (
 	"github.com/mxmCherry/openrtb/v15/native1"
 	nativeRequests "github.com/mxmCherry/openrtb/v15/native1/request"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
-	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/bidadjustment"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/privacy/ccpa"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/privacy/lmt"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/httputil"
 	"github.com/prebid/prebid-server/util/iputil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/publicsuffix"
 	"golang.org/x/text/currency"
 )
@@ -59,8 +65,11 @@ func NewEndpoint(
 	disabledBidders map[string]string,
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
+	storedRespFetcher stored_requests.Fetcher,
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
+	uidStore usersync.UIDStore,
 ) (httprouter.Handle, error) {
-	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
+	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil || storedRespFetcher == nil || hookExecutionPlanBuilder == nil {
 		return nil, errors.New("NewEndpoint requires non-nil arguments.")
 	}
 
@@ -86,7 +95,10 @@ func NewEndpoint(
 		bidderMap,
 		nil,
 		nil,
-		ipValidator}).Auction), nil
+		ipValidator,
+		storedRespFetcher,
+		hookExecutionPlanBuilder,
+		uidStore}).Auction), nil
 }
 
 type endpointDeps struct {
@@ -105,6 +117,10 @@ type endpointDeps struct {
 	cache                     prebid_cache_client.Client
 	debugLogRegexp            *regexp.Regexp
 	privateNetworkIPValidator iputil.IPValidator
+	storedRespFetcher         stored_requests.Fetcher
+	hookExecutionPlanBuilder  hooks.ExecutionPlanBuilder
+	// uidStore is nil when the UIDs are kept in the uids cookie
+	uidStore usersync.UIDStore
 }
 
 func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -135,14 +151,20 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		deps.analytics.LogAuctionObject(&ao)
 	}()
 
-	req, impExtInfoMap, errL := deps.parseRequest(r)
+	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hooks.EndpointAuction)
+
+	req, impExtInfoMap, reqAccount, errL := deps.parseRequest(r, hookExecutor)
+	if rejectErr := hookexecution.FindFirstRejectOrNil(errL); rejectErr != nil {
+		ao.Response = rejectAuctionRequest(*rejectErr, w, hookExecutor, req.BidRequest, nil, &ao.Errors)
+		return
+	}
 
 	if errortypes.ContainsFatalError(errL) && writeError(errL, w, &labels) {
 		return
 	}
 	warnings := errortypes.WarningOnly(errL)
 
-	ctx := context.Background()
+	ctx := tracing.DetachedContext(r.Context())
 
 	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(req.TMax) * time.Millisecond)
 	if timeout > 0 {
@@ -151,7 +173,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		defer cancel()
 	}
 
-	usersyncs := usersync.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
+	usersyncs := deps.parseUsersyncs(ctx, r, getFirstPartyID(req))
 	if req.App != nil {
 		labels.Source = metrics.DemandApp
 		labels.RType = metrics.ReqTypeORTB2App
@@ -166,8 +188,8 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		labels.PubID = getAccountID(req.Site.Publisher)
 	}
 
-	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
+	// The account was looked up along with the request, under the same pubID value
+	account, acctIDErrs := reqAccount.account, reqAccount.errs
 	if len(acctIDErrs) > 0 {
 		errL = append(errL, acctIDErrs...)
 		writeError(errL, w, &labels)
@@ -181,6 +203,22 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		return
 	}
 
+	hookExecutor.SetAccount(account)
+	var rejectErr *hookexecution.RejectError
+	if req.BidRequest, rejectErr = hookExecutor.ExecuteProcessedAuctionStage(req.BidRequest); rejectErr != nil {
+		ao.Request = req.BidRequest
+		ao.Account = account
+		ao.Response = rejectAuctionRequest(*rejectErr, w, hookExecutor, req.BidRequest, account, &ao.Errors)
+		return
+	}
+
+	storedAuctionResponses, storedBidResponses, storedRespErrs := deps.processStoredResponses(ctx, req.Imp)
+	if len(storedRespErrs) > 0 {
+		errL = append(errL, storedRespErrs...)
+		writeError(errL, w, &labels)
+		return
+	}
+
 	secGPC := r.Header.Get("Sec-GPC")
 
 	auctionRequest := exchange.AuctionRequest{
@@ -193,6 +231,9 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		Warnings:                   warnings,
 		GlobalPrivacyControlHeader: secGPC,
 		ImpExtInfoMap:              impExtInfoMap,
+		StoredAuctionResponses:     storedAuctionResponses,
+		StoredBidResponses:         storedBidResponses,
+		HookExecutor:               hookExecutor,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
@@ -225,6 +266,15 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	}
 }
 
+// parseUsersyncs reads the UIDs of the user from the UID store if there's one, and from the uids cookie otherwise.
+// The UIDs are kept in the store under the host cookie, or under the firstPartyID if the request has no host cookie.
+func (deps *endpointDeps) parseUsersyncs(ctx context.Context, r *http.Request, firstPartyID string) *usersync.Cookie {
+	if deps.uidStore != nil {
+		return usersync.ParseCookieFromStore(ctx, r, &(deps.cfg.HostCookie), deps.uidStore, firstPartyID)
+	}
+	return usersync.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
+}
+
 // parseRequest turns the HTTP request into an OpenRTB request. This is guaranteed to return:
 //
 //   - A context which times out appropriately, given the request.
@@ -235,7 +285,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 // possible, it will return errors with messages that suggest improvements.
 //
 // If the errors list has at least one element, then no guarantees are made about the returned request.
-func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb_ext.RequestWrapper, impExtInfoMap map[string]exchange.ImpExtInfo, errs []error) {
+func (deps *endpointDeps) parseRequest(httpRequest *http.Request, hookExecutor hookexecution.StageExecutor) (req *openrtb_ext.RequestWrapper, impExtInfoMap map[string]exchange.ImpExtInfo, account requestAccount, errs []error) {
 	req = &openrtb_ext.RequestWrapper{}
 	req.BidRequest = &openrtb2.BidRequest{}
 	errs = nil
@@ -258,8 +308,12 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb_
 		}
 	}
 
+	if requestJson, errs = executeRequestStages(httpRequest, requestJson, hookExecutor); len(errs) > 0 {
+		return
+	}
+
 	timeout := parseTimeout(requestJson, time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(tracing.DetachedContext(httpRequest.Context()), timeout)
 	defer cancel()
 
 	// Fetch the Stored Request data and merge it into the HTTP request.
@@ -267,6 +321,11 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb_
 		return
 	}
 
+	if requestJson, err = moveGPPToRegsExt(requestJson); err != nil {
+		errs = []error{err}
+		return
+	}
+
 	if err := json.Unmarshal(requestJson, req.BidRequest); err != nil {
 		errs = []error{err}
 		return
@@ -282,6 +341,9 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb_
 
 	lmt.ModifyForIOS(req.BidRequest)
 
+	account, warnings := deps.applyAccountBidders(ctx, req)
+	errs = append(errs, warnings...)
+
 	errL := deps.validateRequest(req)
 	if len(errL) > 0 {
 		errs = append(errs, errL...)
@@ -305,6 +367,28 @@ func parseTimeout(requestJson []byte, defaultTimeout time.Duration) time.Duratio
 	return defaultTimeout
 }
 
+// moveGPPToRegsExt moves the OpenRTB 2.6 request.regs.gpp and request.regs.gpp_sid fields, which the bid request
+// model does not support yet, to request.regs.ext. Values already present in request.regs.ext take precedence.
+func moveGPPToRegsExt(requestJson []byte) ([]byte, error) {
+	for _, field := range []string{"gpp", "gpp_sid"} {
+		value, dataType, _, err := jsonparser.Get(requestJson, "regs", field)
+		if dataType == jsonparser.NotExist || err != nil {
+			continue
+		}
+		if dataType == jsonparser.String {
+			value = []byte(`"` + string(value) + `"`)
+		}
+
+		if _, extDataType, _, _ := jsonparser.Get(requestJson, "regs", "ext", field); extDataType == jsonparser.NotExist {
+			if requestJson, err = jsonparser.Set(requestJson, value, "regs", "ext", field); err != nil {
+				return nil, fmt.Errorf("request.regs.%s cannot be moved to request.regs.ext: %v", field, err)
+			}
+		}
+		requestJson = jsonparser.Delete(requestJson, "regs", field)
+	}
+	return requestJson, nil
+}
+
 func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper) []error {
 	errL := []error{}
 	if req.ID == "" {
@@ -351,6 +435,10 @@ func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper) []err
 			return []error{err}
 		}
 
+		if err := bidadjustment.Validate(reqPrebid.BidAdjustments); err != nil {
+			return []error{fmt.Errorf("request.ext.prebid.bidadjustments: %v", err)}
+		}
+
 		if err := validateSChains(reqPrebid.SChains); err != nil {
 			return []error{err}
 		}
@@ -359,9 +447,20 @@ func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper) []err
 			return []error{err}
 		}
 
+		if err := deps.validateFirstPartyData(reqPrebid, aliases); err != nil {
+			return []error{err}
+		}
+
 		if err := validateCustomRates(reqPrebid.CurrencyConversions); err != nil {
 			return []error{err}
 		}
+
+		if len(reqPrebid.MultiBid) > 0 {
+			multiBid, multiBidErrs := openrtb_ext.ValidateAndBuildExtMultiBid(reqPrebid)
+			errL = append(errL, multiBidErrs...)
+			reqPrebid.MultiBid = multiBid
+			reqExt.SetPrebid(reqPrebid)
+		}
 	}
 
 	if (req.Site == nil && req.App == nil) || (req.Site != nil && req.App != nil) {
@@ -405,6 +504,21 @@ func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper) []err
 		}
 	}
 
+	if gppPolicy, err := gpp.ReadFromRequestWrapper(req); err != nil {
+		return append(errL, err)
+	} else if gppPolicy.Consent != "" {
+		if _, err := gpp.Parse(gppPolicy.Consent); err != nil {
+			errL = append(errL, &errortypes.Warning{
+				Message:     fmt.Sprintf("GPP consent is invalid and will be ignored. (%v)", err),
+				WarningCode: errortypes.InvalidPrivacyConsentWarningCode})
+			regsExt, err := req.GetRegExt()
+			if err != nil {
+				return append(errL, err)
+			}
+			regsExt.SetGPP("")
+		}
+	}
+
 	impIDs := make(map[string]int, len(req.Imp))
 	for index := range req.Imp {
 		imp := &req.Imp[index]
@@ -509,6 +623,48 @@ func (deps *endpointDeps) validateEidPermissions(prebid *openrtb_ext.ExtRequestP
 	return nil
 }
 
+func (deps *endpointDeps) validateFirstPartyData(prebid *openrtb_ext.ExtRequestPrebid, aliases map[string]string) error {
+	if prebid.Data != nil {
+		for _, bidder := range prebid.Data.Bidders {
+			if err := validateFirstPartyDataBidder(bidder, deps.bidderMap, aliases); err != nil {
+				return fmt.Errorf(`request.ext.prebid.data.bidders contains %v`, err)
+			}
+		}
+	}
+
+	configuredBidders := make(map[string]struct{})
+	for i, bidderConfig := range prebid.BidderConfigs {
+		if len(bidderConfig.Bidders) == 0 {
+			return fmt.Errorf(`request.ext.prebid.bidderconfig[%d] missing or empty required field: "bidders"`, i)
+		}
+
+		if bidderConfig.Config == nil || bidderConfig.Config.ORTB2 == nil {
+			return fmt.Errorf(`request.ext.prebid.bidderconfig[%d] missing required field: "config.ortb2"`, i)
+		}
+
+		for _, bidder := range bidderConfig.Bidders {
+			if err := validateFirstPartyDataBidder(bidder, deps.bidderMap, aliases); err != nil {
+				return fmt.Errorf(`request.ext.prebid.bidderconfig[%d] contains %v`, i, err)
+			}
+			if _, exists := configuredBidders[bidder]; exists {
+				return fmt.Errorf(`request.ext.prebid.bidderconfig[%d] contains bidder "%v" which is already configured`, i, bidder)
+			}
+			configuredBidders[bidder] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+func validateFirstPartyDataBidder(bidder string, knownBidders map[string]openrtb_ext.BidderName, knownAliases map[string]string) error {
+	_, isCoreBidder := knownBidders[bidder]
+	_, isAlias := knownAliases[bidder]
+	if !isCoreBidder && !isAlias {
+		return fmt.Errorf(`unrecognized bidder "%v"`, bidder)
+	}
+	return nil
+}
+
 func validateBidders(bidders []string, knownBidders map[string]openrtb_ext.BidderName, knownAliases map[string]string) error {
 	for _, bidder := range bidders {
 		if bidder == "*" {
@@ -1135,7 +1291,7 @@ func (deps *endpointDeps) validateUser(req *openrtb_ext.RequestWrapper, aliases
 	// Check if the buyeruids are valid
 	prebid := userExt.GetPrebid()
 	if prebid != nil {
-		if len(prebid.BuyerUIDs) < 1 {
+		if len(prebid.BuyerUIDs) < 1 && prebid.FirstPartyID == "" {
 			return errors.New(`request.user.ext.prebid requires a "buyeruids" property with at least one ID defined. If none exist, then request.user.ext.prebid should not be defined.`)
 		}
 		for bidderName := range prebid.BuyerUIDs {
@@ -1333,7 +1489,11 @@ func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson
 	if hasStoredBidRequest {
 		storedReqIds = []string{storedBidRequestId}
 	}
+	ctx, span := tracing.StartSpan(ctx, "stored_requests.fetch", trace.SpanKindInternal)
+	span.SetAttributes(attribute.Int("stored_requests", len(storedReqIds)), attribute.Int("stored_imps", len(impIds)))
 	storedRequests, storedImps, errs := deps.storedReqFetcher.FetchRequests(ctx, storedReqIds, impIds)
+	recordSpanErrors(span, errs)
+	span.End()
 	if len(errs) != 0 {
 		return nil, nil, errs
 	}
@@ -1551,6 +1711,57 @@ func writeError(errs []error, w http.ResponseWriter, labels *metrics.Labels) boo
 	return rc
 }
 
+// executeRequestStages runs the entrypoint and raw-auction-request hooks on the request body.
+func executeRequestStages(httpRequest *http.Request, requestJson []byte, hookExecutor hookexecution.StageExecutor) ([]byte, []error) {
+	requestJson, rejectErr := hookExecutor.ExecuteEntrypointStage(httpRequest, requestJson)
+	if rejectErr != nil {
+		return requestJson, []error{rejectErr}
+	}
+
+	requestJson, rejectErr = hookExecutor.ExecuteRawAuctionStage(requestJson)
+	if rejectErr != nil {
+		return requestJson, []error{rejectErr}
+	}
+
+	return requestJson, nil
+}
+
+// rejectAuctionRequest answers a request rejected by a module hook with a response holding no bids but the
+// no-bid reason given by the hook. The request and the account are nil if they were not resolved yet.
+func rejectAuctionRequest(rejectErr hookexecution.RejectError, w http.ResponseWriter, hookExecutor hookexecution.StageExecutor, request *openrtb2.BidRequest, account *config.Account, errs *[]error) *openrtb2.BidResponse {
+	response := &openrtb2.BidResponse{NBR: openrtb2.NoBidReasonCode(rejectErr.NBR).Ptr()}
+	if request != nil {
+		response.ID = request.ID
+	}
+	*errs = append(*errs, &rejectErr)
+
+	var err error
+	if response.Ext, err = hookexecution.EnrichExtBidResponse(nil, hookExecutor.GetOutcomes(), request, account); err != nil {
+		*errs = append(*errs, err)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	w.Header().Set("Content-Type", "application/json")
+	if err := enc.Encode(response); err != nil {
+		*errs = append(*errs, fmt.Errorf("Failed to send response: %v", err))
+	}
+
+	return response
+}
+
+// getFirstPartyID returns the user.ext.prebid.fpid of the request
+func getFirstPartyID(req *openrtb_ext.RequestWrapper) string {
+	userExt, err := req.GetUserExt()
+	if err != nil {
+		return ""
+	}
+	if prebid := userExt.GetPrebid(); prebid != nil {
+		return prebid.FirstPartyID
+	}
+	return ""
+}
+
 // Returns the account ID for the request
 func getAccountID(pub *openrtb2.Publisher) string {
 	if pub != nil {
@@ -1567,3 +1778,10 @@ func getAccountID(pub *openrtb2.Publisher) string {
 	}
 	return metrics.PublisherUnknown
 }
+
+// recordSpanErrors marks the span as failed with the first of the errors.
+func recordSpanErrors(span trace.Span, errs []error) {
+	if len(errs) > 0 {
+		tracing.RecordError(span, errs[0])
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/exchange"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
@@ -69,7 +70,7 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 
 	nilMetrics := &metricsConfig.DummyMetricsEngine{}
 
-	adapters, adaptersErr := exchange.BuildAdapters(server.Client(), &config.Configuration{}, infos, nilMetrics)
+	adapters, adaptersErr := exchange.BuildAdapters(server.Client(), &config.Configuration{}, infos, nilMetrics, nil)
 	if adaptersErr != nil {
 		b.Fatal("unable to build adapters")
 	}
@@ -84,6 +85,7 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		gdpr.AlwaysAllow{},
 		currency.NewRateConverter(&http.Client{}, "", time.Duration(0)),
 		empty_fetcher.EmptyFetcher{},
+		nil,
 	)
 
 	endpoint, _ := NewEndpoint(
@@ -97,6 +99,9 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		map[string]string{},
 		[]byte{},
 		nil,
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	)
 
 	b.ResetTimer()
@@ -22,11 +22,13 @@ import (
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/metrics"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/iputil"
 
 	"github.com/buger/jsonparser"
@@ -439,7 +441,10 @@ func TestExplicitUserId(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(),
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil)
 
 	endpoint(httptest.NewRecorder(), request, nil)
 
@@ -456,6 +461,47 @@ func TestExplicitUserId(t *testing.T) {
 	}
 }
 
+// TestUserSyncsFromUIDStore makes sure that the UIDs are read from the UID store, under the user.ext.prebid.fpid of the request.
+func TestUserSyncsFromUIDStore(t *testing.T) {
+	cfg := &config.Configuration{MaxRequestSize: maxSize}
+	ex := &mockExchange{}
+	store := usersync.NewMemoryUIDStore(time.Hour)
+	store.Set(context.Background(), "fp:first-party-id", "adnxs", "789")
+	store.Set(context.Background(), "fp:exchange-user-id", "adnxs", "456")
+
+	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(`{
+		"id": "some-request-id",
+		"site": {"page": "test.somepage.com"},
+		"user": {"id": "exchange-user-id", "ext": {"prebid": {"fpid": "first-party-id"}}},
+		"imp": [{"id": "my-imp-id", "banner": {"format": [{"w": 300, "h": 600}]}, "ext": {"appnexus": {"placementId": 12883451}}}]
+	}`))
+	pbsCookie := usersync.NewCookie()
+	pbsCookie.TrySync("adnxs", "123")
+	request.AddCookie(pbsCookie.ToHTTPCookie(time.Hour))
+
+	endpoint, _ := NewEndpoint(
+		ex,
+		newParamsValidator(t),
+		empty_fetcher.EmptyFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		cfg,
+		&metricsConfig.DummyMetricsEngine{},
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		store)
+
+	endpoint(httptest.NewRecorder(), request, nil)
+
+	if assert.NotNil(t, ex.lastUserSyncs, "The request never made it into the Exchange.") {
+		uid, _, _ := ex.lastUserSyncs.GetUID("adnxs")
+		assert.Equal(t, "789", uid)
+	}
+}
+
 func doRequest(t *testing.T, test testCase) (int, string) {
 	bidderInfos := getBidderInfos(test.Config.getAdaptersConfigMap(), openrtb_ext.CoreBidderNames())
 	bidderMap := exchange.GetActiveBidders(bidderInfos)
@@ -480,7 +526,10 @@ func doRequest(t *testing.T, test testCase) (int, string) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		disabledBidders,
 		[]byte(test.Config.AliasJSON),
-		bidderMap)
+		bidderMap,
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(test.BidRequest))
 	recorder := httptest.NewRecorder()
@@ -543,7 +592,10 @@ func doBadAliasRequest(t *testing.T, filename string, expectMsg string) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		disabledBidders,
 		aliasJSON,
-		bidderMap)
+		bidderMap,
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(testBidRequest))
 	recorder := httptest.NewRecorder()
@@ -592,7 +644,10 @@ func TestNilExchange(t *testing.T) {
 		&metricsConfig.DummyMetricsEngine{},
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}), map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(),
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil)
 
 	if err == nil {
 		t.Errorf("NewEndpoint should return an error when given a nil Exchange.")
@@ -613,7 +668,10 @@ func TestNilValidator(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(),
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil)
 
 	if err == nil {
 		t.Errorf("NewEndpoint should return an error when given a nil BidderParamValidator.")
@@ -634,7 +692,10 @@ func TestExchangeError(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(),
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -756,7 +817,10 @@ func TestImplicitIPsEndToEnd(t *testing.T) {
 			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 			map[string]string{},
 			[]byte{},
-			openrtb_ext.BuildBidderMap())
+			openrtb_ext.BuildBidderMap(),
+			empty_fetcher.EmptyFetcher{},
+			hooks.EmptyPlanBuilder{},
+			nil)
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
 		httpReq.Header.Set("X-Forwarded-For", test.xForwardedForHeader)
@@ -950,7 +1014,10 @@ func TestImplicitDNTEndToEnd(t *testing.T) {
 			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 			map[string]string{},
 			[]byte{},
-			openrtb_ext.BuildBidderMap())
+			openrtb_ext.BuildBidderMap(),
+			empty_fetcher.EmptyFetcher{},
+			hooks.EmptyPlanBuilder{},
+			nil)
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
 		httpReq.Header.Set("DNT", test.dntHeader)
@@ -1018,6 +1085,9 @@ func TestStoredRequests(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	testStoreVideoAttr := []bool{true, true, false, false}
@@ -1064,6 +1134,9 @@ func TestStoredRequestsVideoErrors(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	// tests processStoredRequests function behavior in parsing incorrect input related to echovideoattrs feature
@@ -1100,6 +1173,9 @@ func TestOversizedRequest(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -1135,6 +1211,9 @@ func TestRequestSizeEdgeCase(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -1164,6 +1243,9 @@ func TestNoEncoding(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -1239,6 +1321,9 @@ func TestContentType(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -1563,6 +1648,9 @@ func TestValidateImpExt(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	for _, group := range testGroups {
@@ -1609,6 +1697,9 @@ func TestCurrencyTrunc(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1653,6 +1744,9 @@ func TestCCPAInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1684,6 +1778,159 @@ func TestCCPAInvalid(t *testing.T) {
 	assert.ElementsMatch(t, errL, []error{&expectedWarning})
 }
 
+func TestGPPInvalid(t *testing.T) {
+	deps := &endpointDeps{
+		&nobidExchange{},
+		newParamsValidator(t),
+		&mockStoredReqFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{},
+		&metricsConfig.DummyMetricsEngine{},
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		false,
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+		nil,
+		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
+	}
+
+	ui := int64(1)
+	req := openrtb2.BidRequest{
+		ID: "anyRequestID",
+		Imp: []openrtb2.Imp{
+			{
+				ID: "anyImpID",
+				Banner: &openrtb2.Banner{
+					W: &ui,
+					H: &ui,
+				},
+				Ext: json.RawMessage(`{"appnexus": {"placementId": 5667}}`),
+			},
+		},
+		Site: &openrtb2.Site{
+			ID: "anySiteID",
+		},
+		Regs: &openrtb2.Regs{
+			Ext: json.RawMessage(`{"gpp": "DBACNY~invalid", "gpp_sid": [2]}`),
+		},
+	}
+	reqWrapper := &openrtb_ext.RequestWrapper{BidRequest: &req}
+
+	errL := deps.validateRequest(reqWrapper)
+
+	expectedWarning := errortypes.Warning{
+		Message:     "GPP consent is invalid and will be ignored. (GPP header lists 2 sections but the string contains 1)",
+		WarningCode: errortypes.InvalidPrivacyConsentWarningCode}
+	assert.ElementsMatch(t, errL, []error{&expectedWarning})
+
+	assert.NoError(t, reqWrapper.RebuildRequest())
+	assert.JSONEq(t, `{"gpp_sid": [2]}`, string(req.Regs.Ext))
+}
+
+func TestMoveGPPToRegsExt(t *testing.T) {
+	testCases := []struct {
+		description  string
+		givenRequest string
+		expectedRegs string
+	}{
+		{
+			description:  "No regs",
+			givenRequest: `{"id":"anyRequestID"}`,
+		},
+		{
+			description:  "OpenRTB 2.6 fields moved",
+			givenRequest: `{"id":"anyRequestID","regs":{"coppa":1,"gpp":"DBABMA~anyConsent","gpp_sid":[2]}}`,
+			expectedRegs: `{"coppa":1,"ext":{"gpp":"DBABMA~anyConsent","gpp_sid":[2]}}`,
+		},
+		{
+			description:  "OpenRTB 2.6 fields moved to existing ext",
+			givenRequest: `{"id":"anyRequestID","regs":{"gpp":"DBABMA~anyConsent","ext":{"us_privacy":"1NYN"}}}`,
+			expectedRegs: `{"ext":{"us_privacy":"1NYN","gpp":"DBABMA~anyConsent"}}`,
+		},
+		{
+			description:  "Ext fields take precedence",
+			givenRequest: `{"id":"anyRequestID","regs":{"gpp":"DBABMA~anyConsent","gpp_sid":[2],"ext":{"gpp":"DBABMA~extConsent"}}}`,
+			expectedRegs: `{"ext":{"gpp":"DBABMA~extConsent","gpp_sid":[2]}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := moveGPPToRegsExt([]byte(test.givenRequest))
+		assert.NoError(t, err, test.description)
+
+		regs, _, _, _ := jsonparser.Get(result, "regs")
+		if test.expectedRegs == "" {
+			assert.Empty(t, regs, test.description)
+		} else {
+			assert.JSONEq(t, test.expectedRegs, string(regs), test.description)
+		}
+	}
+}
+
+func TestValidateRequestMultiBid(t *testing.T) {
+	deps := &endpointDeps{
+		&nobidExchange{},
+		newParamsValidator(t),
+		&mockStoredReqFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{},
+		&metricsConfig.DummyMetricsEngine{},
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		false,
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+		nil,
+		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
+	}
+
+	ui := int64(1)
+	req := openrtb2.BidRequest{
+		ID: "anyRequestID",
+		Imp: []openrtb2.Imp{
+			{
+				ID: "anyImpID",
+				Banner: &openrtb2.Banner{
+					W: &ui,
+					H: &ui,
+				},
+				Ext: json.RawMessage(`{"appnexus": {"placementId": 5667}}`),
+			},
+		},
+		Site: &openrtb2.Site{
+			ID: "anySiteID",
+		},
+		Ext: json.RawMessage(`{"prebid":{"multibid":[{"bidder":"appnexus","maxbids":20,"targetbiddercodeprefix":"apn"},{"bidders":["appnexus"],"maxbids":2}]}}`),
+	}
+	reqWrapper := &openrtb_ext.RequestWrapper{BidRequest: &req}
+
+	errL := deps.validateRequest(reqWrapper)
+
+	expectedWarnings := []error{
+		&errortypes.Warning{
+			Message:     "request.ext.prebid.multibid[0].maxbids must be at most 9, using 9",
+			WarningCode: errortypes.MultiBidWarningCode},
+		&errortypes.Warning{
+			Message:     "request.ext.prebid.multibid[1] ignored for bidder appnexus: multibid is already defined for it",
+			WarningCode: errortypes.MultiBidWarningCode},
+	}
+	assert.Equal(t, expectedWarnings, errL)
+
+	assert.NoError(t, reqWrapper.RebuildRequest())
+	assert.JSONEq(t, `{"prebid":{"multibid":[{"bidder":"appnexus","maxbids":9,"targetbiddercodeprefix":"apn"}]}}`, string(req.Ext))
+}
+
 func TestNoSaleInvalid(t *testing.T) {
 	deps := &endpointDeps{
 		&nobidExchange{},
@@ -1701,6 +1948,9 @@ func TestNoSaleInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1731,6 +1981,44 @@ func TestNoSaleInvalid(t *testing.T) {
 	assert.ElementsMatch(t, errL, []error{expectedError})
 }
 
+func TestValidateUserExtPrebid(t *testing.T) {
+	deps := &endpointDeps{bidderMap: openrtb_ext.BuildBidderMap()}
+
+	testCases := []struct {
+		description   string
+		givenUserExt  string
+		expectedError string
+	}{
+		{
+			description:  "BuyerUIDs",
+			givenUserExt: `{"prebid":{"buyeruids":{"appnexus":"123"}}}`,
+		},
+		{
+			description:  "First Party ID",
+			givenUserExt: `{"prebid":{"fpid":"first-party-id"}}`,
+		},
+		{
+			description:   "Empty",
+			givenUserExt:  `{"prebid":{}}`,
+			expectedError: `request.user.ext.prebid requires a "buyeruids" property with at least one ID defined. If none exist, then request.user.ext.prebid should not be defined.`,
+		},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+			User: &openrtb2.User{Ext: json.RawMessage(test.givenUserExt)},
+		}}
+
+		err := deps.validateUser(req, nil)
+
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+	}
+}
+
 func TestValidateSourceTID(t *testing.T) {
 	cfg := &config.Configuration{
 		AutoGenSourceTID: true,
@@ -1752,6 +2040,9 @@ func TestValidateSourceTID(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1793,6 +2084,9 @@ func TestSChainInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -2012,6 +2306,9 @@ func TestEidPermissionsInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -2137,6 +2434,82 @@ func TestValidateEidPermissions(t *testing.T) {
 	}
 }
 
+func TestValidateFirstPartyData(t *testing.T) {
+	knownBidders := map[string]openrtb_ext.BidderName{"a": openrtb_ext.BidderName("a")}
+	knownAliases := map[string]string{"b": "b"}
+	ortb2 := &openrtb_ext.Config{ORTB2: &openrtb_ext.ORTB2{Site: json.RawMessage(`{"keywords":"sports"}`)}}
+
+	testCases := []struct {
+		description   string
+		prebid        *openrtb_ext.ExtRequestPrebid
+		expectedError error
+	}{
+		{
+			description:   "Valid - Empty",
+			prebid:        &openrtb_ext.ExtRequestPrebid{},
+			expectedError: nil,
+		},
+		{
+			description: "Valid - Data Bidders",
+			prebid: &openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"a", "b"}},
+			},
+			expectedError: nil,
+		},
+		{
+			description: "Valid - Bidder Configs",
+			prebid: &openrtb_ext.ExtRequestPrebid{BidderConfigs: []openrtb_ext.BidderConfig{
+				{Bidders: []string{"a"}, Config: ortb2},
+				{Bidders: []string{"b"}, Config: ortb2},
+			}},
+			expectedError: nil,
+		},
+		{
+			description: "Invalid - Data Bidders - Unknown Bidder",
+			prebid: &openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"a", "z"}},
+			},
+			expectedError: errors.New(`request.ext.prebid.data.bidders contains unrecognized bidder "z"`),
+		},
+		{
+			description: "Invalid - Bidder Configs - Missing Bidders",
+			prebid: &openrtb_ext.ExtRequestPrebid{BidderConfigs: []openrtb_ext.BidderConfig{
+				{Bidders: []string{"a"}, Config: ortb2},
+				{Config: ortb2},
+			}},
+			expectedError: errors.New(`request.ext.prebid.bidderconfig[1] missing or empty required field: "bidders"`),
+		},
+		{
+			description: "Invalid - Bidder Configs - Missing ORTB2",
+			prebid: &openrtb_ext.ExtRequestPrebid{BidderConfigs: []openrtb_ext.BidderConfig{
+				{Bidders: []string{"a"}, Config: &openrtb_ext.Config{}},
+			}},
+			expectedError: errors.New(`request.ext.prebid.bidderconfig[0] missing required field: "config.ortb2"`),
+		},
+		{
+			description: "Invalid - Bidder Configs - Unknown Bidder",
+			prebid: &openrtb_ext.ExtRequestPrebid{BidderConfigs: []openrtb_ext.BidderConfig{
+				{Bidders: []string{"z"}, Config: ortb2},
+			}},
+			expectedError: errors.New(`request.ext.prebid.bidderconfig[0] contains unrecognized bidder "z"`),
+		},
+		{
+			description: "Invalid - Bidder Configs - Duplicate Bidder",
+			prebid: &openrtb_ext.ExtRequestPrebid{BidderConfigs: []openrtb_ext.BidderConfig{
+				{Bidders: []string{"a", "b"}, Config: ortb2},
+				{Bidders: []string{"a"}, Config: ortb2},
+			}},
+			expectedError: errors.New(`request.ext.prebid.bidderconfig[1] contains bidder "a" which is already configured`),
+		},
+	}
+
+	endpoint := &endpointDeps{bidderMap: knownBidders}
+	for _, test := range testCases {
+		result := endpoint.validateFirstPartyData(test.prebid, knownAliases)
+		assert.Equal(t, test.expectedError, result, test.description)
+	}
+}
+
 func TestValidateBidders(t *testing.T) {
 	testCases := []struct {
 		description   string
@@ -2257,7 +2630,10 @@ func TestIOS14EndToEnd(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(),
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil)
 
 	httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "app-ios140-no-ifa.json")))
 
@@ -2290,6 +2666,9 @@ func TestAuctionWarnings(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -3437,6 +3816,10 @@ func (cf mockStoredReqFetcher) FetchRequests(ctx context.Context, requestIDs []s
 	return testStoredRequestData, testStoredImpData, nil
 }
 
+func (cf mockStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return nil, nil
+}
+
 var mockAccountData = map[string]json.RawMessage{
 	"valid_acct": json.RawMessage(`{"disabled":false}`),
 }
@@ -3453,11 +3836,13 @@ func (af mockAccountFetcher) FetchAccount(ctx context.Context, accountID string)
 }
 
 type mockExchange struct {
-	lastRequest *openrtb2.BidRequest
+	lastRequest   *openrtb2.BidRequest
+	lastUserSyncs exchange.IdFetcher
 }
 
 func (m *mockExchange) HoldAuction(ctx context.Context, r exchange.AuctionRequest, debugLog *exchange.DebugLog) (*openrtb2.BidResponse, error) {
 	m.lastRequest = r.BidRequest
+	m.lastUserSyncs = r.UserSyncs
 	return &openrtb2.BidResponse{
 		SeatBid: []openrtb2.SeatBid{{
 			Bid: []openrtb2.Bid{{
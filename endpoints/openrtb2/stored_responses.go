@@ -0,0 +1,112 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// processStoredResponses resolves the stored responses which the imps ask for in imp.ext.prebid.storedauctionresponse
+// and imp.ext.prebid.storedbidresponse. It returns the stored auction responses keyed by imp ID, and the stored bid
+// responses keyed by imp ID then bidder.
+//
+// A stored auction response replaces the whole auction, so it must be set on every imp when it is set on one of them.
+func (deps *endpointDeps) processStoredResponses(ctx context.Context, imps []openrtb2.Imp) (map[string]json.RawMessage, map[string]map[string]json.RawMessage, []error) {
+	auctionResponseIDs := make(map[string]string)
+	bidResponseIDs := make(map[string]map[string]string)
+	var storedResponseIDs []string
+
+	for i, imp := range imps {
+		extPrebid, impExt, err := getImpExtPrebid(imp)
+		if err != nil {
+			return nil, nil, []error{fmt.Errorf("request.imp[%d].ext is invalid: %v", i, err)}
+		}
+
+		if extPrebid.StoredAuctionResponse != nil {
+			if extPrebid.StoredAuctionResponse.ID == "" {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedauctionresponse.id is required", i)}
+			}
+			if len(extPrebid.StoredBidResponse) > 0 {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid has both storedauctionresponse and storedbidresponse, only one of them is allowed", i)}
+			}
+			auctionResponseIDs[imp.ID] = extPrebid.StoredAuctionResponse.ID
+			storedResponseIDs = append(storedResponseIDs, extPrebid.StoredAuctionResponse.ID)
+		}
+
+		for _, storedBidResponse := range extPrebid.StoredBidResponse {
+			if storedBidResponse.ID == "" || storedBidResponse.Bidder == "" {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedbidresponse requires the bidder and id fields", i)}
+			}
+			_, isPrebidBidder := extPrebid.Bidder[storedBidResponse.Bidder]
+			_, isExtBidder := impExt[storedBidResponse.Bidder]
+			if !isPrebidBidder && !(isExtBidder && isBidderToValidate(storedBidResponse.Bidder)) {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedbidresponse bidder %s is not one of the imp bidders", i, storedBidResponse.Bidder)}
+			}
+			if bidResponseIDs[imp.ID] == nil {
+				bidResponseIDs[imp.ID] = make(map[string]string)
+			}
+			bidResponseIDs[imp.ID][storedBidResponse.Bidder] = storedBidResponse.ID
+			storedResponseIDs = append(storedResponseIDs, storedBidResponse.ID)
+		}
+	}
+
+	if len(storedResponseIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	if len(auctionResponseIDs) > 0 {
+		for i, imp := range imps {
+			if _, ok := auctionResponseIDs[imp.ID]; !ok {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedauctionresponse is required, since other imps of the request have one", i)}
+			}
+		}
+	}
+
+	storedResponses, errs := deps.storedRespFetcher.FetchResponses(ctx, storedResponseIDs)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	var storedAuctionResponses map[string]json.RawMessage
+	if len(auctionResponseIDs) > 0 {
+		storedAuctionResponses = make(map[string]json.RawMessage, len(auctionResponseIDs))
+		for impID, storedResponseID := range auctionResponseIDs {
+			storedAuctionResponses[impID] = storedResponses[storedResponseID]
+		}
+	}
+
+	var storedBidResponses map[string]map[string]json.RawMessage
+	if len(bidResponseIDs) > 0 {
+		storedBidResponses = make(map[string]map[string]json.RawMessage, len(bidResponseIDs))
+		for impID, storedResponseIDsByBidder := range bidResponseIDs {
+			storedBidResponses[impID] = make(map[string]json.RawMessage, len(storedResponseIDsByBidder))
+			for bidder, storedResponseID := range storedResponseIDsByBidder {
+				storedBidResponses[impID][bidder] = storedResponses[storedResponseID]
+			}
+		}
+	}
+
+	return storedAuctionResponses, storedBidResponses, nil
+}
+
+// getImpExtPrebid returns the imp.ext.prebid object along with the other imp.ext fields.
+func getImpExtPrebid(imp openrtb2.Imp) (openrtb_ext.ExtImpPrebid, map[string]json.RawMessage, error) {
+	var extPrebid openrtb_ext.ExtImpPrebid
+	if len(imp.Ext) == 0 {
+		return extPrebid, nil, nil
+	}
+
+	var impExt map[string]json.RawMessage
+	if err := json.Unmarshal(imp.Ext, &impExt); err != nil {
+		return extPrebid, nil, err
+	}
+	if extPrebidJSON, ok := impExt[openrtb_ext.PrebidExtKey]; ok {
+		if err := json.Unmarshal(extPrebidJSON, &extPrebid); err != nil {
+			return extPrebid, nil, err
+		}
+	}
+	return extPrebid, impExt, nil
+}
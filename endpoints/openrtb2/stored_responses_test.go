@@ -0,0 +1,142 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStoredResponseFetcher struct {
+	data map[string]json.RawMessage
+}
+
+func (cf *mockStoredResponseFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error) {
+	return nil, nil, nil
+}
+
+func (cf *mockStoredResponseFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	data = make(map[string]json.RawMessage, len(ids))
+	for _, id := range ids {
+		if response, ok := cf.data[id]; ok {
+			data[id] = response
+		} else {
+			errs = append(errs, stored_requests.NotFoundError{ID: id, DataType: "Response"})
+		}
+	}
+	return data, errs
+}
+
+func TestProcessStoredResponses(t *testing.T) {
+	deps := &endpointDeps{
+		storedRespFetcher: &mockStoredResponseFetcher{
+			data: map[string]json.RawMessage{
+				"auction-1": json.RawMessage(`[{"bid":[{"id":"bid-1","price":1}],"seat":"appnexus"}]`),
+				"auction-2": json.RawMessage(`[{"bid":[{"id":"bid-2","price":2}],"seat":"appnexus"}]`),
+				"bid-1":     json.RawMessage(`{"id":"response-1"}`),
+				"bid-2":     json.RawMessage(`{"id":"response-2"}`),
+			},
+		},
+	}
+
+	testCases := []struct {
+		description                    string
+		imps                           []openrtb2.Imp
+		expectedStoredAuctionResponses map[string]json.RawMessage
+		expectedStoredBidResponses     map[string]map[string]json.RawMessage
+		expectedErrs                   []error
+	}{
+		{
+			description: "No stored responses",
+			imps: []openrtb2.Imp{
+				{ID: "imp-1", Ext: json.RawMessage(`{"appnexus":{"placementId":1}}`)},
+				{ID: "imp-2"},
+			},
+		},
+		{
+			description: "Stored auction responses on every imp",
+			imps: []openrtb2.Imp{
+				{ID: "imp-1", Ext: json.RawMessage(`{"appnexus":{"placementId":1},"prebid":{"storedauctionresponse":{"id":"auction-1"}}}`)},
+				{ID: "imp-2", Ext: json.RawMessage(`{"appnexus":{"placementId":1},"prebid":{"storedauctionresponse":{"id":"auction-2"}}}`)},
+			},
+			expectedStoredAuctionResponses: map[string]json.RawMessage{
+				"imp-1": json.RawMessage(`[{"bid":[{"id":"bid-1","price":1}],"seat":"appnexus"}]`),
+				"imp-2": json.RawMessage(`[{"bid":[{"id":"bid-2","price":2}],"seat":"appnexus"}]`),
+			},
+		},
+		{
+			description: "Stored bid responses for imp.ext and imp.ext.prebid.bidder bidders",
+			imps: []openrtb2.Imp{
+				{ID: "imp-1", Ext: json.RawMessage(`{"appnexus":{"placementId":1},"prebid":{"storedbidresponse":[{"bidder":"appnexus","id":"bid-1"}]}}`)},
+				{ID: "imp-2", Ext: json.RawMessage(`{"prebid":{"bidder":{"rubicon":{}},"storedbidresponse":[{"bidder":"rubicon","id":"bid-2"}]}}`)},
+				{ID: "imp-3", Ext: json.RawMessage(`{"appnexus":{"placementId":1}}`)},
+			},
+			expectedStoredBidResponses: map[string]map[string]json.RawMessage{
+				"imp-1": {"appnexus": json.RawMessage(`{"id":"response-1"}`)},
+				"imp-2": {"rubicon": json.RawMessage(`{"id":"response-2"}`)},
+			},
+		},
+		{
+			description: "Invalid imp.ext",
+			imps: []openrtb2.Imp{
+				{ID: "imp-1", Ext: json.RawMessage(`{"prebid":[]}`)},
+			},
+			expectedErrs: []error{errors.New("request.imp[0].ext is invalid: json: cannot unmarshal array into Go value of type openrtb_ext.ExtImpPrebid")},
+		},
+		{
+			description: "Stored auction response without id",
+			imps: []openrtb2.Imp{
+				{ID: "imp-1", Ext: json.RawMessage(`{"prebid":{"storedauctionresponse":{}}}`)},
+			},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid.storedauctionresponse.id is required")},
+		},
+		{
+			description: "Stored auction and bid responses on the same imp",
+			imps: []openrtb2.Imp{
+				{ID: "imp-1", Ext: json.RawMessage(`{"appnexus":{"placementId":1},"prebid":{"storedauctionresponse":{"id":"auction-1"},"storedbidresponse":[{"bidder":"appnexus","id":"bid-1"}]}}`)},
+			},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid has both storedauctionresponse and storedbidresponse, only one of them is allowed")},
+		},
+		{
+			description: "Stored bid response without bidder",
+			imps: []openrtb2.Imp{
+				{ID: "imp-1", Ext: json.RawMessage(`{"appnexus":{"placementId":1},"prebid":{"storedbidresponse":[{"id":"bid-1"}]}}`)},
+			},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid.storedbidresponse requires the bidder and id fields")},
+		},
+		{
+			description: "Stored bid response for a bidder not in the imp",
+			imps: []openrtb2.Imp{
+				{ID: "imp-1", Ext: json.RawMessage(`{"appnexus":{"placementId":1},"prebid":{"storedbidresponse":[{"bidder":"rubicon","id":"bid-1"}]}}`)},
+			},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid.storedbidresponse bidder rubicon is not one of the imp bidders")},
+		},
+		{
+			description: "Stored auction response missing from one of the imps",
+			imps: []openrtb2.Imp{
+				{ID: "imp-1", Ext: json.RawMessage(`{"appnexus":{"placementId":1},"prebid":{"storedauctionresponse":{"id":"auction-1"}}}`)},
+				{ID: "imp-2", Ext: json.RawMessage(`{"appnexus":{"placementId":1}}`)},
+			},
+			expectedErrs: []error{errors.New("request.imp[1].ext.prebid.storedauctionresponse is required, since other imps of the request have one")},
+		},
+		{
+			description: "Stored response not found",
+			imps: []openrtb2.Imp{
+				{ID: "imp-1", Ext: json.RawMessage(`{"appnexus":{"placementId":1},"prebid":{"storedauctionresponse":{"id":"auction-3"}}}`)},
+			},
+			expectedErrs: []error{stored_requests.NotFoundError{ID: "auction-3", DataType: "Response"}},
+		},
+	}
+
+	for _, test := range testCases {
+		storedAuctionResponses, storedBidResponses, errs := deps.processStoredResponses(context.Background(), test.imps)
+
+		assert.Equal(t, test.expectedStoredAuctionResponses, storedAuctionResponses, test.description)
+		assert.Equal(t, test.expectedStoredBidResponses, storedBidResponses, test.description)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
@@ -23,15 +23,19 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
-	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/usersync"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var defaultRequestTimeout int64 = 5000
@@ -49,9 +53,12 @@ func NewVideoEndpoint(
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
 	cache prebid_cache_client.Client,
+	storedRespFetcher stored_requests.Fetcher,
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
+	uidStore usersync.UIDStore,
 ) (httprouter.Handle, error) {
 
-	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
+	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil || storedRespFetcher == nil || hookExecutionPlanBuilder == nil {
 		return nil, errors.New("NewVideoEndpoint requires non-nil arguments.")
 	}
 
@@ -79,7 +86,10 @@ func NewVideoEndpoint(
 		bidderMap,
 		cache,
 		videoEndpointRegexp,
-		ipValidator}).VideoAuctionEndpoint), nil
+		ipValidator,
+		storedRespFetcher,
+		hookExecutionPlanBuilder,
+		uidStore}).VideoAuctionEndpoint), nil
 }
 
 /*
@@ -158,6 +168,13 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hooks.EndpointVideo)
+	var stageErrs []error
+	if requestJson, stageErrs = executeRequestStages(r, requestJson, hookExecutor); len(stageErrs) > 0 {
+		vo.VideoResponse = rejectVideoRequest(*hookexecution.FindFirstRejectOrNil(stageErrs), w, hookExecutor, nil, nil, &vo.Errors)
+		return
+	}
+
 	resolvedRequest := requestJson
 	if debugLog.DebugEnabledOrOverridden {
 		debugLog.Data.Request = string(requestJson)
@@ -177,7 +194,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 			return
 		}
 	} else {
-		storedRequest, errs := deps.loadStoredVideoRequest(context.Background(), storedRequestId)
+		storedRequest, errs := deps.loadStoredVideoRequest(tracing.DetachedContext(r.Context()), storedRequestId)
 		if len(errs) > 0 {
 			handleError(&labels, w, errs, &vo, &debugLog)
 			return
@@ -241,13 +258,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	deps.setFieldsImplicitly(r, bidReq) // move after merge
 
-	errL = deps.validateRequest(&openrtb_ext.RequestWrapper{BidRequest: bidReq})
-	if errortypes.ContainsFatalError(errL) {
-		handleError(&labels, w, errL, &vo, &debugLog)
-		return
-	}
-
-	ctx := context.Background()
+	ctx := tracing.DetachedContext(r.Context())
 	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(bidReq.TMax) * time.Millisecond)
 	if timeout > 0 {
 		var cancel context.CancelFunc
@@ -255,7 +266,16 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		defer cancel()
 	}
 
-	usersyncs := usersync.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
+	reqWrapper := &openrtb_ext.RequestWrapper{BidRequest: bidReq}
+	reqAccount, errL := deps.applyAccountBidders(ctx, reqWrapper)
+
+	errL = append(errL, deps.validateRequest(reqWrapper)...)
+	if errortypes.ContainsFatalError(errL) {
+		handleError(&labels, w, errL, &vo, &debugLog)
+		return
+	}
+
+	usersyncs := deps.parseUsersyncs(ctx, r, getFirstPartyID(reqWrapper))
 	if bidReq.App != nil {
 		labels.Source = metrics.DemandApp
 		labels.PubID = getAccountID(bidReq.App.Publisher)
@@ -269,12 +289,27 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		labels.PubID = getAccountID(bidReq.Site.Publisher)
 	}
 
-	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
+	// The account was looked up along with the request, under the same pubID value
+	account, acctIDErrs := reqAccount.account, reqAccount.errs
 	if len(acctIDErrs) > 0 {
 		handleError(&labels, w, acctIDErrs, &vo, &debugLog)
 		return
 	}
+	vo.Account = account
+
+	hookExecutor.SetAccount(account)
+	var rejectErr *hookexecution.RejectError
+	if bidReq, rejectErr = hookExecutor.ExecuteProcessedAuctionStage(bidReq); rejectErr != nil {
+		vo.Request = bidReq
+		vo.VideoResponse = rejectVideoRequest(*rejectErr, w, hookExecutor, bidReq, account, &vo.Errors)
+		return
+	}
+
+	storedAuctionResponses, storedBidResponses, storedRespErrs := deps.processStoredResponses(ctx, bidReq.Imp)
+	if len(storedRespErrs) > 0 {
+		handleError(&labels, w, storedRespErrs, &vo, &debugLog)
+		return
+	}
 
 	secGPC := r.Header.Get("Sec-GPC")
 
@@ -285,7 +320,11 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		RequestType:                labels.RType,
 		StartTime:                  start,
 		LegacyLabels:               labels,
+		Warnings:                   errortypes.WarningOnly(errL),
 		GlobalPrivacyControlHeader: secGPC,
+		StoredAuctionResponses:     storedAuctionResponses,
+		StoredBidResponses:         storedBidResponses,
+		HookExecutor:               hookExecutor,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, &debugLog)
@@ -297,8 +336,11 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	//fill the pods with the best bids which fit in
+	podResponse, droppedBids := assembleAdPods(response, videoBidReq, bidReq.Imp)
+
 	//build simplified response
-	bidResp, err := buildVideoResponse(response, podErrors)
+	bidResp, err := buildVideoResponse(podResponse, podErrors)
 	if err != nil {
 		errL := []error{err}
 		handleError(&labels, w, errL, &vo, &debugLog)
@@ -307,6 +349,13 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	if bidReq.Test == 1 {
 		bidResp.Ext = response.Ext
 	}
+	if len(droppedBids) > 0 {
+		if bidResp.Ext, err = addDroppedPodBids(bidResp.Ext, droppedBids); err != nil {
+			errL := []error{err}
+			handleError(&labels, w, errL, &vo, &debugLog)
+			return
+		}
+	}
 
 	if len(bidResp.AdPods) == 0 && debugLog.DebugEnabledOrOverridden {
 		err := debugLog.PutDebugLogError(deps.cache, deps.cfg.CacheURL.ExpectedTimeMillis, vo.Errors)
@@ -325,6 +374,18 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 
 	vo.VideoResponse = bidResp
 
+	if videoBidReq.Output == openrtb_ext.VideoOutputVAST {
+		vast, err := buildVASTResponse(podResponse, deps.cfg, account, start.UnixNano()/1e+6)
+		if err != nil {
+			errL := []error{err}
+			handleError(&labels, w, errL, &vo, &debugLog)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write(vast)
+		return
+	}
+
 	resp, err := json.Marshal(bidResp)
 	//resp, err := json.Marshal(response)
 	if err != nil {
@@ -338,6 +399,27 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 
 }
 
+// rejectVideoRequest answers a request rejected by a module hook with no ad pods. The request and the account
+// are nil if they were not resolved yet.
+func rejectVideoRequest(rejectErr hookexecution.RejectError, w http.ResponseWriter, hookExecutor hookexecution.StageExecutor, request *openrtb2.BidRequest, account *config.Account, errs *[]error) *openrtb_ext.BidResponseVideo {
+	bidResp := &openrtb_ext.BidResponseVideo{AdPods: []*openrtb_ext.AdPod{}}
+	*errs = append(*errs, &rejectErr)
+
+	var err error
+	if bidResp.Ext, err = hookexecution.EnrichExtBidResponse(nil, hookExecutor.GetOutcomes(), request, account); err != nil {
+		*errs = append(*errs, err)
+	}
+
+	resp, err := json.Marshal(bidResp)
+	if err != nil {
+		*errs = append(*errs, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+	return bidResp
+}
+
 func cleanupVideoBidRequest(videoReq *openrtb_ext.BidRequestVideo, podErrors []PodError) *openrtb_ext.BidRequestVideo {
 	for i := len(podErrors) - 1; i >= 0; i-- {
 		videoReq.PodConfig.Pods = append(videoReq.PodConfig.Pods[:podErrors[i].PodIndex], videoReq.PodConfig.Pods[podErrors[i].PodIndex+1:]...)
@@ -553,7 +635,11 @@ func findAdPod(podInd int64, pods []*openrtb_ext.AdPod) *openrtb_ext.AdPod {
 }
 
 func (deps *endpointDeps) loadStoredVideoRequest(ctx context.Context, storedRequestId string) ([]byte, []error) {
+	ctx, span := tracing.StartSpan(ctx, "stored_requests.fetch", trace.SpanKindInternal)
+	span.SetAttributes(attribute.Int("stored_requests", 1))
 	storedRequests, _, errs := deps.videoFetcher.FetchRequests(ctx, []string{storedRequestId}, []string{})
+	recordSpanErrors(span, errs)
+	span.End()
 	jsonString := storedRequests[storedRequestId]
 	return jsonString, errs
 }
@@ -675,6 +761,12 @@ func createBidExtension(videoRequest *openrtb_ext.BidRequestVideo) ([]byte, erro
 func (deps *endpointDeps) parseVideoRequest(request []byte, headers http.Header) (req *openrtb_ext.BidRequestVideo, errs []error, podErrors []PodError) {
 	req = &openrtb_ext.BidRequestVideo{}
 
+	request, err := moveGPPToRegsExt(request)
+	if err != nil {
+		errs = []error{err}
+		return
+	}
+
 	if err := json.Unmarshal(request, &req); err != nil {
 		errs = []error{err}
 		return
@@ -728,6 +820,15 @@ func (deps *endpointDeps) validateVideoRequest(req *openrtb_ext.BidRequestVideo)
 		err := errors.New("request missing required field: PodConfig.Pods")
 		errL = append(errL, err)
 	}
+	if req.Output != "" && req.Output != openrtb_ext.VideoOutputVAST {
+		err := fmt.Errorf("request.output must be empty or %s", openrtb_ext.VideoOutputVAST)
+		errL = append(errL, err)
+	}
+	// The players treat all the sequenced ads of a VAST document as a single pod
+	if req.Output == openrtb_ext.VideoOutputVAST && len(req.PodConfig.Pods) > 1 {
+		err := fmt.Errorf("request.output %s requires a single pod in PodConfig.Pods. Got %d", openrtb_ext.VideoOutputVAST, len(req.PodConfig.Pods))
+		errL = append(errL, err)
+	}
 	podErrors := make([]PodError, 0, 0)
 	podIdsSet := make(map[int]bool)
 	for ind, pod := range req.PodConfig.Pods {
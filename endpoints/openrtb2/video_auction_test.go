@@ -11,16 +11,19 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-server/analytics"
 	analyticsConf "github.com/prebid/prebid-server/analytics/config"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/metrics"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/usersync"
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	gometrics "github.com/rcrowley/go-metrics"
@@ -105,6 +108,39 @@ func TestVideoEndpointImpressionsDuration(t *testing.T) {
 
 }
 
+// TestVideoUserSyncsFromUIDStore makes sure that the UIDs are read from the UID store, under the user.ext.prebid.fpid of the request.
+func TestVideoUserSyncsFromUIDStore(t *testing.T) {
+	ex := &mockExchangeVideo{}
+	reqData, err := ioutil.ReadFile("sample-requests/video/video_valid_sample.json")
+	if err != nil {
+		t.Fatalf("Failed to fetch a valid request: %v", err)
+	}
+	videoRequest := map[string]json.RawMessage{}
+	if err := json.Unmarshal(getRequestPayload(t, reqData), &videoRequest); err != nil {
+		t.Fatalf("Failed to unmarshal the request: %v", err)
+	}
+	videoRequest["user"] = json.RawMessage(`{"id":"exchange-user-id","ext":{"prebid":{"fpid":"first-party-id"}}}`)
+	reqBody, _ := json.Marshal(videoRequest)
+
+	store := usersync.NewMemoryUIDStore(time.Hour)
+	store.Set(context.Background(), "fp:first-party-id", "adnxs", "789")
+	store.Set(context.Background(), "fp:exchange-user-id", "adnxs", "456")
+
+	req := httptest.NewRequest("POST", "/openrtb2/video", bytes.NewReader(reqBody))
+	pbsCookie := usersync.NewCookie()
+	pbsCookie.TrySync("adnxs", "123")
+	req.AddCookie(pbsCookie.ToHTTPCookie(time.Hour))
+
+	deps := mockDeps(t, ex)
+	deps.uidStore = store
+	deps.VideoAuctionEndpoint(httptest.NewRecorder(), req, nil)
+
+	if assert.NotNil(t, ex.lastUserSyncs, "The request never made it into the Exchange.") {
+		uid, _, _ := ex.lastUserSyncs.GetUID("adnxs")
+		assert.Equal(t, "789", uid)
+	}
+}
+
 func TestCreateBidExtension(t *testing.T) {
 	durationRange := make([]int, 0)
 	durationRange = append(durationRange, 15)
@@ -1225,6 +1261,9 @@ func mockDepsWithMetrics(t *testing.T, ex *mockExchangeVideo) (*endpointDeps, *m
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 	return deps, metrics, mockModule
 }
@@ -1267,6 +1306,9 @@ func mockDeps(t *testing.T, ex *mockExchangeVideo) *endpointDeps {
 		ex.cache,
 		regexp.MustCompile(`[<>]`),
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	return deps
@@ -1289,6 +1331,9 @@ func mockDepsAppendBidderNames(t *testing.T, ex *mockExchangeAppendBidderNames)
 		ex.cache,
 		regexp.MustCompile(`[<>]`),
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	return deps
@@ -1311,6 +1356,9 @@ func mockDepsNoBids(t *testing.T, ex *mockExchangeVideoNoBids) *endpointDeps {
 		ex.cache,
 		regexp.MustCompile(`[<>]`),
 		hardcodedResponseIPValidator{response: true},
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	return edep
@@ -1338,13 +1386,19 @@ func (cf mockVideoStoredReqFetcher) FetchRequests(ctx context.Context, requestID
 	return testVideoStoredRequestData, testVideoStoredImpData, nil
 }
 
+func (cf mockVideoStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return nil, nil
+}
+
 type mockExchangeVideo struct {
-	lastRequest *openrtb2.BidRequest
-	cache       *mockCacheClient
+	lastRequest   *openrtb2.BidRequest
+	lastUserSyncs exchange.IdFetcher
+	cache         *mockCacheClient
 }
 
 func (m *mockExchangeVideo) HoldAuction(ctx context.Context, r exchange.AuctionRequest, debugLog *exchange.DebugLog) (*openrtb2.BidResponse, error) {
 	m.lastRequest = r.BidRequest
+	m.lastUserSyncs = r.UserSyncs
 	if debugLog != nil && debugLog.Enabled {
 		m.cache.called = true
 	}
@@ -0,0 +1,296 @@
+package openrtb2
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// The reasons why a bid is left out of its ad pod
+const (
+	podBidDurationNotAllowed  = "Bid duration doesn't match the pod durations"
+	podBidDuplicateCategory   = "Bid has the same IAB category as another bid of the pod"
+	podBidDuplicateAdvertiser = "Bid has the same advertiser domain as another bid of the pod"
+	podBidDurationExceeded    = "Bid doesn't fit in the remaining pod duration"
+)
+
+// maxPodSearchNodes bounds the search of the best bids of a pod. Once reached, the best bids found so far are used.
+const maxPodSearchNodes = 100000
+
+// podBid is a bid competing for a place in its ad pod
+type podBid struct {
+	bid      *openrtb2.Bid
+	seat     string
+	duration int
+	adomains []string
+}
+
+// assembleAdPods picks the bids of each ad pod which bring the most revenue, while their total duration fits
+// the pod and no two of them share an IAB category or an advertiser domain. It returns a copy of the response
+// which only has the picked bids, along with the bids left out. The bids which don't belong to a pod, or weren't
+// cached, are left as they are.
+func assembleAdPods(response *openrtb2.BidResponse, videoReq *openrtb_ext.BidRequestVideo, imps []openrtb2.Imp) (*openrtb2.BidResponse, []openrtb_ext.DroppedPodBid) {
+	podDurations := make(map[int64]int, len(videoReq.PodConfig.Pods))
+	for _, pod := range videoReq.PodConfig.Pods {
+		podDurations[int64(pod.PodId)] = pod.AdPodDurationSec
+	}
+	impDurations := make(map[string]int, len(imps))
+	for _, imp := range imps {
+		if imp.Video != nil {
+			impDurations[imp.ID] = int(imp.Video.MaxDuration)
+		}
+	}
+
+	var droppedBids []openrtb_ext.DroppedPodBid
+	droppedBidIDs := make(map[*openrtb2.Bid]bool)
+	var podIDs []int64
+	podBids := make(map[int64][]podBid)
+	for i := range response.SeatBid {
+		seatBid := &response.SeatBid[i]
+		for j := range seatBid.Bid {
+			bid := &seatBid.Bid[j]
+			podID := getPodID(bid.ImpID)
+			if _, ok := podDurations[podID]; !ok {
+				continue
+			}
+
+			var bidExt openrtb_ext.ExtBid
+			if err := json.Unmarshal(bid.Ext, &bidExt); err != nil || bidExt.Prebid == nil || bidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbVastCacheKey, seatBid.Seat)] == "" {
+				continue
+			}
+
+			var bidDuration int
+			if bidExt.Prebid.Video != nil {
+				bidDuration = bidExt.Prebid.Video.Duration
+			}
+			duration, allowed := getPodBidDuration(bidDuration, impDurations[bid.ImpID], videoReq.PodConfig)
+			if !allowed {
+				droppedBids = append(droppedBids, newDroppedPodBid(podID, bid, seatBid.Seat, podBidDurationNotAllowed))
+				droppedBidIDs[bid] = true
+				continue
+			}
+
+			if _, ok := podBids[podID]; !ok {
+				podIDs = append(podIDs, podID)
+			}
+			podBids[podID] = append(podBids[podID], podBid{
+				bid:      bid,
+				seat:     seatBid.Seat,
+				duration: duration,
+				adomains: normalizeAdomains(bid.ADomain),
+			})
+		}
+	}
+
+	for _, podID := range podIDs {
+		bids := podBids[podID]
+		picked := pickPodBids(bids, podDurations[podID])
+		for i, bid := range bids {
+			if !picked[i] {
+				reason := getPodBidDropReason(bid, bids, picked)
+				droppedBids = append(droppedBids, newDroppedPodBid(podID, bid.bid, bid.seat, reason))
+				droppedBidIDs[bid.bid] = true
+			}
+		}
+	}
+
+	if len(droppedBids) == 0 {
+		return response, nil
+	}
+
+	podResponse := *response
+	podResponse.SeatBid = make([]openrtb2.SeatBid, 0, len(response.SeatBid))
+	for i := range response.SeatBid {
+		seatBid := response.SeatBid[i]
+		seatBid.Bid = make([]openrtb2.Bid, 0, len(response.SeatBid[i].Bid))
+		for j := range response.SeatBid[i].Bid {
+			if !droppedBidIDs[&response.SeatBid[i].Bid[j]] {
+				seatBid.Bid = append(seatBid.Bid, response.SeatBid[i].Bid[j])
+			}
+		}
+		if len(seatBid.Bid) > 0 {
+			podResponse.SeatBid = append(podResponse.SeatBid, seatBid)
+		}
+	}
+	return &podResponse, droppedBids
+}
+
+func getPodID(impID string) int64 {
+	podID, _ := strconv.ParseInt(strings.Split(impID, "_")[0], 0, 64)
+	return podID
+}
+
+// getPodBidDuration returns the duration a bid takes in its pod. The bids without duration are assumed to last
+// as long as their impression allows. With RequireExactDuration, the bid must last one of the pod durations.
+// Otherwise it takes the shortest pod duration it fits in, like in its hb_pb_cat_dur targeting.
+func getPodBidDuration(bidDuration int, impDuration int, podConfig openrtb_ext.PodConfig) (int, bool) {
+	if bidDuration == 0 {
+		bidDuration = impDuration
+	}
+
+	duration := 0
+	for _, podDuration := range podConfig.DurationRangeSec {
+		if podConfig.RequireExactDuration {
+			if bidDuration == podDuration {
+				return podDuration, true
+			}
+		} else if bidDuration <= podDuration && (duration == 0 || podDuration < duration) {
+			duration = podDuration
+		}
+	}
+	return duration, duration > 0
+}
+
+func normalizeAdomains(adomains []string) []string {
+	normalized := make([]string, 0, len(adomains))
+	for _, adomain := range adomains {
+		if adomain = strings.ToLower(strings.TrimSpace(adomain)); adomain != "" {
+			normalized = append(normalized, adomain)
+		}
+	}
+	return normalized
+}
+
+func newDroppedPodBid(podID int64, bid *openrtb2.Bid, seat string, reason string) openrtb_ext.DroppedPodBid {
+	return openrtb_ext.DroppedPodBid{
+		PodId:  podID,
+		BidId:  bid.ID,
+		Seat:   seat,
+		Reason: reason,
+	}
+}
+
+// pickPodBids tells which bids of a pod are picked. It searches the sets of bids from the highest priced bid down,
+// and gives up on the sets which can't beat the best one found so far, even if all their remaining bids fit in.
+func pickPodBids(bids []podBid, podDuration int) []bool {
+	order := make([]int, len(bids))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return bids[order[i]].bid.Price > bids[order[j]].bid.Price
+	})
+
+	search := &podSearch{
+		bids:             bids,
+		order:            order,
+		remainingRevenue: make([]float64, len(bids)+1),
+		picked:           make([]bool, len(bids)),
+		best:             make([]bool, len(bids)),
+		categories:       make(map[string]int),
+		adomains:         make(map[string]int),
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		search.remainingRevenue[i] = search.remainingRevenue[i+1] + bids[order[i]].bid.Price
+	}
+	search.search(0, podDuration, 0, 0)
+	return search.best
+}
+
+type podSearch struct {
+	bids  []podBid
+	order []int
+	// remainingRevenue holds the revenue of the bids from each position of order to the last one
+	remainingRevenue []float64
+	nodes            int
+
+	picked     []bool
+	categories map[string]int
+	adomains   map[string]int
+
+	best        []bool
+	bestRevenue float64
+	bestCount   int
+}
+
+func (s *podSearch) search(position int, remainingDuration int, revenue float64, count int) {
+	if s.nodes >= maxPodSearchNodes {
+		return
+	}
+	s.nodes++
+
+	if revenue > s.bestRevenue || (revenue == s.bestRevenue && count > s.bestCount) {
+		s.bestRevenue = revenue
+		s.bestCount = count
+		copy(s.best, s.picked)
+	}
+	if position == len(s.order) || revenue+s.remainingRevenue[position] < s.bestRevenue {
+		return
+	}
+
+	index := s.order[position]
+	bid := s.bids[index]
+	if bid.duration <= remainingDuration && !s.conflicts(bid) {
+		s.pick(index, 1)
+		s.search(position+1, remainingDuration-bid.duration, revenue+bid.bid.Price, count+1)
+		s.pick(index, -1)
+	}
+	s.search(position+1, remainingDuration, revenue, count)
+}
+
+func (s *podSearch) conflicts(bid podBid) bool {
+	for _, category := range bid.bid.Cat {
+		if s.categories[category] > 0 {
+			return true
+		}
+	}
+	for _, adomain := range bid.adomains {
+		if s.adomains[adomain] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// pick adds the bid to the picked ones if delta is 1, or removes it if delta is -1
+func (s *podSearch) pick(index int, delta int) {
+	s.picked[index] = delta > 0
+	for _, category := range s.bids[index].bid.Cat {
+		s.categories[category] += delta
+	}
+	for _, adomain := range s.bids[index].adomains {
+		s.adomains[adomain] += delta
+	}
+}
+
+func getPodBidDropReason(bid podBid, bids []podBid, picked []bool) string {
+	for i, pickedBid := range bids {
+		if picked[i] && sharesValue(bid.bid.Cat, pickedBid.bid.Cat) {
+			return podBidDuplicateCategory
+		}
+	}
+	for i, pickedBid := range bids {
+		if picked[i] && sharesValue(bid.adomains, pickedBid.adomains) {
+			return podBidDuplicateAdvertiser
+		}
+	}
+	return podBidDurationExceeded
+}
+
+func sharesValue(a []string, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addDroppedPodBids adds the bids left out of their pod to the ext.prebid.droppedbids of the video response
+func addDroppedPodBids(ext json.RawMessage, droppedBids []openrtb_ext.DroppedPodBid) (json.RawMessage, error) {
+	droppedBidsJSON, err := json.Marshal(droppedBids)
+	if err != nil {
+		return ext, err
+	}
+	if len(ext) == 0 {
+		ext = json.RawMessage(`{}`)
+	}
+	return jsonparser.Set(ext, droppedBidsJSON, "prebid", "droppedbids")
+}
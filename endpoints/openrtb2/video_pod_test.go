@@ -0,0 +1,247 @@
+package openrtb2
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func podTestBid(id string, impID string, price float64, duration int, cat []string, adomain []string) openrtb2.Bid {
+	return openrtb2.Bid{
+		ID:      id,
+		ImpID:   impID,
+		Price:   price,
+		Cat:     cat,
+		ADomain: adomain,
+		Ext:     json.RawMessage(fmt.Sprintf(`{"prebid":{"targeting":{"hb_uuid_appnexus":"uuid-%s"},"video":{"duration":%d,"primary_category":""}}}`, id, duration)),
+	}
+}
+
+func TestAssembleAdPods(t *testing.T) {
+	testCases := []struct {
+		description     string
+		podConfig       openrtb_ext.PodConfig
+		bids            []openrtb2.Bid
+		expectedBidIDs  []string
+		expectedDropped []openrtb_ext.DroppedPodBid
+	}{
+		{
+			description: "All bids fit in the pod",
+			podConfig: openrtb_ext.PodConfig{
+				DurationRangeSec: []int{15, 30},
+				Pods:             []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 60}},
+			},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 5, 30, []string{"IAB1"}, []string{"a.com"}),
+				podTestBid("b", "1_1", 4, 15, []string{"IAB2"}, []string{"b.com"}),
+			},
+			expectedBidIDs: []string{"a", "b"},
+		},
+		{
+			description: "Revenue maximizing bids are picked instead of the highest priced one",
+			podConfig: openrtb_ext.PodConfig{
+				DurationRangeSec: []int{15, 30},
+				Pods:             []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 30}},
+			},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 10, 30, nil, nil),
+				podTestBid("b", "1_1", 6, 15, nil, nil),
+				podTestBid("c", "1_2", 6, 15, nil, nil),
+			},
+			expectedBidIDs: []string{"b", "c"},
+			expectedDropped: []openrtb_ext.DroppedPodBid{
+				{PodId: 1, BidId: "a", Seat: "appnexus", Reason: podBidDurationExceeded},
+			},
+		},
+		{
+			description: "Bids with the same IAB category are separated",
+			podConfig: openrtb_ext.PodConfig{
+				DurationRangeSec: []int{15},
+				Pods:             []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 60}},
+			},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 10, 15, []string{"IAB1"}, []string{"a.com"}),
+				podTestBid("b", "1_1", 8, 15, []string{"IAB1"}, []string{"b.com"}),
+				podTestBid("c", "1_2", 7, 15, []string{"IAB2"}, []string{"A.com"}),
+				podTestBid("d", "1_3", 5, 15, []string{"IAB3"}, []string{"d.com"}),
+			},
+			expectedBidIDs: []string{"b", "c", "d"},
+			expectedDropped: []openrtb_ext.DroppedPodBid{
+				{PodId: 1, BidId: "a", Seat: "appnexus", Reason: podBidDuplicateCategory},
+			},
+		},
+		{
+			description: "Bids with the same advertiser domain are separated",
+			podConfig: openrtb_ext.PodConfig{
+				DurationRangeSec: []int{15},
+				Pods:             []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 60}},
+			},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 10, 15, []string{"IAB1"}, []string{"a.com"}),
+				podTestBid("b", "1_1", 7, 15, []string{"IAB2"}, []string{"A.com"}),
+				podTestBid("c", "1_2", 5, 15, []string{"IAB3"}, []string{"c.com"}),
+			},
+			expectedBidIDs: []string{"a", "c"},
+			expectedDropped: []openrtb_ext.DroppedPodBid{
+				{PodId: 1, BidId: "b", Seat: "appnexus", Reason: podBidDuplicateAdvertiser},
+			},
+		},
+		{
+			description: "Bids are bucketed up to the pod durations",
+			podConfig: openrtb_ext.PodConfig{
+				DurationRangeSec: []int{15, 30},
+				Pods:             []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 45}},
+			},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 10, 20, nil, nil),
+				podTestBid("b", "1_1", 8, 20, nil, nil),
+				podTestBid("c", "1_2", 5, 10, nil, nil),
+			},
+			expectedBidIDs: []string{"a", "c"},
+			expectedDropped: []openrtb_ext.DroppedPodBid{
+				{PodId: 1, BidId: "b", Seat: "appnexus", Reason: podBidDurationExceeded},
+			},
+		},
+		{
+			description: "Bids not lasting one of the exact durations are dropped",
+			podConfig: openrtb_ext.PodConfig{
+				DurationRangeSec:     []int{15, 30},
+				RequireExactDuration: true,
+				Pods:                 []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 60}},
+			},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 10, 20, nil, nil),
+				podTestBid("b", "1_1", 8, 30, nil, nil),
+			},
+			expectedBidIDs: []string{"b"},
+			expectedDropped: []openrtb_ext.DroppedPodBid{
+				{PodId: 1, BidId: "a", Seat: "appnexus", Reason: podBidDurationNotAllowed},
+			},
+		},
+		{
+			description: "Pods are filled separately",
+			podConfig: openrtb_ext.PodConfig{
+				DurationRangeSec: []int{30},
+				Pods: []openrtb_ext.Pod{
+					{PodId: 1, AdPodDurationSec: 30},
+					{PodId: 2, AdPodDurationSec: 30},
+				},
+			},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 10, 30, []string{"IAB1"}, nil),
+				podTestBid("b", "2_0", 8, 30, []string{"IAB1"}, nil),
+				podTestBid("c", "2_1", 9, 30, nil, nil),
+			},
+			expectedBidIDs: []string{"a", "c"},
+			expectedDropped: []openrtb_ext.DroppedPodBid{
+				{PodId: 2, BidId: "b", Seat: "appnexus", Reason: podBidDurationExceeded},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		response := &openrtb2.BidResponse{
+			SeatBid: []openrtb2.SeatBid{{Seat: "appnexus", Bid: test.bids}},
+		}
+		videoReq := &openrtb_ext.BidRequestVideo{PodConfig: test.podConfig}
+
+		podResponse, dropped := assembleAdPods(response, videoReq, nil)
+
+		var bidIDs []string
+		for _, seatBid := range podResponse.SeatBid {
+			for _, bid := range seatBid.Bid {
+				bidIDs = append(bidIDs, bid.ID)
+			}
+		}
+		assert.ElementsMatch(t, test.expectedBidIDs, bidIDs, test.description)
+		assert.Equal(t, test.expectedDropped, dropped, test.description)
+		assert.Len(t, response.SeatBid[0].Bid, len(test.bids), test.description+": the auction response shouldn't change")
+	}
+}
+
+func TestAssembleAdPodsSkipsUncachedBids(t *testing.T) {
+	uncached := openrtb2.Bid{ID: "b", ImpID: "1_1", Price: 20, Ext: json.RawMessage(`{"prebid":{"targeting":{}}}`)}
+	response := &openrtb2.BidResponse{
+		SeatBid: []openrtb2.SeatBid{{Seat: "appnexus", Bid: []openrtb2.Bid{
+			podTestBid("a", "1_0", 10, 30, nil, nil),
+			uncached,
+		}}},
+	}
+	videoReq := &openrtb_ext.BidRequestVideo{PodConfig: openrtb_ext.PodConfig{
+		DurationRangeSec: []int{30},
+		Pods:             []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 30}},
+	}}
+
+	podResponse, dropped := assembleAdPods(response, videoReq, nil)
+
+	assert.Equal(t, response, podResponse)
+	assert.Empty(t, dropped)
+}
+
+func TestGetPodBidDuration(t *testing.T) {
+	testCases := []struct {
+		description      string
+		bidDuration      int
+		impDuration      int
+		podConfig        openrtb_ext.PodConfig
+		expectedDuration int
+		expectedAllowed  bool
+	}{
+		{
+			description:      "Bid duration bucketed up",
+			bidDuration:      20,
+			podConfig:        openrtb_ext.PodConfig{DurationRangeSec: []int{30, 15}},
+			expectedDuration: 30,
+			expectedAllowed:  true,
+		},
+		{
+			description:      "Bid without duration takes the impression one",
+			impDuration:      15,
+			podConfig:        openrtb_ext.PodConfig{DurationRangeSec: []int{15, 30}},
+			expectedDuration: 15,
+			expectedAllowed:  true,
+		},
+		{
+			description:     "Bid longer than the pod durations",
+			bidDuration:     45,
+			podConfig:       openrtb_ext.PodConfig{DurationRangeSec: []int{15, 30}},
+			expectedAllowed: false,
+		},
+		{
+			description:      "Exact duration",
+			bidDuration:      30,
+			podConfig:        openrtb_ext.PodConfig{DurationRangeSec: []int{15, 30}, RequireExactDuration: true},
+			expectedDuration: 30,
+			expectedAllowed:  true,
+		},
+		{
+			description:     "Not an exact duration",
+			bidDuration:     20,
+			podConfig:       openrtb_ext.PodConfig{DurationRangeSec: []int{15, 30}, RequireExactDuration: true},
+			expectedAllowed: false,
+		},
+	}
+
+	for _, test := range testCases {
+		duration, allowed := getPodBidDuration(test.bidDuration, test.impDuration, test.podConfig)
+		assert.Equal(t, test.expectedDuration, duration, test.description)
+		assert.Equal(t, test.expectedAllowed, allowed, test.description)
+	}
+}
+
+func TestAddDroppedPodBids(t *testing.T) {
+	dropped := []openrtb_ext.DroppedPodBid{{PodId: 1, BidId: "a", Seat: "appnexus", Reason: podBidDuplicateCategory}}
+	expectedDropped := `[{"podid":1,"bidid":"a","seat":"appnexus","reason":"` + podBidDuplicateCategory + `"}]`
+
+	ext, err := addDroppedPodBids(nil, dropped)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"prebid":{"droppedbids":`+expectedDropped+`}}`, string(ext))
+
+	ext, err = addDroppedPodBids(json.RawMessage(`{"debug":{},"prebid":{"auctiontimestamp":5}}`), dropped)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"debug":{},"prebid":{"auctiontimestamp":5,"droppedbids":`+expectedDropped+`}}`, string(ext))
+}
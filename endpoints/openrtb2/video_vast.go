@@ -0,0 +1,115 @@
+package openrtb2
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+	"strings"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/endpoints/events"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const (
+	vastWrapperVersion  = "4.0"
+	vastWrapperAdSystem = "Prebid Server"
+)
+
+type vastWrapperDocument struct {
+	XMLName xml.Name        `xml:"VAST"`
+	Version string          `xml:"version,attr"`
+	Ads     []vastWrapperAd `xml:"Ad"`
+}
+
+type vastWrapperAd struct {
+	ID       string      `xml:"id,attr"`
+	Sequence int         `xml:"sequence,attr"`
+	Wrapper  vastWrapper `xml:"Wrapper"`
+}
+
+type vastWrapper struct {
+	AdSystem     string      `xml:"AdSystem"`
+	VASTAdTagURI vastCDATA   `xml:"VASTAdTagURI"`
+	Impressions  []vastCDATA `xml:"Impression"`
+}
+
+type vastCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+// vastWrapperBid is a cached bid of the ad pod which is wrapped in the VAST response
+type vastWrapperBid struct {
+	price   float64
+	bidID   string
+	seat    string
+	cacheID string
+	adm     string
+}
+
+// buildVASTResponse makes a VAST document out of the bids of the ad pod, for the players which call
+// /openrtb2/video directly. Each cached bid becomes a wrapper of its Prebid Cache URL, and the ads are
+// sequenced by price. The requests for a VAST response have a single pod, since the players treat all
+// the sequenced ads of a VAST document as one pod.
+//
+// If events are enabled for the account, the wrappers get an impression tracker, unless the bidder
+// already added it to the VAST it cached.
+func buildVASTResponse(bidResponse *openrtb2.BidResponse, cfg *config.Configuration, account *config.Account, auctionTimestampMs int64) ([]byte, error) {
+	var bids []vastWrapperBid
+	for _, seatBid := range bidResponse.SeatBid {
+		for _, bid := range seatBid.Bid {
+			var bidExt openrtb_ext.ExtBid
+			if err := json.Unmarshal(bid.Ext, &bidExt); err != nil || bidExt.Prebid == nil {
+				continue
+			}
+			cacheID := bidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbVastCacheKey, seatBid.Seat)]
+			if cacheID == "" {
+				continue
+			}
+
+			bidID := bid.ID
+			if len(bidExt.Prebid.BidId) > 0 {
+				bidID = bidExt.Prebid.BidId
+			}
+			bids = append(bids, vastWrapperBid{
+				price:   bid.Price,
+				bidID:   bidID,
+				seat:    seatBid.Seat,
+				cacheID: cacheID,
+				adm:     bid.AdM,
+			})
+		}
+	}
+	sort.SliceStable(bids, func(i, j int) bool {
+		return bids[i].price > bids[j].price
+	})
+
+	vast := vastWrapperDocument{
+		Version: vastWrapperVersion,
+		Ads:     make([]vastWrapperAd, 0, len(bids)),
+	}
+	for i, bid := range bids {
+		ad := vastWrapperAd{
+			ID:       bid.bidID,
+			Sequence: i + 1,
+			Wrapper: vastWrapper{
+				AdSystem:     vastWrapperAdSystem,
+				VASTAdTagURI: vastCDATA{Value: cfg.GetCachedAssetURL(bid.cacheID)},
+			},
+		}
+		if account.EventsEnabled {
+			impressionURL := events.GetVastUrlTracking(cfg.ExternalURL, bid.bidID, bid.seat, account.ID, auctionTimestampMs)
+			if !strings.Contains(bid.adm, impressionURL) {
+				ad.Wrapper.Impressions = append(ad.Wrapper.Impressions, vastCDATA{Value: impressionURL})
+			}
+		}
+		vast.Ads = append(vast.Ads, ad)
+	}
+
+	vastXML, err := xml.Marshal(vast)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), vastXML...), nil
+}
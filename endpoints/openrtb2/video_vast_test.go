@@ -0,0 +1,117 @@
+package openrtb2
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/endpoints/events"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildVASTResponse(t *testing.T) {
+	cfg := &config.Configuration{
+		ExternalURL: "http://pbs.com",
+		CacheURL: config.Cache{
+			Scheme: "https",
+			Host:   "cache.com",
+			Query:  "uuid=%PBS_CACHE_UUID%",
+		},
+	}
+	trackedVAST := `<VAST><Ad><InLine><Impression><![CDATA[` + events.GetVastUrlTracking("http://pbs.com", "b2", "appnexus", "acct", 1000) + `]]></Impression></InLine></Ad></VAST>`
+	response := &openrtb2.BidResponse{
+		SeatBid: []openrtb2.SeatBid{
+			{
+				Seat: "appnexus",
+				Bid: []openrtb2.Bid{
+					{ID: "b1", ImpID: "1_2", Price: 3, Ext: json.RawMessage(`{"prebid":{"targeting":{"hb_uuid_appnexus":"uuid-1"}}}`)},
+					{ID: "b2", ImpID: "1_0", Price: 2, AdM: trackedVAST, Ext: json.RawMessage(`{"prebid":{"targeting":{"hb_uuid_appnexus":"uuid-2"}}}`)},
+					{ID: "b3", ImpID: "1_1", Price: 1, Ext: json.RawMessage(`{"prebid":{"targeting":{}}}`)},
+				},
+			},
+			{
+				Seat: "rubicon",
+				Bid: []openrtb2.Bid{
+					{ID: "b4", ImpID: "1_1", Price: 5, Ext: json.RawMessage(`{"prebid":{"bidid":"generated","targeting":{"hb_uuid_rubicon":"uuid-4"}}}`)},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description  string
+		account      *config.Account
+		expectedVAST string
+	}{
+		{
+			description: "Events disabled",
+			account:     &config.Account{ID: "acct"},
+			expectedVAST: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<VAST version="4.0">` +
+				`<Ad id="generated" sequence="1"><Wrapper><AdSystem>Prebid Server</AdSystem><VASTAdTagURI><![CDATA[https://cache.com/cache?uuid=uuid-4]]></VASTAdTagURI></Wrapper></Ad>` +
+				`<Ad id="b1" sequence="2"><Wrapper><AdSystem>Prebid Server</AdSystem><VASTAdTagURI><![CDATA[https://cache.com/cache?uuid=uuid-1]]></VASTAdTagURI></Wrapper></Ad>` +
+				`<Ad id="b2" sequence="3"><Wrapper><AdSystem>Prebid Server</AdSystem><VASTAdTagURI><![CDATA[https://cache.com/cache?uuid=uuid-2]]></VASTAdTagURI></Wrapper></Ad>` +
+				`</VAST>`,
+		},
+		{
+			description: "Events enabled",
+			account:     &config.Account{ID: "acct", EventsEnabled: true},
+			expectedVAST: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<VAST version="4.0">` +
+				`<Ad id="generated" sequence="1"><Wrapper><AdSystem>Prebid Server</AdSystem><VASTAdTagURI><![CDATA[https://cache.com/cache?uuid=uuid-4]]></VASTAdTagURI>` +
+				`<Impression><![CDATA[http://pbs.com/event?t=imp&b=generated&a=acct&bidder=rubicon&f=b&ts=1000]]></Impression></Wrapper></Ad>` +
+				`<Ad id="b1" sequence="2"><Wrapper><AdSystem>Prebid Server</AdSystem><VASTAdTagURI><![CDATA[https://cache.com/cache?uuid=uuid-1]]></VASTAdTagURI>` +
+				`<Impression><![CDATA[http://pbs.com/event?t=imp&b=b1&a=acct&bidder=appnexus&f=b&ts=1000]]></Impression></Wrapper></Ad>` +
+				`<Ad id="b2" sequence="3"><Wrapper><AdSystem>Prebid Server</AdSystem><VASTAdTagURI><![CDATA[https://cache.com/cache?uuid=uuid-2]]></VASTAdTagURI></Wrapper></Ad>` +
+				`</VAST>`,
+		},
+	}
+
+	for _, test := range testCases {
+		vast, err := buildVASTResponse(response, cfg, test.account, 1000)
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedVAST, string(vast), test.description)
+	}
+}
+
+func TestBuildVASTResponseNoBids(t *testing.T) {
+	vast, err := buildVASTResponse(&openrtb2.BidResponse{}, &config.Configuration{}, &config.Account{}, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<VAST version="4.0"></VAST>`, string(vast))
+}
+
+func TestVideoEndpointValidationsOutput(t *testing.T) {
+	deps := mockDeps(t, &mockExchangeVideo{})
+	onePod := []openrtb_ext.Pod{{PodId: 1}}
+	twoPods := []openrtb_ext.Pod{{PodId: 1}, {PodId: 2}}
+
+	testCases := []struct {
+		description   string
+		output        string
+		pods          []openrtb_ext.Pod
+		expectedError string
+	}{
+		{description: "No output", output: "", pods: twoPods, expectedError: ""},
+		{description: "VAST output", output: openrtb_ext.VideoOutputVAST, pods: onePod, expectedError: ""},
+		{description: "VAST output with several pods", output: openrtb_ext.VideoOutputVAST, pods: twoPods, expectedError: "request.output vast requires a single pod in PodConfig.Pods. Got 2"},
+		{description: "Unknown output", output: "xml", pods: onePod, expectedError: "request.output must be empty or vast"},
+	}
+
+	for _, test := range testCases {
+		req := openrtb_ext.BidRequestVideo{Output: test.output, PodConfig: openrtb_ext.PodConfig{Pods: test.pods}}
+		errs, _ := deps.validateVideoRequest(&req)
+
+		var outputErrs []string
+		for _, err := range errs {
+			if strings.HasPrefix(err.Error(), "request.output") {
+				outputErrs = append(outputErrs, err.Error())
+			}
+		}
+		if test.expectedError == "" {
+			assert.Empty(t, outputErrs, test.description)
+		} else {
+			assert.Equal(t, []string{test.expectedError}, outputErrs, test.description)
+		}
+	}
+}
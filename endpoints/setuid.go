@@ -14,6 +14,7 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/httputil"
 )
@@ -26,7 +27,9 @@ const (
 	chromeiOSStrLen = len(chromeiOSStr)
 )
 
-func NewSetUIDEndpoint(cfg config.HostCookie, syncersByBidder map[string]usersync.Syncer, perms gdpr.Permissions, pbsanalytics analytics.PBSAnalyticsModule, metricsEngine metrics.MetricsEngine) httprouter.Handle {
+// NewSetUIDEndpoint implements the /setuid endpoint. The UIDs are saved in the uidStore if there's one, and in the
+// uids cookie otherwise. The uidPriorityGroups decide which UIDs are evicted from a cookie which grows too large.
+func NewSetUIDEndpoint(cfg config.HostCookie, syncersByBidder map[string]usersync.Syncer, uidPriorityGroups [][]string, perms gdpr.Permissions, pbsanalytics analytics.PBSAnalyticsModule, metricsEngine metrics.MetricsEngine, uidStore usersync.UIDStore) httprouter.Handle {
 	cookieTTL := time.Duration(cfg.TTL) * 24 * time.Hour
 
 	// convert map of syncers by bidder to map of syncers by key
@@ -35,6 +38,7 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncersByBidder map[string]usersyn
 	for _, v := range syncersByBidder {
 		syncersByKey[v.Key()] = v
 	}
+	uidPriority := usersync.NewUIDPriority(uidPriorityGroups, syncersByBidder)
 
 	return httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		so := analytics.SetUIDObject{
@@ -73,7 +77,12 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncersByBidder map[string]usersyn
 			return
 		}
 
-		if shouldReturn, status, body := preventSyncsGDPR(query.Get("gdpr"), query.Get("gdpr_consent"), perms); shouldReturn {
+		gdprConsent := query.Get("gdpr_consent")
+		if gdprConsent == "" {
+			gdprConsent = gppTCF2Consent(query.Get("gpp"), query.Get("gpp_sid"))
+		}
+
+		if shouldReturn, status, body := preventSyncsGDPR(query.Get("gdpr"), gdprConsent, perms); shouldReturn {
 			w.WriteHeader(status)
 			w.Write([]byte(body))
 			switch status {
@@ -86,22 +95,41 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncersByBidder map[string]usersyn
 			return
 		}
 
+		var userKey string
+		if uidStore != nil {
+			if userKey = usersync.UserKey(r, &cfg, query.Get(usersync.FirstPartyIDParam)); userKey == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`a host cookie or the "` + usersync.FirstPartyIDParam + `" query param is required to save the uid`))
+				metricsEngine.RecordSetUid(metrics.SetUidBadRequest)
+				so.Status = http.StatusBadRequest
+				return
+			}
+		}
+
 		uid := query.Get("uid")
 		so.UID = uid
 
 		if uid == "" {
-			pc.Unsync(syncer.Key())
-			metricsEngine.RecordSetUid(metrics.SetUidOK)
-			metricsEngine.RecordSyncerSet(syncer.Key(), metrics.SyncerSetUidCleared)
-			so.Success = true
-		} else if err = pc.TrySync(syncer.Key(), uid); err == nil {
+			if err = unsyncUID(r.Context(), pc, uidStore, userKey, syncer.Key()); err == nil {
+				metricsEngine.RecordSetUid(metrics.SetUidOK)
+				metricsEngine.RecordSyncerSet(syncer.Key(), metrics.SyncerSetUidCleared)
+				so.Success = true
+			}
+		} else if err = syncUID(r.Context(), pc, uidStore, userKey, syncer.Key(), uid); err == nil {
 			metricsEngine.RecordSetUid(metrics.SetUidOK)
 			metricsEngine.RecordSyncerSet(syncer.Key(), metrics.SyncerSetUidOK)
 			so.Success = true
 		}
+		if err != nil {
+			so.Errors = append(so.Errors, err)
+		}
 
-		setSiteCookie := siteCookieCheck(r.UserAgent())
-		pc.SetCookieOnResponse(w, setSiteCookie, &cfg, cookieTTL)
+		if uidStore == nil {
+			setSiteCookie := siteCookieCheck(r.UserAgent())
+			for _, evictedKey := range pc.SetCookieOnResponse(w, setSiteCookie, &cfg, cookieTTL, uidPriority) {
+				metricsEngine.RecordSyncerUIDEvicted(evictedKey)
+			}
+		}
 
 		switch responseFormat {
 		case "i":
@@ -117,6 +145,23 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncersByBidder map[string]usersyn
 	})
 }
 
+// syncUID saves the uid in the uidStore if there's one, and in the cookie otherwise.
+func syncUID(ctx context.Context, pc *usersync.Cookie, uidStore usersync.UIDStore, userKey string, syncerKey string, uid string) error {
+	if err := pc.TrySync(syncerKey, uid); err != nil || uidStore == nil {
+		return err
+	}
+	return uidStore.Set(ctx, userKey, syncerKey, uid)
+}
+
+// unsyncUID removes the uid from the uidStore if there's one, and from the cookie otherwise.
+func unsyncUID(ctx context.Context, pc *usersync.Cookie, uidStore usersync.UIDStore, userKey string, syncerKey string) error {
+	pc.Unsync(syncerKey)
+	if uidStore == nil {
+		return nil
+	}
+	return uidStore.Delete(ctx, userKey, syncerKey)
+}
+
 func getSyncer(query url.Values, syncersByKey map[string]usersync.Syncer) (usersync.Syncer, error) {
 	key := query.Get("bidder")
 
@@ -185,6 +230,21 @@ func checkChromeBrowserVersion(ua string, index int, chromeStrLength int) bool {
 	return result
 }
 
+// gppTCF2Consent returns the TCF EU v2 consent string of the gpp query param, if the gpp_sid query param lists
+// it as applicable.
+func gppTCF2Consent(rawGPP, rawSID string) string {
+	sid, err := gpp.ParseSID(rawSID)
+	if err != nil || !gpp.Applies(sid, gpp.SectionTCFEU2) {
+		return ""
+	}
+
+	parsedGPP, err := gpp.Parse(rawGPP)
+	if err != nil {
+		return ""
+	}
+	return parsedGPP.TCF2Consent()
+}
+
 func preventSyncsGDPR(gdprEnabled string, gdprConsent string, perms gdpr.Permissions) (shouldReturn bool, status int, body string) {
 	if gdprEnabled != "" && gdprEnabled != "0" && gdprEnabled != "1" {
 		return true, http.StatusBadRequest, "the gdpr query param must be either 0 or 1. You gave " + gdprEnabled
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -202,6 +203,28 @@ func TestSetUIDEndpoint(t *testing.T) {
 			expectedBody:           "gdpr_consent was invalid. malformed consent string malformed: some error",
 			description:            "Should return an error if GDPR consent string is malformed",
 		},
+		{
+			uri: "/setuid?bidder=pubmatic&uid=123&gdpr=1&gpp_sid=2&gpp=" +
+				"DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+			syncersBidderNameToKey: map[string]string{"pubmatic": "pubmatic"},
+			gdprAllowsHostCookies:  true,
+			gdprMalformed:          true,
+			existingSyncs:          nil,
+			expectedStatusCode:     http.StatusBadRequest,
+			expectedBody:           "gdpr_consent was invalid. malformed consent string CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA: some error",
+			description:            "Should use the consent string of the GPP TCF EU v2 section if GDPR consent string is missing",
+		},
+		{
+			uri: "/setuid?bidder=pubmatic&uid=123&gdpr=1&gpp_sid=6&gpp=" +
+				"DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+			syncersBidderNameToKey: map[string]string{"pubmatic": "pubmatic"},
+			gdprAllowsHostCookies:  true,
+			existingSyncs:          nil,
+			expectedSyncs:          nil,
+			expectedStatusCode:     http.StatusBadRequest,
+			expectedBody:           "gdpr_consent is required when gdpr=1",
+			description:            "Should ignore the GPP TCF EU v2 section if it is not applicable",
+		},
 		{
 			uri:                    "/setuid?bidder=pubmatic&uid=123&f=b",
 			syncersBidderNameToKey: map[string]string{"pubmatic": "pubmatic"},
@@ -373,6 +396,36 @@ func TestSetUIDEndpointMetrics(t *testing.T) {
 	}
 }
 
+func TestSetUIDEndpointEvictsByPriority(t *testing.T) {
+	existingCookie := usersync.NewCookie()
+	existingCookie.TrySync("adnxs", strings.Repeat("a", 32))
+	existingCookie.TrySync("rubicon", strings.Repeat("b", 32))
+	// the uids have the same length, so that the cookie has room for two of them
+	hostCookie := config.HostCookie{MaxCookieSizeBytes: len(existingCookie.ToHTTPCookie(time.Hour).String()) + 50}
+
+	syncersByBidder := map[string]usersync.Syncer{
+		"appnexus": fakeSyncer{key: "adnxs", defaultSyncType: usersync.SyncTypeIFrame},
+		"rubicon":  fakeSyncer{key: "rubicon", defaultSyncType: usersync.SyncTypeIFrame},
+		"pubmatic": fakeSyncer{key: "pubmatic", defaultSyncType: usersync.SyncTypeIFrame},
+	}
+	perms := &mockPermsSetUID{allowHost: true, personalInfoAllowed: true}
+	analytics := analyticsConf.NewPBSAnalytics(&config.Analytics{})
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordSetUid", metrics.SetUidOK).Once()
+	metricsEngine.On("RecordSyncerSet", "pubmatic", metrics.SyncerSetUidOK).Once()
+	metricsEngine.On("RecordSyncerUIDEvicted", "rubicon").Once()
+
+	endpoint := NewSetUIDEndpoint(hostCookie, syncersByBidder, [][]string{{"appnexus"}, {"pubmatic"}}, perms, analytics, metricsEngine, nil)
+	request := httptest.NewRequest("GET", "/setuid?bidder=pubmatic&uid="+strings.Repeat("c", 32), nil)
+	addCookie(request, existingCookie)
+	response := httptest.NewRecorder()
+	endpoint(response, request, nil)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, map[string]string{"adnxs": strings.Repeat("a", 32), "pubmatic": strings.Repeat("c", 32)}, parseCookieString(t, response).GetUIDs())
+	metricsEngine.AssertExpectations(t)
+}
+
 func TestOptedOut(t *testing.T) {
 	request := httptest.NewRequest("GET", "/setuid?bidder=pubmatic&uid=123", nil)
 	cookie := usersync.NewCookie()
@@ -504,6 +557,82 @@ func assertHasSyncs(t *testing.T, testCase string, resp *httptest.ResponseRecord
 	}
 }
 
+func TestSetUIDEndpointWithStore(t *testing.T) {
+	testCases := []struct {
+		description        string
+		uri                string
+		hostCookie         string
+		existingUIDs       map[string]map[string]string
+		expectedUIDs       map[string]map[string]string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			description:        "Save the uid under the host cookie",
+			uri:                "/setuid?bidder=pubmatic&uid=123&fpid=fp1",
+			hostCookie:         "hc1",
+			expectedUIDs:       map[string]map[string]string{"hc:hc1": {"pubmatic": "123"}},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			description:        "Save the uid under the first-party ID",
+			uri:                "/setuid?bidder=pubmatic&uid=123&fpid=fp1",
+			expectedUIDs:       map[string]map[string]string{"fp:fp1": {"pubmatic": "123"}},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			description:        "Delete the uid",
+			uri:                "/setuid?bidder=pubmatic&uid=&fpid=fp1",
+			existingUIDs:       map[string]map[string]string{"fp:fp1": {"pubmatic": "123", "adnxs": "456"}},
+			expectedUIDs:       map[string]map[string]string{"fp:fp1": {"adnxs": "456"}},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			description:        "No user key",
+			uri:                "/setuid?bidder=pubmatic&uid=123",
+			expectedUIDs:       map[string]map[string]string{},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `a host cookie or the "fpid" query param is required to save the uid`,
+		},
+	}
+
+	for _, test := range testCases {
+		store := usersync.NewMemoryUIDStore(time.Hour)
+		for userKey, uids := range test.existingUIDs {
+			for key, uid := range uids {
+				store.Set(context.Background(), userKey, key, uid)
+			}
+		}
+		hostCookie := config.HostCookie{CookieName: "khaos"}
+		syncersByBidder := map[string]usersync.Syncer{
+			"pubmatic": fakeSyncer{key: "pubmatic", defaultSyncType: usersync.SyncTypeIFrame},
+		}
+		perms := &mockPermsSetUID{allowHost: true, personalInfoAllowed: true}
+		analytics := analyticsConf.NewPBSAnalytics(&config.Analytics{})
+		metricsEngine := &metricsConf.DummyMetricsEngine{}
+		endpoint := NewSetUIDEndpoint(hostCookie, syncersByBidder, nil, perms, analytics, metricsEngine, store)
+
+		request := httptest.NewRequest("GET", test.uri, nil)
+		if test.hostCookie != "" {
+			request.AddCookie(&http.Cookie{Name: "khaos", Value: test.hostCookie})
+		}
+		response := httptest.NewRecorder()
+		endpoint(response, request, nil)
+
+		assert.Equal(t, test.expectedStatusCode, response.Code, test.description)
+		assert.Equal(t, test.expectedBody, response.Body.String(), test.description)
+		assert.Empty(t, response.Header().Get("Set-Cookie"), test.description+": the uids cookie shouldn't be set")
+		for userKey, expectedUIDs := range test.expectedUIDs {
+			storedUIDs, _ := store.Get(context.Background(), userKey)
+			uids := make(map[string]string, len(storedUIDs))
+			for key, uid := range storedUIDs {
+				uids[key] = uid.UID
+			}
+			assert.Equal(t, expectedUIDs, uids, test.description)
+		}
+	}
+}
+
 func makeRequest(uri string, existingSyncs map[string]string) *http.Request {
 	request := httptest.NewRequest("GET", uri, nil)
 	if len(existingSyncs) > 0 {
@@ -530,7 +659,7 @@ func doRequest(req *http.Request, metrics metrics.MetricsEngine, syncersBidderNa
 		syncersByBidder[bidderName] = fakeSyncer{key: syncerKey, defaultSyncType: usersync.SyncTypeIFrame}
 	}
 
-	endpoint := NewSetUIDEndpoint(cfg.HostCookie, syncersByBidder, perms, analytics, metrics)
+	endpoint := NewSetUIDEndpoint(cfg.HostCookie, syncersByBidder, cfg.UserSync.UIDPriorityGroups, perms, analytics, metrics, nil)
 	response := httptest.NewRecorder()
 	endpoint(response, req, nil)
 	return response
@@ -21,6 +21,15 @@ const (
 	AccountLevelDebugDisabledWarningCode
 	BidderLevelDebugDisabledWarningCode
 	DisabledCurrencyConversionWarningCode
+	FloorsWarningCode
+	FloorBidRejectionWarningCode
+	MultiBidWarningCode
+	BidValidationWarningCode
+	BidAdjustmentWarningCode
+	AdsCertSignerWarningCode
+	FirstPartyDataWarningCode
+	AccountBiddersWarningCode
+	BidderCircuitOpenWarningCode
 )
 
 // Coder provides an error or warning code with severity.
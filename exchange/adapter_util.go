@@ -10,7 +10,9 @@ import (
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
-func BuildAdapters(client *http.Client, cfg *config.Configuration, infos config.BidderInfos, me metrics.MetricsEngine) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
+// BuildAdapters builds the adapters of the enabled bidders. Their requests go through the circuit breakers,
+// unless breakers is nil.
+func BuildAdapters(client *http.Client, cfg *config.Configuration, infos config.BidderInfos, me metrics.MetricsEngine, breakers *CircuitBreakers) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
 	bidders, errs := buildBidders(cfg.Adapters, infos, newAdapterBuilders())
 	if len(errs) > 0 {
 		return nil, errs
@@ -19,8 +21,9 @@ func BuildAdapters(client *http.Client, cfg *config.Configuration, infos config.
 	exchangeBidders := make(map[openrtb_ext.BidderName]adaptedBidder, len(bidders))
 	for bidderName, bidder := range bidders {
 		info := infos[string(bidderName)]
-		exchangeBidder := adaptBidder(bidder, client, cfg, me, bidderName, info.Debug)
+		exchangeBidder := adaptBidder(bidder, getBidderClient(client, bidderName, info.Transport), cfg, me, bidderName, info.Debug, info.EndpointCompression)
 		exchangeBidder = addValidatedBidderMiddleware(exchangeBidder)
+		exchangeBidder = addCircuitBreakerMiddleware(exchangeBidder, bidderName, breakers)
 		exchangeBidders[bidderName] = exchangeBidder
 	}
 	return exchangeBidders, nil
@@ -43,7 +46,13 @@ func buildBidders(adapterConfig map[string]config.Adapter, infos config.BidderIn
 			continue
 		}
 
-		builder, builderFound := builders[bidderName]
+		// The aliases configured by the host reuse the adapter of their parent
+		builderName := bidderName
+		if info.AliasOf != "" {
+			builderName = openrtb_ext.BidderName(info.AliasOf)
+		}
+
+		builder, builderFound := builders[builderName]
 		if !builderFound {
 			errs = append(errs, fmt.Errorf("%v: builder not registered", bidder))
 			continue
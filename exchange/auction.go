@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -106,32 +107,47 @@ func (d *DebugLog) PutDebugLogError(cache prebid_cache_client.Client, timeout in
 
 func newAuction(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int, preferDeals bool) *auction {
 	winningBids := make(map[string]*pbsOrtbBid, numImps)
-	winningBidsByBidder := make(map[string]map[openrtb_ext.BidderName]*pbsOrtbBid, numImps)
+	allBidsByBidder := make(map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid, numImps)
 
 	for bidderName, seatBid := range seatBids {
 		if seatBid != nil {
 			for _, bid := range seatBid.bids {
-				cpm := bid.bid.Price
 				wbid, ok := winningBids[bid.bid.ImpID]
 				if !ok || isNewWinningBid(bid.bid, wbid.bid, preferDeals) {
 					winningBids[bid.bid.ImpID] = bid
 				}
-				if bidMap, ok := winningBidsByBidder[bid.bid.ImpID]; ok {
-					bestSoFar, ok := bidMap[bidderName]
-					if !ok || cpm > bestSoFar.bid.Price {
-						bidMap[bidderName] = bid
-					}
-				} else {
-					winningBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName]*pbsOrtbBid)
-					winningBidsByBidder[bid.bid.ImpID][bidderName] = bid
+				if _, ok := allBidsByBidder[bid.bid.ImpID]; !ok {
+					allBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName][]*pbsOrtbBid)
 				}
+				allBidsByBidder[bid.bid.ImpID][bidderName] = append(allBidsByBidder[bid.bid.ImpID][bidderName], bid)
 			}
 		}
 	}
 
+	// The stable sort keeps the first bid received on ties, so the overall winner is always the best bid of its bidder
+	for _, bidsPerBidder := range allBidsByBidder {
+		for _, bids := range bidsPerBidder {
+			sort.SliceStable(bids, func(i, j int) bool {
+				return isNewWinningBid(bids[i].bid, bids[j].bid, preferDeals)
+			})
+		}
+	}
+
 	return &auction{
-		winningBids:         winningBids,
-		winningBidsByBidder: winningBidsByBidder,
+		winningBids:     winningBids,
+		allBidsByBidder: allBidsByBidder,
+	}
+}
+
+// applyMultiBid keeps only the best bids each bidder is allowed to have in targeting and cache, according
+// to the multibid config of the request. The other bids are still part of the response.
+func (a *auction) applyMultiBid(targData *targetData) {
+	for _, bidsPerBidder := range a.allBidsByBidder {
+		for bidderName, bids := range bidsPerBidder {
+			if maxBids := targData.maxBids(bidderName); len(bids) > maxBids {
+				bidsPerBidder[bidderName] = bids[:maxBids]
+			}
+		}
 	}
 }
 
@@ -150,9 +166,11 @@ func isNewWinningBid(bid, wbid *openrtb2.Bid, preferDeals bool) bool {
 
 func (a *auction) setRoundedPrices(priceGranularity openrtb_ext.PriceGranularity) {
 	roundedPrices := make(map[*pbsOrtbBid]string, 5*len(a.winningBids))
-	for _, topBidsPerImp := range a.winningBidsByBidder {
-		for _, topBidPerBidder := range topBidsPerImp {
-			roundedPrices[topBidPerBidder] = GetPriceBucket(topBidPerBidder.bid.Price, priceGranularity)
+	for _, bidsPerBidder := range a.allBidsByBidder {
+		for _, bids := range bidsPerBidder {
+			for _, bid := range bids {
+				roundedPrices[bid] = GetPriceBucket(bid.bid.Price, priceGranularity)
+			}
 		}
 	}
 	a.roundedPrices = roundedPrices
@@ -160,7 +178,7 @@ func (a *auction) setRoundedPrices(priceGranularity openrtb_ext.PriceGranularity
 
 func (a *auction) doCache(ctx context.Context, cache prebid_cache_client.Client, targData *targetData, evTracking *eventTracking, bidRequest *openrtb2.BidRequest, ttlBuffer int64, defaultTTLs *config.DefaultTTLs, bidCategory map[string]string, debugLog *DebugLog) []error {
 	var bids, vast, includeBidderKeys, includeWinners bool = targData.includeCacheBids, targData.includeCacheVast, targData.includeBidderKeys, targData.includeWinners
-	if !((bids || vast) && (includeBidderKeys || includeWinners)) {
+	if !((bids || vast) && (includeBidderKeys || includeWinners || targData.hasTargetBidderCodePrefix())) {
 		return nil
 	}
 	var errs []error
@@ -186,64 +204,68 @@ func (a *auction) doCache(ctx context.Context, cache prebid_cache_client.Client,
 	for _, imp := range bidRequest.Imp {
 		expByImp[imp.ID] = imp.Exp
 	}
-	for _, topBidsPerImp := range a.winningBidsByBidder {
-		for bidderName, topBidPerBidder := range topBidsPerImp {
-			impID := topBidPerBidder.bid.ImpID
-			isOverallWinner := a.winningBids[impID] == topBidPerBidder
-			if !includeBidderKeys && !isOverallWinner {
-				continue
-			}
-			var customCacheKey string
-			var catDur string
-			useCustomCacheKey := false
-			if competitiveExclusion && isOverallWinner || includeBidderKeys {
-				// set custom cache key for winning bid when competitive exclusion applies
-				catDur = bidCategory[topBidPerBidder.bid.ID]
-				if len(catDur) > 0 {
-					customCacheKey = fmt.Sprintf("%s_%s", catDur, hbCacheID)
-					useCustomCacheKey = true
+	for _, bidsPerBidder := range a.allBidsByBidder {
+		for bidderName, bidderBids := range bidsPerBidder {
+			for i, bid := range bidderBids {
+				impID := bid.bid.ImpID
+				isOverallWinner := a.winningBids[impID] == bid
+				// The extra bids targeted under a bidder code prefix are cached like the winning bids
+				hasBidderKeys := targData.hasBidderKeys(bidderName, i)
+				if !hasBidderKeys && !isOverallWinner {
+					continue
 				}
-			}
-			if bids {
-				if jsonBytes, err := json.Marshal(topBidPerBidder.bid); err == nil {
-					jsonBytes, err = evTracking.modifyBidJSON(topBidPerBidder, bidderName, jsonBytes)
-					if err != nil {
-						errs = append(errs, err)
-					}
-					if useCustomCacheKey {
-						// not allowed if bids is true; log error and cache normally
-						errs = append(errs, errors.New("cannot use custom cache key for non-vast bids"))
+				var customCacheKey string
+				var catDur string
+				useCustomCacheKey := false
+				if competitiveExclusion && isOverallWinner || hasBidderKeys {
+					// set custom cache key for winning bid when competitive exclusion applies
+					catDur = bidCategory[bid.bid.ID]
+					if len(catDur) > 0 {
+						customCacheKey = fmt.Sprintf("%s_%s", catDur, hbCacheID)
+						useCustomCacheKey = true
 					}
-					toCache = append(toCache, prebid_cache_client.Cacheable{
-						Type:       prebid_cache_client.TypeJSON,
-						Data:       jsonBytes,
-						TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-					})
-					bidIndices[len(toCache)-1] = topBidPerBidder.bid
-				} else {
-					errs = append(errs, err)
 				}
-			}
-			if vast && topBidPerBidder.bidType == openrtb_ext.BidTypeVideo {
-				vastXML := makeVAST(topBidPerBidder.bid)
-				if jsonBytes, err := json.Marshal(vastXML); err == nil {
-					if useCustomCacheKey {
+				if bids {
+					if jsonBytes, err := json.Marshal(bid.bid); err == nil {
+						jsonBytes, err = evTracking.modifyBidJSON(bid, bidderName, jsonBytes)
+						if err != nil {
+							errs = append(errs, err)
+						}
+						if useCustomCacheKey {
+							// not allowed if bids is true; log error and cache normally
+							errs = append(errs, errors.New("cannot use custom cache key for non-vast bids"))
+						}
 						toCache = append(toCache, prebid_cache_client.Cacheable{
-							Type:       prebid_cache_client.TypeXML,
+							Type:       prebid_cache_client.TypeJSON,
 							Data:       jsonBytes,
-							TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-							Key:        customCacheKey,
+							TTLSeconds: cacheTTL(expByImp[impID], bid.bid.Exp, defTTL(bid.bidType, defaultTTLs), ttlBuffer),
 						})
+						bidIndices[len(toCache)-1] = bid.bid
 					} else {
-						toCache = append(toCache, prebid_cache_client.Cacheable{
-							Type:       prebid_cache_client.TypeXML,
-							Data:       jsonBytes,
-							TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-						})
+						errs = append(errs, err)
+					}
+				}
+				if vast && bid.bidType == openrtb_ext.BidTypeVideo {
+					vastXML := makeVAST(bid.bid)
+					if jsonBytes, err := json.Marshal(vastXML); err == nil {
+						if useCustomCacheKey {
+							toCache = append(toCache, prebid_cache_client.Cacheable{
+								Type:       prebid_cache_client.TypeXML,
+								Data:       jsonBytes,
+								TTLSeconds: cacheTTL(expByImp[impID], bid.bid.Exp, defTTL(bid.bidType, defaultTTLs), ttlBuffer),
+								Key:        customCacheKey,
+							})
+						} else {
+							toCache = append(toCache, prebid_cache_client.Cacheable{
+								Type:       prebid_cache_client.TypeXML,
+								Data:       jsonBytes,
+								TTLSeconds: cacheTTL(expByImp[impID], bid.bid.Exp, defTTL(bid.bidType, defaultTTLs), ttlBuffer),
+							})
+						}
+						vastIndices[len(toCache)-1] = bid.bid
+					} else {
+						errs = append(errs, err)
 					}
-					vastIndices[len(toCache)-1] = topBidPerBidder.bid
-				} else {
-					errs = append(errs, err)
 				}
 			}
 		}
@@ -361,8 +383,9 @@ func defTTL(bidType openrtb_ext.BidType, defaultTTLs *config.DefaultTTLs) (ttl i
 type auction struct {
 	// winningBids is a map from imp.id to the highest overall CPM bid in that imp.
 	winningBids map[string]*pbsOrtbBid
-	// winningBidsByBidder stores the highest bid on each imp by each bidder.
-	winningBidsByBidder map[string]map[openrtb_ext.BidderName]*pbsOrtbBid
+	// allBidsByBidder stores the bids on each imp by each bidder, best bid first. Once the multibid config is
+	// applied it only holds the bids which take part in targeting and cache.
+	allBidsByBidder map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid
 	// roundedPrices stores the price strings rounded for each bid according to the price granularity.
 	roundedPrices map[*pbsOrtbBid]string
 	// cacheIds stores the UUIDs from Prebid Cache for fetching the full bid JSON.
@@ -188,7 +188,7 @@ func loadCacheSpec(filename string) (*cacheSpec, error) {
 func runCacheSpec(t *testing.T, fileDisplayName string, specData *cacheSpec) {
 	var bid *pbsOrtbBid
 	winningBidsByImp := make(map[string]*pbsOrtbBid)
-	winningBidsByBidder := make(map[string]map[openrtb_ext.BidderName]*pbsOrtbBid)
+	allBidsByBidder := make(map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid)
 	roundedPrices := make(map[*pbsOrtbBid]string)
 	bidCategory := make(map[string]string)
 
@@ -207,14 +207,11 @@ func runCacheSpec(t *testing.T, fileDisplayName string, specData *cacheSpec) {
 		}
 
 		// Map this bid if it's the highest we've seen from this bidder so far
-		if _, ok := winningBidsByBidder[bid.bid.ImpID]; ok {
-			bestSoFar, ok := winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder]
-			if !ok || cpm > bestSoFar.bid.Price {
-				winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = bid
-			}
-		} else {
-			winningBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName]*pbsOrtbBid)
-			winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = bid
+		if _, ok := allBidsByBidder[bid.bid.ImpID]; !ok {
+			allBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName][]*pbsOrtbBid)
+		}
+		if bestSoFar := allBidsByBidder[bid.bid.ImpID][pbsBid.Bidder]; len(bestSoFar) == 0 || cpm > bestSoFar[0].bid.Price {
+			allBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = []*pbsOrtbBid{bid}
 		}
 
 		if len(pbsBid.Bid.Cat) == 1 {
@@ -254,9 +251,9 @@ func runCacheSpec(t *testing.T, fileDisplayName string, specData *cacheSpec) {
 	}
 
 	testAuction := &auction{
-		winningBids:         winningBidsByImp,
-		winningBidsByBidder: winningBidsByBidder,
-		roundedPrices:       roundedPrices,
+		winningBids:     winningBidsByImp,
+		allBidsByBidder: allBidsByBidder,
+		roundedPrices:   roundedPrices,
 	}
 	evTracking := &eventTracking{
 		accountID:          "TEST_ACC_ID",
@@ -384,10 +381,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p230,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p123,
-						"rubicon":  &bid1p230,
+						"appnexus": {&bid1p123},
+						"rubicon":  {&bid1p230},
 					},
 				},
 			},
@@ -412,15 +409,15 @@ func TestNewAuction(t *testing.T) {
 					"imp1": &bid1p230,
 					"imp2": &bid2p144,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p230,
-						"rubicon":  &bid1p077,
-						"openx":    &bid1p123,
+						"appnexus": {&bid1p230},
+						"rubicon":  {&bid1p077},
+						"openx":    {&bid1p123},
 					},
 					"imp2": {
-						"appnexus": &bid2p123,
-						"rubicon":  &bid2p144,
+						"appnexus": {&bid2p123},
+						"rubicon":  {&bid2p144},
 					},
 				},
 			},
@@ -441,10 +438,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p123,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p123,
-						"rubicon":  &bid1p088d,
+						"appnexus": {&bid1p123},
+						"rubicon":  {&bid1p088d},
 					},
 				},
 			},
@@ -465,10 +462,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p088d,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p123,
-						"rubicon":  &bid1p088d,
+						"appnexus": {&bid1p123},
+						"rubicon":  {&bid1p088d},
 					},
 				},
 			},
@@ -489,10 +486,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p166d,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p166d,
-						"rubicon":  &bid1p088d,
+						"appnexus": {&bid1p166d},
+						"rubicon":  {&bid1p088d},
 					},
 				},
 			},
@@ -516,11 +513,55 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p166d,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
+					"imp1": {
+						"appnexus": {&bid1p166d},
+						"rubicon":  {&bid1p088d},
+						"openx":    {&bid1p230},
+					},
+				},
+			},
+		},
+		{
+			description: "Several bids from a bidder on the same imp, best bid first",
+			seatBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {
+					bids: []*pbsOrtbBid{&bid1p077, &bid1p230, &bid1p123},
+				},
+				"rubicon": {
+					bids: []*pbsOrtbBid{&bid1p088d},
+				},
+			},
+			numImps:     1,
+			preferDeals: false,
+			expectedAuction: auction{
+				winningBids: map[string]*pbsOrtbBid{
+					"imp1": &bid1p230,
+				},
+				allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
+					"imp1": {
+						"appnexus": {&bid1p230, &bid1p123, &bid1p077},
+						"rubicon":  {&bid1p088d},
+					},
+				},
+			},
+		},
+		{
+			description: "Several bids from a bidder on the same imp, prefer deals",
+			seatBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {
+					bids: []*pbsOrtbBid{&bid1p230, &bid1p088d, &bid1p166d},
+				},
+			},
+			numImps:     1,
+			preferDeals: true,
+			expectedAuction: auction{
+				winningBids: map[string]*pbsOrtbBid{
+					"imp1": &bid1p166d,
+				},
+				allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p166d,
-						"rubicon":  &bid1p088d,
-						"openx":    &bid1p230,
+						"appnexus": {&bid1p166d, &bid1p088d, &bid1p230},
 					},
 				},
 			},
@@ -535,6 +576,132 @@ func TestNewAuction(t *testing.T) {
 
 }
 
+func TestApplyMultiBid(t *testing.T) {
+	bid1 := &pbsOrtbBid{bid: &openrtb2.Bid{ImpID: "imp1", Price: 3}}
+	bid2 := &pbsOrtbBid{bid: &openrtb2.Bid{ImpID: "imp1", Price: 2}}
+	bid3 := &pbsOrtbBid{bid: &openrtb2.Bid{ImpID: "imp1", Price: 1}}
+	maxBids := 2
+
+	testCases := []struct {
+		description     string
+		targData        *targetData
+		expectedBidders map[openrtb_ext.BidderName][]*pbsOrtbBid
+	}{
+		{
+			description: "No multibid config keeps the best bid of each bidder",
+			targData:    &targetData{},
+			expectedBidders: map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"appnexus": {bid1},
+				"rubicon":  {bid2},
+			},
+		},
+		{
+			description: "Multibid config for one bidder",
+			targData: &targetData{multiBid: map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid{
+				"appnexus": {Bidder: "appnexus", MaxBids: &maxBids},
+			}},
+			expectedBidders: map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"appnexus": {bid1, bid2},
+				"rubicon":  {bid2},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		auc := &auction{allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
+			"imp1": {
+				"appnexus": {bid1, bid2, bid3},
+				"rubicon":  {bid2, bid3},
+			},
+		}}
+		auc.applyMultiBid(test.targData)
+		assert.Equal(t, test.expectedBidders, auc.allBidsByBidder["imp1"], test.description)
+	}
+}
+
+func TestDoCacheMultiBid(t *testing.T) {
+	bid1 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "bid1", ImpID: "imp1", Price: 3}, bidType: openrtb_ext.BidTypeBanner}
+	bid2 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "bid2", ImpID: "imp1", Price: 2}, bidType: openrtb_ext.BidTypeBanner}
+	bid3 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "bid3", ImpID: "imp1", Price: 1}, bidType: openrtb_ext.BidTypeBanner}
+	maxBids := 3
+
+	testCases := []struct {
+		description       string
+		includeWinners    bool
+		includeBidderKeys bool
+		multiBid          map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid
+		expectedCachedIDs []string
+	}{
+		{
+			description:    "Extra bids with a bidder code prefix cached without the bidder keys",
+			includeWinners: true,
+			multiBid: map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid{
+				openrtb_ext.BidderAppnexus: {Bidder: "appnexus", MaxBids: &maxBids, TargetBidderCodePrefix: "apn"},
+			},
+			expectedCachedIDs: []string{"bid1", "bid2", "bid3"},
+		},
+		{
+			description: "Extra bids with a bidder code prefix cached without the winners keys",
+			multiBid: map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid{
+				openrtb_ext.BidderAppnexus: {Bidder: "appnexus", MaxBids: &maxBids, TargetBidderCodePrefix: "apn"},
+			},
+			expectedCachedIDs: []string{"bid1", "bid2", "bid3"},
+		},
+		{
+			description:    "Extra bids without a bidder code prefix not cached",
+			includeWinners: true,
+			multiBid: map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid{
+				openrtb_ext.BidderAppnexus: {Bidder: "appnexus", MaxBids: &maxBids},
+			},
+			expectedCachedIDs: []string{"bid1"},
+		},
+		{
+			description:       "Extra bids without a bidder code prefix not cached with the bidder keys",
+			includeBidderKeys: true,
+			multiBid: map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid{
+				openrtb_ext.BidderAppnexus: {Bidder: "appnexus", MaxBids: &maxBids},
+			},
+			expectedCachedIDs: []string{"bid1"},
+		},
+		{
+			description:       "Extra bids with a bidder code prefix cached with the bidder keys",
+			includeBidderKeys: true,
+			multiBid: map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid{
+				openrtb_ext.BidderAppnexus: {Bidder: "appnexus", MaxBids: &maxBids, TargetBidderCodePrefix: "apn"},
+			},
+			expectedCachedIDs: []string{"bid1", "bid2", "bid3"},
+		},
+	}
+
+	for _, test := range testCases {
+		cache := &mockCache{}
+		targData := &targetData{
+			includeWinners:    test.includeWinners,
+			includeBidderKeys: test.includeBidderKeys,
+			includeCacheBids:  true,
+			multiBid:          test.multiBid,
+		}
+		auc := &auction{
+			winningBids: map[string]*pbsOrtbBid{"imp1": bid1},
+			allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"imp1": {openrtb_ext.BidderAppnexus: {bid1, bid2, bid3}},
+			},
+		}
+		bidRequest := &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp1"}}}
+		auc.doCache(context.Background(), cache, targData, &eventTracking{}, bidRequest, 60, &config.DefaultTTLs{}, nil, nil)
+
+		var cachedIDs []string
+		for _, item := range cache.items {
+			var bid openrtb2.Bid
+			if err := json.Unmarshal(item.Data, &bid); err != nil {
+				t.Fatalf("%s: failed to read the cached bid: %v", test.description, err)
+			}
+			cachedIDs = append(cachedIDs, bid.ID)
+		}
+		assert.Equal(t, test.expectedCachedIDs, cachedIDs, test.description)
+	}
+}
+
 type cacheSpec struct {
 	BidRequest                  openrtb2.BidRequest             `json:"bidRequest"`
 	PbsBids                     []pbsBid                        `json:"pbsBids"`
@@ -0,0 +1,51 @@
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/bidadjustment"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// applyBidAdjustments adjusts the price of the bids by the adjustments matching their media type, bidder and deal
+// ID. The bids left without a positive price, which is only allowed for deals, are removed.
+func applyBidAdjustments(seatBid *pbsOrtbSeatBid, bidderName openrtb_ext.BidderName, adjustments *openrtb_ext.ExtRequestPrebidBidAdjustments, conversions currency.Conversions) []error {
+	if seatBid == nil || len(seatBid.bids) == 0 || adjustments == nil {
+		return nil
+	}
+
+	var errs []error
+	validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+	for _, bid := range seatBid.bids {
+		bidAdjustments := bidadjustment.Get(adjustments, bid.bidType, bidderName, bid.bid.DealID)
+		if len(bidAdjustments) == 0 {
+			validBids = append(validBids, bid)
+			continue
+		}
+
+		price, err := bidadjustment.Apply(bidAdjustments, bid.bid.Price, seatBid.currency, conversions)
+		if err != nil {
+			errs = append(errs, &errortypes.Warning{
+				WarningCode: errortypes.BidAdjustmentWarningCode,
+				Message:     fmt.Sprintf("bid adjustment not applied [bid ID: %s] reason: %v", bid.bid.ID, err),
+			})
+			validBids = append(validBids, bid)
+			continue
+		}
+
+		if price < 0 || (price == 0 && bid.bid.DealID == "") {
+			errs = append(errs, &errortypes.Warning{
+				WarningCode: errortypes.BidAdjustmentWarningCode,
+				Message:     fmt.Sprintf("bid rejected [bid ID: %s] reason: adjusted bid price %.4f %s is not positive", bid.bid.ID, price, seatBid.currency),
+			})
+			continue
+		}
+		bid.bid.Price = price
+		validBids = append(validBids, bid)
+	}
+	seatBid.bids = validBids
+
+	return errs
+}
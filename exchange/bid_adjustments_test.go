@@ -0,0 +1,74 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyBidAdjustments(t *testing.T) {
+	adjustments := &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: openrtb_ext.AdjustmentsByMediaType{
+		Banner: openrtb_ext.AdjustmentsByBidder{
+			"appnexus": {
+				"deal-1": {{Type: openrtb_ext.AdjustmentTypeStatic, Value: 5, Currency: "EUR"}},
+				"*":      {{Type: openrtb_ext.AdjustmentTypeMultiplier, Value: 0.5}},
+			},
+		},
+		Video: openrtb_ext.AdjustmentsByBidder{
+			"*": {"*": {{Type: openrtb_ext.AdjustmentTypeCPM, Value: 1, Currency: "USD"}}},
+		},
+		Native: openrtb_ext.AdjustmentsByBidder{
+			"*": {"*": {{Type: openrtb_ext.AdjustmentTypeCPM, Value: 1, Currency: "JPY"}}},
+		},
+	}}
+	conversions := currency.NewRates(map[string]map[string]float64{"EUR": {"USD": 1.2}})
+
+	seatBid := &pbsOrtbSeatBid{
+		currency: "USD",
+		bids: []*pbsOrtbBid{
+			{bid: &openrtb2.Bid{ID: "multiplier", Price: 2}, bidType: openrtb_ext.BidTypeBanner},
+			{bid: &openrtb2.Bid{ID: "static", Price: 2, DealID: "deal-1"}, bidType: openrtb_ext.BidTypeBanner},
+			{bid: &openrtb2.Bid{ID: "cpm", Price: 2}, bidType: openrtb_ext.BidTypeVideo},
+			{bid: &openrtb2.Bid{ID: "negative", Price: 0.5}, bidType: openrtb_ext.BidTypeVideo},
+			{bid: &openrtb2.Bid{ID: "unconverted", Price: 2}, bidType: openrtb_ext.BidTypeNative},
+			{bid: &openrtb2.Bid{ID: "none", Price: 2}, bidType: openrtb_ext.BidTypeAudio},
+		},
+	}
+
+	errs := applyBidAdjustments(seatBid, openrtb_ext.BidderAppnexus, adjustments, conversions)
+
+	prices := make(map[string]float64, len(seatBid.bids))
+	for _, bid := range seatBid.bids {
+		prices[bid.bid.ID] = bid.bid.Price
+	}
+	assert.Equal(t, map[string]float64{
+		"multiplier":  1,
+		"static":      6,
+		"cpm":         1,
+		"unconverted": 2,
+		"none":        2,
+	}, prices)
+
+	if assert.Len(t, errs, 2) {
+		for _, err := range errs {
+			assert.Equal(t, errortypes.BidAdjustmentWarningCode, errortypes.ReadCode(err))
+		}
+	}
+}
+
+func TestApplyBidAdjustmentsNone(t *testing.T) {
+	seatBid := &pbsOrtbSeatBid{
+		bids: []*pbsOrtbBid{
+			{bid: &openrtb2.Bid{ID: "bid", Price: 2}, bidType: openrtb_ext.BidTypeBanner},
+		},
+	}
+
+	errs := applyBidAdjustments(seatBid, openrtb_ext.BidderAppnexus, nil, currency.NewConstantRates())
+
+	assert.Empty(t, errs)
+	assert.Equal(t, 2.0, seatBid.bids[0].bid.Price)
+}
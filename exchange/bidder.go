@@ -2,6 +2,7 @@ package exchange
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -10,6 +11,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptrace"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -22,8 +25,13 @@ import (
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/experiment/adscert"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context/ctxhttp"
 )
 
@@ -49,7 +57,7 @@ type adaptedBidder interface {
 	//
 	// Any errors will be user-facing in the API.
 	// Error messages should help publishers understand what might account for "bad" bids.
-	requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool) (*pbsOrtbSeatBid, []error)
+	requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error)
 }
 
 // pbsOrtbBid is a Bid returned by an adaptedBidder.
@@ -93,16 +101,17 @@ type pbsOrtbSeatBid struct {
 //
 // The name refers to the "Adapter" architecture pattern, and should not be confused with a Prebid "Adapter"
 // (which is being phased out and replaced by Bidder for OpenRTB auctions)
-func adaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *config.DebugInfo) adaptedBidder {
+func adaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *config.DebugInfo, endpointCompression string) adaptedBidder {
 	return &bidderAdapter{
 		Bidder:     bidder,
 		BidderName: name,
 		Client:     client,
 		me:         me,
 		config: bidderAdapterConfig{
-			Debug:              cfg.Debug,
-			DisableConnMetrics: cfg.Metrics.Disabled.AdapterConnectionMetrics,
-			DebugInfo:          config.DebugInfo{Allow: parseDebugInfo(debugInfo)},
+			Debug:               cfg.Debug,
+			DisableConnMetrics:  cfg.Metrics.Disabled.AdapterConnectionMetrics,
+			DebugInfo:           config.DebugInfo{Allow: parseDebugInfo(debugInfo)},
+			EndpointCompression: endpointCompression,
 		},
 	}
 }
@@ -122,16 +131,29 @@ type bidderAdapter struct {
 	config     bidderAdapterConfig
 }
 
+// bidRequestOptions holds the request level settings which drive how a bidder is called
+type bidRequestOptions struct {
+	accountDebugAllowed bool
+	headerDebugAllowed  bool
+	addCallSignHeader   bool
+}
+
 type bidderAdapterConfig struct {
-	Debug              config.Debug
-	DisableConnMetrics bool
-	DebugInfo          config.DebugInfo
+	Debug               config.Debug
+	DisableConnMetrics  bool
+	DebugInfo           config.DebugInfo
+	EndpointCompression string
 }
 
-func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
-	reqData, errs := bidder.Bidder.MakeRequests(request, reqInfo)
+func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error) {
+	// The imps with a stored response are left out of the bidder requests. The bidder isn't called when all of them have one.
+	var reqData []*adapters.RequestData
+	var errs []error
+	if bidderRequest := removeImpsWithStoredResponses(request, bidderStoredResponses); len(bidderStoredResponses) == 0 || len(bidderRequest.Imp) > 0 {
+		reqData, errs = bidder.Bidder.MakeRequests(bidderRequest, reqInfo)
+	}
 
-	if len(reqData) == 0 {
+	if len(reqData) == 0 && len(bidderStoredResponses) == 0 {
 		// If the adapter failed to generate both requests and errors, this is an error.
 		if len(errs) == 0 {
 			errs = append(errs, &errortypes.FailedToRequestBids{Message: "The adapter failed to generate any bid requests, but also failed to generate an error explaining why"})
@@ -152,9 +174,14 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 		}
 	}
 
+	if bidRequestOptions.addCallSignHeader {
+		errs = append(errs, bidder.addAdsCertSignHeaders(reqData, adsCertSigner)...)
+	}
+
 	// Make any HTTP requests in parallel.
 	// If the bidder only needs to make one, save some cycles by just using the current one.
-	responseChannel := make(chan *httpCallInfo, len(reqData))
+	responseCount := len(reqData) + len(bidderStoredResponses)
+	responseChannel := make(chan *httpCallInfo, responseCount)
 	if len(reqData) == 1 {
 		responseChannel <- bidder.doRequest(ctx, reqData[0])
 	} else {
@@ -164,17 +191,20 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 			}(oneReqData) // Method arg avoids a race condition on oneReqData
 		}
 	}
+	for impID, storedResponse := range bidderStoredResponses {
+		responseChannel <- prepareStoredResponse(request, impID, storedResponse)
+	}
 
 	defaultCurrency := "USD"
 	seatBid := &pbsOrtbSeatBid{
-		bids:      make([]*pbsOrtbBid, 0, len(reqData)),
+		bids:      make([]*pbsOrtbBid, 0, responseCount),
 		currency:  defaultCurrency,
-		httpCalls: make([]*openrtb_ext.ExtHttpCall, 0, len(reqData)),
+		httpCalls: make([]*openrtb_ext.ExtHttpCall, 0, responseCount),
 	}
 
 	// If the bidder made multiple requests, we still want them to enter as many bids as possible...
 	// even if the timeout occurs sometime halfway through.
-	for i := 0; i < len(reqData); i++ {
+	for i := 0; i < responseCount; i++ {
 		httpInfo := <-responseChannel
 		// If this is a test bid, capture debugging info from the requests.
 		// Write debug data to ext in case if:
@@ -182,12 +212,12 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 		// - debugContextKey (url param) in true
 		// - account debug is allowed
 		// - bidder debug is allowed
-		if headerDebugAllowed {
+		if bidRequestOptions.headerDebugAllowed {
 			seatBid.httpCalls = append(seatBid.httpCalls, makeExt(httpInfo))
 		} else {
 			debugInfo := ctx.Value(DebugContextKey)
 			if debugInfo != nil && debugInfo.(bool) {
-				if accountDebugAllowed {
+				if bidRequestOptions.accountDebugAllowed {
 					if bidder.config.DebugInfo.Allow {
 						seatBid.httpCalls = append(seatBid.httpCalls, makeExt(httpInfo))
 					} else {
@@ -272,6 +302,48 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 	return seatBid, errs
 }
 
+// removeImpsWithStoredResponses returns a copy of the request without the imps which have a stored response.
+func removeImpsWithStoredResponses(request *openrtb2.BidRequest, storedResponses map[string]json.RawMessage) *openrtb2.BidRequest {
+	if len(storedResponses) == 0 {
+		return request
+	}
+
+	imps := make([]openrtb2.Imp, 0, len(request.Imp))
+	for _, imp := range request.Imp {
+		if _, hasStoredResponse := storedResponses[imp.ID]; !hasStoredResponse {
+			imps = append(imps, imp)
+		}
+	}
+	requestCopy := *request
+	requestCopy.Imp = imps
+	return &requestCopy
+}
+
+// prepareStoredResponse fakes the bidder call of an imp with a stored response, so that the bidder parses it with MakeBids.
+// The request body holds the bid request restricted to the imp, as it would have been sent to the bidder.
+func prepareStoredResponse(request *openrtb2.BidRequest, impID string, storedResponse json.RawMessage) *httpCallInfo {
+	requestCopy := *request
+	requestCopy.Imp = nil
+	for _, imp := range request.Imp {
+		if imp.ID == impID {
+			requestCopy.Imp = []openrtb2.Imp{imp}
+			break
+		}
+	}
+	requestBody, err := json.Marshal(requestCopy)
+	return &httpCallInfo{
+		request: &adapters.RequestData{
+			Method: http.MethodPost,
+			Body:   requestBody,
+		},
+		response: &adapters.ResponseData{
+			StatusCode: http.StatusOK,
+			Body:       storedResponse,
+		},
+		err: err,
+	}
+}
+
 func addNativeTypes(bid *openrtb2.Bid, request *openrtb2.BidRequest) (*nativeResponse.Response, []error) {
 	var errs []error
 	var nativeMarkup *nativeResponse.Response
@@ -381,21 +453,67 @@ func makeExt(httpInfo *httpCallInfo) *openrtb_ext.ExtHttpCall {
 	return ext
 }
 
+// addAdsCertSignHeaders signs the bidder requests with ads.cert. A request which can't be signed is still
+// sent without the header, since signing is not allowed to block the auction.
+func (bidder *bidderAdapter) addAdsCertSignHeaders(reqData []*adapters.RequestData, adsCertSigner adscert.Signer) []error {
+	var errs []error
+	for i := 0; i < len(reqData); i++ {
+		startSignRequestTime := time.Now()
+		signatureMessage, err := adsCertSigner.Sign(reqData[i].Uri, reqData[i].Body)
+		bidder.me.RecordAdsCertSignTime(time.Since(startSignRequestTime))
+		if err != nil {
+			bidder.me.RecordAdsCertReq(false)
+			errs = append(errs, &errortypes.Warning{
+				WarningCode: errortypes.AdsCertSignerWarningCode,
+				Message:     fmt.Sprintf("AdsCert signer is enabled but cannot sign the request: %s", err.Error()),
+			})
+			continue
+		}
+		bidder.me.RecordAdsCertReq(true)
+		if signatureMessage == "" {
+			continue
+		}
+		if reqData[i].Headers != nil {
+			reqData[i].Headers = reqData[i].Headers.Clone()
+		} else {
+			reqData[i].Headers = http.Header{}
+		}
+		reqData[i].Headers.Add(adscert.SignHeader, signatureMessage)
+	}
+	return errs
+}
+
 // doRequest makes a request, handles the response, and returns the data needed by the
 // Bidder interface.
 func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.RequestData) *httpCallInfo {
-	return bidder.doRequestImpl(ctx, req, glog.Warningf)
+	ctx, span := tracing.StartSpan(ctx, "bidder.request", trace.SpanKindClient)
+	defer span.End()
+	span.SetAttributes(attribute.String("bidder", string(bidder.BidderName)), semconv.HTTPMethodKey.String(req.Method))
+
+	callInfo := bidder.doRequestImpl(tracing.WithClientTrace(ctx, span), req, glog.Warningf)
+	if callInfo.response != nil {
+		tracing.SetHTTPStatus(span, trace.SpanKindClient, callInfo.response.StatusCode)
+	}
+	tracing.RecordError(span, callInfo.err)
+	return callInfo
 }
 
 func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg) *httpCallInfo {
-	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(req.Body))
+	body, headers, err := bidder.encodeRequestBody(req)
 	if err != nil {
 		return &httpCallInfo{
 			request: req,
 			err:     err,
 		}
 	}
-	httpReq.Header = req.Headers
+	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(body))
+	if err != nil {
+		return &httpCallInfo{
+			request: req,
+			err:     err,
+		}
+	}
+	httpReq.Header = headers
 
 	// If adapter connection metrics are not disabled, add the client trace
 	// to get complete connection info into our metrics
@@ -453,6 +571,39 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	}
 }
 
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// encodeRequestBody compresses the request body if the bidder accepts gzip requests. The headers of the
+// request are copied before adding the Content-Encoding, so that the debug info shows the adapter's ones.
+func (bidder *bidderAdapter) encodeRequestBody(req *adapters.RequestData) ([]byte, http.Header, error) {
+	if !strings.EqualFold(bidder.config.EndpointCompression, config.CompressionGZIP) || len(req.Body) == 0 {
+		return req.Body, req.Headers, nil
+	}
+
+	var body bytes.Buffer
+	writer := gzipWriterPool.Get().(*gzip.Writer)
+	defer gzipWriterPool.Put(writer)
+	writer.Reset(&body)
+	if _, err := writer.Write(req.Body); err != nil {
+		return nil, nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, err
+	}
+	bidder.me.RecordAdapterCompressedRequestSize(bidder.BidderName, body.Len())
+
+	headers := req.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	headers.Set("Content-Encoding", "gzip")
+	return body.Bytes(), headers, nil
+}
+
 func (bidder *bidderAdapter) doTimeoutNotification(timeoutBidder adapters.TimeoutBidder, req *adapters.RequestData, logger util.LogMsg) {
 	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 	defer cancel()
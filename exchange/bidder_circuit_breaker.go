@@ -0,0 +1,286 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/experiment/adscert"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// CircuitBreakers holds the circuit breaker of each bidder. They outlive the adapters, so that the breakers
+// keep their state when the bidder configuration is reloaded.
+type CircuitBreakers struct {
+	cfg config.CircuitBreaker
+	me  metrics.MetricsEngine
+	now func() time.Time
+
+	mutex    sync.Mutex
+	breakers map[openrtb_ext.BidderName]*circuitBreaker
+}
+
+// CircuitBreakerStatus describes the state of the circuit breaker of a bidder, and the requests in its window.
+type CircuitBreakerStatus struct {
+	State    metrics.CircuitBreakerState `json:"state"`
+	Requests int                         `json:"requests"`
+	Errors   int                         `json:"errors"`
+	Timeouts int                         `json:"timeouts"`
+	OpenedAt *time.Time                  `json:"opened_at,omitempty"`
+}
+
+// NewCircuitBreakers creates the registry of the bidder circuit breakers.
+func NewCircuitBreakers(cfg config.CircuitBreaker, me metrics.MetricsEngine) *CircuitBreakers {
+	return &CircuitBreakers{
+		cfg:      cfg,
+		me:       me,
+		now:      time.Now,
+		breakers: make(map[openrtb_ext.BidderName]*circuitBreaker),
+	}
+}
+
+// Status returns the current status of the circuit breaker of each bidder which was called.
+func (c *CircuitBreakers) Status() map[openrtb_ext.BidderName]CircuitBreakerStatus {
+	c.mutex.Lock()
+	breakers := make(map[openrtb_ext.BidderName]*circuitBreaker, len(c.breakers))
+	for name, breaker := range c.breakers {
+		breakers[name] = breaker
+	}
+	c.mutex.Unlock()
+
+	statuses := make(map[openrtb_ext.BidderName]CircuitBreakerStatus, len(breakers))
+	for name, breaker := range breakers {
+		statuses[name] = breaker.status()
+	}
+	return statuses
+}
+
+func (c *CircuitBreakers) get(bidderName openrtb_ext.BidderName) *circuitBreaker {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	breaker, ok := c.breakers[bidderName]
+	if !ok {
+		breaker = newCircuitBreaker(bidderName, c.cfg, c.me, c.now)
+		c.breakers[bidderName] = breaker
+	}
+	return breaker
+}
+
+// addCircuitBreakerMiddleware returns a bidder which isn't called while the circuit breaker of the
+// bidder is open. A nil CircuitBreakers returns the bidder as is.
+func addCircuitBreakerMiddleware(bidder adaptedBidder, bidderName openrtb_ext.BidderName, breakers *CircuitBreakers) adaptedBidder {
+	if breakers == nil {
+		return bidder
+	}
+	return &circuitBreakerBidder{
+		bidder:  bidder,
+		breaker: breakers.get(bidderName),
+	}
+}
+
+type circuitBreakerBidder struct {
+	bidder  adaptedBidder
+	breaker *circuitBreaker
+}
+
+func (b *circuitBreakerBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error) {
+	probe, allowed := b.breaker.allow()
+	if !allowed {
+		return nil, []error{&errortypes.Warning{
+			WarningCode: errortypes.BidderCircuitOpenWarningCode,
+			Message:     fmt.Sprintf("%s was not called because it failed too many recent requests", name),
+		}}
+	}
+
+	seatBid, errs := b.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo, adsCertSigner, bidRequestOptions, bidderStoredResponses)
+	b.breaker.record(probe, getRequestOutcome(errs))
+	return seatBid, errs
+}
+
+type requestOutcome int
+
+const (
+	requestSucceeded requestOutcome = iota
+	requestFailed
+	requestTimedOut
+)
+
+// getRequestOutcome tells whether the errors of a bidder show that its endpoint is unhealthy. The invalid
+// requests or bids are the bidder's own business, and count as successes.
+func getRequestOutcome(errs []error) requestOutcome {
+	outcome := requestSucceeded
+	for _, err := range errs {
+		switch errortypes.ReadCode(err) {
+		case errortypes.TimeoutErrorCode:
+			return requestTimedOut
+		case errortypes.BadServerResponseErrorCode:
+			outcome = requestFailed
+		default:
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				outcome = requestFailed
+			}
+		}
+	}
+	return outcome
+}
+
+// circuitBreaker counts the outcome of the requests of a bidder in a sliding window, made of buckets which
+// each cover a slice of the window.
+type circuitBreaker struct {
+	bidderName     openrtb_ext.BidderName
+	cfg            config.CircuitBreaker
+	me             metrics.MetricsEngine
+	now            func() time.Time
+	bucketDuration time.Duration
+	openDuration   time.Duration
+
+	mutex          sync.Mutex
+	state          metrics.CircuitBreakerState
+	buckets        []windowBucket
+	openedAt       time.Time
+	probes         int
+	probeSuccesses int
+}
+
+type windowBucket struct {
+	index    int64
+	requests int
+	errors   int
+	timeouts int
+}
+
+func newCircuitBreaker(bidderName openrtb_ext.BidderName, cfg config.CircuitBreaker, me metrics.MetricsEngine, now func() time.Time) *circuitBreaker {
+	return &circuitBreaker{
+		bidderName:     bidderName,
+		cfg:            cfg,
+		me:             me,
+		now:            now,
+		bucketDuration: time.Duration(cfg.WindowMs) * time.Millisecond / time.Duration(cfg.WindowBuckets),
+		openDuration:   time.Duration(cfg.OpenDurationMs) * time.Millisecond,
+		state:          metrics.CircuitBreakerClosed,
+		buckets:        make([]windowBucket, cfg.WindowBuckets),
+	}
+}
+
+// allow tells whether the bidder can be called, and if so whether the request is a probe of a half open breaker.
+func (cb *circuitBreaker) allow() (probe bool, allowed bool) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.state == metrics.CircuitBreakerOpen && cb.now().Sub(cb.openedAt) >= cb.openDuration {
+		cb.setState(metrics.CircuitBreakerHalfOpen)
+		cb.probes = 0
+		cb.probeSuccesses = 0
+	}
+
+	switch cb.state {
+	case metrics.CircuitBreakerClosed:
+		return false, true
+	case metrics.CircuitBreakerHalfOpen:
+		if cb.probes < cb.cfg.HalfOpenRequests {
+			cb.probes++
+			return true, true
+		}
+	}
+	cb.me.RecordAdapterCircuitBreakerRejection(cb.bidderName)
+	return false, false
+}
+
+// record counts the outcome of a request. The outcomes of the requests let through in a former state are ignored.
+func (cb *circuitBreaker) record(probe bool, outcome requestOutcome) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if probe {
+		if cb.state != metrics.CircuitBreakerHalfOpen {
+			return
+		}
+		if outcome != requestSucceeded {
+			cb.open()
+			return
+		}
+		cb.probeSuccesses++
+		if cb.probeSuccesses >= cb.cfg.HalfOpenRequests {
+			cb.buckets = make([]windowBucket, len(cb.buckets))
+			cb.setState(metrics.CircuitBreakerClosed)
+		}
+		return
+	}
+
+	if cb.state != metrics.CircuitBreakerClosed {
+		return
+	}
+	bucket := cb.currentBucket()
+	bucket.requests++
+	switch outcome {
+	case requestFailed:
+		bucket.errors++
+	case requestTimedOut:
+		bucket.timeouts++
+	}
+
+	requests, errors, timeouts := cb.windowCounts()
+	if requests < cb.cfg.MinRequests {
+		return
+	}
+	if float64(errors)/float64(requests) >= cb.cfg.ErrorRateThreshold || float64(timeouts)/float64(requests) >= cb.cfg.TimeoutRateThreshold {
+		cb.open()
+	}
+}
+
+func (cb *circuitBreaker) open() {
+	cb.openedAt = cb.now()
+	cb.setState(metrics.CircuitBreakerOpen)
+}
+
+func (cb *circuitBreaker) setState(state metrics.CircuitBreakerState) {
+	cb.state = state
+	cb.me.RecordAdapterCircuitBreakerState(cb.bidderName, state)
+}
+
+func (cb *circuitBreaker) currentBucket() *windowBucket {
+	index := cb.now().UnixNano() / int64(cb.bucketDuration)
+	bucket := &cb.buckets[index%int64(len(cb.buckets))]
+	if bucket.index != index {
+		*bucket = windowBucket{index: index}
+	}
+	return bucket
+}
+
+// windowCounts sums up the buckets which are still in the window.
+func (cb *circuitBreaker) windowCounts() (requests int, errors int, timeouts int) {
+	index := cb.now().UnixNano() / int64(cb.bucketDuration)
+	for _, bucket := range cb.buckets {
+		if index-bucket.index < int64(len(cb.buckets)) {
+			requests += bucket.requests
+			errors += bucket.errors
+			timeouts += bucket.timeouts
+		}
+	}
+	return
+}
+
+func (cb *circuitBreaker) status() CircuitBreakerStatus {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	status := CircuitBreakerStatus{State: cb.state}
+	status.Requests, status.Errors, status.Timeouts = cb.windowCounts()
+	if cb.state != metrics.CircuitBreakerClosed {
+		openedAt := cb.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
@@ -0,0 +1,262 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
+	metricsConf "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testCircuitBreakerConfig = config.CircuitBreaker{
+	Enabled:              true,
+	WindowMs:             10000,
+	WindowBuckets:        10,
+	MinRequests:          4,
+	ErrorRateThreshold:   0.5,
+	TimeoutRateThreshold: 0.5,
+	OpenDurationMs:       30000,
+	HalfOpenRequests:     2,
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestCircuitBreaker(clock *fakeClock) *circuitBreaker {
+	return newCircuitBreaker(openrtb_ext.BidderAppnexus, testCircuitBreakerConfig, &metricsConf.DummyMetricsEngine{}, clock.Now)
+}
+
+func TestGetRequestOutcome(t *testing.T) {
+	testCases := []struct {
+		description     string
+		errs            []error
+		expectedOutcome requestOutcome
+	}{
+		{
+			description:     "no error",
+			expectedOutcome: requestSucceeded,
+		},
+		{
+			description:     "bad input",
+			errs:            []error{&errortypes.BadInput{Message: "invalid imp"}},
+			expectedOutcome: requestSucceeded,
+		},
+		{
+			description:     "warning",
+			errs:            []error{&errortypes.Warning{Message: "warning"}},
+			expectedOutcome: requestSucceeded,
+		},
+		{
+			description:     "bad server response",
+			errs:            []error{&errortypes.BadServerResponse{Message: "status 500"}},
+			expectedOutcome: requestFailed,
+		},
+		{
+			description:     "connection failure",
+			errs:            []error{&url.Error{Op: "Post", URL: "http://bidder.com", Err: errors.New("connection refused")}},
+			expectedOutcome: requestFailed,
+		},
+		{
+			description:     "timeout",
+			errs:            []error{&errortypes.BadServerResponse{Message: "status 500"}, &errortypes.Timeout{Message: "timeout"}},
+			expectedOutcome: requestTimedOut,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedOutcome, getRequestOutcome(test.errs), test.description)
+	}
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	testCases := []struct {
+		description   string
+		outcomes      []requestOutcome
+		expectedState metrics.CircuitBreakerState
+	}{
+		{
+			description:   "not enough requests",
+			outcomes:      []requestOutcome{requestFailed, requestFailed, requestFailed},
+			expectedState: metrics.CircuitBreakerClosed,
+		},
+		{
+			description:   "error rate below the threshold",
+			outcomes:      []requestOutcome{requestFailed, requestSucceeded, requestTimedOut, requestSucceeded, requestSucceeded},
+			expectedState: metrics.CircuitBreakerClosed,
+		},
+		{
+			description:   "error rate reaching the threshold",
+			outcomes:      []requestOutcome{requestFailed, requestSucceeded, requestFailed, requestSucceeded},
+			expectedState: metrics.CircuitBreakerOpen,
+		},
+		{
+			description:   "timeout rate reaching the threshold",
+			outcomes:      []requestOutcome{requestTimedOut, requestSucceeded, requestTimedOut, requestSucceeded},
+			expectedState: metrics.CircuitBreakerOpen,
+		},
+	}
+
+	for _, test := range testCases {
+		breaker := newTestCircuitBreaker(&fakeClock{now: time.Unix(1000, 0)})
+		for _, outcome := range test.outcomes {
+			probe, allowed := breaker.allow()
+			assert.True(t, allowed, test.description)
+			breaker.record(probe, outcome)
+		}
+		assert.Equal(t, test.expectedState, breaker.status().State, test.description)
+	}
+}
+
+func TestCircuitBreakerSlidingWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	breaker := newTestCircuitBreaker(clock)
+
+	breaker.record(false, requestFailed)
+	breaker.record(false, requestFailed)
+	clock.advance(10 * time.Second)
+	breaker.record(false, requestFailed)
+	breaker.record(false, requestSucceeded)
+	breaker.record(false, requestSucceeded)
+
+	status := breaker.status()
+	assert.Equal(t, metrics.CircuitBreakerClosed, status.State, "the failures out of the window should be ignored")
+	assert.Equal(t, 3, status.Requests)
+	assert.Equal(t, 1, status.Errors)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	testCases := []struct {
+		description   string
+		probeOutcomes []requestOutcome
+		expectedState metrics.CircuitBreakerState
+	}{
+		{
+			description:   "probes succeeding",
+			probeOutcomes: []requestOutcome{requestSucceeded, requestSucceeded},
+			expectedState: metrics.CircuitBreakerClosed,
+		},
+		{
+			description:   "probe failing",
+			probeOutcomes: []requestOutcome{requestSucceeded, requestFailed},
+			expectedState: metrics.CircuitBreakerOpen,
+		},
+		{
+			description:   "probe timing out",
+			probeOutcomes: []requestOutcome{requestTimedOut},
+			expectedState: metrics.CircuitBreakerOpen,
+		},
+		{
+			description:   "probes in flight",
+			probeOutcomes: []requestOutcome{requestSucceeded},
+			expectedState: metrics.CircuitBreakerHalfOpen,
+		},
+	}
+
+	for _, test := range testCases {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		breaker := newTestCircuitBreaker(clock)
+		for i := 0; i < 4; i++ {
+			breaker.record(false, requestFailed)
+		}
+
+		clock.advance(29 * time.Second)
+		_, allowed := breaker.allow()
+		assert.False(t, allowed, "%s: the breaker should be open", test.description)
+
+		clock.advance(time.Second)
+		probe1, allowed1 := breaker.allow()
+		probe2, allowed2 := breaker.allow()
+		_, allowed3 := breaker.allow()
+		assert.True(t, probe1 && allowed1 && probe2 && allowed2, "%s: the probes should be allowed", test.description)
+		assert.False(t, allowed3, "%s: the requests beyond the probes should be skipped", test.description)
+
+		breaker.record(false, requestFailed)
+		for _, outcome := range test.probeOutcomes {
+			breaker.record(true, outcome)
+		}
+
+		status := breaker.status()
+		assert.Equal(t, test.expectedState, status.State, test.description)
+		if test.expectedState == metrics.CircuitBreakerClosed {
+			assert.Zero(t, status.Requests, "%s: the window should be reset", test.description)
+			assert.Nil(t, status.OpenedAt, test.description)
+		} else {
+			assert.NotNil(t, status.OpenedAt, test.description)
+		}
+	}
+}
+
+func TestCircuitBreakerMetrics(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterCircuitBreakerState", openrtb_ext.BidderAppnexus, mock.Anything).Return()
+	metricsMock.On("RecordAdapterCircuitBreakerRejection", openrtb_ext.BidderAppnexus).Return()
+	breaker := newCircuitBreaker(openrtb_ext.BidderAppnexus, testCircuitBreakerConfig, metricsMock, clock.Now)
+
+	for i := 0; i < 4; i++ {
+		breaker.record(false, requestFailed)
+	}
+	breaker.allow()
+	clock.advance(30 * time.Second)
+	probe, _ := breaker.allow()
+	breaker.record(probe, requestSucceeded)
+	probe, _ = breaker.allow()
+	breaker.record(probe, requestSucceeded)
+
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterCircuitBreakerRejection", 1)
+	metricsMock.AssertCalled(t, "RecordAdapterCircuitBreakerState", openrtb_ext.BidderAppnexus, metrics.CircuitBreakerOpen)
+	metricsMock.AssertCalled(t, "RecordAdapterCircuitBreakerState", openrtb_ext.BidderAppnexus, metrics.CircuitBreakerHalfOpen)
+	metricsMock.AssertCalled(t, "RecordAdapterCircuitBreakerState", openrtb_ext.BidderAppnexus, metrics.CircuitBreakerClosed)
+}
+
+func TestCircuitBreakerMiddleware(t *testing.T) {
+	breakers := NewCircuitBreakers(testCircuitBreakerConfig, &metricsConf.DummyMetricsEngine{})
+	failingBidder := &mockAdaptedBidder{
+		errorResponse: []error{&errortypes.BadServerResponse{Message: "status 500"}},
+	}
+	bidder := addCircuitBreakerMiddleware(failingBidder, openrtb_ext.BidderAppnexus, breakers)
+
+	for i := 0; i < 4; i++ {
+		_, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, nil, nil, nil, bidRequestOptions{}, nil)
+		assert.Equal(t, failingBidder.errorResponse, errs, "the bidder should be called while the breaker is closed")
+	}
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, nil, nil, nil, bidRequestOptions{}, nil)
+	assert.Nil(t, seatBid)
+	assert.Equal(t, []error{&errortypes.Warning{
+		WarningCode: errortypes.BidderCircuitOpenWarningCode,
+		Message:     "appnexus was not called because it failed too many recent requests",
+	}}, errs)
+
+	rebuiltBidder := addCircuitBreakerMiddleware(&mockAdaptedBidder{}, openrtb_ext.BidderAppnexus, breakers)
+	_, errs = rebuiltBidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, nil, nil, nil, bidRequestOptions{}, nil)
+	assert.Len(t, errs, 1, "the breaker should be kept when the adapters are rebuilt")
+
+	statuses := breakers.Status()
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, metrics.CircuitBreakerOpen, statuses[openrtb_ext.BidderAppnexus].State)
+	assert.Equal(t, 4, statuses[openrtb_ext.BidderAppnexus].Errors)
+}
+
+func TestCircuitBreakerMiddlewareDisabled(t *testing.T) {
+	bidder := &mockAdaptedBidder{}
+	assert.Equal(t, bidder, addCircuitBreakerMiddleware(bidder, openrtb_ext.BidderAppnexus, nil))
+}
@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// bidderClients holds the HTTP clients of the bidders with their own transport settings. They're kept when the
+// adapters are rebuilt with the same settings, so that a reload of the bidder configuration keeps their connections.
+var bidderClients = struct {
+	sync.Mutex
+	clients map[openrtb_ext.BidderName]bidderClient
+}{
+	clients: make(map[openrtb_ext.BidderName]bidderClient),
+}
+
+type bidderClient struct {
+	base      *http.Client
+	transport config.TransportInfo
+	client    *http.Client
+}
+
+// getBidderClient returns the HTTP client of a bidder. The bidders without transport settings share the base client,
+// while the other ones get a copy of it with their own transport.
+func getBidderClient(base *http.Client, bidderName openrtb_ext.BidderName, transport *config.TransportInfo) *http.Client {
+	if transport == nil || base == nil {
+		return base
+	}
+
+	baseTransport := http.DefaultTransport.(*http.Transport)
+	if base.Transport != nil {
+		var ok bool
+		if baseTransport, ok = base.Transport.(*http.Transport); !ok {
+			glog.Warningf("The transport settings of %s are ignored as the bidders don't use an http.Transport", bidderName)
+			return base
+		}
+	}
+
+	bidderClients.Lock()
+	defer bidderClients.Unlock()
+
+	cached, found := bidderClients.clients[bidderName]
+	if found && cached.base == base && reflect.DeepEqual(cached.transport, *transport) {
+		return cached.client
+	}
+	if found {
+		cached.client.CloseIdleConnections()
+	}
+
+	client := *base
+	client.Transport = newBidderTransport(baseTransport, *transport)
+	bidderClients.clients[bidderName] = bidderClient{
+		base:      base,
+		transport: *transport,
+		client:    &client,
+	}
+	return &client
+}
+
+// newBidderTransport copies the base transport, with the settings of the bidder instead of the ones it sets.
+func newBidderTransport(base *http.Transport, info config.TransportInfo) *http.Transport {
+	transport := base.Clone()
+	if info.MaxIdleConns > 0 {
+		transport.MaxIdleConns = info.MaxIdleConns
+	}
+	if info.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = info.MaxIdleConnsPerHost
+	}
+	if info.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = time.Duration(info.IdleConnTimeout) * time.Second
+	}
+	if info.DialTimeout > 0 {
+		dialer := &net.Dialer{
+			Timeout:   time.Duration(info.DialTimeout) * time.Millisecond,
+			KeepAlive: 30 * time.Second,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+	if info.HTTP2 != nil {
+		transport.ForceAttemptHTTP2 = *info.HTTP2
+		if !*info.HTTP2 {
+			// A non nil empty map disables HTTP/2
+			transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+		}
+	}
+	return transport
+}
@@ -0,0 +1,50 @@
+package exchange
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBidderClient(t *testing.T) {
+	base := &http.Client{
+		Transport: &http.Transport{MaxIdleConns: 10, MaxIdleConnsPerHost: 2, IdleConnTimeout: time.Minute},
+		Timeout:   time.Second,
+	}
+	bidderName := openrtb_ext.BidderName("bidderClientTest")
+
+	assert.Equal(t, base, getBidderClient(base, bidderName, nil), "the bidders without transport settings should share the base client")
+
+	falseValue := false
+	transport := &config.TransportInfo{MaxIdleConns: 50, IdleConnTimeout: 30, HTTP2: &falseValue, DialTimeout: 200}
+	client := getBidderClient(base, bidderName, transport)
+	if assert.NotEqual(t, base, client) {
+		bidderTransport := client.Transport.(*http.Transport)
+		assert.Equal(t, 50, bidderTransport.MaxIdleConns)
+		assert.Equal(t, 2, bidderTransport.MaxIdleConnsPerHost, "the settings left out should be taken from the base transport")
+		assert.Equal(t, 30*time.Second, bidderTransport.IdleConnTimeout)
+		assert.NotNil(t, bidderTransport.DialContext)
+		assert.False(t, bidderTransport.ForceAttemptHTTP2)
+		assert.NotNil(t, bidderTransport.TLSNextProto, "HTTP/2 should be disabled")
+		assert.Equal(t, time.Second, client.Timeout)
+	}
+	assert.Equal(t, 10, base.Transport.(*http.Transport).MaxIdleConns, "the base transport should not be changed")
+
+	sameTransport := *transport
+	assert.True(t, client == getBidderClient(base, bidderName, &sameTransport), "the client should be reused while the settings don't change")
+
+	trueValue := true
+	newClient := getBidderClient(base, bidderName, &config.TransportInfo{HTTP2: &trueValue})
+	assert.False(t, client == newClient, "a new client should be built when the settings change")
+	assert.True(t, newClient.Transport.(*http.Transport).ForceAttemptHTTP2)
+}
+
+func TestGetBidderClientCustomTransport(t *testing.T) {
+	base := &http.Client{Transport: http.NewFileTransport(http.Dir("."))}
+	client := getBidderClient(base, openrtb_ext.BidderName("bidderClientTest"), &config.TransportInfo{MaxIdleConns: 1})
+	assert.Equal(t, base, client, "the settings should be ignored if the base client doesn't use an http.Transport")
+}
@@ -2,6 +2,7 @@ package exchange
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -23,6 +24,7 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/experiment/adscert"
 	"github.com/prebid/prebid-server/metrics"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
@@ -91,10 +93,10 @@ func TestSingleBidder(t *testing.T) {
 		}
 		bidderImpl.bidResponse = mockBidderResponse
 
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, test.debugInfo)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, test.debugInfo, "")
 		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 
-		seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
+		seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false}, nil)
 
 		// Make sure the goodSingleBidder was called with the expected arguments.
 		if bidderImpl.httpResponse == nil {
@@ -165,9 +167,9 @@ func TestRequestBidRemovesSensitiveHeaders(t *testing.T) {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, DebugContextKey, true)
 
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo, "")
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
+	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false}, nil)
 
 	expectedHttpCalls := []*openrtb_ext.ExtHttpCall{
 		{
@@ -206,9 +208,9 @@ func TestSetGPCHeader(t *testing.T) {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, DebugContextKey, true)
 
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo, "")
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{GlobalPrivacyControlHeader: "1"}, true, false)
+	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{GlobalPrivacyControlHeader: "1"}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false}, nil)
 
 	expectedHttpCall := []*openrtb_ext.ExtHttpCall{
 		{
@@ -244,9 +246,9 @@ func TestSetGPCHeaderNil(t *testing.T) {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, DebugContextKey, true)
 
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo, "")
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{GlobalPrivacyControlHeader: "1"}, true, false)
+	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{GlobalPrivacyControlHeader: "1"}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false}, nil)
 
 	expectedHttpCall := []*openrtb_ext.ExtHttpCall{
 		{
@@ -262,6 +264,87 @@ func TestSetGPCHeaderNil(t *testing.T) {
 	assert.ElementsMatch(t, seatBid.httpCalls, expectedHttpCall)
 }
 
+func TestRequestBidAdsCertSignHeader(t *testing.T) {
+	testCases := []struct {
+		description       string
+		addCallSignHeader bool
+		signer            adscert.Signer
+		expectedHeaders   map[string][]string
+		expectedErrs      []error
+		expectedSuccess   []bool
+	}{
+		{
+			description:       "Signing not asked by the request",
+			addCallSignHeader: false,
+			signer:            &mockSigner{signature: "signature"},
+			expectedHeaders:   map[string][]string{"Content-Type": {"application/json"}},
+		},
+		{
+			description:       "Signed",
+			addCallSignHeader: true,
+			signer:            &mockSigner{signature: "signature"},
+			expectedHeaders:   map[string][]string{"Content-Type": {"application/json"}, "X-Ads-Cert-Auth": {"signature"}},
+			expectedSuccess:   []bool{true},
+		},
+		{
+			description:       "Signing failure doesn't block the request",
+			addCallSignHeader: true,
+			signer:            &mockSigner{err: errors.New("signatory unavailable")},
+			expectedHeaders:   map[string][]string{"Content-Type": {"application/json"}},
+			expectedErrs: []error{&errortypes.Warning{
+				WarningCode: errortypes.AdsCertSignerWarningCode,
+				Message:     "AdsCert signer is enabled but cannot sign the request: signatory unavailable",
+			}},
+			expectedSuccess: []bool{false},
+		},
+	}
+
+	for _, test := range testCases {
+		server := httptest.NewServer(mockHandler(200, "getBody", "responseJson"))
+
+		requestHeaders := http.Header{}
+		requestHeaders.Add("Content-Type", "application/json")
+		bidderImpl := &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method:  "POST",
+				Uri:     server.URL,
+				Body:    []byte("requestJson"),
+				Headers: requestHeaders,
+			},
+			bidResponse: &adapters.BidderResponse{
+				Bids: []*adapters.TypedBid{},
+			},
+		}
+
+		metricsMock := &metrics.MetricsEngineMock{}
+		for _, success := range test.expectedSuccess {
+			metricsMock.On("RecordAdsCertReq", success).Return().Once()
+			metricsMock.On("RecordAdsCertSignTime", mock.Anything).Return().Once()
+		}
+
+		cfg := &config.Configuration{Metrics: config.Metrics{Disabled: config.DisabledMetrics{AdapterConnectionMetrics: true}}}
+		bidder := adaptBidder(bidderImpl, server.Client(), cfg, metricsMock, openrtb_ext.BidderAppnexus, &config.DebugInfo{Allow: true}, "")
+		seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, test.signer, bidRequestOptions{headerDebugAllowed: true, addCallSignHeader: test.addCallSignHeader}, nil)
+		server.Close()
+
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+		if assert.Len(t, seatBid.httpCalls, 1, test.description) {
+			assert.Equal(t, test.expectedHeaders, seatBid.httpCalls[0].RequestHeaders, test.description)
+		}
+		assert.Equal(t, map[string][]string{"Content-Type": {"application/json"}}, map[string][]string(requestHeaders), "The adapter headers should not be changed: %s", test.description)
+		metricsMock.AssertExpectations(t)
+	}
+}
+
+type mockSigner struct {
+	signature string
+	err       error
+}
+
+func (ms *mockSigner) Sign(destinationURL string, body []byte) (string, error) {
+	return ms.signature, ms.err
+}
+
 // TestMultiBidder makes sure all the requests get sent, and the responses processed.
 // Because this is done in parallel, it should be run under the race detector.
 func TestMultiBidder(t *testing.T) {
@@ -302,9 +385,9 @@ func TestMultiBidder(t *testing.T) {
 			}},
 		bidResponse: mockBidderResponse,
 	}
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "")
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, true)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: true}, nil)
 
 	if seatBid == nil {
 		t.Fatalf("SeatBid should exist, because bids exist.")
@@ -398,6 +481,85 @@ func TestConnectionClose(t *testing.T) {
 	}
 }
 
+func TestRequestCompression(t *testing.T) {
+	testCases := []struct {
+		description         string
+		endpointCompression string
+		body                []byte
+		expectedEncoding    string
+		expectedMetric      bool
+	}{
+		{
+			description:         "gzip",
+			endpointCompression: "gzip",
+			body:                []byte(`{"id":"req"}`),
+			expectedEncoding:    "gzip",
+			expectedMetric:      true,
+		},
+		{
+			description:         "gzip upper case",
+			endpointCompression: "GZIP",
+			body:                []byte(`{"id":"req"}`),
+			expectedEncoding:    "gzip",
+			expectedMetric:      true,
+		},
+		{
+			description:         "gzip without body",
+			endpointCompression: "gzip",
+		},
+		{
+			description: "no compression",
+			body:        []byte(`{"id":"req"}`),
+		},
+	}
+
+	for _, test := range testCases {
+		var receivedEncoding string
+		var receivedBody []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			receivedEncoding = r.Header.Get("Content-Encoding")
+			receivedBody, _ = ioutil.ReadAll(r.Body)
+			if receivedEncoding == "gzip" {
+				reader, err := gzip.NewReader(bytes.NewReader(receivedBody))
+				if assert.NoError(t, err, test.description) {
+					receivedBody, _ = ioutil.ReadAll(reader)
+				}
+			}
+		}))
+
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordAdapterCompressedRequestSize", openrtb_ext.BidderAppnexus, mock.Anything).Return()
+		bidder := &bidderAdapter{
+			Bidder:     &mixedMultiBidder{},
+			Client:     server.Client(),
+			BidderName: openrtb_ext.BidderAppnexus,
+			me:         metricsMock,
+			config: bidderAdapterConfig{
+				DisableConnMetrics:  true,
+				EndpointCompression: test.endpointCompression,
+			},
+		}
+		requestHeaders := http.Header{"Content-Type": []string{"application/json"}}
+		callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    test.body,
+			Headers: requestHeaders,
+		})
+		server.Close()
+
+		assert.NoError(t, callInfo.err, test.description)
+		assert.Equal(t, test.expectedEncoding, receivedEncoding, test.description)
+		assert.Equal(t, string(test.body), string(receivedBody), test.description)
+		assert.Empty(t, requestHeaders.Get("Content-Encoding"), "%s: the adapter headers should not be changed", test.description)
+		if test.expectedMetric {
+			metricsMock.AssertNumberOfCalls(t, "RecordAdapterCompressedRequestSize", 1)
+		} else {
+			metricsMock.AssertNotCalled(t, "RecordAdapterCompressedRequestSize", mock.Anything, mock.Anything)
+		}
+	}
+}
+
 type bid struct {
 	currency string
 	price    float64
@@ -656,7 +818,7 @@ func TestMultiCurrencies(t *testing.T) {
 		)
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "")
 		currencyConverter := currency.NewRateConverter(
 			&http.Client{},
 			mockedHTTPServer.URL,
@@ -672,8 +834,9 @@ func TestMultiCurrencies(t *testing.T) {
 			1,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
-			true,
-			true,
+			&adscert.NilSigner{},
+			bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: true},
+			nil,
 		)
 
 		// Verify:
@@ -809,7 +972,7 @@ func TestMultiCurrencies_RateConverterNotSet(t *testing.T) {
 		}
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "")
 		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 		seatBid, errs := bidder.requestBid(
 			context.Background(),
@@ -818,8 +981,9 @@ func TestMultiCurrencies_RateConverterNotSet(t *testing.T) {
 			1,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
-			true,
-			true,
+			&adscert.NilSigner{},
+			bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: true},
+			nil,
 		)
 
 		// Verify:
@@ -971,7 +1135,7 @@ func TestMultiCurrencies_RequestCurrencyPick(t *testing.T) {
 		}
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "")
 		currencyConverter := currency.NewRateConverter(
 			&http.Client{},
 			mockedHTTPServer.URL,
@@ -986,8 +1150,9 @@ func TestMultiCurrencies_RequestCurrencyPick(t *testing.T) {
 			1,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
-			true,
-			false,
+			&adscert.NilSigner{},
+			bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false},
+			nil,
 		)
 
 		// Verify:
@@ -1281,7 +1446,7 @@ func TestMobileNativeTypes(t *testing.T) {
 			},
 			bidResponse: tc.mockBidderResponse,
 		}
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "")
 		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 
 		seatBids, _ := bidder.requestBid(
@@ -1291,8 +1456,9 @@ func TestMobileNativeTypes(t *testing.T) {
 			1.0,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
-			true,
-			true,
+			&adscert.NilSigner{},
+			bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: true},
+			nil,
 		)
 
 		var actualValue string
@@ -1304,9 +1470,9 @@ func TestMobileNativeTypes(t *testing.T) {
 }
 
 func TestErrorReporting(t *testing.T) {
-	bidder := adaptBidder(&bidRejector{}, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+	bidder := adaptBidder(&bidRejector{}, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "")
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	bids, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
+	bids, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false}, nil)
 	if bids != nil {
 		t.Errorf("There should be no seatbid if no http requests are returned.")
 	}
@@ -1318,6 +1484,61 @@ func TestErrorReporting(t *testing.T) {
 	}
 }
 
+func TestRequestBidWithStoredResponses(t *testing.T) {
+	server := httptest.NewServer(mockHandler(200, "getBody", `{"seatbid":[{"bid":[{"id":"live-bid","impid":"imp-live","price":1}]}]}`))
+	defer server.Close()
+
+	testCases := []struct {
+		description          string
+		storedResponses      map[string]json.RawMessage
+		expectedRequestedImp []string
+		expectedBidIDs       []string
+	}{
+		{
+			description:          "No stored response",
+			storedResponses:      nil,
+			expectedRequestedImp: []string{"imp-live", "imp-stored"},
+			expectedBidIDs:       []string{"live-bid"},
+		},
+		{
+			description: "Stored response for one imp",
+			storedResponses: map[string]json.RawMessage{
+				"imp-stored": json.RawMessage(`{"seatbid":[{"bid":[{"id":"stored-bid","impid":"imp-stored","price":2}]}]}`),
+			},
+			expectedRequestedImp: []string{"imp-live"},
+			expectedBidIDs:       []string{"live-bid", "stored-bid"},
+		},
+		{
+			description: "Stored responses for all imps",
+			storedResponses: map[string]json.RawMessage{
+				"imp-live":   json.RawMessage(`{"seatbid":[{"bid":[{"id":"stored-bid-1","impid":"imp-live","price":2}]}]}`),
+				"imp-stored": json.RawMessage(`{"seatbid":[{"bid":[{"id":"stored-bid-2","impid":"imp-stored","price":2}]}]}`),
+			},
+			expectedRequestedImp: nil,
+			expectedBidIDs:       []string{"stored-bid-1", "stored-bid-2"},
+		},
+	}
+
+	for _, test := range testCases {
+		bidderImpl := &storedResponsesBidder{uri: server.URL}
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "")
+		request := &openrtb2.BidRequest{
+			Imp: []openrtb2.Imp{{ID: "imp-live"}, {ID: "imp-stored"}},
+		}
+
+		seatBid, errs := bidder.requestBid(context.Background(), request, "test", 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false}, test.storedResponses)
+
+		assert.Empty(t, errs, test.description)
+		assert.Equal(t, test.expectedRequestedImp, bidderImpl.requestedImpIDs, test.description)
+		bidIDs := make([]string, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			bidIDs = append(bidIDs, bid.bid.ID)
+		}
+		assert.ElementsMatch(t, test.expectedBidIDs, bidIDs, test.description)
+		assert.Len(t, request.Imp, 2, test.description+": the request imps should be left untouched")
+	}
+}
+
 func TestSetAssetTypes(t *testing.T) {
 	testCases := []struct {
 		respAsset   nativeResponse.Asset
@@ -1525,9 +1746,9 @@ func TestCallRecordAdapterConnections(t *testing.T) {
 	metrics.On("RecordAdapterConnections", expectedAdapterName, false, mock.MatchedBy(compareConnWaitTime)).Once()
 
 	// Run requestBid using an http.Client with a mock handler
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, metrics, openrtb_ext.BidderAppnexus, nil)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, metrics, openrtb_ext.BidderAppnexus, nil, "")
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	_, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, true)
+	_, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: true}, nil)
 
 	// Assert no errors
 	assert.Equal(t, 0, len(errs), "bidder.requestBid returned errors %v \n", errs)
@@ -1821,3 +2042,31 @@ func (bidder *notifyingBidder) MakeBids(internalRequest *openrtb2.BidRequest, ex
 func (bidder *notifyingBidder) MakeTimeoutNotification(req *adapters.RequestData) (*adapters.RequestData, []error) {
 	return &bidder.notifyRequest, nil
 }
+
+// storedResponsesBidder calls its uri with the imp IDs of the request and parses the responses as OpenRTB bid responses
+type storedResponsesBidder struct {
+	uri             string
+	requestedImpIDs []string
+}
+
+func (bidder *storedResponsesBidder) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	for _, imp := range request.Imp {
+		bidder.requestedImpIDs = append(bidder.requestedImpIDs, imp.ID)
+	}
+	return []*adapters.RequestData{{Method: "POST", Uri: bidder.uri}}, nil
+}
+
+func (bidder *storedResponsesBidder) MakeBids(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	var bidResponse openrtb2.BidResponse
+	if err := json.Unmarshal(response.Body, &bidResponse); err != nil {
+		return nil, []error{err}
+	}
+
+	bidderResponse := adapters.NewBidderResponse()
+	for _, seatBid := range bidResponse.SeatBid {
+		for i := range seatBid.Bid {
+			bidderResponse.Bids = append(bidderResponse.Bids, &adapters.TypedBid{Bid: &seatBid.Bid[i], BidType: openrtb_ext.BidTypeBanner})
+		}
+	}
+	return bidderResponse, nil
+}
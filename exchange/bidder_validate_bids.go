@@ -2,13 +2,19 @@ package exchange
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/experiment/adscert"
+	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	goCurrency "golang.org/x/text/currency"
 )
@@ -28,8 +34,8 @@ type validatedBidder struct {
 	bidder adaptedBidder
 }
 
-func (v *validatedBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
-	seatBid, errs := v.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo, accountDebugAllowed, headerDebugAllowed)
+func (v *validatedBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error) {
+	seatBid, errs := v.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo, adsCertSigner, bidRequestOptions, bidderStoredResponses)
 	if validationErrors := removeInvalidBids(request, seatBid); len(validationErrors) > 0 {
 		errs = append(errs, validationErrors...)
 	}
@@ -122,3 +128,108 @@ func validateBid(bid *pbsOrtbBid) (bool, error) {
 
 	return true, nil
 }
+
+// applyBidValidations runs the account validations of the bids against the imps of the bidder request. Depending on
+// the validation mode, the bids failing a validation are either removed with an error or kept with a warning.
+func applyBidValidations(request *openrtb2.BidRequest, seatBid *pbsOrtbSeatBid, bidderName openrtb_ext.BidderName, validations config.AccountValidations, me metrics.MetricsEngine) []error {
+	if seatBid == nil || len(seatBid.bids) == 0 {
+		return nil
+	}
+	if !validations.BannerCreativeSize.IsEnabled() && !validations.SecureMarkup.IsEnabled() {
+		return nil
+	}
+
+	impsByID := make(map[string]*openrtb2.Imp, len(request.Imp))
+	for i := range request.Imp {
+		impsByID[request.Imp[i].ID] = &request.Imp[i]
+	}
+
+	var errs []error
+	validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+	for _, bid := range seatBid.bids {
+		imp, found := impsByID[bid.bid.ImpID]
+		if !found {
+			validBids = append(validBids, bid)
+			continue
+		}
+
+		rejected := false
+		if validations.BannerCreativeSize.IsEnabled() && bid.bidType == openrtb_ext.BidTypeBanner && !hasRequestedSize(imp.Banner, bid.bid) {
+			message := fmt.Sprintf("Bid \"%s\" size %dx%d does not match any format requested by imp \"%s\"", bid.bid.ID, bid.bid.W, bid.bid.H, imp.ID)
+			errs = append(errs, bidValidationError(message, validations.BannerCreativeSize))
+			rejected = rejected || validations.BannerCreativeSize == config.ValidationEnforce
+			me.RecordAdapterBidValidation(bidderName, metrics.BidValidationCreativeSize, validations.BannerCreativeSize == config.ValidationEnforce)
+		}
+		if validations.SecureMarkup.IsEnabled() && imp.Secure != nil && *imp.Secure == 1 && isInsecureMarkup(bid.bid.AdM) {
+			message := fmt.Sprintf("Bid \"%s\" has insecure markup but imp \"%s\" requires secure creatives", bid.bid.ID, imp.ID)
+			errs = append(errs, bidValidationError(message, validations.SecureMarkup))
+			rejected = rejected || validations.SecureMarkup == config.ValidationEnforce
+			me.RecordAdapterBidValidation(bidderName, metrics.BidValidationSecureMarkup, validations.SecureMarkup == config.ValidationEnforce)
+		}
+
+		if !rejected {
+			validBids = append(validBids, bid)
+		}
+	}
+	seatBid.bids = validBids
+	return errs
+}
+
+// bidValidationError reports a failed validation as an error if the mode enforces it, or as a warning otherwise.
+func bidValidationError(message string, mode config.ValidationMode) error {
+	if mode == config.ValidationEnforce {
+		return &errortypes.BadServerResponse{Message: message}
+	}
+	return &errortypes.Warning{WarningCode: errortypes.BidValidationWarningCode, Message: message}
+}
+
+// hasRequestedSize returns true if the size of the bid matches one of the formats of the banner. The banner size
+// stands for the format when it has none, and any size is accepted when it has neither.
+func hasRequestedSize(banner *openrtb2.Banner, bid *openrtb2.Bid) bool {
+	if banner == nil {
+		return true
+	}
+
+	if len(banner.Format) == 0 {
+		if banner.W == nil || banner.H == nil {
+			return true
+		}
+		return bid.W == *banner.W && bid.H == *banner.H
+	}
+
+	for _, format := range banner.Format {
+		if bid.W == format.W && bid.H == format.H {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// urlAttributePattern matches the values of the HTML attributes which load or link to a URL
+	urlAttributePattern = regexp.MustCompile(`(?i)\b(?:src|srcset|href)\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+	// vastURLElementPattern matches the content of the VAST elements which hold a URL, with or without CDATA
+	vastURLElementPattern = regexp.MustCompile(`(?i)<(?:MediaFile|Mezzanine|InteractiveCreativeFile|ClosedCaptionFile|` +
+		`Impression|Error|Tracking|ClickThrough|ClickTracking|CustomClick|VASTAdTagURI|` +
+		`StaticResource|IFrameResource|JavaScriptResource|ExecutableResource|` +
+		`IconClickThrough|IconClickTracking|IconViewTracking|CompanionClickThrough|CompanionClickTracking|` +
+		`NonLinearClickThrough|NonLinearClickTracking|Viewable|NotViewable|ViewUndetermined)\b[^>]*>\s*(?:<!\[CDATA\[)?([^<]*)`)
+)
+
+// isInsecureMarkup returns true if the markup loads content over http, either directly or URL encoded. Only the
+// URLs of the HTML attributes and of the VAST elements are checked, so that XML namespaces don't count.
+func isInsecureMarkup(adm string) bool {
+	for _, pattern := range []*regexp.Regexp{urlAttributePattern, vastURLElementPattern} {
+		for _, match := range pattern.FindAllStringSubmatch(adm, -1) {
+			if isInsecureURL(match[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isInsecureURL(url string) bool {
+	url = strings.ToLower(url)
+	return strings.Contains(url, "http:") || strings.Contains(url, "http%3a")
+}
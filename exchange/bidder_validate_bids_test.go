@@ -2,11 +2,16 @@ package exchange
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/experiment/adscert"
+	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/stretchr/testify/assert"
 )
@@ -51,7 +56,7 @@ func TestAllValidBids(t *testing.T) {
 			},
 		},
 	})
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false}, nil)
 	assert.Len(t, seatBid.bids, 4)
 	assert.Len(t, errs, 0)
 }
@@ -109,7 +114,7 @@ func TestAllBadBids(t *testing.T) {
 			},
 		},
 	})
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false}, nil)
 	assert.Len(t, seatBid.bids, 0)
 	assert.Len(t, errs, 7)
 }
@@ -178,7 +183,7 @@ func TestMixedBids(t *testing.T) {
 			},
 		},
 	})
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false}, nil)
 	assert.Len(t, seatBid.bids, 3)
 	assert.Len(t, errs, 5)
 }
@@ -298,7 +303,7 @@ func TestCurrencyBids(t *testing.T) {
 			Cur: tc.brqCur,
 		}
 
-		seatBid, errs := bidder.requestBid(context.Background(), request, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false)
+		seatBid, errs := bidder.requestBid(context.Background(), request, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{accountDebugAllowed: true, headerDebugAllowed: false}, nil)
 		assert.Len(t, seatBid.bids, expectedValidBids)
 		assert.Len(t, errs, expectedErrs)
 	}
@@ -309,6 +314,209 @@ type mockAdaptedBidder struct {
 	errorResponse []error
 }
 
-func (b *mockAdaptedBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
+func (b *mockAdaptedBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error) {
 	return b.bidResponse, b.errorResponse
 }
+
+func TestApplyBidValidations(t *testing.T) {
+	secure := int8(1)
+	request := &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{
+				ID:     "bannerImp",
+				Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}, {W: 300, H: 600}}},
+			},
+			{
+				ID:     "secureImp",
+				Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+				Secure: &secure,
+			},
+		},
+	}
+
+	testCases := []struct {
+		description        string
+		validations        config.AccountValidations
+		bid                *openrtb2.Bid
+		bidType            openrtb_ext.BidType
+		expectBid          bool
+		expectErr          error
+		expectedValidation metrics.BidValidation
+	}{
+		{
+			description: "Creative size - Skip",
+			validations: config.AccountValidations{BannerCreativeSize: config.ValidationSkip},
+			bid:         &openrtb2.Bid{ID: "bid", ImpID: "bannerImp", W: 728, H: 90},
+			bidType:     openrtb_ext.BidTypeBanner,
+			expectBid:   true,
+		},
+		{
+			description: "Creative size - Matching format",
+			validations: config.AccountValidations{BannerCreativeSize: config.ValidationEnforce},
+			bid:         &openrtb2.Bid{ID: "bid", ImpID: "bannerImp", W: 300, H: 600},
+			bidType:     openrtb_ext.BidTypeBanner,
+			expectBid:   true,
+		},
+		{
+			description: "Creative size - Not a banner",
+			validations: config.AccountValidations{BannerCreativeSize: config.ValidationEnforce},
+			bid:         &openrtb2.Bid{ID: "bid", ImpID: "bannerImp", W: 728, H: 90},
+			bidType:     openrtb_ext.BidTypeVideo,
+			expectBid:   true,
+		},
+		{
+			description:        "Creative size - Warn",
+			validations:        config.AccountValidations{BannerCreativeSize: config.ValidationWarn},
+			bid:                &openrtb2.Bid{ID: "bid", ImpID: "bannerImp", W: 728, H: 90},
+			bidType:            openrtb_ext.BidTypeBanner,
+			expectBid:          true,
+			expectErr:          &errortypes.Warning{WarningCode: errortypes.BidValidationWarningCode, Message: `Bid "bid" size 728x90 does not match any format requested by imp "bannerImp"`},
+			expectedValidation: metrics.BidValidationCreativeSize,
+		},
+		{
+			description:        "Creative size - Enforce",
+			validations:        config.AccountValidations{BannerCreativeSize: config.ValidationEnforce},
+			bid:                &openrtb2.Bid{ID: "bid", ImpID: "bannerImp", W: 728, H: 90},
+			bidType:            openrtb_ext.BidTypeBanner,
+			expectBid:          false,
+			expectErr:          &errortypes.BadServerResponse{Message: `Bid "bid" size 728x90 does not match any format requested by imp "bannerImp"`},
+			expectedValidation: metrics.BidValidationCreativeSize,
+		},
+		{
+			description: "Secure markup - Secure creative",
+			validations: config.AccountValidations{SecureMarkup: config.ValidationEnforce},
+			bid:         &openrtb2.Bid{ID: "bid", ImpID: "secureImp", AdM: `<img src="https://domain.com/creative.png">`},
+			bidType:     openrtb_ext.BidTypeBanner,
+			expectBid:   true,
+		},
+		{
+			description: "Secure markup - Imp not secure",
+			validations: config.AccountValidations{SecureMarkup: config.ValidationEnforce},
+			bid:         &openrtb2.Bid{ID: "bid", ImpID: "bannerImp", AdM: `<img src="http://domain.com/creative.png">`},
+			bidType:     openrtb_ext.BidTypeBanner,
+			expectBid:   true,
+		},
+		{
+			description:        "Secure markup - Warn",
+			validations:        config.AccountValidations{SecureMarkup: config.ValidationWarn},
+			bid:                &openrtb2.Bid{ID: "bid", ImpID: "secureImp", AdM: `<img src="http://domain.com/creative.png">`},
+			bidType:            openrtb_ext.BidTypeBanner,
+			expectBid:          true,
+			expectErr:          &errortypes.Warning{WarningCode: errortypes.BidValidationWarningCode, Message: `Bid "bid" has insecure markup but imp "secureImp" requires secure creatives`},
+			expectedValidation: metrics.BidValidationSecureMarkup,
+		},
+		{
+			description: "Secure markup - XML namespace",
+			validations: config.AccountValidations{SecureMarkup: config.ValidationEnforce},
+			bid:         &openrtb2.Bid{ID: "bid", ImpID: "secureImp", AdM: `<svg xmlns="http://www.w3.org/2000/svg"><image href="https://domain.com/creative.png"/></svg>`},
+			bidType:     openrtb_ext.BidTypeBanner,
+			expectBid:   true,
+		},
+		{
+			description:        "Secure markup - Enforce URL encoded",
+			validations:        config.AccountValidations{SecureMarkup: config.ValidationEnforce},
+			bid:                &openrtb2.Bid{ID: "bid", ImpID: "secureImp", AdM: `<img src="https://domain.com/pixel?redirect=http%3A%2F%2Fdomain.com">`},
+			bidType:            openrtb_ext.BidTypeBanner,
+			expectBid:          false,
+			expectErr:          &errortypes.BadServerResponse{Message: `Bid "bid" has insecure markup but imp "secureImp" requires secure creatives`},
+			expectedValidation: metrics.BidValidationSecureMarkup,
+		},
+	}
+
+	for _, test := range testCases {
+		seatBid := &pbsOrtbSeatBid{bids: []*pbsOrtbBid{{bid: test.bid, bidType: test.bidType}}}
+
+		metricsMock := &metrics.MetricsEngineMock{}
+		if test.expectedValidation != "" {
+			metricsMock.On("RecordAdapterBidValidation", openrtb_ext.BidderAppnexus, test.expectedValidation, !test.expectBid).Once()
+		}
+
+		errs := applyBidValidations(request, seatBid, openrtb_ext.BidderAppnexus, test.validations, metricsMock)
+
+		if test.expectErr != nil {
+			assert.Equal(t, []error{test.expectErr}, errs, test.description+":errs")
+		} else {
+			assert.Empty(t, errs, test.description+":errs")
+		}
+		if test.expectBid {
+			assert.Len(t, seatBid.bids, 1, test.description+":bids")
+		} else {
+			assert.Empty(t, seatBid.bids, test.description+":bids")
+		}
+		metricsMock.AssertExpectations(t)
+	}
+}
+
+func TestIsInsecureMarkup(t *testing.T) {
+	testCases := []struct {
+		description string
+		adm         string
+		expected    bool
+	}{
+		{
+			description: "Secure image",
+			adm:         `<img src="https://domain.com/creative.png">`,
+			expected:    false,
+		},
+		{
+			description: "Insecure image",
+			adm:         `<img src="http://domain.com/creative.png">`,
+			expected:    true,
+		},
+		{
+			description: "Insecure link in single quotes",
+			adm:         `<a href='HTTP://domain.com/landing'>ad</a>`,
+			expected:    true,
+		},
+		{
+			description: "Insecure URL encoded redirect",
+			adm:         `<img src="https://domain.com/pixel?redirect=http%3A%2F%2Fdomain.com">`,
+			expected:    true,
+		},
+		{
+			description: "SVG namespace",
+			adm:         `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><image xlink:href="https://domain.com/creative.png"/></svg>`,
+			expected:    false,
+		},
+		{
+			description: "Insecure SVG image",
+			adm:         `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><image xlink:href="http://domain.com/creative.png"/></svg>`,
+			expected:    true,
+		},
+		{
+			description: "Text which is not a URL attribute",
+			adm:         `<div title="see http://domain.com">ad</div>`,
+			expected:    false,
+		},
+		{
+			description: "VAST namespace",
+			adm: `<VAST version="3.0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:noNamespaceSchemaLocation="vast.xsd"><Ad><InLine>` +
+				`<Impression><![CDATA[https://domain.com/imp]]></Impression><Creatives><Creative><Linear>` +
+				`<TrackingEvents><Tracking event="start"><![CDATA[https://domain.com/start]]></Tracking></TrackingEvents>` +
+				`<MediaFiles><MediaFile delivery="progressive" type="video/mp4">https://domain.com/video.mp4</MediaFile></MediaFiles>` +
+				`</Linear></Creative></Creatives></InLine></Ad></VAST>`,
+			expected: false,
+		},
+		{
+			description: "Insecure VAST media file",
+			adm: `<VAST version="3.0"><Ad><InLine><Creatives><Creative><Linear><MediaFiles>` +
+				`<MediaFile delivery="progressive" type="video/mp4"><![CDATA[ http://domain.com/video.mp4 ]]></MediaFile>` +
+				`</MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`,
+			expected: true,
+		},
+		{
+			description: "Insecure VAST tracking",
+			adm:         `<VAST version="3.0"><Ad><InLine><Creatives><Creative><Linear><TrackingEvents><Tracking event="start">http://domain.com/start</Tracking></TrackingEvents></Linear></Creative></Creatives></InLine></Ad></VAST>`,
+			expected:    true,
+		},
+		{
+			description: "Insecure VAST wrapper",
+			adm:         `<VAST version="3.0"><Ad><Wrapper><VASTAdTagURI><![CDATA[http://domain.com/vast.xml]]></VASTAdTagURI></Wrapper></Ad></VAST>`,
+			expected:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, isInsecureMarkup(test.adm), test.description)
+	}
+}
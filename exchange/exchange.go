@@ -15,20 +15,27 @@ import (
 	"time"
 
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/bidadjustment"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/experiment/adscert"
+	"github.com/prebid/prebid-server/floors"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/usersync"
 
 	"github.com/buger/jsonparser"
 	"github.com/gofrs/uuid"
 	"github.com/golang/glog"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ContextKey string
@@ -65,6 +72,9 @@ type exchange struct {
 	privacyConfig     config.Privacy
 	categoriesFetcher stored_requests.CategoryFetcher
 	bidIDGenerator    BidIDGenerator
+	priceFloorEnabled bool
+	priceFloorFetcher floors.FloorFetcher
+	adsCertSigner     adscert.Signer
 }
 
 // Container to pass out response ext data from the GetAllBids goroutines back into the main thread
@@ -111,7 +121,7 @@ func (randomDeduplicateBidBooleanGenerator) Generate() bool {
 	return rand.Intn(100) < 50
 }
 
-func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, syncersByBidder map[string]usersync.Syncer, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gDPR gdpr.Permissions, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher) Exchange {
+func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, syncersByBidder map[string]usersync.Syncer, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gDPR gdpr.Permissions, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher, priceFloorFetcher floors.FloorFetcher) Exchange {
 	bidderToSyncerKey := map[string]string{}
 	for bidder, syncer := range syncersByBidder {
 		bidderToSyncerKey[bidder] = syncer.Key()
@@ -139,7 +149,10 @@ func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid
 			GDPR: cfg.GDPR,
 			LMT:  cfg.LMT,
 		},
-		bidIDGenerator: &bidIDGenerator{cfg.GenerateBidID},
+		bidIDGenerator:    &bidIDGenerator{cfg.GenerateBidID},
+		priceFloorEnabled: cfg.PriceFloors.Enabled,
+		priceFloorFetcher: priceFloorFetcher,
+		adsCertSigner:     adscert.NewAdCertsSigner(cfg.Experiment.AdCerts),
 	}
 }
 
@@ -159,6 +172,12 @@ type AuctionRequest struct {
 	Warnings                   []error
 	GlobalPrivacyControlHeader string
 	ImpExtInfoMap              map[string]ImpExtInfo
+	// StoredAuctionResponses maps the imp IDs to the seat bids replayed in place of the bidder calls
+	StoredAuctionResponses map[string]json.RawMessage
+	// StoredBidResponses maps the imp IDs to the stored responses of each bidder, parsed by the bidder in place of its response
+	StoredBidResponses map[string]map[string]json.RawMessage
+	// HookExecutor runs the module hooks of the bidder and response stages. No hook is run if it's nil.
+	HookExecutor hookexecution.StageExecutor
 
 	// LegacyLabels is included here for temporary compatability with cleanOpenRTBRequests
 	// in HoldAuction until we get to factoring it away. Do not use for anything new.
@@ -172,6 +191,8 @@ type BidderRequest struct {
 	BidderName     openrtb_ext.BidderName
 	BidderCoreName openrtb_ext.BidderName
 	BidderLabels   metrics.AdapterLabels
+	// BidderStoredResponses maps the imp IDs to the stored responses which replace the bidder calls for them
+	BidderStoredResponses map[string]json.RawMessage
 }
 
 func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *DebugLog) (*openrtb2.BidResponse, error) {
@@ -181,6 +202,10 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 		return nil, err
 	}
 
+	if r.HookExecutor == nil {
+		r.HookExecutor = hookexecution.EmptyHookExecutor{}
+	}
+
 	cacheInstructions := getExtCacheInstructions(requestExt)
 	targData := getExtTargetData(requestExt, &cacheInstructions)
 	if targData != nil {
@@ -201,14 +226,31 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	}
 
 	bidAdjustmentFactors := getExtBidAdjustmentFactors(requestExt)
+	bidAdjustments := bidadjustment.Merge(requestExt.Prebid.BidAdjustments, r.Account.BidAdjustments)
 
 	recordImpMetrics(r.BidRequest, e.me)
 
+	// Get currency rates conversions for the auction
+	_, currencySpan := tracing.StartSpan(ctx, "currency.conversions", trace.SpanKindInternal)
+	conversions := e.getAuctionCurrencyRates(requestExt.Prebid.CurrencyConversions)
+	currencySpan.End()
+
+	// Resolve the price floors of each imp before the request is split up among the bidders
+	var floorEnforcement floors.Enforcement
+	if e.priceFloorEnabled {
+		floorErrs := floors.EnrichWithPriceFloors(r.BidRequest, requestExt, r.Account, conversions, e.priceFloorFetcher)
+		r.Warnings = append(r.Warnings, floorErrs...)
+		floorEnforcement = floors.GetEnforcement(requestExt.Prebid.Floors, r.Account.PriceFloors)
+	}
+
 	// Make our best guess if GDPR applies
 	gdprDefaultValue := e.parseGDPRDefaultValue(r.BidRequest)
 
 	// Slice of BidRequests, each a copy of the original cleaned to only contain bidder data for the named bidder
-	bidderRequests, privacyLabels, errs := cleanOpenRTBRequests(ctx, r, requestExt, e.bidderToSyncerKey, e.gDPR, e.me, gdprDefaultValue, e.privacyConfig, &r.Account)
+	privacyCtx, privacySpan := tracing.StartSpan(ctx, "privacy_enforcement", trace.SpanKindInternal)
+	bidderRequests, privacyLabels, errs := cleanOpenRTBRequests(privacyCtx, r, requestExt, e.bidderToSyncerKey, e.gDPR, e.me, gdprDefaultValue, e.privacyConfig, &r.Account)
+	privacySpan.SetAttributes(attribute.Bool("gdpr.enforced", privacyLabels.GDPREnforced), attribute.Bool("ccpa.enforced", privacyLabels.CCPAEnforced), attribute.Bool("coppa.enforced", privacyLabels.COPPAEnforced))
+	privacySpan.End()
 
 	e.me.RecordRequestPrivacy(privacyLabels)
 
@@ -220,10 +262,27 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	auctionCtx, cancel := e.makeAuctionContext(ctx, cacheInstructions.cacheBids)
 	defer cancel()
 
-	// Get currency rates conversions for the auction
-	conversions := e.getAuctionCurrencyRates(requestExt.Prebid.CurrencyConversions)
-
-	adapterBids, adapterExtra, anyBidsReturned := e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, conversions, r.Account.DebugAllow, r.GlobalPrivacyControlHeader, debugLog.DebugOverride)
+	var adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid
+	var adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra
+	var anyBidsReturned bool
+	if len(r.StoredAuctionResponses) > 0 {
+		// The stored auction responses replace the bids of every bidder
+		adapterBids, adapterExtra, liveAdapters, err = buildStoredAuctionResponse(r.BidRequest, r.StoredAuctionResponses)
+		if err != nil {
+			return nil, err
+		}
+		anyBidsReturned = len(adapterBids) > 0
+	} else {
+		bidRequestOptions := bidRequestOptions{
+			accountDebugAllowed: r.Account.DebugAllow,
+			headerDebugAllowed:  debugLog.DebugOverride,
+			addCallSignHeader:   isAdsCertEnabled(requestExt.Prebid.Experiment),
+		}
+		adapterBids, adapterExtra, anyBidsReturned = e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, bidAdjustments, conversions, bidRequestOptions, r.GlobalPrivacyControlHeader, floorEnforcement, r.Account.Validations, r.HookExecutor)
+	}
+	if anyBidsReturned {
+		anyBidsReturned = executeAllProcessedBidResponsesStage(adapterBids, r.HookExecutor)
+	}
 
 	var auc *auction
 	var cacheErrs []error
@@ -260,6 +319,7 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 		if targData != nil {
 			// A non-nil auction is only needed if targeting is active. (It is used below this block to extract cache keys)
 			auc = newAuction(adapterBids, len(r.BidRequest.Imp), targData.preferDeals)
+			auc.applyMultiBid(targData)
 			auc.setRoundedPrices(targData.priceGranularity)
 
 			if requestExt.Prebid.SupportDeals {
@@ -317,7 +377,15 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	}
 
 	// Build the response
-	return e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequest, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, r.ImpExtInfoMap, errs)
+	bidResponse, err := e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequest, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, r.ImpExtInfoMap, errs)
+	if err != nil {
+		return bidResponse, err
+	}
+
+	bidResponse = r.HookExecutor.ExecuteAuctionResponseStage(bidResponse)
+	bidResponse.Ext, err = hookexecution.EnrichExtBidResponse(bidResponse.Ext, r.HookExecutor.GetOutcomes(), r.BidRequest, &r.Account)
+
+	return bidResponse, err
 }
 
 func (e *exchange) parseGDPRDefaultValue(bidRequest *openrtb2.BidRequest) gdpr.Signal {
@@ -360,14 +428,16 @@ func applyDealSupport(bidRequest *openrtb2.BidRequest, auc *auction, bidCategory
 	errs := []error{}
 	impDealMap := getDealTiers(bidRequest)
 
-	for impID, topBidsPerImp := range auc.winningBidsByBidder {
+	for impID, bidsPerBidder := range auc.allBidsByBidder {
 		impDeal := impDealMap[impID]
-		for bidder, topBidPerBidder := range topBidsPerImp {
-			if topBidPerBidder.dealPriority > 0 {
-				if validateDealTier(impDeal[bidder]) {
-					updateHbPbCatDur(topBidPerBidder, impDeal[bidder], bidCategory)
-				} else {
-					errs = append(errs, fmt.Errorf("dealTier configuration invalid for bidder '%s', imp ID '%s'", string(bidder), impID))
+		for bidder, bids := range bidsPerBidder {
+			for _, bid := range bids {
+				if bid.dealPriority > 0 {
+					if validateDealTier(impDeal[bidder]) {
+						updateHbPbCatDur(bid, impDeal[bidder], bidCategory)
+					} else {
+						errs = append(errs, fmt.Errorf("dealTier configuration invalid for bidder '%s', imp ID '%s'", string(bidder), impID))
+					}
 				}
 			}
 		}
@@ -430,11 +500,14 @@ func (e *exchange) makeAuctionContext(ctx context.Context, needsCache bool) (auc
 func (e *exchange) getAllBids(
 	ctx context.Context,
 	bidderRequests []BidderRequest,
-	bidAdjustments map[string]float64,
+	bidAdjustmentFactors map[string]float64,
+	bidAdjustments *openrtb_ext.ExtRequestPrebidBidAdjustments,
 	conversions currency.Conversions,
-	accountDebugAllowed bool,
+	bidRequestOptions bidRequestOptions,
 	globalPrivacyControlHeader string,
-	headerDebugAllowed bool) (
+	floorEnforcement floors.Enforcement,
+	bidValidations config.AccountValidations,
+	hookExecutor hookexecution.StageExecutor) (
 	map[openrtb_ext.BidderName]*pbsOrtbSeatBid,
 	map[openrtb_ext.BidderName]*seatResponseExtra, bool) {
 	// Set up pointers to the bid results
@@ -460,14 +533,29 @@ func (e *exchange) getAllBids(
 			start := time.Now()
 
 			adjustmentFactor := 1.0
-			if givenAdjustment, ok := bidAdjustments[string(bidderRequest.BidderName)]; ok {
+			if givenAdjustment, ok := bidAdjustmentFactors[string(bidderRequest.BidderName)]; ok {
 				adjustmentFactor = givenAdjustment
 			}
 			reqInfo := adapters.NewExtraRequestInfo(conversions)
 			reqInfo.PbsEntryPoint = bidderRequest.BidderLabels.RType
 			reqInfo.GlobalPrivacyControlHeader = globalPrivacyControlHeader
 
-			bids, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest.BidRequest, bidderRequest.BidderName, adjustmentFactor, conversions, &reqInfo, accountDebugAllowed, headerDebugAllowed)
+			var bids *pbsOrtbSeatBid
+			var err []error
+			if bidRequest, rejectErr := hookExecutor.ExecuteBidderRequestStage(bidderRequest.BidRequest, string(bidderRequest.BidderName)); rejectErr != nil {
+				err = []error{rejectErr}
+			} else {
+				bidderRequest.BidRequest = bidRequest
+				bids, err = e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest.BidRequest, bidderRequest.BidderName, adjustmentFactor, conversions, &reqInfo, e.adsCertSigner, bidRequestOptions, bidderRequest.BidderStoredResponses)
+				if rejectErr := executeRawBidderResponseStage(bids, bidderRequest.BidderName, hookExecutor); rejectErr != nil {
+					err = append(err, rejectErr)
+				}
+			}
+			err = append(err, applyBidAdjustments(bids, bidderRequest.BidderName, bidAdjustments, conversions)...)
+			if floorEnforcement.Enabled {
+				err = append(err, enforceFloorToBids(bidderRequest.BidRequest, bids, conversions, floorEnforcement)...)
+			}
+			err = append(err, applyBidValidations(bidderRequest.BidRequest, bids, bidderRequest.BidderName, bidValidations, e.me)...)
 
 			// Add in time reporting
 			elapsed := time.Since(start)
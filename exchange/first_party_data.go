@@ -0,0 +1,209 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// firstPartyData holds the first party data options of a request, which decide the first party data each
+// bidder receives.
+type firstPartyData struct {
+	// dataBidders restricts the global first party data to the bidders listed in ext.prebid.data.bidders.
+	// All the bidders receive it when nil.
+	dataBidders map[string]struct{}
+	// bidderConfigs maps the bidders to the ortb2 objects of ext.prebid.bidderconfig merged into their requests.
+	bidderConfigs map[string]*openrtb_ext.ORTB2
+}
+
+func newFirstPartyData(requestExt *openrtb_ext.ExtRequest) firstPartyData {
+	var fpd firstPartyData
+	if requestExt == nil {
+		return fpd
+	}
+
+	if requestExt.Prebid.Data != nil && len(requestExt.Prebid.Data.Bidders) > 0 {
+		fpd.dataBidders = make(map[string]struct{}, len(requestExt.Prebid.Data.Bidders))
+		for _, bidder := range requestExt.Prebid.Data.Bidders {
+			fpd.dataBidders[bidder] = struct{}{}
+		}
+	}
+
+	for _, bidderConfig := range requestExt.Prebid.BidderConfigs {
+		if bidderConfig.Config == nil || bidderConfig.Config.ORTB2 == nil {
+			continue
+		}
+		if fpd.bidderConfigs == nil {
+			fpd.bidderConfigs = make(map[string]*openrtb_ext.ORTB2)
+		}
+		for _, bidder := range bidderConfig.Bidders {
+			fpd.bidderConfigs[bidder] = bidderConfig.Config.ORTB2
+		}
+	}
+	return fpd
+}
+
+// apply prepares the first party data of the bidder's copy of the request. The global first party data is
+// removed if the bidder isn't allowed to receive it, then the bidder config is merged into the site, app and
+// user objects. This *will* mutate the request, but will *not* mutate any objects nested inside it.
+//
+// The bidder config which cannot be merged is left out and reported as a warning.
+func (fpd firstPartyData) apply(req *openrtb2.BidRequest, bidder string) []error {
+	var errs []error
+
+	if !fpd.allowsGlobalData(bidder) {
+		if err := removeGlobalFirstPartyData(req); err != nil {
+			errs = append(errs, newFirstPartyDataWarning(fmt.Sprintf("unable to remove first party data for bidder %s: %v", bidder, err)))
+		}
+	}
+
+	ortb2, ok := fpd.bidderConfigs[bidder]
+	if !ok {
+		return errs
+	}
+
+	if len(ortb2.Site) > 0 {
+		if req.App != nil {
+			errs = append(errs, newFirstPartyDataWarning(fmt.Sprintf("request.ext.prebid.bidderconfig site not applied for bidder %s: the request defines app", bidder)))
+		} else {
+			site := &openrtb2.Site{}
+			if err := mergeFirstPartyData(req.Site, ortb2.Site, site); err != nil {
+				errs = append(errs, newFirstPartyDataWarning(fmt.Sprintf("request.ext.prebid.bidderconfig site not applied for bidder %s: %v", bidder, err)))
+			} else {
+				req.Site = site
+			}
+		}
+	}
+
+	if len(ortb2.App) > 0 {
+		if req.Site != nil {
+			errs = append(errs, newFirstPartyDataWarning(fmt.Sprintf("request.ext.prebid.bidderconfig app not applied for bidder %s: the request defines site", bidder)))
+		} else {
+			app := &openrtb2.App{}
+			if err := mergeFirstPartyData(req.App, ortb2.App, app); err != nil {
+				errs = append(errs, newFirstPartyDataWarning(fmt.Sprintf("request.ext.prebid.bidderconfig app not applied for bidder %s: %v", bidder, err)))
+			} else {
+				req.App = app
+			}
+		}
+	}
+
+	if len(ortb2.User) > 0 {
+		user := &openrtb2.User{}
+		if err := mergeFirstPartyData(req.User, ortb2.User, user); err != nil {
+			errs = append(errs, newFirstPartyDataWarning(fmt.Sprintf("request.ext.prebid.bidderconfig user not applied for bidder %s: %v", bidder, err)))
+		} else {
+			req.User = user
+		}
+	}
+
+	return errs
+}
+
+func (fpd firstPartyData) allowsGlobalData(bidder string) bool {
+	if fpd.dataBidders == nil {
+		return true
+	}
+	_, allowed := fpd.dataBidders[bidder]
+	return allowed
+}
+
+// removeGlobalFirstPartyData removes site.ext.data, app.ext.data, user.ext.data and imp[].ext.data from the request.
+func removeGlobalFirstPartyData(req *openrtb2.BidRequest) error {
+	if req.Site != nil {
+		ext, err := removeExtData(req.Site.Ext)
+		if err != nil {
+			return fmt.Errorf("invalid site.ext: %v", err)
+		}
+		siteCopy := *req.Site
+		siteCopy.Ext = ext
+		req.Site = &siteCopy
+	}
+
+	if req.App != nil {
+		ext, err := removeExtData(req.App.Ext)
+		if err != nil {
+			return fmt.Errorf("invalid app.ext: %v", err)
+		}
+		appCopy := *req.App
+		appCopy.Ext = ext
+		req.App = &appCopy
+	}
+
+	if req.User != nil {
+		ext, err := removeExtData(req.User.Ext)
+		if err != nil {
+			return fmt.Errorf("invalid user.ext: %v", err)
+		}
+		userCopy := *req.User
+		userCopy.Ext = ext
+		req.User = &userCopy
+	}
+
+	if len(req.Imp) == 0 {
+		return nil
+	}
+
+	imps := make([]openrtb2.Imp, len(req.Imp))
+	for i, imp := range req.Imp {
+		ext, err := removeExtData(imp.Ext)
+		if err != nil {
+			return fmt.Errorf("invalid imp[%d].ext: %v", i, err)
+		}
+		imps[i] = imp
+		imps[i].Ext = ext
+	}
+	req.Imp = imps
+
+	return nil
+}
+
+// removeExtData returns the ext without its first party data field, or nil if nothing else is left.
+func removeExtData(ext json.RawMessage) (json.RawMessage, error) {
+	if len(ext) == 0 {
+		return ext, nil
+	}
+
+	var extMap map[string]json.RawMessage
+	if err := json.Unmarshal(ext, &extMap); err != nil {
+		return nil, err
+	}
+	if _, exists := extMap[openrtb_ext.FirstPartyDataExtKey]; !exists {
+		return ext, nil
+	}
+
+	delete(extMap, openrtb_ext.FirstPartyDataExtKey)
+	if len(extMap) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(extMap)
+}
+
+// mergeFirstPartyData merges the bidder config patch into the original object, following the JSON merge patch
+// rules, and stores the result in merged.
+func mergeFirstPartyData(original interface{}, patch json.RawMessage, merged interface{}) error {
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+	if string(originalJSON) == "null" {
+		originalJSON = []byte(`{}`)
+	}
+
+	mergedJSON, err := jsonpatch.MergePatch(originalJSON, patch)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(mergedJSON, merged)
+}
+
+func newFirstPartyDataWarning(message string) error {
+	return &errortypes.Warning{
+		WarningCode: errortypes.FirstPartyDataWarningCode,
+		Message:     message,
+	}
+}
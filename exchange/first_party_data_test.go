@@ -0,0 +1,278 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFirstPartyData(t *testing.T) {
+	siteORTB2 := &openrtb_ext.ORTB2{Site: json.RawMessage(`{"keywords":"sports"}`)}
+	userORTB2 := &openrtb_ext.ORTB2{User: json.RawMessage(`{"gender":"F"}`)}
+
+	testCases := []struct {
+		description string
+		requestExt  *openrtb_ext.ExtRequest
+		expected    firstPartyData
+	}{
+		{
+			description: "Nil ext",
+			requestExt:  nil,
+			expected:    firstPartyData{},
+		},
+		{
+			description: "Empty ext",
+			requestExt:  &openrtb_ext.ExtRequest{},
+			expected:    firstPartyData{},
+		},
+		{
+			description: "Data bidders",
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"appnexus", "rubicon"}},
+			}},
+			expected: firstPartyData{dataBidders: map[string]struct{}{"appnexus": {}, "rubicon": {}}},
+		},
+		{
+			description: "Bidder configs",
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				BidderConfigs: []openrtb_ext.BidderConfig{
+					{Bidders: []string{"appnexus", "rubicon"}, Config: &openrtb_ext.Config{ORTB2: siteORTB2}},
+					{Bidders: []string{"pubmatic"}, Config: &openrtb_ext.Config{ORTB2: userORTB2}},
+					{Bidders: []string{"openx"}, Config: &openrtb_ext.Config{}},
+				},
+			}},
+			expected: firstPartyData{bidderConfigs: map[string]*openrtb_ext.ORTB2{
+				"appnexus": siteORTB2,
+				"rubicon":  siteORTB2,
+				"pubmatic": userORTB2,
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, newFirstPartyData(test.requestExt), test.description)
+	}
+}
+
+func TestFirstPartyDataApply(t *testing.T) {
+	testCases := []struct {
+		description      string
+		fpd              firstPartyData
+		request          openrtb2.BidRequest
+		expectedRequest  openrtb2.BidRequest
+		expectedWarnings []string
+	}{
+		{
+			description: "No first party data options",
+			fpd:         firstPartyData{},
+			request: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+				User: &openrtb2.User{ID: "user", Ext: json.RawMessage(`{"data":{"segment":"1"}}`)},
+			},
+			expectedRequest: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+				User: &openrtb2.User{ID: "user", Ext: json.RawMessage(`{"data":{"segment":"1"}}`)},
+			},
+		},
+		{
+			description: "Global data kept for allowed bidder",
+			fpd:         firstPartyData{dataBidders: map[string]struct{}{"appnexus": {}}},
+			request: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+				Imp:  []openrtb2.Imp{{ID: "imp", Ext: json.RawMessage(`{"bidder":{},"data":{"pos":"top"}}`)}},
+			},
+			expectedRequest: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+				Imp:  []openrtb2.Imp{{ID: "imp", Ext: json.RawMessage(`{"bidder":{},"data":{"pos":"top"}}`)}},
+			},
+		},
+		{
+			description: "Global data removed for other bidders",
+			fpd:         firstPartyData{dataBidders: map[string]struct{}{"rubicon": {}}},
+			request: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site", Ext: json.RawMessage(`{"amp":1,"data":{"section":"news"}}`)},
+				User: &openrtb2.User{ID: "user", Ext: json.RawMessage(`{"data":{"segment":"1"}}`)},
+				Imp:  []openrtb2.Imp{{ID: "imp", Ext: json.RawMessage(`{"bidder":{},"data":{"pos":"top"}}`)}},
+			},
+			expectedRequest: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site", Ext: json.RawMessage(`{"amp":1}`)},
+				User: &openrtb2.User{ID: "user"},
+				Imp:  []openrtb2.Imp{{ID: "imp", Ext: json.RawMessage(`{"bidder":{}}`)}},
+			},
+		},
+		{
+			description: "Bidder config merged into site and user",
+			fpd: firstPartyData{bidderConfigs: map[string]*openrtb_ext.ORTB2{"appnexus": {
+				Site: json.RawMessage(`{"keywords":"sports","ext":{"data":{"section":"sports"}}}`),
+				User: json.RawMessage(`{"gender":"F"}`),
+			}}},
+			request: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site", Keywords: "news", Ext: json.RawMessage(`{"amp":1,"data":{"section":"news"}}`)},
+			},
+			expectedRequest: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site", Keywords: "sports", Ext: json.RawMessage(`{"amp":1,"data":{"section":"sports"}}`)},
+				User: &openrtb2.User{Gender: "F"},
+			},
+		},
+		{
+			description: "Bidder config merged into app",
+			fpd: firstPartyData{bidderConfigs: map[string]*openrtb_ext.ORTB2{"appnexus": {
+				App: json.RawMessage(`{"keywords":"games"}`),
+			}}},
+			request: openrtb2.BidRequest{
+				App: &openrtb2.App{ID: "app"},
+			},
+			expectedRequest: openrtb2.BidRequest{
+				App: &openrtb2.App{ID: "app", Keywords: "games"},
+			},
+		},
+		{
+			description: "Bidder config of other bidders ignored",
+			fpd: firstPartyData{bidderConfigs: map[string]*openrtb_ext.ORTB2{"rubicon": {
+				Site: json.RawMessage(`{"keywords":"sports"}`),
+			}}},
+			request: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site", Keywords: "news"},
+			},
+			expectedRequest: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site", Keywords: "news"},
+			},
+		},
+		{
+			description: "Global data removed before bidder config merged",
+			fpd: firstPartyData{
+				dataBidders: map[string]struct{}{"rubicon": {}},
+				bidderConfigs: map[string]*openrtb_ext.ORTB2{"appnexus": {
+					User: json.RawMessage(`{"ext":{"data":{"interest":"cars"}}}`),
+				}},
+			},
+			request: openrtb2.BidRequest{
+				User: &openrtb2.User{ID: "user", Ext: json.RawMessage(`{"data":{"segment":"1"}}`)},
+			},
+			expectedRequest: openrtb2.BidRequest{
+				User: &openrtb2.User{ID: "user", Ext: json.RawMessage(`{"data":{"interest":"cars"}}`)},
+			},
+		},
+		{
+			description: "Bidder config site conflicts with request app",
+			fpd: firstPartyData{bidderConfigs: map[string]*openrtb_ext.ORTB2{"appnexus": {
+				Site: json.RawMessage(`{"keywords":"sports"}`),
+				User: json.RawMessage(`{"gender":"F"}`),
+			}}},
+			request: openrtb2.BidRequest{
+				App: &openrtb2.App{ID: "app"},
+			},
+			expectedRequest: openrtb2.BidRequest{
+				App:  &openrtb2.App{ID: "app"},
+				User: &openrtb2.User{Gender: "F"},
+			},
+			expectedWarnings: []string{"request.ext.prebid.bidderconfig site not applied for bidder appnexus: the request defines app"},
+		},
+		{
+			description: "Bidder config app conflicts with request site",
+			fpd: firstPartyData{bidderConfigs: map[string]*openrtb_ext.ORTB2{"appnexus": {
+				App: json.RawMessage(`{"keywords":"games"}`),
+			}}},
+			request: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site"},
+			},
+			expectedRequest: openrtb2.BidRequest{
+				Site: &openrtb2.Site{ID: "site"},
+			},
+			expectedWarnings: []string{"request.ext.prebid.bidderconfig app not applied for bidder appnexus: the request defines site"},
+		},
+		{
+			description: "Bidder config cannot be merged",
+			fpd: firstPartyData{bidderConfigs: map[string]*openrtb_ext.ORTB2{"appnexus": {
+				User: json.RawMessage(`[]`),
+			}}},
+			request: openrtb2.BidRequest{
+				User: &openrtb2.User{ID: "user"},
+			},
+			expectedRequest: openrtb2.BidRequest{
+				User: &openrtb2.User{ID: "user"},
+			},
+			expectedWarnings: []string{"request.ext.prebid.bidderconfig user not applied for bidder appnexus: json: cannot unmarshal array into Go value of type openrtb2.User"},
+		},
+	}
+
+	for _, test := range testCases {
+		request := test.request
+		errs := test.fpd.apply(&request, "appnexus")
+
+		assert.Equal(t, test.expectedRequest, request, test.description+":request")
+		if assert.Len(t, errs, len(test.expectedWarnings), test.description+":warnings") {
+			for i, err := range errs {
+				assert.Equal(t, errortypes.FirstPartyDataWarningCode, errortypes.ReadCode(err), test.description+":code")
+				assert.Equal(t, test.expectedWarnings[i], err.Error(), test.description+":message")
+			}
+		}
+	}
+}
+
+func TestFirstPartyDataApplyDoesNotMutateNestedObjects(t *testing.T) {
+	site := &openrtb2.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"news"}}`)}
+	user := &openrtb2.User{ID: "user", Ext: json.RawMessage(`{"data":{"segment":"1"}}`)}
+	imps := []openrtb2.Imp{{ID: "imp", Ext: json.RawMessage(`{"bidder":{},"data":{"pos":"top"}}`)}}
+	request := openrtb2.BidRequest{Site: site, User: user, Imp: imps}
+
+	fpd := firstPartyData{
+		dataBidders:   map[string]struct{}{"rubicon": {}},
+		bidderConfigs: map[string]*openrtb_ext.ORTB2{"appnexus": {User: json.RawMessage(`{"gender":"F"}`)}},
+	}
+	errs := fpd.apply(&request, "appnexus")
+
+	assert.Empty(t, errs)
+	assert.Equal(t, &openrtb2.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"news"}}`)}, site)
+	assert.Equal(t, &openrtb2.User{ID: "user", Ext: json.RawMessage(`{"data":{"segment":"1"}}`)}, user)
+	assert.Equal(t, json.RawMessage(`{"bidder":{},"data":{"pos":"top"}}`), imps[0].Ext)
+}
+
+func TestRemoveExtData(t *testing.T) {
+	testCases := []struct {
+		description   string
+		ext           json.RawMessage
+		expectedExt   json.RawMessage
+		expectedError bool
+	}{
+		{
+			description: "Empty",
+			ext:         nil,
+			expectedExt: nil,
+		},
+		{
+			description: "No data",
+			ext:         json.RawMessage(`{"amp":1}`),
+			expectedExt: json.RawMessage(`{"amp":1}`),
+		},
+		{
+			description: "Only data",
+			ext:         json.RawMessage(`{"data":{"section":"news"}}`),
+			expectedExt: nil,
+		},
+		{
+			description: "Data and other fields",
+			ext:         json.RawMessage(`{"amp":1,"data":{"section":"news"}}`),
+			expectedExt: json.RawMessage(`{"amp":1}`),
+		},
+		{
+			description:   "Malformed",
+			ext:           json.RawMessage(`malformed`),
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		ext, err := removeExtData(test.ext)
+		if test.expectedError {
+			assert.Error(t, err, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+			assert.Equal(t, test.expectedExt, ext, test.description)
+		}
+	}
+}
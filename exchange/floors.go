@@ -0,0 +1,59 @@
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/floors"
+)
+
+// enforceFloorToBids removes the bids priced below the floor of the imp they were made for. Bid prices are
+// compared after bid adjustments and conversion to the seat currency. Deal bids are only checked if the
+// enforcement says so.
+func enforceFloorToBids(bidRequest *openrtb2.BidRequest, seatBid *pbsOrtbSeatBid, conversions currency.Conversions, enforcement floors.Enforcement) []error {
+	if seatBid == nil || len(seatBid.bids) == 0 {
+		return nil
+	}
+
+	impsByID := make(map[string]*openrtb2.Imp, len(bidRequest.Imp))
+	for i := range bidRequest.Imp {
+		impsByID[bidRequest.Imp[i].ID] = &bidRequest.Imp[i]
+	}
+
+	var errs []error
+	validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+	for _, bid := range seatBid.bids {
+		imp, found := impsByID[bid.bid.ImpID]
+		if !found || imp.BidFloor <= 0 || (bid.bid.DealID != "" && !enforcement.FloorDeals) {
+			validBids = append(validBids, bid)
+			continue
+		}
+
+		floorCur := imp.BidFloorCur
+		if floorCur == "" {
+			floorCur = "USD"
+		}
+		rate, err := conversions.GetRate(floorCur, seatBid.currency)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to enforce floor for bid ID %s: %v", bid.bid.ID, err))
+			validBids = append(validBids, bid)
+			continue
+		}
+
+		floor := imp.BidFloor * rate
+		if bid.bid.Price < floor {
+			errs = append(errs, &errortypes.Warning{
+				WarningCode: errortypes.FloorBidRejectionWarningCode,
+				Message: fmt.Sprintf("bid rejected [bid ID: %s] reason: bid price value %.4f %s is less than bidFloor value %.4f %s for impression id %s",
+					bid.bid.ID, bid.bid.Price, seatBid.currency, floor, seatBid.currency, imp.ID),
+			})
+			continue
+		}
+		validBids = append(validBids, bid)
+	}
+	seatBid.bids = validBids
+
+	return errs
+}
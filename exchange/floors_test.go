@@ -0,0 +1,108 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnforceFloorToBids(t *testing.T) {
+	bidRequest := &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "imp-usd", BidFloor: 1, BidFloorCur: "USD"},
+			{ID: "imp-eur", BidFloor: 1, BidFloorCur: "EUR"},
+			{ID: "imp-default-cur", BidFloor: 1},
+			{ID: "imp-no-floor"},
+		},
+	}
+	conversions := currency.NewRates(map[string]map[string]float64{"EUR": {"USD": 1.2}})
+
+	testCases := []struct {
+		description     string
+		bids            []*pbsOrtbBid
+		seatCurrency    string
+		enforcement     floors.Enforcement
+		expectedBidIDs  []string
+		expectedErrCode []int
+	}{
+		{
+			description: "Bids below floor rejected",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb2.Bid{ID: "bid-1", ImpID: "imp-usd", Price: 0.9}},
+				{bid: &openrtb2.Bid{ID: "bid-2", ImpID: "imp-usd", Price: 1}},
+				{bid: &openrtb2.Bid{ID: "bid-3", ImpID: "imp-default-cur", Price: 0.5}},
+				{bid: &openrtb2.Bid{ID: "bid-4", ImpID: "imp-no-floor", Price: 0.1}},
+			},
+			seatCurrency:    "USD",
+			enforcement:     floors.Enforcement{Enabled: true},
+			expectedBidIDs:  []string{"bid-2", "bid-4"},
+			expectedErrCode: []int{errortypes.FloorBidRejectionWarningCode, errortypes.FloorBidRejectionWarningCode},
+		},
+		{
+			description: "Floor converted to seat currency",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb2.Bid{ID: "bid-1", ImpID: "imp-eur", Price: 1.1}},
+				{bid: &openrtb2.Bid{ID: "bid-2", ImpID: "imp-eur", Price: 1.3}},
+			},
+			seatCurrency:    "USD",
+			enforcement:     floors.Enforcement{Enabled: true},
+			expectedBidIDs:  []string{"bid-2"},
+			expectedErrCode: []int{errortypes.FloorBidRejectionWarningCode},
+		},
+		{
+			description: "Deal bids kept unless deal floors are enforced",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb2.Bid{ID: "bid-1", ImpID: "imp-usd", Price: 0.5, DealID: "deal-1"}},
+			},
+			seatCurrency:   "USD",
+			enforcement:    floors.Enforcement{Enabled: true},
+			expectedBidIDs: []string{"bid-1"},
+		},
+		{
+			description: "Deal bids rejected when deal floors are enforced",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb2.Bid{ID: "bid-1", ImpID: "imp-usd", Price: 0.5, DealID: "deal-1"}},
+			},
+			seatCurrency:    "USD",
+			enforcement:     floors.Enforcement{Enabled: true, FloorDeals: true},
+			expectedBidIDs:  []string{},
+			expectedErrCode: []int{errortypes.FloorBidRejectionWarningCode},
+		},
+		{
+			description: "Bid kept when the floor can't be converted",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb2.Bid{ID: "bid-1", ImpID: "imp-eur", Price: 0.5}},
+			},
+			seatCurrency:    "JPY",
+			enforcement:     floors.Enforcement{Enabled: true},
+			expectedBidIDs:  []string{"bid-1"},
+			expectedErrCode: []int{errortypes.UnknownErrorCode},
+		},
+	}
+
+	for _, test := range testCases {
+		seatBid := &pbsOrtbSeatBid{bids: test.bids, currency: test.seatCurrency}
+
+		errs := enforceFloorToBids(bidRequest, seatBid, conversions, test.enforcement)
+
+		bidIDs := make([]string, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			bidIDs = append(bidIDs, bid.bid.ID)
+		}
+		assert.Equal(t, test.expectedBidIDs, bidIDs, test.description)
+
+		errCodes := make([]int, 0, len(errs))
+		for _, err := range errs {
+			errCodes = append(errCodes, errortypes.ReadCode(err))
+		}
+		if len(test.expectedErrCode) == 0 {
+			assert.Empty(t, errCodes, test.description)
+		} else {
+			assert.Equal(t, test.expectedErrCode, errCodes, test.description)
+		}
+	}
+}
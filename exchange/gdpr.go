@@ -2,9 +2,12 @@ package exchange
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/privacy/gpp"
 )
 
 // ExtractGDPR will pull the gdpr flag from an openrtb request
@@ -33,10 +36,35 @@ func extractConsent(bidRequest *openrtb2.BidRequest) (consent string, err error)
 	return
 }
 
+// ExtractGPP will pull the parsed GPP string and its applicable sections from an openrtb request. An invalid GPP
+// string is reported as a warning and ignored.
+func extractGPP(bidRequest *openrtb2.BidRequest) (gpp.GPP, []int8, error) {
+	var re regsExt
+	if bidRequest.Regs != nil && bidRequest.Regs.Ext != nil {
+		if err := json.Unmarshal(bidRequest.Regs.Ext, &re); err != nil {
+			return gpp.GPP{}, nil, err
+		}
+	}
+	if re.GPP == "" {
+		return gpp.GPP{}, re.GPPSID, nil
+	}
+
+	parsedGPP, err := gpp.Parse(re.GPP)
+	if err != nil {
+		return gpp.GPP{}, re.GPPSID, &errortypes.Warning{
+			Message:     fmt.Sprintf("request.regs.ext.gpp is ignored: %v", err),
+			WarningCode: errortypes.InvalidPrivacyConsentWarningCode,
+		}
+	}
+	return parsedGPP, re.GPPSID, nil
+}
+
 type userExt struct {
 	Consent string `json:"consent,omitempty"`
 }
 
 type regsExt struct {
-	GDPR *int `json:"gdpr,omitempty"`
+	GDPR   *int   `json:"gdpr,omitempty"`
+	GPP    string `json:"gpp,omitempty"`
+	GPPSID []int8 `json:"gpp_sid,omitempty"`
 }
@@ -0,0 +1,87 @@
+package exchange
+
+import (
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// executeRawBidderResponseStage runs the raw-bidder-response hooks on the bids of the seat. A rejection drops
+// all of the bidder's bids.
+func executeRawBidderResponseStage(seatBid *pbsOrtbSeatBid, bidder openrtb_ext.BidderName, hookExecutor hookexecution.StageExecutor) error {
+	if seatBid == nil {
+		return nil
+	}
+
+	typedBids, rejectErr := hookExecutor.ExecuteRawBidderResponseStage(toTypedBids(seatBid.bids), string(bidder))
+	if rejectErr != nil {
+		seatBid.bids = nil
+		return rejectErr
+	}
+	seatBid.bids = fromTypedBids(typedBids, seatBid.bids)
+	return nil
+}
+
+// executeAllProcessedBidResponsesStage runs the all-processed-bid-responses hooks on the bids of every seat.
+// Seats left without bids are removed from the auction.
+func executeAllProcessedBidResponsesStage(adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, hookExecutor hookexecution.StageExecutor) bool {
+	responses := make(map[openrtb_ext.BidderName][]*adapters.TypedBid, len(adapterBids))
+	for bidder, seatBid := range adapterBids {
+		responses[bidder] = toTypedBids(seatBid.bids)
+	}
+
+	responses = hookExecutor.ExecuteAllProcessedBidResponsesStage(responses)
+
+	bidsFound := false
+	for bidder, seatBid := range adapterBids {
+		seatBid.bids = fromTypedBids(responses[bidder], seatBid.bids)
+		if len(seatBid.bids) == 0 {
+			delete(adapterBids, bidder)
+			continue
+		}
+		bidsFound = true
+	}
+	return bidsFound
+}
+
+func toTypedBids(bids []*pbsOrtbBid) []*adapters.TypedBid {
+	typedBids := make([]*adapters.TypedBid, 0, len(bids))
+	for _, bid := range bids {
+		typedBids = append(typedBids, &adapters.TypedBid{
+			Bid:          bid.bid,
+			BidMeta:      bid.bidMeta,
+			BidType:      bid.bidType,
+			BidVideo:     bid.bidVideo,
+			DealPriority: bid.dealPriority,
+		})
+	}
+	return typedBids
+}
+
+// fromTypedBids rebuilds the exchange bids from the bids returned by the hooks. The data the hooks don't
+// have access to is kept for the bids which were already part of the seat.
+func fromTypedBids(typedBids []*adapters.TypedBid, original []*pbsOrtbBid) []*pbsOrtbBid {
+	originalBids := make(map[*openrtb2.Bid]*pbsOrtbBid, len(original))
+	for _, bid := range original {
+		originalBids[bid.bid] = bid
+	}
+
+	bids := make([]*pbsOrtbBid, 0, len(typedBids))
+	for _, typedBid := range typedBids {
+		if typedBid == nil || typedBid.Bid == nil {
+			continue
+		}
+		bid, found := originalBids[typedBid.Bid]
+		if !found {
+			bid = &pbsOrtbBid{}
+		}
+		bid.bid = typedBid.Bid
+		bid.bidMeta = typedBid.BidMeta
+		bid.bidType = typedBid.BidType
+		bid.bidVideo = typedBid.BidVideo
+		bid.dealPriority = typedBid.DealPriority
+		bids = append(bids, bid)
+	}
+	return bids
+}
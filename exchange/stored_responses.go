@@ -0,0 +1,70 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// buildStoredAuctionResponse turns the stored auction responses of the imps into the seat bids of the auction.
+// The stored bids are assigned to the imp they are stored for, whatever their own impid.
+func buildStoredAuctionResponse(request *openrtb2.BidRequest, storedAuctionResponses map[string]json.RawMessage) (
+	map[openrtb_ext.BidderName]*pbsOrtbSeatBid,
+	map[openrtb_ext.BidderName]*seatResponseExtra,
+	[]openrtb_ext.BidderName,
+	error) {
+	adapterBids := make(map[openrtb_ext.BidderName]*pbsOrtbSeatBid)
+	adapterExtra := make(map[openrtb_ext.BidderName]*seatResponseExtra)
+	var liveAdapters []openrtb_ext.BidderName
+
+	for _, imp := range request.Imp {
+		storedAuctionResponse, ok := storedAuctionResponses[imp.ID]
+		if !ok {
+			continue
+		}
+
+		var seatBids []openrtb2.SeatBid
+		if err := json.Unmarshal(storedAuctionResponse, &seatBids); err != nil {
+			return nil, nil, nil, fmt.Errorf("Invalid stored auction response for imp %s: %v", imp.ID, err)
+		}
+
+		for _, seatBid := range seatBids {
+			bidderName := openrtb_ext.BidderName(seatBid.Seat)
+			if _, ok := adapterBids[bidderName]; !ok {
+				adapterBids[bidderName] = &pbsOrtbSeatBid{currency: "USD"}
+				adapterExtra[bidderName] = &seatResponseExtra{}
+				liveAdapters = append(liveAdapters, bidderName)
+			}
+
+			for i := range seatBid.Bid {
+				bid := seatBid.Bid[i]
+				bid.ImpID = imp.ID
+				adapterBids[bidderName].bids = append(adapterBids[bidderName].bids, &pbsOrtbBid{
+					bid:     &bid,
+					bidType: getStoredBidType(bid, imp),
+				})
+			}
+		}
+	}
+	return adapterBids, adapterExtra, liveAdapters, nil
+}
+
+// getStoredBidType returns the bid type found in bid.ext.prebid.type or, if missing, the imp media type.
+func getStoredBidType(bid openrtb2.Bid, imp openrtb2.Imp) openrtb_ext.BidType {
+	var bidExt openrtb_ext.ExtBid
+	if err := json.Unmarshal(bid.Ext, &bidExt); err == nil && bidExt.Prebid != nil && bidExt.Prebid.Type != "" {
+		return bidExt.Prebid.Type
+	}
+
+	switch {
+	case imp.Banner == nil && imp.Video != nil:
+		return openrtb_ext.BidTypeVideo
+	case imp.Banner == nil && imp.Audio != nil:
+		return openrtb_ext.BidTypeAudio
+	case imp.Banner == nil && imp.Native != nil:
+		return openrtb_ext.BidTypeNative
+	}
+	return openrtb_ext.BidTypeBanner
+}
@@ -0,0 +1,93 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildStoredAuctionResponse(t *testing.T) {
+	request := &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "imp-banner", Banner: &openrtb2.Banner{}},
+			{ID: "imp-video", Video: &openrtb2.Video{}},
+		},
+	}
+
+	testCases := []struct {
+		description            string
+		storedAuctionResponses map[string]json.RawMessage
+		expectedBids           map[openrtb_ext.BidderName][]*pbsOrtbBid
+		expectedErr            string
+	}{
+		{
+			description: "Bids are assigned to the imps of their stored response",
+			storedAuctionResponses: map[string]json.RawMessage{
+				"imp-banner": json.RawMessage(`[{"bid":[{"id":"bid-1","impid":"stored-imp","price":1}],"seat":"appnexus"}]`),
+				"imp-video":  json.RawMessage(`[{"bid":[{"id":"bid-2","impid":"stored-imp","price":2}],"seat":"appnexus"},{"bid":[{"id":"bid-3","impid":"stored-imp","price":3}],"seat":"rubicon"}]`),
+			},
+			expectedBids: map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"appnexus": {
+					{bid: &openrtb2.Bid{ID: "bid-1", ImpID: "imp-banner", Price: 1}, bidType: openrtb_ext.BidTypeBanner},
+					{bid: &openrtb2.Bid{ID: "bid-2", ImpID: "imp-video", Price: 2}, bidType: openrtb_ext.BidTypeVideo},
+				},
+				"rubicon": {
+					{bid: &openrtb2.Bid{ID: "bid-3", ImpID: "imp-video", Price: 3}, bidType: openrtb_ext.BidTypeVideo},
+				},
+			},
+		},
+		{
+			description: "Bid type from bid.ext.prebid.type",
+			storedAuctionResponses: map[string]json.RawMessage{
+				"imp-banner": json.RawMessage(`[{"bid":[{"id":"bid-1","price":1,"ext":{"prebid":{"type":"native"}}}],"seat":"appnexus"}]`),
+			},
+			expectedBids: map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"appnexus": {
+					{bid: &openrtb2.Bid{ID: "bid-1", ImpID: "imp-banner", Price: 1, Ext: json.RawMessage(`{"prebid":{"type":"native"}}`)}, bidType: openrtb_ext.BidTypeNative},
+				},
+			},
+		},
+		{
+			description: "Invalid stored auction response",
+			storedAuctionResponses: map[string]json.RawMessage{
+				"imp-banner": json.RawMessage(`{"bid":[]}`),
+			},
+			expectedErr: "Invalid stored auction response for imp imp-banner: json: cannot unmarshal object into Go value of type []openrtb2.SeatBid",
+		},
+	}
+
+	for _, test := range testCases {
+		adapterBids, adapterExtra, liveAdapters, err := buildStoredAuctionResponse(request, test.storedAuctionResponses)
+
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.Len(t, adapterBids, len(test.expectedBids), test.description)
+		assert.Len(t, adapterExtra, len(test.expectedBids), test.description)
+		assert.Len(t, liveAdapters, len(test.expectedBids), test.description)
+		for bidder, expectedBids := range test.expectedBids {
+			if assert.Contains(t, adapterBids, bidder, test.description) {
+				assert.Equal(t, expectedBids, adapterBids[bidder].bids, test.description)
+				assert.Equal(t, "USD", adapterBids[bidder].currency, test.description)
+			}
+		}
+	}
+}
+
+func TestGetBidderStoredResponses(t *testing.T) {
+	storedBidResponses := map[string]map[string]json.RawMessage{
+		"imp-1": {"appnexus": json.RawMessage(`{"id":"1"}`), "rubicon": json.RawMessage(`{"id":"2"}`)},
+		"imp-2": {"rubicon": json.RawMessage(`{"id":"3"}`)},
+	}
+	imps := []openrtb2.Imp{{ID: "imp-1"}, {ID: "imp-2"}}
+
+	assert.Equal(t, map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"1"}`)}, getBidderStoredResponses(storedBidResponses, "appnexus", imps))
+	assert.Equal(t, map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"2"}`), "imp-2": json.RawMessage(`{"id":"3"}`)}, getBidderStoredResponses(storedBidResponses, "rubicon", imps))
+	assert.Nil(t, getBidderStoredResponses(storedBidResponses, "openx", imps))
+	assert.Nil(t, getBidderStoredResponses(storedBidResponses, "rubicon", []openrtb2.Imp{{ID: "imp-3"}}))
+}
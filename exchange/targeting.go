@@ -24,6 +24,8 @@ type targetData struct {
 	includeCacheVast  bool
 	includeFormat     bool
 	preferDeals       bool
+	// multiBid holds the multibid config of the request by bidder
+	multiBid map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid
 	// cacheHost and cachePath exist to supply cache host and path as targeting parameters
 	cacheHost string
 	cachePath string
@@ -36,54 +38,106 @@ type targetData struct {
 // it's ok if those stay in the auction. For now, this method implements a very naive cache strategy.
 // In the future, we should implement a more clever retry & backoff strategy to balance the success rate & performance.
 func (targData *targetData) setTargeting(auc *auction, isApp bool, categoryMapping map[string]string) {
-	for impId, topBidsPerImp := range auc.winningBidsByBidder {
+	for impId, bidsPerBidder := range auc.allBidsByBidder {
 		overallWinner := auc.winningBids[impId]
-		for bidderName, topBidPerBidder := range topBidsPerImp {
-			isOverallWinner := overallWinner == topBidPerBidder
-
-			targets := make(map[string]string, 10)
-			if cpm, ok := auc.roundedPrices[topBidPerBidder]; ok {
-				targData.addKeys(targets, openrtb_ext.HbpbConstantKey, cpm, bidderName, isOverallWinner)
-			}
-			targData.addKeys(targets, openrtb_ext.HbBidderConstantKey, string(bidderName), bidderName, isOverallWinner)
-			if hbSize := makeHbSize(topBidPerBidder.bid); hbSize != "" {
-				targData.addKeys(targets, openrtb_ext.HbSizeConstantKey, hbSize, bidderName, isOverallWinner)
-			}
-			if cacheID, ok := auc.cacheIds[topBidPerBidder.bid]; ok {
-				targData.addKeys(targets, openrtb_ext.HbCacheKey, cacheID, bidderName, isOverallWinner)
-			}
-			if vastID, ok := auc.vastCacheIds[topBidPerBidder.bid]; ok {
-				targData.addKeys(targets, openrtb_ext.HbVastCacheKey, vastID, bidderName, isOverallWinner)
-			}
-			if targData.includeFormat {
-				targData.addKeys(targets, openrtb_ext.HbFormatKey, string(topBidPerBidder.bidType), bidderName, isOverallWinner)
+		for bidderName, bids := range bidsPerBidder {
+			for i, bid := range bids {
+				targetingBidder := bidderName
+				if i > 0 {
+					// Extra bids are targeted under the bidder code prefix of the multibid config, e.g. hb_pb_prefix2
+					prefix := targData.targetBidderCodePrefix(bidderName)
+					if prefix == "" {
+						continue
+					}
+					targetingBidder = openrtb_ext.BidderName(prefix + strconv.Itoa(i+1))
+				}
+				targData.setBidTargeting(bid, targetingBidder, targData.hasBidderKeys(bidderName, i), overallWinner == bid, isApp, categoryMapping, auc)
 			}
+		}
+	}
+}
 
-			if targData.cacheHost != "" {
-				targData.addKeys(targets, openrtb_ext.HbConstantCacheHostKey, targData.cacheHost, bidderName, isOverallWinner)
-			}
-			if targData.cachePath != "" {
-				targData.addKeys(targets, openrtb_ext.HbConstantCachePathKey, targData.cachePath, bidderName, isOverallWinner)
-			}
+func (targData *targetData) setBidTargeting(bid *pbsOrtbBid, bidderName openrtb_ext.BidderName, includeBidderKeys bool, isOverallWinner bool, isApp bool, categoryMapping map[string]string, auc *auction) {
+	targets := make(map[string]string, 10)
+	if cpm, ok := auc.roundedPrices[bid]; ok {
+		targData.addKeys(targets, openrtb_ext.HbpbConstantKey, cpm, bidderName, includeBidderKeys, isOverallWinner)
+	}
+	targData.addKeys(targets, openrtb_ext.HbBidderConstantKey, string(bidderName), bidderName, includeBidderKeys, isOverallWinner)
+	if hbSize := makeHbSize(bid.bid); hbSize != "" {
+		targData.addKeys(targets, openrtb_ext.HbSizeConstantKey, hbSize, bidderName, includeBidderKeys, isOverallWinner)
+	}
+	if cacheID, ok := auc.cacheIds[bid.bid]; ok {
+		targData.addKeys(targets, openrtb_ext.HbCacheKey, cacheID, bidderName, includeBidderKeys, isOverallWinner)
+	}
+	if vastID, ok := auc.vastCacheIds[bid.bid]; ok {
+		targData.addKeys(targets, openrtb_ext.HbVastCacheKey, vastID, bidderName, includeBidderKeys, isOverallWinner)
+	}
+	if targData.includeFormat {
+		targData.addKeys(targets, openrtb_ext.HbFormatKey, string(bid.bidType), bidderName, includeBidderKeys, isOverallWinner)
+	}
 
-			if deal := topBidPerBidder.bid.DealID; len(deal) > 0 {
-				targData.addKeys(targets, openrtb_ext.HbDealIDConstantKey, deal, bidderName, isOverallWinner)
-			}
+	if targData.cacheHost != "" {
+		targData.addKeys(targets, openrtb_ext.HbConstantCacheHostKey, targData.cacheHost, bidderName, includeBidderKeys, isOverallWinner)
+	}
+	if targData.cachePath != "" {
+		targData.addKeys(targets, openrtb_ext.HbConstantCachePathKey, targData.cachePath, bidderName, includeBidderKeys, isOverallWinner)
+	}
 
-			if isApp {
-				targData.addKeys(targets, openrtb_ext.HbEnvKey, openrtb_ext.HbEnvKeyApp, bidderName, isOverallWinner)
-			}
-			if len(categoryMapping) > 0 {
-				targData.addKeys(targets, openrtb_ext.HbCategoryDurationKey, categoryMapping[topBidPerBidder.bid.ID], bidderName, isOverallWinner)
-			}
+	if deal := bid.bid.DealID; len(deal) > 0 {
+		targData.addKeys(targets, openrtb_ext.HbDealIDConstantKey, deal, bidderName, includeBidderKeys, isOverallWinner)
+	}
+
+	if isApp {
+		targData.addKeys(targets, openrtb_ext.HbEnvKey, openrtb_ext.HbEnvKeyApp, bidderName, includeBidderKeys, isOverallWinner)
+	}
+	if len(categoryMapping) > 0 {
+		targData.addKeys(targets, openrtb_ext.HbCategoryDurationKey, categoryMapping[bid.bid.ID], bidderName, includeBidderKeys, isOverallWinner)
+	}
+
+	bid.bidTargets = targets
+}
+
+// maxBids returns how many bids of the bidder per imp take part in targeting and cache.
+func (targData *targetData) maxBids(bidderName openrtb_ext.BidderName) int {
+	if targData != nil {
+		if multiBid, ok := targData.multiBid[bidderName]; ok && multiBid.MaxBids != nil {
+			return *multiBid.MaxBids
+		}
+	}
+	return openrtb_ext.DefaultBidLimit
+}
+
+func (targData *targetData) targetBidderCodePrefix(bidderName openrtb_ext.BidderName) string {
+	if targData != nil {
+		if multiBid, ok := targData.multiBid[bidderName]; ok {
+			return multiBid.TargetBidderCodePrefix
+		}
+	}
+	return ""
+}
+
+// hasBidderKeys returns true if the bid of the bidder at the given rank among its bids is targeted with the bidder
+// keys. The extra bids are only targeted under the multibid target bidder code prefix of their bidder, even if the
+// request doesn't ask for the bidder keys. Without a prefix, nothing refers to them.
+func (targData *targetData) hasBidderKeys(bidderName openrtb_ext.BidderName, rank int) bool {
+	if rank > 0 {
+		return targData.targetBidderCodePrefix(bidderName) != ""
+	}
+	return targData.includeBidderKeys
+}
 
-			topBidPerBidder.bidTargets = targets
+// hasTargetBidderCodePrefix returns true if a bidder has its extra bids targeted under a bidder code prefix.
+func (targData *targetData) hasTargetBidderCodePrefix() bool {
+	for _, multiBid := range targData.multiBid {
+		if multiBid.TargetBidderCodePrefix != "" {
+			return true
 		}
 	}
+	return false
 }
 
-func (targData *targetData) addKeys(keys map[string]string, key openrtb_ext.TargetingKey, value string, bidderName openrtb_ext.BidderName, overallWinner bool) {
-	if targData.includeBidderKeys {
+func (targData *targetData) addKeys(keys map[string]string, key openrtb_ext.TargetingKey, value string, bidderName openrtb_ext.BidderName, includeBidderKeys bool, overallWinner bool) {
+	if includeBidderKeys {
 		keys[key.BidderKey(bidderName, MaxKeyLength)] = value
 	}
 	if targData.includeWinners && overallWinner {
@@ -143,7 +143,7 @@ func buildAdapterMap(bids map[openrtb_ext.BidderName][]*openrtb2.Bid, mockServer
 		adapterMap[bidder] = adaptBidder(&mockTargetingBidder{
 			mockServerURL: mockServerURL,
 			bids:          bids,
-		}, client, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		}, client, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "")
 	}
 	return adapterMap
 }
@@ -280,16 +280,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			includeWinners:   true,
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid084,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 		},
@@ -310,16 +310,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			includeBidderKeys: true,
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid084,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 		},
@@ -345,16 +345,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			includeFormat:     true,
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid084,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 		},
@@ -385,16 +385,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			cachePath:         "cache",
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid111,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 			cacheIds: map[*openrtb2.Bid]string{
@@ -431,8 +431,9 @@ func TestSetTargeting(t *testing.T) {
 		auc.setRoundedPrices(test.TargetData.priceGranularity)
 		winningBids := make(map[string]*pbsOrtbBid)
 		// Set winning bids from the auction data
-		for imp, bidsByBidder := range auc.winningBidsByBidder {
-			for _, bid := range bidsByBidder {
+		for imp, bidsByBidder := range auc.allBidsByBidder {
+			for _, bids := range bidsByBidder {
+				bid := bids[0]
 				if winningBid, ok := winningBids[imp]; ok {
 					if winningBid.bid.Price < bid.bid.Price {
 						winningBids[imp] = bid
@@ -449,7 +450,7 @@ func TestSetTargeting(t *testing.T) {
 			for bidder, expected := range targetsByBidder {
 				assert.Equal(t,
 					expected,
-					auc.winningBidsByBidder[imp][bidder].bidTargets,
+					auc.allBidsByBidder[imp][bidder][0].bidTargets,
 					"Test: %s\nTargeting failed for bidder %s on imp %s.",
 					test.Description,
 					string(bidder),
@@ -459,3 +460,77 @@ func TestSetTargeting(t *testing.T) {
 	}
 
 }
+
+func TestSetTargetingMultiBid(t *testing.T) {
+	bid1 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "bid1", ImpID: "ImpId-1", Price: 3}, bidType: openrtb_ext.BidTypeBanner}
+	bid2 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "bid2", ImpID: "ImpId-1", Price: 2, DealID: "mydeal"}, bidType: openrtb_ext.BidTypeBanner}
+	bid3 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "bid3", ImpID: "ImpId-1", Price: 1}, bidType: openrtb_ext.BidTypeBanner}
+	maxBids := 3
+
+	testCases := []struct {
+		description       string
+		includeBidderKeys bool
+		multiBid          map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid
+		expectedTargets   []map[string]string
+	}{
+		{
+			description:       "Extra bids targeted under the bidder code prefix",
+			includeBidderKeys: true,
+			multiBid: map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid{
+				openrtb_ext.BidderAppnexus: {Bidder: "appnexus", MaxBids: &maxBids, TargetBidderCodePrefix: "apn"},
+			},
+			expectedTargets: []map[string]string{
+				{"hb_bidder": "appnexus", "hb_bidder_appnexus": "appnexus", "hb_pb": "3.00", "hb_pb_appnexus": "3.00"},
+				{"hb_bidder_apn2": "apn2", "hb_pb_apn2": "2.00", "hb_deal_apn2": "mydeal"},
+				{"hb_bidder_apn3": "apn3", "hb_pb_apn3": "1.00"},
+			},
+		},
+		{
+			description: "Extra bids targeted under the bidder code prefix without the bidder keys",
+			multiBid: map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid{
+				openrtb_ext.BidderAppnexus: {Bidder: "appnexus", MaxBids: &maxBids, TargetBidderCodePrefix: "apn"},
+			},
+			expectedTargets: []map[string]string{
+				{"hb_bidder": "appnexus", "hb_pb": "3.00"},
+				{"hb_bidder_apn2": "apn2", "hb_pb_apn2": "2.00", "hb_deal_apn2": "mydeal"},
+				{"hb_bidder_apn3": "apn3", "hb_pb_apn3": "1.00"},
+			},
+		},
+		{
+			description:       "Extra bids not targeted without a bidder code prefix",
+			includeBidderKeys: true,
+			multiBid: map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid{
+				openrtb_ext.BidderAppnexus: {Bidders: []string{"appnexus"}, MaxBids: &maxBids},
+			},
+			expectedTargets: []map[string]string{
+				{"hb_bidder": "appnexus", "hb_bidder_appnexus": "appnexus", "hb_pb": "3.00", "hb_pb_appnexus": "3.00"},
+				nil,
+				nil,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		for _, bid := range []*pbsOrtbBid{bid1, bid2, bid3} {
+			bid.bidTargets = nil
+		}
+		targData := &targetData{
+			priceGranularity:  openrtb_ext.PriceGranularityFromString("med"),
+			includeWinners:    true,
+			includeBidderKeys: test.includeBidderKeys,
+			multiBid:          test.multiBid,
+		}
+		auc := &auction{
+			winningBids: map[string]*pbsOrtbBid{"ImpId-1": bid1},
+			allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"ImpId-1": {openrtb_ext.BidderAppnexus: {bid1, bid2, bid3}},
+			},
+		}
+		auc.setRoundedPrices(targData.priceGranularity)
+		targData.setTargeting(auc, false, nil)
+
+		for i, bid := range []*pbsOrtbBid{bid1, bid2, bid3} {
+			assert.Equal(t, test.expectedTargets[i], bid.bidTargets, "%s: bid %d", test.description, i+1)
+		}
+	}
+}
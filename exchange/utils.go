@@ -11,11 +11,13 @@ import (
 
 	"github.com/buger/jsonparser"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/privacy/lmt"
 )
 
@@ -86,9 +88,23 @@ func cleanOpenRTBRequests(ctx context.Context,
 	if err != nil {
 		errs = append(errs, err)
 	}
+	gppString, gppSID, err := extractGPP(req.BidRequest)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	// the applicable GPP sections decide whether GDPR applies when the request does not say so
+	if gdprSignal == gdpr.SignalAmbiguous && len(gppSID) > 0 {
+		gdprSignal = gdpr.SignalNo
+		if gpp.Applies(gppSID, gpp.SectionTCFEU2) {
+			gdprSignal = gdpr.SignalYes
+		}
+	}
+	if consent == "" && gpp.Applies(gppSID, gpp.SectionTCFEU2) {
+		consent = gppString.TCF2Consent()
+	}
 	gdprEnforced := gdprSignal == gdpr.SignalYes || (gdprSignal == gdpr.SignalAmbiguous && gdprDefaultValue == gdpr.SignalYes)
 
-	ccpaEnforcer, err := extractCCPA(req.BidRequest, privacyConfig, &req.Account, aliases, integrationTypeMap[req.LegacyLabels.RType])
+	ccpaEnforcer, err := extractCCPA(req.BidRequest, privacyConfig, &req.Account, aliases, integrationTypeMap[req.LegacyLabels.RType], gppString, gppSID)
 	if err != nil {
 		errs = append(errs, err)
 	}
@@ -117,9 +133,21 @@ func cleanOpenRTBRequests(ctx context.Context,
 		}
 	}
 
+	activityControl := privacy.NewActivityControl(&req.Account.Privacy)
+	activityRequest := privacy.NewActivityRequest(req.BidRequest)
+
 	// bidder level privacy policies
 	allowedBidderRequests = make([]BidderRequest, 0, len(allBidderRequests))
 	for _, bidderRequest := range allBidderRequests {
+		// activity controls
+		bidderComponent := privacy.Component{Type: privacy.ComponentTypeBidder, Name: bidderRequest.BidderName.String()}
+		if !activityControl.Allow(privacy.ActivityFetchBids, bidderComponent, activityRequest) {
+			continue
+		}
+		privacyEnforcement.UFPD = !activityControl.Allow(privacy.ActivityTransmitUserFPD, bidderComponent, activityRequest)
+		privacyEnforcement.Eids = !activityControl.Allow(privacy.ActivityTransmitEids, bidderComponent, activityRequest)
+		privacyEnforcement.PreciseGeo = !activityControl.Allow(privacy.ActivityTransmitPreciseGeo, bidderComponent, activityRequest)
+
 		bidRequestAllowed := true
 
 		// CCPA
@@ -176,12 +204,25 @@ func ccpaEnabled(account *config.Account, privacyConfig config.Privacy, requestT
 	return privacyConfig.CCPA.Enforce
 }
 
-func extractCCPA(orig *openrtb2.BidRequest, privacyConfig config.Privacy, account *config.Account, aliases map[string]string, requestType config.IntegrationType) (privacy.PolicyEnforcer, error) {
+func extractCCPA(orig *openrtb2.BidRequest, privacyConfig config.Privacy, account *config.Account, aliases map[string]string, requestType config.IntegrationType, gppString gpp.GPP, gppSID []int8) (privacy.PolicyEnforcer, error) {
 	// Quick extra wrapper until RequestWrapper makes its way into CleanRequests
 	ccpaPolicy, err := ccpa.ReadFromRequestWrapper(&openrtb_ext.RequestWrapper{BidRequest: orig})
 	if err != nil {
 		return privacy.NilPolicyEnforcer{}, err
 	}
+	if ccpaPolicy.Consent == "" && gpp.Applies(gppSID, gpp.SectionUSPV1) {
+		ccpaPolicy.Consent = gppString.USPrivacy()
+	}
+
+	// An invalid US section is reported, and treated as no opt-out so that the us_privacy string still applies
+	var gppWarning error
+	gppOptOut, err := gppString.USOptOut(gppSID)
+	if err != nil {
+		gppWarning = &errortypes.Warning{
+			Message:     fmt.Sprintf("request.regs.ext.gpp US section is ignored: %v", err),
+			WarningCode: errortypes.InvalidPrivacyConsentWarningCode,
+		}
+	}
 
 	validBidders := GetValidBidders(aliases)
 	ccpaParsedPolicy, err := ccpaPolicy.Parse(validBidders)
@@ -191,9 +232,9 @@ func extractCCPA(orig *openrtb2.BidRequest, privacyConfig config.Privacy, accoun
 
 	ccpaEnforcer := privacy.EnabledPolicyEnforcer{
 		Enabled:        ccpaEnabled(account, privacyConfig, requestType),
-		PolicyEnforcer: ccpaParsedPolicy,
+		PolicyEnforcer: ccpaParsedPolicy.WithOptOut(gppOptOut),
 	}
-	return ccpaEnforcer, nil
+	return ccpaEnforcer, gppWarning
 }
 
 func extractLMT(orig *openrtb2.BidRequest, privacyConfig config.Privacy) privacy.PolicyEnforcer {
@@ -231,6 +272,8 @@ func getAuctionBidderRequests(req AuctionRequest,
 		return nil, []error{err}
 	}
 
+	fpd := newFirstPartyData(requestExt)
+
 	var errs []error
 	for bidder, imps := range impsByBidder {
 		coreBidder := resolveBidder(bidder, aliases)
@@ -241,6 +284,8 @@ func getAuctionBidderRequests(req AuctionRequest,
 
 		prepareSource(&reqCopy, bidder, sChainsByBidder)
 
+		errs = append(errs, fpd.apply(&reqCopy, bidder)...)
+
 		if err := removeUnpermissionedEids(&reqCopy, bidder, requestExt); err != nil {
 			errs = append(errs, fmt.Errorf("unable to enforce request.ext.prebid.data.eidpermissions because %v", err))
 			continue
@@ -258,6 +303,7 @@ func getAuctionBidderRequests(req AuctionRequest,
 				CookieFlag:  req.LegacyLabels.CookieFlag,
 				AdapterBids: metrics.AdapterBidPresent,
 			},
+			BidderStoredResponses: getBidderStoredResponses(req.StoredBidResponses, bidder, imps),
 		}
 
 		syncerKey := bidderToSyncerKey[string(coreBidder)]
@@ -272,6 +318,20 @@ func getAuctionBidderRequests(req AuctionRequest,
 	return bidderRequests, errs
 }
 
+// getBidderStoredResponses returns the stored responses of the bidder, keyed by imp ID.
+func getBidderStoredResponses(storedBidResponses map[string]map[string]json.RawMessage, bidder string, imps []openrtb2.Imp) map[string]json.RawMessage {
+	var bidderStoredResponses map[string]json.RawMessage
+	for _, imp := range imps {
+		if storedResponse, ok := storedBidResponses[imp.ID][bidder]; ok {
+			if bidderStoredResponses == nil {
+				bidderStoredResponses = make(map[string]json.RawMessage)
+			}
+			bidderStoredResponses[imp.ID] = storedResponse
+		}
+	}
+	return bidderStoredResponses
+}
+
 func getExtJson(req *openrtb2.BidRequest, unpackedExt *openrtb_ext.ExtRequest) (json.RawMessage, error) {
 	if len(req.Ext) == 0 || unpackedExt == nil {
 		return json.RawMessage(``), nil
@@ -279,6 +339,16 @@ func getExtJson(req *openrtb2.BidRequest, unpackedExt *openrtb_ext.ExtRequest) (
 
 	extCopy := *unpackedExt
 	extCopy.Prebid.SChains = nil
+	extCopy.Prebid.BidderConfigs = nil
+	if extCopy.Prebid.Data != nil && len(extCopy.Prebid.Data.Bidders) > 0 {
+		if len(extCopy.Prebid.Data.EidPermissions) > 0 {
+			dataCopy := *extCopy.Prebid.Data
+			dataCopy.Bidders = nil
+			extCopy.Prebid.Data = &dataCopy
+		} else {
+			extCopy.Prebid.Data = nil
+		}
+	}
 	return json.Marshal(extCopy)
 }
 
@@ -331,8 +401,8 @@ func extractBuyerUIDs(user *openrtb2.User) (map[string]string, error) {
 		return nil, nil
 	}
 
-	// The API guarantees that user.ext.prebid.buyeruids exists and has at least one ID defined,
-	// as long as user.ext.prebid exists.
+	// The API guarantees that user.ext.prebid has either a user.ext.prebid.fpid, or a user.ext.prebid.buyeruids
+	// with at least one ID defined. The fpid is removed along with the buyeruids.
 	buyerUIDs := userExt.Prebid.BuyerUIDs
 	userExt.Prebid = nil
 
@@ -684,11 +754,37 @@ func getExtTargetData(requestExt *openrtb_ext.ExtRequest, cacheInstructions *ext
 			includeCacheVast:  cacheInstructions.cacheVAST,
 			includeFormat:     requestExt.Prebid.Targeting.IncludeFormat,
 			preferDeals:       requestExt.Prebid.Targeting.PreferDeals,
+			multiBid:          getExtMultiBid(requestExt.Prebid.MultiBid),
 		}
 	}
 	return targData
 }
 
+// getExtMultiBid indexes the multibid config of the request by bidder. The config is expected to have been
+// validated by the endpoint, so a bidder listed more than once keeps its first entry.
+func getExtMultiBid(multiBids []*openrtb_ext.ExtMultiBid) map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid {
+	if len(multiBids) == 0 {
+		return nil
+	}
+
+	multiBidByBidder := make(map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid)
+	for _, multiBid := range multiBids {
+		if multiBid == nil {
+			continue
+		}
+		bidders := multiBid.Bidders
+		if multiBid.Bidder != "" {
+			bidders = []string{multiBid.Bidder}
+		}
+		for _, bidder := range bidders {
+			if _, found := multiBidByBidder[openrtb_ext.BidderName(bidder)]; !found {
+				multiBidByBidder[openrtb_ext.BidderName(bidder)] = multiBid
+			}
+		}
+	}
+	return multiBidByBidder
+}
+
 func getDebugInfo(bidRequest *openrtb2.BidRequest, requestExt *openrtb_ext.ExtRequest) bool {
 	return (bidRequest != nil && bidRequest.Test == 1) || (requestExt != nil && requestExt.Prebid.Debug)
 }
@@ -700,3 +796,8 @@ func getExtBidAdjustmentFactors(requestExt *openrtb_ext.ExtRequest) map[string]f
 	}
 	return bidAdjustmentFactors
 }
+
+// isAdsCertEnabled tells if the request asks for the bidder requests to be signed with ads.cert
+func isAdsCertEnabled(experiment *openrtb_ext.Experiment) bool {
+	return experiment != nil && experiment.AdsCert != nil && experiment.AdsCert.Enabled
+}
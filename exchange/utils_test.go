@@ -868,6 +868,85 @@ func TestCleanOpenRTBRequestsSChain(t *testing.T) {
 	}
 }
 
+func TestCleanOpenRTBRequestsFirstPartyData(t *testing.T) {
+	testCases := []struct {
+		description      string
+		inExt            json.RawMessage
+		outRequestExt    json.RawMessage
+		outSiteByBidder  map[string]*openrtb2.Site
+		expectedWarnings int
+	}{
+		{
+			description:   "Global data restricted to listed bidders",
+			inExt:         json.RawMessage(`{"prebid":{"data":{"bidders":["appnexus"]}}}`),
+			outRequestExt: json.RawMessage(`{"prebid":{}}`),
+			outSiteByBidder: map[string]*openrtb2.Site{
+				"appnexus": {Page: "www.some.domain.com", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+				"rubicon":  {Page: "www.some.domain.com"},
+			},
+		},
+		{
+			description:   "Global data restricted to listed bidders with eid permissions",
+			inExt:         json.RawMessage(`{"prebid":{"data":{"bidders":["appnexus"],"eidpermissions":[{"source":"source1","bidders":["appnexus"]}]}}}`),
+			outRequestExt: json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"source1","bidders":["appnexus"]}]}}}`),
+			outSiteByBidder: map[string]*openrtb2.Site{
+				"appnexus": {Page: "www.some.domain.com", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+				"rubicon":  {Page: "www.some.domain.com"},
+			},
+		},
+		{
+			description:   "Bidder config merged for the listed bidders",
+			inExt:         json.RawMessage(`{"prebid":{"bidderconfig":[{"bidders":["rubicon"],"config":{"ortb2":{"site":{"keywords":"sports"}}}}]}}`),
+			outRequestExt: json.RawMessage(`{"prebid":{}}`),
+			outSiteByBidder: map[string]*openrtb2.Site{
+				"appnexus": {Page: "www.some.domain.com", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+				"rubicon":  {Page: "www.some.domain.com", Keywords: "sports", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+			},
+		},
+		{
+			description:   "Bidder config not applied reported as warning",
+			inExt:         json.RawMessage(`{"prebid":{"bidderconfig":[{"bidders":["rubicon"],"config":{"ortb2":{"app":{"keywords":"games"}}}}]}}`),
+			outRequestExt: json.RawMessage(`{"prebid":{}}`),
+			outSiteByBidder: map[string]*openrtb2.Site{
+				"appnexus": {Page: "www.some.domain.com", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+				"rubicon":  {Page: "www.some.domain.com", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+			},
+			expectedWarnings: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb2.BidRequest{
+			Site: &openrtb2.Site{Page: "www.some.domain.com", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+			Imp: []openrtb2.Imp{{
+				ID:     "some-imp-id",
+				Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+				Ext:    json.RawMessage(`{"appnexus":{"placementId":1},"rubicon":{"zoneId":2}}`),
+			}},
+			Ext: test.inExt,
+		}
+		extRequest, err := extractBidRequestExt(req)
+		assert.NoError(t, err, test.description+":Error unmarshaling inExt")
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+		}
+
+		permissions := permissionsMock{allowAllBidders: true, passGeo: true, passID: true}
+		metrics := metrics.MetricsEngineMock{}
+		bidderRequests, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, extRequest, map[string]string{}, &permissions, &metrics, gdpr.SignalNo, config.Privacy{}, nil)
+
+		assert.Len(t, errs, test.expectedWarnings, test.description+":errors")
+		assert.Len(t, bidderRequests, len(test.outSiteByBidder), test.description+":bidderRequests")
+		for _, bidderRequest := range bidderRequests {
+			bidder := bidderRequest.BidderName.String()
+			assert.Equal(t, test.outSiteByBidder[bidder], bidderRequest.BidRequest.Site, test.description+":"+bidder+":Site")
+			assert.Equal(t, test.outRequestExt, bidderRequest.BidRequest.Ext, test.description+":"+bidder+":Ext")
+		}
+	}
+}
+
 func TestExtractBidRequestExt(t *testing.T) {
 	var boolFalse, boolTrue *bool = new(bool), new(bool)
 	*boolFalse = false
@@ -1211,6 +1290,7 @@ func TestGetExtTargetData(t *testing.T) {
 		targetData    *targetData
 		nilTargetData bool
 	}
+	multiBidMaxBids := 2
 	testCases := []struct {
 		desc string
 		in   inTest
@@ -1276,6 +1356,32 @@ func TestGetExtTargetData(t *testing.T) {
 				nilTargetData: false,
 			},
 		},
+		{
+			"Targeting data with multibid in requestExt, multibid indexed by bidder",
+			inTest{
+				requestExt: &openrtb_ext.ExtRequest{
+					Prebid: openrtb_ext.ExtRequestPrebid{
+						Targeting: &openrtb_ext.ExtRequestTargeting{IncludeBidderKeys: true},
+						MultiBid: []*openrtb_ext.ExtMultiBid{
+							{Bidder: "appnexus", MaxBids: &multiBidMaxBids, TargetBidderCodePrefix: "apn"},
+							{Bidders: []string{"rubicon", "openx"}, MaxBids: &multiBidMaxBids},
+						},
+					},
+				},
+				cacheInstructions: &extCacheInstructions{},
+			},
+			outTest{
+				targetData: &targetData{
+					includeBidderKeys: true,
+					multiBid: map[openrtb_ext.BidderName]*openrtb_ext.ExtMultiBid{
+						"appnexus": {Bidder: "appnexus", MaxBids: &multiBidMaxBids, TargetBidderCodePrefix: "apn"},
+						"rubicon":  {Bidders: []string{"rubicon", "openx"}, MaxBids: &multiBidMaxBids},
+						"openx":    {Bidders: []string{"rubicon", "openx"}, MaxBids: &multiBidMaxBids},
+					},
+				},
+				nilTargetData: false,
+			},
+		},
 	}
 	for _, test := range testCases {
 		actualTargetData := getExtTargetData(test.in.requestExt, test.in.cacheInstructions)
@@ -1682,6 +1788,242 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 	}
 }
 
+func TestCleanOpenRTBRequestsGPP(t *testing.T) {
+	tcf2Consent := "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA"
+
+	testCases := []struct {
+		description         string
+		regsExt             string
+		gdprDefaultValue    gdpr.Signal
+		expectScrub         bool
+		expectPrivacyLabels metrics.PrivacyLabels
+		expectError         bool
+	}{
+		{
+			description:      "GDPR applies and consent from the GPP TCF EU v2 section",
+			regsExt:          `{"gpp":"DBABMA~` + tcf2Consent + `","gpp_sid":[2]}`,
+			gdprDefaultValue: gdpr.SignalNo,
+			expectScrub:      true,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				GDPREnforced:   true,
+				GDPRTCFVersion: metrics.TCFVersionV2,
+			},
+		},
+		{
+			description:         "GDPR does not apply according to the GPP applicable sections",
+			regsExt:             `{"gpp":"DBABMA~` + tcf2Consent + `","gpp_sid":[6]}`,
+			gdprDefaultValue:    gdpr.SignalYes,
+			expectScrub:         false,
+			expectPrivacyLabels: metrics.PrivacyLabels{},
+		},
+		{
+			description:      "GDPR signal takes precedence over the GPP applicable sections",
+			regsExt:          `{"gdpr":1,"gpp":"DBABMA~` + tcf2Consent + `","gpp_sid":[6]}`,
+			gdprDefaultValue: gdpr.SignalNo,
+			expectScrub:      true,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				GDPREnforced: true,
+			},
+		},
+		{
+			description:      "CCPA opt-out from the GPP USP v1 section",
+			regsExt:          `{"gpp":"DBACNY~` + tcf2Consent + `~1YYY","gpp_sid":[6]}`,
+			gdprDefaultValue: gdpr.SignalYes,
+			expectScrub:      true,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: true,
+			},
+		},
+		{
+			description:      "Invalid GPP US section falls back to the us_privacy opt-out",
+			regsExt:          `{"us_privacy":"1YYY","gpp":"DBABL~x","gpp_sid":[7]}`,
+			gdprDefaultValue: gdpr.SignalYes,
+			expectScrub:      true,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: true,
+			},
+			expectError: true,
+		},
+		{
+			description:      "Invalid GPP string ignored",
+			regsExt:          `{"gpp":"invalid","gpp_sid":[2]}`,
+			gdprDefaultValue: gdpr.SignalNo,
+			expectScrub:      true,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				GDPREnforced: true,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.Regs = &openrtb2.Regs{Ext: json.RawMessage(test.regsExt)}
+
+		privacyConfig := config.Privacy{
+			CCPA: config.CCPA{
+				Enforce: true,
+			},
+			GDPR: config.GDPR{
+				Enabled: true,
+				TCF2: config.TCF2{
+					Enabled: true,
+				},
+			},
+		}
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+		}
+
+		results, privacyLabels, errs := cleanOpenRTBRequests(
+			context.Background(),
+			auctionReq,
+			nil,
+			map[string]string{},
+			&permissionsMock{allowAllBidders: true, passGeo: false, passID: false},
+			&metrics.MetricsEngineMock{},
+			test.gdprDefaultValue,
+			privacyConfig,
+			nil)
+		result := results[0]
+
+		if test.expectError {
+			assert.NotEmpty(t, errs, test.description+":errs")
+		} else {
+			assert.Empty(t, errs, test.description+":errs")
+		}
+
+		if test.expectScrub {
+			assert.Equal(t, "", result.BidRequest.User.BuyerUID, test.description+":User.BuyerUID")
+		} else {
+			assert.NotEqual(t, "", result.BidRequest.User.BuyerUID, test.description+":User.BuyerUID")
+		}
+		assert.Equal(t, test.expectPrivacyLabels, privacyLabels, test.description+":PrivacyLabels")
+	}
+}
+
+func TestCleanOpenRTBRequestsActivityControls(t *testing.T) {
+	falseValue := false
+	denyAppnexus := config.Activity{
+		Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{"appnexus"}}, Allow: false}},
+	}
+
+	testCases := []struct {
+		description      string
+		allowActivities  config.AllowActivities
+		expectBidRequest bool
+		expectUserFPD    bool
+		expectUserEids   bool
+		expectPreciseGeo bool
+	}{
+		{
+			description:      "No activity controls",
+			expectBidRequest: true,
+			expectUserFPD:    true,
+			expectUserEids:   true,
+			expectPreciseGeo: true,
+		},
+		{
+			description:      "Fetch bids denied",
+			allowActivities:  config.AllowActivities{FetchBids: denyAppnexus},
+			expectBidRequest: false,
+		},
+		{
+			description:      "Fetch bids denied for other bidders",
+			allowActivities:  config.AllowActivities{FetchBids: config.Activity{Default: &falseValue, Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{"appnexus"}}, Allow: true}}}},
+			expectBidRequest: true,
+			expectUserFPD:    true,
+			expectUserEids:   true,
+			expectPreciseGeo: true,
+		},
+		{
+			description:      "Transmit user first party data denied",
+			allowActivities:  config.AllowActivities{TransmitUfpd: denyAppnexus},
+			expectBidRequest: true,
+			expectUserFPD:    false,
+			expectUserEids:   true,
+			expectPreciseGeo: true,
+		},
+		{
+			description:      "Transmit eids denied",
+			allowActivities:  config.AllowActivities{TransmitEids: denyAppnexus},
+			expectBidRequest: true,
+			expectUserFPD:    true,
+			expectUserEids:   false,
+			expectPreciseGeo: true,
+		},
+		{
+			description:      "Transmit precise geo denied",
+			allowActivities:  config.AllowActivities{TransmitPreciseGeo: denyAppnexus},
+			expectBidRequest: true,
+			expectUserFPD:    true,
+			expectUserEids:   true,
+			expectPreciseGeo: false,
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.User.Ext = json.RawMessage(`{"eids":[{"source":"anySource","uids":[{"id":"anyId"}]}]}`)
+		req.Device.Geo = &openrtb2.Geo{Lat: 123.456, Lon: 678.89}
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+			Account: config.Account{
+				Privacy: config.AccountPrivacy{AllowActivities: test.allowActivities},
+			},
+		}
+
+		results, _, errs := cleanOpenRTBRequests(
+			context.Background(),
+			auctionReq,
+			nil,
+			map[string]string{},
+			&permissionsMock{allowAllBidders: true, passGeo: true, passID: true},
+			&metrics.MetricsEngineMock{},
+			gdpr.SignalNo,
+			config.Privacy{},
+			nil)
+		assert.Empty(t, errs, test.description+":errs")
+
+		if !test.expectBidRequest {
+			assert.Empty(t, results, test.description+":results")
+			continue
+		}
+		if !assert.Len(t, results, 1, test.description+":results") {
+			continue
+		}
+		result := results[0].BidRequest
+
+		if test.expectUserFPD {
+			assert.Equal(t, "their-id", result.User.BuyerUID, test.description+":User.BuyerUID")
+			assert.Equal(t, "some device ID hash", result.Device.DIDMD5, test.description+":Device.DIDMD5")
+		} else {
+			assert.Empty(t, result.User.BuyerUID, test.description+":User.BuyerUID")
+			assert.Empty(t, result.Device.DIDMD5, test.description+":Device.DIDMD5")
+		}
+
+		if test.expectUserEids {
+			assert.Contains(t, string(result.User.Ext), "eids", test.description+":User.Ext")
+		} else {
+			assert.NotContains(t, string(result.User.Ext), "eids", test.description+":User.Ext")
+		}
+
+		if test.expectPreciseGeo {
+			assert.Equal(t, 123.456, result.Device.Geo.Lat, test.description+":Device.Geo.Lat")
+			assert.Equal(t, "132.173.230.74", result.Device.IP, test.description+":Device.IP")
+		} else {
+			assert.Equal(t, 123.46, result.Device.Geo.Lat, test.description+":Device.Geo.Lat")
+			assert.Equal(t, "132.173.230.0", result.Device.IP, test.description+":Device.IP")
+		}
+	}
+}
+
 func TestCleanOpenRTBRequestsGDPRBlockBidRequest(t *testing.T) {
 	testCases := []struct {
 		description            string
@@ -2228,3 +2570,20 @@ func TestRemoveUnpermissionedEidsEmptyValidations(t *testing.T) {
 		assert.Equal(t, &requestExpected, test.request, test.description+":request")
 	}
 }
+
+func TestIsAdsCertEnabled(t *testing.T) {
+	testCases := []struct {
+		description string
+		experiment  *openrtb_ext.Experiment
+		expected    bool
+	}{
+		{description: "No experiment", experiment: nil, expected: false},
+		{description: "No adscert", experiment: &openrtb_ext.Experiment{}, expected: false},
+		{description: "Adscert disabled", experiment: &openrtb_ext.Experiment{AdsCert: &openrtb_ext.AdsCert{Enabled: false}}, expected: false},
+		{description: "Adscert enabled", experiment: &openrtb_ext.Experiment{AdsCert: &openrtb_ext.AdsCert{Enabled: true}}, expected: true},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, isAdsCertEnabled(test.experiment), test.description)
+	}
+}
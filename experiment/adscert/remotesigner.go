@@ -0,0 +1,99 @@
+package adscert
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// remoteSigner asks a local signatory sidecar to sign the requests. The sidecar holds the origin domain
+// and the private key, and takes care of the key exchange with the counterparties.
+//
+// The requests are sent as POST {url} with a payload like:
+//
+// {"destination_url": "https://bidder.com/bid", "url_hash": "...", "body_hash": "..."}
+//
+// where the hashes are the base64url encoded SHA-256 of the destination url and of the request body.
+// The sidecar should answer with a payload like:
+//
+// {"signature": "from=ssai-serving.tk&from_key=...&invoking=...&nonce=...&status=1&timestamp=...; sigb=...&sigu=..."}
+type remoteSigner struct {
+	client  *http.Client
+	url     string
+	timeout time.Duration
+}
+
+type remoteSignRequest struct {
+	DestinationURL string `json:"destination_url"`
+	URLHash        string `json:"url_hash"`
+	BodyHash       string `json:"body_hash"`
+}
+
+type remoteSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+func newRemoteSigner(remoteConfig config.AdsCertRemote) *remoteSigner {
+	return &remoteSigner{
+		client:  &http.Client{},
+		url:     remoteConfig.Url,
+		timeout: time.Duration(remoteConfig.SigningTimeoutMs) * time.Millisecond,
+	}
+}
+
+func (rs *remoteSigner) Sign(destinationURL string, body []byte) (string, error) {
+	payload, err := json.Marshal(remoteSignRequest{
+		DestinationURL: destinationURL,
+		URLHash:        hash([]byte(destinationURL)),
+		BodyHash:       hash(body),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequest("POST", rs.url, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	ctx, cancel := context.WithTimeout(context.Background(), rs.timeout)
+	defer cancel()
+	httpResp, err := ctxhttp.Do(ctx, rs.client, httpReq)
+	if err != nil {
+		return "", fmt.Errorf("Error signing request via ads.cert signatory: %v", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return "", fmt.Errorf("Error reading ads.cert signatory response: %v", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Error signing request via ads.cert signatory: server responded with status %d", httpResp.StatusCode)
+	}
+
+	var signResponse remoteSignResponse
+	if err := json.Unmarshal(respBody, &signResponse); err != nil {
+		return "", fmt.Errorf("Error parsing ads.cert signatory response: %v", err)
+	}
+	if signResponse.Signature == "" {
+		return "", errors.New("Error signing request via ads.cert signatory: empty signature")
+	}
+	return signResponse.Signature, nil
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
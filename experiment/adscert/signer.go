@@ -0,0 +1,29 @@
+package adscert
+
+import (
+	"github.com/prebid/prebid-server/config"
+)
+
+// SignHeader is the header carrying the ads.cert Authenticated Connections signature of a bidder request
+const SignHeader = "X-Ads-Cert-Auth"
+
+// Signer generates the ads.cert Authenticated Connections signature of a request
+type Signer interface {
+	// Sign returns the X-Ads-Cert-Auth header value for a request sent to destinationURL with the given body.
+	Sign(destinationURL string, body []byte) (string, error)
+}
+
+// NilSigner is used when the host doesn't enable ads.cert. It produces no signature.
+type NilSigner struct{}
+
+func (ns *NilSigner) Sign(destinationURL string, body []byte) (string, error) {
+	return "", nil
+}
+
+// NewAdCertsSigner builds the Signer described by the host configuration, which is expected to be valid.
+func NewAdCertsSigner(experimentAdCertsConfig config.ExperimentAdsCert) Signer {
+	if !experimentAdCertsConfig.Enabled {
+		return &NilSigner{}
+	}
+	return newRemoteSigner(experimentAdCertsConfig.Remote)
+}
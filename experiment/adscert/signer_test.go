@@ -0,0 +1,104 @@
+package adscert
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAdCertsSigner(t *testing.T) {
+	signer := NewAdCertsSigner(config.ExperimentAdsCert{Enabled: false})
+	assert.IsType(t, &NilSigner{}, signer, "Disabled ads.cert should produce a NilSigner")
+
+	signer = NewAdCertsSigner(config.ExperimentAdsCert{
+		Enabled: true,
+		Remote:  config.AdsCertRemote{Url: "http://localhost/sign", SigningTimeoutMs: 10},
+	})
+	if assert.IsType(t, &remoteSigner{}, signer, "Enabled ads.cert should produce a remote signer") {
+		assert.Equal(t, "http://localhost/sign", signer.(*remoteSigner).url)
+		assert.Equal(t, 10*time.Millisecond, signer.(*remoteSigner).timeout)
+	}
+}
+
+func TestNilSigner(t *testing.T) {
+	signature, err := (&NilSigner{}).Sign("http://bidder.com", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Empty(t, signature)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	testCases := []struct {
+		description       string
+		responseStatus    int
+		responseBody      string
+		expectedSignature string
+		expectedErr       string
+	}{
+		{
+			description:       "Signed",
+			responseStatus:    http.StatusOK,
+			responseBody:      `{"signature":"from=prebid.org&from_key=abc; sigb=def&sigu=ghi"}`,
+			expectedSignature: "from=prebid.org&from_key=abc; sigb=def&sigu=ghi",
+		},
+		{
+			description:    "Server error",
+			responseStatus: http.StatusInternalServerError,
+			expectedErr:    "Error signing request via ads.cert signatory: server responded with status 500",
+		},
+		{
+			description:    "Malformed response",
+			responseStatus: http.StatusOK,
+			responseBody:   `{`,
+			expectedErr:    "Error parsing ads.cert signatory response: unexpected end of JSON input",
+		},
+		{
+			description:    "Empty signature",
+			responseStatus: http.StatusOK,
+			responseBody:   `{}`,
+			expectedErr:    "Error signing request via ads.cert signatory: empty signature",
+		},
+	}
+
+	for _, test := range testCases {
+		var received remoteSignRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &received)
+			w.WriteHeader(test.responseStatus)
+			w.Write([]byte(test.responseBody))
+		}))
+
+		signer := newRemoteSigner(config.AdsCertRemote{Url: server.URL, SigningTimeoutMs: 1000})
+		signature, err := signer.Sign("https://bidder.com/bid", []byte(`{"id":"req"}`))
+		server.Close()
+
+		assert.Equal(t, remoteSignRequest{
+			DestinationURL: "https://bidder.com/bid",
+			URLHash:        hash([]byte("https://bidder.com/bid")),
+			BodyHash:       hash([]byte(`{"id":"req"}`)),
+		}, received, test.description)
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+		assert.Equal(t, test.expectedSignature, signature, test.description)
+	}
+}
+
+func TestRemoteSignerTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	signer := newRemoteSigner(config.AdsCertRemote{Url: server.URL, SigningTimeoutMs: 1})
+	_, err := signer.Sign("https://bidder.com/bid", nil)
+	assert.Error(t, err)
+}
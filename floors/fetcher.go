@@ -0,0 +1,130 @@
+package floors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// FloorFetcher retrieves an account's price floors data from a remote floors provider.
+type FloorFetcher interface {
+	// Fetch returns the floors data available for the given fetch settings along with the fetch status.
+	// Implementations must not block the auction while the data is being downloaded.
+	Fetch(fetchConfig config.AccountFloorFetch) (*openrtb_ext.PriceFloorData, string)
+}
+
+type fetchedFloors struct {
+	data      *openrtb_ext.PriceFloorData
+	fetchedAt time.Time
+}
+
+// priceFloorFetcher downloads floors data in the background and keeps the latest copy of each URL in memory.
+// A copy is refreshed once it's older than the fetch period, and dropped once it's older than the max age.
+type priceFloorFetcher struct {
+	client   *http.Client
+	now      func() time.Time
+	mutex    sync.Mutex
+	floors   map[string]fetchedFloors
+	inFlight map[string]struct{}
+}
+
+// NewPriceFloorFetcher returns a FloorFetcher which uses the given client to download floors data.
+func NewPriceFloorFetcher(client *http.Client) FloorFetcher {
+	return &priceFloorFetcher{
+		client:   client,
+		now:      time.Now,
+		floors:   make(map[string]fetchedFloors),
+		inFlight: make(map[string]struct{}),
+	}
+}
+
+func (f *priceFloorFetcher) Fetch(fetchConfig config.AccountFloorFetch) (*openrtb_ext.PriceFloorData, string) {
+	if !fetchConfig.Enabled || fetchConfig.URL == "" {
+		return nil, openrtb_ext.FetchStatusNone
+	}
+
+	f.mutex.Lock()
+	now := f.now()
+	floors, found := f.floors[fetchConfig.URL]
+	if found && fetchConfig.MaxAge > 0 && now.Sub(floors.fetchedAt) > time.Duration(fetchConfig.MaxAge)*time.Second {
+		delete(f.floors, fetchConfig.URL)
+		found = false
+	}
+	refreshDue := !found || (fetchConfig.Period > 0 && now.Sub(floors.fetchedAt) > time.Duration(fetchConfig.Period)*time.Second)
+	_, fetching := f.inFlight[fetchConfig.URL]
+	startFetch := refreshDue && !fetching
+	if startFetch {
+		f.inFlight[fetchConfig.URL] = struct{}{}
+	}
+	f.mutex.Unlock()
+
+	if startFetch {
+		go f.fetchAndStore(fetchConfig)
+	}
+
+	if found {
+		return floors.data, openrtb_ext.FetchStatusSuccess
+	}
+	return nil, openrtb_ext.FetchStatusInProgress
+}
+
+func (f *priceFloorFetcher) fetchAndStore(fetchConfig config.AccountFloorFetch) {
+	data, err := f.fetch(fetchConfig)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.inFlight, fetchConfig.URL)
+	if err != nil {
+		glog.Errorf("Error fetching price floors from %s: %v", fetchConfig.URL, err)
+		return
+	}
+	f.floors[fetchConfig.URL] = fetchedFloors{data: data, fetchedAt: f.now()}
+}
+
+func (f *priceFloorFetcher) fetch(fetchConfig config.AccountFloorFetch) (*openrtb_ext.PriceFloorData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(fetchConfig.Timeout)*time.Millisecond)
+	defer cancel()
+
+	httpResp, err := ctxhttp.Get(ctx, f.client, fetchConfig.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %d", httpResp.StatusCode)
+	}
+
+	var body io.Reader = httpResp.Body
+	maxSize := int64(fetchConfig.MaxFileSize) * 1024
+	if maxSize > 0 {
+		body = io.LimitReader(httpResp.Body, maxSize+1)
+	}
+	respBytes, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && int64(len(respBytes)) > maxSize {
+		return nil, fmt.Errorf("floors file exceeds the maximum size of %d KB", fetchConfig.MaxFileSize)
+	}
+
+	var data openrtb_ext.PriceFloorData
+	if err := json.Unmarshal(respBytes, &data); err != nil {
+		return nil, err
+	}
+	validData, errs := validateFloorData(&data)
+	if validData == nil {
+		return nil, fmt.Errorf("invalid floors data: %v", errs)
+	}
+	return validData, nil
+}
@@ -0,0 +1,134 @@
+package floors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+const validFloorsFile = `{"currency":"USD","modelgroups":[{"schema":{"fields":["mediaType"]},"values":{"banner":1.5}}]}`
+
+func newFloorsServer(body string, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Write([]byte(body))
+	}))
+}
+
+func fetchUntilDone(fetcher FloorFetcher, fetchConfig config.AccountFloorFetch) (*openrtb_ext.PriceFloorData, string) {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, status := fetcher.Fetch(fetchConfig)
+		if status != openrtb_ext.FetchStatusInProgress || time.Now().After(deadline) {
+			return data, status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func waitForFetches(fetcher *priceFloorFetcher) {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		fetcher.mutex.Lock()
+		inFlight := len(fetcher.inFlight)
+		fetcher.mutex.Unlock()
+		if inFlight == 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFetchSuccess(t *testing.T) {
+	var requests int32
+	server := newFloorsServer(validFloorsFile, &requests)
+	defer server.Close()
+
+	fetcher := NewPriceFloorFetcher(server.Client())
+	fetchConfig := config.AccountFloorFetch{Enabled: true, URL: server.URL, Timeout: 1000, MaxFileSize: 10, MaxAge: 600, Period: 300}
+
+	data, status := fetcher.Fetch(fetchConfig)
+	assert.Nil(t, data)
+	assert.Equal(t, openrtb_ext.FetchStatusInProgress, status)
+
+	data, status = fetchUntilDone(fetcher, fetchConfig)
+	assert.Equal(t, openrtb_ext.FetchStatusSuccess, status)
+	if assert.NotNil(t, data) {
+		assert.Equal(t, map[string]float64{"banner": 1.5}, data.ModelGroups[0].Values)
+	}
+
+	fetcher.Fetch(fetchConfig)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "floors should be served from memory until the period elapses")
+}
+
+func TestFetchRefreshAndExpiry(t *testing.T) {
+	var requests int32
+	server := newFloorsServer(validFloorsFile, &requests)
+	defer server.Close()
+
+	now := time.Now()
+	fetcher := NewPriceFloorFetcher(server.Client()).(*priceFloorFetcher)
+	fetcher.now = func() time.Time { return now }
+	fetchConfig := config.AccountFloorFetch{Enabled: true, URL: server.URL, Timeout: 1000, MaxAge: 600, Period: 300}
+
+	_, status := fetchUntilDone(fetcher, fetchConfig)
+	assert.Equal(t, openrtb_ext.FetchStatusSuccess, status)
+
+	// Past the period, the stale floors are still served while they are refreshed
+	now = now.Add(400 * time.Second)
+	data, status := fetcher.Fetch(fetchConfig)
+	assert.NotNil(t, data)
+	assert.Equal(t, openrtb_ext.FetchStatusSuccess, status)
+
+	// Past the max age, the floors are dropped
+	waitForFetches(fetcher)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	now = now.Add(700 * time.Second)
+	data, status = fetcher.Fetch(fetchConfig)
+	assert.Nil(t, data)
+	assert.Equal(t, openrtb_ext.FetchStatusInProgress, status)
+	waitForFetches(fetcher)
+}
+
+func TestFetchErrors(t *testing.T) {
+	testCases := []struct {
+		description string
+		body        string
+		maxFileSize int
+	}{
+		{description: "Malformed JSON", body: `{"modelgroups":`},
+		{description: "Invalid floors data", body: `{"modelgroups":[]}`},
+		{description: "File too large", body: `{"floorprovider":"` + strings.Repeat("a", 2048) + `"}`, maxFileSize: 1},
+	}
+
+	for _, test := range testCases {
+		var requests int32
+		server := newFloorsServer(test.body, &requests)
+
+		fetcher := NewPriceFloorFetcher(server.Client()).(*priceFloorFetcher)
+		fetchConfig := config.AccountFloorFetch{Enabled: true, URL: server.URL, Timeout: 1000, MaxFileSize: test.maxFileSize}
+		_, err := fetcher.fetch(fetchConfig)
+		assert.Error(t, err, test.description)
+
+		server.Close()
+	}
+}
+
+func TestFetchDisabled(t *testing.T) {
+	fetcher := NewPriceFloorFetcher(http.DefaultClient)
+
+	data, status := fetcher.Fetch(config.AccountFloorFetch{Enabled: false, URL: "http://floors.com"})
+	assert.Nil(t, data)
+	assert.Equal(t, openrtb_ext.FetchStatusNone, status)
+
+	data, status = fetcher.Fetch(config.AccountFloorFetch{Enabled: true})
+	assert.Nil(t, data)
+	assert.Equal(t, openrtb_ext.FetchStatusNone, status)
+}
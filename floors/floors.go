@@ -0,0 +1,271 @@
+package floors
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const (
+	defaultCurrency    string = "USD"
+	defaultModelWeight int    = 1
+	floorPrecision     int    = 4
+)
+
+// Enforcement describes whether and how the resolved floors should be enforced on the bids of an auction.
+type Enforcement struct {
+	Enabled    bool
+	FloorDeals bool
+}
+
+// EnrichWithPriceFloors resolves the floors data of the auction from the fetched floors, the request or the
+// account, in that order of precedence. It sets bidfloor and bidfloorcur of each imp to the floor matching
+// the imp, and replaces requestExt.Prebid.Floors with the resolved floors so they are passed on to the bidders.
+//
+// The returned errors are warnings; the auction should carry on with whatever floors could be resolved.
+func EnrichWithPriceFloors(bidRequest *openrtb2.BidRequest, requestExt *openrtb_ext.ExtRequest, account config.Account, conversions currency.Conversions, fetcher FloorFetcher) []error {
+	if bidRequest == nil || requestExt == nil || !account.PriceFloors.Enabled {
+		return nil
+	}
+	if requestFloors := requestExt.Prebid.Floors; requestFloors != nil && requestFloors.Enabled != nil && !*requestFloors.Enabled {
+		return nil
+	}
+
+	floors, errs := resolveFloors(account.PriceFloors, requestExt.Prebid.Floors, fetcher)
+	requestExt.Prebid.Floors = floors
+
+	if floors.Data == nil {
+		if floors.FloorMin > 0 {
+			errs = append(errs, applyFloorMin(bidRequest, floors, conversions)...)
+		}
+		return toWarnings(errs)
+	}
+
+	modelGroup := selectModelGroup(floors.Data.ModelGroups, rand.Intn)
+	floors.Data.ModelGroups = []openrtb_ext.PriceFloorModelGroup{modelGroup}
+
+	skipped := shouldSkip(floors, modelGroup, rand.Intn)
+	floors.Skipped = &skipped
+	if skipped {
+		return toWarnings(errs)
+	}
+
+	errs = append(errs, applyModelGroup(bidRequest, floors, modelGroup, conversions)...)
+	return toWarnings(errs)
+}
+
+// GetEnforcement decides whether the floors resolved for an auction should be enforced by Prebid Server.
+func GetEnforcement(floors *openrtb_ext.PriceFloorRules, account config.AccountPriceFloors) Enforcement {
+	return getEnforcement(floors, account, rand.Intn)
+}
+
+func getEnforcement(floors *openrtb_ext.PriceFloorRules, account config.AccountPriceFloors, randomInt func(int) int) Enforcement {
+	if floors == nil || (floors.Skipped != nil && *floors.Skipped) {
+		return Enforcement{}
+	}
+	if !floors.Enforcement.GetEnforcePBS() {
+		return Enforcement{}
+	}
+
+	enforceRate := account.EnforceFloorsRate
+	if floors.Enforcement != nil && floors.Enforcement.EnforceRate > 0 {
+		enforceRate = floors.Enforcement.EnforceRate
+	}
+	if randomInt(100) >= enforceRate {
+		return Enforcement{}
+	}
+
+	floorDeals := account.EnforceDealFloors
+	if requestFloorDeals := floors.Enforcement.GetFloorDeals(); requestFloorDeals != nil {
+		floorDeals = *requestFloorDeals
+	}
+	return Enforcement{Enabled: true, FloorDeals: floorDeals}
+}
+
+// resolveFloors picks the floors data for the auction. The request's top-level settings such as floormin
+// and enforcement are kept regardless of where the data is taken from.
+func resolveFloors(account config.AccountPriceFloors, requestFloors *openrtb_ext.PriceFloorRules, fetcher FloorFetcher) (*openrtb_ext.PriceFloorRules, []error) {
+	var errs []error
+	floors := &openrtb_ext.PriceFloorRules{}
+	if requestFloors != nil {
+		if err := validateFloorRules(requestFloors); err != nil {
+			errs = append(errs, err)
+		} else {
+			*floors = *requestFloors
+		}
+	}
+	floors.Data = nil
+	floors.Skipped = nil
+	floors.FetchStatus = openrtb_ext.FetchStatusNone
+	floors.PriceFloorLocation = openrtb_ext.FloorLocationNoData
+
+	if fetcher != nil && account.UseDynamicData && account.Fetch.Enabled {
+		fetchedData, fetchStatus := fetcher.Fetch(account.Fetch)
+		floors.FetchStatus = fetchStatus
+		if fetchedData != nil && fetchStatus == openrtb_ext.FetchStatusSuccess {
+			if data, dataErrs := validateFloorData(fetchedData); data != nil {
+				floors.Data = data
+				floors.PriceFloorLocation = openrtb_ext.FloorLocationFetch
+				return floors, append(errs, dataErrs...)
+			} else {
+				errs = append(errs, dataErrs...)
+			}
+		}
+	}
+
+	if requestFloors != nil && requestFloors.Data != nil {
+		if data, dataErrs := validateFloorData(requestFloors.Data); data != nil {
+			floors.Data = data
+			floors.PriceFloorLocation = openrtb_ext.FloorLocationRequest
+			return floors, append(errs, dataErrs...)
+		} else {
+			errs = append(errs, dataErrs...)
+		}
+	}
+
+	if account.Data != nil {
+		if data, dataErrs := validateFloorData(account.Data); data != nil {
+			floors.Data = data
+			floors.PriceFloorLocation = openrtb_ext.FloorLocationAccount
+			return floors, append(errs, dataErrs...)
+		} else {
+			errs = append(errs, dataErrs...)
+		}
+	}
+
+	return floors, errs
+}
+
+// selectModelGroup picks one of the model groups at random, weighted by their model weight.
+func selectModelGroup(groups []openrtb_ext.PriceFloorModelGroup, randomInt func(int) int) openrtb_ext.PriceFloorModelGroup {
+	if len(groups) == 1 {
+		return groups[0]
+	}
+
+	totalWeight := 0
+	for _, group := range groups {
+		totalWeight += getModelWeight(group)
+	}
+
+	selector := randomInt(totalWeight)
+	for _, group := range groups {
+		selector -= getModelWeight(group)
+		if selector < 0 {
+			return group
+		}
+	}
+	return groups[len(groups)-1]
+}
+
+func getModelWeight(group openrtb_ext.PriceFloorModelGroup) int {
+	if group.ModelWeight == nil {
+		return defaultModelWeight
+	}
+	return *group.ModelWeight
+}
+
+// shouldSkip decides whether floors are skipped for this auction, using the most specific skip rate defined.
+func shouldSkip(floors *openrtb_ext.PriceFloorRules, group openrtb_ext.PriceFloorModelGroup, randomInt func(int) int) bool {
+	skipRate := floors.SkipRate
+	if floors.Data.SkipRate > 0 {
+		skipRate = floors.Data.SkipRate
+	}
+	if group.SkipRate > 0 {
+		skipRate = group.SkipRate
+	}
+	return skipRate > 0 && randomInt(100) < skipRate
+}
+
+func applyModelGroup(bidRequest *openrtb2.BidRequest, floors *openrtb_ext.PriceFloorRules, group openrtb_ext.PriceFloorModelGroup, conversions currency.Conversions) []error {
+	var errs []error
+	floorCur := getModelGroupCurrency(floors.Data, group)
+	floorMin, err := getFloorMin(floors, floorCur, conversions)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	delimiter := getDelimiter(group.Schema)
+	masks := wildcardMasks(len(group.Schema.Fields))
+	for i := range bidRequest.Imp {
+		imp := &bidRequest.Imp[i]
+		floorValue := group.Default
+		desiredValues := createRuleKey(group.Schema.Fields, bidRequest, imp)
+		if rule, found := findRule(group.Values, delimiter, desiredValues, masks); found {
+			floorValue = group.Values[rule]
+		}
+		floorValue = math.Max(floorValue, floorMin)
+		if floorValue > 0 {
+			imp.BidFloor = roundFloor(floorValue)
+			imp.BidFloorCur = floorCur
+		}
+	}
+	return errs
+}
+
+// applyFloorMin raises the floor of each imp to floormin when the request has floormin set but no floors data.
+func applyFloorMin(bidRequest *openrtb2.BidRequest, floors *openrtb_ext.PriceFloorRules, conversions currency.Conversions) []error {
+	for i := range bidRequest.Imp {
+		imp := &bidRequest.Imp[i]
+		impCur := imp.BidFloorCur
+		if impCur == "" {
+			impCur = defaultCurrency
+		}
+		floorMin, err := getFloorMin(floors, impCur, conversions)
+		if err != nil {
+			return []error{err}
+		}
+		if floorMin > imp.BidFloor {
+			imp.BidFloor = roundFloor(floorMin)
+			imp.BidFloorCur = impCur
+		}
+	}
+	return nil
+}
+
+// getFloorMin returns floormin converted to the given currency, or 0 if floormin isn't set.
+func getFloorMin(floors *openrtb_ext.PriceFloorRules, toCur string, conversions currency.Conversions) (float64, error) {
+	if floors.FloorMin <= 0 {
+		return 0, nil
+	}
+	fromCur := floors.FloorMinCur
+	if fromCur == "" {
+		fromCur = toCur
+	}
+	rate, err := conversions.GetRate(fromCur, toCur)
+	if err != nil {
+		return 0, fmt.Errorf("unable to convert floormin from %s to %s: %v", fromCur, toCur, err)
+	}
+	return floors.FloorMin * rate, nil
+}
+
+func getModelGroupCurrency(data *openrtb_ext.PriceFloorData, group openrtb_ext.PriceFloorModelGroup) string {
+	if group.Currency != "" {
+		return group.Currency
+	}
+	if data.Currency != "" {
+		return data.Currency
+	}
+	return defaultCurrency
+}
+
+func roundFloor(value float64) float64 {
+	scale := math.Pow10(floorPrecision)
+	return math.Round(value*scale) / scale
+}
+
+func toWarnings(errs []error) []error {
+	warnings := make([]error, 0, len(errs))
+	for _, err := range errs {
+		warnings = append(warnings, &errortypes.Warning{
+			WarningCode: errortypes.FloorsWarningCode,
+			Message:     fmt.Sprintf("price floors: %v", err),
+		})
+	}
+	return warnings
+}
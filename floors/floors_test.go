@@ -0,0 +1,471 @@
+package floors
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockFetcher struct {
+	data   *openrtb_ext.PriceFloorData
+	status string
+}
+
+func (f *mockFetcher) Fetch(fetchConfig config.AccountFloorFetch) (*openrtb_ext.PriceFloorData, string) {
+	return f.data, f.status
+}
+
+func newFloorData(currency string, values map[string]float64, defaultFloor float64) *openrtb_ext.PriceFloorData {
+	return &openrtb_ext.PriceFloorData{
+		Currency: currency,
+		ModelGroups: []openrtb_ext.PriceFloorModelGroup{{
+			Schema:  openrtb_ext.PriceFloorSchema{Fields: []string{MediaType, Size}},
+			Values:  values,
+			Default: defaultFloor,
+		}},
+	}
+}
+
+func TestEnrichWithPriceFloors(t *testing.T) {
+	trueValue, falseValue := true, false
+	conversions := currency.NewRates(map[string]map[string]float64{"USD": {"EUR": 0.5}})
+	accountFloors := config.AccountPriceFloors{Enabled: true}
+
+	testCases := []struct {
+		description       string
+		requestFloors     *openrtb_ext.PriceFloorRules
+		account           config.AccountPriceFloors
+		fetcher           FloorFetcher
+		imps              []openrtb2.Imp
+		expectedFloors    []float64
+		expectedCurs      []string
+		expectedLocation  string
+		expectedSkipped   *bool
+		expectedWarnCount int
+	}{
+		{
+			description: "Request floors applied",
+			requestFloors: &openrtb_ext.PriceFloorRules{
+				Data: newFloorData("USD", map[string]float64{"banner|300x250": 1.5, "banner|*": 1}, 0.1),
+			},
+			account: accountFloors,
+			imps: []openrtb2.Imp{
+				{ID: "1", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}},
+				{ID: "2", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 600}}}},
+				{ID: "3", Video: &openrtb2.Video{W: 640, H: 480}},
+			},
+			expectedFloors:   []float64{1.5, 1, 0.1},
+			expectedCurs:     []string{"USD", "USD", "USD"},
+			expectedLocation: openrtb_ext.FloorLocationRequest,
+			expectedSkipped:  &falseValue,
+		},
+		{
+			description: "Floormin in another currency raises the floor",
+			requestFloors: &openrtb_ext.PriceFloorRules{
+				FloorMin:    4,
+				FloorMinCur: "USD",
+				Data:        newFloorData("EUR", map[string]float64{"banner|*": 1}, 0),
+			},
+			account: accountFloors,
+			imps: []openrtb2.Imp{
+				{ID: "1", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}},
+			},
+			expectedFloors:   []float64{2},
+			expectedCurs:     []string{"EUR"},
+			expectedLocation: openrtb_ext.FloorLocationRequest,
+			expectedSkipped:  &falseValue,
+		},
+		{
+			description: "Imp floor kept when no rule matches and no default is set",
+			requestFloors: &openrtb_ext.PriceFloorRules{
+				Data: newFloorData("USD", map[string]float64{"video|*": 1}, 0),
+			},
+			account: accountFloors,
+			imps: []openrtb2.Imp{
+				{ID: "1", BidFloor: 0.7, BidFloorCur: "EUR", Banner: &openrtb2.Banner{}},
+			},
+			expectedFloors:   []float64{0.7},
+			expectedCurs:     []string{"EUR"},
+			expectedLocation: openrtb_ext.FloorLocationRequest,
+			expectedSkipped:  &falseValue,
+		},
+		{
+			description: "Account floors used when the request has none",
+			account: config.AccountPriceFloors{
+				Enabled: true,
+				Data:    newFloorData("USD", map[string]float64{"*|*": 3}, 0),
+			},
+			imps:             []openrtb2.Imp{{ID: "1", Banner: &openrtb2.Banner{}}},
+			expectedFloors:   []float64{3},
+			expectedCurs:     []string{"USD"},
+			expectedLocation: openrtb_ext.FloorLocationAccount,
+			expectedSkipped:  &falseValue,
+		},
+		{
+			description: "Fetched floors take precedence over request floors",
+			requestFloors: &openrtb_ext.PriceFloorRules{
+				Data: newFloorData("USD", map[string]float64{"*|*": 1}, 0),
+			},
+			account: config.AccountPriceFloors{
+				Enabled:        true,
+				UseDynamicData: true,
+				Fetch:          config.AccountFloorFetch{Enabled: true, URL: "http://floors.com"},
+			},
+			fetcher: &mockFetcher{
+				data:   newFloorData("USD", map[string]float64{"*|*": 5}, 0),
+				status: openrtb_ext.FetchStatusSuccess,
+			},
+			imps:             []openrtb2.Imp{{ID: "1", Banner: &openrtb2.Banner{}}},
+			expectedFloors:   []float64{5},
+			expectedCurs:     []string{"USD"},
+			expectedLocation: openrtb_ext.FloorLocationFetch,
+			expectedSkipped:  &falseValue,
+		},
+		{
+			description: "Request floors used while fetch is in progress",
+			requestFloors: &openrtb_ext.PriceFloorRules{
+				Data: newFloorData("USD", map[string]float64{"*|*": 1}, 0),
+			},
+			account: config.AccountPriceFloors{
+				Enabled:        true,
+				UseDynamicData: true,
+				Fetch:          config.AccountFloorFetch{Enabled: true, URL: "http://floors.com"},
+			},
+			fetcher:          &mockFetcher{status: openrtb_ext.FetchStatusInProgress},
+			imps:             []openrtb2.Imp{{ID: "1", Banner: &openrtb2.Banner{}}},
+			expectedFloors:   []float64{1},
+			expectedCurs:     []string{"USD"},
+			expectedLocation: openrtb_ext.FloorLocationRequest,
+			expectedSkipped:  &falseValue,
+		},
+		{
+			description: "Floors skipped",
+			requestFloors: &openrtb_ext.PriceFloorRules{
+				SkipRate: 100,
+				Data:     newFloorData("USD", map[string]float64{"*|*": 1}, 0),
+			},
+			account:          accountFloors,
+			imps:             []openrtb2.Imp{{ID: "1", Banner: &openrtb2.Banner{}}},
+			expectedFloors:   []float64{0},
+			expectedCurs:     []string{""},
+			expectedLocation: openrtb_ext.FloorLocationRequest,
+			expectedSkipped:  &trueValue,
+		},
+		{
+			description: "Invalid request floors data",
+			requestFloors: &openrtb_ext.PriceFloorRules{
+				Data: &openrtb_ext.PriceFloorData{ModelGroups: []openrtb_ext.PriceFloorModelGroup{{
+					Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"unknown"}},
+					Values: map[string]float64{"*": 1},
+				}}},
+			},
+			account:           accountFloors,
+			imps:              []openrtb2.Imp{{ID: "1", Banner: &openrtb2.Banner{}}},
+			expectedFloors:    []float64{0},
+			expectedCurs:      []string{""},
+			expectedLocation:  openrtb_ext.FloorLocationNoData,
+			expectedWarnCount: 2,
+		},
+		{
+			description: "Floormin applied without floors data",
+			requestFloors: &openrtb_ext.PriceFloorRules{
+				FloorMin:    1,
+				FloorMinCur: "USD",
+			},
+			account: accountFloors,
+			imps: []openrtb2.Imp{
+				{ID: "1", Banner: &openrtb2.Banner{}},
+				{ID: "2", BidFloor: 0.2, BidFloorCur: "EUR", Banner: &openrtb2.Banner{}},
+				{ID: "3", BidFloor: 3, Banner: &openrtb2.Banner{}},
+			},
+			expectedFloors:   []float64{1, 0.5, 3},
+			expectedCurs:     []string{"USD", "EUR", ""},
+			expectedLocation: openrtb_ext.FloorLocationNoData,
+		},
+	}
+
+	for _, test := range testCases {
+		bidRequest := &openrtb2.BidRequest{ID: "request", Imp: test.imps}
+		requestExt := &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{Floors: test.requestFloors}}
+		account := config.Account{PriceFloors: test.account}
+
+		errs := EnrichWithPriceFloors(bidRequest, requestExt, account, conversions, test.fetcher)
+
+		assert.Len(t, errs, test.expectedWarnCount, test.description)
+		for i, imp := range bidRequest.Imp {
+			assert.Equal(t, test.expectedFloors[i], imp.BidFloor, test.description)
+			assert.Equal(t, test.expectedCurs[i], imp.BidFloorCur, test.description)
+		}
+		if assert.NotNil(t, requestExt.Prebid.Floors, test.description) {
+			assert.Equal(t, test.expectedLocation, requestExt.Prebid.Floors.PriceFloorLocation, test.description)
+			assert.Equal(t, test.expectedSkipped, requestExt.Prebid.Floors.Skipped, test.description)
+		}
+	}
+}
+
+func TestEnrichWithPriceFloorsDisabled(t *testing.T) {
+	falseValue := false
+	requestData := newFloorData("USD", map[string]float64{"*|*": 1}, 0)
+
+	testCases := []struct {
+		description   string
+		requestFloors *openrtb_ext.PriceFloorRules
+		account       config.AccountPriceFloors
+	}{
+		{
+			description:   "Disabled for account",
+			requestFloors: &openrtb_ext.PriceFloorRules{Data: requestData},
+			account:       config.AccountPriceFloors{Enabled: false},
+		},
+		{
+			description:   "Disabled by request",
+			requestFloors: &openrtb_ext.PriceFloorRules{Enabled: &falseValue, Data: requestData},
+			account:       config.AccountPriceFloors{Enabled: true},
+		},
+	}
+
+	for _, test := range testCases {
+		bidRequest := &openrtb2.BidRequest{ID: "request", Imp: []openrtb2.Imp{{ID: "1", Banner: &openrtb2.Banner{}}}}
+		requestExt := &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{Floors: test.requestFloors}}
+
+		errs := EnrichWithPriceFloors(bidRequest, requestExt, config.Account{PriceFloors: test.account}, currency.NewConstantRates(), nil)
+
+		assert.Empty(t, errs, test.description)
+		assert.Equal(t, 0.0, bidRequest.Imp[0].BidFloor, test.description)
+		assert.Equal(t, test.requestFloors, requestExt.Prebid.Floors, test.description)
+	}
+}
+
+func TestEnrichWithPriceFloorsLeavesAccountDataUnchanged(t *testing.T) {
+	accountData := &openrtb_ext.PriceFloorData{
+		ModelGroups: []openrtb_ext.PriceFloorModelGroup{
+			{Schema: openrtb_ext.PriceFloorSchema{Fields: []string{MediaType}}, Values: map[string]float64{"Banner": 1}},
+			{Schema: openrtb_ext.PriceFloorSchema{Fields: []string{MediaType}}, Values: map[string]float64{"Banner": 2}},
+		},
+	}
+	accountDataJSON, _ := json.Marshal(accountData)
+	account := config.Account{PriceFloors: config.AccountPriceFloors{Enabled: true, Data: accountData}}
+
+	bidRequest := &openrtb2.BidRequest{ID: "request", Imp: []openrtb2.Imp{{ID: "1", Banner: &openrtb2.Banner{}}}}
+	requestExt := &openrtb_ext.ExtRequest{}
+	EnrichWithPriceFloors(bidRequest, requestExt, account, currency.NewConstantRates(), nil)
+
+	afterJSON, _ := json.Marshal(accountData)
+	assert.JSONEq(t, string(accountDataJSON), string(afterJSON))
+	assert.Len(t, requestExt.Prebid.Floors.Data.ModelGroups, 1)
+	assert.Contains(t, []float64{1, 2}, bidRequest.Imp[0].BidFloor)
+}
+
+func TestGetEnforcement(t *testing.T) {
+	trueValue, falseValue := true, false
+	alwaysLow := func(int) int { return 0 }
+	alwaysHigh := func(n int) int { return n - 1 }
+
+	testCases := []struct {
+		description string
+		floors      *openrtb_ext.PriceFloorRules
+		account     config.AccountPriceFloors
+		randomInt   func(int) int
+		expected    Enforcement
+	}{
+		{
+			description: "No floors",
+			floors:      nil,
+			account:     config.AccountPriceFloors{EnforceFloorsRate: 100},
+			randomInt:   alwaysLow,
+			expected:    Enforcement{},
+		},
+		{
+			description: "Floors skipped",
+			floors:      &openrtb_ext.PriceFloorRules{Skipped: &trueValue},
+			account:     config.AccountPriceFloors{EnforceFloorsRate: 100},
+			randomInt:   alwaysLow,
+			expected:    Enforcement{},
+		},
+		{
+			description: "Enforcement turned off by request",
+			floors:      &openrtb_ext.PriceFloorRules{Enforcement: &openrtb_ext.PriceFloorEnforcement{EnforcePBS: &falseValue}},
+			account:     config.AccountPriceFloors{EnforceFloorsRate: 100},
+			randomInt:   alwaysLow,
+			expected:    Enforcement{},
+		},
+		{
+			description: "Enforced with account deal setting",
+			floors:      &openrtb_ext.PriceFloorRules{Skipped: &falseValue},
+			account:     config.AccountPriceFloors{EnforceFloorsRate: 100, EnforceDealFloors: true},
+			randomInt:   alwaysHigh,
+			expected:    Enforcement{Enabled: true, FloorDeals: true},
+		},
+		{
+			description: "Request deal setting overrides account",
+			floors:      &openrtb_ext.PriceFloorRules{Enforcement: &openrtb_ext.PriceFloorEnforcement{FloorDeals: &falseValue}},
+			account:     config.AccountPriceFloors{EnforceFloorsRate: 100, EnforceDealFloors: true},
+			randomInt:   alwaysLow,
+			expected:    Enforcement{Enabled: true, FloorDeals: false},
+		},
+		{
+			description: "Not enforced due to account enforce rate",
+			floors:      &openrtb_ext.PriceFloorRules{},
+			account:     config.AccountPriceFloors{EnforceFloorsRate: 50},
+			randomInt:   alwaysHigh,
+			expected:    Enforcement{},
+		},
+		{
+			description: "Request enforce rate overrides account",
+			floors:      &openrtb_ext.PriceFloorRules{Enforcement: &openrtb_ext.PriceFloorEnforcement{EnforceRate: 100}},
+			account:     config.AccountPriceFloors{EnforceFloorsRate: 50},
+			randomInt:   alwaysHigh,
+			expected:    Enforcement{Enabled: true},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, getEnforcement(test.floors, test.account, test.randomInt), test.description)
+	}
+}
+
+func TestSelectModelGroup(t *testing.T) {
+	weight10, weight30 := 10, 30
+	groups := []openrtb_ext.PriceFloorModelGroup{
+		{ModelVersion: "a", ModelWeight: &weight10},
+		{ModelVersion: "b", ModelWeight: &weight30},
+		{ModelVersion: "c"},
+	}
+
+	testCases := []struct {
+		description     string
+		randomValue     int
+		expectedVersion string
+	}{
+		{description: "First group", randomValue: 9, expectedVersion: "a"},
+		{description: "Second group", randomValue: 10, expectedVersion: "b"},
+		{description: "Third group with default weight", randomValue: 40, expectedVersion: "c"},
+	}
+
+	for _, test := range testCases {
+		randomInt := func(n int) int {
+			assert.Equal(t, 41, n, test.description)
+			return test.randomValue
+		}
+		assert.Equal(t, test.expectedVersion, selectModelGroup(groups, randomInt).ModelVersion, test.description)
+	}
+}
+
+func TestShouldSkip(t *testing.T) {
+	testCases := []struct {
+		description   string
+		rulesSkipRate int
+		dataSkipRate  int
+		groupSkipRate int
+		randomValue   int
+		expected      bool
+	}{
+		{description: "No skip rate", randomValue: 0, expected: false},
+		{description: "Rules skip rate", rulesSkipRate: 50, randomValue: 49, expected: true},
+		{description: "Data skip rate overrides rules", rulesSkipRate: 50, dataSkipRate: 10, randomValue: 20, expected: false},
+		{description: "Group skip rate overrides data", dataSkipRate: 10, groupSkipRate: 30, randomValue: 20, expected: true},
+	}
+
+	for _, test := range testCases {
+		floors := &openrtb_ext.PriceFloorRules{SkipRate: test.rulesSkipRate, Data: &openrtb_ext.PriceFloorData{SkipRate: test.dataSkipRate}}
+		group := openrtb_ext.PriceFloorModelGroup{SkipRate: test.groupSkipRate}
+		randomInt := func(int) int { return test.randomValue }
+		assert.Equal(t, test.expected, shouldSkip(floors, group, randomInt), test.description)
+	}
+}
+
+func TestValidateFloorData(t *testing.T) {
+	weight0 := 0
+	repeatedFields := make([]string, 40)
+	repeatedRule := make([]string, 40)
+	for i := range repeatedFields {
+		repeatedFields[i] = MediaType
+		repeatedRule[i] = "banner"
+	}
+
+	testCases := []struct {
+		description      string
+		data             *openrtb_ext.PriceFloorData
+		expectValid      bool
+		expectedGroups   int
+		expectedRules    map[string]float64
+		expectedErrCount int
+	}{
+		{
+			description:    "Valid data with keys lower-cased",
+			data:           newFloorData("USD", map[string]float64{"Banner|300X250": 1}, 0),
+			expectValid:    true,
+			expectedGroups: 1,
+			expectedRules:  map[string]float64{"banner|300x250": 1},
+		},
+		{
+			description:      "Invalid rules dropped",
+			data:             newFloorData("USD", map[string]float64{"banner|300x250": 1, "banner": 2, "video|*": -1}, 0),
+			expectValid:      true,
+			expectedGroups:   1,
+			expectedRules:    map[string]float64{"banner|300x250": 1},
+			expectedErrCount: 2,
+		},
+		{
+			description:      "Invalid data skip rate",
+			data:             &openrtb_ext.PriceFloorData{SkipRate: 101},
+			expectValid:      false,
+			expectedErrCount: 1,
+		},
+		{
+			description:      "No model groups",
+			data:             &openrtb_ext.PriceFloorData{},
+			expectValid:      false,
+			expectedErrCount: 1,
+		},
+		{
+			description: "Invalid model group dropped",
+			data: &openrtb_ext.PriceFloorData{ModelGroups: []openrtb_ext.PriceFloorModelGroup{
+				{ModelWeight: &weight0, Schema: openrtb_ext.PriceFloorSchema{Fields: []string{MediaType}}},
+				{Schema: openrtb_ext.PriceFloorSchema{Fields: []string{MediaType}, Delimiter: ","}, Values: map[string]float64{"banner": 1}},
+			}},
+			expectValid:      true,
+			expectedGroups:   1,
+			expectedRules:    map[string]float64{"banner": 1},
+			expectedErrCount: 1,
+		},
+		{
+			description: "Model group with a repeated schema field dropped",
+			data: &openrtb_ext.PriceFloorData{ModelGroups: []openrtb_ext.PriceFloorModelGroup{
+				{Schema: openrtb_ext.PriceFloorSchema{Fields: repeatedFields}, Values: map[string]float64{strings.Join(repeatedRule, "|"): 1}},
+			}},
+			expectValid:      false,
+			expectedErrCount: 2,
+		},
+		{
+			description: "Model group with a duplicate schema field dropped",
+			data: &openrtb_ext.PriceFloorData{ModelGroups: []openrtb_ext.PriceFloorModelGroup{
+				{Schema: openrtb_ext.PriceFloorSchema{Fields: []string{MediaType, Size, MediaType}}, Values: map[string]float64{"banner|300x250|banner": 1}},
+				{Schema: openrtb_ext.PriceFloorSchema{Fields: []string{MediaType}}, Values: map[string]float64{"banner": 1}},
+			}},
+			expectValid:      true,
+			expectedGroups:   1,
+			expectedRules:    map[string]float64{"banner": 1},
+			expectedErrCount: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		data, errs := validateFloorData(test.data)
+		assert.Len(t, errs, test.expectedErrCount, test.description)
+		if !test.expectValid {
+			assert.Nil(t, data, test.description)
+			continue
+		}
+		if assert.NotNil(t, data, test.description) {
+			assert.Len(t, data.ModelGroups, test.expectedGroups, test.description)
+			assert.Equal(t, test.expectedRules, data.ModelGroups[0].Values, test.description)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package floors
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+)
+
+// Defines the schema fields a price floors model group may be keyed by
+const (
+	SiteDomain string = "siteDomain"
+	PubDomain  string = "pubDomain"
+	Domain     string = "domain"
+	Bundle     string = "bundle"
+	MediaType  string = "mediaType"
+	Size       string = "size"
+	GptSlot    string = "gptSlot"
+	AdUnitCode string = "adUnitCode"
+	Country    string = "country"
+	DeviceType string = "deviceType"
+)
+
+const (
+	catchAll         string = "*"
+	defaultDelimiter string = "|"
+
+	mediaTypeBanner string = "banner"
+	mediaTypeVideo  string = "video"
+	mediaTypeAudio  string = "audio"
+	mediaTypeNative string = "native"
+
+	deviceTypePhone   string = "phone"
+	deviceTypeTablet  string = "tablet"
+	deviceTypeDesktop string = "desktop"
+)
+
+var schemaFields = map[string]struct{}{
+	SiteDomain: {},
+	PubDomain:  {},
+	Domain:     {},
+	Bundle:     {},
+	MediaType:  {},
+	Size:       {},
+	GptSlot:    {},
+	AdUnitCode: {},
+	Country:    {},
+	DeviceType: {},
+}
+
+// createRuleKey returns the lower-cased value of each schema field for the given imp, in schema order.
+// Fields which can't be determined from the request are set to the catch-all value.
+func createRuleKey(fields []string, request *openrtb2.BidRequest, imp *openrtb2.Imp) []string {
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := catchAll
+		switch field {
+		case SiteDomain:
+			value = getSiteDomain(request)
+		case PubDomain:
+			value = getPublisherDomain(request)
+		case Domain:
+			value = getDomain(request)
+		case Bundle:
+			if request.App != nil && request.App.Bundle != "" {
+				value = request.App.Bundle
+			}
+		case MediaType:
+			value = getMediaType(imp)
+		case Size:
+			value = getSize(imp)
+		case GptSlot:
+			value = getGptSlot(imp)
+		case AdUnitCode:
+			value = getAdUnitCode(imp)
+		case Country:
+			if request.Device != nil && request.Device.Geo != nil && request.Device.Geo.Country != "" {
+				value = request.Device.Geo.Country
+			}
+		case DeviceType:
+			value = getDeviceType(request)
+		}
+		if value == "" {
+			value = catchAll
+		}
+		values = append(values, strings.ToLower(value))
+	}
+	return values
+}
+
+// findRule looks up the most specific rule matching the desired values, trying the catch-all combinations
+// of the masks in order. With the masks of wildcardMasks, an exact match is preferred, followed by the rules
+// with the fewest catch-all fields. Among rules with the same number of catch-all fields, the ones whose
+// catch-all fields are further to the right of the schema are preferred.
+func findRule(ruleValues map[string]float64, delimiter string, desiredValues []string, masks []uint) (string, bool) {
+	for _, mask := range masks {
+		candidate := make([]string, len(desiredValues))
+		for i, value := range desiredValues {
+			if mask&(1<<uint(i)) != 0 {
+				candidate[i] = catchAll
+			} else {
+				candidate[i] = value
+			}
+		}
+		key := strings.Join(candidate, delimiter)
+		if _, found := ruleValues[key]; found {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// wildcardMasks returns every combination of catch-all fields for a schema of the given size, in the
+// order they should be tried. Bit i of a mask set means the field at index i is replaced by the catch-all.
+// There are 2^fieldCount masks, so the schema size must be validated first.
+func wildcardMasks(fieldCount int) []uint {
+	masks := make([]uint, 1<<uint(fieldCount))
+	for i := range masks {
+		masks[i] = uint(i)
+	}
+	sort.Slice(masks, func(i, j int) bool {
+		iCount, jCount := bits.OnesCount(masks[i]), bits.OnesCount(masks[j])
+		if iCount != jCount {
+			return iCount < jCount
+		}
+		return masks[i] > masks[j]
+	})
+	return masks
+}
+
+func getSiteDomain(request *openrtb2.BidRequest) string {
+	if request.Site != nil {
+		return request.Site.Domain
+	}
+	if request.App != nil {
+		return request.App.Domain
+	}
+	return ""
+}
+
+func getPublisherDomain(request *openrtb2.BidRequest) string {
+	if request.Site != nil && request.Site.Publisher != nil {
+		return request.Site.Publisher.Domain
+	}
+	if request.App != nil && request.App.Publisher != nil {
+		return request.App.Publisher.Domain
+	}
+	return ""
+}
+
+func getDomain(request *openrtb2.BidRequest) string {
+	if domain := getSiteDomain(request); domain != "" {
+		return domain
+	}
+	return getPublisherDomain(request)
+}
+
+func getMediaType(imp *openrtb2.Imp) string {
+	mediaType := catchAll
+	formatCount := 0
+	if imp.Banner != nil {
+		formatCount++
+		mediaType = mediaTypeBanner
+	}
+	if imp.Video != nil {
+		formatCount++
+		mediaType = mediaTypeVideo
+	}
+	if imp.Audio != nil {
+		formatCount++
+		mediaType = mediaTypeAudio
+	}
+	if imp.Native != nil {
+		formatCount++
+		mediaType = mediaTypeNative
+	}
+	if formatCount > 1 {
+		return catchAll
+	}
+	return mediaType
+}
+
+func getSize(imp *openrtb2.Imp) string {
+	var width, height int64
+	sizeCount := 0
+	if imp.Banner != nil {
+		if len(imp.Banner.Format) > 0 {
+			sizeCount += len(imp.Banner.Format)
+			width, height = imp.Banner.Format[0].W, imp.Banner.Format[0].H
+		} else if imp.Banner.W != nil && imp.Banner.H != nil {
+			sizeCount++
+			width, height = *imp.Banner.W, *imp.Banner.H
+		}
+	}
+	if imp.Video != nil && imp.Video.W > 0 && imp.Video.H > 0 {
+		sizeCount++
+		width, height = imp.Video.W, imp.Video.H
+	}
+	if sizeCount != 1 {
+		return catchAll
+	}
+	return fmt.Sprintf("%dx%d", width, height)
+}
+
+func getGptSlot(imp *openrtb2.Imp) string {
+	adServerName, _ := jsonparser.GetString(imp.Ext, "data", "adserver", "name")
+	if adServerName == "gam" {
+		if adSlot, err := jsonparser.GetString(imp.Ext, "data", "adserver", "adslot"); err == nil {
+			return adSlot
+		}
+	}
+	pbAdSlot, _ := jsonparser.GetString(imp.Ext, "data", "pbadslot")
+	return pbAdSlot
+}
+
+func getAdUnitCode(imp *openrtb2.Imp) string {
+	if gpid, err := jsonparser.GetString(imp.Ext, "gpid"); err == nil && gpid != "" {
+		return gpid
+	}
+	if imp.TagID != "" {
+		return imp.TagID
+	}
+	if pbAdSlot, err := jsonparser.GetString(imp.Ext, "data", "pbadslot"); err == nil && pbAdSlot != "" {
+		return pbAdSlot
+	}
+	storedRequestID, _ := jsonparser.GetString(imp.Ext, "prebid", "storedrequest", "id")
+	return storedRequestID
+}
+
+func getDeviceType(request *openrtb2.BidRequest) string {
+	if request.Device == nil {
+		return catchAll
+	}
+	switch request.Device.DeviceType {
+	case openrtb2.DeviceTypePhone:
+		return deviceTypePhone
+	case openrtb2.DeviceTypeTablet:
+		return deviceTypeTablet
+	case openrtb2.DeviceTypePersonalComputer:
+		return deviceTypeDesktop
+	}
+
+	userAgent := strings.ToLower(request.Device.UA)
+	switch {
+	case userAgent == "":
+		return catchAll
+	case strings.Contains(userAgent, "ipad") || strings.Contains(userAgent, "tablet"):
+		return deviceTypeTablet
+	case strings.Contains(userAgent, "mobi") || strings.Contains(userAgent, "iphone"):
+		return deviceTypePhone
+	}
+	return deviceTypeDesktop
+}
@@ -0,0 +1,157 @@
+package floors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRuleKey(t *testing.T) {
+	width, height := int64(728), int64(90)
+
+	testCases := []struct {
+		description string
+		fields      []string
+		request     *openrtb2.BidRequest
+		imp         *openrtb2.Imp
+		expected    []string
+	}{
+		{
+			description: "Site request with banner imp",
+			fields:      []string{MediaType, Size, Domain, SiteDomain, PubDomain},
+			request: &openrtb2.BidRequest{
+				Site: &openrtb2.Site{Domain: "www.Website.com", Publisher: &openrtb2.Publisher{Domain: "publisher.com"}},
+			},
+			imp:      &openrtb2.Imp{ID: "1", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}},
+			expected: []string{"banner", "300x250", "www.website.com", "www.website.com", "publisher.com"},
+		},
+		{
+			description: "App request with video imp",
+			fields:      []string{MediaType, Size, Bundle, Domain, Country},
+			request: &openrtb2.BidRequest{
+				App:    &openrtb2.App{Bundle: "com.app.bundle", Publisher: &openrtb2.Publisher{Domain: "publisher.com"}},
+				Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "USA"}},
+			},
+			imp:      &openrtb2.Imp{ID: "1", Video: &openrtb2.Video{W: 640, H: 480}},
+			expected: []string{"video", "640x480", "com.app.bundle", "publisher.com", "usa"},
+		},
+		{
+			description: "Multi-format imp with several sizes",
+			fields:      []string{MediaType, Size},
+			request:     &openrtb2.BidRequest{},
+			imp: &openrtb2.Imp{ID: "1",
+				Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}, {W: 300, H: 600}}},
+				Native: &openrtb2.Native{},
+			},
+			expected: []string{"*", "*"},
+		},
+		{
+			description: "Banner imp without formats",
+			fields:      []string{Size},
+			request:     &openrtb2.BidRequest{},
+			imp:         &openrtb2.Imp{ID: "1", Banner: &openrtb2.Banner{W: &width, H: &height}},
+			expected:    []string{"728x90"},
+		},
+		{
+			description: "GAM ad slot",
+			fields:      []string{GptSlot, AdUnitCode},
+			request:     &openrtb2.BidRequest{},
+			imp: &openrtb2.Imp{ID: "1", TagID: "tag-1",
+				Ext: json.RawMessage(`{"data":{"adserver":{"name":"gam","adslot":"/1111/homepage"},"pbadslot":"pbadslot-1"}}`),
+			},
+			expected: []string{"/1111/homepage", "tag-1"},
+		},
+		{
+			description: "Prebid ad slot and gpid",
+			fields:      []string{GptSlot, AdUnitCode},
+			request:     &openrtb2.BidRequest{},
+			imp: &openrtb2.Imp{ID: "1", TagID: "tag-1",
+				Ext: json.RawMessage(`{"gpid":"gpid-1","data":{"adserver":{"name":"other","adslot":"/1111/homepage"},"pbadslot":"pbadslot-1"}}`),
+			},
+			expected: []string{"pbadslot-1", "gpid-1"},
+		},
+		{
+			description: "Device type from user agent",
+			fields:      []string{DeviceType},
+			request:     &openrtb2.BidRequest{Device: &openrtb2.Device{UA: "Mozilla/5.0 (iPhone; CPU iPhone OS 14_0 like Mac OS X) Mobile/15E148"}},
+			imp:         &openrtb2.Imp{ID: "1"},
+			expected:    []string{"phone"},
+		},
+		{
+			description: "Device type from device type",
+			fields:      []string{DeviceType},
+			request:     &openrtb2.BidRequest{Device: &openrtb2.Device{DeviceType: openrtb2.DeviceTypeTablet, UA: "Mozilla/5.0 Mobile"}},
+			imp:         &openrtb2.Imp{ID: "1"},
+			expected:    []string{"tablet"},
+		},
+		{
+			description: "Missing values",
+			fields:      []string{Domain, Bundle, Country, DeviceType, GptSlot},
+			request:     &openrtb2.BidRequest{},
+			imp:         &openrtb2.Imp{ID: "1"},
+			expected:    []string{"*", "*", "*", "*", "*"},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, createRuleKey(test.fields, test.request, test.imp), test.description)
+	}
+}
+
+func TestFindRule(t *testing.T) {
+	testCases := []struct {
+		description   string
+		ruleValues    map[string]float64
+		desiredValues []string
+		expectedRule  string
+		expectedFound bool
+	}{
+		{
+			description:   "Exact match",
+			ruleValues:    map[string]float64{"banner|300x250|www.website.com": 1, "banner|*|*": 0.5},
+			desiredValues: []string{"banner", "300x250", "www.website.com"},
+			expectedRule:  "banner|300x250|www.website.com",
+			expectedFound: true,
+		},
+		{
+			description:   "One catch-all field preferred over two",
+			ruleValues:    map[string]float64{"banner|*|www.website.com": 1, "banner|*|*": 0.5},
+			desiredValues: []string{"banner", "300x250", "www.website.com"},
+			expectedRule:  "banner|*|www.website.com",
+			expectedFound: true,
+		},
+		{
+			description:   "Rightmost catch-all field preferred",
+			ruleValues:    map[string]float64{"*|300x250|www.website.com": 1, "banner|300x250|*": 0.5},
+			desiredValues: []string{"banner", "300x250", "www.website.com"},
+			expectedRule:  "banner|300x250|*",
+			expectedFound: true,
+		},
+		{
+			description:   "All catch-all fields",
+			ruleValues:    map[string]float64{"video|*|*": 1, "*|*|*": 0.5},
+			desiredValues: []string{"banner", "300x250", "www.website.com"},
+			expectedRule:  "*|*|*",
+			expectedFound: true,
+		},
+		{
+			description:   "No match",
+			ruleValues:    map[string]float64{"video|*|*": 1},
+			desiredValues: []string{"banner", "300x250", "www.website.com"},
+			expectedRule:  "",
+			expectedFound: false,
+		},
+	}
+
+	for _, test := range testCases {
+		rule, found := findRule(test.ruleValues, "|", test.desiredValues, wildcardMasks(len(test.desiredValues)))
+		assert.Equal(t, test.expectedRule, rule, test.description)
+		assert.Equal(t, test.expectedFound, found, test.description)
+	}
+}
+
+func TestWildcardMasks(t *testing.T) {
+	assert.Equal(t, []uint{0, 4, 2, 1, 6, 5, 3, 7}, wildcardMasks(3))
+}
@@ -0,0 +1,114 @@
+package floors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const (
+	skipRateMin    int = 0
+	skipRateMax    int = 100
+	modelWeightMin int = 1
+	modelWeightMax int = 100
+)
+
+// validateFloorRules checks the top-level settings of the floors object found in the request
+func validateFloorRules(floors *openrtb_ext.PriceFloorRules) error {
+	if floors.SkipRate < skipRateMin || floors.SkipRate > skipRateMax {
+		return fmt.Errorf("invalid skip rate = %d in floors; must be between %d and %d", floors.SkipRate, skipRateMin, skipRateMax)
+	}
+	if floors.FloorMin < 0 {
+		return fmt.Errorf("invalid floormin = %f in floors; must not be negative", floors.FloorMin)
+	}
+	if floors.Enforcement != nil && (floors.Enforcement.EnforceRate < 0 || floors.Enforcement.EnforceRate > 100) {
+		return fmt.Errorf("invalid enforcement rate = %d in floors; must be between 0 and 100", floors.Enforcement.EnforceRate)
+	}
+	return nil
+}
+
+// validateFloorData returns a copy of the floors data holding only its valid model groups and rules, or nil
+// if the data can't be used at all. The data passed in is never modified since it may be shared across auctions.
+func validateFloorData(data *openrtb_ext.PriceFloorData) (*openrtb_ext.PriceFloorData, []error) {
+	if data.SkipRate < skipRateMin || data.SkipRate > skipRateMax {
+		return nil, []error{fmt.Errorf("invalid skip rate = %d in floors data; must be between %d and %d", data.SkipRate, skipRateMin, skipRateMax)}
+	}
+	if len(data.ModelGroups) == 0 {
+		return nil, []error{fmt.Errorf("no model groups found in floors data")}
+	}
+
+	var errs []error
+	validGroups := make([]openrtb_ext.PriceFloorModelGroup, 0, len(data.ModelGroups))
+	for i, group := range data.ModelGroups {
+		if err := validateModelGroup(&group); err != nil {
+			errs = append(errs, fmt.Errorf("model group %d dropped: %v", i, err))
+			continue
+		}
+		group.Values, errs = validateRules(group.Values, group.Schema, errs)
+		validGroups = append(validGroups, group)
+	}
+
+	if len(validGroups) == 0 {
+		return nil, append(errs, fmt.Errorf("no valid model groups found in floors data"))
+	}
+	validData := *data
+	validData.ModelGroups = validGroups
+	return &validData, errs
+}
+
+func validateModelGroup(group *openrtb_ext.PriceFloorModelGroup) error {
+	if group.SkipRate < skipRateMin || group.SkipRate > skipRateMax {
+		return fmt.Errorf("invalid skip rate = %d; must be between %d and %d", group.SkipRate, skipRateMin, skipRateMax)
+	}
+	if group.ModelWeight != nil && (*group.ModelWeight < modelWeightMin || *group.ModelWeight > modelWeightMax) {
+		return fmt.Errorf("invalid model weight = %d; must be between %d and %d", *group.ModelWeight, modelWeightMin, modelWeightMax)
+	}
+	if group.Default < 0 {
+		return fmt.Errorf("invalid default = %f; must not be negative", group.Default)
+	}
+	if len(group.Schema.Fields) == 0 {
+		return fmt.Errorf("no schema fields defined")
+	}
+	// The rules are looked up by trying every combination of catch-all fields, so the schema must stay small
+	if len(group.Schema.Fields) > len(schemaFields) {
+		return fmt.Errorf("too many schema fields = %d; must be at most %d", len(group.Schema.Fields), len(schemaFields))
+	}
+	seenFields := make(map[string]struct{}, len(group.Schema.Fields))
+	for _, field := range group.Schema.Fields {
+		if _, ok := schemaFields[field]; !ok {
+			return fmt.Errorf("invalid schema field '%s'", field)
+		}
+		if _, seen := seenFields[field]; seen {
+			return fmt.Errorf("duplicate schema field '%s'", field)
+		}
+		seenFields[field] = struct{}{}
+	}
+	return nil
+}
+
+// validateRules returns a copy of the rule values with lower-cased keys, keeping only the rules which have
+// a value for each of the schema fields and a non-negative floor.
+func validateRules(values map[string]float64, schema openrtb_ext.PriceFloorSchema, errs []error) (map[string]float64, []error) {
+	delimiter := getDelimiter(schema)
+	validValues := make(map[string]float64, len(values))
+	for key, value := range values {
+		if len(strings.Split(key, delimiter)) != len(schema.Fields) {
+			errs = append(errs, fmt.Errorf("invalid floor rule '%s'; rule doesn't match the schema fields", key))
+			continue
+		}
+		if value < 0 {
+			errs = append(errs, fmt.Errorf("invalid floor value = %f for rule '%s'; must not be negative", value, key))
+			continue
+		}
+		validValues[strings.ToLower(key)] = value
+	}
+	return validValues, errs
+}
+
+func getDelimiter(schema openrtb_ext.PriceFloorSchema) string {
+	if schema.Delimiter == "" {
+		return defaultDelimiter
+	}
+	return schema.Delimiter
+}
@@ -32,11 +32,29 @@ const (
 	tcf2SpecVersion uint8 = 2
 )
 
+// VendorListFetcher returns the global vendor list of the given version.
+type VendorListFetcher func(ctx context.Context, id uint16) (vendorlist.VendorList, error)
+
+// NewVendorListFetcher builds a VendorListFetcher which downloads the vendor lists with the client, and keeps them
+// in memory. It preloads the latest vendor lists, so it's meant to be built once and shared by the Permissions.
+func NewVendorListFetcher(ctx context.Context, cfg config.GDPR, client *http.Client) VendorListFetcher {
+	return newVendorListFetcher(ctx, cfg, client, vendorListURLMaker)
+}
+
 // NewPermissions gets an instance of the Permissions for use elsewhere in the project.
 func NewPermissions(ctx context.Context, cfg config.GDPR, vendorIDs map[openrtb_ext.BidderName]uint16, client *http.Client) Permissions {
 	if !cfg.Enabled {
 		return &AlwaysAllow{}
 	}
+	return NewPermissionsWithVendorListFetcher(cfg, vendorIDs, NewVendorListFetcher(ctx, cfg, client))
+}
+
+// NewPermissionsWithVendorListFetcher gets an instance of the Permissions which reads the vendor lists from the
+// given fetcher.
+func NewPermissionsWithVendorListFetcher(cfg config.GDPR, vendorIDs map[openrtb_ext.BidderName]uint16, fetchVendorList VendorListFetcher) Permissions {
+	if !cfg.Enabled {
+		return &AlwaysAllow{}
+	}
 
 	gdprDefaultValue := SignalYes
 	if cfg.DefaultValue == "0" {
@@ -62,7 +80,7 @@ func NewPermissions(ctx context.Context, cfg config.GDPR, vendorIDs map[openrtb_
 		purposeConfigs:   purposeConfigs,
 		vendorIDs:        vendorIDs,
 		fetchVendorList: map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
-			tcf2SpecVersion: newVendorListFetcher(ctx, cfg, client, vendorListURLMaker)},
+			tcf2SpecVersion: fetchVendorList},
 	}
 
 	if cfg.HostVendorID == 0 {
@@ -0,0 +1,110 @@
+package hookexecution
+
+import (
+	"encoding/json"
+
+	"github.com/buger/jsonparser"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+)
+
+// modulesOutcome defines the contract for bidresponse.ext.prebid.modules
+type modulesOutcome struct {
+	Errors   messages      `json:"errors,omitempty"`
+	Warnings messages      `json:"warnings,omitempty"`
+	Trace    *traceOutcome `json:"trace,omitempty"`
+}
+
+// messages are keyed by module code and then by hook_impl_code
+type messages map[string]map[string][]string
+
+type traceOutcome struct {
+	ExecutionTimeMillis int64        `json:"execution_time_millis"`
+	Stages              []stageTrace `json:"stages"`
+}
+
+type stageTrace struct {
+	Stage               string         `json:"stage"`
+	ExecutionTimeMillis int64          `json:"execution_time_millis"`
+	Outcomes            []StageOutcome `json:"outcomes"`
+}
+
+// EnrichExtBidResponse adds the hook outcomes to bidresponse.ext.prebid.modules when debug is enabled for
+// the request. The bid request and the account may be nil if the request was rejected before they were
+// resolved.
+func EnrichExtBidResponse(ext json.RawMessage, outcomes []StageOutcome, bidRequest *openrtb2.BidRequest, account *config.Account) (json.RawMessage, error) {
+	if len(outcomes) == 0 || !isDebugEnabled(bidRequest, account) {
+		return ext, nil
+	}
+
+	modules, err := json.Marshal(map[string]interface{}{
+		"prebid": map[string]interface{}{
+			"modules": buildModulesOutcome(outcomes),
+		},
+	})
+	if err != nil {
+		return ext, err
+	}
+
+	if len(ext) == 0 {
+		return modules, nil
+	}
+	return jsonpatch.MergePatch(ext, modules)
+}
+
+func isDebugEnabled(bidRequest *openrtb2.BidRequest, account *config.Account) bool {
+	if bidRequest == nil || (account != nil && !account.DebugAllow) {
+		return false
+	}
+	if bidRequest.Test == 1 {
+		return true
+	}
+	debug, err := jsonparser.GetBoolean(bidRequest.Ext, "prebid", "debug")
+	return err == nil && debug
+}
+
+func buildModulesOutcome(outcomes []StageOutcome) modulesOutcome {
+	modules := modulesOutcome{
+		Errors:   make(messages),
+		Warnings: make(messages),
+		Trace:    &traceOutcome{Stages: make([]stageTrace, 0)},
+	}
+
+	for _, stage := range hookstage.Stages() {
+		trace := stageTrace{Stage: string(stage)}
+		for _, outcome := range outcomes {
+			if outcome.Stage != string(stage) {
+				continue
+			}
+			trace.Outcomes = append(trace.Outcomes, outcome)
+			// Outcomes of the same stage are produced in parallel for each bidder
+			if outcome.ExecutionTimeMillis > trace.ExecutionTimeMillis {
+				trace.ExecutionTimeMillis = outcome.ExecutionTimeMillis
+			}
+			for _, group := range outcome.Groups {
+				for _, result := range group.InvocationResults {
+					modules.Errors.add(result.HookID, result.Errors)
+					modules.Warnings.add(result.HookID, result.Warnings)
+				}
+			}
+		}
+		if len(trace.Outcomes) > 0 {
+			modules.Trace.Stages = append(modules.Trace.Stages, trace)
+			modules.Trace.ExecutionTimeMillis += trace.ExecutionTimeMillis
+		}
+	}
+
+	return modules
+}
+
+func (m messages) add(hookID HookID, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	if _, ok := m[hookID.ModuleCode]; !ok {
+		m[hookID.ModuleCode] = make(map[string][]string)
+	}
+	m[hookID.ModuleCode][hookID.HookImplCode] = append(m[hookID.ModuleCode][hookID.HookImplCode], values...)
+}
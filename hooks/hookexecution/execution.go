@@ -0,0 +1,186 @@
+package hookexecution
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+)
+
+// hookHandler invokes the stage method of the hook with the payload.
+type hookHandler func(ctx context.Context, miCtx hookstage.ModuleInvocationContext, hook interface{}, payload interface{}) (hookstage.HookResult, error)
+
+type hookResponse struct {
+	result        hookstage.HookResult
+	err           error
+	panicked      bool
+	executionTime time.Duration
+}
+
+// executeStage runs the groups of the plan one after the other. The payload returned by each group is passed
+// to the next one. The execution stops as soon as a hook rejects the payload.
+func (e *hookExecutor) executeStage(plan hooks.Plan, stage hookstage.Stage, entity string, payload interface{}, handler hookHandler) (interface{}, *RejectError) {
+	stageOutcome := StageOutcome{
+		Entity: entity,
+		Stage:  string(stage),
+		Groups: make([]GroupOutcome, 0, len(plan)),
+	}
+	start := time.Now()
+	defer func() {
+		stageOutcome.ExecutionTimeMillis = time.Since(start).Milliseconds()
+		e.pushStageOutcome(stageOutcome)
+	}()
+
+	for _, group := range plan {
+		groupOutcome, updatedPayload, rejectErr := e.executeGroup(group, stage, payload, handler)
+		stageOutcome.Groups = append(stageOutcome.Groups, groupOutcome)
+		if rejectErr != nil {
+			return payload, rejectErr
+		}
+		payload = updatedPayload
+	}
+
+	return payload, nil
+}
+
+// executeGroup runs the hooks of the group in parallel. Their mutations are applied in the plan order once
+// all of them returned or timed out, unless one of them rejected the payload.
+func (e *hookExecutor) executeGroup(group hooks.Group, stage hookstage.Stage, payload interface{}, handler hookHandler) (GroupOutcome, interface{}, *RejectError) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), group.Timeout)
+	defer cancel()
+
+	responseChans := make([]chan hookResponse, len(group.Hooks))
+	for i, hw := range group.Hooks {
+		responseChans[i] = make(chan hookResponse, 1)
+		go invokeHook(ctx, handler, hw, e.invocationContext(hw.Module), payload, responseChans[i])
+	}
+
+	outcome := GroupOutcome{InvocationResults: make([]HookOutcome, len(group.Hooks))}
+	results := make([]*hookstage.HookResult, len(group.Hooks))
+	for i, hw := range group.Hooks {
+		hookOutcome := HookOutcome{
+			HookID: HookID{ModuleCode: hw.Module, HookImplCode: hw.Code},
+			Action: ActionNone,
+		}
+
+		resp, ok := awaitResponse(ctx, responseChans[i])
+		if ok {
+			hookOutcome.ExecutionTimeMillis = resp.executionTime.Milliseconds()
+			switch {
+			case resp.panicked:
+				hookOutcome.Status = StatusExecutionFailure
+				hookOutcome.Errors = []string{"hook execution failed"}
+			case resp.err != nil:
+				hookOutcome.Status = StatusFailure
+				hookOutcome.Errors = []string{resp.err.Error()}
+			default:
+				result := resp.result
+				results[i] = &result
+				hookOutcome.Status = StatusSuccess
+				hookOutcome.Message = result.Message
+				hookOutcome.DebugMessages = result.DebugMessages
+				hookOutcome.AnalyticsTags = result.AnalyticsTags
+				hookOutcome.Errors = result.Errors
+				hookOutcome.Warnings = result.Warnings
+				if result.ModuleContext != nil {
+					e.setModuleContext(hw.Module, result.ModuleContext)
+				}
+			}
+		} else {
+			hookOutcome.Status = StatusTimeout
+			hookOutcome.ExecutionTimeMillis = time.Since(start).Milliseconds()
+			hookOutcome.Errors = []string{"hook execution timeout"}
+		}
+
+		outcome.InvocationResults[i] = hookOutcome
+	}
+
+	for i, result := range results {
+		if result != nil && result.Reject {
+			outcome.InvocationResults[i].Action = ActionReject
+			outcome.ExecutionTimeMillis = time.Since(start).Milliseconds()
+			return outcome, payload, &RejectError{
+				NBR:   result.NbrCode,
+				Hook:  outcome.InvocationResults[i].HookID,
+				Stage: string(stage),
+			}
+		}
+	}
+
+	for i, result := range results {
+		if result == nil || len(result.ChangeSet.Mutations()) == 0 {
+			continue
+		}
+		hookOutcome := &outcome.InvocationResults[i]
+		hookOutcome.Action = ActionUpdate
+		for _, mutation := range result.ChangeSet.Mutations() {
+			updatedPayload, err := applyMutation(mutation, payload)
+			if err != nil {
+				hookOutcome.Status = StatusExecutionFailure
+				hookOutcome.Errors = append(hookOutcome.Errors, fmt.Sprintf("failed to apply hook mutation %s %v: %v", mutation.Type, mutation.Key, err))
+				continue
+			}
+			payload = updatedPayload
+		}
+	}
+
+	outcome.ExecutionTimeMillis = time.Since(start).Milliseconds()
+	return outcome, payload, nil
+}
+
+// awaitResponse waits for the hook response until the group times out. Responses which were already received
+// are used even if the group timed out while waiting for a previous hook.
+func awaitResponse(ctx context.Context, response <-chan hookResponse) (hookResponse, bool) {
+	select {
+	case resp := <-response:
+		return resp, true
+	default:
+	}
+
+	select {
+	case resp := <-response:
+		return resp, true
+	case <-ctx.Done():
+		return hookResponse{}, false
+	}
+}
+
+func invokeHook(ctx context.Context, handler hookHandler, hw hooks.HookWrapper, miCtx hookstage.ModuleInvocationContext, payload interface{}, response chan<- hookResponse) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			glog.Errorf("OpenRTB auction recovered panic in module hook %s.%s: %v, Stack trace is: %v", hw.Module, hw.Code, r, string(debug.Stack()))
+			response <- hookResponse{panicked: true, executionTime: time.Since(start)}
+		}
+	}()
+
+	result, err := handler(ctx, miCtx, hw.Hook, payload)
+	response <- hookResponse{result: result, err: err, executionTime: time.Since(start)}
+}
+
+// applyMutation applies the mutation and makes sure it returned a payload of the stage type.
+func applyMutation(mutation hookstage.Mutation, payload interface{}) (updatedPayload interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			updatedPayload, err = payload, fmt.Errorf("mutation panicked: %v", r)
+		}
+	}()
+
+	if mutation.Apply == nil {
+		return payload, fmt.Errorf("mutation function missing")
+	}
+	updatedPayload, err = mutation.Apply(payload)
+	if err != nil {
+		return payload, err
+	}
+	if reflect.TypeOf(updatedPayload) != reflect.TypeOf(payload) {
+		return payload, fmt.Errorf("mutation returned %T instead of %T", updatedPayload, payload)
+	}
+	return updatedPayload, nil
+}
@@ -0,0 +1,240 @@
+package hookexecution
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// StageExecutor executes the hooks of each stage for a single request. Stages which may be rejected return
+// a RejectError, in which case the returned payload is the one they were given.
+// Implementations must be safe for concurrent use, as the bidder stages are executed by each bidder.
+type StageExecutor interface {
+	ExecuteEntrypointStage(req *http.Request, body []byte) ([]byte, *RejectError)
+	ExecuteRawAuctionStage(body []byte) ([]byte, *RejectError)
+	ExecuteProcessedAuctionStage(req *openrtb2.BidRequest) (*openrtb2.BidRequest, *RejectError)
+	ExecuteBidderRequestStage(req *openrtb2.BidRequest, bidder string) (*openrtb2.BidRequest, *RejectError)
+	ExecuteRawBidderResponseStage(bids []*adapters.TypedBid, bidder string) ([]*adapters.TypedBid, *RejectError)
+	ExecuteAllProcessedBidResponsesStage(responses map[openrtb_ext.BidderName][]*adapters.TypedBid) map[openrtb_ext.BidderName][]*adapters.TypedBid
+	ExecuteAuctionResponseStage(resp *openrtb2.BidResponse) *openrtb2.BidResponse
+
+	// SetAccount makes the account execution plan apply to the stages executed afterwards.
+	SetAccount(account *config.Account)
+	// GetOutcomes returns the outcomes of the stages executed so far.
+	GetOutcomes() []StageOutcome
+}
+
+// NewHookExecutor returns an executor of the hooks planned for the endpoint.
+func NewHookExecutor(builder hooks.ExecutionPlanBuilder, endpoint string) StageExecutor {
+	return &hookExecutor{
+		planBuilder:    builder,
+		endpoint:       endpoint,
+		moduleContexts: make(map[string]hookstage.ModuleContext),
+	}
+}
+
+type hookExecutor struct {
+	planBuilder    hooks.ExecutionPlanBuilder
+	endpoint       string
+	account        *config.Account
+	mutex          sync.Mutex
+	moduleContexts map[string]hookstage.ModuleContext
+	stageOutcomes  []StageOutcome
+}
+
+func (e *hookExecutor) SetAccount(account *config.Account) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.account = account
+}
+
+func (e *hookExecutor) GetOutcomes() []StageOutcome {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append([]StageOutcome(nil), e.stageOutcomes...)
+}
+
+func (e *hookExecutor) ExecuteEntrypointStage(req *http.Request, body []byte) ([]byte, *RejectError) {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.Entrypoint, nil)
+	if len(plan) == 0 {
+		return body, nil
+	}
+
+	handler := func(ctx context.Context, miCtx hookstage.ModuleInvocationContext, hook interface{}, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.EntrypointHook).HandleEntrypointHook(ctx, miCtx, payload.(hookstage.EntrypointPayload))
+	}
+
+	payload, rejectErr := e.executeStage(plan, hookstage.Entrypoint, entityHttpRequest, hookstage.EntrypointPayload{Request: req, Body: body}, handler)
+	return payload.(hookstage.EntrypointPayload).Body, rejectErr
+}
+
+func (e *hookExecutor) ExecuteRawAuctionStage(body []byte) ([]byte, *RejectError) {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.RawAuctionRequest, e.getAccount())
+	if len(plan) == 0 {
+		return body, nil
+	}
+
+	handler := func(ctx context.Context, miCtx hookstage.ModuleInvocationContext, hook interface{}, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.RawAuctionRequestHook).HandleRawAuctionRequestHook(ctx, miCtx, payload.(hookstage.RawAuctionRequestPayload))
+	}
+
+	payload, rejectErr := e.executeStage(plan, hookstage.RawAuctionRequest, entityAuctionRequest, hookstage.RawAuctionRequestPayload(body), handler)
+	return payload.(hookstage.RawAuctionRequestPayload), rejectErr
+}
+
+func (e *hookExecutor) ExecuteProcessedAuctionStage(req *openrtb2.BidRequest) (*openrtb2.BidRequest, *RejectError) {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.ProcessedAuctionRequest, e.getAccount())
+	if len(plan) == 0 {
+		return req, nil
+	}
+
+	handler := func(ctx context.Context, miCtx hookstage.ModuleInvocationContext, hook interface{}, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.ProcessedAuctionRequestHook).HandleProcessedAuctionRequestHook(ctx, miCtx, payload.(hookstage.ProcessedAuctionRequestPayload))
+	}
+
+	payload, rejectErr := e.executeStage(plan, hookstage.ProcessedAuctionRequest, entityAuctionRequest, hookstage.ProcessedAuctionRequestPayload{BidRequest: req}, handler)
+	if updated := payload.(hookstage.ProcessedAuctionRequestPayload).BidRequest; updated != nil {
+		req = updated
+	}
+	return req, rejectErr
+}
+
+func (e *hookExecutor) ExecuteBidderRequestStage(req *openrtb2.BidRequest, bidder string) (*openrtb2.BidRequest, *RejectError) {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.BidderRequest, e.getAccount())
+	if len(plan) == 0 {
+		return req, nil
+	}
+
+	handler := func(ctx context.Context, miCtx hookstage.ModuleInvocationContext, hook interface{}, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.BidderRequestHook).HandleBidderRequestHook(ctx, miCtx, payload.(hookstage.BidderRequestPayload))
+	}
+
+	payload, rejectErr := e.executeStage(plan, hookstage.BidderRequest, bidder, hookstage.BidderRequestPayload{Bidder: bidder, BidRequest: req}, handler)
+	if updated := payload.(hookstage.BidderRequestPayload).BidRequest; updated != nil {
+		req = updated
+	}
+	return req, rejectErr
+}
+
+func (e *hookExecutor) ExecuteRawBidderResponseStage(bids []*adapters.TypedBid, bidder string) ([]*adapters.TypedBid, *RejectError) {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.RawBidderResponse, e.getAccount())
+	if len(plan) == 0 {
+		return bids, nil
+	}
+
+	handler := func(ctx context.Context, miCtx hookstage.ModuleInvocationContext, hook interface{}, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.RawBidderResponseHook).HandleRawBidderResponseHook(ctx, miCtx, payload.(hookstage.RawBidderResponsePayload))
+	}
+
+	payload, rejectErr := e.executeStage(plan, hookstage.RawBidderResponse, bidder, hookstage.RawBidderResponsePayload{Bidder: bidder, Bids: bids}, handler)
+	return payload.(hookstage.RawBidderResponsePayload).Bids, rejectErr
+}
+
+func (e *hookExecutor) ExecuteAllProcessedBidResponsesStage(responses map[openrtb_ext.BidderName][]*adapters.TypedBid) map[openrtb_ext.BidderName][]*adapters.TypedBid {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.AllProcessedBidResponses, e.getAccount())
+	if len(plan) == 0 {
+		return responses
+	}
+
+	handler := func(ctx context.Context, miCtx hookstage.ModuleInvocationContext, hook interface{}, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.AllProcessedBidResponsesHook).HandleAllProcessedBidResponsesHook(ctx, miCtx, payload.(hookstage.AllProcessedBidResponsesPayload))
+	}
+
+	// Rejections are ignored at this stage, bidders are expected to be dropped with a mutation instead
+	payload, _ := e.executeStage(plan, hookstage.AllProcessedBidResponses, entityAllProcessedBidResponses, hookstage.AllProcessedBidResponsesPayload{Responses: responses}, handler)
+	return payload.(hookstage.AllProcessedBidResponsesPayload).Responses
+}
+
+func (e *hookExecutor) ExecuteAuctionResponseStage(resp *openrtb2.BidResponse) *openrtb2.BidResponse {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.AuctionResponse, e.getAccount())
+	if len(plan) == 0 {
+		return resp
+	}
+
+	handler := func(ctx context.Context, miCtx hookstage.ModuleInvocationContext, hook interface{}, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.AuctionResponseHook).HandleAuctionResponseHook(ctx, miCtx, payload.(hookstage.AuctionResponsePayload))
+	}
+
+	// The response has already been built at this stage, so rejections are ignored
+	payload, _ := e.executeStage(plan, hookstage.AuctionResponse, entityAuctionResponse, hookstage.AuctionResponsePayload{BidResponse: resp}, handler)
+	if updated := payload.(hookstage.AuctionResponsePayload).BidResponse; updated != nil {
+		resp = updated
+	}
+	return resp
+}
+
+func (e *hookExecutor) getAccount() *config.Account {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.account
+}
+
+func (e *hookExecutor) invocationContext(module string) hookstage.ModuleInvocationContext {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	miCtx := hookstage.ModuleInvocationContext{
+		Endpoint:      e.endpoint,
+		ModuleContext: e.moduleContexts[module],
+	}
+	if e.account != nil {
+		miCtx.AccountID = e.account.ID
+	}
+	return miCtx
+}
+
+func (e *hookExecutor) setModuleContext(module string, moduleContext hookstage.ModuleContext) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.moduleContexts[module] = moduleContext
+}
+
+func (e *hookExecutor) pushStageOutcome(outcome StageOutcome) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.stageOutcomes = append(e.stageOutcomes, outcome)
+}
+
+// EmptyHookExecutor is used when no hooks need to be executed, e.g. in tests or internal auctions.
+type EmptyHookExecutor struct{}
+
+func (EmptyHookExecutor) ExecuteEntrypointStage(req *http.Request, body []byte) ([]byte, *RejectError) {
+	return body, nil
+}
+
+func (EmptyHookExecutor) ExecuteRawAuctionStage(body []byte) ([]byte, *RejectError) {
+	return body, nil
+}
+
+func (EmptyHookExecutor) ExecuteProcessedAuctionStage(req *openrtb2.BidRequest) (*openrtb2.BidRequest, *RejectError) {
+	return req, nil
+}
+
+func (EmptyHookExecutor) ExecuteBidderRequestStage(req *openrtb2.BidRequest, bidder string) (*openrtb2.BidRequest, *RejectError) {
+	return req, nil
+}
+
+func (EmptyHookExecutor) ExecuteRawBidderResponseStage(bids []*adapters.TypedBid, bidder string) ([]*adapters.TypedBid, *RejectError) {
+	return bids, nil
+}
+
+func (EmptyHookExecutor) ExecuteAllProcessedBidResponsesStage(responses map[openrtb_ext.BidderName][]*adapters.TypedBid) map[openrtb_ext.BidderName][]*adapters.TypedBid {
+	return responses
+}
+
+func (EmptyHookExecutor) ExecuteAuctionResponseStage(resp *openrtb2.BidResponse) *openrtb2.BidResponse {
+	return resp
+}
+
+func (EmptyHookExecutor) SetAccount(account *config.Account) {}
+
+func (EmptyHookExecutor) GetOutcomes() []StageOutcome {
+	return nil
+}
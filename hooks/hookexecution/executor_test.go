@@ -0,0 +1,297 @@
+package hookexecution
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPlanBuilder struct {
+	plans map[hookstage.Stage]hooks.Plan
+}
+
+func (b mockPlanBuilder) PlanForStage(endpoint string, stage hookstage.Stage, account *config.Account) hooks.Plan {
+	return b.plans[stage]
+}
+
+// mockModule implements every stage with the same behaviour
+type mockModule struct {
+	result hookstage.HookResult
+	err    error
+	delay  time.Duration
+	panics bool
+}
+
+func (m mockModule) handle() (hookstage.HookResult, error) {
+	time.Sleep(m.delay)
+	if m.panics {
+		panic("module panic")
+	}
+	return m.result, m.err
+}
+
+func (m mockModule) HandleEntrypointHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.EntrypointPayload) (hookstage.HookResult, error) {
+	return m.handle()
+}
+
+func (m mockModule) HandleProcessedAuctionRequestHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.ProcessedAuctionRequestPayload) (hookstage.HookResult, error) {
+	return m.handle()
+}
+
+func (m mockModule) HandleRawBidderResponseHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.RawBidderResponsePayload) (hookstage.HookResult, error) {
+	return m.handle()
+}
+
+// contextModule records the module context it is invoked with and stores a counter in it
+type contextModule struct {
+	received *[]hookstage.ModuleContext
+}
+
+func (m contextModule) HandleEntrypointHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.EntrypointPayload) (hookstage.HookResult, error) {
+	*m.received = append(*m.received, miCtx.ModuleContext)
+	return hookstage.HookResult{ModuleContext: hookstage.ModuleContext{"calls": len(*m.received)}}, nil
+}
+
+func (m contextModule) HandleProcessedAuctionRequestHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.ProcessedAuctionRequestPayload) (hookstage.HookResult, error) {
+	*m.received = append(*m.received, miCtx.ModuleContext)
+	return hookstage.HookResult{}, nil
+}
+
+func bodyMutation(body string) hookstage.ChangeSet {
+	changeSet := hookstage.ChangeSet{}
+	changeSet.AddMutation(func(payload interface{}) (interface{}, error) {
+		entrypoint := payload.(hookstage.EntrypointPayload)
+		entrypoint.Body = []byte(body)
+		return entrypoint, nil
+	}, hookstage.MutationUpdate, "body")
+	return changeSet
+}
+
+func groupOf(timeout time.Duration, modules ...interface{}) hooks.Group {
+	group := hooks.Group{Timeout: timeout}
+	for i, module := range modules {
+		group.Hooks = append(group.Hooks, hooks.HookWrapper{Module: "vendor.module" + string(rune('a'+i)), Code: "code", Hook: module})
+	}
+	return group
+}
+
+func TestExecuteEntrypointStage(t *testing.T) {
+	failingMutation := hookstage.ChangeSet{}
+	failingMutation.AddMutation(func(payload interface{}) (interface{}, error) {
+		return nil, errors.New("invalid payload")
+	}, hookstage.MutationUpdate, "body")
+
+	testCases := []struct {
+		description      string
+		plan             hooks.Plan
+		expectedBody     string
+		expectedReject   *RejectError
+		expectedStatuses [][]Status
+		expectedActions  [][]Action
+	}{
+		{
+			description:  "No hooks",
+			expectedBody: "original",
+		},
+		{
+			description: "Mutations applied in plan order, group after group",
+			plan: hooks.Plan{
+				groupOf(time.Second, mockModule{result: hookstage.HookResult{ChangeSet: bodyMutation("first")}}, mockModule{result: hookstage.HookResult{ChangeSet: bodyMutation("second")}}),
+				groupOf(time.Second, mockModule{}),
+			},
+			expectedBody:     "second",
+			expectedStatuses: [][]Status{{StatusSuccess, StatusSuccess}, {StatusSuccess}},
+			expectedActions:  [][]Action{{ActionUpdate, ActionUpdate}, {ActionNone}},
+		},
+		{
+			description: "Rejection discards the group mutations and stops the stage",
+			plan: hooks.Plan{
+				groupOf(time.Second, mockModule{result: hookstage.HookResult{ChangeSet: bodyMutation("changed")}}, mockModule{result: hookstage.HookResult{Reject: true, NbrCode: 12}}),
+				groupOf(time.Second, mockModule{}),
+			},
+			expectedBody:     "original",
+			expectedReject:   &RejectError{NBR: 12, Hook: HookID{ModuleCode: "vendor.moduleb", HookImplCode: "code"}, Stage: "entrypoint"},
+			expectedStatuses: [][]Status{{StatusSuccess, StatusSuccess}},
+			expectedActions:  [][]Action{{ActionNone, ActionReject}},
+		},
+		{
+			description: "Failing hooks are ignored",
+			plan: hooks.Plan{
+				groupOf(50*time.Millisecond,
+					mockModule{result: hookstage.HookResult{Reject: true}, delay: 200 * time.Millisecond},
+					mockModule{err: errors.New("hook error")},
+					mockModule{panics: true},
+					mockModule{result: hookstage.HookResult{ChangeSet: failingMutation}},
+				),
+			},
+			expectedBody:     "original",
+			expectedStatuses: [][]Status{{StatusTimeout, StatusFailure, StatusExecutionFailure, StatusExecutionFailure}},
+			expectedActions:  [][]Action{{ActionNone, ActionNone, ActionNone, ActionUpdate}},
+		},
+	}
+
+	for _, test := range testCases {
+		executor := NewHookExecutor(mockPlanBuilder{plans: map[hookstage.Stage]hooks.Plan{hookstage.Entrypoint: test.plan}}, hooks.EndpointAuction)
+
+		body, rejectErr := executor.ExecuteEntrypointStage(httptest.NewRequest("POST", "/openrtb2/auction", nil), []byte("original"))
+		assert.Equal(t, test.expectedBody, string(body), test.description)
+		assert.Equal(t, test.expectedReject, rejectErr, test.description)
+
+		outcomes := executor.GetOutcomes()
+		if len(test.plan) == 0 {
+			assert.Empty(t, outcomes, test.description)
+			continue
+		}
+		if !assert.Len(t, outcomes, 1, test.description) {
+			continue
+		}
+		assert.Equal(t, "http-request", outcomes[0].Entity, test.description)
+		var statuses [][]Status
+		var actions [][]Action
+		for _, group := range outcomes[0].Groups {
+			var groupStatuses []Status
+			var groupActions []Action
+			for _, result := range group.InvocationResults {
+				groupStatuses = append(groupStatuses, result.Status)
+				groupActions = append(groupActions, result.Action)
+			}
+			statuses = append(statuses, groupStatuses)
+			actions = append(actions, groupActions)
+		}
+		assert.Equal(t, test.expectedStatuses, statuses, test.description)
+		assert.Equal(t, test.expectedActions, actions, test.description)
+	}
+}
+
+func TestExecuteProcessedAuctionStage(t *testing.T) {
+	changeSet := hookstage.ChangeSet{}
+	changeSet.AddMutation(func(payload interface{}) (interface{}, error) {
+		processed := payload.(hookstage.ProcessedAuctionRequestPayload)
+		updated := *processed.BidRequest
+		updated.TMax = 500
+		return hookstage.ProcessedAuctionRequestPayload{BidRequest: &updated}, nil
+	}, hookstage.MutationUpdate, "bidrequest", "tmax")
+
+	executor := NewHookExecutor(mockPlanBuilder{plans: map[hookstage.Stage]hooks.Plan{
+		hookstage.ProcessedAuctionRequest: {groupOf(time.Second, mockModule{result: hookstage.HookResult{ChangeSet: changeSet}})},
+	}}, hooks.EndpointAuction)
+
+	original := &openrtb2.BidRequest{ID: "request", TMax: 100}
+	updated, rejectErr := executor.ExecuteProcessedAuctionStage(original)
+	assert.Nil(t, rejectErr)
+	assert.Equal(t, int64(500), updated.TMax)
+	assert.Equal(t, int64(100), original.TMax, "the hook mutation must not modify the original request")
+}
+
+func TestExecuteRawBidderResponseStage(t *testing.T) {
+	changeSet := hookstage.ChangeSet{}
+	changeSet.AddMutation(func(payload interface{}) (interface{}, error) {
+		response := payload.(hookstage.RawBidderResponsePayload)
+		response.Bids = response.Bids[1:]
+		return response, nil
+	}, hookstage.MutationDelete, "bids")
+
+	executor := NewHookExecutor(mockPlanBuilder{plans: map[hookstage.Stage]hooks.Plan{
+		hookstage.RawBidderResponse: {groupOf(time.Second, mockModule{result: hookstage.HookResult{ChangeSet: changeSet}})},
+	}}, hooks.EndpointAuction)
+
+	bids := []*adapters.TypedBid{{Bid: &openrtb2.Bid{ID: "bid-1"}}, {Bid: &openrtb2.Bid{ID: "bid-2"}}}
+	updated, rejectErr := executor.ExecuteRawBidderResponseStage(bids, "appnexus")
+	assert.Nil(t, rejectErr)
+	assert.Equal(t, bids[1:], updated)
+
+	outcomes := executor.GetOutcomes()
+	if assert.Len(t, outcomes, 1) {
+		assert.Equal(t, "appnexus", outcomes[0].Entity)
+		assert.Equal(t, "raw-bidder-response", outcomes[0].Stage)
+	}
+}
+
+func TestModuleContextSharedAcrossStages(t *testing.T) {
+	var received []hookstage.ModuleContext
+	module := contextModule{received: &received}
+	executor := NewHookExecutor(mockPlanBuilder{plans: map[hookstage.Stage]hooks.Plan{
+		hookstage.Entrypoint:              {groupOf(time.Second, module)},
+		hookstage.ProcessedAuctionRequest: {groupOf(time.Second, module)},
+	}}, hooks.EndpointAuction)
+
+	executor.ExecuteEntrypointStage(httptest.NewRequest("POST", "/openrtb2/auction", nil), nil)
+	executor.SetAccount(&config.Account{ID: "account"})
+	executor.ExecuteProcessedAuctionStage(&openrtb2.BidRequest{})
+
+	assert.Equal(t, []hookstage.ModuleContext{nil, {"calls": 1}}, received)
+}
+
+func TestEnrichExtBidResponse(t *testing.T) {
+	outcomes := []StageOutcome{
+		{
+			Entity:              "http-request",
+			Stage:               "entrypoint",
+			ExecutionTimeMillis: 2,
+			Groups: []GroupOutcome{{
+				ExecutionTimeMillis: 2,
+				InvocationResults: []HookOutcome{{
+					HookID:              HookID{ModuleCode: "vendor.module", HookImplCode: "code"},
+					Status:              StatusSuccess,
+					Action:              ActionNone,
+					Message:             "done",
+					ExecutionTimeMillis: 2,
+					Errors:              []string{"error"},
+					Warnings:            []string{"warning"},
+				}},
+			}},
+		},
+	}
+	expectedModules := `{"errors":{"vendor.module":{"code":["error"]}},"warnings":{"vendor.module":{"code":["warning"]}},"trace":{"execution_time_millis":2,"stages":[{"stage":"entrypoint","execution_time_millis":2,"outcomes":[{"entity":"http-request","execution_time_millis":2,"groups":[{"execution_time_millis":2,"invocation_results":[{"hook_id":{"module_code":"vendor.module","hook_impl_code":"code"},"status":"success","action":"no_action","message":"done","execution_time_millis":2}]}]}]}]}}`
+
+	testCases := []struct {
+		description string
+		ext         json.RawMessage
+		request     *openrtb2.BidRequest
+		account     *config.Account
+		expectedExt string
+	}{
+		{
+			description: "Debug disabled",
+			ext:         json.RawMessage(`{"prebid":{"auctiontimestamp":1}}`),
+			request:     &openrtb2.BidRequest{},
+			account:     &config.Account{DebugAllow: true},
+			expectedExt: `{"prebid":{"auctiontimestamp":1}}`,
+		},
+		{
+			description: "Debug not allowed for the account",
+			ext:         json.RawMessage(`{"prebid":{"auctiontimestamp":1}}`),
+			request:     &openrtb2.BidRequest{Test: 1},
+			account:     &config.Account{DebugAllow: false},
+			expectedExt: `{"prebid":{"auctiontimestamp":1}}`,
+		},
+		{
+			description: "Debug enabled with request.test",
+			ext:         json.RawMessage(`{"prebid":{"auctiontimestamp":1}}`),
+			request:     &openrtb2.BidRequest{Test: 1},
+			account:     &config.Account{DebugAllow: true},
+			expectedExt: `{"prebid":{"auctiontimestamp":1,"modules":` + expectedModules + `}}`,
+		},
+		{
+			description: "Debug enabled with request.ext.prebid.debug before the account is resolved",
+			request:     &openrtb2.BidRequest{Ext: json.RawMessage(`{"prebid":{"debug":true}}`)},
+			expectedExt: `{"prebid":{"modules":` + expectedModules + `}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		ext, err := EnrichExtBidResponse(test.ext, outcomes, test.request, test.account)
+		assert.NoError(t, err, test.description)
+		assert.JSONEq(t, test.expectedExt, string(ext), test.description)
+	}
+}
@@ -0,0 +1,87 @@
+package hookexecution
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Status tells how the execution of a hook went.
+type Status string
+
+const (
+	StatusSuccess          Status = "success"
+	StatusTimeout          Status = "timeout"
+	StatusFailure          Status = "failure"
+	StatusExecutionFailure Status = "execution_failure"
+)
+
+// Action tells what the hook did to the payload.
+type Action string
+
+const (
+	ActionNone   Action = "no_action"
+	ActionUpdate Action = "update"
+	ActionReject Action = "reject"
+)
+
+// Entities the stage outcomes are reported for. The bidder stages are reported for the bidder name.
+const (
+	entityHttpRequest              = "http-request"
+	entityAuctionRequest           = "auction-request"
+	entityAllProcessedBidResponses = "all-processed-bid-responses"
+	entityAuctionResponse          = "auction-response"
+)
+
+// HookID identifies a hook of an execution plan.
+type HookID struct {
+	ModuleCode   string `json:"module_code"`
+	HookImplCode string `json:"hook_impl_code"`
+}
+
+// HookOutcome is the result of a single hook invocation.
+type HookOutcome struct {
+	HookID              HookID          `json:"hook_id"`
+	Status              Status          `json:"status"`
+	Action              Action          `json:"action"`
+	Message             string          `json:"message"`
+	DebugMessages       []string        `json:"debug_messages,omitempty"`
+	AnalyticsTags       json.RawMessage `json:"analytics_tags,omitempty"`
+	ExecutionTimeMillis int64           `json:"execution_time_millis"`
+	Errors              []string        `json:"-"`
+	Warnings            []string        `json:"-"`
+}
+
+// GroupOutcome holds the results of the hooks of a group, in the order of the execution plan.
+type GroupOutcome struct {
+	ExecutionTimeMillis int64         `json:"execution_time_millis"`
+	InvocationResults   []HookOutcome `json:"invocation_results"`
+}
+
+// StageOutcome holds the results of the groups executed for a stage and an entity.
+type StageOutcome struct {
+	Entity              string         `json:"entity"`
+	Stage               string         `json:"-"`
+	ExecutionTimeMillis int64          `json:"execution_time_millis"`
+	Groups              []GroupOutcome `json:"groups"`
+}
+
+// RejectError is returned when a hook rejects the payload of a stage.
+type RejectError struct {
+	NBR   int
+	Hook  HookID
+	Stage string
+}
+
+func (e *RejectError) Error() string {
+	return fmt.Sprintf(`Module %s (hook: %s) rejected request with code %d at %s stage`, e.Hook.ModuleCode, e.Hook.HookImplCode, e.NBR, e.Stage)
+}
+
+// FindFirstRejectOrNil returns the first RejectError of the list.
+func FindFirstRejectOrNil(errs []error) *RejectError {
+	for _, err := range errs {
+		if rejectErr, ok := err.(*RejectError); ok {
+			return rejectErr
+		}
+	}
+	return nil
+}
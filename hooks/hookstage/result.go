@@ -0,0 +1,89 @@
+package hookstage
+
+import (
+	"encoding/json"
+)
+
+// ModuleInvocationContext holds the data a hook is invoked with, besides the stage payload.
+type ModuleInvocationContext struct {
+	// AccountID is empty for the stages executed before the account is resolved.
+	AccountID string
+	// Endpoint is the path of the endpoint serving the request, e.g. "/openrtb2/auction".
+	Endpoint string
+	// ModuleContext is shared by the hooks of a module across the stages of a single request. Hooks must
+	// not modify it but return an updated copy in their result instead.
+	ModuleContext ModuleContext
+}
+
+// ModuleContext holds the data a module wants to carry across stages. The context returned by a hook
+// replaces the one the next hook of the same module is invoked with.
+type ModuleContext map[string]interface{}
+
+// HookResult is what a hook returns to the executor.
+type HookResult struct {
+	// Reject stops the processing of the payload. Depending on the stage, the whole request is answered
+	// with no bids, or a single bidder is dropped from the auction.
+	Reject bool
+	// NbrCode is the OpenRTB no-bid reason returned when the request is rejected.
+	NbrCode int
+	// Message is a human readable explanation of the result, surfaced in the debug trace.
+	Message string
+	// ChangeSet describes the changes to the payload. They are applied once every hook of the group has
+	// returned, unless the payload has been rejected.
+	ChangeSet     ChangeSet
+	Errors        []string
+	Warnings      []string
+	DebugMessages []string
+	// AnalyticsTags are free form values the module wants to expose to the analytics modules.
+	AnalyticsTags json.RawMessage
+	ModuleContext ModuleContext
+}
+
+// MutationType describes the kind of change a mutation makes to the payload.
+type MutationType int
+
+const (
+	MutationAdd MutationType = iota
+	MutationUpdate
+	MutationDelete
+)
+
+func (t MutationType) String() string {
+	switch t {
+	case MutationAdd:
+		return "add"
+	case MutationUpdate:
+		return "update"
+	case MutationDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// MutationFunc receives the stage payload and returns its updated copy. The payload is passed as the
+// stage's payload type (e.g. a ProcessedAuctionRequestPayload) and the same type must be returned.
+type MutationFunc func(payload interface{}) (interface{}, error)
+
+// Mutation is a single change of the payload. Key describes the changed part of the payload for the
+// debug trace, e.g. []string{"bidrequest", "user", "data"}.
+type Mutation struct {
+	Type  MutationType
+	Key   []string
+	Apply MutationFunc
+}
+
+// ChangeSet collects the mutations a hook wants to apply to the stage payload.
+type ChangeSet struct {
+	mutations []Mutation
+}
+
+// AddMutation records a mutation to apply after the hook has returned.
+func (c *ChangeSet) AddMutation(apply MutationFunc, t MutationType, key ...string) *ChangeSet {
+	c.mutations = append(c.mutations, Mutation{Type: t, Key: key, Apply: apply})
+	return c
+}
+
+// Mutations returns the recorded mutations in the order they were added.
+func (c ChangeSet) Mutations() []Mutation {
+	return c.mutations
+}
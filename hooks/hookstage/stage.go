@@ -0,0 +1,113 @@
+package hookstage
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Stage is the name of a point of the auction pipeline where hooks can be plugged in.
+type Stage string
+
+const (
+	Entrypoint               Stage = "entrypoint"
+	RawAuctionRequest        Stage = "raw-auction-request"
+	ProcessedAuctionRequest  Stage = "processed-auction-request"
+	BidderRequest            Stage = "bidder-request"
+	RawBidderResponse        Stage = "raw-bidder-response"
+	AllProcessedBidResponses Stage = "all-processed-bid-responses"
+	AuctionResponse          Stage = "auction-response"
+)
+
+// Stages lists every stage in the order they are executed during an auction.
+func Stages() []Stage {
+	return []Stage{
+		Entrypoint,
+		RawAuctionRequest,
+		ProcessedAuctionRequest,
+		BidderRequest,
+		RawBidderResponse,
+		AllProcessedBidResponses,
+		AuctionResponse,
+	}
+}
+
+// EntrypointHook is invoked as soon as the HTTP request is received, before the body is parsed.
+type EntrypointHook interface {
+	HandleEntrypointHook(ctx context.Context, miCtx ModuleInvocationContext, payload EntrypointPayload) (HookResult, error)
+}
+
+// EntrypointPayload is passed to entrypoint hooks and mutations. Mutations must return an EntrypointPayload.
+type EntrypointPayload struct {
+	Request *http.Request
+	Body    []byte
+}
+
+// RawAuctionRequestHook is invoked with the raw request body, before stored requests are merged into it.
+type RawAuctionRequestHook interface {
+	HandleRawAuctionRequestHook(ctx context.Context, miCtx ModuleInvocationContext, payload RawAuctionRequestPayload) (HookResult, error)
+}
+
+// RawAuctionRequestPayload is passed to raw-auction-request hooks and mutations. Mutations must return a
+// RawAuctionRequestPayload.
+type RawAuctionRequestPayload []byte
+
+// ProcessedAuctionRequestHook is invoked with the validated OpenRTB request, before it is split among the bidders.
+type ProcessedAuctionRequestHook interface {
+	HandleProcessedAuctionRequestHook(ctx context.Context, miCtx ModuleInvocationContext, payload ProcessedAuctionRequestPayload) (HookResult, error)
+}
+
+// ProcessedAuctionRequestPayload is passed to processed-auction-request hooks and mutations. Mutations must
+// return a ProcessedAuctionRequestPayload.
+type ProcessedAuctionRequestPayload struct {
+	BidRequest *openrtb2.BidRequest
+}
+
+// BidderRequestHook is invoked once per bidder with the request which is about to be sent to that bidder.
+type BidderRequestHook interface {
+	HandleBidderRequestHook(ctx context.Context, miCtx ModuleInvocationContext, payload BidderRequestPayload) (HookResult, error)
+}
+
+// BidderRequestPayload is passed to bidder-request hooks and mutations. Mutations must return a
+// BidderRequestPayload.
+type BidderRequestPayload struct {
+	Bidder     string
+	BidRequest *openrtb2.BidRequest
+}
+
+// RawBidderResponseHook is invoked once per bidder with the bids it returned, before price floors are enforced.
+type RawBidderResponseHook interface {
+	HandleRawBidderResponseHook(ctx context.Context, miCtx ModuleInvocationContext, payload RawBidderResponsePayload) (HookResult, error)
+}
+
+// RawBidderResponsePayload is passed to raw-bidder-response hooks and mutations. Mutations must return a
+// RawBidderResponsePayload.
+type RawBidderResponsePayload struct {
+	Bidder string
+	Bids   []*adapters.TypedBid
+}
+
+// AllProcessedBidResponsesHook is invoked once every bidder has responded, before the auction is run.
+type AllProcessedBidResponsesHook interface {
+	HandleAllProcessedBidResponsesHook(ctx context.Context, miCtx ModuleInvocationContext, payload AllProcessedBidResponsesPayload) (HookResult, error)
+}
+
+// AllProcessedBidResponsesPayload is passed to all-processed-bid-responses hooks and mutations. Mutations must
+// return an AllProcessedBidResponsesPayload.
+type AllProcessedBidResponsesPayload struct {
+	Responses map[openrtb_ext.BidderName][]*adapters.TypedBid
+}
+
+// AuctionResponseHook is invoked with the response which is about to be returned by the endpoint.
+type AuctionResponseHook interface {
+	HandleAuctionResponseHook(ctx context.Context, miCtx ModuleInvocationContext, payload AuctionResponsePayload) (HookResult, error)
+}
+
+// AuctionResponsePayload is passed to auction-response hooks and mutations. Mutations must return an
+// AuctionResponsePayload.
+type AuctionResponsePayload struct {
+	BidResponse *openrtb2.BidResponse
+}
@@ -0,0 +1,135 @@
+package hooks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+)
+
+// Endpoints supporting hooks, as referenced in the execution plans
+const (
+	EndpointAuction = "/openrtb2/auction"
+	EndpointAmp     = "/openrtb2/amp"
+	EndpointVideo   = "/openrtb2/video"
+)
+
+// HookWrapper is a hook referenced by an execution plan.
+type HookWrapper struct {
+	// Module is the code of the module implementing the hook.
+	Module string
+	// Code is the hook_impl_code the plan refers to the hook with.
+	Code string
+	// Hook is the module, which implements the hook interface of the stage.
+	Hook interface{}
+}
+
+// Group is a set of hooks executed in parallel, within the group timeout.
+type Group struct {
+	Timeout time.Duration
+	Hooks   []HookWrapper
+}
+
+// Plan is the ordered list of groups executed for a stage.
+type Plan []Group
+
+// ExecutionPlanBuilder resolves the hooks to execute at each stage of an endpoint.
+type ExecutionPlanBuilder interface {
+	// PlanForStage returns the host groups followed by the account ones. The account is nil for the
+	// stages executed before it is resolved.
+	PlanForStage(endpoint string, stage hookstage.Stage, account *config.Account) Plan
+}
+
+// EmptyPlanBuilder is used when hooks are disabled. It never returns any hook.
+type EmptyPlanBuilder struct{}
+
+func (EmptyPlanBuilder) PlanForStage(endpoint string, stage hookstage.Stage, account *config.Account) Plan {
+	return nil
+}
+
+// NewExecutionPlanBuilder returns a builder of the plans defined in the host and account configurations.
+// The host plan must only reference hooks implemented by the repository modules.
+func NewExecutionPlanBuilder(hooks config.Hooks, repo HookRepository) (ExecutionPlanBuilder, error) {
+	if !hooks.Enabled {
+		return EmptyPlanBuilder{}, nil
+	}
+
+	builder := &executionPlanBuilder{
+		repo:      repo,
+		hostPlans: make(map[string]map[hookstage.Stage]Plan),
+	}
+
+	for endpoint, endpointPlan := range hooks.HostExecutionPlan.Endpoints {
+		builder.hostPlans[endpoint] = make(map[hookstage.Stage]Plan)
+		for stage, stagePlan := range endpointPlan.Stages {
+			plan, err := builder.resolvePlan(hookstage.Stage(stage), stagePlan)
+			if err != nil {
+				return nil, fmt.Errorf("invalid host execution plan for %s %s: %v", endpoint, stage, err)
+			}
+			builder.hostPlans[endpoint][hookstage.Stage(stage)] = plan
+		}
+	}
+
+	return builder, nil
+}
+
+type executionPlanBuilder struct {
+	repo      HookRepository
+	hostPlans map[string]map[hookstage.Stage]Plan
+}
+
+func (b *executionPlanBuilder) PlanForStage(endpoint string, stage hookstage.Stage, account *config.Account) Plan {
+	plan := append(Plan{}, b.hostPlans[endpoint][stage]...)
+	if account == nil {
+		return plan
+	}
+
+	stagePlan, ok := account.Hooks.ExecutionPlan.Endpoints[endpoint].Stages[string(stage)]
+	if !ok {
+		return plan
+	}
+
+	accountPlan, err := b.resolvePlan(stage, stagePlan)
+	if err != nil {
+		// An account misconfiguration must not break its auctions, so the faulty hooks are just skipped
+		glog.Warningf("Invalid execution plan for account %s, %s %s: %v", account.ID, endpoint, stage, err)
+	}
+
+	return append(plan, accountPlan...)
+}
+
+// resolvePlan looks up the hooks referenced by the stage plan. The returned plan holds every hook which
+// could be resolved, even when an error is returned. The groups without a positive timeout are dropped,
+// since the account plans aren't validated along with the host config.
+func (b *executionPlanBuilder) resolvePlan(stage hookstage.Stage, stagePlan config.HookExecutionStage) (Plan, error) {
+	var err error
+	plan := make(Plan, 0, len(stagePlan.Groups))
+	for _, groupPlan := range stagePlan.Groups {
+		if groupPlan.Timeout <= 0 {
+			err = fmt.Errorf("group timeout must be positive. Got %d", groupPlan.Timeout)
+			continue
+		}
+		group := Group{
+			Timeout: time.Duration(groupPlan.Timeout) * time.Millisecond,
+			Hooks:   make([]HookWrapper, 0, len(groupPlan.HookSequence)),
+		}
+		for _, step := range groupPlan.HookSequence {
+			module, ok := b.repo.GetModule(step.ModuleCode)
+			if !ok {
+				err = fmt.Errorf(`unknown module "%s"`, step.ModuleCode)
+				continue
+			}
+			if !implementsStage(module, stage) {
+				err = fmt.Errorf(`module "%s" doesn't implement the %s stage`, step.ModuleCode, stage)
+				continue
+			}
+			group.Hooks = append(group.Hooks, HookWrapper{Module: step.ModuleCode, Code: step.HookImplCode, Hook: module})
+		}
+		if len(group.Hooks) > 0 {
+			plan = append(plan, group)
+		}
+	}
+	return plan, err
+}
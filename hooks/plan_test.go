@@ -0,0 +1,204 @@
+package hooks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEntrypointModule struct{}
+
+func (fakeEntrypointModule) HandleEntrypointHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.EntrypointPayload) (hookstage.HookResult, error) {
+	return hookstage.HookResult{}, nil
+}
+
+type fakeProcessedAuctionModule struct{}
+
+func (fakeProcessedAuctionModule) HandleProcessedAuctionRequestHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.ProcessedAuctionRequestPayload) (hookstage.HookResult, error) {
+	return hookstage.HookResult{}, nil
+}
+
+func newStagePlan(timeout int, steps ...config.HookExecutionStep) config.HookExecutionStage {
+	return config.HookExecutionStage{
+		Groups: []config.HookExecutionGroup{{Timeout: timeout, HookSequence: steps}},
+	}
+}
+
+func TestNewHookRepository(t *testing.T) {
+	_, err := NewHookRepository(map[string]interface{}{"vendor.module": fakeEntrypointModule{}})
+	assert.NoError(t, err)
+
+	_, err = NewHookRepository(map[string]interface{}{"vendor.module": struct{}{}})
+	assert.EqualError(t, err, `module "vendor.module" doesn't implement any hook`)
+}
+
+func TestNewExecutionPlanBuilder(t *testing.T) {
+	repo, _ := NewHookRepository(map[string]interface{}{
+		"vendor.entrypoint": fakeEntrypointModule{},
+		"vendor.processed":  fakeProcessedAuctionModule{},
+	})
+
+	testCases := []struct {
+		description string
+		hooks       config.Hooks
+		expectedErr string
+	}{
+		{
+			description: "Hooks disabled",
+			hooks: config.Hooks{
+				HostExecutionPlan: config.HookExecutionPlan{Endpoints: map[string]config.HookExecutionEndpoint{
+					EndpointAuction: {Stages: map[string]config.HookExecutionStage{
+						"entrypoint": newStagePlan(5, config.HookExecutionStep{ModuleCode: "vendor.unknown", HookImplCode: "code"}),
+					}},
+				}},
+			},
+		},
+		{
+			description: "Valid host plan",
+			hooks: config.Hooks{
+				Enabled: true,
+				HostExecutionPlan: config.HookExecutionPlan{Endpoints: map[string]config.HookExecutionEndpoint{
+					EndpointAuction: {Stages: map[string]config.HookExecutionStage{
+						"entrypoint": newStagePlan(5, config.HookExecutionStep{ModuleCode: "vendor.entrypoint", HookImplCode: "code"}),
+					}},
+				}},
+			},
+		},
+		{
+			description: "Unknown module",
+			hooks: config.Hooks{
+				Enabled: true,
+				HostExecutionPlan: config.HookExecutionPlan{Endpoints: map[string]config.HookExecutionEndpoint{
+					EndpointAuction: {Stages: map[string]config.HookExecutionStage{
+						"entrypoint": newStagePlan(5, config.HookExecutionStep{ModuleCode: "vendor.unknown", HookImplCode: "code"}),
+					}},
+				}},
+			},
+			expectedErr: `invalid host execution plan for /openrtb2/auction entrypoint: unknown module "vendor.unknown"`,
+		},
+		{
+			description: "Module not implementing the stage",
+			hooks: config.Hooks{
+				Enabled: true,
+				HostExecutionPlan: config.HookExecutionPlan{Endpoints: map[string]config.HookExecutionEndpoint{
+					EndpointAuction: {Stages: map[string]config.HookExecutionStage{
+						"entrypoint": newStagePlan(5, config.HookExecutionStep{ModuleCode: "vendor.processed", HookImplCode: "code"}),
+					}},
+				}},
+			},
+			expectedErr: `invalid host execution plan for /openrtb2/auction entrypoint: module "vendor.processed" doesn't implement the entrypoint stage`,
+		},
+	}
+
+	for _, test := range testCases {
+		_, err := NewExecutionPlanBuilder(test.hooks, repo)
+		if test.expectedErr == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+		}
+	}
+}
+
+func TestPlanForStage(t *testing.T) {
+	repo, _ := NewHookRepository(map[string]interface{}{
+		"vendor.entrypoint": fakeEntrypointModule{},
+		"vendor.processed":  fakeProcessedAuctionModule{},
+	})
+	builder, err := NewExecutionPlanBuilder(config.Hooks{
+		Enabled: true,
+		HostExecutionPlan: config.HookExecutionPlan{Endpoints: map[string]config.HookExecutionEndpoint{
+			EndpointAuction: {Stages: map[string]config.HookExecutionStage{
+				"processed-auction-request": newStagePlan(5, config.HookExecutionStep{ModuleCode: "vendor.processed", HookImplCode: "host"}),
+			}},
+		}},
+	}, repo)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	account := &config.Account{
+		ID: "account",
+		Hooks: config.AccountHooks{ExecutionPlan: config.HookExecutionPlan{Endpoints: map[string]config.HookExecutionEndpoint{
+			EndpointAuction: {Stages: map[string]config.HookExecutionStage{
+				"processed-auction-request": newStagePlan(10,
+					config.HookExecutionStep{ModuleCode: "vendor.processed", HookImplCode: "account"},
+					config.HookExecutionStep{ModuleCode: "vendor.unknown", HookImplCode: "account"},
+				),
+			}},
+		}}},
+	}
+
+	hostGroup := Group{
+		Timeout: 5 * time.Millisecond,
+		Hooks:   []HookWrapper{{Module: "vendor.processed", Code: "host", Hook: fakeProcessedAuctionModule{}}},
+	}
+	accountGroup := Group{
+		Timeout: 10 * time.Millisecond,
+		Hooks:   []HookWrapper{{Module: "vendor.processed", Code: "account", Hook: fakeProcessedAuctionModule{}}},
+	}
+
+	testCases := []struct {
+		description  string
+		endpoint     string
+		stage        hookstage.Stage
+		account      *config.Account
+		expectedPlan Plan
+	}{
+		{
+			description:  "Host plan only before the account is resolved",
+			endpoint:     EndpointAuction,
+			stage:        hookstage.ProcessedAuctionRequest,
+			expectedPlan: Plan{hostGroup},
+		},
+		{
+			description:  "Account groups after host ones, unknown hooks skipped",
+			endpoint:     EndpointAuction,
+			stage:        hookstage.ProcessedAuctionRequest,
+			account:      account,
+			expectedPlan: Plan{hostGroup, accountGroup},
+		},
+		{
+			description: "Account groups without a positive timeout skipped",
+			endpoint:    EndpointAuction,
+			stage:       hookstage.ProcessedAuctionRequest,
+			account: &config.Account{
+				ID: "timeout",
+				Hooks: config.AccountHooks{ExecutionPlan: config.HookExecutionPlan{Endpoints: map[string]config.HookExecutionEndpoint{
+					EndpointAuction: {Stages: map[string]config.HookExecutionStage{
+						"processed-auction-request": {Groups: []config.HookExecutionGroup{
+							{Timeout: 0, HookSequence: []config.HookExecutionStep{{ModuleCode: "vendor.processed", HookImplCode: "account"}}},
+							{Timeout: -1, HookSequence: []config.HookExecutionStep{{ModuleCode: "vendor.processed", HookImplCode: "account"}}},
+							{Timeout: 10, HookSequence: []config.HookExecutionStep{{ModuleCode: "vendor.processed", HookImplCode: "account"}}},
+						}},
+					}},
+				}}},
+			},
+			expectedPlan: Plan{hostGroup, accountGroup},
+		},
+		{
+			description:  "Account without plan",
+			endpoint:     EndpointAuction,
+			stage:        hookstage.ProcessedAuctionRequest,
+			account:      &config.Account{ID: "other"},
+			expectedPlan: Plan{hostGroup},
+		},
+		{
+			description:  "Other endpoint",
+			endpoint:     EndpointAmp,
+			stage:        hookstage.ProcessedAuctionRequest,
+			account:      account,
+			expectedPlan: Plan{},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedPlan, builder.PlanForStage(test.endpoint, test.stage, test.account), test.description)
+	}
+
+	assert.Nil(t, EmptyPlanBuilder{}.PlanForStage(EndpointAuction, hookstage.Entrypoint, account))
+}
@@ -0,0 +1,70 @@
+package hooks
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/hooks/hookstage"
+)
+
+// HookRepository gives access to the modules implementing hooks, keyed by module code.
+type HookRepository interface {
+	GetModule(code string) (interface{}, bool)
+}
+
+// NewHookRepository indexes the hooks of the given modules. It returns an error if a module doesn't
+// implement any hook.
+func NewHookRepository(modules map[string]interface{}) (HookRepository, error) {
+	repo := &hookRepository{modules: make(map[string]interface{}, len(modules))}
+	for code, module := range modules {
+		if !implementsAnyHook(module) {
+			return nil, fmt.Errorf(`module "%s" doesn't implement any hook`, code)
+		}
+		repo.modules[code] = module
+	}
+	return repo, nil
+}
+
+type hookRepository struct {
+	modules map[string]interface{}
+}
+
+func implementsAnyHook(module interface{}) bool {
+	switch module.(type) {
+	case hookstage.EntrypointHook,
+		hookstage.RawAuctionRequestHook,
+		hookstage.ProcessedAuctionRequestHook,
+		hookstage.BidderRequestHook,
+		hookstage.RawBidderResponseHook,
+		hookstage.AllProcessedBidResponsesHook,
+		hookstage.AuctionResponseHook:
+		return true
+	}
+	return false
+}
+
+// implementsStage tells if the module has a hook for the stage.
+func implementsStage(module interface{}, stage hookstage.Stage) bool {
+	var ok bool
+	switch stage {
+	case hookstage.Entrypoint:
+		_, ok = module.(hookstage.EntrypointHook)
+	case hookstage.RawAuctionRequest:
+		_, ok = module.(hookstage.RawAuctionRequestHook)
+	case hookstage.ProcessedAuctionRequest:
+		_, ok = module.(hookstage.ProcessedAuctionRequestHook)
+	case hookstage.BidderRequest:
+		_, ok = module.(hookstage.BidderRequestHook)
+	case hookstage.RawBidderResponse:
+		_, ok = module.(hookstage.RawBidderResponseHook)
+	case hookstage.AllProcessedBidResponses:
+		_, ok = module.(hookstage.AllProcessedBidResponsesHook)
+	case hookstage.AuctionResponse:
+		_, ok = module.(hookstage.AuctionResponseHook)
+	}
+	return ok
+}
+
+func (r *hookRepository) GetModule(code string) (interface{}, bool) {
+	module, ok := r.modules[code]
+	return module, ok
+}
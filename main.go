@@ -51,6 +51,16 @@ func loadConfig() (*config.Configuration, error) {
 	return config.New(v)
 }
 
+// configFileUsed returns the path of the config file read by loadConfig, or an empty string if there's none.
+func configFileUsed() string {
+	v := viper.New()
+	config.SetupViper(v, configFileName)
+	if err := v.ReadInConfig(); err != nil {
+		return ""
+	}
+	return v.ConfigFileUsed()
+}
+
 func serve(revision string, cfg *config.Configuration) error {
 	fetchingInterval := time.Duration(cfg.CurrencyConverter.FetchIntervalSeconds) * time.Second
 	staleRatesThreshold := time.Duration(cfg.CurrencyConverter.StaleRatesSeconds) * time.Second
@@ -64,10 +74,16 @@ func serve(revision string, cfg *config.Configuration) error {
 		return err
 	}
 
+	if cfg.BidderConfigReload.Enabled {
+		if err := r.EnableBidderConfigReload(cfg.BidderConfigReload, loadConfig, configFileUsed()); err != nil {
+			return err
+		}
+	}
+
 	pbc.InitPrebidCache(cfg.CacheURL.GetBaseURL())
 
 	corsRouter := router.SupportCORS(r)
-	server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(revision, currencyConverter, fetchingInterval), r.MetricsEngine)
+	server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(revision, currencyConverter, fetchingInterval, r.AdminRouter), r.MetricsEngine)
 
 	r.Shutdown()
 	return nil
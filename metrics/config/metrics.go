@@ -202,6 +202,13 @@ func (me *MultiMetricsEngine) RecordSyncerSet(key string, status metrics.SyncerS
 	}
 }
 
+// RecordSyncerUIDEvicted across all engines
+func (me *MultiMetricsEngine) RecordSyncerUIDEvicted(key string) {
+	for _, thisME := range *me {
+		thisME.RecordSyncerUIDEvicted(key)
+	}
+}
+
 // RecordStoredReqCacheResult across all engines
 func (me *MultiMetricsEngine) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
 	for _, thisME := range *me {
@@ -258,6 +265,55 @@ func (me *MultiMetricsEngine) RecordAdapterGDPRRequestBlocked(adapter openrtb_ex
 	}
 }
 
+// RecordAdapterBidValidation across all engines
+func (me *MultiMetricsEngine) RecordAdapterBidValidation(adapter openrtb_ext.BidderName, validation metrics.BidValidation, rejected bool) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterBidValidation(adapter, validation, rejected)
+	}
+}
+
+// RecordAdsCertReq across all engines
+func (me *MultiMetricsEngine) RecordAdsCertReq(success bool) {
+	for _, thisME := range *me {
+		thisME.RecordAdsCertReq(success)
+	}
+}
+
+// RecordAdsCertSignTime across all engines
+func (me *MultiMetricsEngine) RecordAdsCertSignTime(adsCertSignTime time.Duration) {
+	for _, thisME := range *me {
+		thisME.RecordAdsCertSignTime(adsCertSignTime)
+	}
+}
+
+// RecordBidderConfigReload across all engines
+func (me *MultiMetricsEngine) RecordBidderConfigReload(success bool) {
+	for _, thisME := range *me {
+		thisME.RecordBidderConfigReload(success)
+	}
+}
+
+// RecordAdapterCircuitBreakerState across all engines
+func (me *MultiMetricsEngine) RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state metrics.CircuitBreakerState) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterCircuitBreakerState(adapterName, state)
+	}
+}
+
+// RecordAdapterCircuitBreakerRejection across all engines
+func (me *MultiMetricsEngine) RecordAdapterCircuitBreakerRejection(adapterName openrtb_ext.BidderName) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterCircuitBreakerRejection(adapterName)
+	}
+}
+
+// RecordAdapterCompressedRequestSize across all engines
+func (me *MultiMetricsEngine) RecordAdapterCompressedRequestSize(adapterName openrtb_ext.BidderName, size int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterCompressedRequestSize(adapterName, size)
+	}
+}
+
 // DummyMetricsEngine is a Noop metrics engine in case no metrics are configured. (may also be useful for tests)
 type DummyMetricsEngine struct{}
 
@@ -341,6 +397,10 @@ func (me *DummyMetricsEngine) RecordSetUid(status metrics.SetUidStatus) {
 func (me *DummyMetricsEngine) RecordSyncerSet(key string, status metrics.SyncerSetUidStatus) {
 }
 
+// RecordSyncerUIDEvicted as a noop
+func (me *DummyMetricsEngine) RecordSyncerUIDEvicted(key string) {
+}
+
 // RecordStoredReqCacheResult as a noop
 func (me *DummyMetricsEngine) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
 }
@@ -372,3 +432,31 @@ func (me *DummyMetricsEngine) RecordRequestPrivacy(privacy metrics.PrivacyLabels
 // RecordAdapterGDPRRequestBlocked as a noop
 func (me *DummyMetricsEngine) RecordAdapterGDPRRequestBlocked(adapter openrtb_ext.BidderName) {
 }
+
+// RecordAdapterBidValidation as a noop
+func (me *DummyMetricsEngine) RecordAdapterBidValidation(adapter openrtb_ext.BidderName, validation metrics.BidValidation, rejected bool) {
+}
+
+// RecordAdsCertReq as a noop
+func (me *DummyMetricsEngine) RecordAdsCertReq(success bool) {
+}
+
+// RecordAdsCertSignTime as a noop
+func (me *DummyMetricsEngine) RecordAdsCertSignTime(adsCertSignTime time.Duration) {
+}
+
+// RecordBidderConfigReload as a noop
+func (me *DummyMetricsEngine) RecordBidderConfigReload(success bool) {
+}
+
+// RecordAdapterCircuitBreakerState as a noop
+func (me *DummyMetricsEngine) RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state metrics.CircuitBreakerState) {
+}
+
+// RecordAdapterCircuitBreakerRejection as a noop
+func (me *DummyMetricsEngine) RecordAdapterCircuitBreakerRejection(adapterName openrtb_ext.BidderName) {
+}
+
+// RecordAdapterCompressedRequestSize as a noop
+func (me *DummyMetricsEngine) RecordAdapterCompressedRequestSize(adapterName openrtb_ext.BidderName, size int) {
+}
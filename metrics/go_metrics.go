@@ -43,6 +43,7 @@ type Metrics struct {
 	SetUidMeter           metrics.Meter
 	SetUidStatusMeter     map[SetUidStatus]metrics.Meter
 	SyncerSetsMeter       map[string]map[SyncerSetUidStatus]metrics.Meter
+	SyncerEvictionsMeter  map[string]metrics.Meter
 
 	// Media types found in the "imp" JSON object
 	ImpsTypeBanner metrics.Meter
@@ -54,6 +55,15 @@ type Metrics struct {
 	TimeoutNotificationSuccess metrics.Meter
 	TimeoutNotificationFailure metrics.Meter
 
+	// Ads.cert signing metrics
+	AdsCertRequestsSuccess metrics.Meter
+	AdsCertRequestsFailure metrics.Meter
+	AdsCertSignTimer       metrics.Timer
+
+	// Bidder config reload metrics
+	BidderConfigReloadSuccess metrics.Meter
+	BidderConfigReloadFailure metrics.Meter
+
 	// TCF adaption metrics
 	PrivacyCCPARequest       metrics.Meter
 	PrivacyCCPARequestOptOut metrics.Meter
@@ -86,6 +96,12 @@ type AdapterMetrics struct {
 	ConnReused         metrics.Counter
 	ConnWaitTime       metrics.Timer
 	GDPRRequestBlocked metrics.Meter
+	BidValidation      map[BidValidation]*BidValidationMetrics
+	// CircuitBreakerState holds 0 while the circuit breaker is closed, 1 while it's open and 2 while it's half open
+	CircuitBreakerState    metrics.Gauge
+	CircuitBreakerRejected metrics.Meter
+	// CompressedRequestSize holds the size of the compressed request bodies, in bytes
+	CompressedRequestSize metrics.Histogram
 }
 
 type MarkupDeliveryMetrics struct {
@@ -93,6 +109,12 @@ type MarkupDeliveryMetrics struct {
 	NurlMeter metrics.Meter
 }
 
+// BidValidationMetrics houses the counts of the bids failing a validation, by whether they were rejected or kept
+type BidValidationMetrics struct {
+	WarnMeter   metrics.Meter
+	RejectMeter metrics.Meter
+}
+
 type accountMetrics struct {
 	requestMeter      metrics.Meter
 	bidsReceivedMeter metrics.Meter
@@ -143,6 +165,7 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderNa
 		SetUidMeter:                    blankMeter,
 		SetUidStatusMeter:              make(map[SetUidStatus]metrics.Meter),
 		SyncerSetsMeter:                make(map[string]map[SyncerSetUidStatus]metrics.Meter),
+		SyncerEvictionsMeter:           make(map[string]metrics.Meter),
 
 		ImpsTypeBanner: blankMeter,
 		ImpsTypeVideo:  blankMeter,
@@ -152,6 +175,13 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderNa
 		TimeoutNotificationSuccess: blankMeter,
 		TimeoutNotificationFailure: blankMeter,
 
+		AdsCertRequestsSuccess: blankMeter,
+		AdsCertRequestsFailure: blankMeter,
+		AdsCertSignTimer:       blankTimer,
+
+		BidderConfigReloadSuccess: blankMeter,
+		BidderConfigReloadFailure: blankMeter,
+
 		PrivacyCCPARequest:       blankMeter,
 		PrivacyCCPARequestOptOut: blankMeter,
 		PrivacyCOPPARequest:      blankMeter,
@@ -264,6 +294,8 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 		for _, status := range SyncerSetUidStatuses() {
 			newMetrics.SyncerSetsMeter[syncerKey][status] = metrics.GetOrRegisterMeter(fmt.Sprintf("syncer.%s.set.%s", syncerKey, status), registry)
 		}
+
+		newMetrics.SyncerEvictionsMeter[syncerKey] = metrics.GetOrRegisterMeter(fmt.Sprintf("syncer.%s.evicted", syncerKey), registry)
 	}
 
 	for _, a := range exchanges {
@@ -288,6 +320,13 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 	newMetrics.TimeoutNotificationSuccess = metrics.GetOrRegisterMeter("timeout_notification.ok", registry)
 	newMetrics.TimeoutNotificationFailure = metrics.GetOrRegisterMeter("timeout_notification.failed", registry)
 
+	newMetrics.AdsCertRequestsSuccess = metrics.GetOrRegisterMeter("ads_cert_requests.ok", registry)
+	newMetrics.AdsCertRequestsFailure = metrics.GetOrRegisterMeter("ads_cert_requests.failed", registry)
+	newMetrics.AdsCertSignTimer = metrics.GetOrRegisterTimer("ads_cert_sign_time", registry)
+
+	newMetrics.BidderConfigReloadSuccess = metrics.GetOrRegisterMeter("bidder_config_reload.ok", registry)
+	newMetrics.BidderConfigReloadFailure = metrics.GetOrRegisterMeter("bidder_config_reload.failed", registry)
+
 	newMetrics.PrivacyCCPARequest = metrics.GetOrRegisterMeter("privacy.request.ccpa.specified", registry)
 	newMetrics.PrivacyCCPARequestOptOut = metrics.GetOrRegisterMeter("privacy.request.ccpa.opt-out", registry)
 	newMetrics.PrivacyCOPPARequest = metrics.GetOrRegisterMeter("privacy.request.coppa", registry)
@@ -312,7 +351,11 @@ func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMet
 		BidsReceivedMeter: blankMeter,
 		PanicMeter:        blankMeter,
 		MarkupMetrics:     makeBlankBidMarkupMetrics(),
+		BidValidation:     makeBlankBidValidationMetrics(),
 	}
+	newAdapter.CircuitBreakerState = metrics.NilGauge{}
+	newAdapter.CircuitBreakerRejected = blankMeter
+	newAdapter.CompressedRequestSize = &metrics.NilHistogram{}
 	if !disabledMetrics.AdapterConnectionMetrics {
 		newAdapter.ConnCreated = metrics.NilCounter{}
 		newAdapter.ConnReused = metrics.NilCounter{}
@@ -336,6 +379,17 @@ func makeBlankBidMarkupMetrics() map[openrtb_ext.BidType]*MarkupDeliveryMetrics
 	}
 }
 
+func makeBlankBidValidationMetrics() map[BidValidation]*BidValidationMetrics {
+	validationMetrics := make(map[BidValidation]*BidValidationMetrics)
+	for _, validation := range BidValidations() {
+		validationMetrics[validation] = &BidValidationMetrics{
+			WarnMeter:   &metrics.NilMeter{},
+			RejectMeter: &metrics.NilMeter{},
+		}
+	}
+	return validationMetrics
+}
+
 func makeBlankMarkupDeliveryMetrics() *MarkupDeliveryMetrics {
 	return &MarkupDeliveryMetrics{
 		AdmMeter:  &metrics.NilMeter{},
@@ -363,9 +417,17 @@ func registerAdapterMetrics(registry metrics.Registry, adapterOrAccount string,
 	}
 	if adapterOrAccount != "adapter" {
 		am.BidsReceivedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.bids_received", adapterOrAccount, exchange), registry)
+	} else {
+		am.CircuitBreakerState = metrics.GetOrRegisterGauge(fmt.Sprintf("%[1]s.%[2]s.circuit_breaker.state", adapterOrAccount, exchange), registry)
+		am.CircuitBreakerRejected = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.circuit_breaker.rejected", adapterOrAccount, exchange), registry)
+		am.CompressedRequestSize = metrics.GetOrRegisterHistogram(fmt.Sprintf("%[1]s.%[2]s.compressed_request_size", adapterOrAccount, exchange), registry, metrics.NewExpDecaySample(1028, 0.015))
 	}
 	am.PanicMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.requests.panic", adapterOrAccount, exchange), registry)
 	am.GDPRRequestBlocked = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.gdpr_request_blocked", adapterOrAccount, exchange), registry)
+	for validation, validationMetrics := range am.BidValidation {
+		validationMetrics.WarnMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.bid_validation.%[3]s.warn", adapterOrAccount, exchange, validation), registry)
+		validationMetrics.RejectMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.bid_validation.%[3]s.reject", adapterOrAccount, exchange, validation), registry)
+	}
 }
 
 func makeDeliveryMetrics(registry metrics.Registry, prefix string, bidType openrtb_ext.BidType) *MarkupDeliveryMetrics {
@@ -656,6 +718,13 @@ func (me *Metrics) RecordSyncerSet(key string, status SyncerSetUidStatus) {
 	}
 }
 
+// RecordSyncerUIDEvicted implements a part of the MetricsEngine interface. Records a UID evicted from the uids cookie
+func (me *Metrics) RecordSyncerUIDEvicted(key string) {
+	if meter, exists := me.SyncerEvictionsMeter[key]; exists {
+		meter.Mark(1)
+	}
+}
+
 // RecordStoredReqCacheResult implements a part of the MetricsEngine interface. Records the
 // cache hits and misses when looking up stored requests
 func (me *Metrics) RecordStoredReqCacheResult(cacheResult CacheResult, inc int) {
@@ -699,6 +768,26 @@ func (me *Metrics) RecordTimeoutNotice(success bool) {
 	}
 }
 
+func (me *Metrics) RecordAdsCertReq(success bool) {
+	if success {
+		me.AdsCertRequestsSuccess.Mark(1)
+	} else {
+		me.AdsCertRequestsFailure.Mark(1)
+	}
+}
+
+func (me *Metrics) RecordAdsCertSignTime(adsCertSignTime time.Duration) {
+	me.AdsCertSignTimer.Update(adsCertSignTime)
+}
+
+func (me *Metrics) RecordBidderConfigReload(success bool) {
+	if success {
+		me.BidderConfigReloadSuccess.Mark(1)
+	} else {
+		me.BidderConfigReloadFailure.Mark(1)
+	}
+}
+
 func (me *Metrics) RecordRequestPrivacy(privacy PrivacyLabels) {
 	if privacy.CCPAProvided {
 		me.PrivacyCCPARequest.Mark(1)
@@ -737,3 +826,57 @@ func (me *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.Bidde
 
 	am.GDPRRequestBlocked.Mark(1)
 }
+
+func (me *Metrics) RecordAdapterBidValidation(adapterName openrtb_ext.BidderName, validation BidValidation, rejected bool) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter bid validation metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	validationMetrics, ok := am.BidValidation[validation]
+	if !ok {
+		return
+	}
+	if rejected {
+		validationMetrics.RejectMeter.Mark(1)
+	} else {
+		validationMetrics.WarnMeter.Mark(1)
+	}
+}
+
+var circuitBreakerStateValues = map[CircuitBreakerState]int64{
+	CircuitBreakerClosed:   0,
+	CircuitBreakerOpen:     1,
+	CircuitBreakerHalfOpen: 2,
+}
+
+// RecordAdapterCircuitBreakerState implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state CircuitBreakerState) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter circuit breaker state for %s: adapter not found", string(adapterName))
+		return
+	}
+	am.CircuitBreakerState.Update(circuitBreakerStateValues[state])
+}
+
+// RecordAdapterCircuitBreakerRejection implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterCircuitBreakerRejection(adapterName openrtb_ext.BidderName) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter circuit breaker rejection for %s: adapter not found", string(adapterName))
+		return
+	}
+	am.CircuitBreakerRejected.Mark(1)
+}
+
+// RecordAdapterCompressedRequestSize implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterCompressedRequestSize(adapterName openrtb_ext.BidderName, size int) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter compressed request size for %s: adapter not found", string(adapterName))
+		return
+	}
+	am.CompressedRequestSize.Update(int64(size))
+}
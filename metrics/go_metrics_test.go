@@ -74,8 +74,10 @@ func TestNewMetrics(t *testing.T) {
 	ensureContains(t, registry, "syncer.foo.request.privacy_blocked", m.SyncerRequestsMeter["foo"][SyncerCookieSyncPrivacyBlocked])
 	ensureContains(t, registry, "syncer.foo.request.already_synced", m.SyncerRequestsMeter["foo"][SyncerCookieSyncAlreadySynced])
 	ensureContains(t, registry, "syncer.foo.request.type_not_supported", m.SyncerRequestsMeter["foo"][SyncerCookieSyncTypeNotSupported])
+	ensureContains(t, registry, "syncer.foo.request.cookie_full", m.SyncerRequestsMeter["foo"][SyncerCookieSyncCookieFull])
 	ensureContains(t, registry, "syncer.foo.set.ok", m.SyncerSetsMeter["foo"][SyncerSetUidOK])
 	ensureContains(t, registry, "syncer.foo.set.cleared", m.SyncerSetsMeter["foo"][SyncerSetUidCleared])
+	ensureContains(t, registry, "syncer.foo.evicted", m.SyncerEvictionsMeter["foo"])
 }
 
 func TestRecordBidType(t *testing.T) {
@@ -239,6 +241,39 @@ func TestRecordTLSHandshakeTime(t *testing.T) {
 	}
 }
 
+func TestRecordAdsCertReq(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{AccountAdapterDetails: true}, nil)
+
+	m.RecordAdsCertReq(true)
+	m.RecordAdsCertReq(true)
+	m.RecordAdsCertReq(false)
+
+	assert.Equal(t, int64(2), m.AdsCertRequestsSuccess.Count())
+	assert.Equal(t, int64(1), m.AdsCertRequestsFailure.Count())
+}
+
+func TestRecordAdsCertSignTime(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{AccountAdapterDetails: true}, nil)
+
+	m.RecordAdsCertSignTime(time.Second * 2)
+
+	assert.Equal(t, (time.Second * 2).Nanoseconds(), m.AdsCertSignTimer.Sum())
+}
+
+func TestRecordBidderConfigReload(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{AccountAdapterDetails: true}, nil)
+
+	m.RecordBidderConfigReload(true)
+	m.RecordBidderConfigReload(false)
+	m.RecordBidderConfigReload(false)
+
+	assert.Equal(t, int64(1), m.BidderConfigReloadSuccess.Count())
+	assert.Equal(t, int64(2), m.BidderConfigReloadFailure.Count())
+}
+
 func TestRecordAdapterConnections(t *testing.T) {
 	var fakeBidder openrtb_ext.BidderName = "fooAdvertising"
 
@@ -603,6 +638,52 @@ func TestRecordAdapterGDPRRequestBlocked(t *testing.T) {
 	}
 }
 
+func TestRecordAdapterBidValidation(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+	m.RecordAdapterBidValidation(openrtb_ext.BidderAppnexus, BidValidationCreativeSize, true)
+	m.RecordAdapterBidValidation(openrtb_ext.BidderAppnexus, BidValidationSecureMarkup, false)
+	m.RecordAdapterBidValidation("unknownBidder", BidValidationSecureMarkup, false)
+
+	validationMetrics := m.AdapterMetrics[openrtb_ext.BidderAppnexus].BidValidation
+	assert.Equal(t, int64(1), validationMetrics[BidValidationCreativeSize].RejectMeter.Count(), "creative size rejected")
+	assert.Equal(t, int64(0), validationMetrics[BidValidationCreativeSize].WarnMeter.Count(), "creative size warned")
+	assert.Equal(t, int64(0), validationMetrics[BidValidationSecureMarkup].RejectMeter.Count(), "secure markup rejected")
+	assert.Equal(t, int64(1), validationMetrics[BidValidationSecureMarkup].WarnMeter.Count(), "secure markup warned")
+}
+
+func TestRecordAdapterCircuitBreaker(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+	m.RecordAdapterCircuitBreakerState(openrtb_ext.BidderAppnexus, CircuitBreakerOpen)
+	m.RecordAdapterCircuitBreakerRejection(openrtb_ext.BidderAppnexus)
+	m.RecordAdapterCircuitBreakerRejection(openrtb_ext.BidderAppnexus)
+	m.RecordAdapterCircuitBreakerState("unknownBidder", CircuitBreakerOpen)
+	m.RecordAdapterCircuitBreakerRejection("unknownBidder")
+
+	am := m.AdapterMetrics[openrtb_ext.BidderAppnexus]
+	assert.Equal(t, int64(1), am.CircuitBreakerState.Value(), "state")
+	assert.Equal(t, int64(2), am.CircuitBreakerRejected.Count(), "rejected")
+
+	m.RecordAdapterCircuitBreakerState(openrtb_ext.BidderAppnexus, CircuitBreakerHalfOpen)
+	assert.Equal(t, int64(2), am.CircuitBreakerState.Value(), "half open state")
+}
+
+func TestRecordAdapterCompressedRequestSize(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+	m.RecordAdapterCompressedRequestSize(openrtb_ext.BidderAppnexus, 700)
+	m.RecordAdapterCompressedRequestSize(openrtb_ext.BidderAppnexus, 1300)
+	m.RecordAdapterCompressedRequestSize("unknownBidder", 1000)
+
+	am := m.AdapterMetrics[openrtb_ext.BidderAppnexus]
+	assert.Equal(t, int64(2), am.CompressedRequestSize.Count(), "count")
+	assert.Equal(t, int64(2000), am.CompressedRequestSize.Sum(), "sum")
+}
+
 func TestRecordCookieSync(t *testing.T) {
 	registry := metrics.NewRegistry()
 	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderRubicon}, config.DisabledMetrics{}, nil)
@@ -676,6 +757,21 @@ func TestRecordSyncerSet(t *testing.T) {
 	assert.Equal(t, m.SyncerSetsMeter["foo"][SyncerSetUidCleared].Count(), int64(1))
 }
 
+func TestRecordSyncerUIDEvicted(t *testing.T) {
+	registry := metrics.NewRegistry()
+	syncerKeys := []string{"foo"}
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderRubicon}, config.DisabledMetrics{}, syncerKeys)
+
+	// Known
+	m.RecordSyncerUIDEvicted("foo")
+
+	// Unknown Syncer
+	m.RecordSyncerUIDEvicted("bar")
+
+	assert.Equal(t, m.SyncerEvictionsMeter["foo"].Count(), int64(1))
+	assert.NotContains(t, m.SyncerEvictionsMeter, "bar")
+}
+
 func ensureContainsBidTypeMetrics(t *testing.T, registry metrics.Registry, prefix string, mdm map[openrtb_ext.BidType]*MarkupDeliveryMetrics) {
 	ensureContains(t, registry, prefix+".banner.adm_bids_received", mdm[openrtb_ext.BidTypeBanner].AdmMeter)
 	ensureContains(t, registry, prefix+".banner.nurl_bids_received", mdm[openrtb_ext.BidTypeBanner].NurlMeter)
@@ -154,6 +154,8 @@ const (
 	ReqTypeORTB2App RequestType = "openrtb2-app"
 	ReqTypeAMP      RequestType = "amp"
 	ReqTypeVideo    RequestType = "video"
+	// ReqTypeCookieSync is only used to record the cookie sync requests rejected by the rate limits
+	ReqTypeCookieSync RequestType = "cookiesync"
 )
 
 // The media types described in the "imp" json objects
@@ -171,6 +173,7 @@ func RequestTypes() []RequestType {
 		ReqTypeORTB2App,
 		ReqTypeAMP,
 		ReqTypeVideo,
+		ReqTypeCookieSync,
 	}
 }
 
@@ -206,6 +209,7 @@ const (
 	RequestStatusNetworkErr   RequestStatus = "networkerr"
 	RequestStatusBlacklisted  RequestStatus = "blacklistedacctorapp"
 	RequestStatusQueueTimeout RequestStatus = "queuetimeout"
+	RequestStatusRateLimited  RequestStatus = "ratelimited"
 )
 
 func RequestStatuses() []RequestStatus {
@@ -216,6 +220,7 @@ func RequestStatuses() []RequestStatus {
 		RequestStatusNetworkErr,
 		RequestStatusBlacklisted,
 		RequestStatusQueueTimeout,
+		RequestStatusRateLimited,
 	}
 }
 
@@ -251,6 +256,41 @@ func AdapterErrors() []AdapterError {
 	}
 }
 
+// BidValidation identifies a validation of the bids returned by the adapters
+type BidValidation string
+
+const (
+	BidValidationCreativeSize BidValidation = "creative_size"
+	BidValidationSecureMarkup BidValidation = "secure_markup"
+)
+
+func BidValidations() []BidValidation {
+	return []BidValidation{
+		BidValidationCreativeSize,
+		BidValidationSecureMarkup,
+	}
+}
+
+// CircuitBreakerState is the state of the circuit breaker of an adapter
+type CircuitBreakerState string
+
+const (
+	// CircuitBreakerClosed lets the requests through
+	CircuitBreakerClosed CircuitBreakerState = "closed"
+	// CircuitBreakerOpen skips the adapter
+	CircuitBreakerOpen CircuitBreakerState = "open"
+	// CircuitBreakerHalfOpen lets a few probe requests through, to find out whether the adapter recovered
+	CircuitBreakerHalfOpen CircuitBreakerState = "half_open"
+)
+
+func CircuitBreakerStates() []CircuitBreakerState {
+	return []CircuitBreakerState{
+		CircuitBreakerClosed,
+		CircuitBreakerOpen,
+		CircuitBreakerHalfOpen,
+	}
+}
+
 const (
 	// CacheHit represents a cache hit i.e the key was found in cache
 	CacheHit CacheResult = "hit"
@@ -320,6 +360,7 @@ const (
 	SyncerCookieSyncPrivacyBlocked   SyncerCookieSyncStatus = "privacy_blocked"
 	SyncerCookieSyncAlreadySynced    SyncerCookieSyncStatus = "already_synced"
 	SyncerCookieSyncTypeNotSupported SyncerCookieSyncStatus = "type_not_supported"
+	SyncerCookieSyncCookieFull       SyncerCookieSyncStatus = "cookie_full"
 )
 
 // SyncerRequestStatuses returns possible syncer statuses.
@@ -329,6 +370,7 @@ func SyncerRequestStatuses() []SyncerCookieSyncStatus {
 		SyncerCookieSyncPrivacyBlocked,
 		SyncerCookieSyncAlreadySynced,
 		SyncerCookieSyncTypeNotSupported,
+		SyncerCookieSyncCookieFull,
 	}
 }
 
@@ -398,6 +440,8 @@ type MetricsEngine interface {
 	RecordSyncerRequest(key string, status SyncerCookieSyncStatus)
 	RecordSetUid(status SetUidStatus)
 	RecordSyncerSet(key string, status SyncerSetUidStatus)
+	// RecordSyncerUIDEvicted records a UID evicted from the uids cookie because it grew past its size budget.
+	RecordSyncerUIDEvicted(key string)
 	RecordStoredReqCacheResult(cacheResult CacheResult, inc int)
 	RecordStoredImpCacheResult(cacheResult CacheResult, inc int)
 	RecordAccountCacheResult(cacheResult CacheResult, inc int)
@@ -408,4 +452,16 @@ type MetricsEngine interface {
 	RecordTimeoutNotice(sucess bool)
 	RecordRequestPrivacy(privacy PrivacyLabels)
 	RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName)
+	// RecordAdapterBidValidation records a bid failing a validation, which is either rejected or kept with a warning
+	RecordAdapterBidValidation(adapterName openrtb_ext.BidderName, validation BidValidation, rejected bool)
+	RecordAdsCertReq(success bool)
+	RecordAdsCertSignTime(adsCertSignTime time.Duration)
+	// RecordBidderConfigReload records an attempt to reload the bidder configuration without restarting
+	RecordBidderConfigReload(success bool)
+	// RecordAdapterCircuitBreakerState records the new state of the circuit breaker of an adapter
+	RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state CircuitBreakerState)
+	// RecordAdapterCircuitBreakerRejection records a request not sent to an adapter because its circuit breaker is open
+	RecordAdapterCircuitBreakerRejection(adapterName openrtb_ext.BidderName)
+	// RecordAdapterCompressedRequestSize records the size of a compressed request body sent to an adapter, in bytes
+	RecordAdapterCompressedRequestSize(adapterName openrtb_ext.BidderName, size int)
 }
@@ -111,6 +111,11 @@ func (me *MetricsEngineMock) RecordSyncerSet(key string, status SyncerSetUidStat
 	me.Called(key, status)
 }
 
+// RecordSyncerUIDEvicted mock
+func (me *MetricsEngineMock) RecordSyncerUIDEvicted(key string) {
+	me.Called(key)
+}
+
 // RecordStoredReqCacheResult mock
 func (me *MetricsEngineMock) RecordStoredReqCacheResult(cacheResult CacheResult, inc int) {
 	me.Called(cacheResult, inc)
@@ -150,3 +155,38 @@ func (me *MetricsEngineMock) RecordRequestPrivacy(privacy PrivacyLabels) {
 func (me *MetricsEngineMock) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName) {
 	me.Called(adapterName)
 }
+
+// RecordAdapterBidValidation mock
+func (me *MetricsEngineMock) RecordAdapterBidValidation(adapterName openrtb_ext.BidderName, validation BidValidation, rejected bool) {
+	me.Called(adapterName, validation, rejected)
+}
+
+// RecordAdsCertReq mock
+func (me *MetricsEngineMock) RecordAdsCertReq(success bool) {
+	me.Called(success)
+}
+
+// RecordAdsCertSignTime mock
+func (me *MetricsEngineMock) RecordAdsCertSignTime(adsCertSignTime time.Duration) {
+	me.Called(adsCertSignTime)
+}
+
+// RecordBidderConfigReload mock
+func (me *MetricsEngineMock) RecordBidderConfigReload(success bool) {
+	me.Called(success)
+}
+
+// RecordAdapterCircuitBreakerState mock
+func (me *MetricsEngineMock) RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state CircuitBreakerState) {
+	me.Called(adapterName, state)
+}
+
+// RecordAdapterCircuitBreakerRejection mock
+func (me *MetricsEngineMock) RecordAdapterCircuitBreakerRejection(adapterName openrtb_ext.BidderName) {
+	me.Called(adapterName)
+}
+
+// RecordAdapterCompressedRequestSize mock
+func (me *MetricsEngineMock) RecordAdapterCompressedRequestSize(adapterName openrtb_ext.BidderName, size int) {
+	me.Called(adapterName, size)
+}
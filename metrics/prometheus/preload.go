@@ -10,6 +10,7 @@ func preloadLabelValues(m *Metrics, syncerKeys []string) {
 		setUidStatusValues        = setUidStatusesAsString()
 		adapterErrorValues        = adapterErrorsAsString()
 		adapterValues             = adaptersAsString()
+		bidValidationValues       = bidValidationsAsString()
 		bidTypeValues             = []string{markupDeliveryAdm, markupDeliveryNurl}
 		boolValues                = boolValuesAsString()
 		cacheResultValues         = cacheResultsAsString()
@@ -165,6 +166,10 @@ func preloadLabelValues(m *Metrics, syncerKeys []string) {
 		statusLabel: syncerSetsStatusValues,
 	})
 
+	preloadLabelValuesForCounter(m.syncerEvicted, map[string][]string{
+		syncerLabel: syncerKeys,
+	})
+
 	//to minimize memory usage, queuedTimeout metric is now supported for video endpoint only
 	//boolean value represents 2 general request statuses: accepted and rejected
 	preloadLabelValuesForHistogram(m.requestsQueueTimer, map[string][]string{
@@ -190,6 +195,12 @@ func preloadLabelValues(m *Metrics, syncerKeys []string) {
 		versionLabel: tcfVersionsAsString(),
 	})
 
+	preloadLabelValuesForCounter(m.adapterBidValidation, map[string][]string{
+		adapterLabel:    adapterValues,
+		validationLabel: bidValidationValues,
+		rejectedLabel:   boolValues,
+	})
+
 	if !m.metricsDisabled.AdapterGDPRRequestBlocked {
 		preloadLabelValuesForCounter(m.adapterGDPRBlockedRequests, map[string][]string{
 			adapterLabel: adapterValues,
@@ -43,6 +43,9 @@ type Metrics struct {
 	timeoutNotifications         *prometheus.CounterVec
 	dnsLookupTimer               prometheus.Histogram
 	tlsHandhakeTimer             prometheus.Histogram
+	adsCertRequests              *prometheus.CounterVec
+	adsCertSignTimer             prometheus.Histogram
+	bidderConfigReloads          *prometheus.CounterVec
 	privacyCCPA                  *prometheus.CounterVec
 	privacyCOPPA                 *prometheus.CounterVec
 	privacyLMT                   *prometheus.CounterVec
@@ -59,10 +62,15 @@ type Metrics struct {
 	adapterCreatedConnections  *prometheus.CounterVec
 	adapterConnectionWaitTime  *prometheus.HistogramVec
 	adapterGDPRBlockedRequests *prometheus.CounterVec
+	adapterBidValidation       *prometheus.CounterVec
+	adapterCircuitBreakerState *prometheus.GaugeVec
+	adapterCircuitRejections   *prometheus.CounterVec
+	adapterCompressedReqSize   *prometheus.HistogramVec
 
 	// Syncer Metrics
 	syncerRequests *prometheus.CounterVec
 	syncerSets     *prometheus.CounterVec
+	syncerEvicted  *prometheus.CounterVec
 
 	// Account Metrics
 	accountRequests *prometheus.CounterVec
@@ -77,6 +85,7 @@ const (
 	adapterLabel         = "adapter"
 	bidTypeLabel         = "bid_type"
 	cacheResultLabel     = "cache_result"
+	circuitStateLabel    = "circuit_state"
 	connectionErrorLabel = "connection_error"
 	cookieLabel          = "cookie"
 	hasBidsLabel         = "has_bids"
@@ -86,12 +95,14 @@ const (
 	isVideoLabel         = "video"
 	markupDeliveryLabel  = "delivery"
 	optOutLabel          = "opt_out"
+	rejectedLabel        = "rejected"
 	privacyBlockedLabel  = "privacy_blocked"
 	requestStatusLabel   = "request_status"
 	requestTypeLabel     = "request_type"
 	statusLabel          = "status"
 	successLabel         = "success"
 	syncerLabel          = "syncer"
+	validationLabel      = "validation"
 	versionLabel         = "version"
 )
 
@@ -131,6 +142,7 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 	cacheWriteTimeBuckets := []float64{0.001, 0.002, 0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 1}
 	priceBuckets := []float64{250, 500, 750, 1000, 1500, 2000, 2500, 3000, 3500, 4000}
 	queuedRequestTimeBuckets := []float64{0, 1, 5, 30, 60, 120, 180, 240, 300}
+	requestSizeBuckets := []float64{500, 1000, 2000, 4000, 8000, 16000, 32000, 64000}
 
 	metrics := Metrics{}
 	metrics.Registry = prometheus.NewRegistry()
@@ -275,6 +287,21 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Seconds to perform TLS Handshake",
 		standardTimeBuckets)
 
+	metrics.adsCertRequests = newCounter(cfg, metrics.Registry,
+		"ads_cert_requests",
+		"Count of AdsCert request, and if they were successfully sent.",
+		[]string{successLabel})
+
+	metrics.adsCertSignTimer = newHistogram(cfg, metrics.Registry,
+		"ads_cert_sign_time",
+		"Seconds to generate an AdsCert header",
+		standardTimeBuckets)
+
+	metrics.bidderConfigReloads = newCounter(cfg, metrics.Registry,
+		"bidder_config_reloads",
+		"Count of bidder configuration reloads, and if they were successfully applied.",
+		[]string{successLabel})
+
 	metrics.privacyCCPA = newCounter(cfg, metrics.Registry,
 		"privacy_ccpa",
 		"Count of total requests to Prebid Server where CCPA was provided by source and opt-out .",
@@ -302,6 +329,27 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 			[]string{adapterLabel})
 	}
 
+	metrics.adapterBidValidation = newCounter(cfg, metrics.Registry,
+		"adapter_bid_validation",
+		"Count of bids failing a validation labeled by adapter, validation and whether the bid was rejected.",
+		[]string{adapterLabel, validationLabel, rejectedLabel})
+
+	metrics.adapterCircuitBreakerState = newGaugeVec(cfg, metrics.Registry,
+		"adapter_circuit_breaker_state",
+		"Current state of the adapter circuit breakers, set to 1 for the current state of each adapter and 0 for the others.",
+		[]string{adapterLabel, circuitStateLabel})
+
+	metrics.adapterCircuitRejections = newCounter(cfg, metrics.Registry,
+		"adapter_circuit_breaker_rejections",
+		"Count of requests not sent to an adapter because its circuit breaker was open.",
+		[]string{adapterLabel})
+
+	metrics.adapterCompressedReqSize = newHistogramVec(cfg, metrics.Registry,
+		"adapter_compressed_request_size_bytes",
+		"Size of the compressed request bodies sent to an adapter, in bytes.",
+		[]string{adapterLabel},
+		requestSizeBuckets)
+
 	metrics.adapterBids = newCounter(cfg, metrics.Registry,
 		"adapter_bids",
 		"Count of bids labeled by adapter and markup delivery type (adm or nurl).",
@@ -362,6 +410,11 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of setuid set requests for a syncer labeled by syncer key and status.",
 		[]string{syncerLabel, statusLabel})
 
+	metrics.syncerEvicted = newCounter(cfg, metrics.Registry,
+		"syncer_uids_evicted",
+		"Count of UIDs evicted from the uids cookie because it grew past its size budget labeled by syncer key.",
+		[]string{syncerLabel})
+
 	metrics.accountRequests = newCounter(cfg, metrics.Registry,
 		"account_requests",
 		"Count of total requests to Prebid Server labeled by account.",
@@ -402,6 +455,18 @@ func newCounterWithoutLabels(cfg config.PrometheusMetrics, registry *prometheus.
 	return counter
 }
 
+func newGaugeVec(cfg config.PrometheusMetrics, registry *prometheus.Registry, name, help string, labels []string) *prometheus.GaugeVec {
+	opts := prometheus.GaugeOpts{
+		Namespace: cfg.Namespace,
+		Subsystem: cfg.Subsystem,
+		Name:      name,
+		Help:      help,
+	}
+	gauge := prometheus.NewGaugeVec(opts, labels)
+	registry.MustRegister(gauge)
+	return gauge
+}
+
 func newHistogramVec(cfg config.PrometheusMetrics, registry *prometheus.Registry, name, help string, labels []string, buckets []float64) *prometheus.HistogramVec {
 	opts := prometheus.HistogramOpts{
 		Namespace: cfg.Namespace,
@@ -641,6 +706,12 @@ func (m *Metrics) RecordSyncerSet(key string, status metrics.SyncerSetUidStatus)
 	}).Inc()
 }
 
+func (m *Metrics) RecordSyncerUIDEvicted(key string) {
+	m.syncerEvicted.With(prometheus.Labels{
+		syncerLabel: key,
+	}).Inc()
+}
+
 func (m *Metrics) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
 	m.storedRequestCacheResult.With(prometheus.Labels{
 		cacheResultLabel: string(cacheResult),
@@ -688,6 +759,59 @@ func (m *Metrics) RecordTimeoutNotice(success bool) {
 	}
 }
 
+func (m *Metrics) RecordAdsCertReq(success bool) {
+	if success {
+		m.adsCertRequests.With(prometheus.Labels{
+			successLabel: requestSuccessful,
+		}).Inc()
+	} else {
+		m.adsCertRequests.With(prometheus.Labels{
+			successLabel: requestFailed,
+		}).Inc()
+	}
+}
+
+func (m *Metrics) RecordAdsCertSignTime(adsCertSignTime time.Duration) {
+	m.adsCertSignTimer.Observe(adsCertSignTime.Seconds())
+}
+
+func (m *Metrics) RecordBidderConfigReload(success bool) {
+	if success {
+		m.bidderConfigReloads.With(prometheus.Labels{
+			successLabel: requestSuccessful,
+		}).Inc()
+	} else {
+		m.bidderConfigReloads.With(prometheus.Labels{
+			successLabel: requestFailed,
+		}).Inc()
+	}
+}
+
+func (m *Metrics) RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state metrics.CircuitBreakerState) {
+	for _, s := range metrics.CircuitBreakerStates() {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		m.adapterCircuitBreakerState.With(prometheus.Labels{
+			adapterLabel:      string(adapterName),
+			circuitStateLabel: string(s),
+		}).Set(value)
+	}
+}
+
+func (m *Metrics) RecordAdapterCircuitBreakerRejection(adapterName openrtb_ext.BidderName) {
+	m.adapterCircuitRejections.With(prometheus.Labels{
+		adapterLabel: string(adapterName),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterCompressedRequestSize(adapterName openrtb_ext.BidderName, size int) {
+	m.adapterCompressedReqSize.With(prometheus.Labels{
+		adapterLabel: string(adapterName),
+	}).Observe(float64(size))
+}
+
 func (m *Metrics) RecordRequestPrivacy(privacy metrics.PrivacyLabels) {
 	if privacy.CCPAProvided {
 		m.privacyCCPA.With(prometheus.Labels{
@@ -725,3 +849,11 @@ func (m *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.Bidder
 		adapterLabel: string(adapterName),
 	}).Inc()
 }
+
+func (m *Metrics) RecordAdapterBidValidation(adapterName openrtb_ext.BidderName, validation metrics.BidValidation, rejected bool) {
+	m.adapterBidValidation.With(prometheus.Labels{
+		adapterLabel:    string(adapterName),
+		validationLabel: string(validation),
+		rejectedLabel:   strconv.FormatBool(rejected),
+	}).Inc()
+}
@@ -1058,6 +1058,10 @@ func TestRecordSyncerRequestMetric(t *testing.T) {
 			status: metrics.SyncerCookieSyncTypeNotSupported,
 			label:  "type_not_supported",
 		},
+		{
+			status: metrics.SyncerCookieSyncCookieFull,
+			label:  "cookie_full",
+		},
 	}
 
 	for _, test := range tests {
@@ -1145,6 +1149,18 @@ func TestRecordSyncerSetMetric(t *testing.T) {
 	}
 }
 
+func TestRecordSyncerUIDEvictedMetric(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordSyncerUIDEvicted("anyKey")
+
+	assertCounterVecValue(t, "", "syncer_uids_evicted", m.syncerEvicted,
+		float64(1),
+		prometheus.Labels{
+			syncerLabel: "anyKey",
+		})
+}
+
 func TestPrebidCacheRequestTimeMetric(t *testing.T) {
 	m := createMetricsForTesting()
 
@@ -1237,6 +1253,96 @@ func TestTimeoutNotifications(t *testing.T) {
 
 }
 
+func TestRecordAdsCertReq(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdsCertReq(true)
+	m.RecordAdsCertReq(true)
+	m.RecordAdsCertReq(false)
+
+	assertCounterVecValue(t, "", "ads_cert_requests:ok", m.adsCertRequests,
+		float64(2),
+		prometheus.Labels{
+			successLabel: requestSuccessful,
+		})
+
+	assertCounterVecValue(t, "", "ads_cert_requests:fail", m.adsCertRequests,
+		float64(1),
+		prometheus.Labels{
+			successLabel: requestFailed,
+		})
+}
+
+func TestRecordAdsCertSignTime(t *testing.T) {
+	pm := createMetricsForTesting()
+	pm.RecordAdsCertSignTime(time.Second * 2)
+
+	m := dto.Metric{}
+	pm.adsCertSignTimer.Write(&m)
+	histogram := *m.GetHistogram()
+
+	assert.Equal(t, uint64(1), histogram.GetSampleCount(), "Incorrect number of histogram entries")
+	assert.Equal(t, float64(2), histogram.GetSampleSum(), "Incorrect number of histogram cumulative values")
+}
+
+func TestRecordBidderConfigReload(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordBidderConfigReload(true)
+	m.RecordBidderConfigReload(false)
+	m.RecordBidderConfigReload(false)
+
+	assertCounterVecValue(t, "", "bidder_config_reloads:ok", m.bidderConfigReloads,
+		float64(1),
+		prometheus.Labels{
+			successLabel: requestSuccessful,
+		})
+
+	assertCounterVecValue(t, "", "bidder_config_reloads:fail", m.bidderConfigReloads,
+		float64(2),
+		prometheus.Labels{
+			successLabel: requestFailed,
+		})
+}
+
+func TestRecordAdapterCircuitBreakerState(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterCircuitBreakerState(openrtb_ext.BidderAppnexus, metrics.CircuitBreakerOpen)
+	m.RecordAdapterCircuitBreakerState(openrtb_ext.BidderAppnexus, metrics.CircuitBreakerHalfOpen)
+
+	assertGaugeVecValue(t, "", "adapter_circuit_breaker_state:closed", m.adapterCircuitBreakerState,
+		float64(0),
+		prometheus.Labels{adapterLabel: string(openrtb_ext.BidderAppnexus), circuitStateLabel: string(metrics.CircuitBreakerClosed)})
+	assertGaugeVecValue(t, "", "adapter_circuit_breaker_state:open", m.adapterCircuitBreakerState,
+		float64(0),
+		prometheus.Labels{adapterLabel: string(openrtb_ext.BidderAppnexus), circuitStateLabel: string(metrics.CircuitBreakerOpen)})
+	assertGaugeVecValue(t, "", "adapter_circuit_breaker_state:half_open", m.adapterCircuitBreakerState,
+		float64(1),
+		prometheus.Labels{adapterLabel: string(openrtb_ext.BidderAppnexus), circuitStateLabel: string(metrics.CircuitBreakerHalfOpen)})
+}
+
+func TestRecordAdapterCircuitBreakerRejection(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterCircuitBreakerRejection(openrtb_ext.BidderAppnexus)
+	m.RecordAdapterCircuitBreakerRejection(openrtb_ext.BidderAppnexus)
+
+	assertCounterVecValue(t, "", "adapter_circuit_breaker_rejections", m.adapterCircuitRejections,
+		float64(2),
+		prometheus.Labels{adapterLabel: string(openrtb_ext.BidderAppnexus)})
+}
+
+func TestRecordAdapterCompressedRequestSize(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterCompressedRequestSize(openrtb_ext.BidderAppnexus, 700)
+	m.RecordAdapterCompressedRequestSize(openrtb_ext.BidderAppnexus, 1300)
+
+	result := getHistogramFromHistogramVec(m.adapterCompressedReqSize, adapterLabel, string(openrtb_ext.BidderAppnexus))
+	assertHistogram(t, "adapter_compressed_request_size_bytes", result, 2, 2000)
+}
+
 func TestRecordDNSTime(t *testing.T) {
 	type testIn struct {
 		dnsLookupDuration time.Duration
@@ -1535,6 +1641,14 @@ func assertCounterVecValue(t *testing.T, description, name string, counterVec *p
 	assertCounterValue(t, description, name, counter, expected)
 }
 
+func assertGaugeVecValue(t *testing.T, description, name string, gaugeVec *prometheus.GaugeVec, expected float64, labels prometheus.Labels) {
+	m := dto.Metric{}
+	gaugeVec.With(labels).Write(&m)
+	actual := *m.GetGauge().Value
+
+	assert.Equal(t, expected, actual, description)
+}
+
 func getHistogramFromHistogramVec(histogram *prometheus.HistogramVec, labelKey, labelValue string) dto.Histogram {
 	var result dto.Histogram
 	processMetrics(histogram, func(m dto.Metric) {
@@ -1600,3 +1714,20 @@ func TestRecordAdapterGDPRRequestBlocked(t *testing.T) {
 			adapterLabel: string(openrtb_ext.BidderAppnexus),
 		})
 }
+
+func TestRecordAdapterBidValidation(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterBidValidation(openrtb_ext.BidderAppnexus, metrics.BidValidationSecureMarkup, true)
+
+	assertCounterVecValue(t,
+		"Increment adapter bid validation counter",
+		"adapter_bid_validation",
+		m.adapterBidValidation,
+		1,
+		prometheus.Labels{
+			adapterLabel:    string(openrtb_ext.BidderAppnexus),
+			validationLabel: string(metrics.BidValidationSecureMarkup),
+			rejectedLabel:   "true",
+		})
+}
@@ -25,6 +25,15 @@ func adapterErrorsAsString() []string {
 	return valuesAsString
 }
 
+func bidValidationsAsString() []string {
+	values := metrics.BidValidations()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
 func boolValuesAsString() []string {
 	return []string{
 		strconv.FormatBool(true),
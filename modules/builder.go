@@ -0,0 +1,86 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// ModuleDeps holds the host dependencies a module may need to be built.
+type ModuleDeps struct {
+	HTTPClient *http.Client
+}
+
+// ModuleBuilderFn builds a module from its host-level JSON configuration. The returned module must
+// implement at least one of the hook interfaces of the hookstage package.
+type ModuleBuilderFn func(cfg json.RawMessage, deps ModuleDeps) (interface{}, error)
+
+// builders returns the modules shipped with the server, keyed by vendor and then by module name.
+// New modules are registered here, e.g.
+//
+//   "acme": {
+//       "brand_safety": brandsafety.Builder,
+//   },
+func builders() map[string]map[string]ModuleBuilderFn {
+	return map[string]map[string]ModuleBuilderFn{}
+}
+
+// Builder builds the modules enabled in the host configuration.
+type Builder interface {
+	Build(cfg config.Hooks, deps ModuleDeps) (map[string]interface{}, error)
+}
+
+// NewBuilder returns a Builder of the modules shipped with the server.
+func NewBuilder() Builder {
+	return &builder{builders: builders()}
+}
+
+type builder struct {
+	builders map[string]map[string]ModuleBuilderFn
+}
+
+type moduleConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// Build returns the enabled modules keyed by their code, which is "<vendor>.<module>".
+func (b *builder) Build(cfg config.Hooks, deps ModuleDeps) (map[string]interface{}, error) {
+	modules := make(map[string]interface{})
+	if !cfg.Enabled {
+		return modules, nil
+	}
+
+	for vendor, vendorModules := range cfg.Modules {
+		for name, rawConfig := range vendorModules {
+			code := fmt.Sprintf("%s.%s", vendor, name)
+
+			configJSON, err := json.Marshal(rawConfig)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal config of module %s: %v", code, err)
+			}
+
+			var mc moduleConfig
+			if err := json.Unmarshal(configJSON, &mc); err != nil {
+				return nil, fmt.Errorf("failed to read config of module %s: %v", code, err)
+			}
+			if !mc.Enabled {
+				continue
+			}
+
+			build, ok := b.builders[vendor][name]
+			if !ok {
+				return nil, fmt.Errorf("unknown module %s", code)
+			}
+
+			module, err := build(configJSON, deps)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build module %s: %v", code, err)
+			}
+			modules[code] = module
+		}
+	}
+
+	return modules, nil
+}
@@ -0,0 +1,81 @@
+package modules
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeModule struct {
+	cfg json.RawMessage
+}
+
+func TestBuild(t *testing.T) {
+	testBuilder := &builder{builders: map[string]map[string]ModuleBuilderFn{
+		"vendor": {
+			"module": func(cfg json.RawMessage, deps ModuleDeps) (interface{}, error) {
+				return fakeModule{cfg: cfg}, nil
+			},
+			"broken": func(cfg json.RawMessage, deps ModuleDeps) (interface{}, error) {
+				return nil, errors.New("invalid config")
+			},
+		},
+	}}
+
+	testCases := []struct {
+		description     string
+		hooks           config.Hooks
+		expectedModules map[string]interface{}
+		expectedErr     string
+	}{
+		{
+			description: "Hooks disabled",
+			hooks: config.Hooks{
+				Modules: map[string]map[string]interface{}{"vendor": {"module": map[string]interface{}{"enabled": true}}},
+			},
+			expectedModules: map[string]interface{}{},
+		},
+		{
+			description: "Enabled module built with its config, disabled ones skipped",
+			hooks: config.Hooks{
+				Enabled: true,
+				Modules: map[string]map[string]interface{}{
+					"vendor": {
+						"module":   map[string]interface{}{"enabled": true, "key": "value"},
+						"disabled": map[string]interface{}{"enabled": false},
+					},
+				},
+			},
+			expectedModules: map[string]interface{}{"vendor.module": fakeModule{cfg: json.RawMessage(`{"enabled":true,"key":"value"}`)}},
+		},
+		{
+			description: "Unknown module",
+			hooks: config.Hooks{
+				Enabled: true,
+				Modules: map[string]map[string]interface{}{"other": {"module": map[string]interface{}{"enabled": true}}},
+			},
+			expectedErr: "unknown module other.module",
+		},
+		{
+			description: "Module failing to build",
+			hooks: config.Hooks{
+				Enabled: true,
+				Modules: map[string]map[string]interface{}{"vendor": {"broken": map[string]interface{}{"enabled": true}}},
+			},
+			expectedErr: "failed to build module vendor.broken: invalid config",
+		},
+	}
+
+	for _, test := range testCases {
+		modules, err := testBuilder.Build(test.hooks, ModuleDeps{})
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedModules, modules, test.description)
+	}
+}
@@ -0,0 +1,57 @@
+package openrtb_ext
+
+// Defines the types of the bid adjustments
+const (
+	// AdjustmentTypeMultiplier multiplies the bid price by the adjustment value
+	AdjustmentTypeMultiplier = "multiplier"
+	// AdjustmentTypeCPM subtracts the adjustment value, a static CPM, from the bid price
+	AdjustmentTypeCPM = "cpm"
+	// AdjustmentTypeStatic overrides the bid price with the adjustment value, a static CPM
+	AdjustmentTypeStatic = "static"
+)
+
+// AdjustmentWildCard matches any media type, bidder or deal ID in the bid adjustments
+const AdjustmentWildCard = "*"
+
+// ExtRequestPrebidBidAdjustments defines the contract for bidrequest.ext.prebid.bidadjustments
+type ExtRequestPrebidBidAdjustments struct {
+	MediaType AdjustmentsByMediaType `json:"mediatype,omitempty"`
+}
+
+// AdjustmentsByMediaType defines the contract for bidrequest.ext.prebid.bidadjustments.mediatype
+type AdjustmentsByMediaType struct {
+	Banner   AdjustmentsByBidder `json:"banner,omitempty"`
+	Video    AdjustmentsByBidder `json:"video,omitempty"`
+	Audio    AdjustmentsByBidder `json:"audio,omitempty"`
+	Native   AdjustmentsByBidder `json:"native,omitempty"`
+	WildCard AdjustmentsByBidder `json:"*,omitempty"`
+}
+
+// AdjustmentsByBidder maps the bidders, or the wildcard, to their adjustments keyed by deal ID, or the wildcard.
+// The wildcard deal ID also applies to the bids without a deal.
+type AdjustmentsByBidder map[string]map[string][]Adjustment
+
+// Adjustment defines the contract for an adjustment of the bid price. The currency applies to the static CPM
+// values of the cpm and static adjustment types.
+type Adjustment struct {
+	Type     string  `json:"adjtype,omitempty"`
+	Value    float64 `json:"value,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+// ForMediaType returns the adjustments of the media type, or nil if it has none.
+func (m *AdjustmentsByMediaType) ForMediaType(mediaType string) AdjustmentsByBidder {
+	switch mediaType {
+	case string(BidTypeBanner):
+		return m.Banner
+	case string(BidTypeVideo):
+		return m.Video
+	case string(BidTypeAudio):
+		return m.Audio
+	case string(BidTypeNative):
+		return m.Native
+	case AdjustmentWildCard:
+		return m.WildCard
+	}
+	return nil
+}
@@ -149,8 +149,19 @@ type BidRequestVideo struct {
 	//   boolean, optional
 	//  Flag indicating if the bidder name will be added to the hb_pb_cat_dur. Default is false.
 	AppendBidderNames bool `json:"appendbiddernames,omitempty"`
+
+	// Attribute:
+	//   output
+	// Type:
+	//   string, optional
+	//  Format of the response. "vast" returns a VAST document wrapping the cached bids of the pods,
+	//  instead of their targeting keys.
+	Output string `json:"output,omitempty"`
 }
 
+// VideoOutputVAST is the output of the video requests answered with a VAST document
+const VideoOutputVAST = "vast"
+
 type PodConfig struct {
 	// Attribute:
 	//   durationrangesec
@@ -18,3 +18,12 @@ type VideoTargeting struct {
 	HbPbCatDur string `json:"hb_pb_cat_dur,omitempty"`
 	HbCacheID  string `json:"hb_cache_id,omitempty"`
 }
+
+// DroppedPodBid is a bid which won its impression but was left out of its ad pod. The dropped bids are
+// returned in the ext.prebid.droppedbids of the video response.
+type DroppedPodBid struct {
+	PodId  int64  `json:"podid"`
+	BidId  string `json:"bidid"`
+	Seat   string `json:"seat"`
+	Reason string `json:"reason"`
+}
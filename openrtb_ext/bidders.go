@@ -198,9 +198,9 @@ const (
 	BidderZeroClickFraud    BidderName = "zeroclickfraud"
 )
 
-// CoreBidderNames returns a slice of all core bidders.
+// CoreBidderNames returns a slice of all core bidders, including the aliases configured by the host.
 func CoreBidderNames() []BidderName {
-	return []BidderName{
+	return append([]BidderName{
 		Bidder33Across,
 		BidderAcuityAds,
 		BidderAdagio,
@@ -324,7 +324,43 @@ func CoreBidderNames() []BidderName {
 		BidderYieldmo,
 		BidderYieldone,
 		BidderZeroClickFraud,
+	}, aliasBidderNames...)
+}
+
+// aliasBidderNames holds the aliases configured by the host in the bidder-info files, which are handled
+// like core bidders.
+var aliasBidderNames []BidderName
+
+// aliasBidderToParent maps the aliases configured by the host to the core bidder they are an alias of.
+var aliasBidderToParent = map[BidderName]BidderName{}
+
+// SetAliasBidderName registers an alias configured by the host, so it is recognized and handled like a core
+// bidder. It is meant to be called at startup, before any of the bidder names lists or lookups are used.
+func SetAliasBidderName(aliasBidderName string, parentBidderName BidderName) error {
+	if IsBidderNameReserved(aliasBidderName) {
+		return fmt.Errorf("alias %s is a reserved bidder name and cannot be used", aliasBidderName)
+	}
+	if _, exists := bidderNameLookup[strings.ToLower(aliasBidderName)]; exists {
+		return fmt.Errorf("alias %s conflicts with an existing bidder name", aliasBidderName)
+	}
+	parentBidder, parentFound := bidderNameLookup[strings.ToLower(string(parentBidderName))]
+	if !parentFound {
+		return fmt.Errorf("alias %s references unknown bidder %s", aliasBidderName, parentBidderName)
+	}
+	if _, parentIsAlias := aliasBidderToParent[parentBidder]; parentIsAlias {
+		return fmt.Errorf("alias %s cannot reference another alias (%s)", aliasBidderName, parentBidderName)
 	}
+
+	aliasBidder := BidderName(aliasBidderName)
+	aliasBidderNames = append(aliasBidderNames, aliasBidder)
+	aliasBidderToParent[aliasBidder] = parentBidder
+	bidderNameLookup[strings.ToLower(aliasBidderName)] = aliasBidder
+	return nil
+}
+
+// GetAliasBidderToParent returns the aliases configured by the host, mapped to the core bidder they are an alias of.
+func GetAliasBidderToParent() map[BidderName]BidderName {
+	return aliasBidderToParent
 }
 
 // BuildBidderMap builds a map of string to BidderName, to remain compatbile with the
@@ -416,6 +452,14 @@ func NewBidderParamsValidator(schemaDirectory string) (BidderParamValidator, err
 		schemaContents[BidderName(bidderName)] = string(fileBytes)
 	}
 
+	// The aliases configured by the host share the params of their parent bidder
+	for aliasBidder, parentBidder := range aliasBidderToParent {
+		if parentSchema, ok := schemas[parentBidder]; ok {
+			schemas[aliasBidder] = parentSchema
+			schemaContents[aliasBidder] = schemaContents[parentBidder]
+		}
+	}
+
 	return &bidderParamValidator{
 		schemaContents: schemaContents,
 		parsedSchemas:  schemas,
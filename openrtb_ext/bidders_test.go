@@ -2,6 +2,7 @@ package openrtb_ext
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -118,3 +119,71 @@ func TestIsBidderNameReserved(t *testing.T) {
 		assert.Equal(t, test.expected, result, test.bidder)
 	}
 }
+
+func TestSetAliasBidderName(t *testing.T) {
+	defer func() {
+		for alias := range aliasBidderToParent {
+			delete(bidderNameLookup, strings.ToLower(string(alias)))
+		}
+		aliasBidderNames = nil
+		aliasBidderToParent = map[BidderName]BidderName{}
+	}()
+
+	testCases := []struct {
+		description string
+		alias       string
+		parent      BidderName
+		expectedErr string
+	}{
+		{
+			description: "Valid",
+			alias:       "appnexusAlias",
+			parent:      BidderAppnexus,
+		},
+		{
+			description: "Valid - Parent Case Insensitive",
+			alias:       "rubiconAlias",
+			parent:      "Rubicon",
+		},
+		{
+			description: "Invalid - Reserved Name",
+			alias:       "all",
+			parent:      BidderAppnexus,
+			expectedErr: "alias all is a reserved bidder name and cannot be used",
+		},
+		{
+			description: "Invalid - Core Bidder Name",
+			alias:       "AppNexus",
+			parent:      BidderRubicon,
+			expectedErr: "alias AppNexus conflicts with an existing bidder name",
+		},
+		{
+			description: "Invalid - Unknown Parent",
+			alias:       "unknownAlias",
+			parent:      "unknown",
+			expectedErr: "alias unknownAlias references unknown bidder unknown",
+		},
+		{
+			description: "Invalid - Parent Is An Alias",
+			alias:       "aliasOfAlias",
+			parent:      "appnexusAlias",
+			expectedErr: "alias aliasOfAlias cannot reference another alias (appnexusAlias)",
+		},
+	}
+
+	for _, test := range testCases {
+		err := SetAliasBidderName(test.alias, test.parent)
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+	}
+
+	assert.Equal(t, map[BidderName]BidderName{"appnexusAlias": BidderAppnexus, "rubiconAlias": BidderRubicon}, GetAliasBidderToParent())
+	assert.Subset(t, CoreBidderNames(), []BidderName{"appnexusAlias", "rubiconAlias"})
+
+	normalized, found := NormalizeBidderName("APPNEXUSALIAS")
+	assert.True(t, found)
+	assert.Equal(t, BidderName("appnexusAlias"), normalized)
+}
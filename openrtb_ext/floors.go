@@ -0,0 +1,83 @@
+package openrtb_ext
+
+// Defines the locations the resolved price floors data can be taken from
+const (
+	FloorLocationNoData  = "noData"
+	FloorLocationRequest = "request"
+	FloorLocationAccount = "account"
+	FloorLocationFetch   = "fetch"
+)
+
+// Defines the statuses of a dynamic price floors fetch
+const (
+	FetchStatusNone       = "none"
+	FetchStatusSuccess    = "success"
+	FetchStatusError      = "error"
+	FetchStatusInProgress = "inprogress"
+)
+
+// PriceFloorRules defines the contract for bidrequest.ext.prebid.floors
+type PriceFloorRules struct {
+	FloorMin    float64                `json:"floormin,omitempty"`
+	FloorMinCur string                 `json:"floormincur,omitempty"`
+	SkipRate    int                    `json:"skiprate,omitempty"`
+	Data        *PriceFloorData        `json:"data,omitempty"`
+	Enforcement *PriceFloorEnforcement `json:"enforcement,omitempty"`
+	Enabled     *bool                  `json:"enabled,omitempty"`
+
+	// Skipped, FetchStatus and PriceFloorLocation are set by Prebid Server to describe
+	// how the floors of the auction were resolved. They are ignored on input.
+	Skipped            *bool  `json:"skipped,omitempty"`
+	FetchStatus        string `json:"fetchstatus,omitempty"`
+	PriceFloorLocation string `json:"location,omitempty"`
+}
+
+// PriceFloorData defines the contract for bidrequest.ext.prebid.floors.data
+type PriceFloorData struct {
+	Currency            string                 `json:"currency,omitempty"`
+	SkipRate            int                    `json:"skiprate,omitempty"`
+	FloorsSchemaVersion int                    `json:"floorsschemaversion,omitempty"`
+	ModelTimestamp      int                    `json:"modeltimestamp,omitempty"`
+	ModelGroups         []PriceFloorModelGroup `json:"modelgroups,omitempty"`
+	FloorProvider       string                 `json:"floorprovider,omitempty"`
+}
+
+// PriceFloorModelGroup defines the contract for bidrequest.ext.prebid.floors.data.modelgroups[i]
+type PriceFloorModelGroup struct {
+	Currency     string             `json:"currency,omitempty"`
+	ModelWeight  *int               `json:"modelweight,omitempty"`
+	ModelVersion string             `json:"modelversion,omitempty"`
+	SkipRate     int                `json:"skiprate,omitempty"`
+	Schema       PriceFloorSchema   `json:"schema"`
+	Values       map[string]float64 `json:"values,omitempty"`
+	Default      float64            `json:"default,omitempty"`
+}
+
+// PriceFloorSchema defines the contract for bidrequest.ext.prebid.floors.data.modelgroups[i].schema
+type PriceFloorSchema struct {
+	Fields    []string `json:"fields"`
+	Delimiter string   `json:"delimiter,omitempty"`
+}
+
+// PriceFloorEnforcement defines the contract for bidrequest.ext.prebid.floors.enforcement
+type PriceFloorEnforcement struct {
+	EnforcePBS  *bool `json:"enforcepbs,omitempty"`
+	FloorDeals  *bool `json:"floordeals,omitempty"`
+	EnforceRate int   `json:"enforcerate,omitempty"`
+}
+
+// GetEnforcePBS returns whether Prebid Server should enforce the floors, which defaults to true
+func (e *PriceFloorEnforcement) GetEnforcePBS() bool {
+	if e == nil || e.EnforcePBS == nil {
+		return true
+	}
+	return *e.EnforcePBS
+}
+
+// GetFloorDeals returns whether the floors should be enforced on deal bids, or nil if it's not specified
+func (e *PriceFloorEnforcement) GetFloorDeals() *bool {
+	if e == nil {
+		return nil
+	}
+	return e.FloorDeals
+}
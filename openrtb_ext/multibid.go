@@ -0,0 +1,102 @@
+package openrtb_ext
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/errortypes"
+)
+
+const (
+	// DefaultBidLimit is the number of bids per imp kept for targeting when multibid isn't configured for a bidder
+	DefaultBidLimit = 1
+	// MaxBidLimit is the highest maxbids value a request may ask for
+	MaxBidLimit = 9
+)
+
+// ExtMultiBid defines the contract for bidrequest.ext.prebid.multibid
+type ExtMultiBid struct {
+	Bidder                 string   `json:"bidder,omitempty"`
+	Bidders                []string `json:"bidders,omitempty"`
+	MaxBids                *int     `json:"maxbids,omitempty"`
+	TargetBidderCodePrefix string   `json:"targetbiddercodeprefix,omitempty"`
+}
+
+// ValidateAndBuildExtMultiBid validates the multibid entries of the request and returns the usable ones.
+// Invalid entries are dropped and reported as warnings, so an invalid multibid config never fails the auction.
+func ValidateAndBuildExtMultiBid(prebid *ExtRequestPrebid) ([]*ExtMultiBid, []error) {
+	if prebid == nil || len(prebid.MultiBid) == 0 {
+		return nil, nil
+	}
+
+	var validated []*ExtMultiBid
+	var errs []error
+	seenBidders := make(map[string]struct{})
+
+	for i, multiBid := range prebid.MultiBid {
+		if multiBid == nil {
+			continue
+		}
+		if multiBid.MaxBids == nil {
+			errs = append(errs, multiBidWarning("request.ext.prebid.multibid[%d] ignored: maxbids is required", i))
+			continue
+		}
+
+		maxBids := *multiBid.MaxBids
+		if maxBids < DefaultBidLimit {
+			errs = append(errs, multiBidWarning("request.ext.prebid.multibid[%d].maxbids must be at least %d, using %d", i, DefaultBidLimit, DefaultBidLimit))
+			maxBids = DefaultBidLimit
+		} else if maxBids > MaxBidLimit {
+			errs = append(errs, multiBidWarning("request.ext.prebid.multibid[%d].maxbids must be at most %d, using %d", i, MaxBidLimit, MaxBidLimit))
+			maxBids = MaxBidLimit
+		}
+
+		var bidders []string
+		prefix := multiBid.TargetBidderCodePrefix
+		switch {
+		case multiBid.Bidder != "":
+			if len(multiBid.Bidders) > 0 {
+				errs = append(errs, multiBidWarning("request.ext.prebid.multibid[%d] defines both bidder and bidders, ignoring bidders", i))
+			}
+			bidders = []string{multiBid.Bidder}
+		case len(multiBid.Bidders) > 0:
+			if prefix != "" {
+				errs = append(errs, multiBidWarning("request.ext.prebid.multibid[%d].targetbiddercodeprefix can only be used with bidder, ignoring it", i))
+				prefix = ""
+			}
+			bidders = multiBid.Bidders
+		default:
+			errs = append(errs, multiBidWarning("request.ext.prebid.multibid[%d] ignored: bidder or bidders is required", i))
+			continue
+		}
+
+		var newBidders []string
+		for _, bidder := range bidders {
+			if _, seen := seenBidders[bidder]; seen {
+				errs = append(errs, multiBidWarning("request.ext.prebid.multibid[%d] ignored for bidder %s: multibid is already defined for it", i, bidder))
+				continue
+			}
+			seenBidders[bidder] = struct{}{}
+			newBidders = append(newBidders, bidder)
+		}
+		if len(newBidders) == 0 {
+			continue
+		}
+
+		entry := &ExtMultiBid{MaxBids: &maxBids, TargetBidderCodePrefix: prefix}
+		if multiBid.Bidder != "" {
+			entry.Bidder = multiBid.Bidder
+		} else {
+			entry.Bidders = newBidders
+		}
+		validated = append(validated, entry)
+	}
+
+	return validated, errs
+}
+
+func multiBidWarning(format string, args ...interface{}) error {
+	return &errortypes.Warning{
+		Message:     fmt.Sprintf(format, args...),
+		WarningCode: errortypes.MultiBidWarningCode,
+	}
+}
@@ -0,0 +1,103 @@
+package openrtb_ext
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAndBuildExtMultiBid(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	testCases := []struct {
+		description      string
+		multiBid         []*ExtMultiBid
+		expectedMultiBid []*ExtMultiBid
+		expectedErrs     []string
+	}{
+		{
+			description: "No multibid",
+		},
+		{
+			description: "Valid bidder and bidders entries",
+			multiBid: []*ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: intPtr(3), TargetBidderCodePrefix: "apn"},
+				{Bidders: []string{"rubicon", "openx"}, MaxBids: intPtr(2)},
+			},
+			expectedMultiBid: []*ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: intPtr(3), TargetBidderCodePrefix: "apn"},
+				{Bidders: []string{"rubicon", "openx"}, MaxBids: intPtr(2)},
+			},
+		},
+		{
+			description: "Missing maxbids and bidders",
+			multiBid: []*ExtMultiBid{
+				{Bidder: "appnexus"},
+				{MaxBids: intPtr(2)},
+			},
+			expectedErrs: []string{
+				"request.ext.prebid.multibid[0] ignored: maxbids is required",
+				"request.ext.prebid.multibid[1] ignored: bidder or bidders is required",
+			},
+		},
+		{
+			description: "Out of range maxbids",
+			multiBid: []*ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: intPtr(0)},
+				{Bidder: "rubicon", MaxBids: intPtr(20)},
+			},
+			expectedMultiBid: []*ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: intPtr(1)},
+				{Bidder: "rubicon", MaxBids: intPtr(9)},
+			},
+			expectedErrs: []string{
+				"request.ext.prebid.multibid[0].maxbids must be at least 1, using 1",
+				"request.ext.prebid.multibid[1].maxbids must be at most 9, using 9",
+			},
+		},
+		{
+			description: "Bidder and bidders both set, prefix with bidders",
+			multiBid: []*ExtMultiBid{
+				{Bidder: "appnexus", Bidders: []string{"rubicon"}, MaxBids: intPtr(2)},
+				{Bidders: []string{"openx"}, MaxBids: intPtr(2), TargetBidderCodePrefix: "ox"},
+			},
+			expectedMultiBid: []*ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: intPtr(2)},
+				{Bidders: []string{"openx"}, MaxBids: intPtr(2)},
+			},
+			expectedErrs: []string{
+				"request.ext.prebid.multibid[0] defines both bidder and bidders, ignoring bidders",
+				"request.ext.prebid.multibid[1].targetbiddercodeprefix can only be used with bidder, ignoring it",
+			},
+		},
+		{
+			description: "Bidder defined more than once",
+			multiBid: []*ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: intPtr(2)},
+				{Bidders: []string{"appnexus", "rubicon"}, MaxBids: intPtr(3)},
+				{Bidder: "rubicon", MaxBids: intPtr(4)},
+			},
+			expectedMultiBid: []*ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: intPtr(2)},
+				{Bidders: []string{"rubicon"}, MaxBids: intPtr(3)},
+			},
+			expectedErrs: []string{
+				"request.ext.prebid.multibid[1] ignored for bidder appnexus: multibid is already defined for it",
+				"request.ext.prebid.multibid[2] ignored for bidder rubicon: multibid is already defined for it",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		multiBid, errs := ValidateAndBuildExtMultiBid(&ExtRequestPrebid{MultiBid: test.multiBid})
+		assert.Equal(t, test.expectedMultiBid, multiBid, test.description)
+
+		var errMessages []string
+		for _, err := range errs {
+			assert.Equal(t, errortypes.MultiBidWarningCode, errortypes.ReadCode(err), test.description)
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.Equal(t, test.expectedErrs, errMessages, test.description)
+	}
+}
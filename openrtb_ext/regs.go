@@ -9,4 +9,9 @@ type ExtRegs struct {
 
 	// USPrivacy should be a four character string, see: https://iabtechlab.com/wp-content/uploads/2019/11/OpenRTB-Extension-U.S.-Privacy-IAB-Tech-Lab.pdf
 	USPrivacy string `json:"us_privacy,omitempty"`
+
+	// GPP is a Global Privacy Platform string and GPPSID lists the ids of its sections which apply to the request.
+	// OpenRTB 2.6 defines them as request.regs.gpp and request.regs.gpp_sid, which are moved here on input.
+	GPP    string `json:"gpp,omitempty"`
+	GPPSID []int8 `json:"gpp_sid,omitempty"`
 }
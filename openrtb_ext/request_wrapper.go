@@ -635,6 +635,10 @@ type RegExt struct {
 	extDirty       bool
 	usPrivacy      string
 	usPrivacyDirty bool
+	gpp            string
+	gppDirty       bool
+	gppSID         []int8
+	gppSIDDirty    bool
 }
 
 func (re *RegExt) unmarshal(extJson json.RawMessage) error {
@@ -651,7 +655,19 @@ func (re *RegExt) unmarshal(extJson json.RawMessage) error {
 	}
 	uspJson, hasUsp := re.ext["us_privacy"]
 	if hasUsp {
-		err = json.Unmarshal(uspJson, &re.usPrivacy)
+		if err = json.Unmarshal(uspJson, &re.usPrivacy); err != nil {
+			return err
+		}
+	}
+	gppJson, hasGPP := re.ext["gpp"]
+	if hasGPP {
+		if err = json.Unmarshal(gppJson, &re.gpp); err != nil {
+			return err
+		}
+	}
+	gppSIDJson, hasGPPSID := re.ext["gpp_sid"]
+	if hasGPPSID {
+		err = json.Unmarshal(gppSIDJson, &re.gppSID)
 	}
 
 	return err
@@ -670,6 +686,30 @@ func (re *RegExt) marshal() (json.RawMessage, error) {
 		}
 		re.usPrivacyDirty = false
 	}
+	if re.gppDirty {
+		if len(re.gpp) > 0 {
+			rawjson, err := json.Marshal(re.gpp)
+			if err != nil {
+				return nil, err
+			}
+			re.ext["gpp"] = rawjson
+		} else {
+			delete(re.ext, "gpp")
+		}
+		re.gppDirty = false
+	}
+	if re.gppSIDDirty {
+		if len(re.gppSID) > 0 {
+			rawjson, err := json.Marshal(re.gppSID)
+			if err != nil {
+				return nil, err
+			}
+			re.ext["gpp_sid"] = rawjson
+		} else {
+			delete(re.ext, "gpp_sid")
+		}
+		re.gppSIDDirty = false
+	}
 
 	re.extDirty = false
 	if len(re.ext) == 0 {
@@ -679,7 +719,7 @@ func (re *RegExt) marshal() (json.RawMessage, error) {
 }
 
 func (re *RegExt) Dirty() bool {
-	return re.extDirty || re.usPrivacyDirty
+	return re.extDirty || re.usPrivacyDirty || re.gppDirty || re.gppSIDDirty
 }
 
 func (re *RegExt) GetExt() map[string]json.RawMessage {
@@ -705,6 +745,30 @@ func (re *RegExt) SetUSPrivacy(uSPrivacy string) {
 	re.usPrivacyDirty = true
 }
 
+func (re *RegExt) GetGPP() string {
+	gpp := re.gpp
+	return gpp
+}
+
+func (re *RegExt) SetGPP(gpp string) {
+	re.gpp = gpp
+	re.gppDirty = true
+}
+
+func (re *RegExt) GetGPPSID() []int8 {
+	if re.gppSID == nil {
+		return nil
+	}
+	gppSID := make([]int8, len(re.gppSID))
+	copy(gppSID, re.gppSID)
+	return gppSID
+}
+
+func (re *RegExt) SetGPPSID(gppSID []int8) {
+	re.gppSID = gppSID
+	re.gppSIDDirty = true
+}
+
 // ---------------------------------------------------------------
 // SiteExt provides an interface for request.site.ext
 // ---------------------------------------------------------------
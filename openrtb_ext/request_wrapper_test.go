@@ -20,3 +20,21 @@ func TestUserExt(t *testing.T) {
 	assert.Equal(t, "NewConsent", *userExt.GetConsent())
 
 }
+
+func TestRegExtGPP(t *testing.T) {
+	regExt := &RegExt{}
+
+	err := regExt.unmarshal([]byte(`{"gpp":"DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA","gpp_sid":[2]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, false, regExt.Dirty(), "Unmarshalled RegExt should not be dirty.")
+	assert.Equal(t, "DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA", regExt.GetGPP())
+	assert.Equal(t, []int8{2}, regExt.GetGPPSID())
+
+	regExt.SetGPP("")
+	regExt.SetGPPSID([]int8{6})
+	assert.Equal(t, true, regExt.Dirty(), "Modified RegExt should be dirty.")
+
+	extJson, err := regExt.marshal()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"gpp_sid":[6]}`, string(extJson))
+}
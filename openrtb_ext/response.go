@@ -1,6 +1,10 @@
 package openrtb_ext
 
-import "github.com/mxmCherry/openrtb/v15/openrtb2"
+import (
+	"encoding/json"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+)
 
 // ExtBidResponse defines the contract for bidresponse.ext
 type ExtBidResponse struct {
@@ -38,6 +42,9 @@ type ExtResponseSyncData struct {
 // ExtResponsePrebid defines the contract for bidresponse.ext.prebid
 type ExtResponsePrebid struct {
 	AuctionTimestamp int64 `json:"auctiontimestamp,omitempty"`
+	// Modules defines the contract for bidresponse.ext.prebid.modules, which holds the outcomes of the
+	// module hooks when debug is enabled
+	Modules json.RawMessage `json:"modules,omitempty"`
 }
 
 // ExtUserSync defines the contract for bidresponse.ext.usersync.{bidder}.syncs[i]
@@ -16,6 +16,8 @@ type ExtUser struct {
 // ExtUserPrebid defines the contract for bidrequest.user.ext.prebid
 type ExtUserPrebid struct {
 	BuyerUIDs map[string]string `json:"buyeruids,omitempty"`
+	// FirstPartyID is the ID under which the UIDs of the user are kept in the UID store, if the request has no host cookie
+	FirstPartyID string `json:"fpid,omitempty"`
 }
 
 // ExtUserEid defines the contract for bidrequest.user.ext.eids
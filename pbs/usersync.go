@@ -42,6 +42,8 @@ type UserSyncDeps struct {
 	HostCookieConfig *config.HostCookie
 	MetricsEngine    metrics.MetricsEngine
 	PBSAnalytics     analytics.PBSAnalyticsModule
+	// UIDStore is nil when the UIDs are kept in the uids cookie
+	UIDStore usersync.UIDStore
 }
 
 // Struct for parsing json in google's response
@@ -95,7 +97,15 @@ func (deps *UserSyncDeps) OptOut(w http.ResponseWriter, r *http.Request, _ httpr
 	pc := usersync.ParseCookieFromRequest(r, deps.HostCookieConfig)
 	pc.SetOptOut(optout != "")
 
-	pc.SetCookieOnResponse(w, false, deps.HostCookieConfig, deps.HostCookieConfig.TTLDuration())
+	if optout != "" && deps.UIDStore != nil {
+		if userKey := usersync.UserKey(r, deps.HostCookieConfig, r.FormValue(usersync.FirstPartyIDParam)); userKey != "" {
+			if err := deps.UIDStore.Purge(r.Context(), userKey); err != nil {
+				glog.Errorf("Opt Out failed to purge the UIDs of the user: %v", err)
+			}
+		}
+	}
+
+	pc.SetCookieOnResponse(w, false, deps.HostCookieConfig, deps.HostCookieConfig.TTLDuration(), nil)
 
 	if optout == "" {
 		http.Redirect(w, r, deps.HostCookieConfig.OptInURL, 301)
@@ -14,9 +14,12 @@ import (
 
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/tracing"
 
 	"github.com/buger/jsonparser"
 	"github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context/ctxhttp"
 )
 
@@ -107,16 +110,22 @@ func (c *clientImpl) PutJson(ctx context.Context, values []Cacheable) (uuids []s
 	httpReq.Header.Add("Content-Type", "application/json;charset=utf-8")
 	httpReq.Header.Add("Accept", "application/json")
 
+	ctx, span := tracing.StartSpan(ctx, "prebid_cache.put", trace.SpanKindClient)
+	defer span.End()
+	span.SetAttributes(attribute.Int("items", len(values)))
+
 	startTime := time.Now()
-	anResp, err := ctxhttp.Do(ctx, c.httpClient, httpReq)
+	anResp, err := ctxhttp.Do(tracing.WithClientTrace(ctx, span), c.httpClient, httpReq)
 	elapsedTime := time.Since(startTime)
 	if err != nil {
+		tracing.RecordError(span, err)
 		c.metrics.RecordPrebidCacheRequestTime(false, elapsedTime)
 		logError(&errs, "Error sending the request to Prebid Cache: %v; Duration=%v, Items=%v, Payload Size=%v", err, elapsedTime, len(values), len(postBody))
 		return uuidsToReturn, errs
 	}
 	defer anResp.Body.Close()
 	c.metrics.RecordPrebidCacheRequestTime(true, elapsedTime)
+	tracing.SetHTTPStatus(span, trace.SpanKindClient, anResp.StatusCode)
 
 	responseBody, err := ioutil.ReadAll(anResp.Body)
 	if anResp.StatusCode != 200 {
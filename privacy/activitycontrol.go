@@ -0,0 +1,178 @@
+package privacy
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+)
+
+// Activity is an action carrying a privacy risk, which the activity controls of an account may deny.
+type Activity int
+
+const (
+	ActivitySyncUser Activity = iota
+	ActivityFetchBids
+	ActivityTransmitUserFPD
+	ActivityTransmitPreciseGeo
+	ActivityTransmitEids
+	ActivityReportAnalytics
+)
+
+// Component types, as matched by the component_type condition of the activity rules.
+const (
+	ComponentTypeBidder    = "bidder"
+	ComponentTypeAnalytics = "analytics"
+)
+
+// Component identifies the bidder or module performing an activity.
+type Component struct {
+	Type string
+	Name string
+}
+
+// ActivityRequest holds the properties of a request the activity rules match on.
+type ActivityRequest struct {
+	Country string
+	Region  string
+	GPPSID  []int8
+}
+
+// NewActivityRequest extracts the properties the activity rules match on from an OpenRTB bid request. The device
+// geo takes precedence over the user geo.
+func NewActivityRequest(bidRequest *openrtb2.BidRequest) ActivityRequest {
+	request := ActivityRequest{}
+	if bidRequest == nil {
+		return request
+	}
+
+	var geo *openrtb2.Geo
+	if bidRequest.Device != nil && bidRequest.Device.Geo != nil {
+		geo = bidRequest.Device.Geo
+	} else if bidRequest.User != nil && bidRequest.User.Geo != nil {
+		geo = bidRequest.User.Geo
+	}
+	if geo != nil {
+		request.Country = geo.Country
+		request.Region = geo.Region
+	}
+
+	if bidRequest.Regs != nil && len(bidRequest.Regs.Ext) > 0 {
+		var regsExt struct {
+			GPPSID []int8 `json:"gpp_sid"`
+		}
+		if err := json.Unmarshal(bidRequest.Regs.Ext, &regsExt); err == nil {
+			request.GPPSID = regsExt.GPPSID
+		}
+	}
+	return request
+}
+
+// ActivityControl decides whether the activities are allowed, according to the activity controls of an account.
+// The zero value allows all activities.
+type ActivityControl struct {
+	activities map[Activity]config.Activity
+}
+
+// NewActivityControl returns the activity control of the account privacy configuration.
+func NewActivityControl(privacyConfig *config.AccountPrivacy) ActivityControl {
+	if privacyConfig == nil {
+		return ActivityControl{}
+	}
+
+	allow := privacyConfig.AllowActivities
+	activities := map[Activity]config.Activity{
+		ActivitySyncUser:           allow.SyncUser,
+		ActivityFetchBids:          allow.FetchBids,
+		ActivityTransmitUserFPD:    allow.TransmitUfpd,
+		ActivityTransmitPreciseGeo: allow.TransmitPreciseGeo,
+		ActivityTransmitEids:       allow.TransmitEids,
+		ActivityReportAnalytics:    allow.ReportAnalytics,
+	}
+	for activity, control := range activities {
+		if control.Default == nil && len(control.Rules) == 0 {
+			delete(activities, activity)
+		}
+	}
+
+	if len(activities) == 0 {
+		return ActivityControl{}
+	}
+	return ActivityControl{activities: activities}
+}
+
+// Allow returns true if the component is allowed to perform the activity for the request. The first rule whose
+// condition matches decides, the activity default applies when none does.
+func (c ActivityControl) Allow(activity Activity, component Component, request ActivityRequest) bool {
+	control, found := c.activities[activity]
+	if !found {
+		return true
+	}
+
+	for _, rule := range control.Rules {
+		if conditionMatches(rule.Condition, component, request) {
+			return rule.Allow
+		}
+	}
+
+	if control.Default != nil {
+		return *control.Default
+	}
+	return true
+}
+
+func conditionMatches(condition config.ActivityCondition, component Component, request ActivityRequest) bool {
+	if len(condition.ComponentType) > 0 && !containsFold(condition.ComponentType, component.Type) {
+		return false
+	}
+	if len(condition.ComponentName) > 0 && !containsFold(condition.ComponentName, component.Name) {
+		return false
+	}
+	if len(condition.GPPSID) > 0 && !intersects(condition.GPPSID, request.GPPSID) {
+		return false
+	}
+	if len(condition.Geo) > 0 && !geoMatches(condition.Geo, request.Country, request.Region) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func intersects(a, b []int8) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// geoMatches returns true if one of the geos, a country optionally followed by a dot and a region, matches the
+// request country and region.
+func geoMatches(geos []string, country, region string) bool {
+	if country == "" {
+		return false
+	}
+
+	for _, geo := range geos {
+		geoCountry, geoRegion := geo, ""
+		if i := strings.Index(geo, "."); i >= 0 {
+			geoCountry, geoRegion = geo[:i], geo[i+1:]
+		}
+		if strings.EqualFold(geoCountry, country) && (geoRegion == "" || strings.EqualFold(geoRegion, region)) {
+			return true
+		}
+	}
+	return false
+}
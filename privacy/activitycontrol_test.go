@@ -0,0 +1,179 @@
+package privacy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivityControlAllow(t *testing.T) {
+	trueValue, falseValue := true, false
+
+	testCases := []struct {
+		description string
+		activity    config.Activity
+		component   Component
+		request     ActivityRequest
+		expected    bool
+	}{
+		{
+			description: "Not Configured",
+			activity:    config.Activity{},
+			component:   Component{Type: ComponentTypeBidder, Name: "appnexus"},
+			expected:    true,
+		},
+		{
+			description: "Default Deny",
+			activity:    config.Activity{Default: &falseValue},
+			component:   Component{Type: ComponentTypeBidder, Name: "appnexus"},
+			expected:    false,
+		},
+		{
+			description: "Component Name Rule - Case Insensitive",
+			activity: config.Activity{
+				Default: &trueValue,
+				Rules:   []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{"AppNexus"}}, Allow: false}},
+			},
+			component: Component{Type: ComponentTypeBidder, Name: "appnexus"},
+			expected:  false,
+		},
+		{
+			description: "Component Name Rule - Other Component",
+			activity: config.Activity{
+				Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{"rubicon"}}, Allow: false}},
+			},
+			component: Component{Type: ComponentTypeBidder, Name: "appnexus"},
+			expected:  true,
+		},
+		{
+			description: "Component Type Rule",
+			activity: config.Activity{
+				Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentType: []string{ComponentTypeAnalytics}}, Allow: false}},
+			},
+			component: Component{Type: ComponentTypeAnalytics, Name: "pubstack"},
+			expected:  false,
+		},
+		{
+			description: "First Matching Rule Decides",
+			activity: config.Activity{
+				Rules: []config.ActivityRule{
+					{Condition: config.ActivityCondition{ComponentName: []string{"appnexus"}}, Allow: true},
+					{Condition: config.ActivityCondition{ComponentType: []string{ComponentTypeBidder}}, Allow: false},
+				},
+			},
+			component: Component{Type: ComponentTypeBidder, Name: "appnexus"},
+			expected:  true,
+		},
+		{
+			description: "All Conditions Must Match",
+			activity: config.Activity{
+				Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{"appnexus"}, GPPSID: []int8{7}}, Allow: false}},
+			},
+			component: Component{Type: ComponentTypeBidder, Name: "appnexus"},
+			request:   ActivityRequest{GPPSID: []int8{2}},
+			expected:  true,
+		},
+		{
+			description: "GPP SID Rule",
+			activity: config.Activity{
+				Rules: []config.ActivityRule{{Condition: config.ActivityCondition{GPPSID: []int8{7, 8}}, Allow: false}},
+			},
+			component: Component{Type: ComponentTypeBidder, Name: "appnexus"},
+			request:   ActivityRequest{GPPSID: []int8{2, 8}},
+			expected:  false,
+		},
+		{
+			description: "Geo Rule - Country",
+			activity: config.Activity{
+				Rules: []config.ActivityRule{{Condition: config.ActivityCondition{Geo: []string{"USA"}}, Allow: false}},
+			},
+			component: Component{Type: ComponentTypeBidder, Name: "appnexus"},
+			request:   ActivityRequest{Country: "usa", Region: "CA"},
+			expected:  false,
+		},
+		{
+			description: "Geo Rule - Region",
+			activity: config.Activity{
+				Rules: []config.ActivityRule{{Condition: config.ActivityCondition{Geo: []string{"USA.VA"}}, Allow: false}},
+			},
+			component: Component{Type: ComponentTypeBidder, Name: "appnexus"},
+			request:   ActivityRequest{Country: "USA", Region: "CA"},
+			expected:  true,
+		},
+		{
+			description: "Geo Rule - Unknown Geo",
+			activity: config.Activity{
+				Rules: []config.ActivityRule{{Condition: config.ActivityCondition{Geo: []string{"USA"}}, Allow: false}},
+			},
+			component: Component{Type: ComponentTypeBidder, Name: "appnexus"},
+			expected:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		activityControl := NewActivityControl(&config.AccountPrivacy{
+			AllowActivities: config.AllowActivities{FetchBids: test.activity},
+		})
+
+		result := activityControl.Allow(ActivityFetchBids, test.component, test.request)
+		assert.Equal(t, test.expected, result, test.description)
+
+		result = activityControl.Allow(ActivitySyncUser, test.component, test.request)
+		assert.True(t, result, test.description+":otherActivity")
+	}
+}
+
+func TestNewActivityControlNotConfigured(t *testing.T) {
+	assert.Equal(t, ActivityControl{}, NewActivityControl(nil))
+	assert.Equal(t, ActivityControl{}, NewActivityControl(&config.AccountPrivacy{}))
+}
+
+func TestNewActivityRequest(t *testing.T) {
+	testCases := []struct {
+		description string
+		request     *openrtb2.BidRequest
+		expected    ActivityRequest
+	}{
+		{
+			description: "Nil",
+			request:     nil,
+			expected:    ActivityRequest{},
+		},
+		{
+			description: "Device Geo",
+			request: &openrtb2.BidRequest{
+				Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "USA", Region: "CA"}},
+				User:   &openrtb2.User{Geo: &openrtb2.Geo{Country: "FRA"}},
+			},
+			expected: ActivityRequest{Country: "USA", Region: "CA"},
+		},
+		{
+			description: "User Geo",
+			request: &openrtb2.BidRequest{
+				User: &openrtb2.User{Geo: &openrtb2.Geo{Country: "FRA"}},
+			},
+			expected: ActivityRequest{Country: "FRA"},
+		},
+		{
+			description: "GPP SID",
+			request: &openrtb2.BidRequest{
+				Regs: &openrtb2.Regs{Ext: json.RawMessage(`{"gpp_sid":[2,6]}`)},
+			},
+			expected: ActivityRequest{GPPSID: []int8{2, 6}},
+		},
+		{
+			description: "Malformed Regs Ext",
+			request: &openrtb2.BidRequest{
+				Regs: &openrtb2.Regs{Ext: json.RawMessage(`malformed`)},
+			},
+			expected: ActivityRequest{},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, NewActivityRequest(test.request), test.description)
+	}
+}
@@ -134,6 +134,16 @@ func (p ParsedPolicy) isNoSaleForBidder(bidder string) bool {
 	return exists
 }
 
+// WithOptOut returns a copy of the policy which also enforces an opt-out of sale signaled outside of the
+// us_privacy string, such as by a US section of a GPP string. The request.ext.prebid.nosale bidders stay exempt.
+func (p ParsedPolicy) WithOptOut(optOut bool) ParsedPolicy {
+	if optOut {
+		p.consentSpecified = true
+		p.consentOptOutSale = true
+	}
+	return p
+}
+
 // ShouldEnforce returns true when the opt-out signal is explicitly detected.
 func (p ParsedPolicy) ShouldEnforce(bidder string) bool {
 	return !p.isNoSaleForBidder(bidder) && p.consentOptOutSale
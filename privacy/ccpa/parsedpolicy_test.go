@@ -381,6 +381,63 @@ func TestShouldEnforce(t *testing.T) {
 	}
 }
 
+func TestWithOptOut(t *testing.T) {
+	testCases := []struct {
+		description string
+		policy      ParsedPolicy
+		optOut      bool
+		expected    ParsedPolicy
+	}{
+		{
+			description: "Opt-Out - Not Specified",
+			policy: ParsedPolicy{
+				noSaleSpecificBidders: map[string]struct{}{"a": {}},
+			},
+			optOut: true,
+			expected: ParsedPolicy{
+				consentSpecified:      true,
+				consentOptOutSale:     true,
+				noSaleSpecificBidders: map[string]struct{}{"a": {}},
+			},
+		},
+		{
+			description: "Opt-Out - Already Specified Without Opt-Out",
+			policy: ParsedPolicy{
+				consentSpecified:  true,
+				consentOptOutSale: false,
+			},
+			optOut: true,
+			expected: ParsedPolicy{
+				consentSpecified:  true,
+				consentOptOutSale: true,
+			},
+		},
+		{
+			description: "No Opt-Out - Policy Unchanged",
+			policy: ParsedPolicy{
+				consentSpecified:  true,
+				consentOptOutSale: true,
+			},
+			optOut: false,
+			expected: ParsedPolicy{
+				consentSpecified:  true,
+				consentOptOutSale: true,
+			},
+		},
+		{
+			description: "No Opt-Out - Not Specified",
+			policy:      ParsedPolicy{},
+			optOut:      false,
+			expected:    ParsedPolicy{},
+		},
+	}
+
+	for _, test := range testCases {
+		result := test.policy.WithOptOut(test.optOut)
+		assert.Equal(t, test.expected, result, test.description)
+	}
+}
+
 type mockPolicWriter struct {
 	mock.Mock
 }
@@ -9,11 +9,16 @@ type Enforcement struct {
 	GDPRGeo bool
 	GDPRID  bool
 	LMT     bool
+
+	// activity controls
+	UFPD       bool
+	Eids       bool
+	PreciseGeo bool
 }
 
 // Any returns true if at least one privacy policy requires enforcement.
 func (e Enforcement) Any() bool {
-	return e.CCPA || e.COPPA || e.GDPRGeo || e.GDPRID || e.LMT
+	return e.CCPA || e.COPPA || e.GDPRGeo || e.GDPRID || e.LMT || e.UFPD || e.Eids || e.PreciseGeo
 }
 
 // Apply cleans personally identifiable information from an OpenRTB bid request.
@@ -29,7 +34,7 @@ func (e Enforcement) apply(bidRequest *openrtb2.BidRequest, scrubber Scrubber) {
 }
 
 func (e Enforcement) getDeviceIDScrubStrategy() ScrubStrategyDeviceID {
-	if e.COPPA || e.GDPRID || e.CCPA || e.LMT {
+	if e.COPPA || e.GDPRID || e.CCPA || e.LMT || e.UFPD {
 		return ScrubStrategyDeviceIDAll
 	}
 
@@ -37,7 +42,7 @@ func (e Enforcement) getDeviceIDScrubStrategy() ScrubStrategyDeviceID {
 }
 
 func (e Enforcement) getIPv4ScrubStrategy() ScrubStrategyIPV4 {
-	if e.COPPA || e.GDPRGeo || e.CCPA || e.LMT {
+	if e.COPPA || e.GDPRGeo || e.CCPA || e.LMT || e.PreciseGeo {
 		return ScrubStrategyIPV4Lowest8
 	}
 
@@ -49,7 +54,7 @@ func (e Enforcement) getIPv6ScrubStrategy() ScrubStrategyIPV6 {
 		return ScrubStrategyIPV6Lowest32
 	}
 
-	if e.GDPRGeo || e.CCPA || e.LMT {
+	if e.GDPRGeo || e.CCPA || e.LMT || e.PreciseGeo {
 		return ScrubStrategyIPV6Lowest16
 	}
 
@@ -61,7 +66,7 @@ func (e Enforcement) getGeoScrubStrategy() ScrubStrategyGeo {
 		return ScrubStrategyGeoFull
 	}
 
-	if e.GDPRGeo || e.CCPA || e.LMT {
+	if e.GDPRGeo || e.CCPA || e.LMT || e.PreciseGeo {
 		return ScrubStrategyGeoReducedPrecision
 	}
 
@@ -69,6 +74,13 @@ func (e Enforcement) getGeoScrubStrategy() ScrubStrategyGeo {
 }
 
 func (e Enforcement) getUserScrubStrategy() ScrubStrategyUser {
+	if e.UFPD {
+		if e.COPPA || e.CCPA || e.LMT || e.GDPRID || e.Eids {
+			return ScrubStrategyUserFPDAndEids
+		}
+		return ScrubStrategyUserFPD
+	}
+
 	if e.COPPA {
 		return ScrubStrategyUserIDAndDemographic
 	}
@@ -81,5 +93,9 @@ func (e Enforcement) getUserScrubStrategy() ScrubStrategyUser {
 		return ScrubStrategyUserID
 	}
 
+	if e.Eids {
+		return ScrubStrategyUserEids
+	}
+
 	return ScrubStrategyUserNone
 }
@@ -194,6 +194,55 @@ func TestApply(t *testing.T) {
 			expectedUser:       ScrubStrategyUserIDAndDemographic,
 			expectedUserGeo:    ScrubStrategyGeoFull,
 		},
+		{
+			description: "Activity Controls: UFPD Only",
+			enforcement: Enforcement{
+				UFPD: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDAll,
+			expectedDeviceIPv4: ScrubStrategyIPV4None,
+			expectedDeviceIPv6: ScrubStrategyIPV6None,
+			expectedDeviceGeo:  ScrubStrategyGeoNone,
+			expectedUser:       ScrubStrategyUserFPD,
+			expectedUserGeo:    ScrubStrategyGeoNone,
+		},
+		{
+			description: "Activity Controls: Eids Only",
+			enforcement: Enforcement{
+				Eids: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDNone,
+			expectedDeviceIPv4: ScrubStrategyIPV4None,
+			expectedDeviceIPv6: ScrubStrategyIPV6None,
+			expectedDeviceGeo:  ScrubStrategyGeoNone,
+			expectedUser:       ScrubStrategyUserEids,
+			expectedUserGeo:    ScrubStrategyGeoNone,
+		},
+		{
+			description: "Activity Controls: UFPD + Eids",
+			enforcement: Enforcement{
+				UFPD: true,
+				Eids: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDAll,
+			expectedDeviceIPv4: ScrubStrategyIPV4None,
+			expectedDeviceIPv6: ScrubStrategyIPV6None,
+			expectedDeviceGeo:  ScrubStrategyGeoNone,
+			expectedUser:       ScrubStrategyUserFPDAndEids,
+			expectedUserGeo:    ScrubStrategyGeoNone,
+		},
+		{
+			description: "Activity Controls: Precise Geo Only",
+			enforcement: Enforcement{
+				PreciseGeo: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDNone,
+			expectedDeviceIPv4: ScrubStrategyIPV4Lowest8,
+			expectedDeviceIPv6: ScrubStrategyIPV6Lowest16,
+			expectedDeviceGeo:  ScrubStrategyGeoReducedPrecision,
+			expectedUser:       ScrubStrategyUserNone,
+			expectedUserGeo:    ScrubStrategyGeoReducedPrecision,
+		},
 	}
 
 	for _, test := range testCases {
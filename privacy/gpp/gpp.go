@@ -0,0 +1,283 @@
+package gpp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SectionID identifies a section of a GPP string, as registered by the IAB GPP specification.
+type SectionID int8
+
+const (
+	SectionTCFEU2 SectionID = 2
+	SectionUSPV1  SectionID = 6
+	SectionUSNat  SectionID = 7
+	SectionUSCA   SectionID = 8
+	SectionUSVA   SectionID = 9
+	SectionUSCO   SectionID = 10
+	SectionUSUT   SectionID = 11
+	SectionUSCT   SectionID = 12
+)
+
+// maxSectionID is the highest section ID registered. The header can't list sections beyond it.
+const maxSectionID = SectionUSCT
+
+// maxInt is the largest value of an int
+const maxInt = int(^uint(0) >> 1)
+
+const (
+	headerType       = 3
+	sectionSeparator = "~"
+)
+
+// GPP represents a parsed GPP string. The sections are kept encoded, they are decoded by the methods reading them.
+type GPP struct {
+	Version      int
+	SectionTypes []SectionID
+	Sections     map[SectionID]string
+}
+
+// Parse decodes the header of the GPP string and splits it into its sections.
+func Parse(gpp string) (GPP, error) {
+	segments := strings.Split(gpp, sectionSeparator)
+
+	header, err := newBitReader(segments[0])
+	if err != nil {
+		return GPP{}, fmt.Errorf("invalid GPP header: %v", err)
+	}
+
+	headerTypeValue, err := header.readInt(6)
+	if err != nil {
+		return GPP{}, fmt.Errorf("invalid GPP header: %v", err)
+	}
+	if headerTypeValue != headerType {
+		return GPP{}, fmt.Errorf("invalid GPP header: type %d instead of %d", headerTypeValue, headerType)
+	}
+
+	version, err := header.readInt(6)
+	if err != nil {
+		return GPP{}, fmt.Errorf("invalid GPP header: %v", err)
+	}
+
+	sectionTypes, err := header.readFibonacciRange(int(maxSectionID))
+	if err != nil {
+		return GPP{}, fmt.Errorf("invalid GPP header: %v", err)
+	}
+
+	if len(sectionTypes) != len(segments)-1 {
+		return GPP{}, fmt.Errorf("GPP header lists %d sections but the string contains %d", len(sectionTypes), len(segments)-1)
+	}
+
+	parsed := GPP{
+		Version:      version,
+		SectionTypes: make([]SectionID, 0, len(sectionTypes)),
+		Sections:     make(map[SectionID]string, len(sectionTypes)),
+	}
+	for i, sectionType := range sectionTypes {
+		id := SectionID(sectionType)
+		if _, found := parsed.Sections[id]; found {
+			return GPP{}, fmt.Errorf("GPP header lists section %d more than once", id)
+		}
+		if segments[i+1] == "" {
+			return GPP{}, fmt.Errorf("GPP section %d is empty", id)
+		}
+		parsed.SectionTypes = append(parsed.SectionTypes, id)
+		parsed.Sections[id] = segments[i+1]
+	}
+	return parsed, nil
+}
+
+// TCF2Consent returns the TCF EU v2 consent string of the GPP string, or an empty string if it has none.
+func (g GPP) TCF2Consent() string {
+	return g.Sections[SectionTCFEU2]
+}
+
+// USPrivacy returns the us_privacy string of the USP v1 section, or an empty string if it has none.
+func (g GPP) USPrivacy() string {
+	return g.Sections[SectionUSPV1]
+}
+
+// usOptOutFields holds the positions of the opt-out fields of the US sections, among the two bits fields which
+// follow the six bits version of their core segment: sale and sharing of personal data and targeted advertising.
+var usOptOutFields = map[SectionID][]int{
+	SectionUSNat: {6, 7, 8},
+	SectionUSCA:  {3, 4},
+	SectionUSVA:  {3, 4},
+	SectionUSCO:  {3, 4},
+	SectionUSUT:  {4, 5},
+	SectionUSCT:  {3, 4},
+}
+
+const usOptedOut = 1
+
+// USOptOut returns true if one of the US national or state sections applicable to the request signals the user
+// opted out of the sale or sharing of their personal data, or of targeted advertising.
+func (g GPP) USOptOut(sid []int8) (bool, error) {
+	for _, id := range g.SectionTypes {
+		fields, isUSSection := usOptOutFields[id]
+		if !isUSSection || !Applies(sid, id) {
+			continue
+		}
+
+		// Only the core segment holds the opt-outs, the optional segments follow it after a dot
+		core := strings.SplitN(g.Sections[id], ".", 2)[0]
+		reader, err := newBitReader(core)
+		if err != nil {
+			return false, fmt.Errorf("invalid GPP section %d: %v", id, err)
+		}
+		if _, err := reader.readInt(6); err != nil {
+			return false, fmt.Errorf("invalid GPP section %d: %v", id, err)
+		}
+
+		position := 0
+		for _, field := range fields {
+			value := 0
+			for ; position <= field; position++ {
+				if value, err = reader.readInt(2); err != nil {
+					return false, fmt.Errorf("invalid GPP section %d: %v", id, err)
+				}
+			}
+			if value == usOptedOut {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Applies returns true if the section is listed among the sections applicable to the request.
+func Applies(sid []int8, section SectionID) bool {
+	for _, id := range sid {
+		if SectionID(id) == section {
+			return true
+		}
+	}
+	return false
+}
+
+const base64URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+var (
+	errNotEnoughBits = errors.New("not enough bits")
+	errIntOverflow   = errors.New("integer overflow")
+)
+
+// bitReader reads the values of a GPP segment, which is a base64url encoded bit field without padding.
+type bitReader struct {
+	bits     []byte
+	position int
+}
+
+func newBitReader(segment string) (*bitReader, error) {
+	bits := make([]byte, 0, 6*len(segment))
+	for _, c := range segment {
+		value := strings.IndexRune(base64URLAlphabet, c)
+		if value < 0 {
+			return nil, fmt.Errorf("invalid character %q", c)
+		}
+		for shift := 5; shift >= 0; shift-- {
+			bits = append(bits, byte(value>>uint(shift))&1)
+		}
+	}
+	return &bitReader{bits: bits}, nil
+}
+
+func (r *bitReader) readBit() (byte, error) {
+	if r.position >= len(r.bits) {
+		return 0, errNotEnoughBits
+	}
+	bit := r.bits[r.position]
+	r.position++
+	return bit, nil
+}
+
+func (r *bitReader) readInt(length int) (int, error) {
+	value := 0
+	for i := 0; i < length; i++ {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		value = value<<1 | int(bit)
+	}
+	return value, nil
+}
+
+// readFibonacci reads a Fibonacci encoded integer, which ends with two consecutive 1 bits.
+func (r *bitReader) readFibonacci() (int, error) {
+	value := 0
+	previous, current := 1, 1
+	// overflowed is set once the next Fibonacci number doesn't fit in an int
+	overflowed := false
+	var lastBit byte
+	for {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		if bit == 1 && lastBit == 1 {
+			return value, nil
+		}
+		if bit == 1 {
+			if overflowed || current > maxInt-value {
+				return 0, errIntOverflow
+			}
+			value += current
+		}
+		if current > maxInt-previous {
+			overflowed = true
+		} else {
+			previous, current = current, previous+current
+		}
+		lastBit = bit
+	}
+}
+
+// readFibonacciRange reads a list of integers made of single values and ranges, each of them encoded as the
+// Fibonacci encoded offset from the previous value. The values can't be greater than maxValue, and so the list
+// can't be longer than maxValue either.
+func (r *bitReader) readFibonacciRange(maxValue int) ([]int, error) {
+	count, err := r.readInt(12)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []int
+	last := 0
+	for i := 0; i < count; i++ {
+		isRange, err := r.readBit()
+		if err != nil {
+			return nil, err
+		}
+
+		offset, err := r.readFibonacci()
+		if err != nil {
+			return nil, err
+		}
+		if offset > maxValue-last {
+			return nil, fmt.Errorf("value greater than %d", maxValue)
+		}
+		start := last + offset
+		last = start
+
+		if isRange == 1 {
+			offset, err := r.readFibonacci()
+			if err != nil {
+				return nil, err
+			}
+			if offset > maxValue-start {
+				return nil, fmt.Errorf("value greater than %d", maxValue)
+			}
+			last = start + offset
+		}
+
+		if len(values)+last-start+1 > maxValue {
+			return nil, fmt.Errorf("more than %d values", maxValue)
+		}
+		for value := start; value <= last; value++ {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,206 @@
+package gpp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tcf2Consent = "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+
+// encodeBits encodes a bit field, which may contain spaces for readability, the way GPP segments are encoded.
+func encodeBits(bits string) string {
+	bits = strings.ReplaceAll(bits, " ", "")
+	for len(bits)%6 != 0 {
+		bits += "0"
+	}
+	var encoded strings.Builder
+	for i := 0; i < len(bits); i += 6 {
+		value := 0
+		for _, bit := range bits[i : i+6] {
+			value = value<<1 | int(bit-'0')
+		}
+		encoded.WriteByte(base64URLAlphabet[value])
+	}
+	return encoded.String()
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		description   string
+		gpp           string
+		expectedGPP   GPP
+		expectedError string
+	}{
+		{
+			description: "TCF EU v2 section",
+			gpp:         "DBABMA~" + tcf2Consent,
+			expectedGPP: GPP{
+				Version:      1,
+				SectionTypes: []SectionID{SectionTCFEU2},
+				Sections:     map[SectionID]string{SectionTCFEU2: tcf2Consent},
+			},
+		},
+		{
+			description: "TCF EU v2 and USP v1 sections",
+			gpp:         "DBACNY~" + tcf2Consent + "~1YNN",
+			expectedGPP: GPP{
+				Version:      1,
+				SectionTypes: []SectionID{SectionTCFEU2, SectionUSPV1},
+				Sections:     map[SectionID]string{SectionTCFEU2: tcf2Consent, SectionUSPV1: "1YNN"},
+			},
+		},
+		{
+			description: "Range of sections",
+			// one range entry starting at 7 and ending 2 sections later
+			gpp: encodeBits("000011 000001 000000000001 1 01011 011") + "~a~b~c",
+			expectedGPP: GPP{
+				Version:      1,
+				SectionTypes: []SectionID{SectionUSNat, SectionUSCA, SectionUSVA},
+				Sections:     map[SectionID]string{SectionUSNat: "a", SectionUSCA: "b", SectionUSVA: "c"},
+			},
+		},
+		{
+			description:   "Invalid header type",
+			gpp:           "BBABMA~" + tcf2Consent,
+			expectedError: "invalid GPP header: type 1 instead of 3",
+		},
+		{
+			description:   "Invalid header character",
+			gpp:           "DB*BMA~" + tcf2Consent,
+			expectedError: "invalid GPP header: invalid character '*'",
+		},
+		{
+			description:   "Truncated header",
+			gpp:           "DBAB~" + tcf2Consent,
+			expectedError: "invalid GPP header: not enough bits",
+		},
+		{
+			description:   "Huge range of sections",
+			gpp:           "DBAB4AAAAAAY~x",
+			expectedError: "invalid GPP header: value greater than 12",
+		},
+		{
+			description:   "Section above the highest registered one",
+			gpp:           encodeBits("000011 000001 000000000001 0 000001 011") + "~a",
+			expectedError: "invalid GPP header: value greater than 12",
+		},
+		{
+			description:   "Fibonacci integer overflow",
+			gpp:           encodeBits("000011 000001 000000000001 0 "+strings.Repeat("0", 200)+"1011") + "~a",
+			expectedError: "invalid GPP header: integer overflow",
+		},
+		{
+			description:   "Missing section",
+			gpp:           "DBACNY~" + tcf2Consent,
+			expectedError: "GPP header lists 2 sections but the string contains 1",
+		},
+		{
+			description:   "Empty section",
+			gpp:           "DBACNY~" + tcf2Consent + "~",
+			expectedError: "GPP section 6 is empty",
+		},
+	}
+
+	for _, test := range testCases {
+		gpp, err := Parse(test.gpp)
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedGPP, gpp, test.description)
+	}
+}
+
+func TestSectionAccessors(t *testing.T) {
+	gpp, err := Parse("DBACNY~" + tcf2Consent + "~1YNN")
+	if assert.NoError(t, err) {
+		assert.Equal(t, tcf2Consent, gpp.TCF2Consent())
+		assert.Equal(t, "1YNN", gpp.USPrivacy())
+	}
+
+	gpp, err = Parse("DBABMA~" + tcf2Consent)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", gpp.USPrivacy())
+	}
+}
+
+func TestUSOptOut(t *testing.T) {
+	usNatHeader := encodeBits("000011 000001 000000000001 0 01011")
+	usCAHeader := encodeBits("000011 000001 000000000001 0 000011")
+
+	testCases := []struct {
+		description    string
+		gpp            string
+		sid            []int8
+		expectedOptOut bool
+		expectedError  string
+	}{
+		{
+			description:    "US national section, opted out of targeted advertising",
+			gpp:            usNatHeader + "~" + encodeBits("000001 01 01 01 01 01 01 10 10 01") + ".QA",
+			sid:            []int8{7},
+			expectedOptOut: true,
+		},
+		{
+			description:    "US national section, did not opt out",
+			gpp:            usNatHeader + "~" + encodeBits("000001 01 01 01 01 01 01 10 10 10"),
+			sid:            []int8{7},
+			expectedOptOut: false,
+		},
+		{
+			description:    "US national section not applicable",
+			gpp:            usNatHeader + "~" + encodeBits("000001 01 01 01 01 01 01 01 01 01"),
+			sid:            []int8{2},
+			expectedOptOut: false,
+		},
+		{
+			description:    "California section, opted out of sale",
+			gpp:            usCAHeader + "~" + encodeBits("000001 01 01 01 01 10"),
+			sid:            []int8{8},
+			expectedOptOut: true,
+		},
+		{
+			description:    "TCF EU v2 section only",
+			gpp:            "DBABMA~" + tcf2Consent,
+			sid:            []int8{2},
+			expectedOptOut: false,
+		},
+		{
+			description:   "Truncated US national section",
+			gpp:           usNatHeader + "~" + encodeBits("000001 01"),
+			sid:           []int8{7},
+			expectedError: "invalid GPP section 7: not enough bits",
+		},
+	}
+
+	for _, test := range testCases {
+		gpp, err := Parse(test.gpp)
+		if !assert.NoError(t, err, test.description) {
+			continue
+		}
+		optOut, err := gpp.USOptOut(test.sid)
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedOptOut, optOut, test.description)
+	}
+}
+
+func TestParseSID(t *testing.T) {
+	sid, err := ParseSID("2, 6,7")
+	assert.NoError(t, err)
+	assert.Equal(t, []int8{2, 6, 7}, sid)
+	assert.Equal(t, "2,6,7", FormatSID(sid))
+
+	sid, err = ParseSID("")
+	assert.NoError(t, err)
+	assert.Nil(t, sid)
+
+	_, err = ParseSID("2,x")
+	assert.EqualError(t, err, `invalid gpp_sid value "x"`)
+}
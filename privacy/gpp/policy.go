@@ -0,0 +1,62 @@
+package gpp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Policy represents the GPP regulatory information from an OpenRTB bid request or a user sync request.
+type Policy struct {
+	Consent string
+	RawSID  string
+}
+
+// ReadFromRequestWrapper extracts the GPP regulatory information from an OpenRTB bid request.
+func ReadFromRequestWrapper(req *openrtb_ext.RequestWrapper) (Policy, error) {
+	if req == nil {
+		return Policy{}, nil
+	}
+
+	regsExt, err := req.GetRegExt()
+	if err != nil {
+		return Policy{}, fmt.Errorf("error reading request.regs.ext: %s", err)
+	}
+	if regsExt == nil {
+		return Policy{}, nil
+	}
+
+	return Policy{
+		Consent: regsExt.GetGPP(),
+		RawSID:  FormatSID(regsExt.GetGPPSID()),
+	}, nil
+}
+
+// ParseSID parses a comma separated list of applicable section ids, as sent to the user sync endpoints.
+func ParseSID(rawSID string) ([]int8, error) {
+	if rawSID == "" {
+		return nil, nil
+	}
+
+	values := strings.Split(rawSID, ",")
+	sid := make([]int8, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gpp_sid value %q", value)
+		}
+		sid = append(sid, int8(id))
+	}
+	return sid, nil
+}
+
+// FormatSID returns the applicable section ids as a comma separated list.
+func FormatSID(sid []int8) string {
+	values := make([]string, 0, len(sid))
+	for _, id := range sid {
+		values = append(values, strconv.Itoa(int(id)))
+	}
+	return strings.Join(values, ",")
+}
@@ -58,6 +58,16 @@ const (
 
 	// ScrubStrategyUserID removes the user's buyer id.
 	ScrubStrategyUserID
+
+	// ScrubStrategyUserEids removes the user's extended ids.
+	ScrubStrategyUserEids
+
+	// ScrubStrategyUserFPD removes the user's first party data: buyer id, exchange id, year of birth, gender,
+	// keywords and data segments.
+	ScrubStrategyUserFPD
+
+	// ScrubStrategyUserFPDAndEids removes the user's first party data and extended ids.
+	ScrubStrategyUserFPDAndEids
 )
 
 // ScrubStrategyDeviceID defines the approach to remove hardware id and device id data.
@@ -142,6 +152,19 @@ func (scrubber) ScrubUser(user *openrtb2.User, strategy ScrubStrategyUser, geo S
 		userCopy.BuyerUID = ""
 		userCopy.ID = ""
 		userCopy.Ext = scrubUserExtIDs(userCopy.Ext)
+	case ScrubStrategyUserEids:
+		userCopy.Ext = scrubUserExtIDs(userCopy.Ext)
+	case ScrubStrategyUserFPD, ScrubStrategyUserFPDAndEids:
+		userCopy.BuyerUID = ""
+		userCopy.ID = ""
+		userCopy.Yob = 0
+		userCopy.Gender = ""
+		userCopy.Keywords = ""
+		userCopy.Data = nil
+		userCopy.Ext = scrubUserExtFields(userCopy.Ext, "data")
+		if strategy == ScrubStrategyUserFPDAndEids {
+			userCopy.Ext = scrubUserExtIDs(userCopy.Ext)
+		}
 	}
 
 	switch geo {
@@ -214,6 +237,10 @@ func scrubGeoPrecision(geo *openrtb2.Geo) *openrtb2.Geo {
 }
 
 func scrubUserExtIDs(userExt json.RawMessage) json.RawMessage {
+	return scrubUserExtFields(userExt, "eids")
+}
+
+func scrubUserExtFields(userExt json.RawMessage, fields ...string) json.RawMessage {
 	if len(userExt) == 0 {
 		return userExt
 	}
@@ -224,9 +251,14 @@ func scrubUserExtIDs(userExt json.RawMessage) json.RawMessage {
 		return userExt
 	}
 
-	_, hasEids := userExtParsed["eids"]
-	if hasEids {
-		delete(userExtParsed, "eids")
+	scrubbed := false
+	for _, field := range fields {
+		if _, found := userExtParsed[field]; found {
+			delete(userExtParsed, field)
+			scrubbed = true
+		}
+	}
+	if scrubbed {
 		result, err := json.Marshal(userExtParsed)
 		if err == nil {
 			return result
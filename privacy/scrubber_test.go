@@ -379,6 +379,61 @@ func TestScrubUser(t *testing.T) {
 	}
 }
 
+func TestScrubUserActivityControls(t *testing.T) {
+	user := &openrtb2.User{
+		ID:       "anyID",
+		BuyerUID: "anyBuyerUID",
+		Yob:      42,
+		Gender:   "anyGender",
+		Keywords: "anyKeywords",
+		Data:     []openrtb2.Data{{ID: "anyData"}},
+		Ext:      json.RawMessage(`{"anyExisting":42,"data":{"id":42},"eids":[{"source":"anySource"}]}`),
+		Geo:      &openrtb2.Geo{City: "some city"},
+	}
+
+	testCases := []struct {
+		description string
+		expected    *openrtb2.User
+		scrubUser   ScrubStrategyUser
+	}{
+		{
+			description: "Eids",
+			expected: &openrtb2.User{
+				ID:       "anyID",
+				BuyerUID: "anyBuyerUID",
+				Yob:      42,
+				Gender:   "anyGender",
+				Keywords: "anyKeywords",
+				Data:     []openrtb2.Data{{ID: "anyData"}},
+				Ext:      json.RawMessage(`{"anyExisting":42,"data":{"id":42}}`),
+				Geo:      &openrtb2.Geo{City: "some city"},
+			},
+			scrubUser: ScrubStrategyUserEids,
+		},
+		{
+			description: "FPD",
+			expected: &openrtb2.User{
+				Ext: json.RawMessage(`{"anyExisting":42,"eids":[{"source":"anySource"}]}`),
+				Geo: &openrtb2.Geo{City: "some city"},
+			},
+			scrubUser: ScrubStrategyUserFPD,
+		},
+		{
+			description: "FPD And Eids",
+			expected: &openrtb2.User{
+				Ext: json.RawMessage(`{"anyExisting":42}`),
+				Geo: &openrtb2.Geo{City: "some city"},
+			},
+			scrubUser: ScrubStrategyUserFPDAndEids,
+		},
+	}
+
+	for _, test := range testCases {
+		result := NewScrubber().ScrubUser(user, test.scrubUser, ScrubStrategyGeoNone)
+		assert.Equal(t, test.expected, result, test.description)
+	}
+}
+
 func TestScrubUserNil(t *testing.T) {
 	result := NewScrubber().ScrubUser(nil, ScrubStrategyUserNone, ScrubStrategyGeoNone)
 	assert.Nil(t, result)
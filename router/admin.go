@@ -9,7 +9,7 @@ import (
 	"github.com/prebid/prebid-server/endpoints"
 )
 
-func Admin(revision string, rateConverter *currency.RateConverter, rateConverterFetchingInterval time.Duration) *http.ServeMux {
+func Admin(revision string, rateConverter *currency.RateConverter, rateConverterFetchingInterval time.Duration, adminRouter http.Handler) *http.ServeMux {
 	// Add endpoints to the admin server
 	// Making sure to add pprof routes
 	mux := http.NewServeMux()
@@ -22,5 +22,7 @@ func Admin(revision string, rateConverter *currency.RateConverter, rateConverter
 	// Register prebid-server defined admin handlers
 	mux.HandleFunc("/currency/rates", endpoints.NewCurrencyRatesEndpoint(rateConverter, rateConverterFetchingInterval))
 	mux.HandleFunc("/version", endpoints.NewVersionEndpoint(revision))
+	// Stored data cache events and inspection endpoints
+	mux.Handle("/", adminRouter)
 	return mux
 }
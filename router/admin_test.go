@@ -0,0 +1,53 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminServesAdminRouter(t *testing.T) {
+	adminRouter := httprouter.New()
+	adminRouter.POST("/storedrequests/openrtb2", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+	rateConverter := currency.NewRateConverter(&http.Client{}, "", time.Hour)
+	mux := Admin("rev", rateConverter, time.Hour, adminRouter)
+
+	testCases := []struct {
+		description    string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{
+			description:    "admin router endpoint",
+			method:         "POST",
+			path:           "/storedrequests/openrtb2",
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			description:    "admin mux endpoint",
+			method:         "GET",
+			path:           "/version",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			description:    "unknown endpoint",
+			method:         "GET",
+			path:           "/unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, nil))
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.description)
+	}
+}
@@ -0,0 +1,398 @@
+package aspects
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/golang/glog"
+	"github.com/julienschmidt/httprouter"
+	accountService "github.com/prebid/prebid-server/account"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/util/httputil"
+	"github.com/prebid/prebid-server/util/iputil"
+)
+
+const (
+	// accountLimitTTL is how long the rate limits of an account are used before being looked up again
+	accountLimitTTL = time.Minute
+	// accountLookupTimeout bounds the lookup of the rate limits of an account
+	accountLookupTimeout = 100 * time.Millisecond
+	// maxAccountLookups bounds the number of accounts looked up at once, so that a flood of unknown account IDs
+	// can't overload the account fetcher
+	maxAccountLookups = 10
+)
+
+// RateLimitedEndpoint describes how to rate limit the requests to an endpoint
+type RateLimitedEndpoint struct {
+	name  string
+	limit func(config.EndpointRateLimits) config.RateLimit
+	// readRequest returns the account ID of the request, or "" if it's unknown, along with the metric labels
+	// used to record the rejected requests. It must not read more than maxSize bytes of the body.
+	readRequest func(r *http.Request, maxSize int64) (string, metrics.Labels)
+}
+
+var (
+	RateLimitedAuction = RateLimitedEndpoint{
+		name:        "auction",
+		limit:       func(l config.EndpointRateLimits) config.RateLimit { return l.Auction },
+		readRequest: readOpenRTBRequest,
+	}
+	RateLimitedAMP = RateLimitedEndpoint{
+		name:        "amp",
+		limit:       func(l config.EndpointRateLimits) config.RateLimit { return l.AMP },
+		readRequest: readAMPRequest,
+	}
+	RateLimitedVideo = RateLimitedEndpoint{
+		name:        "video",
+		limit:       func(l config.EndpointRateLimits) config.RateLimit { return l.Video },
+		readRequest: readVideoRequest,
+	}
+	RateLimitedCookieSync = RateLimitedEndpoint{
+		name:        "cookie_sync",
+		limit:       func(l config.EndpointRateLimits) config.RateLimit { return l.CookieSync },
+		readRequest: readCookieSyncRequest,
+	}
+)
+
+// RateLimiter holds the token buckets of the endpoints, accounts and client IPs. The account buckets use the
+// rate limits of the account, which default to account_defaults.rate_limit.
+type RateLimiter struct {
+	cfg            *config.Configuration
+	accounts       stored_requests.AccountFetcher
+	ipValidator    iputil.IPValidator
+	metricsEngine  metrics.MetricsEngine
+	maxRequestSize int64
+	now            func() time.Time
+	// goLookup runs the lookups of the account rate limits in the background
+	goLookup func(lookup func())
+
+	mutex          sync.Mutex
+	buckets        map[string]*tokenBucket
+	accountLimits  map[string]accountRateLimit
+	accountLookups map[string]struct{}
+}
+
+type accountRateLimit struct {
+	limit   config.RateLimit
+	expires time.Time
+}
+
+// NewRateLimiter creates the rate limiter of the endpoints, or returns nil if the rate limiting is disabled.
+func NewRateLimiter(cfg *config.Configuration, accounts stored_requests.AccountFetcher, metricsEngine metrics.MetricsEngine) *RateLimiter {
+	if !cfg.RateLimiting.Enabled {
+		return nil
+	}
+	return &RateLimiter{
+		cfg:      cfg,
+		accounts: accounts,
+		ipValidator: iputil.PublicNetworkIPValidator{
+			IPv4PrivateNetworks: cfg.RequestValidation.IPv4PrivateNetworksParsed,
+			IPv6PrivateNetworks: cfg.RequestValidation.IPv6PrivateNetworksParsed,
+		},
+		metricsEngine:  metricsEngine,
+		maxRequestSize: cfg.MaxRequestSize,
+		now:            time.Now,
+		goLookup:       func(lookup func()) { go lookup() },
+		buckets:        make(map[string]*tokenBucket),
+		accountLimits:  make(map[string]accountRateLimit),
+		accountLookups: make(map[string]struct{}),
+	}
+}
+
+// RateLimited rejects the requests over the rate limits with a 429 response, before the handle reads them.
+// Only the account ID is read from the request, so that the rejected requests cost as little as possible.
+// A nil RateLimiter returns the handle as is.
+func RateLimited(f httprouter.Handle, limiter *RateLimiter, endpoint RateLimitedEndpoint) httprouter.Handle {
+	if limiter == nil {
+		return f
+	}
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		accountID, labels := endpoint.readRequest(r, limiter.maxRequestSize)
+
+		if retryAfter, limited := limiter.limit(r, endpoint, accountID); limited {
+			labels.RequestStatus = metrics.RequestStatusRateLimited
+			labels.PubID = metrics.PublisherUnknown
+			if accountID != "" {
+				labels.PubID = accountID
+			}
+			limiter.metricsEngine.RecordRequest(labels)
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte("Rate limit exceeded"))
+			return
+		}
+		f(w, r, params)
+	}
+}
+
+// limit takes a token from each bucket the request goes through, if they all have one. Otherwise it returns
+// how long the client should wait before retrying. The endpoint and IP buckets are checked first, so that the
+// requests they reject don't cause an account lookup.
+func (l *RateLimiter) limit(r *http.Request, endpoint RateLimitedEndpoint, accountID string) (time.Duration, bool) {
+	limits := make([]bucketLimit, 0, 3)
+	if limit := endpoint.limit(l.cfg.RateLimiting.Endpoints); limit.IsEnabled() {
+		limits = append(limits, bucketLimit{key: "endpoint:" + endpoint.name, limit: limit})
+	}
+	if limit := endpoint.limit(l.cfg.RateLimiting.IP); limit.IsEnabled() {
+		if ip, _ := httputil.FindIP(r, l.ipValidator); ip != nil {
+			limits = append(limits, bucketLimit{key: "ip:" + endpoint.name + ":" + ip.String(), limit: limit})
+		}
+	}
+	if len(limits) == 0 && accountID == "" {
+		return 0, false
+	}
+
+	l.mutex.Lock()
+	now := l.now()
+	buckets, retryAfter := l.checkBuckets(limits, now, nil)
+	if retryAfter > 0 {
+		l.mutex.Unlock()
+		return retryAfter, true
+	}
+
+	var lookupKey string
+	if accountID != "" {
+		key := "account:" + endpoint.name + ":" + accountID
+		limit, found := l.cachedAccountLimit(key, now)
+		if !found && l.startAccountLookup(key) {
+			lookupKey = key
+		}
+		if limit.IsEnabled() {
+			if buckets, retryAfter = l.checkBuckets([]bucketLimit{{key: key, limit: limit}}, now, buckets); retryAfter > 0 {
+				l.mutex.Unlock()
+				return retryAfter, true
+			}
+		}
+	}
+
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	l.mutex.Unlock()
+
+	if lookupKey != "" {
+		l.goLookup(func() { l.lookupAccountLimit(lookupKey, accountID, endpoint) })
+	}
+	return 0, false
+}
+
+type bucketLimit struct {
+	key   string
+	limit config.RateLimit
+}
+
+// checkBuckets appends the buckets of the limits to the given ones, and returns how long it takes until they
+// all have a token. The lock must be held.
+func (l *RateLimiter) checkBuckets(limits []bucketLimit, now time.Time, buckets []*tokenBucket) ([]*tokenBucket, time.Duration) {
+	var retryAfter time.Duration
+	for _, limit := range limits {
+		bucket := l.getBucket(limit.key, limit.limit, now)
+		if bucket == nil {
+			continue
+		}
+		bucket.refill(now)
+		if bucket.limit != limit.limit {
+			bucket.setLimit(limit.limit)
+		}
+		if wait := bucket.wait(); wait > retryAfter {
+			retryAfter = wait
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, retryAfter
+}
+
+// cachedAccountLimit returns the cached rate limit of the account for the endpoint. found is false if it isn't
+// cached, or if it expired, in which case it must be looked up again. The accounts which aren't cached yet
+// aren't limited. The lock must be held.
+func (l *RateLimiter) cachedAccountLimit(key string, now time.Time) (limit config.RateLimit, found bool) {
+	cached, ok := l.accountLimits[key]
+	return cached.limit, ok && now.Before(cached.expires)
+}
+
+// startAccountLookup marks the account as being looked up. It returns false if it already is, or if there are
+// too many lookups running. The lock must be held.
+func (l *RateLimiter) startAccountLookup(key string) bool {
+	if _, ok := l.accountLookups[key]; ok || len(l.accountLookups) >= maxAccountLookups {
+		return false
+	}
+	l.accountLookups[key] = struct{}{}
+	return true
+}
+
+// lookupAccountLimit caches the rate limit of the account for the endpoint, for accountLimitTTL. The accounts
+// which fail the lookup, e.g. the unknown or disabled ones, are cached with the host defaults, so that they
+// aren't looked up again until then.
+func (l *RateLimiter) lookupAccountLimit(key string, accountID string, endpoint RateLimitedEndpoint) {
+	limit := endpoint.limit(l.cfg.AccountDefaults.RateLimit)
+	ctx, cancel := context.WithTimeout(context.Background(), accountLookupTimeout)
+	defer cancel()
+	if account, errs := accountService.GetAccount(ctx, l.cfg, l.accounts, accountID); len(errs) == 0 {
+		if accountLimit := endpoint.limit(account.RateLimit); accountLimit.IsValid() {
+			limit = accountLimit
+		} else {
+			glog.Warningf("Invalid %s rate limit of account %s, using the default one: %+v", endpoint.name, accountID, accountLimit)
+		}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.accountLookups, key)
+	now := l.now()
+	if _, ok := l.accountLimits[key]; !ok && len(l.accountLimits) >= l.cfg.RateLimiting.MaxKeys {
+		for cachedKey, cached := range l.accountLimits {
+			if !now.Before(cached.expires) {
+				delete(l.accountLimits, cachedKey)
+			}
+		}
+	}
+	if _, ok := l.accountLimits[key]; ok || len(l.accountLimits) < l.cfg.RateLimiting.MaxKeys {
+		l.accountLimits[key] = accountRateLimit{limit: limit, expires: now.Add(accountLimitTTL)}
+	}
+}
+
+// getBucket returns the bucket of the key, and creates it if needed. It returns nil if there's no room for
+// a new bucket, even after dropping the idle ones.
+func (l *RateLimiter) getBucket(key string, limit config.RateLimit, now time.Time) *tokenBucket {
+	if bucket, ok := l.buckets[key]; ok {
+		return bucket
+	}
+	if len(l.buckets) >= l.cfg.RateLimiting.MaxKeys {
+		l.dropIdleBuckets(now)
+		if len(l.buckets) >= l.cfg.RateLimiting.MaxKeys {
+			return nil
+		}
+	}
+	bucket := newTokenBucket(limit, now)
+	l.buckets[key] = bucket
+	return bucket
+}
+
+// dropIdleBuckets removes the full buckets, which behave like new ones.
+func (l *RateLimiter) dropIdleBuckets(now time.Time) {
+	for key, bucket := range l.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= float64(bucket.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+type tokenBucket struct {
+	limit   config.RateLimit
+	tokens  float64
+	updated time.Time
+}
+
+func newTokenBucket(limit config.RateLimit, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		limit:   limit,
+		tokens:  float64(limit.Burst),
+		updated: now,
+	}
+}
+
+func (b *tokenBucket) setLimit(limit config.RateLimit) {
+	b.limit = limit
+	b.tokens = math.Min(b.tokens, float64(limit.Burst))
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(b.tokens+elapsed.Seconds()*b.limit.RequestsPerSecond, float64(b.limit.Burst))
+		b.updated = now
+	}
+}
+
+// wait returns how long it takes until the bucket has a token.
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.RequestsPerSecond * float64(time.Second))
+}
+
+// readBody reads up to maxSize bytes of the body, and puts them back in front of the rest of it, so that the
+// handle can still read the whole body.
+func readBody(r *http.Request, maxSize int64) []byte {
+	if r.Body == nil {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(r.Body, maxSize))
+	r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+	return body
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func readOpenRTBRequest(r *http.Request, maxSize int64) (string, metrics.Labels) {
+	body := readBody(r, maxSize)
+	labels := metrics.Labels{
+		Source:     metrics.DemandWeb,
+		RType:      metrics.ReqTypeORTB2Web,
+		CookieFlag: metrics.CookieFlagUnknown,
+	}
+	if _, _, _, err := jsonparser.Get(body, "app"); err == nil {
+		labels.Source = metrics.DemandApp
+		labels.RType = metrics.ReqTypeORTB2App
+	}
+	return readPublisherAccountID(body), labels
+}
+
+func readVideoRequest(r *http.Request, maxSize int64) (string, metrics.Labels) {
+	labels := metrics.Labels{
+		Source:     metrics.DemandUnknown,
+		RType:      metrics.ReqTypeVideo,
+		CookieFlag: metrics.CookieFlagUnknown,
+	}
+	return readPublisherAccountID(readBody(r, maxSize)), labels
+}
+
+// readPublisherAccountID reads the account ID of an OpenRTB request the same way the endpoints do, from the
+// publisher of the site or the app.
+func readPublisherAccountID(body []byte) string {
+	for _, distributionChannel := range []string{"site", "app"} {
+		if parentAccount, err := jsonparser.GetString(body, distributionChannel, "publisher", "ext", "prebid", "parentAccount"); err == nil && parentAccount != "" {
+			return parentAccount
+		}
+		if publisherID, err := jsonparser.GetString(body, distributionChannel, "publisher", "id"); err == nil && publisherID != "" {
+			return publisherID
+		}
+	}
+	return ""
+}
+
+// readAMPRequest only finds the account of the requests with an account query param, as the other ones get it
+// from their stored request.
+func readAMPRequest(r *http.Request, _ int64) (string, metrics.Labels) {
+	labels := metrics.Labels{
+		Source:     metrics.DemandWeb,
+		RType:      metrics.ReqTypeAMP,
+		CookieFlag: metrics.CookieFlagUnknown,
+	}
+	return r.URL.Query().Get("account"), labels
+}
+
+func readCookieSyncRequest(r *http.Request, maxSize int64) (string, metrics.Labels) {
+	labels := metrics.Labels{
+		Source:     metrics.DemandWeb,
+		RType:      metrics.ReqTypeCookieSync,
+		CookieFlag: metrics.CookieFlagUnknown,
+	}
+	accountID, _ := jsonparser.GetString(readBody(r, maxSize), "account")
+	return accountID, labels
+}
@@ -0,0 +1,354 @@
+package aspects
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	metricsConf "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAccountFetcher struct {
+	accounts map[string]json.RawMessage
+	calls    int
+}
+
+func (af *mockAccountFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	af.calls++
+	if account, ok := af.accounts[accountID]; ok {
+		return account, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestRateLimiter(rateLimiting config.RateLimiting, accountDefaults config.EndpointRateLimits, fetcher *mockAccountFetcher, metricsEngine metrics.MetricsEngine) (*RateLimiter, *testClock) {
+	rateLimiting.Enabled = true
+	if rateLimiting.MaxKeys == 0 {
+		rateLimiting.MaxKeys = 100
+	}
+	cfg := &config.Configuration{
+		MaxRequestSize:  1024,
+		RateLimiting:    rateLimiting,
+		AccountDefaults: config.Account{RateLimit: accountDefaults},
+	}
+	cfg.MarshalAccountDefaults()
+	if fetcher == nil {
+		fetcher = &mockAccountFetcher{}
+	}
+	clock := &testClock{now: time.Unix(1000, 0)}
+	limiter := NewRateLimiter(cfg, fetcher, metricsEngine)
+	limiter.now = clock.Now
+	limiter.goLookup = func(lookup func()) { lookup() }
+	return limiter, clock
+}
+
+// sendAuctionRequest sends an auction request of the account, and returns the response along with the body
+// read by the handle
+func sendAuctionRequest(handle httprouter.Handle, accountID string, ip string) (*httptest.ResponseRecorder, string) {
+	body := `{"id":"req","site":{"publisher":{"id":"` + accountID + `"}}}`
+	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(body))
+	if ip != "" {
+		request.Header.Set("X-Forwarded-For", ip)
+	}
+	recorder := httptest.NewRecorder()
+	var readBody string
+	handle(recorder, request, nil)
+	if recorder.Code == http.StatusOK {
+		readBody = recorder.Body.String()
+	}
+	return recorder, readBody
+}
+
+func echoHandle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Write(body)
+}
+
+func TestRateLimitedDisabled(t *testing.T) {
+	limiter := NewRateLimiter(&config.Configuration{}, &mockAccountFetcher{}, &metricsConf.DummyMetricsEngine{})
+	assert.Nil(t, limiter)
+
+	recorder, _ := sendAuctionRequest(RateLimited(echoHandle, limiter, RateLimitedAuction), "acct", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestRateLimitedEndpoint(t *testing.T) {
+	limiter, clock := newTestRateLimiter(config.RateLimiting{
+		Endpoints: config.EndpointRateLimits{Auction: config.RateLimit{RequestsPerSecond: 0.5, Burst: 2}},
+	}, config.EndpointRateLimits{}, nil, &metricsConf.DummyMetricsEngine{})
+	handle := RateLimited(echoHandle, limiter, RateLimitedAuction)
+
+	for i := 0; i < 2; i++ {
+		recorder, body := sendAuctionRequest(handle, "acct", "")
+		assert.Equal(t, http.StatusOK, recorder.Code, "the requests of the burst should get through")
+		assert.JSONEq(t, `{"id":"req","site":{"publisher":{"id":"acct"}}}`, body, "the handle should read the whole body")
+	}
+
+	recorder, _ := sendAuctionRequest(handle, "other", "")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code, "the endpoint limit should apply to all the accounts")
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+	assert.Equal(t, "Rate limit exceeded", recorder.Body.String())
+
+	clock.now = clock.now.Add(1500 * time.Millisecond)
+	recorder, _ = sendAuctionRequest(handle, "acct", "")
+	assert.Equal(t, "1", recorder.Header().Get("Retry-After"), "the retry delay should be rounded up")
+
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	recorder, _ = sendAuctionRequest(handle, "acct", "")
+	assert.Equal(t, http.StatusOK, recorder.Code, "the bucket should be refilled over time")
+
+	ampRecorder := httptest.NewRecorder()
+	RateLimited(echoHandle, limiter, RateLimitedAMP)(ampRecorder, httptest.NewRequest("GET", "/openrtb2/amp?tag_id=1", nil), nil)
+	assert.Equal(t, http.StatusOK, ampRecorder.Code, "the other endpoints should have their own limits")
+}
+
+func TestRateLimitedAccount(t *testing.T) {
+	fetcher := &mockAccountFetcher{accounts: map[string]json.RawMessage{
+		"premium":  json.RawMessage(`{"rate_limit":{"auction":{"requests_per_second":1,"burst":3}}}`),
+		"disabled": json.RawMessage(`{"disabled":true}`),
+		"noburst":  json.RawMessage(`{"rate_limit":{"auction":{"requests_per_second":5,"burst":0}}}`),
+		"negative": json.RawMessage(`{"rate_limit":{"auction":{"requests_per_second":-1}}}`),
+	}}
+	limiter, clock := newTestRateLimiter(config.RateLimiting{}, config.EndpointRateLimits{
+		Auction: config.RateLimit{RequestsPerSecond: 1, Burst: 1},
+	}, fetcher, &metricsConf.DummyMetricsEngine{})
+	handle := RateLimited(echoHandle, limiter, RateLimitedAuction)
+
+	// The first request of each account gets through before its limit is cached
+	testCases := []struct {
+		description      string
+		accountID        string
+		expectedAccepted int
+	}{
+		{
+			description:      "account with its own limit",
+			accountID:        "premium",
+			expectedAccepted: 4,
+		},
+		{
+			description:      "account without its own limit",
+			accountID:        "standard",
+			expectedAccepted: 2,
+		},
+		{
+			description:      "account failing the lookup",
+			accountID:        "disabled",
+			expectedAccepted: 2,
+		},
+		{
+			description:      "account with a limit without burst",
+			accountID:        "noburst",
+			expectedAccepted: 2,
+		},
+		{
+			description:      "account with a negative limit",
+			accountID:        "negative",
+			expectedAccepted: 2,
+		},
+		{
+			description:      "unknown account",
+			accountID:        "",
+			expectedAccepted: 5,
+		},
+	}
+
+	for _, test := range testCases {
+		accepted := 0
+		for i := 0; i < 5; i++ {
+			if recorder, _ := sendAuctionRequest(handle, test.accountID, ""); recorder.Code == http.StatusOK {
+				accepted++
+			}
+		}
+		assert.Equal(t, test.expectedAccepted, accepted, test.description)
+	}
+	assert.Equal(t, 5, fetcher.calls, "the account limits should be cached")
+
+	clock.now = clock.now.Add(accountLimitTTL)
+	recorder, _ := sendAuctionRequest(handle, "premium", "")
+	assert.Equal(t, http.StatusOK, recorder.Code, "the expired limit should apply until it's looked up again")
+	assert.Equal(t, 6, fetcher.calls, "the account limits should be looked up again once expired")
+}
+
+func TestRateLimitedAccountLookup(t *testing.T) {
+	fetcher := &mockAccountFetcher{}
+	limiter, _ := newTestRateLimiter(config.RateLimiting{
+		Endpoints: config.EndpointRateLimits{Auction: config.RateLimit{RequestsPerSecond: 1, Burst: maxAccountLookups + 1}},
+	}, config.EndpointRateLimits{
+		Auction: config.RateLimit{RequestsPerSecond: 1, Burst: 1},
+	}, fetcher, &metricsConf.DummyMetricsEngine{})
+	var lookups []func()
+	limiter.goLookup = func(lookup func()) { lookups = append(lookups, lookup) }
+	handle := RateLimited(echoHandle, limiter, RateLimitedAuction)
+
+	for i := 0; i < maxAccountLookups+1; i++ {
+		recorder, _ := sendAuctionRequest(handle, "acct"+strconv.Itoa(i), "")
+		assert.Equal(t, http.StatusOK, recorder.Code, "the requests should not wait for the account lookups")
+	}
+	assert.Len(t, lookups, maxAccountLookups, "the number of lookups running at once should be bounded")
+
+	recorder, _ := sendAuctionRequest(handle, "other", "")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Len(t, lookups, maxAccountLookups, "the requests rejected by the endpoint limit should not look up the account")
+
+	for _, lookup := range lookups {
+		lookup()
+	}
+	assert.Equal(t, maxAccountLookups, fetcher.calls)
+	assert.Empty(t, limiter.accountLookups)
+	assert.Len(t, limiter.accountLimits, maxAccountLookups, "the unknown accounts should be cached with the defaults")
+}
+
+func TestRateLimitedIP(t *testing.T) {
+	limiter, _ := newTestRateLimiter(config.RateLimiting{
+		IP: config.EndpointRateLimits{Auction: config.RateLimit{RequestsPerSecond: 1, Burst: 1}},
+	}, config.EndpointRateLimits{}, nil, &metricsConf.DummyMetricsEngine{})
+	handle := RateLimited(echoHandle, limiter, RateLimitedAuction)
+
+	recorder, _ := sendAuctionRequest(handle, "acct", "1.1.1.1")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	recorder, _ = sendAuctionRequest(handle, "acct", "1.1.1.1")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	recorder, _ = sendAuctionRequest(handle, "acct", "2.2.2.2")
+	assert.Equal(t, http.StatusOK, recorder.Code, "each IP should have its own bucket")
+}
+
+func TestRateLimitedNoTokenTaken(t *testing.T) {
+	limiter, _ := newTestRateLimiter(config.RateLimiting{
+		Endpoints: config.EndpointRateLimits{Auction: config.RateLimit{RequestsPerSecond: 1, Burst: 2}},
+		IP:        config.EndpointRateLimits{Auction: config.RateLimit{RequestsPerSecond: 1, Burst: 1}},
+	}, config.EndpointRateLimits{}, nil, &metricsConf.DummyMetricsEngine{})
+	handle := RateLimited(echoHandle, limiter, RateLimitedAuction)
+
+	sendAuctionRequest(handle, "acct", "1.1.1.1")
+	recorder, _ := sendAuctionRequest(handle, "acct", "1.1.1.1")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	recorder, _ = sendAuctionRequest(handle, "acct", "2.2.2.2")
+	assert.Equal(t, http.StatusOK, recorder.Code, "the rejected requests should not take a token from the endpoint")
+}
+
+func TestRateLimitedMaxKeys(t *testing.T) {
+	limiter, clock := newTestRateLimiter(config.RateLimiting{
+		IP:      config.EndpointRateLimits{Auction: config.RateLimit{RequestsPerSecond: 1, Burst: 1}},
+		MaxKeys: 1,
+	}, config.EndpointRateLimits{}, nil, &metricsConf.DummyMetricsEngine{})
+	handle := RateLimited(echoHandle, limiter, RateLimitedAuction)
+
+	sendAuctionRequest(handle, "acct", "1.1.1.1")
+	sendAuctionRequest(handle, "acct", "2.2.2.2")
+	recorder, _ := sendAuctionRequest(handle, "acct", "2.2.2.2")
+	assert.Equal(t, http.StatusOK, recorder.Code, "the IPs which don't fit should not be limited")
+	assert.Len(t, limiter.buckets, 1)
+
+	clock.now = clock.now.Add(time.Second)
+	sendAuctionRequest(handle, "acct", "2.2.2.2")
+	recorder, _ = sendAuctionRequest(handle, "acct", "2.2.2.2")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code, "the idle buckets should make room for the new ones")
+	assert.Len(t, limiter.buckets, 1)
+}
+
+func TestRateLimitedMetrics(t *testing.T) {
+	testCases := []struct {
+		description    string
+		endpoint       RateLimitedEndpoint
+		request        *http.Request
+		expectedLabels metrics.Labels
+	}{
+		{
+			description: "auction",
+			endpoint:    RateLimitedAuction,
+			request:     httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(`{"site":{"publisher":{"id":"acct"}}}`)),
+			expectedLabels: metrics.Labels{
+				Source:        metrics.DemandWeb,
+				RType:         metrics.ReqTypeORTB2Web,
+				PubID:         "acct",
+				CookieFlag:    metrics.CookieFlagUnknown,
+				RequestStatus: metrics.RequestStatusRateLimited,
+			},
+		},
+		{
+			description: "app auction of a parent account",
+			endpoint:    RateLimitedAuction,
+			request:     httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(`{"app":{"publisher":{"id":"acct","ext":{"prebid":{"parentAccount":"parent"}}}}}`)),
+			expectedLabels: metrics.Labels{
+				Source:        metrics.DemandApp,
+				RType:         metrics.ReqTypeORTB2App,
+				PubID:         "parent",
+				CookieFlag:    metrics.CookieFlagUnknown,
+				RequestStatus: metrics.RequestStatusRateLimited,
+			},
+		},
+		{
+			description: "amp",
+			endpoint:    RateLimitedAMP,
+			request:     httptest.NewRequest("GET", "/openrtb2/amp?tag_id=1&account=acct", nil),
+			expectedLabels: metrics.Labels{
+				Source:        metrics.DemandWeb,
+				RType:         metrics.ReqTypeAMP,
+				PubID:         "acct",
+				CookieFlag:    metrics.CookieFlagUnknown,
+				RequestStatus: metrics.RequestStatusRateLimited,
+			},
+		},
+		{
+			description: "video without account",
+			endpoint:    RateLimitedVideo,
+			request:     httptest.NewRequest("POST", "/openrtb2/video", strings.NewReader(`{}`)),
+			expectedLabels: metrics.Labels{
+				Source:        metrics.DemandUnknown,
+				RType:         metrics.ReqTypeVideo,
+				PubID:         metrics.PublisherUnknown,
+				CookieFlag:    metrics.CookieFlagUnknown,
+				RequestStatus: metrics.RequestStatusRateLimited,
+			},
+		},
+		{
+			description: "cookie sync",
+			endpoint:    RateLimitedCookieSync,
+			request:     httptest.NewRequest("POST", "/cookie_sync", strings.NewReader(`{"account":"acct"}`)),
+			expectedLabels: metrics.Labels{
+				Source:        metrics.DemandWeb,
+				RType:         metrics.ReqTypeCookieSync,
+				PubID:         "acct",
+				CookieFlag:    metrics.CookieFlagUnknown,
+				RequestStatus: metrics.RequestStatusRateLimited,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordRequest", mock.Anything).Return()
+		limited := config.RateLimit{RequestsPerSecond: 1, Burst: 1}
+		limiter, _ := newTestRateLimiter(config.RateLimiting{
+			Endpoints: config.EndpointRateLimits{Auction: limited, AMP: limited, Video: limited, CookieSync: limited},
+		}, config.EndpointRateLimits{}, nil, metricsMock)
+		limiter.limit(test.request, test.endpoint, "")
+
+		recorder := httptest.NewRecorder()
+		RateLimited(echoHandle, limiter, test.endpoint)(recorder, test.request, nil)
+
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code, test.description)
+		metricsMock.AssertCalled(t, "RecordRequest", test.expectedLabels)
+		metricsMock.AssertNumberOfCalls(t, "RecordRequest", 1)
+	}
+}
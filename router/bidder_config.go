@@ -0,0 +1,355 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/endpoints"
+	"github.com/prebid/prebid-server/endpoints/events"
+	infoEndpoints "github.com/prebid/prebid-server/endpoints/info"
+	"github.com/prebid/prebid-server/endpoints/openrtb2"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	pbc "github.com/prebid/prebid-server/prebid_cache_client"
+	"github.com/prebid/prebid-server/router/aspects"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/usersync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/golang/glog"
+	"github.com/julienschmidt/httprouter"
+)
+
+// bidderConfigDeps holds the dependencies of the bidder configuration endpoints which are built once at startup,
+// and are shared by every version of the bidder configuration.
+type bidderConfigDeps struct {
+	httpClient        *http.Client
+	metricsEngine     metrics.MetricsEngine
+	rateConvertor     *currency.RateConverter
+	paramsValidator   openrtb_ext.BidderParamValidator
+	fetcher           stored_requests.Fetcher
+	ampFetcher        stored_requests.Fetcher
+	videoFetcher      stored_requests.Fetcher
+	storedRespFetcher stored_requests.Fetcher
+	accounts          stored_requests.AccountFetcher
+	categoriesFetcher stored_requests.CategoryFetcher
+	cacheClient       pbc.Client
+	pbsAnalytics      analytics.PBSAnalyticsModule
+	planBuilder       hooks.ExecutionPlanBuilder
+	defaultAliases    map[string]string
+	defReqJSON        []byte
+	circuitBreakers   *exchange.CircuitBreakers
+	priceFloorFetcher floors.FloorFetcher
+	// vendorListFetcher is built by the first configuration which enables GDPR
+	vendorListFetcher gdpr.VendorListFetcher
+	// uidStore is nil when the UIDs are kept in the uids cookie
+	uidStore usersync.UIDStore
+}
+
+// bidderConfigHandlers holds the endpoints which depend on the adapters, the user syncers or the bidder infos.
+// They're built together from a single version of the configuration, so that they're always consistent.
+type bidderConfigHandlers struct {
+	auction           httprouter.Handle
+	openrtb2Auction   httprouter.Handle
+	openrtb2Video     httprouter.Handle
+	openrtb2Amp       httprouter.Handle
+	infoBidders       httprouter.Handle
+	infoBiddersDetail httprouter.Handle
+	cookieSync        httprouter.Handle
+	setUID            httprouter.Handle
+	// vtrack is nil when the endpoint is disabled
+	vtrack httprouter.Handle
+}
+
+func buildBidderConfigHandlers(cfg *config.Configuration, bidderInfos config.BidderInfos, syncersByBidder map[string]usersync.Syncer, deps *bidderConfigDeps) (*bidderConfigHandlers, error) {
+	activeBidders := exchange.GetActiveBidders(bidderInfos)
+	disabledBidders := exchange.GetDisabledBiddersErrorMessages(bidderInfos)
+
+	gvlVendorIDs := bidderInfos.ToGVLVendorIDMap()
+	if cfg.GDPR.Enabled && deps.vendorListFetcher == nil {
+		deps.vendorListFetcher = gdpr.NewVendorListFetcher(context.Background(), cfg.GDPR, deps.httpClient)
+	}
+	gdprPerms := gdpr.NewPermissionsWithVendorListFetcher(cfg.GDPR, gvlVendorIDs, deps.vendorListFetcher)
+
+	adapters, adaptersErrs := exchange.BuildAdapters(deps.httpClient, cfg, bidderInfos, deps.metricsEngine, deps.circuitBreakers)
+	if len(adaptersErrs) > 0 {
+		return nil, errortypes.NewAggregateError("Failed to initialize adapters", adaptersErrs)
+	}
+
+	theExchange := exchange.NewExchange(adapters, deps.cacheClient, cfg, syncersByBidder, deps.metricsEngine, bidderInfos, gdprPerms, deps.rateConvertor, deps.categoriesFetcher, deps.priceFloorFetcher)
+
+	openrtbEndpoint, err := openrtb2.NewEndpoint(theExchange, deps.paramsValidator, deps.fetcher, deps.accounts, cfg, deps.metricsEngine, deps.pbsAnalytics, disabledBidders, deps.defReqJSON, activeBidders, deps.storedRespFetcher, deps.planBuilder, deps.uidStore)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the openrtb2 endpoint handler. %v", err)
+	}
+
+	ampEndpoint, err := openrtb2.NewAmpEndpoint(theExchange, deps.paramsValidator, deps.ampFetcher, deps.accounts, cfg, deps.metricsEngine, deps.pbsAnalytics, disabledBidders, deps.defReqJSON, activeBidders, deps.storedRespFetcher, deps.planBuilder, deps.uidStore)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the amp endpoint handler. %v", err)
+	}
+
+	videoEndpoint, err := openrtb2.NewVideoEndpoint(theExchange, deps.paramsValidator, deps.fetcher, deps.videoFetcher, deps.accounts, cfg, deps.metricsEngine, deps.pbsAnalytics, disabledBidders, deps.defReqJSON, activeBidders, deps.cacheClient, deps.storedRespFetcher, deps.planBuilder, deps.uidStore)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the video endpoint handler. %v", err)
+	}
+
+	requestTimeoutHeaders := config.RequestTimeoutHeaders{}
+	if cfg.RequestTimeoutHeaders != requestTimeoutHeaders {
+		videoEndpoint = aspects.QueuedRequestTimeout(videoEndpoint, cfg.RequestTimeoutHeaders, deps.metricsEngine, metrics.ReqTypeVideo)
+	}
+
+	handlers := &bidderConfigHandlers{
+		auction:           endpoints.Auction(cfg, syncersByBidder, gdprPerms, deps.metricsEngine, dataCache, newExchangeMap(cfg)),
+		openrtb2Auction:   openrtbEndpoint,
+		openrtb2Video:     videoEndpoint,
+		openrtb2Amp:       ampEndpoint,
+		infoBidders:       infoEndpoints.NewBiddersEndpoint(bidderInfos, deps.defaultAliases),
+		infoBiddersDetail: infoEndpoints.NewBiddersDetailEndpoint(bidderInfos, cfg.Adapters, deps.defaultAliases),
+		cookieSync:        endpoints.NewCookieSyncEndpoint(syncersByBidder, cfg, gdprPerms, deps.metricsEngine, deps.pbsAnalytics, deps.accounts, activeBidders, deps.uidStore).Handle,
+		setUID:            endpoints.NewSetUIDEndpoint(cfg.HostCookie, syncersByBidder, cfg.UserSync.UIDPriorityGroups, gdprPerms, deps.pbsAnalytics, deps.metricsEngine, deps.uidStore),
+	}
+	if cfg.VTrack.Enabled {
+		handlers.vtrack = events.NewVTrackEndpoint(cfg, deps.accounts, deps.cacheClient, bidderInfos)
+	}
+	return handlers, nil
+}
+
+// prepareBidderInfos applies the host configuration to the bidder infos read from the disk, and builds the syncers.
+func prepareBidderInfos(bidderInfos config.BidderInfos, cfg *config.Configuration) (map[string]usersync.Syncer, error) {
+	if err := applyBidderInfoConfigOverrides(bidderInfos, cfg.Adapters); err != nil {
+		return nil, err
+	}
+
+	if err := checkSupportedUserSyncEndpoints(bidderInfos); err != nil {
+		return nil, err
+	}
+
+	syncersByBidder, errs := usersync.BuildSyncers(cfg, bidderInfos)
+	if len(errs) > 0 {
+		return nil, errortypes.NewAggregateError("user sync", errs)
+	}
+	return syncersByBidder, nil
+}
+
+// builtInBidderNames returns the names of the bidders compiled into Prebid Server, without the aliases
+// registered from the bidder-info files.
+func builtInBidderNames() []string {
+	aliases := openrtb_ext.GetAliasBidderToParent()
+	names := make([]string, 0, len(openrtb_ext.CoreBidderNames()))
+	for _, name := range openrtb_ext.CoreBidderNames() {
+		if _, isAlias := aliases[name]; !isAlias {
+			names = append(names, string(name))
+		}
+	}
+	return names
+}
+
+// validateReloadedAliases makes sure the aliases defined by the reloaded bidder-info files are the ones registered
+// at startup. The bidder names are used to build the metrics and the request validation, so they can't change
+// without a restart.
+func validateReloadedAliases(bidderInfos config.BidderInfos) error {
+	registered := openrtb_ext.GetAliasBidderToParent()
+
+	var errs []error
+	reloaded := make(map[string]struct{})
+	for name, info := range bidderInfos {
+		if info.AliasOf == "" {
+			continue
+		}
+		reloaded[strings.ToLower(name)] = struct{}{}
+
+		parent, exists := registered[openrtb_ext.BidderName(name)]
+		if !exists {
+			errs = append(errs, fmt.Errorf("alias %s is new and requires a restart", name))
+		} else if !strings.EqualFold(string(parent), info.AliasOf) {
+			errs = append(errs, fmt.Errorf("alias %s changed its parent from %s to %s, which requires a restart", name, parent, info.AliasOf))
+		}
+	}
+	for alias := range registered {
+		if _, exists := reloaded[strings.ToLower(string(alias))]; !exists {
+			errs = append(errs, fmt.Errorf("alias %s was removed, which requires a restart", alias))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errortypes.NewAggregateError("bidder aliases", errs)
+	}
+	return nil
+}
+
+// bidderConfigReloader serves the bidder configuration endpoints, and swaps them all at once when
+// the configuration is reloaded. Requests in flight keep using the version they started with.
+type bidderConfigReloader struct {
+	infoDirectory string
+	deps          *bidderConfigDeps
+	loadConfig    func() (*config.Configuration, error)
+	// afterFunc schedules the debounced reloads
+	afterFunc func(d time.Duration, f func()) reloadTimer
+
+	// mutex serializes the reloads. It's never held when serving requests.
+	mutex    sync.Mutex
+	handlers atomic.Value
+}
+
+// reloadTimer is a pending reload, which is stopped when another change comes in before it runs.
+type reloadTimer interface {
+	Stop() bool
+}
+
+func newBidderConfigReloader(infoDirectory string, deps *bidderConfigDeps, handlers *bidderConfigHandlers) *bidderConfigReloader {
+	reloader := &bidderConfigReloader{
+		infoDirectory: infoDirectory,
+		deps:          deps,
+		afterFunc: func(d time.Duration, f func()) reloadTimer {
+			return time.AfterFunc(d, f)
+		},
+	}
+	reloader.handlers.Store(handlers)
+	return reloader
+}
+
+func (rl *bidderConfigReloader) current() *bidderConfigHandlers {
+	return rl.handlers.Load().(*bidderConfigHandlers)
+}
+
+// handle returns a Handle which delegates to the selected endpoint of the current configuration.
+func (rl *bidderConfigReloader) handle(selectHandler func(*bidderConfigHandlers) httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		handler := selectHandler(rl.current())
+		if handler == nil {
+			http.NotFound(w, r)
+			return
+		}
+		handler(w, r, ps)
+	}
+}
+
+// reload loads the configuration again and rebuilds the endpoints from it. The current endpoints are kept
+// if the new configuration is invalid.
+func (rl *bidderConfigReloader) reload() error {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	handlers, err := rl.build()
+	if err != nil {
+		glog.Errorf("Failed to reload the bidder configuration, the current one is kept: %v", err)
+		rl.deps.metricsEngine.RecordBidderConfigReload(false)
+		return err
+	}
+
+	rl.handlers.Store(handlers)
+	glog.Info("Reloaded the bidder configuration")
+	rl.deps.metricsEngine.RecordBidderConfigReload(true)
+	return nil
+}
+
+func (rl *bidderConfigReloader) build() (*bidderConfigHandlers, error) {
+	if rl.loadConfig == nil {
+		return nil, fmt.Errorf("the bidder configuration reload is not enabled")
+	}
+
+	cfg, err := rl.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	bidderInfos, err := config.LoadBidderInfoFromDisk(rl.infoDirectory, cfg.Adapters, builtInBidderNames())
+	if err != nil {
+		return nil, err
+	}
+	if err := validateReloadedAliases(bidderInfos); err != nil {
+		return nil, err
+	}
+	applyAliasAdapterConfigs(bidderInfos, cfg.Adapters)
+
+	syncersByBidder, err := prepareBidderInfos(bidderInfos, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildBidderConfigHandlers(cfg, bidderInfos, syncersByBidder, rl.deps)
+}
+
+// handleReload reloads the configuration on demand. It responds with the validation errors if the
+// new configuration was rejected.
+func (rl *bidderConfigReloader) handleReload(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if err := rl.reload(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(fmt.Sprintf("Failed to reload the bidder configuration: %v\n", err)))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// watch reloads the configuration whenever a bidder-info file or the given config file change. A batch of
+// changes within the debounce delay triggers a single reload. The returned function stops watching.
+func (rl *bidderConfigReloader) watch(configFile string, debounce time.Duration) (func(), error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := watcher.Add(rl.infoDirectory); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	// Watch the directory rather than the file itself, so that the changes made by replacing the file are seen too
+	if configFile != "" {
+		if err := watcher.Add(filepath.Dir(configFile)); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		var timer reloadTimer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Dir(event.Name) != filepath.Clean(rl.infoDirectory) && filepath.Clean(event.Name) != filepath.Clean(configFile) {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = rl.afterFunc(debounce, func() { rl.reload() })
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				glog.Errorf("Error watching the bidder configuration files: %v", err)
+			case <-done:
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			watcher.Close()
+		})
+	}, nil
+}
@@ -0,0 +1,231 @@
+package router
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBidderConfigReload(t *testing.T) {
+	testCases := []struct {
+		description       string
+		infoDirectory     string
+		loadConfig        func() (*config.Configuration, error)
+		expectedSuccess   bool
+		expectedErrPrefix string
+	}{
+		{
+			description:     "valid configuration",
+			infoDirectory:   "../static/bidder-info",
+			loadConfig:      loadTestConfig,
+			expectedSuccess: true,
+		},
+		{
+			description:   "invalid configuration",
+			infoDirectory: "../static/bidder-info",
+			loadConfig: func() (*config.Configuration, error) {
+				return nil, errors.New("invalid config")
+			},
+			expectedErrPrefix: "invalid config",
+		},
+		{
+			description:       "missing bidder info directory",
+			infoDirectory:     "../static/missing",
+			loadConfig:        loadTestConfig,
+			expectedErrPrefix: "open ../static/missing",
+		},
+		{
+			description:       "reload not enabled",
+			infoDirectory:     "../static/bidder-info",
+			expectedErrPrefix: "the bidder configuration reload is not enabled",
+		},
+	}
+
+	for _, test := range testCases {
+		metricsEngine := &metrics.MetricsEngineMock{}
+		metricsEngine.On("RecordBidderConfigReload", test.expectedSuccess).Return()
+
+		initialHandlers := &bidderConfigHandlers{}
+		reloader := newBidderConfigReloader(test.infoDirectory, newTestBidderConfigDeps(metricsEngine), initialHandlers)
+		reloader.loadConfig = test.loadConfig
+
+		err := reloader.reload()
+
+		if test.expectedSuccess {
+			assert.NoError(t, err, test.description)
+			assert.NotSame(t, initialHandlers, reloader.current(), test.description)
+			assert.NotNil(t, reloader.current().openrtb2Auction, test.description)
+		} else {
+			if assert.Error(t, err, test.description) {
+				assert.Regexp(t, "^"+test.expectedErrPrefix, err.Error(), test.description)
+			}
+			assert.Same(t, initialHandlers, reloader.current(), test.description)
+		}
+		metricsEngine.AssertExpectations(t)
+	}
+}
+
+func TestBidderConfigReloadEndpoint(t *testing.T) {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordBidderConfigReload", mock.Anything).Return()
+
+	reloader := newBidderConfigReloader("../static/bidder-info", newTestBidderConfigDeps(metricsEngine), &bidderConfigHandlers{})
+
+	reloader.loadConfig = loadTestConfig
+	recorder := httptest.NewRecorder()
+	reloader.handleReload(recorder, httptest.NewRequest("POST", "/bidders/reload", nil), nil)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+	reloader.loadConfig = func() (*config.Configuration, error) {
+		return nil, errors.New("invalid config")
+	}
+	recorder = httptest.NewRecorder()
+	reloader.handleReload(recorder, httptest.NewRequest("POST", "/bidders/reload", nil), nil)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "Failed to reload the bidder configuration: invalid config\n", recorder.Body.String())
+}
+
+func TestBidderConfigHandle(t *testing.T) {
+	served := ""
+	reloader := newBidderConfigReloader("", nil, &bidderConfigHandlers{
+		auction: func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) { served = "first" },
+	})
+	handle := reloader.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.auction })
+
+	handle(httptest.NewRecorder(), httptest.NewRequest("POST", "/auction", nil), nil)
+	assert.Equal(t, "first", served)
+
+	reloader.handlers.Store(&bidderConfigHandlers{
+		auction: func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) { served = "second" },
+	})
+	handle(httptest.NewRecorder(), httptest.NewRequest("POST", "/auction", nil), nil)
+	assert.Equal(t, "second", served, "the endpoints should use the latest handlers")
+
+	recorder := httptest.NewRecorder()
+	reloader.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.vtrack })(recorder, httptest.NewRequest("POST", "/vtrack", nil), nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "disabled endpoints should not be found")
+}
+
+func TestBidderConfigWatch(t *testing.T) {
+	infoDirectory, err := ioutil.TempDir("", "bidder-info")
+	if err != nil {
+		t.Fatalf("Failed to create the bidder info directory: %v", err)
+	}
+	defer os.RemoveAll(infoDirectory)
+
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordBidderConfigReload", false).Return()
+
+	loads := 0
+	reloader := newBidderConfigReloader(infoDirectory, newTestBidderConfigDeps(metricsEngine), &bidderConfigHandlers{})
+	reloader.loadConfig = func() (*config.Configuration, error) {
+		loads++
+		return nil, errors.New("invalid config")
+	}
+	// The timers are handed over to the test, which runs the reloads itself instead of waiting for the debounce
+	timers := make(chan *fakeReloadTimer, 100)
+	reloader.afterFunc = func(d time.Duration, f func()) reloadTimer {
+		timer := &fakeReloadTimer{reload: f}
+		timers <- timer
+		return timer
+	}
+
+	stop, err := reloader.watch("", time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to watch the bidder info directory: %v", err)
+	}
+	defer stop()
+
+	var scheduled []*fakeReloadTimer
+	for _, name := range []string{"a.yaml", "b.yaml"} {
+		if err := ioutil.WriteFile(filepath.Join(infoDirectory, name), []byte("maintainer:"), 0644); err != nil {
+			t.Fatalf("Failed to write the bidder info file: %v", err)
+		}
+		select {
+		case timer := <-timers:
+			scheduled = append(scheduled, timer)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("No reload was scheduled after %s changed", name)
+		}
+	}
+	stop()
+
+	assert.True(t, scheduled[0].stopped, "the changes should be debounced into a single reload")
+	scheduled[1].reload()
+	assert.Equal(t, 1, loads, "the configuration should be reloaded once the debounce delay is over")
+}
+
+type fakeReloadTimer struct {
+	reload  func()
+	stopped bool
+}
+
+func (t *fakeReloadTimer) Stop() bool {
+	t.stopped = true
+	return true
+}
+
+func TestValidateReloadedAliases(t *testing.T) {
+	testCases := []struct {
+		description string
+		bidderInfos config.BidderInfos
+		expectedErr string
+	}{
+		{
+			description: "no aliases",
+			bidderInfos: config.BidderInfos{"appnexus": config.BidderInfo{}},
+		},
+		{
+			description: "new alias",
+			bidderInfos: config.BidderInfos{"appnexus": config.BidderInfo{}, "newAlias": config.BidderInfo{AliasOf: "appnexus"}},
+			expectedErr: "bidder aliases (1 error):\n  1: alias newAlias is new and requires a restart\n",
+		},
+	}
+
+	for _, test := range testCases {
+		err := validateReloadedAliases(test.bidderInfos)
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+	}
+}
+
+func loadTestConfig() (*config.Configuration, error) {
+	v := viper.New()
+	config.SetupViper(v, "")
+	v.Set("gdpr.enabled", false)
+	v.Set("gdpr.default_value", "0")
+	return config.New(v)
+}
+
+func newTestBidderConfigDeps(metricsEngine metrics.MetricsEngine) *bidderConfigDeps {
+	return &bidderConfigDeps{
+		httpClient:        &http.Client{},
+		metricsEngine:     metricsEngine,
+		paramsValidator:   &testValidator{},
+		fetcher:           empty_fetcher.EmptyFetcher{},
+		ampFetcher:        empty_fetcher.EmptyFetcher{},
+		videoFetcher:      empty_fetcher.EmptyFetcher{},
+		storedRespFetcher: empty_fetcher.EmptyFetcher{},
+		accounts:          empty_fetcher.EmptyFetcher{},
+		categoriesFetcher: empty_fetcher.EmptyFetcher{},
+		planBuilder:       hooks.EmptyPlanBuilder{},
+	}
+}
@@ -1,7 +1,6 @@
 package router
 
 import (
-	"context"
 	"crypto/tls"
 	"database/sql"
 	"encoding/json"
@@ -30,22 +29,23 @@ import (
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/endpoints"
 	"github.com/prebid/prebid-server/endpoints/events"
-	infoEndpoints "github.com/prebid/prebid-server/endpoints/info"
-	"github.com/prebid/prebid-server/endpoints/openrtb2"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
-	"github.com/prebid/prebid-server/gdpr"
-	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/hooks"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/modules"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/pbs"
 	pbc "github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/router/aspects"
 	"github.com/prebid/prebid-server/server/ssl"
 	storedRequestsConf "github.com/prebid/prebid-server/stored_requests/config"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/sliceutil"
 
+	_ "github.com/go-sql-driver/mysql"
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
 	_ "github.com/lib/pq"
@@ -84,6 +84,11 @@ func NewJsonDirectoryServer(schemaDirectory string, validator openrtb_ext.Bidder
 		data[bidder] = json.RawMessage(validator.Schema(bidderName))
 	}
 
+	// Add in the aliases configured by the host, which share the params of their parent
+	for aliasName := range openrtb_ext.GetAliasBidderToParent() {
+		data[string(aliasName)] = json.RawMessage(validator.Schema(aliasName))
+	}
+
 	// Add in any default aliases
 	for aliasName, bidderName := range aliases {
 		bidderData, ok := data[bidderName]
@@ -166,9 +171,36 @@ func newExchangeMap(cfg *config.Configuration) map[string]adapters.Adapter {
 
 type Router struct {
 	*httprouter.Router
+	// AdminRouter holds the endpoints which must only be served on the admin port
+	AdminRouter     *httprouter.Router
 	MetricsEngine   *metricsConf.DetailedMetricsEngine
 	ParamsValidator openrtb_ext.BidderParamValidator
 	Shutdown        func()
+
+	bidderConfig *bidderConfigReloader
+}
+
+// EnableBidderConfigReload lets the bidder configuration be reloaded without restarting the process. The
+// configuration is read again with loadConfig, from an admin endpoint and, if configured, whenever the
+// bidder-info files or the configFile change. The stored requests, metrics, analytics and modules settings
+// are only read at startup.
+func (r *Router) EnableBidderConfigReload(cfg config.BidderConfigReload, loadConfig func() (*config.Configuration, error), configFile string) error {
+	r.bidderConfig.loadConfig = loadConfig
+	r.AdminRouter.POST("/bidders/reload", r.bidderConfig.handleReload)
+
+	if !cfg.WatchFiles {
+		return nil
+	}
+	stopWatching, err := r.bidderConfig.watch(configFile, time.Duration(cfg.DebounceMs)*time.Millisecond)
+	if err != nil {
+		return fmt.Errorf("Failed to watch the bidder configuration files: %v", err)
+	}
+	shutdown := r.Shutdown
+	r.Shutdown = func() {
+		stopWatching()
+		shutdown()
+	}
+	return nil
 }
 
 func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *Router, err error) {
@@ -176,7 +208,8 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	const infoDirectory = "./static/bidder-info"
 
 	r = &Router{
-		Router: httprouter.New(),
+		Router:      httprouter.New(),
+		AdminRouter: httprouter.New(),
 	}
 
 	// For bid processing, we need both the hardcoded certificates and the certificates found in container's
@@ -207,27 +240,23 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		},
 	}
 
-	// Hack because of how legacy handles districtm
-	legacyBidderList := openrtb_ext.CoreBidderNames()
-	legacyBidderList = append(legacyBidderList, openrtb_ext.BidderName("districtm"))
-
 	p, _ := filepath.Abs(infoDirectory)
-	bidderInfos, err := config.LoadBidderInfoFromDisk(p, cfg.Adapters, openrtb_ext.BuildBidderStringSlice())
+	bidderInfos, err := config.LoadBidderInfoFromDisk(p, cfg.Adapters, builtInBidderNames())
 	if err != nil {
 		return nil, err
 	}
 
-	if err := applyBidderInfoConfigOverrides(bidderInfos, cfg.Adapters); err != nil {
+	if err := registerBidderAliases(bidderInfos, cfg.Adapters); err != nil {
 		return nil, err
 	}
 
-	if err := checkSupportedUserSyncEndpoints(bidderInfos); err != nil {
-		return nil, err
-	}
+	// Hack because of how legacy handles districtm
+	legacyBidderList := openrtb_ext.CoreBidderNames()
+	legacyBidderList = append(legacyBidderList, openrtb_ext.BidderName("districtm"))
 
-	syncersByBidder, errs := usersync.BuildSyncers(cfg, bidderInfos)
-	if len(errs) > 0 {
-		return nil, errortypes.NewAggregateError("user sync", errs)
+	syncersByBidder, err := prepareBidderInfos(bidderInfos, cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	syncerKeys := make([]string, 0, len(syncersByBidder))
@@ -241,9 +270,21 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 
 	// Metrics engine
 	r.MetricsEngine = metricsConf.NewMetricsEngine(cfg, legacyBidderList, syncerKeys)
-	db, shutdown, fetcher, ampFetcher, accounts, categoriesFetcher, videoFetcher := storedRequestsConf.NewStoredRequests(cfg, r.MetricsEngine, generalHttpClient, r.Router)
+	db, shutdown, fetcher, ampFetcher, accounts, categoriesFetcher, videoFetcher, storedRespFetcher := storedRequestsConf.NewStoredRequests(cfg, r.MetricsEngine, generalHttpClient, r.AdminRouter)
 	// todo(zachbadgett): better shutdown
 	r.Shutdown = shutdown
+
+	var tracer *tracing.Tracer
+	if cfg.Tracing.Enabled {
+		var err error
+		if tracer, err = tracing.NewTracer(cfg.Tracing); err != nil {
+			return nil, err
+		}
+		r.Shutdown = func() {
+			shutdown()
+			tracer.Shutdown()
+		}
+	}
 	if err := loadDataCache(cfg, db); err != nil {
 		return nil, fmt.Errorf("Prebid Server could not load data cache: %v", err)
 	}
@@ -255,64 +296,76 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		glog.Fatalf("Failed to create the bidder params validator. %v", err)
 	}
 
-	activeBidders := exchange.GetActiveBidders(bidderInfos)
-	disabledBidders := exchange.GetDisabledBiddersErrorMessages(bidderInfos)
-
 	defaultAliases, defReqJSON := readDefaultRequest(cfg.DefReqConfig)
 	if err := validateDefaultAliases(defaultAliases); err != nil {
 		return nil, err
 	}
 
-	gvlVendorIDs := bidderInfos.ToGVLVendorIDMap()
-	gdprPerms := gdpr.NewPermissions(context.Background(), cfg.GDPR, gvlVendorIDs, generalHttpClient)
-
-	exchanges = newExchangeMap(cfg)
 	cacheClient := pbc.NewClient(cacheHttpClient, &cfg.CacheURL, &cfg.ExtCacheURL, r.MetricsEngine)
 
-	adapters, adaptersErrs := exchange.BuildAdapters(generalHttpClient, cfg, bidderInfos, r.MetricsEngine)
-	if len(adaptersErrs) > 0 {
-		errs := errortypes.NewAggregateError("Failed to initialize adapters", adaptersErrs)
-		return nil, errs
-	}
-
-	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, syncersByBidder, r.MetricsEngine, bidderInfos, gdprPerms, rateConvertor, categoriesFetcher)
-
-	openrtbEndpoint, err := openrtb2.NewEndpoint(theExchange, paramsValidator, fetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders)
+	builtModules, err := modules.NewBuilder().Build(cfg.Hooks, modules.ModuleDeps{HTTPClient: generalHttpClient})
 	if err != nil {
-		glog.Fatalf("Failed to create the openrtb2 endpoint handler. %v", err)
+		return nil, fmt.Errorf("Failed to build modules: %v", err)
 	}
-
-	ampEndpoint, err := openrtb2.NewAmpEndpoint(theExchange, paramsValidator, ampFetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders)
+	hookRepository, err := hooks.NewHookRepository(builtModules)
 	if err != nil {
-		glog.Fatalf("Failed to create the amp endpoint handler. %v", err)
+		return nil, fmt.Errorf("Failed to load module hooks: %v", err)
 	}
-
-	videoEndpoint, err := openrtb2.NewVideoEndpoint(theExchange, paramsValidator, fetcher, videoFetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, cacheClient)
+	planBuilder, err := hooks.NewExecutionPlanBuilder(cfg.Hooks, hookRepository)
 	if err != nil {
-		glog.Fatalf("Failed to create the video endpoint handler. %v", err)
-	}
-
-	requestTimeoutHeaders := config.RequestTimeoutHeaders{}
-	if cfg.RequestTimeoutHeaders != requestTimeoutHeaders {
-		videoEndpoint = aspects.QueuedRequestTimeout(videoEndpoint, cfg.RequestTimeoutHeaders, r.MetricsEngine, metrics.ReqTypeVideo)
+		return nil, fmt.Errorf("Failed to create the hooks execution plan: %v", err)
+	}
+
+	bidderConfigDeps := &bidderConfigDeps{
+		httpClient:        generalHttpClient,
+		metricsEngine:     r.MetricsEngine,
+		rateConvertor:     rateConvertor,
+		paramsValidator:   paramsValidator,
+		fetcher:           fetcher,
+		ampFetcher:        ampFetcher,
+		videoFetcher:      videoFetcher,
+		storedRespFetcher: storedRespFetcher,
+		accounts:          accounts,
+		categoriesFetcher: categoriesFetcher,
+		cacheClient:       cacheClient,
+		pbsAnalytics:      pbsAnalytics,
+		planBuilder:       planBuilder,
+		defaultAliases:    defaultAliases,
+		defReqJSON:        defReqJSON,
+		priceFloorFetcher: floors.NewPriceFloorFetcher(generalHttpClient),
+		uidStore:          usersync.NewUIDStore(cfg.UserSync.UIDStore),
+	}
+	if cfg.CircuitBreaker.Enabled {
+		bidderConfigDeps.circuitBreakers = exchange.NewCircuitBreakers(cfg.CircuitBreaker, r.MetricsEngine)
+		r.AdminRouter.GET("/bidders/circuit_breakers", endpoints.NewCircuitBreakersEndpoint(bidderConfigDeps.circuitBreakers))
+	}
+	handlers, err := buildBidderConfigHandlers(cfg, bidderInfos, syncersByBidder, bidderConfigDeps)
+	if err != nil {
+		return nil, err
 	}
-
-	r.POST("/auction", endpoints.Auction(cfg, syncersByBidder, gdprPerms, r.MetricsEngine, dataCache, exchanges))
-	r.POST("/openrtb2/auction", openrtbEndpoint)
-	r.POST("/openrtb2/video", videoEndpoint)
-	r.GET("/openrtb2/amp", ampEndpoint)
-	r.GET("/info/bidders", infoEndpoints.NewBiddersEndpoint(bidderInfos, defaultAliases))
-	r.GET("/info/bidders/:bidderName", infoEndpoints.NewBiddersDetailEndpoint(bidderInfos, cfg.Adapters, defaultAliases))
+	exchanges = newExchangeMap(cfg)
+	r.bidderConfig = newBidderConfigReloader(p, bidderConfigDeps, handlers)
+
+	// The endpoints which depend on the bidder configuration are served through the reloader, so they
+	// always use its latest version
+	bidderConfig := r.bidderConfig
+	rateLimiter := aspects.NewRateLimiter(cfg, accounts, r.MetricsEngine)
+	r.POST("/auction", bidderConfig.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.auction }))
+	r.POST("/openrtb2/auction", tracer.Handle("/openrtb2/auction", aspects.RateLimited(bidderConfig.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.openrtb2Auction }), rateLimiter, aspects.RateLimitedAuction)))
+	r.POST("/openrtb2/video", tracer.Handle("/openrtb2/video", aspects.RateLimited(bidderConfig.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.openrtb2Video }), rateLimiter, aspects.RateLimitedVideo)))
+	r.GET("/openrtb2/amp", tracer.Handle("/openrtb2/amp", aspects.RateLimited(bidderConfig.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.openrtb2Amp }), rateLimiter, aspects.RateLimitedAMP)))
+	r.GET("/info/bidders", bidderConfig.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.infoBidders }))
+	r.GET("/info/bidders/:bidderName", bidderConfig.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.infoBiddersDetail }))
 	r.GET("/bidders/params", NewJsonDirectoryServer(schemaDirectory, paramsValidator, defaultAliases))
-	r.POST("/cookie_sync", endpoints.NewCookieSyncEndpoint(syncersByBidder, cfg, gdprPerms, r.MetricsEngine, pbsAnalytics, activeBidders).Handle)
+	r.POST("/cookie_sync", aspects.RateLimited(bidderConfig.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.cookieSync }), rateLimiter, aspects.RateLimitedCookieSync))
 	r.GET("/status", endpoints.NewStatusEndpoint(cfg.StatusResponse))
+	r.AdminRouter.GET("/storeddata/accounts/effective/:id", endpoints.NewAccountConfigEndpoint(cfg, accounts))
 	r.GET("/", serveIndex)
 	r.ServeFiles("/static/*filepath", http.Dir("static"))
 
 	// vtrack endpoint
 	if cfg.VTrack.Enabled {
-		vtrackEndpoint := events.NewVTrackEndpoint(cfg, accounts, cacheClient, bidderInfos)
-		r.POST("/vtrack", vtrackEndpoint)
+		r.POST("/vtrack", bidderConfig.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.vtrack }))
 	}
 
 	// event endpoint
@@ -321,14 +374,15 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 
 	userSyncDeps := &pbs.UserSyncDeps{
 		HostCookieConfig: &(cfg.HostCookie),
+		UIDStore:         bidderConfigDeps.uidStore,
 		ExternalUrl:      cfg.ExternalURL,
 		RecaptchaSecret:  cfg.RecaptchaSecret,
 		MetricsEngine:    r.MetricsEngine,
 		PBSAnalytics:     pbsAnalytics,
 	}
 
-	r.GET("/setuid", endpoints.NewSetUIDEndpoint(cfg.HostCookie, syncersByBidder, gdprPerms, pbsAnalytics, r.MetricsEngine))
-	r.GET("/getuids", endpoints.NewGetUIDsEndpoint(cfg.HostCookie))
+	r.GET("/setuid", bidderConfig.handle(func(h *bidderConfigHandlers) httprouter.Handle { return h.setUID }))
+	r.GET("/getuids", endpoints.NewGetUIDsEndpoint(cfg.HostCookie, bidderConfigDeps.uidStore))
 	r.POST("/optout", userSyncDeps.OptOut)
 	r.GET("/optout", userSyncDeps.OptOut)
 
@@ -391,6 +445,42 @@ func applyBidderInfoConfigOverrides(bidderInfos config.BidderInfos, adaptersCfg
 	return nil
 }
 
+// registerBidderAliases makes the aliases defined in the bidder-info files known as bidders, so they are validated,
+// built and reported like the core bidders. An alias without its own adapter configuration gets the one of its
+// parent, except for the user sync settings which are never shared.
+func registerBidderAliases(bidderInfos config.BidderInfos, adaptersCfg map[string]config.Adapter) error {
+	for aliasName, aliasInfo := range bidderInfos {
+		if aliasInfo.AliasOf == "" {
+			continue
+		}
+
+		if err := openrtb_ext.SetAliasBidderName(aliasName, openrtb_ext.BidderName(aliasInfo.AliasOf)); err != nil {
+			return err
+		}
+	}
+	applyAliasAdapterConfigs(bidderInfos, adaptersCfg)
+	return nil
+}
+
+// applyAliasAdapterConfigs gives the adapter configuration of their parent to the aliases which don't have their own.
+func applyAliasAdapterConfigs(bidderInfos config.BidderInfos, adaptersCfg map[string]config.Adapter) {
+	for aliasName, aliasInfo := range bidderInfos {
+		if aliasInfo.AliasOf == "" {
+			continue
+		}
+
+		aliasKey := strings.ToLower(aliasName)
+		if _, exists := adaptersCfg[aliasKey]; exists {
+			continue
+		}
+		if parentCfg, exists := adaptersCfg[strings.ToLower(aliasInfo.AliasOf)]; exists {
+			parentCfg.Syncer = nil
+			parentCfg.UserSyncURL = ""
+			adaptersCfg[aliasKey] = parentCfg
+		}
+	}
+}
+
 func checkSupportedUserSyncEndpoints(bidderInfos config.BidderInfos) error {
 	for name, info := range bidderInfos {
 		if info.Syncer == nil {
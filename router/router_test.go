@@ -1,6 +1,7 @@
 package router
 
 import (
+	"database/sql"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -392,3 +394,26 @@ func TestValidateDefaultAliases(t *testing.T) {
 		}
 	}
 }
+
+func TestMySqlDriverRegistered(t *testing.T) {
+	connection := config.MySqlConnection{
+		Database: "prebid",
+		Host:     "localhost",
+		Port:     3306,
+		Username: "user",
+		Password: "password",
+	}
+
+	db, err := sql.Open("mysql", connection.ConnString())
+	if !assert.NoError(t, err, "the mysql driver should be registered") {
+		return
+	}
+	defer db.Close()
+	assert.IsType(t, &mysql.MySQLDriver{}, db.Driver())
+
+	dsn, err := mysql.ParseDSN(connection.ConnString())
+	if assert.NoError(t, err, "the connection string should be a valid mysql DSN") {
+		assert.Equal(t, "localhost:3306", dsn.Addr)
+		assert.Equal(t, "prebid", dsn.DBName)
+	}
+}
@@ -11,23 +11,32 @@ import (
 	"github.com/prebid/prebid-server/stored_requests"
 )
 
-func NewFetcher(db *sql.DB, queryMaker func(int, int) string) stored_requests.AllFetcher {
+// NewFetcher builds a Fetcher which runs the queries built by the query makers. The accountQueryMaker is optional,
+// since not every database backend supports accounts. The accounts are not found without it.
+func NewFetcher(db *sql.DB, queryMaker func(int, int) string, responseQueryMaker func(int) string, accountQueryMaker func(int) string) stored_requests.AllFetcher {
 	if db == nil {
-		glog.Fatalf("The Postgres Stored Request Fetcher requires a database connection. Please report this as a bug.")
+		glog.Fatalf("The DB Stored Request Fetcher requires a database connection. Please report this as a bug.")
 	}
 	if queryMaker == nil {
-		glog.Fatalf("The Postgres Stored Request Fetcher requires a queryMaker function. Please report this as a bug.")
+		glog.Fatalf("The DB Stored Request Fetcher requires a queryMaker function. Please report this as a bug.")
+	}
+	if responseQueryMaker == nil {
+		glog.Fatalf("The DB Stored Request Fetcher requires a responseQueryMaker function. Please report this as a bug.")
 	}
 	return &dbFetcher{
-		db:         db,
-		queryMaker: queryMaker,
+		db:                 db,
+		queryMaker:         queryMaker,
+		responseQueryMaker: responseQueryMaker,
+		accountQueryMaker:  accountQueryMaker,
 	}
 }
 
 // dbFetcher fetches Stored Requests from a database. This should be instantiated through the NewFetcher() function.
 type dbFetcher struct {
-	db         *sql.DB
-	queryMaker func(numReqs int, numImps int) (query string)
+	db                 *sql.DB
+	queryMaker         func(numReqs int, numImps int) (query string)
+	responseQueryMaker func(numIds int) (query string)
+	accountQueryMaker  func(numIds int) (query string)
 }
 
 func (fetcher *dbFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
@@ -78,7 +87,7 @@ func (fetcher *dbFetcher) FetchRequests(ctx context.Context, requestIDs []string
 		case "imp":
 			storedImpData[id] = data
 		default:
-			glog.Errorf("Postgres result set with id=%s has invalid type: %s. This will be ignored.", id, dataType)
+			glog.Errorf("DB result set with id=%s has invalid type: %s. This will be ignored.", id, dataType)
 		}
 	}
 
@@ -93,8 +102,99 @@ func (fetcher *dbFetcher) FetchRequests(ctx context.Context, requestIDs []string
 	return storedRequestData, storedImpData, errs
 }
 
+func (fetcher *dbFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	if len(ids) < 1 {
+		return nil, nil
+	}
+
+	query := fetcher.responseQueryMaker(len(ids))
+	idInterfaces := make([]interface{}, len(ids))
+	for i := 0; i < len(ids); i++ {
+		idInterfaces[i] = ids[i]
+	}
+
+	rows, err := fetcher.db.QueryContext(ctx, query, idInterfaces...)
+	if err != nil {
+		if err != context.DeadlineExceeded && !isBadInput(err) {
+			glog.Errorf("Error reading from Stored Response DB: %s", err.Error())
+			return nil, appendErrors("Response", ids, nil, nil)
+		}
+		return nil, []error{err}
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			glog.Errorf("error closing DB connection: %v", err)
+		}
+	}()
+
+	storedResponseData := make(map[string]json.RawMessage, len(ids))
+	for rows.Next() {
+		var id string
+		var data []byte
+		var dataType string
+
+		if err := rows.Scan(&id, &data, &dataType); err != nil {
+			return nil, []error{err}
+		}
+
+		if dataType != "response" {
+			glog.Errorf("DB result set with id=%s has invalid type: %s. This will be ignored.", id, dataType)
+			continue
+		}
+		storedResponseData[id] = data
+	}
+
+	if rows.Err() != nil {
+		return nil, []error{rows.Err()}
+	}
+
+	return storedResponseData, appendErrors("Response", ids, storedResponseData, nil)
+}
+
 func (fetcher *dbFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
-	return nil, []error{stored_requests.NotFoundError{accountID, "Account"}}
+	if fetcher.accountQueryMaker == nil {
+		return nil, []error{stored_requests.NotFoundError{accountID, "Account"}}
+	}
+
+	rows, err := fetcher.db.QueryContext(ctx, fetcher.accountQueryMaker(1), accountID)
+	if err != nil {
+		if err != context.DeadlineExceeded && !isBadInput(err) {
+			glog.Errorf("Error reading from Account DB: %s", err.Error())
+			return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+		}
+		return nil, []error{err}
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			glog.Errorf("error closing DB connection: %v", err)
+		}
+	}()
+
+	var account json.RawMessage
+	for rows.Next() {
+		var id string
+		var data []byte
+		var dataType string
+
+		if err := rows.Scan(&id, &data, &dataType); err != nil {
+			return nil, []error{err}
+		}
+
+		if dataType != "account" {
+			glog.Errorf("DB result set with id=%s has invalid type: %s. This will be ignored.", id, dataType)
+			continue
+		}
+		account = data
+	}
+
+	if rows.Err() != nil {
+		return nil, []error{rows.Err()}
+	}
+
+	if account == nil {
+		return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+	}
+	return account, nil
 }
 
 func (fetcher *dbFetcher) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestEmptyQuery(t *testing.T) {
@@ -173,6 +175,83 @@ func TestRowErrors(t *testing.T) {
 	assertMapLength(t, 0, data)
 }
 
+func TestGoodResponseStoredResponses(t *testing.T) {
+	mockQuery := "SELECT id, data, 'response' AS dataType FROM responses_table WHERE id IN (?, ?)"
+	mockReturn := sqlmock.NewRows([]string{"id", "data", "dataType"}).
+		AddRow("response-id", `{"seatbid":[]}`, "response").
+		AddRow("request-id", `{"req":true}`, "request")
+
+	mock, fetcher := newFetcher(t, mockReturn, mockQuery, "response-id", "missing-id")
+	defer fetcher.db.Close()
+
+	storedResponses, errs := fetcher.FetchResponses(context.Background(), []string{"response-id", "missing-id"})
+
+	assertMockExpectations(t, mock)
+	assertErrorCount(t, 1, errs)
+	assertMapLength(t, 1, storedResponses)
+	assertHasData(t, storedResponses, "response-id", `{"seatbid":[]}`)
+}
+
+func TestEmptyQueryStoredResponses(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Unexpected error stubbing DB: %v", err)
+	}
+	defer db.Close()
+
+	fetcher := dbFetcher{
+		db:                 db,
+		responseQueryMaker: successfulResponseQueryMaker(""),
+	}
+	storedResponses, errs := fetcher.FetchResponses(context.Background(), nil)
+	assertErrorCount(t, 0, errs)
+	assertMapLength(t, 0, storedResponses)
+}
+
+func TestFetchAccount(t *testing.T) {
+	mockQuery := "SELECT id, config, 'account' AS dataType FROM accounts_table WHERE id IN (?)"
+	mockReturn := sqlmock.NewRows([]string{"id", "data", "dataType"}).
+		AddRow("account-id", `{"disabled":false}`, "account")
+
+	mock, fetcher := newFetcher(t, mockReturn, mockQuery, "account-id")
+	defer fetcher.db.Close()
+
+	account, errs := fetcher.FetchAccount(context.Background(), "account-id")
+
+	assertMockExpectations(t, mock)
+	assertErrorCount(t, 0, errs)
+	assert.JSONEq(t, `{"disabled":false}`, string(account))
+}
+
+func TestFetchAccountNotFound(t *testing.T) {
+	mockQuery := "SELECT id, config, 'account' AS dataType FROM accounts_table WHERE id IN (?)"
+	mockReturn := sqlmock.NewRows([]string{"id", "data", "dataType"}).
+		AddRow("account-id", `{"req":true}`, "request")
+
+	mock, fetcher := newFetcher(t, mockReturn, mockQuery, "account-id")
+	defer fetcher.db.Close()
+
+	account, errs := fetcher.FetchAccount(context.Background(), "account-id")
+
+	assertMockExpectations(t, mock)
+	assert.Nil(t, account)
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "account-id", DataType: "Account"}}, errs)
+}
+
+func TestFetchAccountWithoutQuery(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Unexpected error stubbing DB: %v", err)
+	}
+	defer db.Close()
+
+	fetcher := dbFetcher{db: db}
+	account, errs := fetcher.FetchAccount(context.Background(), "account-id")
+
+	assert.Nil(t, account)
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "account-id", DataType: "Account"}}, errs)
+}
+
 func newFetcher(t *testing.T, rows *sqlmock.Rows, query string, args ...driver.Value) (sqlmock.Sqlmock, *dbFetcher) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -183,8 +262,10 @@ func newFetcher(t *testing.T, rows *sqlmock.Rows, query string, args ...driver.V
 	queryRegex := fmt.Sprintf("^%s$", regexp.QuoteMeta(query))
 	mock.ExpectQuery(queryRegex).WithArgs(args...).WillReturnRows(rows)
 	fetcher := &dbFetcher{
-		db:         db,
-		queryMaker: successfulQueryMaker(query),
+		db:                 db,
+		queryMaker:         successfulQueryMaker(query),
+		responseQueryMaker: successfulResponseQueryMaker(query),
+		accountQueryMaker:  successfulResponseQueryMaker(query),
 	}
 
 	return mock, fetcher
@@ -227,3 +308,9 @@ func successfulQueryMaker(response string) func(int, int) string {
 		return response
 	}
 }
+
+func successfulResponseQueryMaker(response string) func(int) string {
+	return func(numIds int) string {
+		return response
+	}
+}
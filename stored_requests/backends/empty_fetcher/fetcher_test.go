@@ -19,3 +19,15 @@ func TestErrorLength(t *testing.T) {
 		t.Errorf("The empty fetcher should return 3 errors. Got %d", len(errs))
 	}
 }
+
+func TestResponsesErrorLength(t *testing.T) {
+	fetcher := EmptyFetcher{}
+
+	storedResponses, errs := fetcher.FetchResponses(context.Background(), []string{"a", "b"})
+	if len(storedResponses) != 0 {
+		t.Errorf("The empty fetcher should never return stored responses. Got %d", len(storedResponses))
+	}
+	if len(errs) != 2 {
+		t.Errorf("The empty fetcher should return 2 errors. Got %d", len(errs))
+	}
+}
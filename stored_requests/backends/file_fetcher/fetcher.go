@@ -33,6 +33,13 @@ func (fetcher *eagerFetcher) FetchRequests(ctx context.Context, requestIDs []str
 	return storedRequests, storedImpressions, errs
 }
 
+// FetchResponses fetches the stored responses from the "stored_responses" directory
+func (fetcher *eagerFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	storedResponses := fetcher.FileSystem.Directories["stored_responses"].Files
+	errs := appendErrors("Response", ids, storedResponses, nil)
+	return storedResponses, errs
+}
+
 // FetchAccount fetches the host account configuration for a publisher
 func (fetcher *eagerFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
 	if len(accountID) == 0 {
@@ -26,6 +26,9 @@ import (
 // Accounts
 // GET {endpoint}?account-ids=["acc1","acc2"]
 //
+// Stored responses
+// GET {endpoint}?response-ids=["resp1","resp2"]
+//
 // The above endpoints should return a payload like:
 //
 // {
@@ -46,6 +49,13 @@ import (
 //     "acc2": { ... config data for acc2 ... },
 //   },
 // }
+// or
+// {
+//   "responses": {
+//     "resp1": { ... stored data for resp1 ... },
+//     "resp2": null // If resp2 is not found
+//   },
+// }
 //
 //
 func NewFetcher(client *http.Client, endpoint string) *HttpFetcher {
@@ -97,6 +107,48 @@ func (fetcher *HttpFetcher) FetchRequests(ctx context.Context, requestIDs []stri
 	return
 }
 
+// FetchResponses retrieves stored responses
+//
+// Request format is similar to the one for requests:
+// GET {endpoint}?response-ids=["response1","response2",...]
+func (fetcher *HttpFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fetcher.Endpoint+"response-ids=[\""+strings.Join(ids, "\",\"")+"\"]", nil)
+	if err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: build request failed with %v`, ids, err),
+		}
+	}
+	httpResp, err := ctxhttp.Do(ctx, fetcher.client, httpReq)
+	if err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: %v`, ids, err),
+		}
+	}
+	defer httpResp.Body.Close()
+	respBytes, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: error reading response: %v`, ids, err),
+		}
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: unexpected response status %d`, ids, httpResp.StatusCode),
+		}
+	}
+	var responseData storedResponsesResponseContract
+	if err = json.Unmarshal(respBytes, &responseData); err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: failed to parse response: %v`, ids, err),
+		}
+	}
+	errs = convertNullsToErrs(responseData.Responses, "Response", []error{})
+	return responseData.Responses, errs
+}
+
 // FetchAccounts retrieves account configurations
 //
 // Request format is similar to the one for requests:
@@ -270,3 +322,7 @@ type responseContract struct {
 type accountsResponseContract struct {
 	Accounts map[string]json.RawMessage `json:"accounts"`
 }
+
+type storedResponsesResponseContract struct {
+	Responses map[string]json.RawMessage `json:"responses"`
+}
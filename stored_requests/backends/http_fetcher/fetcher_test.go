@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -132,6 +133,33 @@ func TestFetchAccountsContextTimeout(t *testing.T) {
 	assert.Nil(t, accData, "Unexpected account data returned instead of timeout")
 }
 
+func TestFetchResponses(t *testing.T) {
+	fetcher, close := newTestResponseFetcher(t, []string{"resp-1", "resp-2"}, []string{"resp-1"})
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1", "resp-2"})
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "resp-2", DataType: "Response"}}, errs, "Fetching unknown stored response should have returned an error")
+	assertMapKeys(t, respData, "resp-1")
+}
+
+func TestFetchResponsesNoData(t *testing.T) {
+	fetcher, close := newFetcherBrokenBackend()
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1"})
+	assert.Len(t, errs, 1, "Fetching stored responses from a broken backend should have returned an error")
+	assert.Nil(t, respData, "Fetching stored responses from a broken backend should return nil response map")
+}
+
+func TestFetchResponsesNoIDsProvided(t *testing.T) {
+	fetcher, close := newTestResponseFetcher(t, nil, nil)
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), []string{})
+	assert.Empty(t, errs, "Unexpected error fetching empty stored response list")
+	assert.Nil(t, respData, "Fetching empty stored response list should return nil")
+}
+
 func TestFetchAccount(t *testing.T) {
 	fetcher, close := newTestAccountFetcher(t, []string{"acc-1"})
 	defer close()
@@ -304,6 +332,30 @@ func newAccountHandler(t *testing.T, expectAccIDs []string, jsonifier func(strin
 	}
 }
 
+func newTestResponseFetcher(t *testing.T, expectRespIDs []string, knownRespIDs []string) (fetcher *HttpFetcher, closer func()) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		gotRespIDs := richSplit(r.URL.Query().Get("response-ids"))
+		assertMatches(t, gotRespIDs, expectRespIDs)
+
+		respIDResponse := make(map[string]json.RawMessage, len(gotRespIDs))
+		for _, respID := range gotRespIDs {
+			respIDResponse[respID] = json.RawMessage("null")
+		}
+		for _, respID := range knownRespIDs {
+			respIDResponse[respID] = jsonifyID(respID)
+		}
+
+		if respBytes, err := json.Marshal(storedResponsesResponseContract{Responses: respIDResponse}); err != nil {
+			t.Errorf("failed to marshal storedResponsesResponseContract in test:  %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+		} else {
+			w.Write(respBytes)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	return NewFetcher(server.Client(), server.URL), server.Close
+}
+
 func assertMatches(t *testing.T, queryVals []string, expected []string) {
 	t.Helper()
 
@@ -65,3 +65,8 @@ func (c *cache) Invalidate(ctx context.Context, ids []string) {
 		c.cache.Delete(id)
 	}
 }
+
+// IDs returns the IDs of all the values held by the cache.
+func (c *cache) IDs() []string {
+	return c.cache.Keys()
+}
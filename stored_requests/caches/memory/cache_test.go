@@ -9,6 +9,7 @@ import (
 
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/caches/cachestest"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestLRURobustness(t *testing.T) {
@@ -23,6 +24,35 @@ func TestUnboundedRobustness(t *testing.T) {
 	})
 }
 
+func TestIDs(t *testing.T) {
+	testCases := []struct {
+		description string
+		cache       stored_requests.CacheJSON
+	}{
+		{
+			description: "lru",
+			cache:       NewCache(256*1024, -1, "TestData"),
+		},
+		{
+			description: "unbounded",
+			cache:       NewCache(0, -1, "TestData"),
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Empty(t, stored_requests.ListCachedIDs(test.cache), test.description)
+
+		test.cache.Save(context.Background(), map[string]json.RawMessage{
+			"b": json.RawMessage(`true`),
+			"a": json.RawMessage(`true`),
+			"c": json.RawMessage(`true`),
+		})
+		test.cache.Invalidate(context.Background(), []string{"c"})
+
+		assert.Equal(t, []string{"a", "b"}, stored_requests.ListCachedIDs(test.cache), test.description)
+	}
+}
+
 func TestRaceLRUConcurrency(t *testing.T) {
 	cache := NewCache(256*1024, -1, "TestData")
 	doRaceTest(t, cache)
@@ -16,6 +16,7 @@ type mapLike interface {
 	Get(id string) (json.RawMessage, bool)
 	Set(id string, value json.RawMessage)
 	Delete(id string)
+	Keys() []string
 }
 
 // sync.Map wrapper which implements the interface
@@ -40,6 +41,15 @@ func (m *pbsSyncMap) Delete(id string) {
 	m.Map.Delete(id)
 }
 
+func (m *pbsSyncMap) Keys() []string {
+	var keys []string
+	m.Map.Range(func(key, _ interface{}) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	return keys
+}
+
 // lruCache wrapper which implements the interface
 type pbsLRUCache struct {
 	*freecache.Cache
@@ -66,3 +76,12 @@ func (m *pbsLRUCache) Set(id string, value json.RawMessage) {
 func (m *pbsLRUCache) Delete(id string) {
 	m.Cache.Del([]byte(id))
 }
+
+func (m *pbsLRUCache) Keys() []string {
+	var keys []string
+	it := m.Cache.NewIterator()
+	for entry := it.Next(); entry != nil; entry = it.Next() {
+		keys = append(keys, string(entry.Key))
+	}
+	return keys
+}
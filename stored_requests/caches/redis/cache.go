@@ -0,0 +1,296 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_requests/caches/nil_cache"
+	"github.com/prebid/prebid-server/stored_requests/events"
+)
+
+// resubscribeDelay is how long the invalidation listener waits before reconnecting after a failure.
+var resubscribeDelay = time.Second
+
+// SharedCache is a Stored Data cache backed by a Redis-protocol server, so that it can be shared
+// by every Prebid Server instance. It is meant to be stacked behind the in-memory cache:
+//
+// 1. Saves are written to the server, and the saved IDs are published so that other instances drop
+// their in-memory copies and read the new values through the shared cache.
+// 2. Invalidations delete the keys from the server and are published to the other instances.
+//
+// Listen must be running for this instance to receive the changes published by the others.
+type SharedCache struct {
+	client    *client
+	dataType  config.DataType
+	keyPrefix string
+	channel   string
+	ttl       int
+	origin    string
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	lock     sync.Mutex
+	subConn  *conn
+}
+
+// invalidationMessage is published on the invalidation channel. Origin identifies the publishing instance,
+// which ignores its own messages.
+type invalidationMessage struct {
+	Origin string `json:"origin"`
+	events.Invalidation
+}
+
+// NewSharedCache returns a SharedCache for the Stored Data of the given type. It doesn't connect to
+// the server until the cache is first used.
+func NewSharedCache(cfg config.SharedCache, dataType config.DataType) *SharedCache {
+	glog.Infof("Using a Stored %s shared cache. Address: %s. TTL: %d seconds.", dataType, cfg.Address, cfg.TTL)
+
+	prefix := cfg.KeyPrefix + ":" + strings.ToLower(strings.Replace(string(dataType), " ", "_", -1))
+	return &SharedCache{
+		client:    newClient(cfg.Address, cfg.Password, cfg.Database, cfg.TimeoutDuration(), cfg.PoolSize),
+		dataType:  dataType,
+		keyPrefix: prefix,
+		channel:   prefix + ":invalidations",
+		ttl:       cfg.TTL,
+		origin:    newOrigin(),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Cache returns the shared cache layers for the data type of this SharedCache.
+func (s *SharedCache) Cache() stored_requests.Cache {
+	cache := stored_requests.Cache{
+		Requests: &nil_cache.NilCache{},
+		Imps:     &nil_cache.NilCache{},
+		Accounts: &nil_cache.NilCache{},
+	}
+	if s.dataType == config.AccountDataType {
+		cache.Accounts = &cacheJSON{shared: s, kind: "accounts"}
+	} else {
+		cache.Requests = &cacheJSON{shared: s, kind: "requests"}
+		cache.Imps = &cacheJSON{shared: s, kind: "imps"}
+	}
+	return cache
+}
+
+// Listen is meant to be run as a goroutine. It invalidates the local cache when other instances publish
+// changes to the shared cache, until Stop is called.
+func (s *SharedCache) Listen(local stored_requests.Cache) {
+	for {
+		err := s.subscribe(local)
+
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+		glog.Warningf("Stored %s shared cache invalidation listener disconnected: %v", s.dataType, err)
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+// Stop stops the invalidation listener and closes the connections to the server.
+func (s *SharedCache) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		s.lock.Lock()
+		if s.subConn != nil {
+			s.subConn.Close()
+		}
+		s.lock.Unlock()
+		s.client.close()
+	})
+}
+
+func (s *SharedCache) subscribe(local stored_requests.Cache) error {
+	cn, err := s.client.dial(context.Background())
+	if err != nil {
+		return err
+	}
+	defer cn.Close()
+
+	s.lock.Lock()
+	select {
+	case <-s.stop:
+		s.lock.Unlock()
+		return nil
+	default:
+		s.subConn = cn
+	}
+	s.lock.Unlock()
+
+	// Messages arrive whenever another instance publishes, so the connection must not time out
+	if err := cn.SetDeadline(time.Time{}); err != nil {
+		return err
+	}
+	if _, err := cn.Write(appendCommand(nil, []string{"SUBSCRIBE", s.channel})); err != nil {
+		return err
+	}
+
+	for {
+		reply, err := readReply(cn.reader)
+		if err != nil {
+			return err
+		}
+		if replyErr, ok := reply.(serverError); ok {
+			return replyErr
+		}
+		elements, ok := reply.([]interface{})
+		if !ok || len(elements) != 3 {
+			continue
+		}
+		if kind, ok := elements[0].([]byte); !ok || string(kind) != "message" {
+			continue
+		}
+		if payload, ok := elements[2].([]byte); ok {
+			s.handleMessage(local, payload)
+		}
+	}
+}
+
+func (s *SharedCache) handleMessage(local stored_requests.Cache, payload []byte) {
+	var msg invalidationMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		glog.Warningf("Stored %s shared cache received a malformed invalidation: %v", s.dataType, err)
+		return
+	}
+	if msg.Origin == s.origin {
+		return
+	}
+
+	ctx := context.Background()
+	if len(msg.Requests) > 0 {
+		local.Requests.Invalidate(ctx, msg.Requests)
+	}
+	if len(msg.Imps) > 0 {
+		local.Imps.Invalidate(ctx, msg.Imps)
+	}
+	if len(msg.Accounts) > 0 {
+		local.Accounts.Invalidate(ctx, msg.Accounts)
+	}
+}
+
+// publishCommand builds the command which tells the other instances to drop the given IDs from their local caches.
+func (s *SharedCache) publishCommand(kind string, ids []string) []string {
+	msg := invalidationMessage{Origin: s.origin}
+	switch kind {
+	case "requests":
+		msg.Requests = ids
+	case "imps":
+		msg.Imps = ids
+	case "accounts":
+		msg.Accounts = ids
+	}
+	payload, _ := json.Marshal(msg)
+	return []string{"PUBLISH", s.channel, string(payload)}
+}
+
+// cacheJSON is a single layer of the SharedCache, for either the Stored Requests, Imps or Accounts.
+type cacheJSON struct {
+	shared *SharedCache
+	kind   string
+}
+
+func (c *cacheJSON) key(id string) string {
+	return c.shared.keyPrefix + ":" + c.kind + ":" + id
+}
+
+func (c *cacheJSON) Get(ctx context.Context, ids []string) (data map[string]json.RawMessage) {
+	data = make(map[string]json.RawMessage, len(ids))
+	if len(ids) == 0 {
+		return
+	}
+
+	cmd := make([]string, 0, len(ids)+1)
+	cmd = append(cmd, "MGET")
+	for _, id := range ids {
+		cmd = append(cmd, c.key(id))
+	}
+
+	reply, err := c.shared.client.do(ctx, cmd...)
+	if err != nil {
+		glog.Warningf("Failed to get Stored %s %s from the shared cache: %v", c.shared.dataType, c.kind, err)
+		return
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(ids) {
+		glog.Warningf("Unexpected shared cache reply when getting Stored %s %s", c.shared.dataType, c.kind)
+		return
+	}
+	for i, value := range values {
+		if value, ok := value.([]byte); ok {
+			data[ids[i]] = value
+		}
+	}
+	return
+}
+
+func (c *cacheJSON) Save(ctx context.Context, data map[string]json.RawMessage) {
+	if len(data) == 0 {
+		return
+	}
+
+	cmds := make([][]string, 0, len(data)+1)
+	ids := make([]string, 0, len(data))
+	for id, value := range data {
+		cmd := []string{"SET", c.key(id), string(value)}
+		if c.shared.ttl > 0 {
+			cmd = append(cmd, "EX", strconv.Itoa(c.shared.ttl))
+		}
+		cmds = append(cmds, cmd)
+		ids = append(ids, id)
+	}
+	cmds = append(cmds, c.shared.publishCommand(c.kind, ids))
+
+	c.exec(ctx, "save", cmds)
+}
+
+func (c *cacheJSON) Invalidate(ctx context.Context, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	del := make([]string, 0, len(ids)+1)
+	del = append(del, "DEL")
+	for _, id := range ids {
+		del = append(del, c.key(id))
+	}
+
+	c.exec(ctx, "invalidate", [][]string{del, c.shared.publishCommand(c.kind, ids)})
+}
+
+func (c *cacheJSON) exec(ctx context.Context, action string, cmds [][]string) {
+	replies, err := c.shared.client.pipeline(ctx, cmds)
+	if err != nil {
+		glog.Warningf("Failed to %s Stored %s %s in the shared cache: %v", action, c.shared.dataType, c.kind, err)
+		return
+	}
+	for _, reply := range replies {
+		if replyErr, ok := reply.(serverError); ok {
+			glog.Warningf("Failed to %s Stored %s %s in the shared cache: %v", action, c.shared.dataType, c.kind, replyErr)
+			return
+		}
+	}
+}
+
+func newOrigin() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
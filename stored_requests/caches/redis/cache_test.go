@@ -0,0 +1,367 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_requests/caches/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveGetInvalidate(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	shared := NewSharedCache(newSharedCacheConfig(server.Addr()), config.RequestDataType)
+	defer shared.Stop()
+	cache := shared.Cache()
+	ctx := context.Background()
+
+	cache.Requests.Save(ctx, map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req-1"}`)})
+	cache.Imps.Save(ctx, map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"imp-1"}`)})
+
+	assert.Equal(t, map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req-1"}`)}, cache.Requests.Get(ctx, []string{"req-1", "req-2"}))
+	assert.Equal(t, map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"imp-1"}`)}, cache.Imps.Get(ctx, []string{"imp-1", "req-1"}))
+	assert.Equal(t, "60", server.TTL("pbs:request:requests:req-1"), "Saved values should expire after the configured TTL")
+
+	cache.Requests.Invalidate(ctx, []string{"req-1"})
+
+	assert.Empty(t, cache.Requests.Get(ctx, []string{"req-1"}))
+	assert.Equal(t, map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"imp-1"}`)}, cache.Imps.Get(ctx, []string{"imp-1"}))
+}
+
+func TestKeysAreSeparatedByDataType(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	requests := NewSharedCache(newSharedCacheConfig(server.Addr()), config.RequestDataType)
+	defer requests.Stop()
+	ampRequests := NewSharedCache(newSharedCacheConfig(server.Addr()), config.AMPRequestDataType)
+	defer ampRequests.Stop()
+	ctx := context.Background()
+
+	requests.Cache().Requests.Save(ctx, map[string]json.RawMessage{"req-1": json.RawMessage(`true`)})
+
+	assert.Empty(t, ampRequests.Cache().Requests.Get(ctx, []string{"req-1"}))
+	assert.Contains(t, server.Keys(), "pbs:request:requests:req-1")
+}
+
+func TestAuthAndSelect(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	cfg := newSharedCacheConfig(server.Addr())
+	cfg.Password = "secret"
+	cfg.Database = 3
+	shared := NewSharedCache(cfg, config.AccountDataType)
+	defer shared.Stop()
+
+	shared.Cache().Accounts.Get(context.Background(), []string{"account-1"})
+
+	assert.Equal(t, []string{"AUTH secret", "SELECT 3", "MGET pbs:account:accounts:account-1"}, server.Commands())
+}
+
+func TestServerUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	shared := NewSharedCache(newSharedCacheConfig(address), config.RequestDataType)
+	defer shared.Stop()
+	cache := shared.Cache()
+	ctx := context.Background()
+
+	cache.Requests.Save(ctx, map[string]json.RawMessage{"req-1": json.RawMessage(`true`)})
+	cache.Requests.Invalidate(ctx, []string{"req-1"})
+
+	assert.Empty(t, cache.Requests.Get(ctx, []string{"req-1"}), "An unavailable shared cache should behave as a miss")
+}
+
+func TestListenInvalidatesLocalCache(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+	ctx := context.Background()
+
+	local := stored_requests.Cache{
+		Requests: memory.NewCache(0, 0, "Requests"),
+		Imps:     memory.NewCache(0, 0, "Imps"),
+		Accounts: memory.NewCache(0, 0, "Accounts"),
+	}
+	local.Requests.Save(ctx, map[string]json.RawMessage{"req-1": json.RawMessage(`true`), "req-2": json.RawMessage(`true`)})
+	local.Imps.Save(ctx, map[string]json.RawMessage{"imp-1": json.RawMessage(`true`)})
+
+	listening := NewSharedCache(newSharedCacheConfig(server.Addr()), config.RequestDataType)
+	defer listening.Stop()
+	go listening.Listen(local)
+	server.WaitForSubscribers(t, 1)
+
+	publishing := NewSharedCache(newSharedCacheConfig(server.Addr()), config.RequestDataType)
+	defer publishing.Stop()
+	publishing.Cache().Requests.Invalidate(ctx, []string{"req-1"})
+	publishing.Cache().Imps.Save(ctx, map[string]json.RawMessage{"imp-1": json.RawMessage(`false`)})
+
+	assert.Eventually(t, func() bool {
+		return len(local.Requests.Get(ctx, []string{"req-1"})) == 0 && len(local.Imps.Get(ctx, []string{"imp-1"})) == 0
+	}, time.Second, 5*time.Millisecond, "Changes published by other instances should invalidate the local cache")
+	assert.Len(t, local.Requests.Get(ctx, []string{"req-2"}), 1, "Other local values should be kept")
+}
+
+func TestListenIgnoresOwnChanges(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+	ctx := context.Background()
+
+	local := stored_requests.Cache{
+		Requests: memory.NewCache(0, 0, "Requests"),
+		Imps:     memory.NewCache(0, 0, "Imps"),
+		Accounts: memory.NewCache(0, 0, "Accounts"),
+	}
+
+	local.Requests.Save(ctx, map[string]json.RawMessage{"req-2": json.RawMessage(`true`)})
+
+	shared := NewSharedCache(newSharedCacheConfig(server.Addr()), config.RequestDataType)
+	defer shared.Stop()
+	go shared.Listen(local)
+	server.WaitForSubscribers(t, 1)
+
+	data := map[string]json.RawMessage{"req-1": json.RawMessage(`true`)}
+	stored_requests.TieredCache{local.Requests, shared.Cache().Requests}.Save(ctx, data)
+
+	// Messages are delivered in order, so once the later one is handled the first one was handled too
+	other := NewSharedCache(newSharedCacheConfig(server.Addr()), config.RequestDataType)
+	defer other.Stop()
+	other.Cache().Requests.Invalidate(ctx, []string{"req-2"})
+
+	assert.Eventually(t, func() bool {
+		return len(local.Requests.Get(ctx, []string{"req-2"})) == 0
+	}, time.Second, 5*time.Millisecond, "Changes published by other instances should invalidate the local cache")
+	assert.Equal(t, data, local.Requests.Get(ctx, []string{"req-1"}), "Changes made by this instance shouldn't invalidate its own local cache")
+}
+
+func TestReadReply(t *testing.T) {
+	testCases := []struct {
+		description string
+		giveReply   string
+		expected    interface{}
+	}{
+		{
+			description: "simple string",
+			giveReply:   "+OK\r\n",
+			expected:    "OK",
+		},
+		{
+			description: "error",
+			giveReply:   "-ERR unknown command\r\n",
+			expected:    serverError("ERR unknown command"),
+		},
+		{
+			description: "integer",
+			giveReply:   ":42\r\n",
+			expected:    int64(42),
+		},
+		{
+			description: "bulk string",
+			giveReply:   "$5\r\nhello\r\n",
+			expected:    []byte("hello"),
+		},
+		{
+			description: "null bulk string",
+			giveReply:   "$-1\r\n",
+			expected:    nil,
+		},
+		{
+			description: "array",
+			giveReply:   "*3\r\n$1\r\na\r\n$-1\r\n:1\r\n",
+			expected:    []interface{}{[]byte("a"), nil, int64(1)},
+		},
+	}
+
+	for _, test := range testCases {
+		reply, err := readReply(bufio.NewReader(strings.NewReader(test.giveReply)))
+
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expected, reply, test.description)
+	}
+}
+
+func TestReadReplyMalformed(t *testing.T) {
+	_, err := readReply(bufio.NewReader(strings.NewReader("?what\r\n")))
+	assert.Error(t, err)
+
+	_, err = readReply(bufio.NewReader(strings.NewReader("+OK\n")))
+	assert.Error(t, err)
+}
+
+func newSharedCacheConfig(address string) config.SharedCache {
+	return config.SharedCache{
+		Type:      "redis",
+		Address:   address,
+		KeyPrefix: "pbs",
+		TTL:       60,
+		Timeout:   500,
+		PoolSize:  2,
+	}
+}
+
+// fakeServer is a minimal stand-in for a Redis server, which supports the commands used by the shared cache.
+type fakeServer struct {
+	listener net.Listener
+
+	lock        sync.Mutex
+	values      map[string]string
+	ttls        map[string]string
+	commands    []string
+	subscribers map[string][]net.Conn
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start the fake server: %v", err)
+	}
+	server := &fakeServer{
+		listener:    listener,
+		values:      make(map[string]string),
+		ttls:        make(map[string]string),
+		subscribers: make(map[string][]net.Conn),
+	}
+	go server.serve()
+	return server
+}
+
+func (s *fakeServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeServer) Close() {
+	s.listener.Close()
+}
+
+func (s *fakeServer) TTL(key string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.ttls[key]
+}
+
+func (s *fakeServer) Keys() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *fakeServer) Commands() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *fakeServer) WaitForSubscribers(t *testing.T, count int) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		subscribers := 0
+		for _, conns := range s.subscribers {
+			subscribers += len(conns)
+		}
+		return subscribers >= count
+	}, time.Second, time.Millisecond)
+}
+
+func (s *fakeServer) serve() {
+	for {
+		cn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(cn)
+	}
+}
+
+func (s *fakeServer) handle(cn net.Conn) {
+	defer cn.Close()
+	reader := bufio.NewReader(cn)
+	for {
+		request, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		elements, _ := request.([]interface{})
+		args := make([]string, len(elements))
+		for i, element := range elements {
+			args[i] = string(element.([]byte))
+		}
+		if _, err := cn.Write(s.execute(cn, args)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) execute(cn net.Conn, args []string) []byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	command := args[0]
+	for _, arg := range args[1:] {
+		command += " " + arg
+	}
+	s.commands = append(s.commands, command)
+
+	switch args[0] {
+	case "AUTH", "SELECT":
+		return []byte("+OK\r\n")
+	case "SET":
+		s.values[args[1]] = args[2]
+		if len(args) == 5 && args[3] == "EX" {
+			s.ttls[args[1]] = args[4]
+		}
+		return []byte("+OK\r\n")
+	case "MGET":
+		reply := []byte("*" + strconv.Itoa(len(args)-1) + "\r\n")
+		for _, key := range args[1:] {
+			if value, ok := s.values[key]; ok {
+				reply = append(reply, "$"+strconv.Itoa(len(value))+"\r\n"+value+"\r\n"...)
+			} else {
+				reply = append(reply, "$-1\r\n"...)
+			}
+		}
+		return reply
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.values[key]; ok {
+				delete(s.values, key)
+				deleted++
+			}
+		}
+		return []byte(":" + strconv.Itoa(deleted) + "\r\n")
+	case "PUBLISH":
+		for _, subscriber := range s.subscribers[args[1]] {
+			subscriber.Write(appendCommand(nil, []string{"message", args[1], args[2]}))
+		}
+		return []byte(":" + strconv.Itoa(len(s.subscribers[args[1]])) + "\r\n")
+	case "SUBSCRIBE":
+		s.subscribers[args[1]] = append(s.subscribers[args[1]], cn)
+		return appendCommand(nil, []string{"subscribe", args[1], "1"})
+	default:
+		return []byte("-ERR unknown command '" + args[0] + "'\r\n")
+	}
+}
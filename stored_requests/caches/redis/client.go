@@ -0,0 +1,281 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// client speaks the subset of the Redis serialization protocol (RESP) needed by the shared cache.
+// It keeps a small pool of idle connections, and is safe for concurrent use by multiple goroutines.
+type client struct {
+	address  string
+	password string
+	database int
+	timeout  time.Duration
+	idle     chan *conn
+}
+
+// serverError is an error reply sent by the server. The connection which received it is still usable.
+type serverError string
+
+func (e serverError) Error() string {
+	return string(e)
+}
+
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func newClient(address string, password string, database int, timeout time.Duration, poolSize int) *client {
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+	return &client{
+		address:  address,
+		password: password,
+		database: database,
+		timeout:  timeout,
+		idle:     make(chan *conn, poolSize),
+	}
+}
+
+// do sends a single command and returns its reply.
+func (c *client) do(ctx context.Context, args ...string) (interface{}, error) {
+	replies, err := c.pipeline(ctx, [][]string{args})
+	if err != nil {
+		return nil, err
+	}
+	if replyErr, ok := replies[0].(serverError); ok {
+		return nil, replyErr
+	}
+	return replies[0], nil
+}
+
+// pipeline sends all the commands at once and returns their replies in the same order.
+// Error replies are returned as serverError values in the reply slice.
+func (c *client) pipeline(ctx context.Context, cmds [][]string) ([]interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := c.roundTrip(ctx, cn, cmds)
+	if err != nil {
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return replies, nil
+}
+
+func (c *client) roundTrip(ctx context.Context, cn *conn, cmds [][]string) ([]interface{}, error) {
+	if err := cn.SetDeadline(c.deadline(ctx)); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, 64)
+	for _, cmd := range cmds {
+		buf = appendCommand(buf, cmd)
+	}
+	if _, err := cn.Write(buf); err != nil {
+		return nil, err
+	}
+
+	replies := make([]interface{}, len(cmds))
+	for i := range cmds {
+		reply, err := readReply(cn.reader)
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = reply
+	}
+	return replies, nil
+}
+
+func (c *client) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+func (c *client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+		return c.dial(ctx)
+	}
+}
+
+func (c *client) put(cn *conn) {
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+// dial opens a new connection, authenticates and selects the configured database.
+func (c *client) dial(ctx context.Context) (*conn, error) {
+	dialer := net.Dialer{Deadline: c.deadline(ctx)}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	var setup [][]string
+	if c.password != "" {
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+	if c.database != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.database)})
+	}
+	if len(setup) == 0 {
+		return cn, nil
+	}
+
+	replies, err := c.roundTrip(ctx, cn, setup)
+	if err == nil {
+		for _, reply := range replies {
+			if replyErr, ok := reply.(serverError); ok {
+				err = replyErr
+				break
+			}
+		}
+	}
+	if err != nil {
+		cn.Close()
+		return nil, fmt.Errorf("failed to set up the connection to %s: %v", c.address, err)
+	}
+	return cn, nil
+}
+
+// close closes all the idle connections.
+func (c *client) close() {
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.Close()
+		default:
+			return
+		}
+	}
+}
+
+func appendCommand(buf []byte, args []string) []byte {
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	return buf
+}
+
+// readReply reads a single reply. Bulk strings are returned as []byte, integers as int64,
+// simple strings as string, arrays as []interface{} and error replies as serverError.
+// Null bulk strings and null arrays are returned as nil.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return serverError(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		elements := make([]interface{}, size)
+		for i := range elements {
+			if elements[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return elements, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply type %q", line[0])
+	}
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+// Client is a Redis-protocol client for the other features of Prebid Server which keep their data in a
+// Redis-protocol server. Its replies have the types documented on readReply.
+type Client struct {
+	client *client
+}
+
+// NewClient returns a Client which keeps up to poolSize idle connections. It doesn't connect to the server
+// until it's first used.
+func NewClient(address string, password string, database int, timeout time.Duration, poolSize int) *Client {
+	return &Client{client: newClient(address, password, database, timeout, poolSize)}
+}
+
+// Do sends a single command and returns its reply.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	return c.client.do(ctx, args...)
+}
+
+// Pipeline sends all the commands at once and returns their replies in the same order. It fails if any
+// of the commands gets an error reply.
+func (c *Client) Pipeline(ctx context.Context, cmds [][]string) ([]interface{}, error) {
+	replies, err := c.client.pipeline(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+	for _, reply := range replies {
+		if replyErr, ok := reply.(serverError); ok {
+			return nil, replyErr
+		}
+	}
+	return replies, nil
+}
+
+// Close closes the idle connections.
+func (c *Client) Close() {
+	c.client.close()
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"net/http"
 	"time"
 
@@ -18,9 +19,11 @@ import (
 	"github.com/prebid/prebid-server/stored_requests/backends/http_fetcher"
 	"github.com/prebid/prebid-server/stored_requests/caches/memory"
 	"github.com/prebid/prebid-server/stored_requests/caches/nil_cache"
+	redisCache "github.com/prebid/prebid-server/stored_requests/caches/redis"
 	"github.com/prebid/prebid-server/stored_requests/events"
 	apiEvents "github.com/prebid/prebid-server/stored_requests/events/api"
 	httpEvents "github.com/prebid/prebid-server/stored_requests/events/http"
+	mysqlEvents "github.com/prebid/prebid-server/stored_requests/events/mysql"
 	postgresEvents "github.com/prebid/prebid-server/stored_requests/events/postgres"
 	"github.com/prebid/prebid-server/util/task"
 )
@@ -41,6 +44,7 @@ type dbConnection struct {
 // It probably means you have a bad config or networking issue.
 //
 // As a side-effect, it will add some endpoints to the router if the config calls for it.
+// These endpoints give direct access to the cached data, so the router must only be served on the admin port.
 // In the future we should look for ways to simplify this so that it's not doing two things.
 func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router, dbc *dbConnection) (fetcher stored_requests.AllFetcher, shutdown func()) {
 	// Create database connection if given options for one
@@ -64,22 +68,52 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 			glog.Fatal("Multiple database connection settings found in config, only a single database connection is currently supported.")
 		}
 	}
+	if cfg.MySql.ConnectionInfo.Database != "" {
+		conn := cfg.MySql.ConnectionInfo.ConnString()
+
+		if dbc.conn == "" {
+			glog.Infof("Connecting to MySQL for Stored %s. DB=%s, host=%s, port=%d, user=%s",
+				cfg.DataType(),
+				cfg.MySql.ConnectionInfo.Database,
+				cfg.MySql.ConnectionInfo.Host,
+				cfg.MySql.ConnectionInfo.Port,
+				cfg.MySql.ConnectionInfo.Username)
+			db := newMySqlDB(cfg.DataType(), cfg.MySql.ConnectionInfo)
+			dbc.conn = conn
+			dbc.db = db
+		}
+
+		// Error out if config is trying to use multiple database connections for different stored requests (not supported yet)
+		if conn != dbc.conn {
+			glog.Fatal("Multiple database connection settings found in config, only a single database connection is currently supported.")
+		}
+	}
 
 	eventProducers := newEventProducers(cfg, client, dbc.db, metricsEngine, router)
 	fetcher = newFetcher(cfg, client, dbc.db)
 
 	var shutdown1 func()
+	var shutdown2 func()
 
 	if cfg.InMemoryCache.Type != "" {
 		cache := newCache(cfg)
+		if cfg.SharedCache.Type == "redis" {
+			cache, shutdown2 = addSharedCache(cfg, cache)
+		}
 		fetcher = stored_requests.WithCache(fetcher, cache, metricsEngine)
 		shutdown1 = addListeners(cache, eventProducers)
+		if cfg.DataType() != config.ResponseDataType {
+			addCacheAPI(cfg, router, cache, fetcher)
+		}
 	}
 
 	shutdown = func() {
 		if shutdown1 != nil {
 			shutdown1()
 		}
+		if shutdown2 != nil {
+			shutdown2()
+		}
 		if dbc.db != nil {
 			db := dbc.db
 			dbc.db = nil
@@ -93,7 +127,7 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 	return
 }
 
-// NewStoredRequests returns five things:
+// NewStoredRequests returns seven things:
 //
 // 1. A DB connection, if one was created. This may be nil.
 // 2. A function which should be called on shutdown for graceful cleanups.
@@ -101,13 +135,15 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 // 4. A Fetcher which can be used to get Stored Requests for /openrtb2/amp
 // 5. A Fetcher which can be used to get Category Mapping data
 // 6. A Fetcher which can be used to get Stored Requests for /openrtb2/video
+// 7. A Fetcher which can be used to get Stored Responses
 //
 // If any errors occur, the program will exit with an error message.
 // It probably means you have a bad config or networking issue.
 //
 // As a side-effect, it will add some endpoints to the router if the config calls for it.
+// These endpoints give direct access to the cached data, so the router must only be served on the admin port.
 // In the future we should look for ways to simplify this so that it's not doing two things.
-func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router) (db *sql.DB, shutdown func(), fetcher stored_requests.Fetcher, ampFetcher stored_requests.Fetcher, accountsFetcher stored_requests.AccountFetcher, categoriesFetcher stored_requests.CategoryFetcher, videoFetcher stored_requests.Fetcher) {
+func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router) (db *sql.DB, shutdown func(), fetcher stored_requests.Fetcher, ampFetcher stored_requests.Fetcher, accountsFetcher stored_requests.AccountFetcher, categoriesFetcher stored_requests.CategoryFetcher, videoFetcher stored_requests.Fetcher, storedRespFetcher stored_requests.Fetcher) {
 	// TODO: Switch this to be set in config defaults
 	//if cfg.CategoryMapping.CacheEvents.Enabled && cfg.CategoryMapping.CacheEvents.Endpoint == "" {
 	//	cfg.CategoryMapping.CacheEvents.Endpoint = "/storedrequest/categorymapping"
@@ -120,6 +156,7 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 	fetcher3, shutdown3 := CreateStoredRequests(&cfg.CategoryMapping, metricsEngine, client, router, &dbc)
 	fetcher4, shutdown4 := CreateStoredRequests(&cfg.StoredVideo, metricsEngine, client, router, &dbc)
 	fetcher5, shutdown5 := CreateStoredRequests(&cfg.Accounts, metricsEngine, client, router, &dbc)
+	fetcher6, shutdown6 := CreateStoredRequests(&cfg.StoredResponses, metricsEngine, client, router, &dbc)
 
 	db = dbc.db
 
@@ -128,6 +165,7 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 	categoriesFetcher = fetcher3.(stored_requests.CategoryFetcher)
 	videoFetcher = fetcher4.(stored_requests.Fetcher)
 	accountsFetcher = fetcher5.(stored_requests.AccountFetcher)
+	storedRespFetcher = fetcher6.(stored_requests.Fetcher)
 
 	shutdown = func() {
 		shutdown1()
@@ -135,6 +173,7 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 		shutdown3()
 		shutdown4()
 		shutdown5()
+		shutdown6()
 	}
 
 	return
@@ -165,11 +204,18 @@ func newFetcher(cfg *config.StoredRequests, client *http.Client, db *sql.DB) (fe
 	}
 	if cfg.Postgres.FetcherQueries.QueryTemplate != "" {
 		glog.Infof("Loading Stored %s data via Postgres.\nQuery: %s", cfg.DataType(), cfg.Postgres.FetcherQueries.QueryTemplate)
-		idList = append(idList, db_fetcher.NewFetcher(db, cfg.Postgres.FetcherQueries.MakeQuery))
+		idList = append(idList, db_fetcher.NewFetcher(db, cfg.Postgres.FetcherQueries.MakeQuery, cfg.Postgres.FetcherQueries.MakeQueryResponses, nil))
 	} else if cfg.Postgres.CacheInitialization.Query != "" && cfg.Postgres.PollUpdates.Query != "" {
 		//in this case data will be loaded to cache via poll for updates event
 		idList = append(idList, empty_fetcher.EmptyFetcher{})
 	}
+	if cfg.MySql.FetcherQueries.QueryTemplate != "" {
+		glog.Infof("Loading Stored %s data via MySQL.\nQuery: %s", cfg.DataType(), cfg.MySql.FetcherQueries.QueryTemplate)
+		idList = append(idList, db_fetcher.NewFetcher(db, cfg.MySql.FetcherQueries.MakeQuery, cfg.MySql.FetcherQueries.MakeQueryResponses, cfg.MySql.FetcherQueries.MakeQueryAccounts))
+	} else if cfg.MySql.CacheInitialization.Query != "" && cfg.MySql.PollUpdates.Query != "" {
+		//in this case data will be loaded to cache via poll for updates event
+		idList = append(idList, empty_fetcher.EmptyFetcher{})
+	}
 	if cfg.HTTP.Endpoint != "" {
 		glog.Infof("Loading Stored %s data via HTTP. endpoint=%s", cfg.DataType(), cfg.HTTP.Endpoint)
 		idList = append(idList, http_fetcher.NewFetcher(client, cfg.HTTP.Endpoint))
@@ -193,6 +239,48 @@ func newCache(cfg *config.StoredRequests) stored_requests.Cache {
 	return cache
 }
 
+// addSharedCache stacks the shared cache behind the local one. Changes published by other instances
+// invalidate the local cache, so that they are read through the shared cache.
+func addSharedCache(cfg *config.StoredRequests, local stored_requests.Cache) (cache stored_requests.Cache, shutdown func()) {
+	sharedCache := redisCache.NewSharedCache(cfg.SharedCache, cfg.DataType())
+	go sharedCache.Listen(local)
+
+	shared := sharedCache.Cache()
+	cache = stored_requests.Cache{
+		Requests: stored_requests.TieredCache{local.Requests, shared.Requests},
+		Imps:     stored_requests.TieredCache{local.Imps, shared.Imps},
+		Accounts: stored_requests.TieredCache{local.Accounts, shared.Accounts},
+	}
+	return cache, sharedCache.Stop
+}
+
+// addCacheAPI adds the endpoints which inspect, invalidate and refresh the cached data of the section.
+// The fetcher must be composed with the cache, so that refreshed data is saved into it.
+func addCacheAPI(cfg *config.StoredRequests, router *httprouter.Router, cache stored_requests.Cache, fetcher stored_requests.AllFetcher) {
+	if cfg.DataType() == config.AccountDataType {
+		registerCacheAPI(router, "/storeddata/"+cfg.Section()+"/accounts", apiEvents.NewCacheAPI("Account", cache.Accounts, fetcher.FetchAccount))
+		return
+	}
+
+	fetchRequest := func(ctx context.Context, id string) (json.RawMessage, []error) {
+		requestData, _, errs := fetcher.FetchRequests(ctx, []string{id}, nil)
+		return requestData[id], errs
+	}
+	fetchImp := func(ctx context.Context, id string) (json.RawMessage, []error) {
+		_, impData, errs := fetcher.FetchRequests(ctx, nil, []string{id})
+		return impData[id], errs
+	}
+	registerCacheAPI(router, "/storeddata/"+cfg.Section()+"/requests", apiEvents.NewCacheAPI("Request", cache.Requests, fetchRequest))
+	registerCacheAPI(router, "/storeddata/"+cfg.Section()+"/imps", apiEvents.NewCacheAPI("Imp", cache.Imps, fetchImp))
+}
+
+func registerCacheAPI(router *httprouter.Router, endpoint string, cacheAPI *apiEvents.CacheAPI) {
+	router.GET(endpoint, cacheAPI.HandleList)
+	router.GET(endpoint+"/:id", cacheAPI.HandleGet)
+	router.DELETE(endpoint+"/:id", cacheAPI.HandleInvalidate)
+	router.POST(endpoint+"/:id/refresh", cacheAPI.HandleRefresh)
+}
+
 func newEventProducers(cfg *config.StoredRequests, client *http.Client, db *sql.DB, metricsEngine metrics.MetricsEngine, router *httprouter.Router) (eventProducers []events.EventProducer) {
 	if cfg.CacheEvents.Enabled {
 		eventProducers = append(eventProducers, newEventsAPI(router, cfg.CacheEvents.Endpoint))
@@ -216,6 +304,23 @@ func newEventProducers(cfg *config.StoredRequests, client *http.Client, db *sql.
 		pgEventTickerTask.Start()
 		eventProducers = append(eventProducers, pgEventProducer)
 	}
+	if cfg.MySql.CacheInitialization.Query != "" {
+		mysqlEventCfg := mysqlEvents.MySqlEventProducerConfig{
+			DB:                   db,
+			RequestType:          cfg.DataType(),
+			CacheInitQuery:       cfg.MySql.CacheInitialization.Query,
+			CacheInitTimeout:     time.Duration(cfg.MySql.CacheInitialization.Timeout) * time.Millisecond,
+			CacheUpdateQuery:     cfg.MySql.PollUpdates.Query,
+			CacheUpdateTimeout:   time.Duration(cfg.MySql.PollUpdates.Timeout) * time.Millisecond,
+			CacheUpdateWildcards: cfg.MySql.PollUpdates.WildcardCount(),
+			MetricsEngine:        metricsEngine,
+		}
+		mysqlEventProducer := mysqlEvents.NewMySqlEventProducer(mysqlEventCfg)
+		fetchInterval := time.Duration(cfg.MySql.PollUpdates.RefreshRate) * time.Second
+		mysqlEventTickerTask := task.NewTickerTask(fetchInterval, mysqlEventProducer)
+		mysqlEventTickerTask.Start()
+		eventProducers = append(eventProducers, mysqlEventProducer)
+	}
 	return
 }
 
@@ -255,6 +360,20 @@ func newPostgresDB(dataType config.DataType, cfg config.PostgresConnection) *sql
 	return db
 }
 
+// newMySqlDB opens the connection with the github.com/go-sql-driver/mysql driver, which the router registers.
+func newMySqlDB(dataType config.DataType, cfg config.MySqlConnection) *sql.DB {
+	db, err := sql.Open("mysql", cfg.ConnString())
+	if err != nil {
+		glog.Fatalf("Failed to open %s mysql connection: %v", dataType, err)
+	}
+
+	if err := db.Ping(); err != nil {
+		glog.Fatalf("Failed to ping %s mysql: %v", dataType, err)
+	}
+
+	return db
+}
+
 // consolidate returns a single Fetcher from an array of fetchers of any size.
 func consolidate(dataType config.DataType, fetchers []stored_requests.AllFetcher) stored_requests.AllFetcher {
 	if len(fetchers) == 0 {
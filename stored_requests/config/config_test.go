@@ -229,6 +229,44 @@ func TestNewEventsAPI(t *testing.T) {
 	}
 }
 
+func TestAddCacheAPI(t *testing.T) {
+	router := httprouter.New()
+	fetcher := empty_fetcher.EmptyFetcher{}
+
+	requestsCfg := &config.StoredRequests{InMemoryCache: config.InMemoryCache{Type: "unbounded"}}
+	requestsCfg.SetDataType(config.RequestDataType)
+	addCacheAPI(requestsCfg, router, newCache(requestsCfg), fetcher)
+
+	accountsCfg := &config.StoredRequests{InMemoryCache: config.InMemoryCache{Type: "unbounded"}}
+	accountsCfg.SetDataType(config.AccountDataType)
+	addCacheAPI(accountsCfg, router, newCache(accountsCfg), fetcher)
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{"GET", "/storeddata/stored_requests/requests"},
+		{"GET", "/storeddata/stored_requests/requests/req-1"},
+		{"DELETE", "/storeddata/stored_requests/requests/req-1"},
+		{"POST", "/storeddata/stored_requests/requests/req-1/refresh"},
+		{"GET", "/storeddata/stored_requests/imps"},
+		{"GET", "/storeddata/stored_requests/imps/imp-1"},
+		{"DELETE", "/storeddata/stored_requests/imps/imp-1"},
+		{"POST", "/storeddata/stored_requests/imps/imp-1/refresh"},
+		{"GET", "/storeddata/accounts/accounts"},
+		{"GET", "/storeddata/accounts/accounts/account-1"},
+		{"DELETE", "/storeddata/accounts/accounts/account-1"},
+		{"POST", "/storeddata/accounts/accounts/account-1/refresh"},
+	}
+	for _, route := range routes {
+		handle, _, _ := router.Lookup(route.method, route.path)
+		assert.NotNil(t, handle, "The addCacheAPI method didn't add a %s %s route", route.method, route.path)
+	}
+
+	handle, _, _ := router.Lookup("GET", "/storeddata/accounts/requests")
+	assert.Nil(t, handle, "The accounts section shouldn't have a requests route")
+}
+
 func assertProducerLength(t *testing.T, producers []events.EventProducer, expectedLength int) {
 	t.Helper()
 	if len(producers) != expectedLength {
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/stored_requests"
+)
+
+// CacheAPI exposes a single cache layer (e.g. the Stored Requests, Imps or Accounts of a config section)
+// so that its content can be inspected, invalidated and refreshed. The handlers expect an `:id` param
+// via the URL, except HandleList, e.g.:
+//
+// cacheAPI := NewCacheAPI("Request", cache.Requests, fetch)
+// router.GET("/storeddata/stored_requests/requests", cacheAPI.HandleList)
+// router.GET("/storeddata/stored_requests/requests/:id", cacheAPI.HandleGet)
+// router.DELETE("/storeddata/stored_requests/requests/:id", cacheAPI.HandleInvalidate)
+// router.POST("/storeddata/stored_requests/requests/:id/refresh", cacheAPI.HandleRefresh)
+//
+// The handlers should not be exposed on a public network without authentication,
+// as they give access to the data of every publisher.
+type CacheAPI struct {
+	dataType string
+	cache    stored_requests.CacheJSON
+	fetch    func(ctx context.Context, id string) (json.RawMessage, []error)
+}
+
+type cachedIDs struct {
+	IDs []string `json:"ids"`
+}
+
+// NewCacheAPI creates a CacheAPI for the given cache. The fetch function must read through the cache,
+// so that the fetched data is saved into it.
+func NewCacheAPI(dataType string, cache stored_requests.CacheJSON, fetch func(ctx context.Context, id string) (json.RawMessage, []error)) *CacheAPI {
+	return &CacheAPI{
+		dataType: dataType,
+		cache:    cache,
+		fetch:    fetch,
+	}
+}
+
+// HandleList responds with the IDs of all the values held by the cache.
+func (api *CacheAPI) HandleList(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	body, err := json.Marshal(cachedIDs{IDs: stored_requests.ListCachedIDs(api.cache)})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(fmt.Sprintf("Failed to list the cached IDs: %v\n", err)))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// HandleGet responds with the cached value of the given ID.
+func (api *CacheAPI) HandleGet(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+	data, ok := api.cache.Get(r.Context(), []string{id})[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(fmt.Sprintf("Stored %s with ID=\"%s\" is not cached.\n", api.dataType, id)))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// HandleInvalidate removes the given ID from the cache.
+func (api *CacheAPI) HandleInvalidate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	api.cache.Invalidate(r.Context(), []string{ps.ByName("id")})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleRefresh removes the given ID from the cache and fetches it again from the backend.
+// It responds with the fresh value.
+func (api *CacheAPI) HandleRefresh(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+	api.cache.Invalidate(r.Context(), []string{id})
+
+	data, errs := api.fetch(r.Context(), id)
+	if len(errs) > 0 {
+		status := http.StatusInternalServerError
+		if _, ok := errs[0].(stored_requests.NotFoundError); ok {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		for _, err := range errs {
+			w.Write([]byte(err.Error() + "\n"))
+		}
+		return
+	}
+	if data == nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(stored_requests.NotFoundError{ID: id, DataType: api.dataType}.Error() + "\n"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_requests/caches/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheAPIList(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cache        stored_requests.CacheJSON
+		expectedBody string
+	}{
+		{
+			description:  "empty cache",
+			cache:        memory.NewCache(256*1024, -1, "Request"),
+			expectedBody: `{"ids":[]}`,
+		},
+		{
+			description:  "sorted ids",
+			cache:        newCacheWithData(map[string]json.RawMessage{"b": json.RawMessage(`1`), "a": json.RawMessage(`2`)}),
+			expectedBody: `{"ids":["a","b"]}`,
+		},
+		{
+			description:  "cache which can't list its ids",
+			cache:        &unlistableCache{},
+			expectedBody: `{"ids":[]}`,
+		},
+	}
+
+	for _, test := range testCases {
+		api := NewCacheAPI("Request", test.cache, nil)
+		recorder := httptest.NewRecorder()
+
+		api.HandleList(recorder, httptest.NewRequest("GET", "/", nil), nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, test.description)
+		assert.JSONEq(t, test.expectedBody, recorder.Body.String(), test.description)
+	}
+}
+
+func TestCacheAPIGet(t *testing.T) {
+	api := NewCacheAPI("Request", newCacheWithData(map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req-1"}`)}), nil)
+
+	recorder := httptest.NewRecorder()
+	api.HandleGet(recorder, httptest.NewRequest("GET", "/", nil), idParam("req-1"))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"id":"req-1"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	api.HandleGet(recorder, httptest.NewRequest("GET", "/", nil), idParam("req-2"))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "Stored Request with ID=\"req-2\" is not cached.\n", recorder.Body.String())
+}
+
+func TestCacheAPIInvalidate(t *testing.T) {
+	cache := newCacheWithData(map[string]json.RawMessage{"req-1": json.RawMessage(`true`), "req-2": json.RawMessage(`true`)})
+	api := NewCacheAPI("Request", cache, nil)
+
+	recorder := httptest.NewRecorder()
+	api.HandleInvalidate(recorder, httptest.NewRequest("DELETE", "/", nil), idParam("req-1"))
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, []string{"req-2"}, stored_requests.ListCachedIDs(cache))
+}
+
+func TestCacheAPIRefresh(t *testing.T) {
+	testCases := []struct {
+		description    string
+		fetchedData    json.RawMessage
+		fetchErrs      []error
+		expectedStatus int
+		expectedBody   string
+		expectedIDs    []string
+	}{
+		{
+			description:    "refreshed",
+			fetchedData:    json.RawMessage(`{"id":"req-1","updated":true}`),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"req-1","updated":true}`,
+			expectedIDs:    []string{"req-1"},
+		},
+		{
+			description:    "not found",
+			fetchErrs:      []error{stored_requests.NotFoundError{ID: "req-1", DataType: "Request"}},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Stored Request with ID=\"req-1\" not found.\n",
+			expectedIDs:    []string{},
+		},
+		{
+			description:    "no data",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Stored Request with ID=\"req-1\" not found.\n",
+			expectedIDs:    []string{},
+		},
+		{
+			description:    "fetch error",
+			fetchErrs:      []error{errors.New("timeout")},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "timeout\n",
+			expectedIDs:    []string{},
+		},
+	}
+
+	for _, test := range testCases {
+		cache := newCacheWithData(map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req-1"}`)})
+		fetch := func(ctx context.Context, id string) (json.RawMessage, []error) {
+			// Read through the cache, like the fetchers composed with it
+			assert.Empty(t, cache.Get(ctx, []string{id}), test.description+":the cache should be invalidated before fetching")
+			if test.fetchedData != nil {
+				cache.Save(ctx, map[string]json.RawMessage{id: test.fetchedData})
+			}
+			return test.fetchedData, test.fetchErrs
+		}
+		api := NewCacheAPI("Request", cache, fetch)
+
+		recorder := httptest.NewRecorder()
+		api.HandleRefresh(recorder, httptest.NewRequest("POST", "/", nil), idParam("req-1"))
+
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.description)
+		assert.Equal(t, test.expectedBody, recorder.Body.String(), test.description)
+		assert.Equal(t, test.expectedIDs, stored_requests.ListCachedIDs(cache), test.description)
+	}
+}
+
+func newCacheWithData(data map[string]json.RawMessage) stored_requests.CacheJSON {
+	cache := memory.NewCache(256*1024, -1, "Request")
+	cache.Save(context.Background(), data)
+	return cache
+}
+
+func idParam(id string) httprouter.Params {
+	return httprouter.Params{{Key: "id", Value: id}}
+}
+
+type unlistableCache struct{}
+
+func (c *unlistableCache) Get(ctx context.Context, ids []string) map[string]json.RawMessage {
+	return nil
+}
+
+func (c *unlistableCache) Save(ctx context.Context, data map[string]json.RawMessage) {}
+
+func (c *unlistableCache) Invalidate(ctx context.Context, ids []string) {}
@@ -0,0 +1,234 @@
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/stored_requests/events"
+	"github.com/prebid/prebid-server/util/timeutil"
+)
+
+func bytesNull() []byte {
+	return []byte{'n', 'u', 'l', 'l'}
+}
+
+var storedDataTypeMetricMap = map[config.DataType]metrics.StoredDataType{
+	config.RequestDataType:    metrics.RequestDataType,
+	config.CategoryDataType:   metrics.CategoryDataType,
+	config.VideoDataType:      metrics.VideoDataType,
+	config.AMPRequestDataType: metrics.AMPDataType,
+	config.AccountDataType:    metrics.AccountDataType,
+}
+
+type MySqlEventProducerConfig struct {
+	DB                 *sql.DB
+	RequestType        config.DataType
+	CacheInitQuery     string
+	CacheInitTimeout   time.Duration
+	CacheUpdateQuery   string
+	CacheUpdateTimeout time.Duration
+	// CacheUpdateWildcards is the number of "?" placeholders of the CacheUpdateQuery. MySQL placeholders are
+	// positional, so the time of the last update is bound to each of them.
+	CacheUpdateWildcards int
+	MetricsEngine        metrics.MetricsEngine
+}
+
+type MySqlEventProducer struct {
+	cfg           MySqlEventProducerConfig
+	lastUpdate    time.Time
+	invalidations chan events.Invalidation
+	saves         chan events.Save
+	time          timeutil.Time
+}
+
+func NewMySqlEventProducer(cfg MySqlEventProducerConfig) (eventProducer *MySqlEventProducer) {
+	if cfg.DB == nil {
+		glog.Fatalf("The MySQL Stored %s Loader needs a database connection to work.", cfg.RequestType)
+	}
+
+	return &MySqlEventProducer{
+		cfg:           cfg,
+		lastUpdate:    time.Time{},
+		saves:         make(chan events.Save, 1),
+		invalidations: make(chan events.Invalidation, 1),
+		time:          &timeutil.RealTime{},
+	}
+}
+
+func (e *MySqlEventProducer) Run() error {
+	if e.lastUpdate.IsZero() {
+		return e.fetchAll()
+	}
+
+	return e.fetchDelta()
+}
+
+func (e *MySqlEventProducer) Saves() <-chan events.Save {
+	return e.saves
+}
+
+func (e *MySqlEventProducer) Invalidations() <-chan events.Invalidation {
+	return e.invalidations
+}
+
+func (e *MySqlEventProducer) fetchAll() (fetchErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.CacheInitTimeout)
+	defer cancel()
+
+	startTime := e.time.Now().UTC()
+	rows, err := e.cfg.DB.QueryContext(ctx, e.cfg.CacheInitQuery)
+	elapsedTime := time.Since(startTime)
+	e.recordFetchTime(elapsedTime, metrics.FetchAll)
+
+	if err != nil {
+		glog.Warningf("Failed to fetch all Stored %s data from the DB: %v", e.cfg.RequestType, err)
+		e.recordQueryError(err)
+		return err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			glog.Warningf("Failed to close the Stored %s DB connection: %v", e.cfg.RequestType, err)
+			e.recordError(metrics.StoredDataErrorUndefined)
+			fetchErr = err
+		}
+	}()
+	if err := e.sendEvents(rows); err != nil {
+		glog.Warningf("Failed to load all Stored %s data from the DB: %v", e.cfg.RequestType, err)
+		e.recordError(metrics.StoredDataErrorUndefined)
+		return err
+	}
+
+	e.lastUpdate = startTime
+	return nil
+}
+
+func (e *MySqlEventProducer) fetchDelta() (fetchErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.CacheUpdateTimeout)
+	defer cancel()
+
+	args := make([]interface{}, e.cfg.CacheUpdateWildcards)
+	for i := range args {
+		args[i] = e.lastUpdate
+	}
+
+	startTime := e.time.Now().UTC()
+	rows, err := e.cfg.DB.QueryContext(ctx, e.cfg.CacheUpdateQuery, args...)
+	elapsedTime := time.Since(startTime)
+	e.recordFetchTime(elapsedTime, metrics.FetchDelta)
+
+	if err != nil {
+		glog.Warningf("Failed to fetch updated Stored %s data from the DB: %v", e.cfg.RequestType, err)
+		e.recordQueryError(err)
+		return err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			glog.Warningf("Failed to close the Stored %s DB connection: %v", e.cfg.RequestType, err)
+			e.recordError(metrics.StoredDataErrorUndefined)
+			fetchErr = err
+		}
+	}()
+	if err := e.sendEvents(rows); err != nil {
+		glog.Warningf("Failed to load updated Stored %s data from the DB: %v", e.cfg.RequestType, err)
+		e.recordError(metrics.StoredDataErrorUndefined)
+		return err
+	}
+
+	e.lastUpdate = startTime
+	return nil
+}
+
+func (e *MySqlEventProducer) recordFetchTime(elapsedTime time.Duration, fetchType metrics.StoredDataFetchType) {
+	e.cfg.MetricsEngine.RecordStoredDataFetchTime(
+		metrics.StoredDataLabels{
+			DataType:      storedDataTypeMetricMap[e.cfg.RequestType],
+			DataFetchType: fetchType,
+		}, elapsedTime)
+}
+
+func (e *MySqlEventProducer) recordQueryError(err error) {
+	if _, ok := err.(net.Error); ok {
+		e.recordError(metrics.StoredDataErrorNetwork)
+	} else {
+		e.recordError(metrics.StoredDataErrorUndefined)
+	}
+}
+
+func (e *MySqlEventProducer) recordError(errorType metrics.StoredDataError) {
+	e.cfg.MetricsEngine.RecordStoredDataError(
+		metrics.StoredDataLabels{
+			DataType: storedDataTypeMetricMap[e.cfg.RequestType],
+			Error:    errorType,
+		})
+}
+
+// sendEvents reads the rows and sends notifications into the channel for any updates.
+// If it returns an error, then callers can be certain that no events were sent to the channels.
+func (e *MySqlEventProducer) sendEvents(rows *sql.Rows) (err error) {
+	save := events.Save{
+		Requests: make(map[string]json.RawMessage),
+		Imps:     make(map[string]json.RawMessage),
+		Accounts: make(map[string]json.RawMessage),
+	}
+	var invalidation events.Invalidation
+
+	for rows.Next() {
+		var id string
+		var data []byte
+		var dataType string
+
+		// discard corrupted data so it is not saved in the cache
+		if err := rows.Scan(&id, &data, &dataType); err != nil {
+			return err
+		}
+
+		deleted := len(data) == 0 || bytes.Equal(data, bytesNull())
+		switch dataType {
+		case "request":
+			if deleted {
+				invalidation.Requests = append(invalidation.Requests, id)
+			} else {
+				save.Requests[id] = data
+			}
+		case "imp":
+			if deleted {
+				invalidation.Imps = append(invalidation.Imps, id)
+			} else {
+				save.Imps[id] = data
+			}
+		case "account":
+			if deleted {
+				invalidation.Accounts = append(invalidation.Accounts, id)
+			} else {
+				save.Accounts[id] = data
+			}
+		default:
+			glog.Warningf("Stored Data with id=%s has invalid type: %s. This will be ignored.", id, dataType)
+		}
+	}
+
+	// discard corrupted data so it is not saved in the cache
+	if rows.Err() != nil {
+		return rows.Err()
+	}
+
+	if len(save.Requests) > 0 || len(save.Imps) > 0 || len(save.Accounts) > 0 {
+		e.saves <- save
+	}
+
+	invalidated := len(invalidation.Requests) > 0 || len(invalidation.Imps) > 0 || len(invalidation.Accounts) > 0
+	if invalidated && !e.lastUpdate.IsZero() {
+		e.invalidations <- invalidation
+	}
+
+	return
+}
@@ -0,0 +1,219 @@
+package mysql
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/stored_requests/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+// FakeTime implements the Time interface
+type FakeTime struct {
+	time time.Time
+}
+
+func (mc *FakeTime) Now() time.Time {
+	return mc.time
+}
+
+const fakeQuery = "SELECT id, requestData, type FROM stored_data"
+const fakeUpdateQuery = "SELECT id, requestData, type FROM stored_data WHERE last_updated > ? UNION ALL SELECT id, impData, type FROM stored_imps WHERE last_updated > ?"
+
+func queryRegex(query string) string {
+	return "^" + regexp.QuoteMeta(query) + "$"
+}
+
+func TestFetchAllSuccess(t *testing.T) {
+	tests := []struct {
+		description      string
+		giveMockRows     *sqlmock.Rows
+		wantSave         events.Save
+		wantInvalidation events.Invalidation
+	}{
+		{
+			description:  "no rows",
+			giveMockRows: sqlmock.NewRows([]string{"id", "data", "dataType"}),
+		},
+		{
+			description: "saved reqs, imps and accounts, deleted rows ignored",
+			giveMockRows: sqlmock.NewRows([]string{"id", "data", "dataType"}).
+				AddRow("req-1", "true", "request").
+				AddRow("imp-1", "true", "imp").
+				AddRow("account-1", `{"disabled":false}`, "account").
+				AddRow("req-2", "", "request").
+				AddRow("account-2", "null", "account").
+				AddRow("other-1", "true", "other"),
+			wantSave: events.Save{
+				Requests: map[string]json.RawMessage{"req-1": json.RawMessage(`true`)},
+				Imps:     map[string]json.RawMessage{"imp-1": json.RawMessage(`true`)},
+				Accounts: map[string]json.RawMessage{"account-1": json.RawMessage(`{"disabled":false}`)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		fakeTime := time.Date(2020, time.July, 1, 12, 30, 0, 0, time.UTC)
+		db, dbMock, _ := sqlmock.New()
+		dbMock.ExpectQuery(queryRegex(fakeQuery)).WillReturnRows(tt.giveMockRows)
+
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.Mock.On("RecordStoredDataFetchTime", metrics.StoredDataLabels{
+			DataType:      metrics.RequestDataType,
+			DataFetchType: metrics.FetchAll,
+		}, mock.Anything).Return()
+
+		eventProducer := NewMySqlEventProducer(MySqlEventProducerConfig{
+			DB:               db,
+			RequestType:      config.RequestDataType,
+			CacheInitTimeout: 100 * time.Millisecond,
+			CacheInitQuery:   fakeQuery,
+			MetricsEngine:    metricsMock,
+		})
+		eventProducer.time = &FakeTime{time: fakeTime}
+		err := eventProducer.Run()
+
+		assert.Nil(t, err, tt.description)
+		assert.Equal(t, fakeTime, eventProducer.lastUpdate, tt.description)
+		assertEvents(t, eventProducer, tt.wantSave, tt.wantInvalidation, tt.description)
+		metricsMock.AssertExpectations(t)
+	}
+}
+
+func TestFetchAllErrors(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	dbMock.ExpectQuery(queryRegex(fakeQuery)).WillReturnError(errors.New("Query failed."))
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.Mock.On("RecordStoredDataFetchTime", metrics.StoredDataLabels{
+		DataType:      metrics.AccountDataType,
+		DataFetchType: metrics.FetchAll,
+	}, mock.Anything).Return()
+	metricsMock.Mock.On("RecordStoredDataError", metrics.StoredDataLabels{
+		DataType: metrics.AccountDataType,
+		Error:    metrics.StoredDataErrorUndefined,
+	}).Return()
+
+	eventProducer := NewMySqlEventProducer(MySqlEventProducerConfig{
+		DB:               db,
+		RequestType:      config.AccountDataType,
+		CacheInitTimeout: 100 * time.Millisecond,
+		CacheInitQuery:   fakeQuery,
+		MetricsEngine:    metricsMock,
+	})
+	err := eventProducer.Run()
+
+	assert.EqualError(t, err, "Query failed.")
+	assert.True(t, eventProducer.lastUpdate.IsZero())
+	metricsMock.AssertExpectations(t)
+}
+
+func TestFetchDeltaSuccess(t *testing.T) {
+	lastUpdate := time.Date(2020, time.July, 1, 12, 30, 0, 0, time.UTC)
+	fakeTime := time.Date(2020, time.July, 1, 12, 31, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	dbMock.ExpectQuery(queryRegex(fakeUpdateQuery)).
+		WithArgs(lastUpdate, lastUpdate).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data", "dataType"}).
+			AddRow("req-1", "true", "request").
+			AddRow("imp-1", "", "imp").
+			AddRow("account-1", "null", "account"))
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.Mock.On("RecordStoredDataFetchTime", metrics.StoredDataLabels{
+		DataType:      metrics.RequestDataType,
+		DataFetchType: metrics.FetchDelta,
+	}, mock.Anything).Return()
+
+	eventProducer := NewMySqlEventProducer(MySqlEventProducerConfig{
+		DB:                   db,
+		RequestType:          config.RequestDataType,
+		CacheUpdateTimeout:   100 * time.Millisecond,
+		CacheUpdateQuery:     fakeUpdateQuery,
+		CacheUpdateWildcards: 2,
+		MetricsEngine:        metricsMock,
+	})
+	eventProducer.lastUpdate = lastUpdate
+	eventProducer.time = &FakeTime{time: fakeTime}
+	err := eventProducer.Run()
+
+	assert.Nil(t, err)
+	assert.Equal(t, fakeTime, eventProducer.lastUpdate)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	assertEvents(t, eventProducer,
+		events.Save{
+			Requests: map[string]json.RawMessage{"req-1": json.RawMessage(`true`)},
+			Imps:     map[string]json.RawMessage{},
+			Accounts: map[string]json.RawMessage{},
+		},
+		events.Invalidation{
+			Imps:     []string{"imp-1"},
+			Accounts: []string{"account-1"},
+		}, "fetch delta")
+	metricsMock.AssertExpectations(t)
+}
+
+func TestFetchDeltaErrors(t *testing.T) {
+	lastUpdate := time.Date(2020, time.July, 1, 12, 30, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	dbMock.ExpectQuery(queryRegex(fakeUpdateQuery)).
+		WithArgs(lastUpdate, lastUpdate).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data", "dataType"}).
+			AddRow("req-1", "true", "request").
+			RowError(0, errors.New("Some row error.")))
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.Mock.On("RecordStoredDataFetchTime", metrics.StoredDataLabels{
+		DataType:      metrics.RequestDataType,
+		DataFetchType: metrics.FetchDelta,
+	}, mock.Anything).Return()
+	metricsMock.Mock.On("RecordStoredDataError", metrics.StoredDataLabels{
+		DataType: metrics.RequestDataType,
+		Error:    metrics.StoredDataErrorUndefined,
+	}).Return()
+
+	eventProducer := NewMySqlEventProducer(MySqlEventProducerConfig{
+		DB:                   db,
+		RequestType:          config.RequestDataType,
+		CacheUpdateTimeout:   100 * time.Millisecond,
+		CacheUpdateQuery:     fakeUpdateQuery,
+		CacheUpdateWildcards: 2,
+		MetricsEngine:        metricsMock,
+	})
+	eventProducer.lastUpdate = lastUpdate
+	err := eventProducer.Run()
+
+	assert.Error(t, err)
+	assert.Equal(t, lastUpdate, eventProducer.lastUpdate)
+	metricsMock.AssertExpectations(t)
+}
+
+func assertEvents(t *testing.T, eventProducer *MySqlEventProducer, wantSave events.Save, wantInvalidation events.Invalidation, description string) {
+	t.Helper()
+
+	var save events.Save
+	// Read data from saves channel with timeout to avoid test suite deadlock
+	select {
+	case save = <-eventProducer.Saves():
+	case <-time.After(20 * time.Millisecond):
+	}
+	var invalidation events.Invalidation
+	// Read data from invalidations channel with timeout to avoid test suite deadlock
+	select {
+	case invalidation = <-eventProducer.Invalidations():
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	assert.Equal(t, wantSave, save, description+":saves")
+	assert.Equal(t, wantInvalidation, invalidation, description+":invalidations")
+}
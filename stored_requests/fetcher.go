@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/prebid/prebid-server/metrics"
 )
@@ -23,6 +24,12 @@ type Fetcher interface {
 	//
 	// The returned objects can only be read from. They may not be written to.
 	FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error)
+
+	// FetchResponses fetches the stored responses for the given IDs.
+	//
+	// The returned map will have a key for every ID in the ids list, unless errors exist.
+	// The returned objects can only be read from. They may not be written to.
+	FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error)
 }
 
 type AccountFetcher interface {
@@ -88,6 +95,27 @@ type CacheJSON interface {
 	Save(ctx context.Context, data map[string]json.RawMessage)
 }
 
+// CacheIDLister is implemented by the caches which can list the IDs of the values they hold.
+// Caches which don't implement it are treated as empty when listing.
+type CacheIDLister interface {
+	// IDs returns the IDs of all the values held by the cache, in no particular order.
+	IDs() []string
+}
+
+// ListCachedIDs returns the sorted IDs of all the values held by the cache, if it implements CacheIDLister.
+func ListCachedIDs(cache CacheJSON) []string {
+	lister, ok := cache.(CacheIDLister)
+	if !ok {
+		return []string{}
+	}
+	ids := lister.IDs()
+	if ids == nil {
+		ids = []string{}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // ComposedCache creates an interface to treat a slice of caches as a single cache
 type ComposedCache []CacheJSON
 
@@ -143,6 +171,69 @@ func (c ComposedCache) Save(ctx context.Context, data map[string]json.RawMessage
 	}
 }
 
+// IDs returns the IDs held by any of the underlying caches which implement CacheIDLister
+func (c ComposedCache) IDs() []string {
+	seen := make(map[string]struct{})
+	ids := make([]string, 0)
+	for _, cache := range c {
+		if lister, ok := cache.(CacheIDLister); ok {
+			for _, id := range lister.IDs() {
+				if _, ok := seen[id]; !ok {
+					seen[id] = struct{}{}
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// TieredCache treats a slice of caches as a single cache, ordered from the fastest to the slowest tier.
+// Unlike ComposedCache, values found in a slower tier are saved into the faster tiers in front of it,
+// so that the next Get is served by the fastest one.
+type TieredCache []CacheJSON
+
+// Get will attempt to Get from the caches in order, stopping as soon as all values are found.
+// Values found in a tier are saved into all tiers before it.
+func (c TieredCache) Get(ctx context.Context, ids []string) (data map[string]json.RawMessage) {
+	data = make(map[string]json.RawMessage, len(ids))
+
+	remainingIDs := ids
+
+	for i, cache := range c {
+		cachedData := cache.Get(ctx, remainingIDs)
+		data, remainingIDs = updateFromCache(data, remainingIDs, cachedData)
+
+		if i > 0 && len(cachedData) > 0 {
+			for _, fasterCache := range c[:i] {
+				fasterCache.Save(ctx, cachedData)
+			}
+		}
+
+		// finish early if all ids filled
+		if len(remainingIDs) == 0 {
+			break
+		}
+	}
+
+	return
+}
+
+// Invalidate will propagate invalidations to all underlying caches
+func (c TieredCache) Invalidate(ctx context.Context, ids []string) {
+	ComposedCache(c).Invalidate(ctx, ids)
+}
+
+// Save will propagate saves to all underlying caches
+func (c TieredCache) Save(ctx context.Context, data map[string]json.RawMessage) {
+	ComposedCache(c).Save(ctx, data)
+}
+
+// IDs returns the IDs held by any of the underlying caches which implement CacheIDLister
+func (c TieredCache) IDs() []string {
+	return ComposedCache(c).IDs()
+}
+
 type fetcherWithCache struct {
 	fetcher       AllFetcher
 	cache         Cache
@@ -191,6 +282,11 @@ func (f *fetcherWithCache) FetchRequests(ctx context.Context, requestIDs []strin
 	return
 }
 
+// FetchResponses bypasses the caches, the stored responses are always retrieved from the backing Fetcher.
+func (f *fetcherWithCache) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return f.fetcher.FetchResponses(ctx, ids)
+}
+
 func (f *fetcherWithCache) FetchAccount(ctx context.Context, accountID string) (account json.RawMessage, errs []error) {
 	accountData := f.cache.Accounts.Get(ctx, []string{accountID})
 	// TODO: add metrics
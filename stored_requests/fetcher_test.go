@@ -263,6 +263,64 @@ func TestComposedCache(t *testing.T) {
 	assert.JSONEq(t, `{"id": "3"}`, string(reqData["3"]), "FetchRequests should fetch the right req data")
 }
 
+func TestTieredCache(t *testing.T) {
+	c1 := &mockCache{}
+	c2 := &mockCache{}
+	c3 := &mockCache{}
+	cache := TieredCache{c1, c2, c3}
+	ids := []string{"1", "2", "3"}
+	ctx := context.Background()
+
+	c1.On("Get", ctx, ids).Return(
+		map[string]json.RawMessage{
+			"1": json.RawMessage(`{"id": "1"}`),
+		})
+	c2.On("Get", ctx, []string{"2", "3"}).Return(
+		map[string]json.RawMessage{
+			"2": json.RawMessage(`{"id": "2"}`),
+		})
+	c1.On("Save", ctx, map[string]json.RawMessage{"2": json.RawMessage(`{"id": "2"}`)})
+	c3.On("Get", ctx, []string{"3"}).Return(map[string]json.RawMessage{})
+
+	data := cache.Get(ctx, ids)
+
+	c1.AssertExpectations(t)
+	c2.AssertExpectations(t)
+	c3.AssertExpectations(t)
+	c2.AssertNotCalled(t, "Save")
+	assert.Len(t, data, 2, "Get should return the data found in all tiers")
+	assert.JSONEq(t, `{"id": "1"}`, string(data["1"]), "Get should return the data from the first tier")
+	assert.JSONEq(t, `{"id": "2"}`, string(data["2"]), "Get should return the data from the second tier")
+}
+
+func TestComposedCacheIDs(t *testing.T) {
+	c1 := &listableCache{ids: []string{"b", "a"}}
+	c2 := &mockCache{}
+	c3 := &listableCache{ids: []string{"c", "a"}}
+
+	assert.Equal(t, []string{"a", "b", "c"}, ListCachedIDs(ComposedCache{c1, c2, c3}), "IDs should be listed from the caches which support it")
+	assert.Equal(t, []string{"a", "b", "c"}, ListCachedIDs(TieredCache{c1, c2, c3}), "IDs should be listed from the caches which support it")
+	assert.Equal(t, []string{}, ListCachedIDs(c2), "Caches which can't list their IDs should be treated as empty")
+}
+
+func TestFetchResponsesBypassesCache(t *testing.T) {
+	fetcher := &mockFetcher{}
+	cache := &mockCache{}
+	metricsEngine := &metrics.MetricsEngineMock{}
+	composedFetcher := WithCache(fetcher, Cache{Requests: cache, Imps: cache, Accounts: cache}, metricsEngine)
+	ctx := context.Background()
+
+	fetcher.On("FetchResponses", ctx, []string{"resp-1"}).Return(map[string]json.RawMessage{"resp-1": json.RawMessage(`{"id": "resp-1"}`)}, []error{})
+
+	respData, errs := composedFetcher.FetchResponses(ctx, []string{"resp-1"})
+
+	fetcher.AssertExpectations(t)
+	cache.AssertNotCalled(t, "Get")
+	cache.AssertNotCalled(t, "Save")
+	assert.Empty(t, errs, "FetchResponses shouldn't return an error")
+	assert.JSONEq(t, `{"id": "resp-1"}`, string(respData["resp-1"]), "FetchResponses should fetch the right response data")
+}
+
 type mockFetcher struct {
 	mock.Mock
 }
@@ -272,6 +330,11 @@ func (f *mockFetcher) FetchRequests(ctx context.Context, requestIDs []string, im
 	return args.Get(0).(map[string]json.RawMessage), args.Get(1).(map[string]json.RawMessage), args.Get(2).([]error)
 }
 
+func (f *mockFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	args := f.Called(ctx, ids)
+	return args.Get(0).(map[string]json.RawMessage), args.Get(1).([]error)
+}
+
 func (a *mockFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
 	args := a.Called(ctx, accountID)
 	return args.Get(0).(json.RawMessage), args.Get(1).([]error)
@@ -297,3 +360,12 @@ func (c *mockCache) Save(ctx context.Context, data map[string]json.RawMessage) {
 func (c *mockCache) Invalidate(ctx context.Context, ids []string) {
 	c.Called(ctx, ids)
 }
+
+type listableCache struct {
+	mockCache
+	ids []string
+}
+
+func (c *listableCache) IDs() []string {
+	return c.ids
+}
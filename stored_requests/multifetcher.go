@@ -36,6 +36,26 @@ func (mf MultiFetcher) FetchRequests(ctx context.Context, requestIDs []string, i
 	return
 }
 
+// FetchResponses implements the Fetcher interface for MultiFetcher
+func (mf MultiFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	data = make(map[string]json.RawMessage, len(ids))
+
+	for _, f := range mf {
+		remainingIDs := filter(ids, data)
+		ids = remainingIDs
+
+		theseData, rerrs := f.FetchResponses(ctx, remainingIDs)
+		// Drop NotFound errors, as other fetchers may have them. Also don't want multiple NotFound errors per ID.
+		rerrs = dropMissingIDs(rerrs)
+		if len(rerrs) > 0 {
+			errs = append(errs, rerrs...)
+		}
+		addAll(data, theseData)
+	}
+	errs = appendNotFoundErrors("Response", ids, data, errs)
+	return
+}
+
 func (mf MultiFetcher) FetchAccount(ctx context.Context, accountID string) (account json.RawMessage, errs []error) {
 	for _, f := range mf {
 		if af, ok := f.(AccountFetcher); ok {
@@ -176,3 +176,32 @@ func TestMultiFetcherAccountNotFound(t *testing.T) {
 	assert.Nil(t, account)
 	assert.EqualError(t, errs[0], NotFoundError{"MISSING", "Account"}.Error())
 }
+
+func TestMultiFetcherResponses(t *testing.T) {
+	f1 := &mockFetcher{}
+	f2 := &mockFetcher{}
+	fetcher := &MultiFetcher{f1, f2}
+	ctx := context.Background()
+
+	f1.On("FetchResponses", ctx, []string{"resp-1", "resp-2", "resp-3"}).Return(
+		map[string]json.RawMessage{
+			"resp-1": json.RawMessage(`{"id": "resp-1"}`),
+		},
+		[]error{NotFoundError{ID: "resp-2", DataType: "Response"}, NotFoundError{ID: "resp-3", DataType: "Response"}},
+	)
+	f2.On("FetchResponses", ctx, []string{"resp-2", "resp-3"}).Return(
+		map[string]json.RawMessage{
+			"resp-2": json.RawMessage(`{"id": "resp-2"}`),
+		},
+		[]error{NotFoundError{ID: "resp-3", DataType: "Response"}},
+	)
+
+	respData, errs := fetcher.FetchResponses(ctx, []string{"resp-1", "resp-2", "resp-3"})
+
+	f1.AssertExpectations(t)
+	f2.AssertExpectations(t)
+	assert.Len(t, respData, 2, "MultiFetcher should return all the requested stored responses that exist")
+	assert.Equal(t, []error{NotFoundError{ID: "resp-3", DataType: "Response"}}, errs, "MultiFetcher should return a single NotFoundError for the missing stored response")
+	assert.JSONEq(t, `{"id": "resp-1"}`, string(respData["resp-1"]), "MultiFetcher should return the right response data")
+	assert.JSONEq(t, `{"id": "resp-2"}`, string(respData["resp-2"]), "MultiFetcher should return the right response data")
+}
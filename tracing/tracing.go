@@ -0,0 +1,199 @@
+// Package tracing records the spans of the auction requests with the OpenTelemetry SDK, and exports them to
+// an OpenTelemetry collector.
+//
+// The spans are carried by the context. StartSpan creates a child of the span found in the context, and returns
+// a non-recording span if there's none, e.g. if the request isn't sampled, so the instrumented code doesn't need
+// to know whether tracing is enabled.
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/prebid/prebid-server"
+
+// Tracer starts the root spans of the requests
+type Tracer struct {
+	provider   *sdktrace.TracerProvider
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracer creates a Tracer which exports the spans of the sampled requests to the configured collector.
+func NewTracer(cfg config.Tracing) (*Tracer, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("tracing.endpoint is invalid: %v", err)
+	}
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(endpoint.Host),
+		otlptracehttp.WithURLPath(endpoint.Path),
+		otlptracehttp.WithTimeout(time.Duration(cfg.TimeoutMs) * time.Millisecond),
+	}
+	if endpoint.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the tracing exporter: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter,
+			sdktrace.WithMaxQueueSize(cfg.QueueSize),
+			sdktrace.WithMaxExportBatchSize(cfg.BatchSize),
+			sdktrace.WithBatchTimeout(time.Duration(cfg.FlushIntervalMs)*time.Millisecond),
+			sdktrace.WithExportTimeout(time.Duration(cfg.TimeoutMs)*time.Millisecond)),
+		sdktrace.WithSampler(newSampler(cfg.SamplingRatio)),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String(cfg.ServiceName))),
+	)
+	return newTracer(provider), nil
+}
+
+func newTracer(provider *sdktrace.TracerProvider) *Tracer {
+	return &Tracer{
+		provider:   provider,
+		tracer:     provider.Tracer(instrumentationName),
+		propagator: propagation.TraceContext{},
+	}
+}
+
+// newSampler samples the requests which don't come with a W3C trace context by their trace ID, so that every
+// service sampling with the same ratio keeps the same traces. The sampling decision of the caller is honoured.
+func newSampler(ratio float64) sdktrace.Sampler {
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+}
+
+// Shutdown exports the spans which are still queued. The spans ended afterwards are dropped.
+func (t *Tracer) Shutdown() {
+	if t == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	t.provider.Shutdown(ctx)
+}
+
+// Handle wraps the handle of the given route with a root span, which continues the trace of the caller
+// if the request has a valid W3C traceparent header. A nil Tracer returns the handle as is.
+func (t *Tracer) Handle(route string, handle httprouter.Handle) httprouter.Handle {
+	if t == nil {
+		return handle
+	}
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		ctx := t.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := t.tracer.Start(ctx, route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPMethodKey.String(r.Method), semconv.HTTPRouteKey.String(route)))
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handle(recorder, r.WithContext(ctx), ps)
+
+		SetHTTPStatus(span, trace.SpanKindServer, recorder.status)
+	}
+}
+
+// StartSpan starts a child of the span carried by the context, with the tracer which started it. If the context
+// has no span, the returned span doesn't record anything. The span must be ended by the caller.
+func StartSpan(ctx context.Context, name string, kind trace.SpanKind) (context.Context, trace.Span) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(instrumentationName)
+	return tracer.Start(ctx, name, trace.WithSpanKind(kind))
+}
+
+// RecordError marks the span as failed, and records the error as an exception event. A nil error is ignored.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// SetHTTPStatus records the status code of an HTTP response. The 5xx codes mark the span as failed, as well as
+// the 4xx codes unless the span is the server one.
+func SetHTTPStatus(span trace.Span, kind trace.SpanKind, status int) {
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+	if code, message := semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, kind); code == codes.Error {
+		span.SetStatus(code, message)
+	}
+}
+
+// DetachedContext returns a background context which carries the span of ctx, but not its deadline or
+// cancellation. It's used by the endpoints, which don't cancel the auction when the client goes away.
+func DetachedContext(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// WithClientTrace returns a copy of the context which records the connection events of the HTTP requests made
+// with it as events of the span. The ClientTrace already in the context is still called.
+func WithClientTrace(ctx context.Context, span trace.Span) context.Context {
+	if !span.IsRecording() {
+		return ctx
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			span.AddEvent("get_conn", trace.WithAttributes(attribute.String("net.peer", hostPort)))
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			span.AddEvent("got_conn", trace.WithAttributes(attribute.Bool("reused", info.Reused)))
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			span.AddEvent("dns_start")
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			span.AddEvent("dns_done")
+		},
+		ConnectDone: func(network, addr string, err error) {
+			span.AddEvent("connect_done", trace.WithAttributes(attribute.String("net.peer", addr)))
+		},
+		TLSHandshakeStart: func() {
+			span.AddEvent("tls_handshake_start")
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			span.AddEvent("tls_handshake_done")
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			span.AddEvent("wrote_request")
+		},
+		GotFirstResponseByte: func() {
+			span.AddEvent("got_first_response_byte")
+		},
+	})
+}
@@ -0,0 +1,244 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewTracer(t *testing.T) {
+	testCases := []struct {
+		description string
+		endpoint    string
+		expectedErr bool
+	}{
+		{description: "http", endpoint: "http://localhost:4318/v1/traces"},
+		{description: "https", endpoint: "https://otel-collector/v1/traces"},
+		{description: "invalid", endpoint: "http://local host:4318", expectedErr: true},
+	}
+
+	for _, test := range testCases {
+		tracer, err := NewTracer(config.Tracing{
+			Endpoint:        test.endpoint,
+			ServiceName:     "prebid-server",
+			SamplingRatio:   1,
+			QueueSize:       10,
+			BatchSize:       10,
+			FlushIntervalMs: 1000,
+			TimeoutMs:       1000,
+		})
+		if test.expectedErr {
+			assert.Error(t, err, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.NotNil(t, tracer, test.description)
+		tracer.provider.Shutdown(context.Background())
+	}
+}
+
+func TestHandle(t *testing.T) {
+	testCases := []struct {
+		description          string
+		samplingRatio        float64
+		traceParent          string
+		status               int
+		expectedSpans        int
+		expectedTraceID      string
+		expectedParentSpanID string
+		expectedStatus       codes.Code
+	}{
+		{
+			description:   "sampled",
+			samplingRatio: 1,
+			status:        http.StatusOK,
+			expectedSpans: 1,
+		},
+		{
+			description:   "not sampled",
+			samplingRatio: 0,
+			status:        http.StatusOK,
+		},
+		{
+			description:          "sampled by the caller",
+			samplingRatio:        0,
+			traceParent:          "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			status:               http.StatusOK,
+			expectedSpans:        1,
+			expectedTraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedParentSpanID: "00f067aa0ba902b7",
+		},
+		{
+			description:   "not sampled by the caller",
+			samplingRatio: 1,
+			traceParent:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			status:        http.StatusOK,
+		},
+		{
+			description:   "invalid traceparent",
+			samplingRatio: 1,
+			traceParent:   "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			status:        http.StatusOK,
+			expectedSpans: 1,
+		},
+		{
+			description:    "server error",
+			samplingRatio:  1,
+			status:         http.StatusInternalServerError,
+			expectedSpans:  1,
+			expectedStatus: codes.Error,
+		},
+		{
+			description:   "bad request",
+			samplingRatio: 1,
+			status:        http.StatusBadRequest,
+			expectedSpans: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		recorder := tracetest.NewSpanRecorder()
+		tracer := newTracer(sdktrace.NewTracerProvider(sdktrace.WithSampler(newSampler(test.samplingRatio)), sdktrace.WithSpanProcessor(recorder)))
+
+		handle := tracer.Handle("/openrtb2/auction", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+			_, childSpan := StartSpan(DetachedContext(r.Context()), "child", trace.SpanKindInternal)
+			childSpan.End()
+			w.WriteHeader(test.status)
+		})
+
+		request := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+		if test.traceParent != "" {
+			request.Header.Set("traceparent", test.traceParent)
+		}
+		response := httptest.NewRecorder()
+		handle(response, request, nil)
+
+		assert.Equal(t, test.status, response.Code, test.description)
+		spans := recorder.Ended()
+		if test.expectedSpans == 0 {
+			assert.Empty(t, spans, test.description)
+			continue
+		}
+
+		if !assert.Len(t, spans, 2, test.description) {
+			continue
+		}
+		child, root := spans[0], spans[1]
+		assert.Equal(t, "/openrtb2/auction", root.Name(), test.description)
+		assert.Equal(t, trace.SpanKindServer, root.SpanKind(), test.description)
+		assert.Contains(t, root.Attributes(), semconv.HTTPMethodKey.String("POST"), test.description)
+		assert.Contains(t, root.Attributes(), semconv.HTTPStatusCodeKey.Int(test.status), test.description)
+		assert.Equal(t, test.expectedStatus, root.Status().Code, test.description)
+		if test.expectedTraceID != "" {
+			assert.Equal(t, test.expectedTraceID, root.SpanContext().TraceID().String(), test.description)
+			assert.Equal(t, test.expectedParentSpanID, root.Parent().SpanID().String(), test.description)
+		} else {
+			assert.False(t, root.Parent().IsValid(), test.description)
+		}
+		assert.Equal(t, root.SpanContext().TraceID(), child.SpanContext().TraceID(), test.description)
+		assert.Equal(t, root.SpanContext().SpanID(), child.Parent().SpanID(), test.description)
+	}
+}
+
+func TestNilTracerHandle(t *testing.T) {
+	var tracer *Tracer
+	called := false
+	handle := tracer.Handle("/openrtb2/auction", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		called = true
+	})
+	handle(httptest.NewRecorder(), httptest.NewRequest("POST", "/openrtb2/auction", nil), nil)
+	assert.True(t, called)
+}
+
+func TestStartSpanWithoutParent(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), "no parent", trace.SpanKindInternal)
+
+	assert.False(t, span.IsRecording())
+	assert.NotPanics(t, func() {
+		RecordError(span, errors.New("failure"))
+		SetHTTPStatus(span, trace.SpanKindClient, http.StatusOK)
+		span.End()
+	})
+	assert.Equal(t, ctx, WithClientTrace(ctx, span))
+}
+
+func TestSpanStatus(t *testing.T) {
+	testCases := []struct {
+		description     string
+		kind            trace.SpanKind
+		status          int
+		err             error
+		expectedStatus  codes.Code
+		expectedMessage string
+		expectedEvents  int
+	}{
+		{description: "client success", kind: trace.SpanKindClient, status: http.StatusNoContent, expectedStatus: codes.Unset},
+		{description: "client bad request", kind: trace.SpanKindClient, status: http.StatusBadRequest, expectedStatus: codes.Error},
+		{description: "server bad request", kind: trace.SpanKindServer, status: http.StatusBadRequest, expectedStatus: codes.Unset},
+		{description: "server error", kind: trace.SpanKindServer, status: http.StatusBadGateway, expectedStatus: codes.Error},
+		{description: "error", kind: trace.SpanKindClient, status: http.StatusOK, err: errors.New("timeout"), expectedStatus: codes.Error, expectedMessage: "timeout", expectedEvents: 1},
+	}
+
+	for _, test := range testCases {
+		recorder := tracetest.NewSpanRecorder()
+		ctx, root := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "root")
+
+		_, span := StartSpan(ctx, "bidder.request", test.kind)
+		span.SetAttributes(attribute.String("bidder", "appnexus"))
+		SetHTTPStatus(span, test.kind, test.status)
+		RecordError(span, test.err)
+		span.End()
+		root.End()
+
+		spans := recorder.Ended()
+		if !assert.Len(t, spans, 2, test.description) {
+			continue
+		}
+		assert.Equal(t, test.kind, spans[0].SpanKind(), test.description)
+		assert.Contains(t, spans[0].Attributes(), attribute.String("bidder", "appnexus"), test.description)
+		assert.Contains(t, spans[0].Attributes(), semconv.HTTPStatusCodeKey.Int(test.status), test.description)
+		assert.Equal(t, test.expectedStatus, spans[0].Status().Code, test.description)
+		assert.Equal(t, test.expectedMessage, spans[0].Status().Description, test.description)
+		assert.Len(t, spans[0].Events(), test.expectedEvents, test.description)
+	}
+}
+
+func TestWithClientTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	ctx, root := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "root")
+	ctx, span := StartSpan(ctx, "client", trace.SpanKindClient)
+
+	request, _ := http.NewRequest("GET", server.URL, nil)
+	response, err := http.DefaultClient.Do(request.WithContext(WithClientTrace(ctx, span)))
+	if err != nil {
+		t.Fatalf("Failed to call the test server: %v", err)
+	}
+	response.Body.Close()
+	span.End()
+	root.End()
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	eventNames := make([]string, 0, len(spans[0].Events()))
+	for _, event := range spans[0].Events() {
+		eventNames = append(eventNames, event.Name)
+	}
+	assert.Subset(t, eventNames, []string{"get_conn", "got_conn", "wrote_request", "got_first_response_byte"})
+}
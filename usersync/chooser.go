@@ -1,5 +1,7 @@
 package usersync
 
+import "github.com/prebid/prebid-server/config"
+
 // Chooser determines which syncers are eligible for a given request.
 type Chooser interface {
 	// Choose considers bidders to sync, filters the bidders, and returns the result of the
@@ -26,8 +28,39 @@ type Request struct {
 	Bidders        []string
 	Cooperative    Cooperative
 	Limit          int
+	Limits         Limits
 	Privacy        Privacy
 	SyncTypeFilter SyncTypeFilter
+	UIDEviction    UIDEviction
+}
+
+// Limits specifies the account settings for the number of syncers chosen. The Default applies when the request has
+// no limit, and the Max caps the limit of every request. A value <= 0 means no limit.
+type Limits struct {
+	Default int
+	Max     int
+}
+
+// limit returns the maximum number of syncers to choose for the request, or 0 if there's no limit.
+func (r Request) limit() int {
+	limit := r.Limit
+	if limit <= 0 {
+		limit = r.Limits.Default
+	}
+	if r.Limits.Max > 0 && (limit <= 0 || limit > r.Limits.Max) {
+		limit = r.Limits.Max
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// UIDEviction specifies how the UIDs are evicted from a cookie larger than the HostCookie.MaxCookieSizeBytes. The
+// bidders whose UID would be evicted as soon as it's saved aren't synced. It's disabled if HostCookie is nil.
+type UIDEviction struct {
+	HostCookie *config.HostCookie
+	Priority   UIDPriority
 }
 
 // Cooperative specifies the settings for cooperative syncing for a given request, where bidders
@@ -85,6 +118,13 @@ const (
 
 	// StatusDuplicate specifies the bidder is a duplicate or shared a syncer key with another bidder choice.
 	StatusDuplicate
+
+	// StatusBlockedByPrivacy specifies the account activity controls forbid bidder syncing.
+	StatusBlockedByPrivacy
+
+	// StatusBlockedByCookieSize specifies the cookie is full of UIDs with a higher priority than the bidder's, so
+	// its UID would be evicted as soon as it's saved.
+	StatusBlockedByCookieSize
 )
 
 // Privacy determines which privacy policies will be enforced for a user sync request.
@@ -92,6 +132,7 @@ type Privacy interface {
 	GDPRAllowsHostCookie() bool
 	GDPRAllowsBidderSync(bidder string) bool
 	CCPAAllowsBidderSync(bidder string) bool
+	ActivityAllowsUserSync(bidder string) bool
 }
 
 // standardChooser implements the user syncer algorithm per official Prebid specification.
@@ -113,14 +154,15 @@ func (c standardChooser) Choose(request Request, cookie *Cookie) Result {
 	}
 
 	syncersSeen := make(map[string]struct{})
-	limitDisabled := request.Limit <= 0
+	limit := request.limit()
+	limitDisabled := limit <= 0
 
 	biddersEvaluated := make([]BidderEvaluation, 0)
 	syncersChosen := make([]SyncerChoice, 0)
 
 	bidders := c.bidderChooser.choose(request.Bidders, c.biddersAvailable, request.Cooperative)
-	for i := 0; i < len(bidders) && (limitDisabled || len(syncersChosen) < request.Limit); i++ {
-		syncer, evaluation := c.evaluate(bidders[i], syncersSeen, request.SyncTypeFilter, request.Privacy, cookie)
+	for i := 0; i < len(bidders) && (limitDisabled || len(syncersChosen) < limit); i++ {
+		syncer, evaluation := c.evaluate(bidders[i], syncersSeen, request.SyncTypeFilter, request.Privacy, request.UIDEviction, cookie)
 
 		biddersEvaluated = append(biddersEvaluated, evaluation)
 		if evaluation.Status == StatusOK {
@@ -131,7 +173,7 @@ func (c standardChooser) Choose(request Request, cookie *Cookie) Result {
 	return Result{Status: StatusOK, BiddersEvaluated: biddersEvaluated, SyncersChosen: syncersChosen}
 }
 
-func (c standardChooser) evaluate(bidder string, syncersSeen map[string]struct{}, syncTypeFilter SyncTypeFilter, privacy Privacy, cookie *Cookie) (Syncer, BidderEvaluation) {
+func (c standardChooser) evaluate(bidder string, syncersSeen map[string]struct{}, syncTypeFilter SyncTypeFilter, privacy Privacy, eviction UIDEviction, cookie *Cookie) (Syncer, BidderEvaluation) {
 	syncer, exists := c.bidderSyncerLookup[bidder]
 	if !exists {
 		return nil, BidderEvaluation{Bidder: bidder, Status: StatusUnknownBidder}
@@ -159,5 +201,13 @@ func (c standardChooser) evaluate(bidder string, syncersSeen map[string]struct{}
 		return nil, BidderEvaluation{Bidder: bidder, Status: StatusBlockedByCCPA}
 	}
 
+	if !privacy.ActivityAllowsUserSync(bidder) {
+		return nil, BidderEvaluation{Bidder: bidder, Status: StatusBlockedByPrivacy}
+	}
+
+	if !cookie.keepsNewUID(syncer.Key(), eviction.HostCookie, eviction.Priority) {
+		return nil, BidderEvaluation{Bidder: bidder, Status: StatusBlockedByCookieSize}
+	}
+
 	return syncer, BidderEvaluation{Bidder: bidder, Status: StatusOK}
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -66,7 +67,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "Cookie Opt Out",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"a"},
@@ -80,7 +81,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "GDPR Host Cookie Not Allowed",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: false, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: false, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"a"},
@@ -94,7 +95,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "No Bidders",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{},
@@ -108,7 +109,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "One Bidder - Sync",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"a"},
@@ -122,7 +123,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "One Bidder - No Sync",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"c"},
@@ -136,7 +137,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "Many Bidders - All Sync - Limit Disabled With 0",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"a", "b"},
@@ -150,7 +151,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "Many Bidders - All Sync - Limit Disabled With Negative Value",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   -1,
 			},
 			givenChosenBidders: []string{"a", "b"},
@@ -164,7 +165,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "Many Bidders - Limited Sync",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   1,
 			},
 			givenChosenBidders: []string{"a", "b"},
@@ -178,7 +179,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "Many Bidders - Limited Sync - Disqualified Syncers Don't Count Towards Limit",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   1,
 			},
 			givenChosenBidders: []string{"c", "a", "b"},
@@ -189,10 +190,40 @@ func TestChooserChoose(t *testing.T) {
 				SyncersChosen:    []SyncerChoice{syncerChoiceA},
 			},
 		},
+		{
+			description: "Many Bidders - Account Default Limit",
+			givenRequest: Request{
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
+				Limit:   0,
+				Limits:  Limits{Default: 1},
+			},
+			givenChosenBidders: []string{"a", "b"},
+			givenCookie:        Cookie{},
+			expected: Result{
+				Status:           StatusOK,
+				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", Status: StatusOK}},
+				SyncersChosen:    []SyncerChoice{syncerChoiceA},
+			},
+		},
+		{
+			description: "Many Bidders - Account Max Limit",
+			givenRequest: Request{
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
+				Limit:   2,
+				Limits:  Limits{Default: 2, Max: 1},
+			},
+			givenChosenBidders: []string{"a", "b"},
+			givenCookie:        Cookie{},
+			expected: Result{
+				Status:           StatusOK,
+				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", Status: StatusOK}},
+				SyncersChosen:    []SyncerChoice{syncerChoiceA},
+			},
+		},
 		{
 			description: "Many Bidders - Some Sync, Some Don't",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"a", "c"},
@@ -240,6 +271,8 @@ func TestChooserEvaluate(t *testing.T) {
 	cookieNeedsSync := Cookie{}
 	cookieAlreadyHasSyncForA := Cookie{uids: map[string]uidWithExpiry{"keyA": {Expires: time.Now().Add(time.Duration(24) * time.Hour)}}}
 	cookieAlreadyHasSyncForB := Cookie{uids: map[string]uidWithExpiry{"keyB": {Expires: time.Now().Add(time.Duration(24) * time.Hour)}}}
+	// room for the UID of one more bidder, but not for both
+	cookieFullWithB := &config.HostCookie{MaxCookieSizeBytes: len(cookieAlreadyHasSyncForB.ToHTTPCookie(time.Hour).String()) + 60}
 
 	testCases := []struct {
 		description      string
@@ -247,6 +280,7 @@ func TestChooserEvaluate(t *testing.T) {
 		givenSyncersSeen map[string]struct{}
 		givenPrivacy     Privacy
 		givenCookie      Cookie
+		givenEviction    UIDEviction
 		expectedSyncer   Syncer
 		expectedBidder   string
 		expectedStatus   Status
@@ -255,7 +289,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Valid",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   fakeSyncerA,
 			expectedBidder:   "a",
@@ -265,7 +299,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Unknown Bidder",
 			givenBidder:      "unknown",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   nil,
 			expectedBidder:   "unknown",
@@ -275,7 +309,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Duplicate Syncer",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{"keyA": {}},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   nil,
 			expectedBidder:   "a",
@@ -285,7 +319,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Incompatible Kind",
 			givenBidder:      "b",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   nil,
 			expectedBidder:   "b",
@@ -295,7 +329,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Already Synced",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieAlreadyHasSyncForA,
 			expectedSyncer:   nil,
 			expectedBidder:   "a",
@@ -305,7 +339,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Different Bidder Already Synced",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieAlreadyHasSyncForB,
 			expectedSyncer:   fakeSyncerA,
 			expectedBidder:   "a",
@@ -315,7 +349,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Blocked By GDPR",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: false, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: false, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   nil,
 			expectedBidder:   "a",
@@ -325,17 +359,49 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Blocked By CCPA",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: false},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: false, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   nil,
 			expectedBidder:   "a",
 			expectedStatus:   StatusBlockedByCCPA,
 		},
+		{
+			description:      "Blocked By Activity Control",
+			givenBidder:      "a",
+			givenSyncersSeen: map[string]struct{}{},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: false},
+			givenCookie:      cookieNeedsSync,
+			expectedSyncer:   nil,
+			expectedBidder:   "a",
+			expectedStatus:   StatusBlockedByPrivacy,
+		},
+		{
+			description:      "Blocked By Cookie Size",
+			givenBidder:      "a",
+			givenSyncersSeen: map[string]struct{}{},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
+			givenCookie:      cookieAlreadyHasSyncForB,
+			givenEviction:    UIDEviction{HostCookie: cookieFullWithB, Priority: UIDPriority{"keyB": 0}},
+			expectedSyncer:   nil,
+			expectedBidder:   "a",
+			expectedStatus:   StatusBlockedByCookieSize,
+		},
+		{
+			description:      "Full Cookie With Lower Priority UIDs",
+			givenBidder:      "a",
+			givenSyncersSeen: map[string]struct{}{},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
+			givenCookie:      cookieAlreadyHasSyncForB,
+			givenEviction:    UIDEviction{HostCookie: cookieFullWithB, Priority: UIDPriority{"keyA": 0}},
+			expectedSyncer:   fakeSyncerA,
+			expectedBidder:   "a",
+			expectedStatus:   StatusOK,
+		},
 	}
 
 	for _, test := range testCases {
 		chooser, _ := NewChooser(bidderSyncerLookup).(standardChooser)
-		sync, evaluation := chooser.evaluate(test.givenBidder, test.givenSyncersSeen, syncTypeFilter, test.givenPrivacy, &test.givenCookie)
+		sync, evaluation := chooser.evaluate(test.givenBidder, test.givenSyncersSeen, syncTypeFilter, test.givenPrivacy, test.givenEviction, &test.givenCookie)
 
 		assert.Equal(t, test.expectedSyncer, sync, test.description+":syncer")
 
@@ -344,6 +410,59 @@ func TestChooserEvaluate(t *testing.T) {
 	}
 }
 
+func TestRequestLimit(t *testing.T) {
+	testCases := []struct {
+		description   string
+		givenLimit    int
+		givenLimits   Limits
+		expectedLimit int
+	}{
+		{
+			description:   "None",
+			expectedLimit: 0,
+		},
+		{
+			description:   "Request Limit",
+			givenLimit:    5,
+			givenLimits:   Limits{Default: 2},
+			expectedLimit: 5,
+		},
+		{
+			description:   "Account Default Limit",
+			givenLimit:    -1,
+			givenLimits:   Limits{Default: 2},
+			expectedLimit: 2,
+		},
+		{
+			description:   "Request Limit Below Max Limit",
+			givenLimit:    3,
+			givenLimits:   Limits{Max: 4},
+			expectedLimit: 3,
+		},
+		{
+			description:   "Request Limit Above Max Limit",
+			givenLimit:    5,
+			givenLimits:   Limits{Max: 4},
+			expectedLimit: 4,
+		},
+		{
+			description:   "No Limit Capped By Max Limit",
+			givenLimits:   Limits{Max: 4},
+			expectedLimit: 4,
+		},
+		{
+			description:   "Default Limit Above Max Limit",
+			givenLimits:   Limits{Default: 6, Max: 4},
+			expectedLimit: 4,
+		},
+	}
+
+	for _, test := range testCases {
+		request := Request{Limit: test.givenLimit, Limits: test.givenLimits}
+		assert.Equal(t, test.expectedLimit, request.limit(), test.description)
+	}
+}
+
 type mockBidderChooser struct {
 	mock.Mock
 }
@@ -384,9 +503,10 @@ func (fakeSyncer) GetSync(syncTypes []SyncType, privacyPolicies privacy.Policies
 }
 
 type fakePrivacy struct {
-	gdprAllowsHostCookie bool
-	gdprAllowsBidderSync bool
-	ccpaAllowsBidderSync bool
+	gdprAllowsHostCookie   bool
+	gdprAllowsBidderSync   bool
+	ccpaAllowsBidderSync   bool
+	activityAllowsUserSync bool
 }
 
 func (p fakePrivacy) GDPRAllowsHostCookie() bool {
@@ -400,3 +520,7 @@ func (p fakePrivacy) GDPRAllowsBidderSync(bidder string) bool {
 func (p fakePrivacy) CCPAAllowsBidderSync(bidder string) bool {
 	return p.ccpaAllowsBidderSync
 }
+
+func (p fakePrivacy) ActivityAllowsUserSync(bidder string) bool {
+	return p.activityAllowsUserSync
+}
@@ -4,8 +4,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"math"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/prebid/prebid-server/config"
@@ -18,6 +18,10 @@ const uidCookieName = "uids"
 // separate from the cookie ttl.
 const uidTTL = 14 * 24 * time.Hour
 
+// estimatedUIDLength is the length assumed for a UID which isn't synced yet, when checking if it would fit in the
+// cookie. It's the length of a UUID.
+const estimatedUIDLength = 36
+
 // Cookie is the cookie used in Prebid Server.
 //
 // To get an instance of this from a request, use ParseCookieFromRequest.
@@ -39,13 +43,10 @@ type uidWithExpiry struct {
 
 // ParseCookieFromRequest parses the UserSyncMap from an HTTP Request.
 func ParseCookieFromRequest(r *http.Request, cookie *config.HostCookie) *Cookie {
-	if cookie.OptOutCookie.Name != "" {
-		optOutCookie, err1 := r.Cookie(cookie.OptOutCookie.Name)
-		if err1 == nil && optOutCookie.Value == cookie.OptOutCookie.Value {
-			pc := NewCookie()
-			pc.SetOptOut(true)
-			return pc
-		}
+	if hasOptOutCookie(r, cookie) {
+		pc := NewCookie()
+		pc.SetOptOut(true)
+		return pc
 	}
 	var parsed *Cookie
 	uidCookie, err2 := r.Cookie(uidCookieName)
@@ -54,13 +55,25 @@ func ParseCookieFromRequest(r *http.Request, cookie *config.HostCookie) *Cookie
 	} else {
 		parsed = NewCookie()
 	}
-	// Fixes #582
+	syncHostCookie(r, cookie, parsed)
+	return parsed
+}
+
+func hasOptOutCookie(r *http.Request, cookie *config.HostCookie) bool {
+	if cookie.OptOutCookie.Name == "" {
+		return false
+	}
+	optOutCookie, err := r.Cookie(cookie.OptOutCookie.Name)
+	return err == nil && optOutCookie.Value == cookie.OptOutCookie.Value
+}
+
+// syncHostCookie uses the host cookie as the UID of the host family, if there's none yet. Fixes #582
+func syncHostCookie(r *http.Request, cookie *config.HostCookie, parsed *Cookie) {
 	if uid, _, _ := parsed.GetUID(cookie.Family); uid == "" && cookie.CookieName != "" {
 		if hostCookie, err := r.Cookie(cookie.CookieName); err == nil {
 			parsed.TrySync(cookie.Family, hostCookie.Value)
 		}
 	}
-	return parsed
 }
 
 // ParseCookie parses the UserSync cookie from a raw HTTP cookie.
@@ -142,39 +155,89 @@ func (cookie *Cookie) GetUIDs() map[string]string {
 	return uids
 }
 
-// SetCookieOnResponse is a shortcut for "ToHTTPCookie(); cookie.setDomain(domain); setCookie(w, cookie)"
-func (cookie *Cookie) SetCookieOnResponse(w http.ResponseWriter, setSiteCookie bool, cfg *config.HostCookie, ttl time.Duration) {
-	httpCookie := cookie.ToHTTPCookie(ttl)
-	var domain string = cfg.Domain
+// SetCookieOnResponse is a shortcut for "ToHTTPCookie(); cookie.setDomain(domain); setCookie(w, cookie)". If the
+// cookie is larger than cfg.MaxCookieSizeBytes, the UIDs are evicted by priority until it fits, and the syncer keys
+// of the evicted UIDs are returned.
+func (cookie *Cookie) SetCookieOnResponse(w http.ResponseWriter, setSiteCookie bool, cfg *config.HostCookie, ttl time.Duration, priority UIDPriority) []string {
+	httpCookie, evicted := cookie.toSizedHTTPCookie(cfg, ttl, priority)
 
-	if domain != "" {
-		httpCookie.Domain = domain
+	if setSiteCookie {
+		httpCookie.Secure = true
+		httpCookie.SameSite = http.SameSiteNoneMode
 	}
+	w.Header().Add("Set-Cookie", httpCookie.String())
+	return evicted
+}
 
-	var currSize int = len([]byte(httpCookie.String()))
-	for cfg.MaxCookieSizeBytes > 0 && currSize > cfg.MaxCookieSizeBytes && len(cookie.uids) > 0 {
-		var oldestElem string = ""
-		var oldestDate int64 = math.MaxInt64
-		for key, value := range cookie.uids {
-			timeUntilExpiration := time.Until(value.Expires)
-			if timeUntilExpiration < time.Duration(oldestDate) {
-				oldestElem = key
-				oldestDate = int64(timeUntilExpiration)
-			}
-		}
-		delete(cookie.uids, oldestElem)
-		httpCookie = cookie.ToHTTPCookie(ttl)
-		if domain != "" {
-			httpCookie.Domain = domain
+// toSizedHTTPCookie builds the HTTP cookie, after evicting the UIDs which don't fit in cfg.MaxCookieSizeBytes.
+func (cookie *Cookie) toSizedHTTPCookie(cfg *config.HostCookie, ttl time.Duration, priority UIDPriority) (*http.Cookie, []string) {
+	httpCookie := cookie.toDomainHTTPCookie(cfg, ttl)
+
+	var evicted []string
+	for cfg.MaxCookieSizeBytes > 0 && len([]byte(httpCookie.String())) > cfg.MaxCookieSizeBytes && len(cookie.uids) > 0 {
+		key := cookie.nextEviction(priority)
+		delete(cookie.uids, key)
+		evicted = append(evicted, key)
+		httpCookie = cookie.toDomainHTTPCookie(cfg, ttl)
+	}
+	return httpCookie, evicted
+}
+
+func (cookie *Cookie) toDomainHTTPCookie(cfg *config.HostCookie, ttl time.Duration) *http.Cookie {
+	httpCookie := cookie.ToHTTPCookie(ttl)
+	if cfg.Domain != "" {
+		httpCookie.Domain = cfg.Domain
+	}
+	return httpCookie
+}
+
+// nextEviction returns the syncer key of the UID to evict first: the one with the lowest priority, and the one
+// which expires soonest among them.
+func (cookie *Cookie) nextEviction(priority UIDPriority) string {
+	var evictKey string
+	var evictRank int
+	var evictExpires time.Time
+	for key, value := range cookie.uids {
+		rank := priority.rank(key)
+		if evictKey == "" ||
+			rank > evictRank ||
+			rank == evictRank && value.Expires.Before(evictExpires) ||
+			rank == evictRank && value.Expires.Equal(evictExpires) && key < evictKey {
+			evictKey = key
+			evictRank = rank
+			evictExpires = value.Expires
 		}
-		currSize = len([]byte(httpCookie.String()))
 	}
+	return evictKey
+}
 
-	if setSiteCookie {
-		httpCookie.Secure = true
-		httpCookie.SameSite = http.SameSiteNoneMode
+// keepsNewUID returns false if a new UID for the syncer key would be evicted as soon as it's saved, because the
+// cookie is already full of UIDs with a higher priority. The length of the new UID is estimated.
+func (cookie *Cookie) keepsNewUID(key string, cfg *config.HostCookie, priority UIDPriority) bool {
+	if cfg == nil || cfg.MaxCookieSizeBytes <= 0 {
+		return true
 	}
-	w.Header().Add("Set-Cookie", httpCookie.String())
+
+	trial := &Cookie{
+		uids:     make(map[string]uidWithExpiry, len(cookie.uids)+1),
+		optOut:   cookie.optOut,
+		birthday: cookie.birthday,
+	}
+	for k, v := range cookie.uids {
+		trial.uids[k] = v
+	}
+	trial.uids[key] = uidWithExpiry{
+		UID:     strings.Repeat("x", estimatedUIDLength),
+		Expires: time.Now().Add(uidTTL),
+	}
+
+	_, evicted := trial.toSizedHTTPCookie(cfg, cfg.TTLDuration(), priority)
+	for _, evictedKey := range evicted {
+		if evictedKey == key {
+			return false
+		}
+	}
+	return true
 }
 
 // Unsync removes the user's ID for the given syncer key from this cookie.
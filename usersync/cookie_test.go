@@ -376,6 +376,129 @@ func TestTrimCookiesClosestExpirationDates(t *testing.T) {
 	}
 }
 
+func TestTrimCookiesByPriority(t *testing.T) {
+	newCookie := func() *Cookie {
+		return &Cookie{
+			uids: map[string]uidWithExpiry{
+				"k1": newTempId("12345678901234567890123456789012", 7),
+				"k2": newTempId("abcdefghijklmnopqrstuvwxyzabcdef", 6),
+				"k3": newTempId("ABCDEFGHIJKLMNOPQRSTUVWXYZABCDEF", 5),
+				"k4": newTempId("aAbBcCdDeEfFgGhHiIjJkKlLmMnNoOpP", 4),
+			},
+			birthday: timestamp(),
+		}
+	}
+	// the UIDs have the same length, so that any two of them fit in the size of the first two
+	twoUIDs := newCookie()
+	delete(twoUIDs.uids, "k3")
+	delete(twoUIDs.uids, "k4")
+	sizeOfTwo := len(twoUIDs.toDomainHTTPCookie(&config.HostCookie{Domain: "mock-domain"}, time.Hour).String())
+
+	testCases := []struct {
+		description     string
+		priority        UIDPriority
+		expectedKeys    []string
+		expectedEvicted []string
+	}{
+		{
+			description:     "No priority: the soonest expiries are evicted",
+			expectedKeys:    []string{"k1", "k2"},
+			expectedEvicted: []string{"k4", "k3"},
+		},
+		{
+			description:     "The bidders left out of the priority are evicted first",
+			priority:        UIDPriority{"k4": 0},
+			expectedKeys:    []string{"k1", "k4"},
+			expectedEvicted: []string{"k3", "k2"},
+		},
+		{
+			description:     "The lowest priority is evicted first",
+			priority:        UIDPriority{"k1": 1, "k2": 2, "k3": 0, "k4": 0},
+			expectedKeys:    []string{"k3", "k4"},
+			expectedEvicted: []string{"k2", "k1"},
+		},
+		{
+			description:     "Soonest expiry within a priority group",
+			priority:        UIDPriority{"k1": 0, "k2": 0, "k3": 0, "k4": 0},
+			expectedKeys:    []string{"k1", "k2"},
+			expectedEvicted: []string{"k4", "k3"},
+		},
+	}
+
+	for _, test := range testCases {
+		cookie := newCookie()
+		hostCookie := &config.HostCookie{Domain: "mock-domain", MaxCookieSizeBytes: sizeOfTwo + 50}
+
+		evicted := cookie.SetCookieOnResponse(httptest.NewRecorder(), false, hostCookie, time.Hour, test.priority)
+
+		assert.Equal(t, test.expectedEvicted, evicted, test.description)
+		assert.ElementsMatch(t, test.expectedKeys, mapKeys(cookie.uids), test.description)
+	}
+}
+
+func TestKeepsNewUID(t *testing.T) {
+	cookie := &Cookie{
+		uids: map[string]uidWithExpiry{
+			"k1": newTempId("12345678901234567890123456789012", 7),
+			"k2": newTempId("abcdefghijklmnopqrstuvwxyzabcdef", 6),
+		},
+		birthday: timestamp(),
+	}
+	fullSize := len(cookie.toDomainHTTPCookie(&config.HostCookie{}, time.Hour).String())
+	priority := UIDPriority{"k1": 0, "k2": 1, "high": 0, "low": 2}
+
+	testCases := []struct {
+		description   string
+		key           string
+		hostCookie    *config.HostCookie
+		expectedKeeps bool
+	}{
+		{
+			description:   "No size limit",
+			key:           "low",
+			hostCookie:    &config.HostCookie{},
+			expectedKeeps: true,
+		},
+		{
+			description:   "Fits",
+			key:           "low",
+			hostCookie:    &config.HostCookie{MaxCookieSizeBytes: fullSize + 200},
+			expectedKeeps: true,
+		},
+		{
+			description:   "Full cookie, higher priority than a saved UID",
+			key:           "high",
+			hostCookie:    &config.HostCookie{MaxCookieSizeBytes: fullSize},
+			expectedKeeps: true,
+		},
+		{
+			description:   "Full cookie, lower priority than every saved UID",
+			key:           "low",
+			hostCookie:    &config.HostCookie{MaxCookieSizeBytes: fullSize},
+			expectedKeeps: false,
+		},
+		{
+			description:   "Full cookie, left out of the priority",
+			key:           "unknown",
+			hostCookie:    &config.HostCookie{MaxCookieSizeBytes: fullSize},
+			expectedKeeps: false,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedKeeps, cookie.keepsNewUID(test.key, test.hostCookie, priority), test.description)
+		assert.Len(t, cookie.uids, 2, "The cookie shouldn't be changed: "+test.description)
+	}
+}
+
+func mapKeys(uids map[string]uidWithExpiry) []string {
+	keys := make([]string, 0, len(uids))
+	for key := range uids {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 func ensureEmptyMap(t *testing.T, cookie *Cookie) {
 	if !cookie.AllowSyncs() {
 		t.Error("Empty cookies should allow user syncs.")
@@ -467,7 +590,7 @@ func newSampleCookie() *Cookie {
 func writeThenRead(cookie *Cookie, maxCookieSize int) *Cookie {
 	w := httptest.NewRecorder()
 	hostCookie := &config.HostCookie{Domain: "mock-domain", MaxCookieSizeBytes: maxCookieSize}
-	cookie.SetCookieOnResponse(w, false, hostCookie, 90*24*time.Hour)
+	cookie.SetCookieOnResponse(w, false, hostCookie, 90*24*time.Hour, nil)
 	writtenCookie := w.HeaderMap.Get("Set-Cookie")
 
 	header := http.Header{}
@@ -483,7 +606,7 @@ func TestSetCookieOnResponseForSameSiteNone(t *testing.T) {
 	ua := "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_14_0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/75.0.3770.142 Safari/537.36"
 	req.Header.Set("User-Agent", ua)
 	hostCookie := &config.HostCookie{Domain: "mock-domain", MaxCookieSizeBytes: 0}
-	cookie.SetCookieOnResponse(w, true, hostCookie, 90*24*time.Hour)
+	cookie.SetCookieOnResponse(w, true, hostCookie, 90*24*time.Hour, nil)
 	writtenCookie := w.HeaderMap.Get("Set-Cookie")
 	t.Log("Set-Cookie is: ", writtenCookie)
 	if !strings.Contains(writtenCookie, "; Secure;") {
@@ -498,7 +621,7 @@ func TestSetCookieOnResponseForOlderChromeVersion(t *testing.T) {
 	ua := "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_14_0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/65.0.3770.142 Safari/537.36"
 	req.Header.Set("User-Agent", ua)
 	hostCookie := &config.HostCookie{Domain: "mock-domain", MaxCookieSizeBytes: 0}
-	cookie.SetCookieOnResponse(w, false, hostCookie, 90*24*time.Hour)
+	cookie.SetCookieOnResponse(w, false, hostCookie, 90*24*time.Hour, nil)
 	writtenCookie := w.HeaderMap.Get("Set-Cookie")
 	t.Log("Set-Cookie is: ", writtenCookie)
 	if strings.Contains(writtenCookie, "SameSite=none") {
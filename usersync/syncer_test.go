@@ -9,6 +9,7 @@ import (
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	"github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -602,6 +603,7 @@ func TestSyncerGetSync(t *testing.T) {
 	var (
 		iframeTemplate    = template.Must(template.New("test").Parse("iframe,gdpr:{{.GDPR}},gdprconsent:{{.GDPRConsent}},ccpa:{{.USPrivacy}}"))
 		redirectTemplate  = template.Must(template.New("test").Parse("redirect,gdpr:{{.GDPR}},gdprconsent:{{.GDPRConsent}},ccpa:{{.USPrivacy}}"))
+		gppTemplate       = template.Must(template.New("test").Parse("redirect,gpp:{{.GPP}},gppsid:{{.GPPSID}}"))
 		malformedTemplate = template.Must(template.New("test").Parse("malformed,invalid:{{.DoesNotExist}}"))
 	)
 
@@ -634,6 +636,13 @@ func TestSyncerGetSync(t *testing.T) {
 			givenPrivacyPolicies: privacy.Policies{GDPR: gdpr.Policy{Signal: "A", Consent: "B"}, CCPA: ccpa.Policy{Consent: "C"}},
 			expectedSync:         Sync{URL: "redirect,gdpr:A,gdprconsent:B,ccpa:C", Type: SyncTypeRedirect, SupportCORS: false},
 		},
+		{
+			description:          "GPP",
+			givenSyncer:          standardSyncer{redirect: gppTemplate},
+			givenSyncTypes:       []SyncType{SyncTypeRedirect},
+			givenPrivacyPolicies: privacy.Policies{GPP: gpp.Policy{Consent: "D", RawSID: "2,6"}},
+			expectedSync:         Sync{URL: "redirect,gpp:D,gppsid:2,6", Type: SyncTypeRedirect, SupportCORS: false},
+		},
 		{
 			description:          "Macro Error",
 			givenSyncer:          standardSyncer{iframe: malformedTemplate},
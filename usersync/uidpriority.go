@@ -0,0 +1,35 @@
+package usersync
+
+import "math"
+
+// UIDPriority ranks the syncer keys by the priority of their UIDs in the uids cookie. The lower the rank, the
+// higher the priority. When the cookie grows past its size budget, the UIDs with the highest rank are evicted
+// first, and the ones which expire soonest among them.
+type UIDPriority map[string]int
+
+// NewUIDPriority ranks the syncer keys of the bidders by the index of their group in priorityGroups, so that the
+// first group has the highest priority. A syncer key shared by bidders of several groups takes the highest priority
+// among them. The bidders without a syncer are ignored.
+func NewUIDPriority(priorityGroups [][]string, syncersByBidder map[string]Syncer) UIDPriority {
+	priority := make(UIDPriority)
+	for i, group := range priorityGroups {
+		for _, bidder := range group {
+			syncer, ok := syncersByBidder[bidder]
+			if !ok {
+				continue
+			}
+			if rank, ok := priority[syncer.Key()]; !ok || i < rank {
+				priority[syncer.Key()] = i
+			}
+		}
+	}
+	return priority
+}
+
+// rank returns the rank of the syncer key. The keys left out of the priority groups have the lowest priority.
+func (p UIDPriority) rank(key string) int {
+	if rank, ok := p[key]; ok {
+		return rank
+	}
+	return math.MaxInt32
+}
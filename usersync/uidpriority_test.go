@@ -0,0 +1,46 @@
+package usersync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUIDPriority(t *testing.T) {
+	syncersByBidder := map[string]Syncer{
+		"appnexus":   fakeSyncer{key: "adnxs"},
+		"appnexusAl": fakeSyncer{key: "adnxs"},
+		"rubicon":    fakeSyncer{key: "rubicon"},
+		"pubmatic":   fakeSyncer{key: "pubmatic"},
+	}
+
+	testCases := []struct {
+		description      string
+		priorityGroups   [][]string
+		expectedPriority UIDPriority
+	}{
+		{
+			description:      "None",
+			expectedPriority: UIDPriority{},
+		},
+		{
+			description:      "Ranked by group",
+			priorityGroups:   [][]string{{"rubicon", "appnexus"}, {"pubmatic"}},
+			expectedPriority: UIDPriority{"rubicon": 0, "adnxs": 0, "pubmatic": 1},
+		},
+		{
+			description:      "Shared syncer key takes the highest priority",
+			priorityGroups:   [][]string{{"rubicon"}, {"appnexusAl"}, {"appnexus"}},
+			expectedPriority: UIDPriority{"rubicon": 0, "adnxs": 1},
+		},
+		{
+			description:      "Bidder without a syncer",
+			priorityGroups:   [][]string{{"unknown"}, {"pubmatic"}},
+			expectedPriority: UIDPriority{"pubmatic": 1},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedPriority, NewUIDPriority(test.priorityGroups, syncersByBidder), test.description)
+	}
+}
@@ -0,0 +1,172 @@
+package usersync
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests/caches/redis"
+)
+
+// FirstPartyIDParam is the query param of /setuid, /getuids and /optout which identifies the users who don't
+// have a host cookie.
+const FirstPartyIDParam = "fpid"
+
+// uidStoreSweepInterval is how often the memory store drops the users whose UIDs have all expired
+const uidStoreSweepInterval = time.Minute
+
+// StoredUID is a UID saved in a UIDStore
+type StoredUID struct {
+	UID     string    `json:"uid"`
+	Expires time.Time `json:"expires"`
+}
+
+// UIDStore keeps the bidder UIDs of the users on the server side, instead of in the uids cookie. The UIDs of a
+// user are saved under a key which identifies the user, see UserKey.
+type UIDStore interface {
+	// Get returns the unexpired UIDs of the user, by syncer key.
+	Get(ctx context.Context, userKey string) (map[string]StoredUID, error)
+	// Set saves the UID of the user for the syncer key. It expires after the TTL of the store.
+	Set(ctx context.Context, userKey string, syncerKey string, uid string) error
+	// Delete removes the UID of the user for the syncer key.
+	Delete(ctx context.Context, userKey string, syncerKey string) error
+	// Purge removes all the UIDs of the user.
+	Purge(ctx context.Context, userKey string) error
+}
+
+// NewUIDStore returns the UIDStore set up by the config, or nil if the UIDs are kept in the uids cookie.
+func NewUIDStore(cfg config.UIDStore) UIDStore {
+	switch cfg.Type {
+	case "memory":
+		glog.Infof("Using an in-memory UID store. TTL: %d seconds.", cfg.TTL)
+		return NewMemoryUIDStore(cfg.TTLDuration())
+	case "redis":
+		glog.Infof("Using a Redis UID store. Address: %s. TTL: %d seconds.", cfg.Address, cfg.TTL)
+		client := redis.NewClient(cfg.Address, cfg.Password, cfg.Database, cfg.TimeoutDuration(), cfg.PoolSize)
+		return NewRedisUIDStore(client, cfg.KeyPrefix, cfg.TTLDuration())
+	default:
+		return nil
+	}
+}
+
+// UserKey returns the key the UIDs of the user are saved under. It's the host cookie if the request has one,
+// and the first-party ID otherwise. It's empty if the user has neither.
+func UserKey(r *http.Request, cfg *config.HostCookie, firstPartyID string) string {
+	if cfg.CookieName != "" {
+		if hostCookie, err := r.Cookie(cfg.CookieName); err == nil && hostCookie.Value != "" {
+			return "hc:" + hostCookie.Value
+		}
+	}
+	if firstPartyID != "" {
+		return "fp:" + firstPartyID
+	}
+	return ""
+}
+
+// ParseCookieFromStore builds the Cookie of the user from the UIDs saved in the store. The opt out and the host
+// cookie are read from the request, like in ParseCookieFromRequest, while the uids cookie is ignored.
+func ParseCookieFromStore(ctx context.Context, r *http.Request, cfg *config.HostCookie, store UIDStore, firstPartyID string) *Cookie {
+	parsed := NewCookie()
+	if hasOptOutCookie(r, cfg) {
+		parsed.SetOptOut(true)
+		return parsed
+	}
+
+	if userKey := UserKey(r, cfg, firstPartyID); userKey != "" {
+		uids, err := store.Get(ctx, userKey)
+		if err != nil {
+			glog.Warningf("Failed to read the UIDs of a user from the UID store: %v", err)
+		}
+		for key, uid := range uids {
+			parsed.uids[key] = uidWithExpiry{UID: uid.UID, Expires: uid.Expires}
+		}
+	}
+	syncHostCookie(r, cfg, parsed)
+	return parsed
+}
+
+// MemoryUIDStore is a UIDStore which keeps the UIDs in the memory of this Prebid Server instance.
+type MemoryUIDStore struct {
+	ttl       time.Duration
+	now       func() time.Time
+	lock      sync.Mutex
+	users     map[string]map[string]StoredUID
+	lastSweep time.Time
+}
+
+func NewMemoryUIDStore(ttl time.Duration) *MemoryUIDStore {
+	return &MemoryUIDStore{
+		ttl:   ttl,
+		now:   time.Now,
+		users: make(map[string]map[string]StoredUID),
+	}
+}
+
+func (s *MemoryUIDStore) Get(_ context.Context, userKey string) (map[string]StoredUID, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	uids := make(map[string]StoredUID, len(s.users[userKey]))
+	for key, uid := range s.users[userKey] {
+		if now.Before(uid.Expires) {
+			uids[key] = uid
+		}
+	}
+	return uids, nil
+}
+
+func (s *MemoryUIDStore) Set(_ context.Context, userKey string, syncerKey string, uid string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= uidStoreSweepInterval {
+		s.sweep(now)
+	}
+
+	uids, ok := s.users[userKey]
+	if !ok {
+		uids = make(map[string]StoredUID)
+		s.users[userKey] = uids
+	}
+	uids[syncerKey] = StoredUID{UID: uid, Expires: now.Add(s.ttl)}
+	return nil
+}
+
+func (s *MemoryUIDStore) Delete(_ context.Context, userKey string, syncerKey string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.users[userKey], syncerKey)
+	if len(s.users[userKey]) == 0 {
+		delete(s.users, userKey)
+	}
+	return nil
+}
+
+func (s *MemoryUIDStore) Purge(_ context.Context, userKey string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.users, userKey)
+	return nil
+}
+
+// sweep drops the expired UIDs, and the users left without any. The lock must be held.
+func (s *MemoryUIDStore) sweep(now time.Time) {
+	for userKey, uids := range s.users {
+		for key, uid := range uids {
+			if !now.Before(uid.Expires) {
+				delete(uids, key)
+			}
+		}
+		if len(uids) == 0 {
+			delete(s.users, userKey)
+		}
+	}
+	s.lastSweep = now
+}
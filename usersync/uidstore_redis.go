@@ -0,0 +1,81 @@
+package usersync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prebid/prebid-server/stored_requests/caches/redis"
+)
+
+// RedisUIDStore is a UIDStore backed by a Redis-protocol server, so that the UIDs are shared by every Prebid
+// Server instance. The UIDs of a user are the fields of a hash, which expires once its last saved UID does.
+type RedisUIDStore struct {
+	client    *redis.Client
+	keyPrefix string
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+func NewRedisUIDStore(client *redis.Client, keyPrefix string, ttl time.Duration) *RedisUIDStore {
+	return &RedisUIDStore{
+		client:    client,
+		keyPrefix: keyPrefix + ":uids:",
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+func (s *RedisUIDStore) Get(ctx context.Context, userKey string) (map[string]StoredUID, error) {
+	reply, err := s.client.Do(ctx, "HGETALL", s.keyPrefix+userKey)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := reply.([]interface{})
+	if !ok || len(fields)%2 != 0 {
+		return nil, fmt.Errorf("unexpected HGETALL reply %v", reply)
+	}
+
+	now := s.now()
+	uids := make(map[string]StoredUID, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		key, keyOK := fields[i].([]byte)
+		value, valueOK := fields[i+1].([]byte)
+		if !keyOK || !valueOK {
+			return nil, fmt.Errorf("unexpected HGETALL reply %v", reply)
+		}
+		var uid StoredUID
+		if err := json.Unmarshal(value, &uid); err != nil {
+			continue
+		}
+		if now.Before(uid.Expires) {
+			uids[string(key)] = uid
+		}
+	}
+	return uids, nil
+}
+
+func (s *RedisUIDStore) Set(ctx context.Context, userKey string, syncerKey string, uid string) error {
+	value, err := json.Marshal(StoredUID{UID: uid, Expires: s.now().Add(s.ttl)})
+	if err != nil {
+		return err
+	}
+	key := s.keyPrefix + userKey
+	_, err = s.client.Pipeline(ctx, [][]string{
+		{"HSET", key, syncerKey, string(value)},
+		{"EXPIRE", key, strconv.FormatInt(int64(s.ttl/time.Second), 10)},
+	})
+	return err
+}
+
+func (s *RedisUIDStore) Delete(ctx context.Context, userKey string, syncerKey string) error {
+	_, err := s.client.Do(ctx, "HDEL", s.keyPrefix+userKey, syncerKey)
+	return err
+}
+
+func (s *RedisUIDStore) Purge(ctx context.Context, userKey string) error {
+	_, err := s.client.Do(ctx, "DEL", s.keyPrefix+userKey)
+	return err
+}
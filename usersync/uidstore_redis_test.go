@@ -0,0 +1,155 @@
+package usersync
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/stored_requests/caches/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisUIDStore(t *testing.T) {
+	server := newFakeHashServer(t)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewRedisUIDStore(redis.NewClient(server.address(), "", 0, time.Second, 1), "pbs", time.Hour)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, store.Set(ctx, "hc:user1", "adnxs", "123"))
+	assert.NoError(t, store.Set(ctx, "hc:user1", "rubicon", "456"))
+
+	uids, err := store.Get(ctx, "hc:user1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]StoredUID{
+		"adnxs":   {UID: "123", Expires: now.Add(time.Hour)},
+		"rubicon": {UID: "456", Expires: now.Add(time.Hour)},
+	}, uids)
+
+	assert.NoError(t, store.Delete(ctx, "hc:user1", "adnxs"))
+	uids, _ = store.Get(ctx, "hc:user1")
+	assert.Equal(t, map[string]StoredUID{"rubicon": {UID: "456", Expires: now.Add(time.Hour)}}, uids)
+
+	now = now.Add(time.Hour)
+	uids, _ = store.Get(ctx, "hc:user1")
+	assert.Empty(t, uids, "The expired UIDs shouldn't be returned")
+
+	assert.NoError(t, store.Purge(ctx, "hc:user1"))
+	uids, _ = store.Get(ctx, "hc:user1")
+	assert.Empty(t, uids)
+
+	assert.Contains(t, server.commands, "EXPIRE pbs:uids:hc:user1 3600")
+	assert.Contains(t, server.commands, "DEL pbs:uids:hc:user1")
+}
+
+func TestRedisUIDStoreUnreachable(t *testing.T) {
+	store := NewRedisUIDStore(redis.NewClient("127.0.0.1:1", "", 0, 100*time.Millisecond, 1), "pbs", time.Hour)
+
+	_, err := store.Get(context.Background(), "hc:user1")
+	assert.Error(t, err)
+	assert.Error(t, store.Set(context.Background(), "hc:user1", "adnxs", "123"))
+}
+
+// fakeHashServer implements the hash commands of a Redis-protocol server used by the RedisUIDStore
+type fakeHashServer struct {
+	listener net.Listener
+	lock     sync.Mutex
+	hashes   map[string]map[string]string
+	commands []string
+}
+
+func newFakeHashServer(t *testing.T) *fakeHashServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start the fake server: %v", err)
+	}
+	s := &fakeHashServer{listener: listener, hashes: make(map[string]map[string]string)}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeHashServer) address() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeHashServer) serve() {
+	for {
+		cn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(cn)
+	}
+}
+
+func (s *fakeHashServer) handle(cn net.Conn) {
+	defer cn.Close()
+	reader := bufio.NewReader(cn)
+	for {
+		args, err := readFakeCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := cn.Write(s.execute(args)); err != nil {
+			return
+		}
+	}
+}
+
+func readFakeCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, count)
+	for i := range args {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+func (s *fakeHashServer) execute(args []string) []byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.commands = append(s.commands, strings.Join(args, " "))
+
+	switch args[0] {
+	case "HSET":
+		if s.hashes[args[1]] == nil {
+			s.hashes[args[1]] = make(map[string]string)
+		}
+		s.hashes[args[1]][args[2]] = args[3]
+		return []byte(":1\r\n")
+	case "HGETALL":
+		reply := "*" + strconv.Itoa(2*len(s.hashes[args[1]])) + "\r\n"
+		for field, value := range s.hashes[args[1]] {
+			reply += "$" + strconv.Itoa(len(field)) + "\r\n" + field + "\r\n"
+			reply += "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+		}
+		return []byte(reply)
+	case "HDEL":
+		delete(s.hashes[args[1]], args[2])
+		return []byte(":1\r\n")
+	case "DEL":
+		delete(s.hashes, args[1])
+		return []byte(":1\r\n")
+	case "EXPIRE":
+		return []byte(":1\r\n")
+	default:
+		return []byte("-ERR unknown command '" + args[0] + "'\r\n")
+	}
+}
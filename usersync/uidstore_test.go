@@ -0,0 +1,157 @@
+package usersync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryUIDStore(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryUIDStore(time.Hour)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, store.Set(ctx, "user1", "adnxs", "123"))
+	assert.NoError(t, store.Set(ctx, "user1", "rubicon", "456"))
+	assert.NoError(t, store.Set(ctx, "user2", "adnxs", "789"))
+
+	uids, err := store.Get(ctx, "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]StoredUID{
+		"adnxs":   {UID: "123", Expires: now.Add(time.Hour)},
+		"rubicon": {UID: "456", Expires: now.Add(time.Hour)},
+	}, uids)
+
+	assert.NoError(t, store.Delete(ctx, "user1", "adnxs"))
+	uids, _ = store.Get(ctx, "user1")
+	assert.Equal(t, map[string]StoredUID{"rubicon": {UID: "456", Expires: now.Add(time.Hour)}}, uids)
+
+	assert.NoError(t, store.Purge(ctx, "user1"))
+	uids, _ = store.Get(ctx, "user1")
+	assert.Empty(t, uids)
+
+	now = now.Add(time.Hour)
+	uids, _ = store.Get(ctx, "user2")
+	assert.Empty(t, uids, "The expired UIDs shouldn't be returned")
+
+	assert.NoError(t, store.Set(ctx, "user3", "adnxs", "000"))
+	assert.NotContains(t, store.users, "user2", "The users with expired UIDs should be swept")
+	assert.Contains(t, store.users, "user3")
+}
+
+func TestUserKey(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cookieName   string
+		hostCookie   string
+		firstPartyID string
+		expectedKey  string
+	}{
+		{
+			description:  "Host cookie",
+			cookieName:   "khaos",
+			hostCookie:   "abc",
+			firstPartyID: "fp",
+			expectedKey:  "hc:abc",
+		},
+		{
+			description:  "First-party ID without host cookie",
+			cookieName:   "khaos",
+			firstPartyID: "fp",
+			expectedKey:  "fp:fp",
+		},
+		{
+			description:  "First-party ID without host cookie name",
+			hostCookie:   "abc",
+			firstPartyID: "fp",
+			expectedKey:  "fp:fp",
+		},
+		{
+			description: "Neither",
+			cookieName:  "khaos",
+			expectedKey: "",
+		},
+	}
+
+	for _, test := range testCases {
+		r := httptest.NewRequest("GET", "/getuids", nil)
+		if test.hostCookie != "" {
+			r.AddCookie(&http.Cookie{Name: "khaos", Value: test.hostCookie})
+		}
+		key := UserKey(r, &config.HostCookie{CookieName: test.cookieName}, test.firstPartyID)
+		assert.Equal(t, test.expectedKey, key, test.description)
+	}
+}
+
+func TestParseCookieFromStore(t *testing.T) {
+	hostCookie := &config.HostCookie{
+		Family:     "appnexus",
+		CookieName: "khaos",
+		OptOutCookie: config.Cookie{
+			Name:  "optout",
+			Value: "true",
+		},
+	}
+	store := NewMemoryUIDStore(time.Hour)
+	store.Set(context.Background(), "hc:abc", "rubicon", "456")
+	store.Set(context.Background(), "fp:fp", "adnxs", "789")
+
+	testCases := []struct {
+		description    string
+		cookies        []*http.Cookie
+		firstPartyID   string
+		expectedUIDs   map[string]string
+		expectedOptOut bool
+	}{
+		{
+			description:  "Host cookie user",
+			cookies:      []*http.Cookie{{Name: "khaos", Value: "abc"}},
+			expectedUIDs: map[string]string{"rubicon": "456", "appnexus": "abc"},
+		},
+		{
+			description:  "First-party ID user",
+			firstPartyID: "fp",
+			expectedUIDs: map[string]string{"adnxs": "789"},
+		},
+		{
+			description: "The uids cookie is ignored",
+			cookies: []*http.Cookie{func() *http.Cookie {
+				cookie := NewCookie()
+				cookie.TrySync("adnxs", "123")
+				return cookie.ToHTTPCookie(time.Hour)
+			}()},
+			expectedUIDs: map[string]string{},
+		},
+		{
+			description: "Opted out user",
+			cookies: []*http.Cookie{
+				{Name: "khaos", Value: "abc"},
+				{Name: "optout", Value: "true"},
+			},
+			expectedUIDs:   map[string]string{},
+			expectedOptOut: true,
+		},
+	}
+
+	for _, test := range testCases {
+		r := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+		for _, cookie := range test.cookies {
+			r.AddCookie(cookie)
+		}
+		cookie := ParseCookieFromStore(context.Background(), r, hostCookie, store, test.firstPartyID)
+		assert.Equal(t, test.expectedUIDs, cookie.GetUIDs(), test.description)
+		assert.Equal(t, test.expectedOptOut, !cookie.AllowSyncs(), test.description)
+	}
+}
+
+func TestNewUIDStore(t *testing.T) {
+	assert.Nil(t, NewUIDStore(config.UIDStore{Type: "none"}))
+	assert.IsType(t, &MemoryUIDStore{}, NewUIDStore(config.UIDStore{Type: "memory", TTL: 60}))
+	assert.IsType(t, &RedisUIDStore{}, NewUIDStore(config.UIDStore{Type: "redis", TTL: 60, Address: "localhost:6379", Timeout: 50}))
+}